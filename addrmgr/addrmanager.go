@@ -47,6 +47,10 @@ type AddrManager struct {
 	nNew           int
 	lamtx          sync.Mutex
 	localAddresses map[string]*localAddress
+
+	// anchors houses the addresses of the outbound peers which are
+	// connected to first at the next start.
+	anchors []*wire.NetAddress
 }
 
 type serializedKnownAddress struct {
@@ -65,6 +69,7 @@ type serializedAddrManager struct {
 	Addresses    []*serializedKnownAddress
 	NewBuckets   [newBucketCount][]string // string is NetAddressKey
 	TriedBuckets [triedBucketCount][]string
+	Anchors      []string
 }
 
 type localAddress struct {
@@ -161,15 +166,15 @@ const (
 
 // updateAddress is a helper function to either update an address already known
 // to the address manager, or to add the address if not already known.
-func (a *AddrManager) updateAddress(netAddr, srcAddr *wire.NetAddress) {
+func (a *AddrManager) updateAddress(netAddr *wire.NetAddressV2, srcAddr *wire.NetAddress) {
 	// Filter out non-routable addresses. Note that non-routable
 	// also includes invalid and local addresses.
-	if !IsRoutable(netAddr) {
+	if !IsRoutableV2(netAddr) {
 		return
 	}
 
-	addr := NetAddressKey(netAddr)
-	ka := a.find(netAddr)
+	addr := NetAddressV2Key(netAddr)
+	ka := a.addrIndex[addr]
 	if ka != nil {
 		// TODO(oga) only update addresses periodically.
 		// Update the last seen time and services.
@@ -265,7 +270,7 @@ func (a *AddrManager) expireNew(bucket int) {
 	}
 
 	if oldest != nil {
-		key := NetAddressKey(oldest.na)
+		key := NetAddressV2Key(oldest.na)
 		log.Tracef("expiring oldest address %v", key)
 
 		delete(a.addrNew[bucket], key)
@@ -294,14 +299,14 @@ func (a *AddrManager) pickTried(bucket int) *list.Element {
 	return oldestElem
 }
 
-func (a *AddrManager) getNewBucket(netAddr, srcAddr *wire.NetAddress) int {
+func (a *AddrManager) getNewBucket(netAddr *wire.NetAddressV2, srcAddr *wire.NetAddress) int {
 	// bitcoind:
 	// doublesha256(key + sourcegroup + int64(doublesha256(key + group
 	// + sourcegroup))%bucket_per_source_group) % num_new_buckets
 
 	data1 := []byte{}
 	data1 = append(data1, a.key[:]...)
-	data1 = append(data1, []byte(GroupKey(netAddr))...)
+	data1 = append(data1, []byte(GroupKeyV2(netAddr))...)
 	data1 = append(data1, []byte(GroupKey(srcAddr))...)
 	hash1 := chainhash.HashB(data1)
 	hash64 := binary.LittleEndian.Uint64(hash1)
@@ -317,13 +322,13 @@ func (a *AddrManager) getNewBucket(netAddr, srcAddr *wire.NetAddress) int {
 	return int(binary.LittleEndian.Uint64(hash2) % newBucketCount)
 }
 
-func (a *AddrManager) getTriedBucket(netAddr *wire.NetAddress) int {
+func (a *AddrManager) getTriedBucket(netAddr *wire.NetAddressV2) int {
 	// bitcoind hashes this as:
 	// doublesha256(key + group + truncate_to_64bits(doublesha256(key))
 	// % buckets_per_group) % num_buckets
 	data1 := []byte{}
 	data1 = append(data1, a.key[:]...)
-	data1 = append(data1, []byte(NetAddressKey(netAddr))...)
+	data1 = append(data1, []byte(NetAddressV2Key(netAddr))...)
 	hash1 := chainhash.HashB(data1)
 	hash64 := binary.LittleEndian.Uint64(hash1)
 	hash64 %= triedBucketsPerGroup
@@ -331,7 +336,7 @@ func (a *AddrManager) getTriedBucket(netAddr *wire.NetAddress) int {
 	binary.LittleEndian.PutUint64(hashbuf[:], hash64)
 	data2 := []byte{}
 	data2 = append(data2, a.key[:]...)
-	data2 = append(data2, GroupKey(netAddr)...)
+	data2 = append(data2, GroupKeyV2(netAddr)...)
 	data2 = append(data2, hashbuf[:]...)
 
 	hash2 := chainhash.HashB(data2)
@@ -398,10 +403,14 @@ func (a *AddrManager) savePeers() {
 		j := 0
 		for e := a.addrTried[i].Front(); e != nil; e = e.Next() {
 			ka := e.Value.(*KnownAddress)
-			sam.TriedBuckets[i][j] = NetAddressKey(ka.na)
+			sam.TriedBuckets[i][j] = NetAddressV2Key(ka.na)
 			j++
 		}
 	}
+	sam.Anchors = make([]string, len(a.anchors))
+	for i, na := range a.anchors {
+		sam.Anchors[i] = NetAddressKey(na)
+	}
 
 	w, err := os.Create(a.peersFile)
 	if err != nil {
@@ -464,7 +473,7 @@ func (a *AddrManager) deserializePeers(filePath string) error {
 
 	for _, v := range sam.Addresses {
 		ka := new(KnownAddress)
-		ka.na, err = a.DeserializeNetAddress(v.Addr)
+		ka.na, err = a.deserializeNetAddressV2(v.Addr)
 		if err != nil {
 			return fmt.Errorf("failed to deserialize netaddress "+
 				"%s: %v", v.Addr, err)
@@ -477,7 +486,7 @@ func (a *AddrManager) deserializePeers(filePath string) error {
 		ka.attempts = v.Attempts
 		ka.lastattempt = time.Unix(v.LastAttempt, 0)
 		ka.lastsuccess = time.Unix(v.LastSuccess, 0)
-		a.addrIndex[NetAddressKey(ka.na)] = ka
+		a.addrIndex[NetAddressV2Key(ka.na)] = ka
 	}
 
 	for i := range sam.NewBuckets {
@@ -509,6 +518,18 @@ func (a *AddrManager) deserializePeers(filePath string) error {
 		}
 	}
 
+	// Anchors which can no longer be resolved are skipped since they are
+	// only a hint for the first connections.
+	for _, val := range sam.Anchors {
+		na, err := a.DeserializeNetAddress(val)
+		if err != nil {
+			log.Warnf("Failed to deserialize anchor address %s: %v",
+				val, err)
+			continue
+		}
+		a.anchors = append(a.anchors, na)
+	}
+
 	// Sanity checking.
 	for k, v := range a.addrIndex {
 		if v.refs == 0 && !v.tried {
@@ -539,6 +560,53 @@ func (a *AddrManager) DeserializeNetAddress(addr string) (*wire.NetAddress, erro
 	return a.HostToNetAddress(host, uint16(port), wire.SFNodeNetwork)
 }
 
+// deserializeNetAddressV2 converts a given address string to a
+// *wire.NetAddressV2.  Host names which are not addresses of a known network
+// are resolved like DeserializeNetAddress does.
+func (a *AddrManager) deserializeNetAddressV2(addr string) (*wire.NetAddressV2, error) {
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return nil, err
+	}
+
+	na, err := wire.NewNetAddressV2FromHost(host, uint16(port),
+		wire.SFNodeNetwork)
+	if err == nil {
+		return na, nil
+	}
+	legacy, err := a.HostToNetAddress(host, uint16(port), wire.SFNodeNetwork)
+	if err != nil {
+		return nil, err
+	}
+	return wire.NewNetAddressV2FromLegacy(legacy), nil
+}
+
+// SetAnchors sets the addresses of the outbound peers which are saved along
+// with the known addresses, so they can be connected to first at the next
+// start in order to make it harder to eclipse the node after a restart.  It is
+// safe for concurrent access.
+func (a *AddrManager) SetAnchors(addrs []*wire.NetAddress) {
+	a.mtx.Lock()
+	a.anchors = append([]*wire.NetAddress(nil), addrs...)
+	a.mtx.Unlock()
+}
+
+// TakeAnchors returns the anchor addresses loaded from the peers file and
+// clears them, so they are only saved again when they are set anew with
+// SetAnchors.  This prevents reconnecting to stale anchors after a crash.  It
+// is safe for concurrent access.
+func (a *AddrManager) TakeAnchors() []*wire.NetAddress {
+	a.mtx.Lock()
+	anchors := a.anchors
+	a.anchors = nil
+	a.mtx.Unlock()
+	return anchors
+}
+
 // Start begins the core address handler which manages a pool of known
 // addresses, timeouts, and interval based writes.
 func (a *AddrManager) Start() {
@@ -578,6 +646,19 @@ func (a *AddrManager) AddAddresses(addrs []*wire.NetAddress, srcAddr *wire.NetAd
 	a.mtx.Lock()
 	defer a.mtx.Unlock()
 
+	for _, na := range addrs {
+		a.updateAddress(wire.NewNetAddressV2FromLegacy(na), srcAddr)
+	}
+}
+
+// AddAddressesV2 adds new addresses in the versioned address format, which
+// includes addresses of networks which are not based on IP, to the address
+// manager.  It enforces a max number of addresses and silently ignores
+// duplicate addresses.  It is safe for concurrent access.
+func (a *AddrManager) AddAddressesV2(addrs []*wire.NetAddressV2, srcAddr *wire.NetAddress) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
 	for _, na := range addrs {
 		a.updateAddress(na, srcAddr)
 	}
@@ -590,7 +671,7 @@ func (a *AddrManager) AddAddress(addr, srcAddr *wire.NetAddress) {
 	a.mtx.Lock()
 	defer a.mtx.Unlock()
 
-	a.updateAddress(addr, srcAddr)
+	a.updateAddress(wire.NewNetAddressV2FromLegacy(addr), srcAddr)
 }
 
 // AddAddressByIP adds an address where we are given an ip:port and not a
@@ -637,26 +718,42 @@ func (a *AddrManager) NeedMoreAddresses() bool {
 	return a.numAddresses() < needAddressThreshold
 }
 
-// AddressCache returns the current address cache.  It must be treated as
-// read-only (but since it is a copy now, this is not as dangerous).
+// AddressCache returns the current address cache.  Addresses of networks which
+// can't be represented in the legacy address format are not included.  It must
+// be treated as read-only (but since it is a copy now, this is not as
+// dangerous).
 func (a *AddrManager) AddressCache() []*wire.NetAddress {
-	//a.mtx.Lock()
-	//defer a.mtx.Unlock()
-	//
-	//addrIndexLen := len(a.addrIndex)
-	//if addrIndexLen == 0 {
-	//	return nil
-	//}
-	//
-	//allAddr := make([]*wire.NetAddress, 0, addrIndexLen)
-	//// Iteration order is undefined here, but we randomise it anyway.
-	//for _, v := range a.addrIndex {
-	//	allAddr = append(allAddr, v.na)
-	//}
-	allAddr := a.getAddresses()
-
-	//numAddresses := addrIndexLen * getAddrPercent / 100
-	numAddresses := len(allAddr) * getAddrPercent / 100
+	var addrs []*wire.NetAddress
+	for _, na := range a.getAddresses() {
+		if legacy := na.ToLegacy(); legacy != nil {
+			addrs = append(addrs, legacy)
+		}
+	}
+
+	numAddresses := len(addrs) * getAddrPercent / 100
+	if numAddresses > getAddrMax {
+		numAddresses = getAddrMax
+	}
+
+	// Fisher-Yates shuffle the array. We only need to do the first
+	// `numAddresses' since we are throwing the rest.
+	for i := 0; i < numAddresses; i++ {
+		// pick a number between current index and the end
+		j := rand.Intn(len(addrs)-i) + i
+		addrs[i], addrs[j] = addrs[j], addrs[i]
+	}
+
+	// slice off the limit we are willing to share.
+	return addrs[0:numAddresses]
+}
+
+// AddressCacheV2 returns the current address cache in the versioned address
+// format, including addresses of networks which are not based on IP.  It must
+// be treated as read-only.
+func (a *AddrManager) AddressCacheV2() []*wire.NetAddressV2 {
+	addrs := a.getAddresses()
+
+	numAddresses := len(addrs) * getAddrPercent / 100
 	if numAddresses > getAddrMax {
 		numAddresses = getAddrMax
 	}
@@ -665,17 +762,17 @@ func (a *AddrManager) AddressCache() []*wire.NetAddress {
 	// `numAddresses' since we are throwing the rest.
 	for i := 0; i < numAddresses; i++ {
 		// pick a number between current index and the end
-		//j := rand.Intn(addrIndexLen-i) + i
-		j := rand.Intn(len(allAddr)-i) + i
-		allAddr[i], allAddr[j] = allAddr[j], allAddr[i]
+		j := rand.Intn(len(addrs)-i) + i
+		addrs[i], addrs[j] = addrs[j], addrs[i]
 	}
 
 	// slice off the limit we are willing to share.
-	return allAddr[0:numAddresses]
+	return addrs[0:numAddresses]
 }
+
 // getAddresses returns all of the addresses currently found within the
 // manager's address cache.
-func (a *AddrManager) getAddresses() []*wire.NetAddress {
+func (a *AddrManager) getAddresses() []*wire.NetAddressV2 {
 	a.mtx.Lock()
 	defer a.mtx.Unlock()
 
@@ -684,18 +781,20 @@ func (a *AddrManager) getAddresses() []*wire.NetAddress {
 		return nil
 	}
 
-	addrs := make([]*wire.NetAddress, 0, addrIndexLen)
+	addrs := make([]*wire.NetAddressV2, 0, addrIndexLen)
 	for _, v := range a.addrIndex {
 		addrs = append(addrs, v.na)
 	}
 
 	return addrs
 }
+
 // reset resets the address manager by reinitialising the random source
 // and allocating fresh empty bucket storage.
 func (a *AddrManager) reset() {
 
 	a.addrIndex = make(map[string]*KnownAddress)
+	a.anchors = nil
 
 	// fill key with bytes from a good random source.
 	io.ReadFull(crand.Reader, a.key[:])
@@ -759,6 +858,16 @@ func NetAddressKey(na *wire.NetAddress) string {
 	return net.JoinHostPort(ipString(na), port)
 }
 
+// NetAddressV2Key returns a string key in the form of host:port for addresses
+// in the versioned address format.  The key of addresses which can be
+// represented in the legacy address format is the same as the one returned by
+// NetAddressKey.
+func NetAddressV2Key(na *wire.NetAddressV2) string {
+	port := strconv.FormatUint(uint64(na.Port), 10)
+
+	return net.JoinHostPort(na.Host(), port)
+}
+
 // GetAddress returns a single address that should be routable.  It picks a
 // random one from the possible addresses with preference given to ones that
 // have not been used recently and should not pick 'close' addresses
@@ -794,7 +903,7 @@ func (a *AddrManager) GetAddress() *KnownAddress {
 			randval := a.rand.Intn(large)
 			if float64(randval) < (factor * ka.chance() * float64(large)) {
 				log.Tracef("Selected %v from tried bucket",
-					NetAddressKey(ka.na))
+					NetAddressV2Key(ka.na))
 				return ka
 			}
 			factor *= 1.2
@@ -822,7 +931,7 @@ func (a *AddrManager) GetAddress() *KnownAddress {
 			randval := a.rand.Intn(large)
 			if float64(randval) < (factor * ka.chance() * float64(large)) {
 				log.Tracef("Selected %v from new bucket",
-					NetAddressKey(ka.na))
+					NetAddressV2Key(ka.na))
 				return ka
 			}
 			factor *= 1.2
@@ -960,7 +1069,7 @@ func (a *AddrManager) Good(addr *wire.NetAddress) {
 	// something back.
 	a.nNew++
 
-	rmkey := NetAddressKey(rmka.na)
+	rmkey := NetAddressV2Key(rmka.na)
 	log.Tracef("Replacing %s with %s in tried", rmkey, addrKey)
 
 	// We made sure there is space here just above.
@@ -1081,7 +1190,13 @@ func (a *AddrManager) GetBestLocalAddress(remoteAddr *wire.NetAddress) *wire.Net
 	var bestscore AddressPriority
 	var bestAddress *wire.NetAddress
 	for _, la := range a.localAddresses {
+		// Never suggest an address the remote address can't reach,
+		// such as an IPv6 address to an IPv4 peer, regardless of its
+		// priority.
 		reach := getReachabilityFrom(la.na, remoteAddr)
+		if reach == 0 {
+			continue
+		}
 		if reach > bestreach ||
 			(reach == bestreach && la.score > bestscore) {
 			bestreach = reach
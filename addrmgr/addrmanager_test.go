@@ -9,7 +9,10 @@ package addrmgr_test
 import (
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"net"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 	"time"
@@ -464,6 +467,29 @@ func TestGetBestLocalAddress(t *testing.T) {
 	*/
 }
 
+// TestGetBestLocalAddressUnreachable ensures a local address which is not
+// reachable from the remote address is never suggested, regardless of its
+// priority.
+func TestGetBestLocalAddressUnreachable(t *testing.T) {
+	amgr := addrmgr.New("testgetbestlocaladdressunreachable", nil)
+	localAddr := wire.NetAddress{IP: net.ParseIP("2001:470::1")}
+	amgr.AddLocalAddress(&localAddr, addrmgr.ManualPrio)
+
+	remoteAddr := wire.NetAddress{IP: net.ParseIP("204.124.8.1")}
+	got := amgr.GetBestLocalAddress(&remoteAddr)
+	if !got.IP.Equal(net.IPv4zero) {
+		t.Errorf("unexpected address for IPv4 remote address - got %s, "+
+			"want %s", got.IP, net.IPv4zero)
+	}
+
+	remoteAddr = wire.NetAddress{IP: net.ParseIP("2602:100:abcd::102")}
+	got = amgr.GetBestLocalAddress(&remoteAddr)
+	if !got.IP.Equal(localAddr.IP) {
+		t.Errorf("unexpected address for IPv6 remote address - got %s, "+
+			"want %s", got.IP, localAddr.IP)
+	}
+}
+
 func TestNetAddressKey(t *testing.T) {
 	addNaTests()
 
@@ -476,13 +502,124 @@ func TestNetAddressKey(t *testing.T) {
 		}
 	}
 }
+// TestAnchors ensures anchor addresses are saved to the peers file and are
+// only loaded once.
+func TestAnchors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "testanchors")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	anchors := []*wire.NetAddress{
+		wire.NewNetAddressIPPort(net.ParseIP("173.194.115.66"), 8333, 0),
+		wire.NewNetAddressIPPort(net.ParseIP("2620:100::1"), 8333, 0),
+	}
+	n := addrmgr.New(dir, lookupFunc)
+	n.Start()
+	n.SetAnchors(anchors)
+	n.Stop()
+
+	// takeAnchors returns the anchors loaded by a new address manager.
+	takeAnchors := func() []string {
+		n := addrmgr.New(dir, lookupFunc)
+		n.Start()
+		defer n.Stop()
+		var keys []string
+		for _, na := range n.TakeAnchors() {
+			keys = append(keys, addrmgr.NetAddressKey(na))
+		}
+		if len(n.TakeAnchors()) != 0 {
+			t.Error("TakeAnchors: anchors were not cleared")
+		}
+		return keys
+	}
+	want := []string{"173.194.115.66:8333", "[2620:100::1]:8333"}
+	if got := takeAnchors(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("TakeAnchors: unexpected anchors - got %v, want %v",
+			got, want)
+	}
+	if got := takeAnchors(); len(got) != 0 {
+		t.Fatalf("TakeAnchors: anchors were loaded again - got %v", got)
+	}
+}
+
+// TestAddAddressesV2 ensures addresses of networks which are not based on IP
+// are stored, only returned in the versioned address cache, and survive being
+// saved to and loaded from the peers file.
+func TestAddAddressesV2(t *testing.T) {
+	dir, err := ioutil.TempDir("", "testaddaddressesv2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	hosts := []string{
+		"pg6mmjiyjmcrsslvykfwnntlaru7p5svn6y2ymmju6nubxndf4pscryd.onion",
+		"ukeu3k5oycgaauneqgtnvselmt4yemvoilkln7jpvamvfx7dnkdq.b32.i2p",
+		"fc00:1:2:3:4:5:6:7",
+		"173.194.115.66",
+	}
+	var addrs []*wire.NetAddressV2
+	for _, host := range hosts {
+		na, err := wire.NewNetAddressV2FromHost(host, 8333,
+			wire.SFNodeNetwork)
+		if err != nil {
+			t.Fatalf("NewNetAddressV2FromHost(%q): %v", host, err)
+		}
+		addrs = append(addrs, na)
+	}
+	src := wire.NewNetAddressIPPort(net.ParseIP("173.144.173.111"), 8333, 0)
+
+	// The caches only return a percentage of the addresses, so add
+	// enough other addresses first for all of them to be returned.  The
+	// addresses are added last so they are never the oldest addresses of
+	// a full bucket.
+	n := addrmgr.New(dir, lookupFunc)
+	n.Start()
+	for i := 0; i < 100; i++ {
+		ip := net.IPv4(1, 2, 3, byte(i))
+		n.AddAddress(wire.NewNetAddressIPPort(ip, 8333, 0), src)
+	}
+	n.AddAddressesV2(addrs, src)
+	for _, na := range n.AddressCache() {
+		if na.IP == nil || na.IP.To16() == nil {
+			t.Fatalf("AddressCache: invalid legacy address %v", na)
+		}
+	}
+	numAddresses := n.NumAddresses()
+	n.Stop()
+
+	// All addresses, including the ones which can't be represented in
+	// the legacy address format, are loaded again.
+	n = addrmgr.New(dir, lookupFunc)
+	n.Start()
+	defer n.Stop()
+	if n.NumAddresses() != numAddresses {
+		t.Fatalf("NumAddresses: got %d after reload, want %d",
+			n.NumAddresses(), numAddresses)
+	}
+	seen := make(map[string]wire.NetAddressType)
+	for i := 0; i < 100; i++ {
+		for _, na := range n.AddressCacheV2() {
+			seen[na.Host()] = na.Type
+		}
+	}
+	for i, host := range hosts {
+		if addrType, ok := seen[host]; !ok || addrType != addrs[i].Type {
+			t.Errorf("AddressCacheV2: %s missing or wrong type %v",
+				host, addrType)
+		}
+	}
+}
+
 func TestCorruptPeersFile(t *testing.T) {
 	dir, err := ioutil.TempDir("", "testcorruptpeersfile")
 	if err != nil {
 		t.Fatal(err)
 	}
 	defer os.RemoveAll(dir)
-	peersFile := filepath.Join(dir, PeersFilename)
+	peersFile := filepath.Join(dir, "peers.json")
 	// create corrupt (empty) peers file
 	fp, err := os.Create(peersFile)
 	if err != nil {
@@ -491,7 +628,7 @@ func TestCorruptPeersFile(t *testing.T) {
 	if err := fp.Close(); err != nil {
 		t.Fatalf("Could not write empty peers file: %s", peersFile)
 	}
-	amgr := New(dir, nil)
+	amgr := addrmgr.New(dir, nil)
 	amgr.Start()
 	amgr.Stop()
 	if _, err := os.Stat(peersFile); err != nil {
@@ -17,7 +17,7 @@ import (
 // to determine how viable an address is.
 type KnownAddress struct {
 	mtx         sync.Mutex
-	na          *wire.NetAddress
+	na          *wire.NetAddressV2
 	srcAddr     *wire.NetAddress
 	attempts    int
 	lastattempt time.Time
@@ -26,9 +26,17 @@ type KnownAddress struct {
 	refs        int // reference count of new buckets
 }
 
-// NetAddress returns the underlying wire.NetAddress associated with the
-// known address.
+// NetAddress returns the known address in the legacy wire.NetAddress format,
+// or nil when its network can't be represented by it.
 func (ka *KnownAddress) NetAddress() *wire.NetAddress {
+	ka.mtx.Lock()
+	defer ka.mtx.Unlock()
+	return ka.na.ToLegacy()
+}
+
+// NetAddressV2 returns the underlying wire.NetAddressV2 associated with the
+// known address.
+func (ka *KnownAddress) NetAddressV2() *wire.NetAddressV2 {
 	ka.mtx.Lock()
 	defer ka.mtx.Unlock()
 	return ka.na
@@ -94,6 +94,60 @@ var (
 	heNet = ipNet("2001:470::", 32, 128)
 )
 
+// NetworkType identifies the network an address is reached through.
+type NetworkType int
+
+// These constants define the network types.
+const (
+	// IPv4Network is the network of IPv4 addresses.
+	IPv4Network NetworkType = iota
+
+	// IPv6Network is the network of IPv6 addresses, including the
+	// tunnelled ones.
+	IPv6Network
+
+	// OnionNetwork is the network of Tor onion services.
+	OnionNetwork
+)
+
+// networkTypeStrings is a map of network types back to their constant names
+// for pretty printing.
+var networkTypeStrings = map[NetworkType]string{
+	IPv4Network:  "ipv4",
+	IPv6Network:  "ipv6",
+	OnionNetwork: "onion",
+}
+
+// String returns the NetworkType in human-readable form.
+func (n NetworkType) String() string {
+	if s, ok := networkTypeStrings[n]; ok {
+		return s
+	}
+	return fmt.Sprintf("Unknown NetworkType (%d)", int(n))
+}
+
+// ParseNetworkType returns the network type with the passed name as returned
+// by String.
+func ParseNetworkType(name string) (NetworkType, error) {
+	for n, s := range networkTypeStrings {
+		if s == name {
+			return n, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown network %q", name)
+}
+
+// Network returns the network the passed address is reached through.
+func Network(na *wire.NetAddress) NetworkType {
+	switch {
+	case IsOnionCatTor(na):
+		return OnionNetwork
+	case IsIPv4(na):
+		return IPv4Network
+	}
+	return IPv6Network
+}
+
 // ipNet returns a net.IPNet struct given the passed IP address string, number
 // of one bits to include at the start of the mask, and the total number of bits
 // for the mask.
@@ -231,6 +285,43 @@ func IsRoutable(na *wire.NetAddress) bool {
 		IsLocal(na) || (IsRFC4193(na) && !IsOnionCatTor(na)))
 }
 
+// IsRoutableV2 returns whether or not the passed address in the versioned
+// address format is routable over the public internet or one of the overlay
+// networks which are not based on IP.
+func IsRoutableV2(na *wire.NetAddressV2) bool {
+	switch na.Type {
+	case wire.TorV3Address, wire.I2PAddress:
+		return true
+	case wire.CJDNSAddress:
+		return len(na.Addr) > 0 && na.Addr[0] == 0xfc
+	}
+	legacy := na.ToLegacy()
+	return legacy != nil && IsRoutable(legacy)
+}
+
+// GroupKeyV2 returns a string representing the network group an address in the
+// versioned address format is part of.  Addresses which can be represented in
+// the legacy address format are grouped like GroupKey does, while the group of
+// the other addresses is keyed off the name of their network and the first 4
+// bits of the address which follow any fixed prefix.
+func GroupKeyV2(na *wire.NetAddressV2) string {
+	switch na.Type {
+	case wire.TorV3Address, wire.I2PAddress:
+		if len(na.Addr) > 0 {
+			return fmt.Sprintf("%v:%d", na.Type, na.Addr[0]>>4)
+		}
+	case wire.CJDNSAddress:
+		if len(na.Addr) > 1 {
+			return fmt.Sprintf("%v:%d", na.Type, na.Addr[1]>>4)
+		}
+	default:
+		if legacy := na.ToLegacy(); legacy != nil {
+			return GroupKey(legacy)
+		}
+	}
+	return "unroutable"
+}
+
 // GroupKey returns a string representing the network group an address is part
 // of.  This is the /16 for IPv4, the /32 (/36 for he.net) for IPv6, the string
 // "local" for a local address, the string "tor:key" where key is the /4 of the
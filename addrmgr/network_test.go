@@ -200,3 +200,41 @@ func TestGroupKey(t *testing.T) {
 		}
 	}
 }
+
+// TestNetwork ensures the Network function returns the network addresses are
+// reached through and the network types round trip through their names.
+func TestNetwork(t *testing.T) {
+	tests := []struct {
+		name     string
+		ip       string
+		expected addrmgr.NetworkType
+	}{
+		{name: "ipv4", ip: "12.1.2.3", expected: addrmgr.IPv4Network},
+		{name: "ipv4 mapped ipv6", ip: "::ffff:12.1.2.3", expected: addrmgr.IPv4Network},
+		{name: "ipv6", ip: "2602:100::1", expected: addrmgr.IPv6Network},
+		{name: "ipv6 rfc3964 with ipv4 encap", ip: "2002:0c01:0203::", expected: addrmgr.IPv6Network},
+		{name: "ipv6 tor onioncat", ip: "fd87:d87e:eb43:1234::5678", expected: addrmgr.OnionNetwork},
+	}
+
+	for i, test := range tests {
+		nip := net.ParseIP(test.ip)
+		na := *wire.NewNetAddressIPPort(nip, 8333, wire.SFNodeNetwork)
+		network := addrmgr.Network(&na)
+		if network != test.expected {
+			t.Errorf("TestNetwork #%d (%s): unexpected network - "+
+				"got %v, want %v", i, test.name, network,
+				test.expected)
+			continue
+		}
+		parsed, err := addrmgr.ParseNetworkType(network.String())
+		if err != nil || parsed != network {
+			t.Errorf("TestNetwork #%d (%s): unexpected parsed "+
+				"network - got %v (err %v), want %v", i,
+				test.name, parsed, err, network)
+		}
+	}
+
+	if _, err := addrmgr.ParseNetworkType("i2p"); err == nil {
+		t.Error("TestNetwork: parsed unknown network")
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/HcashOrg/hcd/chaincfg/chainhash"
 	"github.com/HcashOrg/hcd/database"
 	"github.com/HcashOrg/hcd/hcutil"
+	"github.com/HcashOrg/hcd/lockprof"
 	"github.com/HcashOrg/hcd/txscript"
 	"github.com/HcashOrg/hcd/wire"
 )
@@ -103,6 +104,9 @@ type blockNode struct {
 
 	// Keep track of all vote version and bits in this block.
 	votes []VoteVersionTuple
+
+	// status is the validation state of the block.
+	status blockStatus
 }
 
 // newBlockNode returns a new block node for the given block header.  It is
@@ -206,7 +210,9 @@ type BlockChain struct {
 	timeSource          MedianTimeSource
 	notifications       NotificationCallback
 	sigCache            *txscript.SigCache
+	scriptCache         *txscript.ScriptCache
 	indexManager        IndexManager
+	maxReorgDepth       int64
 
 	// subsidyCache is the cache that provides quick lookup of subsidy
 	// values.
@@ -214,13 +220,18 @@ type BlockChain struct {
 
 	// chainLock protects concurrent access to the vast majority of the
 	// fields in this struct below this point.
-	chainLock sync.RWMutex
+	chainLock lockprof.RWMutex
 
 	// These fields are configuration parameters that can be toggled at
 	// runtime.  They are protected by the chain lock.
 	noVerify      bool
 	noCheckpoints bool
 
+	// approvedReorgs houses the side chain blocks which were approved by
+	// the operator to be reorganized to regardless of the maximum
+	// automatic reorganization depth.  It is protected by the chain lock.
+	approvedReorgs map[chainhash.Hash]struct{}
+
 	// These fields are related to the memory block index.  They are
 	// protected by the chain lock.
 	bestNode *blockNode
@@ -286,6 +297,10 @@ type BlockChain struct {
 	calcPriorStakeVersionCache    map[[chainhash.HashSize]byte]uint32
 	calcVoterVersionIntervalCache map[[chainhash.HashSize]byte]uint32
 	calcStakeVersionCache         map[[chainhash.HashSize]byte]uint32
+
+	// stakeVersionTallyCache caches the tallies of complete stake version
+	// intervals by the hash of their last block.
+	stakeVersionTallyCache map[chainhash.Hash]*StakeVersionTally
 }
 
 const (
@@ -1279,6 +1294,13 @@ func (b *BlockChain) connectBlock(node *blockNode, block *hcutil.Block, view *Ut
 			return err
 		}
 
+		// Update the coin supply by adding a record for the block that
+		// contains the coins it added and the resulting total.
+		err = dbPutCoinSupplyEntry(dbTx, block, parent, b.chainParams)
+		if err != nil {
+			return err
+		}
+
 		// Insert the block into the database if it's not already there.
 		err = dbMaybeStoreBlock(dbTx, block)
 		if err != nil {
@@ -1312,8 +1334,9 @@ func (b *BlockChain) connectBlock(node *blockNode, block *hcutil.Block, view *Ut
 	view.commit()
 
 	// Add the new node to the memory main chain indices for faster
-	// lookups.
+	// lookups.  The block has now been fully validated.
 	node.inMainChain = true
+	node.status |= statusValid
 	b.index[node.hash] = node
 	b.depNodes[prevHash] = append(b.depNodes[prevHash], node)
 
@@ -1356,6 +1379,14 @@ func (b *BlockChain) connectBlock(node *blockNode, block *hcutil.Block, view *Ut
 				TicketsMissed:   []chainhash.Hash{},
 				TicketsNew:      node.stakeNode.NewTickets(),
 			})
+		// Notify of tickets which became revocable
+		b.sendNotification(NTRevocableTickets,
+			&RevocableTicketsNtfnsData{
+				Hash:           node.hash,
+				Height:         node.height,
+				TicketsMissed:  node.stakeNode.VoteMissedByBlock(),
+				TicketsExpired: node.stakeNode.ExpiredByBlock(),
+			})
 	}
 
 	// Assemble the current block and the parent into a slice.
@@ -1483,6 +1514,12 @@ func (b *BlockChain) disconnectBlock(node *blockNode, block *hcutil.Block, view
 			return err
 		}
 
+		// Update the coin supply by removing the record of the block.
+		err = dbRemoveCoinSupplyEntry(dbTx, block.Hash())
+		if err != nil {
+			return err
+		}
+
 		err = stake.WriteDisconnectedBestNode(dbTx, parentStakeNode,
 			node.parent.hash, childStakeNode.UndoData())
 		if err != nil {
@@ -1509,8 +1546,10 @@ func (b *BlockChain) disconnectBlock(node *blockNode, block *hcutil.Block, view
 	// now that the modifications have been committed to the database.
 	view.commit()
 
-	// Put block in the side chain cache.
+	// Put block in the side chain cache.  It remains fully validated since
+	// it was part of the main chain.
 	node.inMainChain = false
+	node.status |= statusValid
 	b.blockCacheLock.Lock()
 	b.blockCache[node.hash] = block
 	b.blockCacheLock.Unlock()
@@ -1697,8 +1736,13 @@ func (b *BlockChain) reorganizeChain(detachNodes, attachNodes *list.List,
 		// thus will not be generated.  This is done because the state
 		// is not being immediately written to the database, so it is
 		// not needed.
-		err := b.checkConnectBlock(n, block, view, nil)
+		err := b.checkConnectBlock(n, block, view, nil, BFNone)
 		if err != nil {
+			// Mark the block invalid when it breaks the rules so the
+			// branch is reported as such.
+			if _, ok := err.(RuleError); ok {
+				n.status |= statusInvalid
+			}
 			return err
 		}
 		topBlock = n
@@ -1893,7 +1937,8 @@ func (b *BlockChain) forceHeadReorganization(formerBest chainhash.Hash, newBest
 		return err
 	}
 
-	err = b.checkConnectBlock(newBestNode, newBestBlock, view, nil)
+	err = b.checkConnectBlock(newBestNode, newBestBlock, view, nil,
+		BFNone)
 	if err != nil {
 		return err
 	}
@@ -1930,6 +1975,7 @@ func (b *BlockChain) ForceHeadReorganization(formerBest chainhash.Hash, newBest
 // The flags modify the behavior of this function as follows:
 //  - BFFastAdd: Avoids several expensive transaction validation operations.
 //    This is useful when using checkpoints.
+//  - BFAssumeValid: Avoids script validation when extending the main chain.
 //  - BFDryRun: Prevents the block from being connected and avoids modifying the
 //    state of the memory chain index.  Also, any log messages related to
 //    modifying the state are avoided.
@@ -1957,7 +2003,8 @@ func (b *BlockChain) connectBestChain(node *blockNode, block *hcutil.Block, flag
 		view.SetStakeViewpoint(ViewpointPrevValidInitial)
 		var stxos []spentTxOut
 		if !fastAdd {
-			err := b.checkConnectBlock(node, block, view, &stxos)
+			err := b.checkConnectBlock(node, block, view, &stxos,
+				flags)
 			if err != nil {
 				return false, err
 			}
@@ -2097,6 +2144,16 @@ func (b *BlockChain) connectBestChain(node *blockNode, block *hcutil.Block, flag
 		return false, err
 	}
 
+	// Refuse to automatically reorganize more blocks than allowed unless
+	// the operator approved the new chain.  The block remains on the side
+	// chain and the chain stays at the current best block.
+	if b.exceedsMaxReorgDepth(detachNodes, attachNodes) {
+		if !dryRun {
+			b.rejectReorganization(node, int64(detachNodes.Len()))
+		}
+		return false, nil
+	}
+
 	// Reorganize the chain.
 	if !dryRun {
 		log.Infof("REORGANIZE: Block %v is causing a reorganize.",
@@ -2106,6 +2163,9 @@ func (b *BlockChain) connectBestChain(node *blockNode, block *hcutil.Block, flag
 	if err != nil {
 		return false, err
 	}
+	if !dryRun {
+		b.clearApprovedReorgs(attachNodes)
+	}
 
 	return true, nil
 }
@@ -2171,18 +2231,14 @@ func (b *BlockChain) maxBlockSize(prevNode *blockNode) (int64, error) {
 		return int64(b.chainParams.MaximumBlockSizes[0]), nil
 	}
 
-	// Return the larger block size if the version 4 stake vote for the max
-	// block size increase agenda is active.
-	//
-	// NOTE: The choice field of the return threshold state is not examined
-	// here because there is only one possible choice that can be active
-	// for the agenda, which is yes, so there is no need to check it.
+	// Return the larger block size if the stake vote for the max block size
+	// increase agenda is active.
 	maxSize := int64(b.chainParams.MaximumBlockSizes[0])
-	state, err := b.deploymentState(prevNode, 7, chaincfg.VoteIDMaxBlockSize)
+	active, err := b.isDeploymentActive(prevNode, chaincfg.VoteIDMaxBlockSize)
 	if err != nil {
 		return maxSize, err
 	}
-	if state.State == ThresholdActive {
+	if active {
 		return int64(b.chainParams.MaximumBlockSizes[1]), nil
 	}
 
@@ -2190,6 +2246,27 @@ func (b *BlockChain) maxBlockSize(prevNode *blockNode) (int64, error) {
 	return maxSize, nil
 }
 
+// IsHashFuncAddrActive returns whether or not addresses created with the
+// alternative hash function described by the passed parameters may be paid to
+// in the block AFTER the end of the current best chain.  This is the case once
+// the agenda which introduces them is active.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) IsHashFuncAddrActive(params *chaincfg.HashFuncAddrParams) (bool, error) {
+	if params.AgendaID == "" {
+		return true, nil
+	}
+
+	b.chainLock.Lock()
+	state, err := b.deploymentState(b.bestNode, params.AgendaVersion,
+		params.AgendaID)
+	b.chainLock.Unlock()
+	if err != nil {
+		return false, err
+	}
+	return state.State == ThresholdActive, nil
+}
+
 // MaximumBlockSize returns the maximum permitted block size for the block AFTER
 // the end of the current best chain.
 //
@@ -2269,12 +2346,39 @@ type Config struct {
 	// signature cache.
 	SigCache *txscript.SigCache
 
+	// ScriptCache defines a script verification cache to use when
+	// validating the scripts of blocks.  Inputs which were already verified
+	// with flags that imply the consensus flags, such as when their
+	// transactions were accepted to a transaction memory pool, are not
+	// executed again.
+	//
+	// This field can be nil if the caller is not interested in using a
+	// script cache.
+	ScriptCache *txscript.ScriptCache
+
 	// IndexManager defines an index manager to use when initializing the
 	// chain and connecting and disconnecting blocks.
 	//
 	// This field can be nil if the caller does not wish to make use of an
 	// index manager.
 	IndexManager IndexManager
+
+	// MaxReorgDepth defines the maximum number of blocks a reorganization
+	// may disconnect from the main chain without being approved by the
+	// operator via ReconsiderBlock.  Deeper reorganizations are rejected
+	// and a NTReorganizationRejected notification is sent instead.
+	//
+	// This field can be zero to allow reorganizations of any depth.
+	MaxReorgDepth int64
+
+	// Interrupt specifies a channel the caller can close to signal that
+	// the chain state should stop being rebuilt from the stored blocks
+	// after a call to ResetChainState.  New returns ErrReindexInterrupted
+	// in that case and the rebuild resumes on the next call.
+	//
+	// This field can be nil if the caller does not wish to interrupt the
+	// rebuild.
+	Interrupt <-chan struct{}
 }
 
 // New returns a BlockChain instance using the provided configuration details.
@@ -2305,7 +2409,10 @@ func New(config *Config) (*BlockChain, error) {
 		timeSource:                    config.TimeSource,
 		notifications:                 config.Notifications,
 		sigCache:                      config.SigCache,
+		scriptCache:                   config.ScriptCache,
 		indexManager:                  config.IndexManager,
+		maxReorgDepth:                 config.MaxReorgDepth,
+		approvedReorgs:                make(map[chainhash.Hash]struct{}),
 		bestNode:                      nil,
 		index:                         make(map[chainhash.Hash]*blockNode),
 		depNodes:                      make(map[chainhash.Hash][]*blockNode),
@@ -2320,6 +2427,14 @@ func New(config *Config) (*BlockChain, error) {
 		calcPriorStakeVersionCache:    make(map[[chainhash.HashSize]byte]uint32),
 		calcVoterVersionIntervalCache: make(map[[chainhash.HashSize]byte]uint32),
 		calcStakeVersionCache:         make(map[[chainhash.HashSize]byte]uint32),
+		stakeVersionTallyCache:        make(map[chainhash.Hash]*StakeVersionTally),
+	}
+	b.chainLock.SetName("chain")
+
+	// Remove the chain state when it was reset so it is rebuilt from the
+	// stored blocks.
+	if err := b.maybeFinishChainStateReset(); err != nil {
+		return nil, err
 	}
 
 	// Initialize the chain state from the passed database.  When the db
@@ -2345,6 +2460,12 @@ func New(config *Config) (*BlockChain, error) {
 	b.subsidyCache = NewSubsidyCache(b.bestNode.height, b.chainParams)
 	b.pruner = newChainPruner(&b)
 
+	// Connect the stored blocks again when the chain state is being
+	// rebuilt from them.
+	if err := b.maybeResumeReindex(config.Interrupt); err != nil {
+		return nil, err
+	}
+
 	log.Infof("Blockchain database version %v loaded",
 		b.dbInfo.version)
 
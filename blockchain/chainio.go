@@ -54,7 +54,7 @@ const (
 
 	// currentDatabaseVersion indicates what the current database
 	// version is.
-	currentDatabaseVersion = 2
+	currentDatabaseVersion = 3
 )
 
 // errNotInMainChain signifies that a block hash or height that is not in the
@@ -73,6 +73,13 @@ func isNotInMainChainErr(err error) bool {
 	return ok
 }
 
+// IsNotInMainChainErr is the exported version of isNotInMainChainErr.  It
+// allows callers of functions such as DBFetchBlockByHeight to tell a height
+// beyond the end of the main chain apart from other errors.
+func IsNotInMainChainErr(err error) bool {
+	return isNotInMainChainErr(err)
+}
+
 // errDeserialize signifies that a problem was encountered when deserializing
 // data.
 type errDeserialize string
@@ -914,9 +921,43 @@ func deserializeUtxoEntry(serialized []byte) (*UtxoEntry, error) {
 // both the entry and the error.
 func dbFetchUtxoEntry(dbTx database.Tx, hash *chainhash.Hash) (*UtxoEntry, error) {
 	// Fetch the unspent transaction output information for the passed
-	// transaction hash.  Return now when there is no entry.
+	// transaction hash.
 	utxoBucket := dbTx.Metadata().Bucket(dbnamespace.UtxoSetBucketName)
-	serializedUtxo := utxoBucket.Get(hash[:])
+	return dbDecodeUtxoEntry(hash, utxoBucket.Get(hash[:]))
+}
+
+// dbFetchUtxoEntries uses an existing database transaction to fetch all unspent
+// outputs for each of the provided transaction hashes from the utxo set.  The
+// returned entries are in the same order as the hashes, with nil entries for
+// the hashes which have no entry.
+//
+// The hashes are sorted in place so the entries are looked up in key order,
+// which keeps consecutive lookups close together in the underlying database
+// and its cache rather than jumping around in it as lookups in map order do.
+func dbFetchUtxoEntries(dbTx database.Tx, hashes []chainhash.Hash) ([]*UtxoEntry, error) {
+	sort.Slice(hashes, func(i, j int) bool {
+		return bytes.Compare(hashes[i][:], hashes[j][:]) < 0
+	})
+
+	utxoBucket := dbTx.Metadata().Bucket(dbnamespace.UtxoSetBucketName)
+	entries := make([]*UtxoEntry, len(hashes))
+	for i := range hashes {
+		entry, err := dbDecodeUtxoEntry(&hashes[i],
+			utxoBucket.Get(hashes[i][:]))
+		if err != nil {
+			return nil, err
+		}
+		entries[i] = entry
+	}
+
+	return entries, nil
+}
+
+// dbDecodeUtxoEntry deserializes the passed serialized utxo entry for the
+// provided transaction hash as fetched from the utxo set.  A nil serialized
+// entry results in nil for both the entry and the error.
+func dbDecodeUtxoEntry(hash *chainhash.Hash, serializedUtxo []byte) (*UtxoEntry, error) {
+	// Return now when there is no entry.
 	if serializedUtxo == nil {
 		return nil, nil
 	}
@@ -1367,6 +1408,13 @@ func (b *BlockChain) createChainState() error {
 			return err
 		}
 
+		// Create the bucket that houses the coin supply of each main
+		// chain block, starting with the empty one of the genesis block.
+		err = dbCreateCoinSupplyBucket(dbTx, &b.bestNode.hash)
+		if err != nil {
+			return err
+		}
+
 		// Add the genesis block hash to height and height to hash
 		// mappings to the index.
 		err = dbPutBlockIndex(dbTx, &b.bestNode.hash, b.bestNode.height)
@@ -1387,8 +1435,9 @@ func (b *BlockChain) createChainState() error {
 			return err
 		}
 
-		// Store the genesis block into the database.
-		return dbTx.StoreBlock(genesisBlock)
+		// Store the genesis block into the database unless it is still
+		// stored from before the chain state was reset.
+		return dbMaybeStoreBlock(dbTx, genesisBlock)
 	})
 	return err
 }
@@ -0,0 +1,126 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/HcashOrg/hcd/chaincfg/chainhash"
+)
+
+// blockStatus is a bit field representing the validation state of a block.
+type blockStatus byte
+
+const (
+	// statusValid indicates the block has been fully validated by
+	// connecting it to the main chain.
+	statusValid blockStatus = 1 << iota
+
+	// statusInvalid indicates the block was found to violate the rules
+	// while validating it for a reorganization.
+	statusInvalid
+)
+
+// ChainTipStatus describes the state of the branch ending at a chain tip.
+type ChainTipStatus int
+
+const (
+	// StatusActive indicates the tip is the end of the main chain.
+	StatusActive ChainTipStatus = iota
+
+	// StatusValidFork indicates every block of the branch has been fully
+	// validated, but the branch is not part of the main chain.  This is
+	// typically the case for branches which were reorganized away.
+	StatusValidFork
+
+	// StatusValidHeaders indicates every block of the branch is available
+	// and passed the checks done when accepting a side chain block, but
+	// not all of them have been fully validated.
+	StatusValidHeaders
+
+	// StatusInvalid indicates the branch contains a block which violates
+	// the rules.
+	StatusInvalid
+)
+
+// chainTipStatusStrings is a map of chain tip statuses back to their names as
+// used by the getchaintips RPC.
+var chainTipStatusStrings = map[ChainTipStatus]string{
+	StatusActive:       "active",
+	StatusValidFork:    "valid-fork",
+	StatusValidHeaders: "valid-headers",
+	StatusInvalid:      "invalid",
+}
+
+// String returns the ChainTipStatus as a human-readable name.
+func (s ChainTipStatus) String() string {
+	if str, ok := chainTipStatusStrings[s]; ok {
+		return str
+	}
+	return fmt.Sprintf("Unknown ChainTipStatus (%d)", int(s))
+}
+
+// ChainTip describes the tip of a branch of the block chain as returned by
+// ChainTips.
+type ChainTip struct {
+	Hash      chainhash.Hash
+	Height    int64
+	BranchLen int64
+	WorkSum   *big.Int
+	Status    ChainTipStatus
+}
+
+// ChainTips returns the tips of all branches of the block chain known to the
+// block index, which are the end of the main chain and every side chain block
+// without children.  The branch length is the number of blocks between the
+// tip and the main chain, which is zero for the end of the main chain.  The
+// tips are ordered by descending height.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) ChainTips() []ChainTip {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	tips := []ChainTip{{
+		Hash:    b.bestNode.hash,
+		Height:  b.bestNode.height,
+		WorkSum: new(big.Int).Set(b.bestNode.workSum),
+		Status:  StatusActive,
+	}}
+	for _, node := range b.index {
+		if node.inMainChain || len(node.children) != 0 {
+			continue
+		}
+
+		// Walk the branch back to the main chain to determine its length
+		// and the status of its blocks.
+		status := StatusValidFork
+		var branchLen int64
+		for n := node; n != nil && !n.inMainChain; n = n.parent {
+			branchLen++
+			switch {
+			case n.status&statusInvalid != 0:
+				status = StatusInvalid
+			case n.status&statusValid == 0 && status != StatusInvalid:
+				status = StatusValidHeaders
+			}
+		}
+
+		tips = append(tips, ChainTip{
+			Hash:      node.hash,
+			Height:    node.height,
+			BranchLen: branchLen,
+			WorkSum:   new(big.Int).Set(node.workSum),
+			Status:    status,
+		})
+	}
+
+	sort.SliceStable(tips, func(i, j int) bool {
+		return tips[i].Height > tips[j].Height
+	})
+	return tips
+}
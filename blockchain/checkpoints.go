@@ -233,7 +233,7 @@ func isNonstandardTransaction(tx *hcutil.Tx) bool {
 	return false
 }
 
-// IsCheckpointCandidate returns whether or not the passed block is a good
+// isCheckpointCandidate returns whether or not the passed block is a good
 // checkpoint candidate.
 //
 // The factors used to determine a good checkpoint are:
@@ -249,79 +249,128 @@ func isNonstandardTransaction(tx *hcutil.Tx) bool {
 // The intent is that candidates are reviewed by a developer to make the final
 // decision and then manually added to the list of checkpoints for a network.
 //
-// This function is safe for concurrent access.
-func (b *BlockChain) IsCheckpointCandidate(block *hcutil.Block) (bool, error) {
-	b.chainLock.RLock()
-	defer b.chainLock.RUnlock()
+// This function MUST be called with the chain lock held (for reads).
+func (b *BlockChain) isCheckpointCandidate(dbTx database.Tx, block *hcutil.Block) (bool, error) {
+	// A checkpoint must be in the main chain.
+	blockHeight, err := dbFetchHeightByHash(dbTx, block.Hash())
+	if err != nil {
+		// Only return an error if it's not due to the block not being
+		// in the main chain.
+		if !isNotInMainChainErr(err) {
+			return false, err
+		}
+		return false, nil
+	}
 
-	// Checkpoints must be enabled.
-	if b.noCheckpoints {
-		return false, fmt.Errorf("checkpoints are disabled")
+	// Ensure the height of the passed block and the entry for the block in
+	// the main chain match.  This should always be the case unless the
+	// caller provided an invalid block.
+	if blockHeight != block.Height() {
+		return false, fmt.Errorf("passed block height of %d does not "+
+			"match the main chain height of %d", block.Height(),
+			blockHeight)
 	}
 
-	var isCandidate bool
-	err := b.db.View(func(dbTx database.Tx) error {
-		// A checkpoint must be in the main chain.
-		blockHeight, err := dbFetchHeightByHash(dbTx, block.Hash())
-		if err != nil {
-			// Only return an error if it's not due to the block not
-			// being in the main chain.
-			if !isNotInMainChainErr(err) {
-				return err
-			}
-			return nil
-		}
+	// A checkpoint must be at least CheckpointConfirmations blocks before
+	// the end of the main chain.
+	mainChainHeight := b.bestNode.height
+	if blockHeight > (mainChainHeight - CheckpointConfirmations) {
+		return false, nil
+	}
 
-		// Ensure the height of the passed block and the entry for the
-		// block in the main chain match.  This should always be the
-		// case unless the caller provided an invalid block.
-		if blockHeight != block.Height() {
-			return fmt.Errorf("passed block height of %d does not "+
-				"match the main chain height of %d",
-				block.Height(), blockHeight)
-		}
+	// Get the previous block header.
+	prevHash := &block.MsgBlock().Header.PrevBlock
+	prevHeader, err := dbFetchHeaderByHash(dbTx, prevHash)
+	if err != nil {
+		return false, err
+	}
 
-		// A checkpoint must be at least CheckpointConfirmations blocks
-		// before the end of the main chain.
-		mainChainHeight := b.bestNode.height
-		if blockHeight > (mainChainHeight - CheckpointConfirmations) {
-			return nil
-		}
+	// Get the next block header.
+	nextHeader, err := dbFetchHeaderByHeight(dbTx, blockHeight+1)
+	if err != nil {
+		return false, err
+	}
 
-		// Get the previous block header.
-		prevHash := &block.MsgBlock().Header.PrevBlock
-		prevHeader, err := dbFetchHeaderByHash(dbTx, prevHash)
-		if err != nil {
-			return err
-		}
+	// A checkpoint must have timestamps for the block and the blocks on
+	// either side of it in order (due to the median time allowance this is
+	// not always the case).
+	prevTime := prevHeader.Timestamp
+	curTime := block.MsgBlock().Header.Timestamp
+	nextTime := nextHeader.Timestamp
+	if prevTime.After(curTime) || nextTime.Before(curTime) {
+		return false, nil
+	}
 
-		// Get the next block header.
-		nextHeader, err := dbFetchHeaderByHeight(dbTx, blockHeight+1)
-		if err != nil {
-			return err
+	// A checkpoint must have transactions that only contain standard
+	// scripts.
+	for _, tx := range block.Transactions() {
+		if isNonstandardTransaction(tx) {
+			return false, nil
 		}
+	}
 
-		// A checkpoint must have timestamps for the block and the
-		// blocks on either side of it in order (due to the median time
-		// allowance this is not always the case).
-		prevTime := prevHeader.Timestamp
-		curTime := block.MsgBlock().Header.Timestamp
-		nextTime := nextHeader.Timestamp
-		if prevTime.After(curTime) || nextTime.Before(curTime) {
-			return nil
-		}
+	// All of the checks passed, so the block is a candidate.
+	return true, nil
+}
+
+// IsCheckpointCandidate returns whether or not the passed block is a good
+// checkpoint candidate.  See isCheckpointCandidate for the factors used to
+// determine a good checkpoint.
+//
+// Candidates may be determined while checkpoints are disabled, which is the
+// preferred way to find them since every block of the chain has then been
+// fully validated without relying on the existing checkpoints.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) IsCheckpointCandidate(block *hcutil.Block) (bool, error) {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	var isCandidate bool
+	err := b.db.View(func(dbTx database.Tx) error {
+		var err error
+		isCandidate, err = b.isCheckpointCandidate(dbTx, block)
+		return err
+	})
+	return isCandidate, err
+}
 
-		// A checkpoint must have transactions that only contain
-		// standard scripts.
-		for _, tx := range block.Transactions() {
-			if isNonstandardTransaction(tx) {
-				return nil
+// CheckpointCandidates searches the main chain backwards from the most recent
+// block which has enough confirmations for up to maxCandidates good checkpoint
+// candidates.  The search stops at the latest known checkpoint since there is
+// no point in finding candidates before it.  When checkpoints are disabled,
+// the search continues back to the genesis block.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) CheckpointCandidates(maxCandidates int) ([]chaincfg.Checkpoint, error) {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	var stopHeight int64
+	if checkpoint := b.latestCheckpoint(); checkpoint != nil {
+		stopHeight = checkpoint.Height
+	}
+
+	var candidates []chaincfg.Checkpoint
+	err := b.db.View(func(dbTx database.Tx) error {
+		height := b.bestNode.height - CheckpointConfirmations
+		for ; height > stopHeight && len(candidates) < maxCandidates; height-- {
+			block, err := dbFetchBlockByHeight(dbTx, height)
+			if err != nil {
+				return err
+			}
+			isCandidate, err := b.isCheckpointCandidate(dbTx, block)
+			if err != nil {
+				return err
+			}
+			if isCandidate {
+				candidates = append(candidates, chaincfg.Checkpoint{
+					Height: height,
+					Hash:   block.Hash(),
+				})
 			}
 		}
-
-		// All of the checks passed, so the block is a candidate.
-		isCandidate = true
 		return nil
 	})
-	return isCandidate, err
+	return candidates, err
 }
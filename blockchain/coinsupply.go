@@ -0,0 +1,264 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"fmt"
+
+	"github.com/HcashOrg/hcd/blockchain/internal/dbnamespace"
+	"github.com/HcashOrg/hcd/blockchain/stake"
+	"github.com/HcashOrg/hcd/chaincfg"
+	"github.com/HcashOrg/hcd/chaincfg/chainhash"
+	"github.com/HcashOrg/hcd/database"
+	"github.com/HcashOrg/hcd/hcutil"
+	"github.com/HcashOrg/hcd/wire"
+)
+
+// coinSupplySerializeSize is the size of a serialized coin supply entry,
+// which consists of the coin supply added by a block followed by the total
+// coin supply of the main chain ending at it.
+const coinSupplySerializeSize = 2 * 5 * 8
+
+// CoinSupply breaks down an amount of coins by how they were issued, along
+// with the transaction fees which were not claimed by any coinbase and thus
+// destroyed.  All amounts are in atoms.
+type CoinSupply struct {
+	// PoW is the subsidy paid to miners.
+	PoW int64
+
+	// PoS is the subsidy paid to voters.
+	PoS int64
+
+	// Dev is the subsidy paid to the organization.
+	Dev int64
+
+	// BlockOne is the subsidy of the first block, which pays out the
+	// initial token ledger of the network.
+	BlockOne int64
+
+	// FeesDestroyed is the amount of transaction fees which were destroyed
+	// since no coinbase claimed them.
+	FeesDestroyed int64
+}
+
+// Subsidy returns the total subsidy of the coin supply.
+func (s *CoinSupply) Subsidy() int64 {
+	return s.PoW + s.PoS + s.Dev + s.BlockOne
+}
+
+// Supply returns the number of coins in existence, which is the total subsidy
+// less the destroyed fees.
+func (s *CoinSupply) Supply() int64 {
+	return s.Subsidy() - s.FeesDestroyed
+}
+
+// add adds the passed coin supply to this one.
+func (s *CoinSupply) add(o *CoinSupply) {
+	s.PoW += o.PoW
+	s.PoS += o.PoS
+	s.Dev += o.Dev
+	s.BlockOne += o.BlockOne
+	s.FeesDestroyed += o.FeesDestroyed
+}
+
+// BlockCoinSupply is the coin supply added by a main chain block along with
+// the total coin supply of the main chain ending at it.
+type BlockCoinSupply struct {
+	Hash   chainhash.Hash
+	Height int64
+	Block  CoinSupply
+	Total  CoinSupply
+}
+
+// txFees returns the difference between the input and output amounts of the
+// passed transactions.  This relies on the input amounts having been checked
+// against the spent outputs when the transactions were connected.
+func txFees(txns []*wire.MsgTx) int64 {
+	var fees int64
+	for _, tx := range txns {
+		for _, txIn := range tx.TxIn {
+			fees += txIn.ValueIn
+		}
+		for _, txOut := range tx.TxOut {
+			fees -= txOut.Value
+		}
+	}
+	return fees
+}
+
+// calcBlockCoinSupply returns the coin supply added by connecting the passed
+// block to the main chain, which matches CalculateAddedSubsidy.
+//
+// Since the regular transaction tree of a block is only applied once the next
+// block approves it, the subsidy and the fees of the regular transaction tree
+// of the parent, along with those of the stake transaction tree of the parent
+// which its coinbase may claim, are accounted for by the block approving it.
+// All fees of the parent are destroyed when the block disapproves it.  The
+// vote subsidy is accounted for by the block containing the votes.
+func calcBlockCoinSupply(block, parent *hcutil.Block,
+	params *chaincfg.Params) CoinSupply {
+
+	var supply CoinSupply
+	parentHeight := parent.Height()
+	parentStakeFees := txFees(parent.MsgBlock().STransactions)
+	if hcutil.IsFlagSet16(block.MsgBlock().Header.VoteBits, hcutil.BlockValid) {
+		txns := parent.MsgBlock().Transactions
+		coinbase := txns[0]
+		subsidy := coinbase.TxIn[0].ValueIn
+		switch {
+		case parentHeight == 1:
+			supply.BlockOne = subsidy
+		case parentHeight > 1 && params.BlockTaxProportion != 0 &&
+			len(coinbase.TxOut) > 0:
+			supply.Dev = coinbase.TxOut[0].Value
+			supply.PoW = subsidy - supply.Dev
+		default:
+			supply.PoW = subsidy
+		}
+
+		// The coinbase of the genesis block can not be spent and is not
+		// part of the utxo set, so its outputs are not counted as claimed
+		// fees.
+		if parentHeight > 0 {
+			supply.FeesDestroyed = txFees(txns) + parentStakeFees
+		}
+	} else {
+		supply.FeesDestroyed = parentStakeFees
+	}
+
+	for _, stx := range block.MsgBlock().STransactions {
+		if stake.DetermineTxType(stx) == stake.TxTypeSSGen {
+			supply.PoS += stx.TxIn[0].ValueIn
+		}
+	}
+
+	return supply
+}
+
+// serializeCoinSupplyEntry returns the serialization of the coin supply added
+// by a block followed by the total coin supply of the main chain ending at it.
+func serializeCoinSupplyEntry(block, total *CoinSupply) []byte {
+	serialized := make([]byte, coinSupplySerializeSize)
+	offset := 0
+	for _, s := range []*CoinSupply{block, total} {
+		for _, amount := range []int64{s.PoW, s.PoS, s.Dev, s.BlockOne,
+			s.FeesDestroyed} {
+			dbnamespace.ByteOrder.PutUint64(serialized[offset:],
+				uint64(amount))
+			offset += 8
+		}
+	}
+	return serialized
+}
+
+// deserializeCoinSupplyEntry decodes the coin supply added by a block and the
+// total coin supply of the main chain ending at it from the passed serialized
+// entry.
+func deserializeCoinSupplyEntry(serialized []byte) (CoinSupply, CoinSupply, error) {
+	if len(serialized) != coinSupplySerializeSize {
+		return CoinSupply{}, CoinSupply{}, errDeserialize(fmt.Sprintf(
+			"unexpected coin supply entry size; want %d, got %d",
+			coinSupplySerializeSize, len(serialized)))
+	}
+
+	var block, total CoinSupply
+	offset := 0
+	for _, s := range []*CoinSupply{&block, &total} {
+		for _, amount := range []*int64{&s.PoW, &s.PoS, &s.Dev,
+			&s.BlockOne, &s.FeesDestroyed} {
+			*amount = int64(dbnamespace.ByteOrder.Uint64(
+				serialized[offset:]))
+			offset += 8
+		}
+	}
+	return block, total, nil
+}
+
+// dbFetchCoinSupplyEntry uses an existing database transaction to fetch the
+// coin supply added by the block with the passed hash and the total coin
+// supply of the main chain ending at it.  An error that satisfies
+// IsNotInMainChainErr is returned when there is no entry for the block.
+func dbFetchCoinSupplyEntry(dbTx database.Tx, hash *chainhash.Hash) (CoinSupply, CoinSupply, error) {
+	bucket := dbTx.Metadata().Bucket(dbnamespace.CoinSupplyBucketName)
+	serialized := bucket.Get(hash[:])
+	if serialized == nil {
+		str := fmt.Sprintf("no coin supply for block %s in the main "+
+			"chain", hash)
+		return CoinSupply{}, CoinSupply{}, errNotInMainChain(str)
+	}
+
+	block, total, err := deserializeCoinSupplyEntry(serialized)
+	if err != nil {
+		return CoinSupply{}, CoinSupply{}, database.Error{
+			ErrorCode: database.ErrCorruption,
+			Description: fmt.Sprintf("corrupt coin supply for %v: %v",
+				hash, err),
+		}
+	}
+	return block, total, nil
+}
+
+// dbPutCoinSupplyEntry uses an existing database transaction to store the
+// coin supply added by connecting the passed block to the main chain along
+// with the resulting total coin supply, which is based on the entry of its
+// parent.
+func dbPutCoinSupplyEntry(dbTx database.Tx, block, parent *hcutil.Block,
+	params *chaincfg.Params) error {
+
+	_, total, err := dbFetchCoinSupplyEntry(dbTx, parent.Hash())
+	if err != nil {
+		return err
+	}
+	supply := calcBlockCoinSupply(block, parent, params)
+	total.add(&supply)
+
+	bucket := dbTx.Metadata().Bucket(dbnamespace.CoinSupplyBucketName)
+	return bucket.Put(block.Hash()[:], serializeCoinSupplyEntry(&supply,
+		&total))
+}
+
+// dbRemoveCoinSupplyEntry uses an existing database transaction to remove the
+// coin supply entry of the passed block.
+func dbRemoveCoinSupplyEntry(dbTx database.Tx, blockHash *chainhash.Hash) error {
+	bucket := dbTx.Metadata().Bucket(dbnamespace.CoinSupplyBucketName)
+	return bucket.Delete(blockHash[:])
+}
+
+// dbCreateCoinSupplyBucket uses an existing database transaction to create the
+// coin supply bucket along with the empty entry of the genesis block.
+func dbCreateCoinSupplyBucket(dbTx database.Tx, genesisHash *chainhash.Hash) error {
+	bucket, err := dbTx.Metadata().CreateBucket(dbnamespace.CoinSupplyBucketName)
+	if err != nil {
+		return err
+	}
+	var empty CoinSupply
+	return bucket.Put(genesisHash[:], serializeCoinSupplyEntry(&empty, &empty))
+}
+
+// CoinSupply returns the coin supply added by the main chain block with the
+// passed hash along with the total coin supply of the main chain ending at it.
+// The coin supply is maintained as blocks are connected and disconnected, so
+// this does not need to walk the chain.
+//
+// An error that satisfies IsNotInMainChainErr is returned when the block is
+// not in the main chain.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) CoinSupply(hash *chainhash.Hash) (*BlockCoinSupply, error) {
+	result := &BlockCoinSupply{Hash: *hash}
+	err := b.db.View(func(dbTx database.Tx) error {
+		var err error
+		result.Block, result.Total, err = dbFetchCoinSupplyEntry(dbTx, hash)
+		if err != nil {
+			return err
+		}
+		result.Height, err = dbFetchHeightByHash(dbTx, hash)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestCoinSupplyEntrySerialization ensures serializing and deserializing coin
+// supply entries works as expected.
+func TestCoinSupplyEntrySerialization(t *testing.T) {
+	t.Parallel()
+
+	block := CoinSupply{
+		PoW:           1500000000,
+		PoS:           300000000,
+		Dev:           200000000,
+		FeesDestroyed: 2530,
+	}
+	total := CoinSupply{
+		PoW:           9000000000000,
+		PoS:           1800000000000,
+		Dev:           1200000000000,
+		BlockOne:      168000000000000,
+		FeesDestroyed: 81259300,
+	}
+	serialized := serializeCoinSupplyEntry(&block, &total)
+	if len(serialized) != coinSupplySerializeSize {
+		t.Fatalf("unexpected serialized size: got %d, want %d",
+			len(serialized), coinSupplySerializeSize)
+	}
+	gotBlock, gotTotal, err := deserializeCoinSupplyEntry(serialized)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(gotBlock, block) {
+		t.Errorf("mismatched block supply: got %+v, want %+v",
+			gotBlock, block)
+	}
+	if !reflect.DeepEqual(gotTotal, total) {
+		t.Errorf("mismatched total supply: got %+v, want %+v",
+			gotTotal, total)
+	}
+	if got, want := gotTotal.Supply(), total.Subsidy()-81259300; got != want {
+		t.Errorf("unexpected supply: got %d, want %d", got, want)
+	}
+
+	// Ensure truncated entries are rejected.
+	_, _, err = deserializeCoinSupplyEntry(serialized[:len(serialized)-1])
+	if !isDeserializeErr(err) {
+		t.Errorf("expected deserialize error for truncated entry, got %v",
+			err)
+	}
+}
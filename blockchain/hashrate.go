@@ -0,0 +1,109 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// HashRateEstimate houses an estimate of the network hash rate over a range of
+// main chain blocks.
+type HashRateEstimate struct {
+	// StartHeight and EndHeight are the heights of the first and the last
+	// block of the range.  The work of the first block is not counted
+	// since it was performed before its timestamp.
+	StartHeight int64
+	EndHeight   int64
+
+	// Work is the total work of the blocks after the first block of the
+	// range and TimeSpan is the difference between the latest and the
+	// earliest timestamp of the blocks of the range.
+	Work     *big.Int
+	TimeSpan time.Duration
+}
+
+// HashesPerSec returns the estimated number of network hashes per second, or
+// zero when the timestamps of the blocks do not span at least a second.
+func (e *HashRateEstimate) HashesPerSec() int64 {
+	seconds := int64(e.TimeSpan / time.Second)
+	if seconds == 0 {
+		return 0
+	}
+	return new(big.Int).Div(e.Work, big.NewInt(seconds)).Int64()
+}
+
+// EstimateNetworkHashRate estimates the network hash rate from the main chain
+// blocks up to and including the block at the passed end height, which is the
+// current best block when it is negative.  The estimate covers the passed
+// number of blocks before the end block, or the blocks since the last
+// difficulty change when the number is not positive.
+//
+// The work of the range is the difference between the cumulative work of its
+// last and its first block, so only the timestamps of the blocks in between
+// are examined.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) EstimateNetworkHashRate(endHeight, numBlocks int64) (*HashRateEstimate, error) {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	if endHeight < 0 {
+		endHeight = b.bestNode.height
+	}
+	if endHeight > b.bestNode.height {
+		return nil, fmt.Errorf("height %d is after the current best "+
+			"height %d", endHeight, b.bestNode.height)
+	}
+
+	// Start at the last difficulty change when no number of blocks is
+	// given and make sure the range does not start before the genesis
+	// block.
+	var startHeight int64
+	if numBlocks <= 0 {
+		blocksPerRetarget := int64(b.chainParams.TargetTimespan /
+			b.chainParams.TargetTimePerBlock)
+		startHeight = endHeight - ((endHeight % blocksPerRetarget) + 1)
+	} else {
+		startHeight = endHeight - numBlocks
+	}
+	if startHeight < 0 {
+		startHeight = 0
+	}
+
+	endNode, err := b.ancestorNode(b.bestNode, endHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	// Find the earliest and the latest timestamp of the range.  Block
+	// timestamps are not required to increase, so the timestamps of all
+	// blocks of the range are examined.
+	minTimestamp := endNode.header.Timestamp
+	maxTimestamp := minTimestamp
+	startNode := endNode
+	for startNode.height > startHeight {
+		startNode, err = b.getPrevNodeFromNode(startNode)
+		if err != nil {
+			return nil, err
+		}
+
+		timestamp := startNode.header.Timestamp
+		if timestamp.Before(minTimestamp) {
+			minTimestamp = timestamp
+		}
+		if timestamp.After(maxTimestamp) {
+			maxTimestamp = timestamp
+		}
+	}
+
+	return &HashRateEstimate{
+		StartHeight: startHeight,
+		EndHeight:   endHeight,
+		Work:        new(big.Int).Sub(endNode.workSum, startNode.workSum),
+		TimeSpan:    maxTimestamp.Sub(minTimestamp),
+	}, nil
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/HcashOrg/hcd/chaincfg"
+)
+
+// TestEstimateNetworkHashRate ensures the network hash rate estimated from the
+// cumulative work of the blocks matches the work and timestamps of the blocks
+// in the estimated range.
+func TestEstimateNetworkHashRate(t *testing.T) {
+	params := &chaincfg.SimNetParams
+	bc := newFakeChain(params)
+	blocksPerRetarget := int64(params.TargetTimespan /
+		params.TargetTimePerBlock)
+
+	// Create a chain of blocks with varying difficulty which is longer than
+	// the default window and ends 5 blocks after a difficulty change.
+	// Every seventh block has a timestamp before the one of its parent,
+	// since block timestamps are not required to increase.
+	numBlocks := (150/blocksPerRetarget+1)*blocksPerRetarget + 5
+	node := bc.bestNode
+	timestamp := node.header.Timestamp
+	for i := int64(1); i <= numBlocks; i++ {
+		bits := params.PowLimitBits - uint32(i%3)
+		blockTime := timestamp.Add(params.TargetTimePerBlock)
+		if i%7 == 0 {
+			blockTime = timestamp.Add(-time.Minute)
+		}
+		node = newFakeNode(node, 1, 0, bits, blockTime)
+		bc.index[node.hash] = node
+		timestamp = node.header.Timestamp
+	}
+	bc.bestNode = node
+
+	// nodeAt returns the node at the passed height of the fake chain.
+	nodeAt := func(height int64) *blockNode {
+		n := bc.bestNode
+		for n.height > height {
+			n = n.parent
+		}
+		return n
+	}
+
+	tests := []struct {
+		name        string
+		endHeight   int64
+		numBlocks   int64
+		startHeight int64
+	}{
+		{"default window at best", -1, 120, numBlocks - 120},
+		{"since last difficulty change", -1, 0, numBlocks - 6},
+		{"window before best", 100, 10, 90},
+		{"window past genesis", 10, 120, 0},
+		{"single block", 20, 1, 19},
+	}
+	for _, test := range tests {
+		estimate, err := bc.EstimateNetworkHashRate(test.endHeight,
+			test.numBlocks)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if estimate.StartHeight != test.startHeight {
+			t.Errorf("%s: unexpected start height: got %d, want %d",
+				test.name, estimate.StartHeight, test.startHeight)
+			continue
+		}
+
+		// Calculate the work and time span from the blocks of the
+		// range.
+		wantWork := new(big.Int)
+		start := nodeAt(estimate.StartHeight)
+		minTime, maxTime := start.header.Timestamp, start.header.Timestamp
+		for h := estimate.StartHeight + 1; h <= estimate.EndHeight; h++ {
+			n := nodeAt(h)
+			wantWork.Add(wantWork, CalcWork(n.header.Bits))
+			if n.header.Timestamp.Before(minTime) {
+				minTime = n.header.Timestamp
+			}
+			if n.header.Timestamp.After(maxTime) {
+				maxTime = n.header.Timestamp
+			}
+		}
+		if estimate.Work.Cmp(wantWork) != 0 {
+			t.Errorf("%s: unexpected work: got %v, want %v",
+				test.name, estimate.Work, wantWork)
+		}
+		if want := maxTime.Sub(minTime); estimate.TimeSpan != want {
+			t.Errorf("%s: unexpected time span: got %v, want %v",
+				test.name, estimate.TimeSpan, want)
+		}
+		wantRate := new(big.Int).Div(wantWork,
+			big.NewInt(int64(maxTime.Sub(minTime)/time.Second)))
+		if got := estimate.HashesPerSec(); got != wantRate.Int64() {
+			t.Errorf("%s: unexpected hash rate: got %d, want %d",
+				test.name, got, wantRate.Int64())
+		}
+	}
+
+	// Heights after the best block must be rejected.
+	if _, err := bc.EstimateNetworkHashRate(numBlocks+1, 10); err == nil {
+		t.Errorf("did not receive expected error for height after best")
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/HcashOrg/hcd/database"
+	"github.com/HcashOrg/hcd/wire"
+)
+
+// headerRuleError returns the passed error with the index of the header which
+// caused it prepended to the description when it is a RuleError.
+func headerRuleError(idx int, err error) error {
+	if rErr, ok := err.(RuleError); ok {
+		rErr.Description = fmt.Sprintf("header %d: %s", idx,
+			rErr.Description)
+		return rErr
+	}
+	return err
+}
+
+// VerifyHeaderChain verifies the passed headers form a chain which extends a
+// block known to the node and that every header is valid according to the
+// proof of work, difficulty retarget, timestamp and checkpoint rules.  The
+// cumulative work of the chain up to and including the last header is
+// returned, which allows SPV implementations to cross-check their own header
+// validation against the node.
+//
+// The stake version rules depend on the votes of the blocks and are therefore
+// not checked.  The headers are neither stored nor added to the memory block
+// index.
+//
+// The returned error is a RuleError with the index of the offending header in
+// its description when a header violates the rules.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) VerifyHeaderChain(headers []wire.BlockHeader) (*big.Int, error) {
+	if len(headers) == 0 {
+		return nil, AssertError("VerifyHeaderChain called with no headers")
+	}
+
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	// The first header must build on a block known to the node.
+	prevHash := &headers[0].PrevBlock
+	exists, err := b.blockExists(prevHash)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		str := fmt.Sprintf("previous block %v of header 0 is unknown",
+			prevHash)
+		return nil, ruleError(ErrMissingParent, str)
+	}
+	prevNode, ok := b.index[*prevHash]
+	if !ok {
+		err := b.db.View(func(dbTx database.Tx) error {
+			var err error
+			prevNode, err = b.loadBlockNode(dbTx, prevHash)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range headers {
+		header := &headers[i]
+		if header.PrevBlock != prevNode.hash {
+			str := fmt.Sprintf("previous block %v does not match "+
+				"the hash %v of the preceding header",
+				header.PrevBlock, prevNode.hash)
+			return nil, headerRuleError(i, ruleError(ErrMissingParent,
+				str))
+		}
+		if int64(header.Height) != prevNode.height+1 {
+			str := fmt.Sprintf("block height of %d is not the "+
+				"expected height of %d", header.Height,
+				prevNode.height+1)
+			return nil, headerRuleError(i, ruleError(ErrBadBlockHeight,
+				str))
+		}
+
+		err := checkProofOfWork(header, b.chainParams.PowLimit, BFNone)
+		if err != nil {
+			return nil, headerRuleError(i, err)
+		}
+		err = checkBlockHeaderTime(header, b.timeSource)
+		if err != nil {
+			return nil, headerRuleError(i, err)
+		}
+		err = b.checkBlockHeaderPosition(header, prevNode, BFNone)
+		if err != nil {
+			return nil, headerRuleError(i, err)
+		}
+
+		// Link a temporary node for the header to the previous node so
+		// the checks of the following headers can reach it.
+		node := newBlockNode(header, nil, nil, nil)
+		node.parent = prevNode
+		node.workSum.Add(prevNode.workSum, node.workSum)
+		prevNode = node
+	}
+
+	return new(big.Int).Set(prevNode.workSum), nil
+}
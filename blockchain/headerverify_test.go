@@ -0,0 +1,116 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/HcashOrg/hcd/chaincfg"
+	"github.com/HcashOrg/hcd/wire"
+)
+
+// TestVerifyHeaderChain ensures a valid chain of headers is accepted with its
+// cumulative work and that headers which violate the rules are rejected.
+func TestVerifyHeaderChain(t *testing.T) {
+	params := &chaincfg.SimNetParams
+	bc := newFakeChain(params)
+	bc.timeSource = NewMedianTime()
+
+	// Create a chain of valid headers which spans a difficulty retarget
+	// on top of the genesis block.
+	const numHeaders = 20
+	headers := make([]wire.BlockHeader, 0, numHeaders)
+	prevNode := bc.bestNode
+	for i := 0; i < numHeaders; i++ {
+		timestamp := prevNode.header.Timestamp.Add(params.TargetTimePerBlock)
+		bits, err := bc.calcNextRequiredDifficulty(prevNode, timestamp)
+		if err != nil {
+			t.Fatalf("calcNextRequiredDifficulty: %v", err)
+		}
+		header := wire.BlockHeader{
+			Version:   1,
+			PrevBlock: prevNode.hash,
+			Bits:      bits,
+			Height:    uint32(prevNode.height) + 1,
+			Timestamp: timestamp,
+		}
+		for checkProofOfWork(&header, params.PowLimit, BFNone) != nil {
+			header.Nonce++
+		}
+		headers = append(headers, header)
+
+		node := newBlockNode(&header, nil, nil, nil)
+		node.parent = prevNode
+		node.workSum.Add(prevNode.workSum, node.workSum)
+		prevNode = node
+	}
+
+	work, err := bc.VerifyHeaderChain(headers)
+	if err != nil {
+		t.Fatalf("VerifyHeaderChain: unexpected error: %v", err)
+	}
+	if work.Cmp(prevNode.workSum) != 0 {
+		t.Fatalf("VerifyHeaderChain: unexpected work: got %v, want %v",
+			work, prevNode.workSum)
+	}
+
+	// The headers must not have been added to the block index.
+	if _, ok := bc.index[headers[0].BlockHash()]; ok {
+		t.Fatalf("VerifyHeaderChain: header added to block index")
+	}
+
+	tests := []struct {
+		name   string
+		idx    int
+		modify func(*wire.BlockHeader)
+		code   ErrorCode
+	}{
+		{"unlinked header", 5, func(h *wire.BlockHeader) {
+			h.PrevBlock = headers[3].BlockHash()
+		}, ErrMissingParent},
+		{"wrong height", 5, func(h *wire.BlockHeader) {
+			h.Height++
+		}, ErrBadBlockHeight},
+		{"wrong difficulty", 5, func(h *wire.BlockHeader) {
+			h.Bits--
+		}, ErrUnexpectedDifficulty},
+		{"timestamp too old", 5, func(h *wire.BlockHeader) {
+			h.Timestamp = headers[0].Timestamp
+		}, ErrTimeTooOld},
+		{"timestamp too new", numHeaders - 1, func(h *wire.BlockHeader) {
+			h.Timestamp = h.Timestamp.AddDate(100, 0, 0)
+		}, ErrTimeTooNew},
+	}
+	for _, test := range tests {
+		modified := make([]wire.BlockHeader, test.idx+1)
+		copy(modified, headers)
+		test.modify(&modified[test.idx])
+
+		// Make the modified header satisfy its proof of work unless
+		// the test is about its difficulty.
+		header := &modified[test.idx]
+		target := CompactToBig(header.Bits)
+		for target.Cmp(params.PowLimit) <= 0 &&
+			checkProofOfWork(header, params.PowLimit, BFNone) != nil {
+			header.Nonce++
+		}
+
+		_, err := bc.VerifyHeaderChain(modified)
+		rErr, ok := err.(RuleError)
+		if !ok {
+			t.Errorf("%s: did not receive expected rule error: %v",
+				test.name, err)
+			continue
+		}
+		if rErr.ErrorCode != test.code {
+			t.Errorf("%s: unexpected error code: got %v, want %v",
+				test.name, rErr.ErrorCode, test.code)
+		}
+	}
+
+	if _, err := bc.VerifyHeaderChain(nil); err == nil {
+		t.Errorf("did not receive expected error for no headers")
+	}
+}
@@ -0,0 +1,388 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package indexers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/HcashOrg/hcd/blockchain"
+	"github.com/HcashOrg/hcd/blockchain/internal/progresslog"
+	"github.com/HcashOrg/hcd/chaincfg/chainhash"
+	"github.com/HcashOrg/hcd/database"
+	"github.com/HcashOrg/hcd/hcutil"
+)
+
+// errCatchUpInterrupted is returned by a catch up which was interrupted before
+// it was done.
+var errCatchUpInterrupted = errors.New("index catch up interrupted")
+
+// indexCatchUp tracks the indexes which are caught up to the main chain in the
+// background while the chain keeps connecting and disconnecting blocks.  This
+// is the case for the indexes which are behind the chain when they are loaded
+// and for the indexes which are rebuilt.
+//
+// The indexes of a catch up are only updated along with the chain once their
+// tip reaches the block being connected or disconnected.  Until then they are
+// caught up one block per database transaction, reading the blocks of the main
+// chain within that same transaction, so both paths always agree on the tip
+// of each index.
+type indexCatchUp struct {
+	indexes []Indexer
+
+	// rebuild is set when the indexes are dropped and created again before
+	// they are caught up from the genesis block.
+	rebuild bool
+
+	// started is set once the catch up runs.
+	started bool
+
+	// dropping is set while the entries of the indexes of a rebuild are
+	// deleted and the indexes created again.  Blocks are not indexed in
+	// the meantime.
+	dropping bool
+
+	// err is the error which stopped the catch up before it was done.  The
+	// indexes are left behind the chain, so they keep being skipped until
+	// they are rebuilt or caught up by Init on the next start.
+	err error
+}
+
+// has returns whether or not the passed index is part of the catch up.
+func (c *indexCatchUp) has(indexer Indexer) bool {
+	for _, idx := range c.indexes {
+		if bytes.Equal(idx.Key(), indexer.Key()) {
+			return true
+		}
+	}
+	return false
+}
+
+// action returns what the catch up does to its indexes for messages.
+func (c *indexCatchUp) action() string {
+	if c.rebuild {
+		return "rebuilt"
+	}
+	return "caught up"
+}
+
+// IndexStatus describes the state of an index managed by the index manager.
+type IndexStatus struct {
+	// Height is the height of the last block indexed.
+	Height int32
+
+	// CatchingUp is set while the index is behind the chain and caught up
+	// in the background, either after it was loaded or after it was
+	// dropped to be rebuilt.
+	CatchingUp bool
+
+	// Rebuilding is set while the index is rebuilt in the background.
+	Rebuilding bool
+
+	// Err is the error which stopped the last catch up of the index before
+	// it was done, if any.
+	Err error
+}
+
+// isEnabled returns whether or not the passed index is enabled.
+func (m *Manager) isEnabled(indexer Indexer) bool {
+	for _, idx := range m.enabledIndexes {
+		if bytes.Equal(idx.Key(), indexer.Key()) {
+			return true
+		}
+	}
+	return false
+}
+
+// skipCatchingUp returns whether or not the passed index must be left alone
+// while a block is connected or disconnected because it is being caught up
+// and its tip is not the passed hash yet.
+//
+// This function MUST be called with the database transaction which connects or
+// disconnects the block.
+func (m *Manager) skipCatchingUp(dbTx database.Tx, indexer Indexer, tip *chainhash.Hash) (bool, error) {
+	m.mtx.Lock()
+	catchingUp := m.catchUp != nil && m.catchUp.has(indexer)
+	dropping := catchingUp && m.catchUp.dropping
+	m.mtx.Unlock()
+	if !catchingUp {
+		return false, nil
+	}
+	if dropping {
+		return true, nil
+	}
+
+	curTipHash, _, err := dbFetchIndexerTip(dbTx, indexer.Key())
+	if err != nil {
+		return false, err
+	}
+	return !curTipHash.IsEqual(tip), nil
+}
+
+// IndexStatus returns the state of the passed index, which must be enabled.
+//
+// This function is safe for concurrent access.
+func (m *Manager) IndexStatus(indexer Indexer) (*IndexStatus, error) {
+	if !m.isEnabled(indexer) {
+		return nil, fmt.Errorf("the %s is not enabled", indexer.Name())
+	}
+
+	var status IndexStatus
+	m.mtx.Lock()
+	if c := m.catchUp; c != nil && c.has(indexer) {
+		status.CatchingUp = c.err == nil
+		status.Rebuilding = c.err == nil && c.rebuild
+		status.Err = c.err
+	}
+	m.mtx.Unlock()
+
+	// The index has no tip while it is dropped.
+	err := m.db.View(func(dbTx database.Tx) error {
+		indexesBucket := dbTx.Metadata().Bucket(indexTipsBucketName)
+		if indexesBucket == nil || indexesBucket.Get(indexer.Key()) == nil {
+			return nil
+		}
+		_, height, err := dbFetchIndexerTip(dbTx, indexer.Key())
+		status.Height = height
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// CatchUp catches the indexes which were behind the chain when they were
+// loaded by Init up to the main chain in the background.  Blocks connected in
+// the meantime are only indexed once the indexes reached them, so it is best
+// called once the chain is synced to avoid competing with the initial block
+// download for the database.
+//
+// The catch up runs until it is done or the interrupt channel is closed, and
+// the returned channel receives its result once it stops.  The returned
+// channel is nil when there is nothing to catch up.
+//
+// This function is safe for concurrent access.
+func (m *Manager) CatchUp(interrupt <-chan struct{}) <-chan error {
+	m.mtx.Lock()
+	c := m.catchUp
+	if c == nil || c.started {
+		m.mtx.Unlock()
+		return nil
+	}
+	c.started = true
+	m.mtx.Unlock()
+
+	return m.startCatchUp(c, interrupt)
+}
+
+// StartRebuild drops the passed index and rebuilds it from the genesis block
+// in the background while the chain keeps processing blocks.  The address
+// index refers to the internal block IDs of the transaction index, so it is
+// rebuilt along with the transaction index when it is enabled.  Indexes may
+// not be rebuilt while other indexes are caught up.
+//
+// The rebuild runs until it is done or the interrupt channel is closed, and
+// the returned channel receives its result once it stops.  An interrupted
+// rebuild is finished in the background the next time the indexes are loaded.
+//
+// This function is safe for concurrent access.
+func (m *Manager) StartRebuild(indexer Indexer, interrupt <-chan struct{}) (<-chan error, error) {
+	if !m.isEnabled(indexer) {
+		return nil, fmt.Errorf("the %s is not enabled", indexer.Name())
+	}
+
+	indexes := []Indexer{indexer}
+	if bytes.Equal(indexer.Key(), txIndexKey) {
+		for _, idx := range m.enabledIndexes {
+			if bytes.Equal(idx.Key(), addrIndexKey) {
+				indexes = append(indexes, idx)
+			}
+		}
+	}
+
+	c := &indexCatchUp{
+		indexes:  indexes,
+		rebuild:  true,
+		started:  true,
+		dropping: true,
+	}
+	m.mtx.Lock()
+	if prev := m.catchUp; prev != nil {
+		if prev.err == nil {
+			m.mtx.Unlock()
+			return nil, fmt.Errorf("the %s is already being %s",
+				prev.indexes[0].Name(), prev.action())
+		}
+
+		// The indexes of a catch up which stopped early are behind the
+		// chain, so they may only be replaced by a rebuild which covers
+		// them again.
+		for _, idx := range prev.indexes {
+			if !c.has(idx) {
+				m.mtx.Unlock()
+				return nil, fmt.Errorf("the %s must be rebuilt "+
+					"first since it could not be %s",
+					idx.Name(), prev.action())
+			}
+		}
+	}
+	m.catchUp = c
+	m.mtx.Unlock()
+
+	return m.startCatchUp(c, interrupt), nil
+}
+
+// startCatchUp runs the passed catch up in the background and returns a
+// channel which receives its result once it stops.
+func (m *Manager) startCatchUp(c *indexCatchUp, interrupt <-chan struct{}) <-chan error {
+	done := make(chan error, 1)
+	go func() {
+		err := m.runCatchUp(c, interrupt)
+		if err != nil {
+			m.mtx.Lock()
+			c.err = err
+			m.mtx.Unlock()
+		}
+		done <- err
+	}()
+	return done
+}
+
+// dropAndCreate drops the indexes of the passed rebuild and creates them
+// again.
+func (m *Manager) dropAndCreate(c *indexCatchUp) error {
+	// Drop the dependent indexes first, like DropTxIndex does, so an
+	// interrupted drop never leaves an address index without the block IDs
+	// it refers to.
+	for i := len(c.indexes); i > 0; i-- {
+		indexer := c.indexes[i-1]
+		if err := dropIndex(m.db, indexer.Key(), indexer.Name()); err != nil {
+			return err
+		}
+	}
+	err := m.db.Update(func(dbTx database.Tx) error {
+		return m.maybeCreateIndexes(dbTx)
+	})
+	if err != nil {
+		return err
+	}
+	for _, indexer := range c.indexes {
+		if err := indexer.Init(); err != nil {
+			return err
+		}
+	}
+
+	m.mtx.Lock()
+	c.dropping = false
+	m.mtx.Unlock()
+	return nil
+}
+
+// runCatchUp catches the indexes of the passed catch up up to the end of the
+// main chain, after dropping and creating them again for a rebuild.
+func (m *Manager) runCatchUp(c *indexCatchUp, interrupt <-chan struct{}) error {
+	if c.rebuild {
+		if err := m.dropAndCreate(c); err != nil {
+			return err
+		}
+	}
+
+	name := c.indexes[0].Name()
+	if len(c.indexes) > 1 {
+		name = "indexes"
+	}
+	log.Infof("Catching up the %s in the background", name)
+	progressLogger := progresslog.NewBlockProgressLogger("Indexed", log)
+	for {
+		select {
+		case <-interrupt:
+			log.Infof("Interrupted catching up the %s", name)
+			return errCatchUpInterrupted
+		default:
+		}
+
+		var block, parent *hcutil.Block
+		err := m.db.Update(func(dbTx database.Tx) error {
+			var err error
+			block, parent, err = m.catchUpNextBlock(dbTx, c)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		if block == nil {
+			break
+		}
+		progressLogger.LogBlockHeight(block.MsgBlock(), parent.MsgBlock())
+	}
+
+	log.Infof("Caught up the %s", name)
+	return nil
+}
+
+// catchUpNextBlock connects the block after the lowest tip of the indexes of
+// the passed catch up to the indexes whose tip is its parent, and returns it
+// along with its parent.  Each index thus follows its own tip until it reaches
+// the end of the main chain, from where it is updated along with the chain.
+// The catch up is done when all of the indexes reached the end of the main
+// chain, in which case it is removed from the manager within the same
+// transaction and nil blocks are returned.
+//
+// This function MUST be called with a writable database transaction.
+func (m *Manager) catchUpNextBlock(dbTx database.Tx, c *indexCatchUp) (*hcutil.Block, *hcutil.Block, error) {
+	tips := make([]*chainhash.Hash, len(c.indexes))
+	lowestHeight := int32(math.MaxInt32)
+	for i, indexer := range c.indexes {
+		hash, height, err := dbFetchIndexerTip(dbTx, indexer.Key())
+		if err != nil {
+			return nil, nil, err
+		}
+		tips[i] = hash
+		if height < lowestHeight {
+			lowestHeight = height
+		}
+	}
+
+	block, err := blockchain.DBFetchBlockByHeight(dbTx, int64(lowestHeight)+1)
+	if blockchain.IsNotInMainChainErr(err) {
+		m.mtx.Lock()
+		if m.catchUp == c {
+			m.catchUp = nil
+		}
+		m.mtx.Unlock()
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	parent, err := blockchain.DBFetchBlockByHeight(dbTx, int64(lowestHeight))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Connect the block to the indexes in order since later indexes can
+	// depend on earlier ones.
+	var view *blockchain.UtxoViewpoint
+	prevHash := &block.MsgBlock().Header.PrevBlock
+	for i, indexer := range c.indexes {
+		if !tips[i].IsEqual(prevHash) {
+			continue
+		}
+		if view == nil && indexNeedsInputs(indexer) {
+			view, err = makeUtxoView(dbTx, block, parent)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		err := dbIndexConnectBlock(dbTx, indexer, block, parent, view)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return block, parent, nil
+}
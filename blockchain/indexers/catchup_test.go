@@ -0,0 +1,90 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package indexers
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/HcashOrg/hcd/chaincfg"
+)
+
+// TestStartRebuildRefused ensures rebuilds are refused for indexes which are
+// not enabled, while another catch up is pending or runs, and when they would
+// leave the indexes of a failed catch up behind the chain.  None of these cases
+// may touch the database.
+func TestStartRebuildRefused(t *testing.T) {
+	params := &chaincfg.SimNetParams
+	txIndex := NewTxIndex(nil)
+	addrIndex := NewAddrIndex(nil, params)
+	existsAddrIndex := NewExistsAddrIndex(nil, params)
+	m := NewManager(nil, []Indexer{txIndex, addrIndex}, params)
+
+	tests := []struct {
+		name    string
+		catchUp *indexCatchUp
+		indexer Indexer
+	}{{
+		name:    "index not enabled",
+		indexer: existsAddrIndex,
+	}, {
+		name: "rebuild in progress",
+		catchUp: &indexCatchUp{
+			indexes: []Indexer{addrIndex},
+			rebuild: true,
+			started: true,
+		},
+		indexer: txIndex,
+	}, {
+		name:    "catch up pending",
+		catchUp: &indexCatchUp{indexes: []Indexer{addrIndex}},
+		indexer: txIndex,
+	}, {
+		name: "failed rebuild not covered",
+		catchUp: &indexCatchUp{
+			indexes: []Indexer{txIndex, addrIndex},
+			rebuild: true,
+			started: true,
+			err:     errors.New("failed"),
+		},
+		indexer: addrIndex,
+	}, {
+		name: "failed catch up not covered",
+		catchUp: &indexCatchUp{
+			indexes: []Indexer{txIndex, addrIndex},
+			started: true,
+			err:     errors.New("failed"),
+		},
+		indexer: addrIndex,
+	}}
+	for _, test := range tests {
+		m.catchUp = test.catchUp
+		if _, err := m.StartRebuild(test.indexer, nil); err == nil {
+			t.Errorf("%s: rebuild of the %s was not refused",
+				test.name, test.indexer.Name())
+		}
+		if m.catchUp != test.catchUp {
+			t.Errorf("%s: rebuild replaced although it was refused",
+				test.name)
+		}
+	}
+}
+
+// TestCatchUpNothingPending ensures CatchUp does nothing when no indexes are
+// behind the chain or their catch up already started.
+func TestCatchUpNothingPending(t *testing.T) {
+	params := &chaincfg.SimNetParams
+	txIndex := NewTxIndex(nil)
+	m := NewManager(nil, []Indexer{txIndex}, params)
+
+	if done := m.CatchUp(nil); done != nil {
+		t.Fatal("catch up started without indexes behind the chain")
+	}
+
+	m.catchUp = &indexCatchUp{indexes: []Indexer{txIndex}, started: true}
+	if done := m.CatchUp(nil); done != nil {
+		t.Fatal("catch up started twice")
+	}
+}
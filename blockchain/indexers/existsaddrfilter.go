@@ -0,0 +1,89 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package indexers
+
+import (
+	"encoding/binary"
+)
+
+const (
+	// existsAddrFilterMinCapacity is the minimum number of address keys
+	// the exists address filter is sized for.
+	existsAddrFilterMinCapacity = 1 << 16
+
+	// existsAddrFilterBitsPerKey and existsAddrFilterHashFuncs are the
+	// number of bits per address key and the number of hash functions of
+	// the exists address filter, which result in a false positive rate of
+	// about 1% while the filter is within its capacity.
+	existsAddrFilterBitsPerKey = 10
+	existsAddrFilterHashFuncs  = 7
+)
+
+// existsAddrFilter is an in-memory bloom filter of the address keys stored in
+// the exists address index.  It allows queries for addresses which have never
+// been seen, which is the common case for wallets scanning for unused
+// addresses, to be answered without accessing the database.
+//
+// The filter never reports a key it contains as missing, but it may report a
+// key it does not contain as present, so positive results must be confirmed
+// against the database.
+type existsAddrFilter struct {
+	bits     []uint64
+	numKeys  uint64
+	capacity uint64
+}
+
+// newExistsAddrFilter returns an empty exists address filter sized for at
+// least the passed number of address keys.
+func newExistsAddrFilter(capacity uint64) *existsAddrFilter {
+	if capacity < existsAddrFilterMinCapacity {
+		capacity = existsAddrFilterMinCapacity
+	}
+	numBits := capacity * existsAddrFilterBitsPerKey
+	return &existsAddrFilter{
+		bits:     make([]uint64, (numBits+63)/64),
+		capacity: capacity,
+	}
+}
+
+// hashes returns the two hashes of the passed address key which the positions
+// of its bits are derived from.  Address keys consist of a type byte followed
+// by a hash160, so the hashes are taken from the key directly.
+func (f *existsAddrFilter) hashes(k *[addrKeySize]byte) (uint64, uint64) {
+	h1 := binary.LittleEndian.Uint64(k[1:9]) ^ uint64(k[0])
+	h2 := binary.LittleEndian.Uint64(k[9:17]) | 1
+	return h1, h2
+}
+
+// add adds the passed address key to the filter.
+func (f *existsAddrFilter) add(k *[addrKeySize]byte) {
+	numBits := uint64(len(f.bits)) * 64
+	h1, h2 := f.hashes(k)
+	for i := uint64(0); i < existsAddrFilterHashFuncs; i++ {
+		bit := (h1 + i*h2) % numBits
+		f.bits[bit/64] |= 1 << (bit % 64)
+	}
+	f.numKeys++
+}
+
+// mayContain returns whether the passed address key may have been added to
+// the filter.  It never returns false for a key which was added.
+func (f *existsAddrFilter) mayContain(k *[addrKeySize]byte) bool {
+	numBits := uint64(len(f.bits)) * 64
+	h1, h2 := f.hashes(k)
+	for i := uint64(0); i < existsAddrFilterHashFuncs; i++ {
+		bit := (h1 + i*h2) % numBits
+		if f.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// full returns whether more keys than the filter is sized for were added to
+// it, which raises its false positive rate.
+func (f *existsAddrFilter) full() bool {
+	return f.numKeys > f.capacity
+}
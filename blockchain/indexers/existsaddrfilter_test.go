@@ -0,0 +1,72 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package indexers
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"testing"
+)
+
+// testAddrKey returns a deterministic address key for the passed index.
+func testAddrKey(i uint32) [addrKeySize]byte {
+	var seed [4]byte
+	binary.LittleEndian.PutUint32(seed[:], i)
+	hash := sha256.Sum256(seed[:])
+
+	var k [addrKeySize]byte
+	k[0] = addrKeyTypePubKeyHash
+	copy(k[1:], hash[:])
+	return k
+}
+
+// TestExistsAddrFilter ensures the exists address filter never reports added
+// keys as missing and keeps its false positive rate low within its capacity.
+func TestExistsAddrFilter(t *testing.T) {
+	t.Parallel()
+
+	const numKeys = existsAddrFilterMinCapacity
+	filter := newExistsAddrFilter(numKeys)
+	for i := uint32(0); i < numKeys; i++ {
+		k := testAddrKey(i)
+		filter.add(&k)
+	}
+	if filter.full() {
+		t.Fatalf("filter is full after adding %d keys", numKeys)
+	}
+
+	for i := uint32(0); i < numKeys; i++ {
+		k := testAddrKey(i)
+		if !filter.mayContain(&k) {
+			t.Fatalf("filter does not contain added key %d", i)
+		}
+	}
+
+	// Keys which were not added, including ones which only differ from an
+	// added key in their type, should rarely be reported as present.
+	k := testAddrKey(0)
+	k[0] = addrKeyTypeScriptHash
+	var falsePositives int
+	if filter.mayContain(&k) {
+		falsePositives++
+	}
+
+	for i := uint32(numKeys); i < 2*numKeys; i++ {
+		k := testAddrKey(i)
+		if filter.mayContain(&k) {
+			falsePositives++
+		}
+	}
+	if rate := float64(falsePositives) / numKeys; rate > 0.02 {
+		t.Errorf("unexpected false positive rate: got %.4f, want at "+
+			"most 0.02", rate)
+	}
+
+	k = testAddrKey(2 * numKeys)
+	filter.add(&k)
+	if !filter.full() {
+		t.Errorf("filter is not full after adding %d keys", numKeys+1)
+	}
+}
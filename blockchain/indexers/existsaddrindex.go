@@ -58,6 +58,13 @@ type ExistsAddrIndex struct {
 	// once they are included into a block.
 	unconfirmedLock sync.RWMutex
 	mpExistsAddr    map[[addrKeySize]byte]struct{}
+
+	// filter is an in-memory bloom filter of the address keys stored in
+	// the database, which allows most queries for addresses that were
+	// never seen to skip the database.  It is protected by the filterLock
+	// field.
+	filterLock sync.RWMutex
+	filter     *existsAddrFilter
 }
 
 // NewExistsAddrIndex returns a new instance of an indexer that is used to
@@ -88,13 +95,71 @@ var _ Indexer = (*ExistsAddrIndex)(nil)
 // 	return false
 // }
 
-// Init is only provided to satisfy the Indexer interface as there is nothing to
-// initialize for this index.
+// Init loads the address keys stored in the database into the in-memory
+// filter of the index.
 //
 // This is part of the Indexer interface.
 func (idx *ExistsAddrIndex) Init() error {
-	// Nothing to do.
-	return nil
+	return idx.db.View(func(dbTx database.Tx) error {
+		bucket := dbTx.Metadata().Bucket(existsAddrIndexKey)
+		filter, err := loadExistsAddrFilter(bucket)
+		if err != nil {
+			return err
+		}
+
+		log.Infof("Loaded %d addresses into the exists address filter",
+			filter.numKeys)
+		idx.filterLock.Lock()
+		idx.filter = filter
+		idx.filterLock.Unlock()
+		return nil
+	})
+}
+
+// loadExistsAddrFilter returns a filter of all address keys in the passed
+// bucket, sized for twice their number so it does not need to be rebuilt
+// again soon.
+func loadExistsAddrFilter(bucket database.Bucket) (*existsAddrFilter, error) {
+	var numKeys uint64
+	err := bucket.ForEach(func(k, v []byte) error {
+		numKeys++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	filter := newExistsAddrFilter(numKeys * 2)
+	err = bucket.ForEach(func(k, v []byte) error {
+		if len(k) != addrKeySize {
+			return nil
+		}
+		var key [addrKeySize]byte
+		copy(key[:], k)
+		filter.add(&key)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return filter, nil
+}
+
+// mayExistInDB returns whether the passed address key may be stored in the
+// database according to the in-memory filter.  Keys for which it returns false
+// are not stored in the database.
+//
+// This function is safe for concurrent access.
+func (idx *ExistsAddrIndex) mayExistInDB(k *[addrKeySize]byte) bool {
+	idx.filterLock.RLock()
+	defer idx.filterLock.RUnlock()
+
+	// All keys may exist until the filter is loaded.
+	if idx.filter == nil {
+		return true
+	}
+	return idx.filter.mayContain(k)
 }
 
 // Key returns the database key to use for the index as a byte slice.
@@ -149,16 +214,20 @@ func (idx *ExistsAddrIndex) ExistsAddress(addr hcutil.Address) (bool, error) {
 		return false, err
 	}
 
+	// Only check the database if the filter does not rule the address
+	// out.
 	var exists bool
-	err = idx.db.View(func(dbTx database.Tx) error {
-		meta := dbTx.Metadata()
-		existsAddrIndex := meta.Bucket(existsAddrIndexKey)
-		exists = existsAddrIndex.Get(k[:]) != nil
+	if idx.mayExistInDB(&k) {
+		err = idx.db.View(func(dbTx database.Tx) error {
+			meta := dbTx.Metadata()
+			existsAddrIndex := meta.Bucket(existsAddrIndexKey)
+			exists = existsAddrIndex.Get(k[:]) != nil
 
-		return nil
-	})
-	if err != nil {
-		return false, err
+			return nil
+		})
+		if err != nil {
+			return false, err
+		}
 	}
 
 	// Only check the in memory map if needed.
@@ -184,18 +253,27 @@ func (idx *ExistsAddrIndex) ExistsAddresses(addrs []hcutil.Address) ([]bool, err
 		}
 	}
 
-	err := idx.db.View(func(dbTx database.Tx) error {
-		for i := range addrKeys {
+	// Only check the database for the addresses the filter does not rule
+	// out, which are usually few when scanning for unused addresses.
+	maybe := make([]int, 0, len(addrKeys))
+	for i := range addrKeys {
+		if idx.mayExistInDB(&addrKeys[i]) {
+			maybe = append(maybe, i)
+		}
+	}
+	if len(maybe) > 0 {
+		err := idx.db.View(func(dbTx database.Tx) error {
 			meta := dbTx.Metadata()
 			existsAddrIndex := meta.Bucket(existsAddrIndexKey)
+			for _, i := range maybe {
+				exists[i] = existsAddrIndex.Get(addrKeys[i][:]) != nil
+			}
 
-			exists[i] = existsAddrIndex.Get(addrKeys[i][:]) != nil
+			return nil
+		})
+		if err != nil {
+			return nil, err
 		}
-
-		return nil
-	})
-	if err != nil {
-		return nil, err
 	}
 
 	idx.unconfirmedLock.RLock()
@@ -317,6 +395,26 @@ func (idx *ExistsAddrIndex) ConnectBlock(dbTx database.Tx, block, parent *hcutil
 		}
 	}
 
+	// Add the new addresses to the filter, and rebuild it from the
+	// database once it holds more addresses than it is sized for.  Should
+	// the database transaction fail, the filter merely holds addresses
+	// which are not in the database, which it allows for anyway.
+	idx.filterLock.Lock()
+	defer idx.filterLock.Unlock()
+	if idx.filter == nil {
+		return nil
+	}
+	for k := range newUsedAddrs {
+		idx.filter.add(&k)
+	}
+	if idx.filter.full() {
+		filter, err := loadExistsAddrFilter(existsAddrIndex)
+		if err != nil {
+			return err
+		}
+		idx.filter = filter
+	}
+
 	return nil
 }
 
@@ -9,9 +9,9 @@ package indexers
 import (
 	"bytes"
 	"fmt"
+	"sync"
 
 	"github.com/HcashOrg/hcd/blockchain"
-	"github.com/HcashOrg/hcd/blockchain/internal/progresslog"
 	"github.com/HcashOrg/hcd/blockchain/stake"
 	"github.com/HcashOrg/hcd/chaincfg"
 	"github.com/HcashOrg/hcd/chaincfg/chainhash"
@@ -134,6 +134,11 @@ type Manager struct {
 	params         *chaincfg.Params
 	db             database.DB
 	enabledIndexes []Indexer
+
+	// catchUp tracks the indexes which are caught up or rebuilt in the
+	// background, if any.  It is protected by mtx.
+	mtx     sync.Mutex
+	catchUp *indexCatchUp
 }
 
 // Ensure the Manager type implements the blockchain.IndexManager interface.
@@ -225,11 +230,14 @@ func (m *Manager) maybeCreateIndexes(dbTx database.Tx) error {
 }
 
 // Init initializes the enabled indexes.  This is called during chain
-// initialization and primarily consists of catching up all indexes to the
-// current best chain tip.  This is necessary since each index can be disabled
-// and re-enabled at any time and attempting to catch-up indexes at the same
-// time new blocks are being downloaded would lead to an overall longer time to
-// catch up due to the I/O contention.
+// initialization and consists of rolling back the indexes whose tip is an
+// orphaned block and finding the indexes which are behind the current best
+// chain tip.  This is necessary since each index can be disabled and
+// re-enabled at any time.  The indexes which are behind are not caught up
+// here, since attempting to catch-up indexes at the same time new blocks are
+// being downloaded would lead to an overall longer time to catch up due to the
+// I/O contention.  Instead they are skipped until CatchUp is invoked once the
+// chain is synced.
 //
 // This is part of the blockchain.IndexManager interface.
 func (m *Manager) Init(chain *blockchain.BlockChain) error {
@@ -357,15 +365,12 @@ func (m *Manager) Init(chain *blockchain.BlockChain) error {
 		}
 	}
 
-	// Fetch the current tip heights for each index along with tracking the
-	// lowest one so the catchup code only needs to start at the earliest
-	// block and is able to skip connecting the block for the indexes that
-	// don't need it.
+	// Fetch the current tip heights for each index to find the ones which
+	// are behind the current best chain tip.
 	bestHeight := int32(chain.BestSnapshot().Height)
-	lowestHeight := bestHeight
-	indexerHeights := make([]int32, len(m.enabledIndexes))
+	var behind []Indexer
 	err = m.db.View(func(dbTx database.Tx) error {
-		for i, indexer := range m.enabledIndexes {
+		for _, indexer := range m.enabledIndexes {
 			idxKey := indexer.Key()
 			hash, height, err := dbFetchIndexerTip(dbTx, idxKey)
 			if err != nil {
@@ -374,9 +379,8 @@ func (m *Manager) Init(chain *blockchain.BlockChain) error {
 
 			log.Debugf("Current %s tip (height %d, hash %v)",
 				indexer.Name(), height, hash)
-			indexerHeights[i] = height
-			if height < lowestHeight {
-				lowestHeight = height
+			if height < bestHeight {
+				behind = append(behind, indexer)
 			}
 		}
 		return nil
@@ -386,81 +390,20 @@ func (m *Manager) Init(chain *blockchain.BlockChain) error {
 	}
 
 	// Nothing to index if all of the indexes are caught up.
-	if lowestHeight == bestHeight {
+	if len(behind) == 0 {
 		return nil
 	}
 
-	// Create a progress logger for the indexing process below.
-	progressLogger := progresslog.NewBlockProgressLogger("Indexed", log)
-
 	// At this point, one or more indexes are behind the current best chain
-	// tip and need to be caught up, so log the details and loop through
-	// each block that needs to be indexed.
-	log.Infof("Catching up indexes from height %d to %d", lowestHeight,
-		bestHeight)
-
-	var cachedParent *hcutil.Block
-	for height := lowestHeight + 1; height <= bestHeight; height++ {
-		var block, parent *hcutil.Block
-		err = m.db.Update(func(dbTx database.Tx) error {
-			// Get the parent of the block, unless it's already cached.
-			if cachedParent == nil && height > 0 {
-				parent, err = blockchain.DBFetchBlockByHeight(
-					dbTx, int64(height-1))
-				if err != nil {
-					return err
-				}
-			} else {
-				parent = cachedParent
-			}
-
-			// Load the block for the height since it is required to index
-			// it.
-			block, err = blockchain.DBFetchBlockByHeight(dbTx,
-				int64(height))
-			if err != nil {
-				return err
-			}
-			cachedParent = block
-
-			// Connect the block for all indexes that need it.
-			var view *blockchain.UtxoViewpoint
-			for i, indexer := range m.enabledIndexes {
-				// Skip indexes that don't need to be updated with this
-				// block.
-				if indexerHeights[i] >= height {
-					continue
-				}
-
-				// When the index requires all of the referenced
-				// txouts and they haven't been loaded yet, they
-				// need to be retrieved from the transaction
-				// index.
-				if view == nil && indexNeedsInputs(indexer) {
-					var errMakeView error
-					view, errMakeView = makeUtxoView(dbTx, block, parent)
-					if errMakeView != nil {
-						return errMakeView
-					}
-				}
-				err = dbIndexConnectBlock(dbTx, indexer, block,
-					parent, view)
-				if err != nil {
-					return err
-				}
-
-				indexerHeights[i] = height
-			}
-
-			return nil
-		})
-		if err != nil {
-			return err
-		}
-		progressLogger.LogBlockHeight(block.MsgBlock(), parent.MsgBlock())
-	}
-
-	log.Infof("Indexes caught up to height %d", bestHeight)
+	// tip.  They are left behind while the chain is synced and caught up in
+	// the background by CatchUp afterwards.
+	for _, indexer := range behind {
+		log.Infof("The %s is behind the chain and will be caught up in "+
+			"the background once the chain is synced", indexer.Name())
+	}
+	m.mtx.Lock()
+	m.catchUp = &indexCatchUp{indexes: behind}
+	m.mtx.Unlock()
 	return nil
 }
 
@@ -579,9 +522,18 @@ func makeUtxoView(dbTx database.Tx, block, parent *hcutil.Block) (*blockchain.Ut
 // This is part of the blockchain.IndexManager interface.
 func (m *Manager) ConnectBlock(dbTx database.Tx, block, parent *hcutil.Block, view *blockchain.UtxoViewpoint) error {
 	// Call each of the currently active optional indexes with the block
-	// being connected so they can update accordingly.
+	// being connected so they can update accordingly.  Indexes which are
+	// being caught up are skipped until they reach the block.
+	prevHash := &block.MsgBlock().Header.PrevBlock
 	for _, index := range m.enabledIndexes {
-		err := dbIndexConnectBlock(dbTx, index, block, parent, view)
+		skip, err := m.skipCatchingUp(dbTx, index, prevHash)
+		if err != nil {
+			return err
+		}
+		if skip {
+			continue
+		}
+		err = dbIndexConnectBlock(dbTx, index, block, parent, view)
 		if err != nil {
 			return err
 		}
@@ -597,9 +549,17 @@ func (m *Manager) ConnectBlock(dbTx database.Tx, block, parent *hcutil.Block, vi
 // This is part of the blockchain.IndexManager interface.
 func (m *Manager) DisconnectBlock(dbTx database.Tx, block, parent *hcutil.Block, view *blockchain.UtxoViewpoint) error {
 	// Call each of the currently active optional indexes with the block
-	// being disconnected so they can update accordingly.
+	// being disconnected so they can update accordingly.  Indexes which are
+	// being caught up and have not reached the block yet are skipped.
 	for _, index := range m.enabledIndexes {
-		err := dbIndexDisconnectBlock(dbTx, index, block, parent, view)
+		skip, err := m.skipCatchingUp(dbTx, index, block.Hash())
+		if err != nil {
+			return err
+		}
+		if skip {
+			continue
+		}
+		err = dbIndexDisconnectBlock(dbTx, index, block, parent, view)
 		if err != nil {
 			return err
 		}
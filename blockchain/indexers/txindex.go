@@ -359,8 +359,10 @@ func (idx *TxIndex) Init() error {
 		log.Tracef("Forward scan (highest known %d, next unknown %d)",
 			highestKnown, nextUnknown)
 
-		// No used block IDs due to new database.
+		// No used block IDs due to new database or an index which was
+		// just recreated.
 		if nextUnknown == 1 {
+			idx.curBlockID = 0
 			return nil
 		}
 
@@ -36,4 +36,9 @@ var (
 	// UtxoSetBucketName is the name of the db bucket used to house the
 	// unspent transaction output set.
 	UtxoSetBucketName = []byte("utxoset")
+
+	// CoinSupplyBucketName is the name of the db bucket used to house the
+	// coin supply added by each main chain block along with the total coin
+	// supply of the main chain ending at it.
+	CoinSupplyBucketName = []byte("coinsupply")
 )
@@ -46,17 +46,28 @@ const (
 	// NTSpentAndMissedTickets indicates newly maturing tickets from a newly
 	// accepted block.
 	NTNewTickets
+
+	// NTReorganizationRejected indicates that a reorganization was not
+	// performed because it would disconnect more blocks from the main chain
+	// than the maximum automatic reorganization depth allows.
+	NTReorganizationRejected
+
+	// NTRevocableTickets indicates tickets which became revocable in a
+	// newly accepted block because they missed their vote or expired.
+	NTRevocableTickets
 )
 
 // notificationTypeStrings is a map of notification types back to their constant
 // names for pretty printing.
 var notificationTypeStrings = map[NotificationType]string{
-	NTBlockAccepted:         "NTBlockAccepted",
-	NTBlockConnected:        "NTBlockConnected",
-	NTBlockDisconnected:     "NTBlockDisconnected",
-	NTReorganization:        "NTReorganization",
-	NTSpentAndMissedTickets: "NTSpentAndMissedTickets",
-	NTNewTickets:            "NTNewTickets",
+	NTBlockAccepted:          "NTBlockAccepted",
+	NTBlockConnected:         "NTBlockConnected",
+	NTBlockDisconnected:      "NTBlockDisconnected",
+	NTReorganization:         "NTReorganization",
+	NTSpentAndMissedTickets:  "NTSpentAndMissedTickets",
+	NTNewTickets:             "NTNewTickets",
+	NTReorganizationRejected: "NTReorganizationRejected",
+	NTRevocableTickets:       "NTRevocableTickets",
 }
 
 // String returns the NotificationType in human-readable form.
@@ -83,6 +94,18 @@ type ReorganizationNtfnsData struct {
 	NewHeight int64
 }
 
+// ReorganizationRejectedNtfnsData is the structure for data indicating
+// information about a reorganization which was rejected because it exceeds
+// the maximum automatic reorganization depth.  Depth is the number of main
+// chain blocks the reorganization would have disconnected.
+type ReorganizationRejectedNtfnsData struct {
+	OldHash   chainhash.Hash
+	OldHeight int64
+	NewHash   chainhash.Hash
+	NewHeight int64
+	Depth     int64
+}
+
 // TicketNotificationsData is the structure for new/spent/missed ticket
 // notifications at blockchain HEAD that are outgoing from chain.
 type TicketNotificationsData struct {
@@ -94,6 +117,17 @@ type TicketNotificationsData struct {
 	TicketsNew      []chainhash.Hash
 }
 
+// RevocableTicketsNtfnsData is the structure for data indicating the tickets
+// which became revocable in a block at blockchain HEAD.  TicketsMissed are the
+// tickets which were called to vote and missed it, and TicketsExpired are the
+// tickets which expired without being called to vote.
+type RevocableTicketsNtfnsData struct {
+	Hash           chainhash.Hash
+	Height         int64
+	TicketsMissed  []chainhash.Hash
+	TicketsExpired []chainhash.Hash
+}
+
 // Notification defines notification that is sent to the caller via the callback
 // function provided during the call to New and consists of a notification type
 // as well as associated data that depends on the type as follows:
@@ -103,6 +137,8 @@ type TicketNotificationsData struct {
 //  - NTReorganization:        *ReorganizationNtfnsData
 //  - NTSpentAndMissedTickets: *TicketNotificationsData
 //  - NTNewTickets:            *TicketNotificationsData
+//  - NTReorganizationRejected: *ReorganizationRejectedNtfnsData
+//  - NTRevocableTickets:      *RevocableTicketsNtfnsData
 type Notification struct {
 	Type NotificationType
 	Data interface{}
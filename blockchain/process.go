@@ -37,6 +37,13 @@ const (
 	// without modifying the current state.
 	BFDryRun
 
+	// BFAssumeValid may be set to indicate the block is already known to
+	// be an ancestor of a block which is assumed to be valid, so the
+	// expensive script validation can be skipped.  All other checks are
+	// still performed.  This is used by headers-first mode to link the
+	// headers up to the assumed valid block.
+	BFAssumeValid
+
 	// BFNone is a convenience value to specifically indicate no flags.
 	BFNone BehaviorFlags = 0
 )
@@ -241,8 +248,10 @@ func (b *BlockChain) ProcessBlock(block *hcutil.Block, flags BehaviorFlags) (boo
 	if !dryRun {
 		// Accept any orphan blocks that depend on this block (they are
 		// no longer orphans) and repeat for those accepted blocks until
-		// there are no more.
-		err := b.processOrphans(blockHash, flags)
+		// there are no more.  The orphans are not known to be ancestors
+		// of the block assumed to be valid, so their scripts are always
+		// validated.
+		err := b.processOrphans(blockHash, flags&^BFAssumeValid)
 		if err != nil {
 			return false, false, err
 		}
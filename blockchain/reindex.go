@@ -0,0 +1,273 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/HcashOrg/hcd/blockchain/internal/dbnamespace"
+	"github.com/HcashOrg/hcd/blockchain/internal/progresslog"
+	"github.com/HcashOrg/hcd/blockchain/stake"
+	"github.com/HcashOrg/hcd/chaincfg/chainhash"
+	"github.com/HcashOrg/hcd/database"
+	"github.com/HcashOrg/hcd/hcutil"
+)
+
+// maxReindexDeletions is the maximum number of entries deleted from a bucket
+// per database transaction while the chain state is removed.
+const maxReindexDeletions = 2000000
+
+// reindexBucketName is the name of the db bucket used to house the hashes of
+// the main chain blocks which remain to be connected again while the chain
+// state is rebuilt from the stored blocks.  The keys are the big endian
+// heights of the blocks, so a cursor iterates them in chain order.
+var reindexBucketName = []byte("reindexchainstate")
+
+// ErrReindexInterrupted is returned by New when the chain state was being
+// rebuilt from the stored blocks and the interrupt channel of the config was
+// closed before it was done.  The rebuild resumes on the next call to New.
+var ErrReindexInterrupted = errors.New("chain state reindex interrupted")
+
+// reindexKey returns the key of the reindex bucket for the passed height.
+func reindexKey(height int64) []byte {
+	var key [4]byte
+	binary.BigEndian.PutUint32(key[:], uint32(height))
+	return key[:]
+}
+
+// chainStateBucketNames returns the names of the database buckets which house
+// the chain state derived from the main chain blocks.
+func chainStateBucketNames() [][]byte {
+	names := [][]byte{
+		dbnamespace.BlockChainDbInfoBucketName,
+		dbnamespace.HashIndexBucketName,
+		dbnamespace.HeightIndexBucketName,
+		dbnamespace.SpendJournalBucketName,
+		dbnamespace.UtxoSetBucketName,
+		dbnamespace.CoinSupplyBucketName,
+		thresholdBucketName,
+	}
+	return append(names, stake.DatabaseBucketNames()...)
+}
+
+// ResetChainState prepares the chain state of the passed database to be
+// rebuilt from the blocks it stores, without downloading them again.  It
+// records the main chain blocks and removes the best chain state, so the next
+// call to New removes the rest of the chain state, initializes it to the
+// genesis block and connects the recorded blocks again with full validation.
+// It returns the number of blocks which will be connected again.
+//
+// The optional indexes must be dropped before since they are built on top of
+// the chain state.  A reset which is already pending, or a rebuild which was
+// interrupted, is reset again along with the blocks which remain to be
+// connected.
+func ResetChainState(db database.DB) (int64, error) {
+	var numBlocks int64
+	err := db.Update(func(dbTx database.Tx) error {
+		meta := dbTx.Metadata()
+		bucket, err := meta.CreateBucketIfNotExists(reindexBucketName)
+		if err != nil {
+			return err
+		}
+
+		// Record the main chain blocks unless the chain state was
+		// already removed.  Blocks which remain to be connected from an
+		// interrupted rebuild are recorded above the best block, so
+		// they are kept.
+		serializedState := meta.Get(dbnamespace.ChainStateKeyName)
+		if serializedState != nil {
+			state, err := deserializeBestChainState(serializedState)
+			if err != nil {
+				return err
+			}
+			for height := int64(1); height <= int64(state.height); height++ {
+				hash, err := dbFetchHashByHeight(dbTx, height)
+				if err != nil {
+					return err
+				}
+				err = bucket.Put(reindexKey(height), hash[:])
+				if err != nil {
+					return err
+				}
+			}
+			err = meta.Delete(dbnamespace.ChainStateKeyName)
+			if err != nil {
+				return err
+			}
+		}
+
+		cursor := bucket.Cursor()
+		if cursor.Last() {
+			numBlocks = int64(binary.BigEndian.Uint32(cursor.Key()))
+		}
+		return nil
+	})
+	return numBlocks, err
+}
+
+// dbDeleteBucketInBatches removes the bucket with the passed name, if any, by
+// deleting its entries in multiple database transactions first, since deleting
+// a large bucket in a single transaction would result in massive memory usage.
+func dbDeleteBucketInBatches(db database.DB, name []byte) error {
+	for numDeleted := maxReindexDeletions; numDeleted == maxReindexDeletions; {
+		numDeleted = 0
+		err := db.Update(func(dbTx database.Tx) error {
+			bucket := dbTx.Metadata().Bucket(name)
+			if bucket == nil {
+				return nil
+			}
+			cursor := bucket.Cursor()
+			for ok := cursor.First(); ok; ok = cursor.Next() &&
+				numDeleted < maxReindexDeletions {
+
+				// Nested buckets are removed along with the
+				// bucket.
+				if cursor.Value() == nil {
+					continue
+				}
+				if err := cursor.Delete(); err != nil {
+					return err
+				}
+				numDeleted++
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return db.Update(func(dbTx database.Tx) error {
+		meta := dbTx.Metadata()
+		if meta.Bucket(name) == nil {
+			return nil
+		}
+		return meta.DeleteBucket(name)
+	})
+}
+
+// maybeFinishChainStateReset removes the chain state when it was reset by
+// ResetChainState, so it is initialized to the genesis block again.
+func (b *BlockChain) maybeFinishChainStateReset() error {
+	var pending bool
+	err := b.db.View(func(dbTx database.Tx) error {
+		meta := dbTx.Metadata()
+		pending = meta.Bucket(reindexBucketName) != nil &&
+			meta.Get(dbnamespace.ChainStateKeyName) == nil
+		return nil
+	})
+	if err != nil || !pending {
+		return err
+	}
+
+	log.Infof("Removing the chain state to rebuild it from the stored " +
+		"blocks.  This might take a while...")
+	for _, name := range chainStateBucketNames() {
+		if err := dbDeleteBucketInBatches(b.db, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reconnectBlock connects the passed stored block, which must extend the main
+// chain, with full validation.  Unlike ProcessBlock, it does not reject the
+// block for already being stored in the database.
+func (b *BlockChain) reconnectBlock(block *hcutil.Block) error {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	err := checkBlockSanity(block, b.timeSource, BFNone, b.chainParams)
+	if err != nil {
+		return err
+	}
+	isMainChain, err := b.maybeAcceptBlock(block, BFNone)
+	if err != nil {
+		return err
+	}
+	if !isMainChain {
+		return AssertError(fmt.Sprintf("block %v does not extend the "+
+			"main chain", block.Hash()))
+	}
+	return nil
+}
+
+// maybeResumeReindex connects the main chain blocks recorded by
+// ResetChainState which are not connected yet, until they are all connected
+// or the passed channel is closed.  No notifications are sent for them.
+func (b *BlockChain) maybeResumeReindex(interrupt <-chan struct{}) error {
+	var pending bool
+	err := b.db.View(func(dbTx database.Tx) error {
+		pending = dbTx.Metadata().Bucket(reindexBucketName) != nil
+		return nil
+	})
+	if err != nil || !pending {
+		return err
+	}
+
+	notifications := b.notifications
+	b.notifications = nil
+	defer func() {
+		b.notifications = notifications
+	}()
+
+	best := b.BestSnapshot()
+	parent, err := b.BlockByHash(best.Hash)
+	if err != nil {
+		return err
+	}
+	log.Infof("Rebuilding the chain state from the stored blocks after "+
+		"height %d", best.Height)
+	progressLogger := progresslog.NewBlockProgressLogger("Reconnected", log)
+	for height := best.Height + 1; ; height++ {
+		select {
+		case <-interrupt:
+			return ErrReindexInterrupted
+		default:
+		}
+
+		var block *hcutil.Block
+		err := b.db.View(func(dbTx database.Tx) error {
+			bucket := dbTx.Metadata().Bucket(reindexBucketName)
+			serializedHash := bucket.Get(reindexKey(height))
+			if serializedHash == nil {
+				return nil
+			}
+			hash, err := chainhash.NewHash(serializedHash)
+			if err != nil {
+				return err
+			}
+			blockBytes, err := dbTx.FetchBlock(hash)
+			if err != nil {
+				return err
+			}
+			block, err = hcutil.NewBlockFromBytes(blockBytes)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		if block == nil {
+			break
+		}
+
+		if err := b.reconnectBlock(block); err != nil {
+			return fmt.Errorf("unable to connect stored block %v "+
+				"(height %d) again: %v -- delete the database and "+
+				"resync the blockchain", block.Hash(), height, err)
+		}
+		progressLogger.LogBlockHeight(block.MsgBlock(), parent.MsgBlock())
+		parent = block
+	}
+
+	if err := dbDeleteBucketInBatches(b.db, reindexBucketName); err != nil {
+		return err
+	}
+	log.Infof("Rebuilt the chain state from the stored blocks up to "+
+		"height %d", b.BestSnapshot().Height)
+	return nil
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"container/list"
+	"fmt"
+
+	"github.com/HcashOrg/hcd/chaincfg/chainhash"
+)
+
+// exceedsMaxReorgDepth returns whether or not a reorganization which detaches
+// and attaches the passed nodes disconnects more blocks from the main chain
+// than the maximum automatic reorganization depth allows.  Reorganizations to
+// a chain containing a block approved by the operator are always allowed.
+//
+// This function MUST be called with the chain state lock held (for reads).
+func (b *BlockChain) exceedsMaxReorgDepth(detachNodes, attachNodes *list.List) bool {
+	if b.maxReorgDepth <= 0 || int64(detachNodes.Len()) <= b.maxReorgDepth {
+		return false
+	}
+
+	for e := attachNodes.Front(); e != nil; e = e.Next() {
+		n := e.Value.(*blockNode)
+		if _, ok := b.approvedReorgs[n.hash]; ok {
+			return false
+		}
+	}
+	return true
+}
+
+// rejectReorganization logs that a reorganization to the passed side chain
+// node which would disconnect depth blocks from the main chain was rejected
+// and sends a NTReorganizationRejected notification.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) rejectReorganization(node *blockNode, depth int64) {
+	log.Criticalf("REORGANIZE: Block %v (height %v) would reorganize %d "+
+		"blocks which exceeds the maximum automatic reorganization depth "+
+		"of %d -- the chain will remain at block %v (height %v) until "+
+		"the reorganization is approved with reconsiderblock", node.hash,
+		node.height, depth, b.maxReorgDepth, b.bestNode.hash,
+		b.bestNode.height)
+
+	rejectData := &ReorganizationRejectedNtfnsData{
+		OldHash:   b.bestNode.hash,
+		OldHeight: b.bestNode.height,
+		NewHash:   node.hash,
+		NewHeight: node.height,
+		Depth:     depth,
+	}
+	b.chainLock.Unlock()
+	b.sendNotification(NTReorganizationRejected, rejectData)
+	b.chainLock.Lock()
+}
+
+// clearApprovedReorgs removes the approvals of the passed nodes once they were
+// attached to the main chain, so they do not allow further deep
+// reorganizations should the chain be reorganized away from them again.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) clearApprovedReorgs(attachNodes *list.List) {
+	for e := attachNodes.Front(); e != nil; e = e.Next() {
+		delete(b.approvedReorgs, e.Value.(*blockNode).hash)
+	}
+}
+
+// ReconsiderBlock approves reorganizing the main chain to a side chain
+// containing the block with the passed hash regardless of the maximum
+// automatic reorganization depth.  The chain is reorganized right away to the
+// side chain tip with the most work descending from the block when it has
+// more work than the current best chain.  Otherwise, the approval remains in
+// effect until the side chain accumulates enough work.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) ReconsiderBlock(hash *chainhash.Hash) error {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	node, ok := b.index[*hash]
+	if !ok {
+		return fmt.Errorf("block %v is not known", hash)
+	}
+	if node.inMainChain {
+		return nil
+	}
+	if node.status&statusInvalid != 0 {
+		return fmt.Errorf("block %v is known to be invalid", hash)
+	}
+	b.approvedReorgs[*hash] = struct{}{}
+
+	// Find the tip with the most work among the blocks descending from
+	// the approved block, skipping branches known to be invalid.
+	tip := node
+	pending := []*blockNode{node}
+	for len(pending) > 0 {
+		n := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if n.workSum.Cmp(tip.workSum) > 0 {
+			tip = n
+		}
+		for _, child := range n.children {
+			if child.status&statusInvalid == 0 {
+				pending = append(pending, child)
+			}
+		}
+	}
+	if tip.workSum.Cmp(b.bestNode.workSum) <= 0 {
+		log.Infof("Reorganization to block %v approved, but its chain "+
+			"does not have more work than the current best chain", hash)
+		return nil
+	}
+
+	detachNodes, attachNodes, err := b.getReorganizeNodes(tip)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("REORGANIZE: Approved block %v is causing a reorganize of %d "+
+		"blocks.", hash, detachNodes.Len())
+	err = b.reorganizeChain(detachNodes, attachNodes, BFNone)
+	if err != nil {
+		return err
+	}
+
+	b.clearApprovedReorgs(attachNodes)
+	return nil
+}
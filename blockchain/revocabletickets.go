@@ -0,0 +1,123 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"fmt"
+
+	"github.com/HcashOrg/hcd/blockchain/stake"
+	"github.com/HcashOrg/hcd/chaincfg/chainhash"
+	"github.com/HcashOrg/hcd/database"
+	"github.com/HcashOrg/hcd/hcutil"
+	"github.com/HcashOrg/hcd/wire"
+)
+
+// RevocationOutput describes an output a revocation of a ticket must pay to
+// one of the commitments of the ticket.
+type RevocationOutput struct {
+	// Address is the address committed to by the ticket.
+	Address hcutil.Address
+
+	// Amount is the amount the output returns when no fee is paid from
+	// it, and MaxFee is the maximum fee the commitment allows to be
+	// deducted from the amount.
+	Amount int64
+	MaxFee int64
+}
+
+// RevocableTicket describes a ticket which missed its vote or expired and has
+// not been revoked yet, along with everything needed to build its revocation.
+type RevocableTicket struct {
+	// Hash and Height are the hash of the ticket and the height of the
+	// block which contains it.
+	Hash   chainhash.Hash
+	Height int64
+
+	// Expired is whether the ticket expired instead of missing its vote.
+	Expired bool
+
+	// PrevOut is the output of the ticket the revocation spends, and
+	// Amount, ScriptVersion and PkScript are its amount, script version
+	// and public key script.
+	PrevOut       wire.OutPoint
+	Amount        int64
+	ScriptVersion uint16
+	PkScript      []byte
+
+	// Outputs are the outputs the revocation must pay to, in order.
+	Outputs []RevocationOutput
+}
+
+// RevocableTickets returns the tickets which are revocable as of the current
+// best block, which are those which missed their vote or expired and have not
+// been revoked yet, along with the hash and height of the best block.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) RevocableTickets() ([]RevocableTicket, chainhash.Hash, int64, error) {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	best := b.bestNode
+	sn := best.stakeNode
+	missed := sn.MissedTickets()
+	tickets := make([]RevocableTicket, 0, len(missed))
+	err := b.db.View(func(dbTx database.Tx) error {
+		for i := range missed {
+			hash := &missed[i]
+			utxo, err := dbFetchUtxoEntry(dbTx, hash)
+			if err != nil {
+				return err
+			}
+			if utxo == nil || utxo.IsOutputSpent(0) {
+				return AssertError(fmt.Sprintf("missing output of "+
+					"missed ticket %v", hash))
+			}
+
+			ticket := RevocableTicket{
+				Hash:          *hash,
+				Height:        utxo.BlockHeight(),
+				Expired:       sn.ExistsExpiredTicket(*hash),
+				PrevOut:       *wire.NewOutPoint(hash, 0, wire.TxTreeStake),
+				Amount:        utxo.AmountByIndex(0),
+				ScriptVersion: utxo.ScriptVersionByIndex(0),
+				PkScript:      utxo.PkScriptByIndex(0),
+			}
+
+			// The commitments are the odd outputs of the ticket.
+			// The revocation returns the amount of the ticket in
+			// proportion to the contributed amounts.
+			minOuts := ConvertUtxosToMinimalOutputs(utxo)
+			_, _, contributed, _, rules, limits, _ :=
+				stake.SStxStakeOutputInfo(minOuts)
+			amounts := stake.CalculateRewards(contributed,
+				ticket.Amount, 0)
+			for idx, amount := range amounts {
+				addr, err := stake.AddrFromSStxPkScrCommitment(
+					minOuts[idx*2+1].PkScript, b.chainParams)
+				if err != nil {
+					return err
+				}
+
+				var maxFee int64
+				if rules[idx][1] {
+					maxFee = stake.FeeAllowance(amount, limits[idx][1])
+				}
+				ticket.Outputs = append(ticket.Outputs, RevocationOutput{
+					Address: addr,
+					Amount:  amount,
+					MaxFee:  maxFee,
+				})
+			}
+
+			tickets = append(tickets, ticket)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, chainhash.Hash{}, 0, err
+	}
+
+	return tickets, best.hash, best.height, nil
+}
@@ -11,16 +11,19 @@ import (
 	"math"
 	"runtime"
 
+	"github.com/HcashOrg/hcd/chaincfg/chainhash"
 	"github.com/HcashOrg/hcd/hcutil"
 	"github.com/HcashOrg/hcd/txscript"
 	"github.com/HcashOrg/hcd/wire"
 )
 
-// txValidateItem holds a transaction along with which input to validate.
+// txValidateItem holds a transaction along with which input to validate.  The
+// full hash of the transaction is only set when a script cache is in use.
 type txValidateItem struct {
-	txInIndex int
-	txIn      *wire.TxIn
-	tx        *hcutil.Tx
+	txInIndex  int
+	txIn       *wire.TxIn
+	tx         *hcutil.Tx
+	txFullHash *chainhash.Hash
 }
 
 // txValidator provides a type which asynchronously validates transaction
@@ -33,6 +36,11 @@ type txValidator struct {
 	utxoView     *UtxoViewpoint
 	flags        txscript.ScriptFlags
 	sigCache     *txscript.SigCache
+	scriptCache  *txscript.ScriptCache
+
+	// storeResults specifies whether successful verifications are added
+	// to the script cache.
+	storeResults bool
 }
 
 // sendResult sends the result of a script pair validation on the internal
@@ -83,6 +91,16 @@ out:
 				break out
 			}
 
+			// Skip executing the script pair when the input was
+			// already verified with flags which imply the current
+			// ones.
+			if v.scriptCache != nil && v.scriptCache.Exists(
+				txVI.txFullHash, &txIn.PreviousOutPoint, v.flags) {
+
+				v.sendResult(nil)
+				continue
+			}
+
 			// Create a new script engine for the script pair.
 			sigScript := txIn.SignatureScript
 			version := txEntry.ScriptVersionByIndex(originTxIndex)
@@ -115,6 +133,10 @@ out:
 			}
 
 			// Validation succeeded.
+			if v.scriptCache != nil && v.storeResults {
+				v.scriptCache.Add(txVI.txFullHash,
+					&txIn.PreviousOutPoint, v.flags)
+			}
 			v.sendResult(nil)
 
 		case <-v.quitChan:
@@ -183,22 +205,45 @@ func (v *txValidator) Validate(items []*txValidateItem) error {
 
 // newTxValidator returns a new instance of txValidator to be used for
 // validating transaction scripts asynchronously.
-func newTxValidator(utxoView *UtxoViewpoint, flags txscript.ScriptFlags, sigCache *txscript.SigCache) *txValidator {
+func newTxValidator(utxoView *UtxoViewpoint, flags txscript.ScriptFlags,
+	sigCache *txscript.SigCache, scriptCache *txscript.ScriptCache,
+	storeResults bool) *txValidator {
+
 	return &txValidator{
 		validateChan: make(chan *txValidateItem),
 		quitChan:     make(chan struct{}),
 		resultChan:   make(chan error),
 		utxoView:     utxoView,
 		sigCache:     sigCache,
+		scriptCache:  scriptCache,
+		storeResults: storeResults,
 		flags:        flags,
 	}
 }
 
+// txFullHashForCache returns the full hash of the passed transaction when a
+// script cache is in use, or nil otherwise since it is only needed for the
+// cache keys.
+func txFullHashForCache(tx *hcutil.Tx, scriptCache *txscript.ScriptCache) *chainhash.Hash {
+	if scriptCache == nil {
+		return nil
+	}
+	hash := tx.MsgTx().TxHashFull()
+	return &hash
+}
+
 // ValidateTransactionScripts validates the scripts for the passed transaction
-// using multiple goroutines.
-func ValidateTransactionScripts(tx *hcutil.Tx, utxoView *UtxoViewpoint, flags txscript.ScriptFlags, sigCache *txscript.SigCache) error {
+// using multiple goroutines.  Inputs found in the passed script cache are not
+// executed again and successfully verified inputs are added to it, so the
+// cache can save the work when the transaction is validated as part of a
+// block later on.
+func ValidateTransactionScripts(tx *hcutil.Tx, utxoView *UtxoViewpoint,
+	flags txscript.ScriptFlags, sigCache *txscript.SigCache,
+	scriptCache *txscript.ScriptCache) error {
+
 	// Collect all of the transaction inputs and required information for
 	// validation.
+	txFullHash := txFullHashForCache(tx, scriptCache)
 	txIns := tx.MsgTx().TxIn
 	txValItems := make([]*txValidateItem, 0, len(txIns))
 	for txInIdx, txIn := range txIns {
@@ -208,23 +253,29 @@ func ValidateTransactionScripts(tx *hcutil.Tx, utxoView *UtxoViewpoint, flags tx
 		}
 
 		txVI := &txValidateItem{
-			txInIndex: txInIdx,
-			txIn:      txIn,
-			tx:        tx,
+			txInIndex:  txInIdx,
+			txIn:       txIn,
+			tx:         tx,
+			txFullHash: txFullHash,
 		}
 		txValItems = append(txValItems, txVI)
 	}
 
 	// Validate all of the inputs.
-	return newTxValidator(utxoView, flags, sigCache).Validate(txValItems)
+	return newTxValidator(utxoView, flags, sigCache, scriptCache,
+		true).Validate(txValItems)
 
 }
 
 // checkBlockScripts executes and validates the scripts for all transactions in
-// the passed block using multiple goroutines.
+// the passed block using multiple goroutines.  Inputs found in the passed
+// script cache, which were typically verified when their transaction was
+// accepted to the memory pool, are not executed again.  The inputs of a block
+// are not added to the cache since they are spent once it is connected.
 // txTree = true is TxTreeRegular, txTree = false is TxTreeStake.
 func checkBlockScripts(block *hcutil.Block, utxoView *UtxoViewpoint, txTree bool,
-	scriptFlags txscript.ScriptFlags, sigCache *txscript.SigCache) error {
+	scriptFlags txscript.ScriptFlags, sigCache *txscript.SigCache,
+	scriptCache *txscript.ScriptCache) error {
 
 	// Collect all of the transaction inputs and required information for
 	// validation for all transactions in the block into a single slice.
@@ -243,6 +294,7 @@ func checkBlockScripts(block *hcutil.Block, utxoView *UtxoViewpoint, txTree bool
 	}
 	txValItems := make([]*txValidateItem, 0, numInputs)
 	for _, tx := range txs {
+		txFullHash := txFullHashForCache(tx, scriptCache)
 		for txInIdx, txIn := range tx.MsgTx().TxIn {
 			// Skip coinbases.
 			if txIn.PreviousOutPoint.Index == math.MaxUint32 {
@@ -250,14 +302,16 @@ func checkBlockScripts(block *hcutil.Block, utxoView *UtxoViewpoint, txTree bool
 			}
 
 			txVI := &txValidateItem{
-				txInIndex: txInIdx,
-				txIn:      txIn,
-				tx:        tx,
+				txInIndex:  txInIdx,
+				txIn:       txIn,
+				tx:         tx,
+				txFullHash: txFullHash,
 			}
 			txValItems = append(txValItems, txVI)
 		}
 	}
 
 	// Validate all of the inputs.
-	return newTxValidator(utxoView, scriptFlags, sigCache).Validate(txValItems)
+	return newTxValidator(utxoView, scriptFlags, sigCache, scriptCache,
+		false).Validate(txValItems)
 }
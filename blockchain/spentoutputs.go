@@ -0,0 +1,118 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"github.com/HcashOrg/hcd/blockchain/stake"
+	"github.com/HcashOrg/hcd/chaincfg/chainhash"
+	"github.com/HcashOrg/hcd/database"
+	"github.com/HcashOrg/hcd/hcutil"
+	"github.com/HcashOrg/hcd/wire"
+)
+
+// SpentOutput describes a previous output spent by a transaction which was
+// connected to the main chain along with a block.  It provides the data of the
+// spend journal, which is the undo data of the block, so callers such as
+// external indexers can determine how balances changed without keeping a
+// copy of the utxo set.
+type SpentOutput struct {
+	// SpendingTx is the hash of the spending transaction and InputIndex
+	// the index of the input within it which spends the output.
+	SpendingTx chainhash.Hash
+	InputIndex uint32
+
+	// PrevOut is the spent output.
+	PrevOut wire.OutPoint
+
+	// Amount is the amount of the spent output.
+	Amount int64
+
+	// Height and BlockIndex are the height of the block containing the
+	// transaction which created the output and the index of that
+	// transaction within the block.
+	Height     int64
+	BlockIndex uint32
+
+	// ScriptVersion and PkScript are the version and the public key script
+	// of the spent output.
+	ScriptVersion uint16
+	PkScript      []byte
+}
+
+// SpentOutputs returns the outputs spent by the transactions connected to the
+// main chain along with the block with the passed hash.  These are the outputs
+// spent by the regular transaction tree of the parent of the block, when the
+// block approves it, followed by those spent by the stake transaction tree of
+// the block itself, each in the order of the transactions and their inputs.
+// The stakebase inputs of votes do not spend an output and are skipped.
+//
+// An error that satisfies IsNotInMainChainErr is returned when the block is
+// not in the main chain.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) SpentOutputs(hash *chainhash.Hash) ([]SpentOutput, error) {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	block, err := b.fetchMainChainBlockByHash(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	// The genesis block does not spend anything.
+	if block.Height() == 0 {
+		return nil, nil
+	}
+	parent, err := b.fetchMainChainBlockByHash(&block.MsgBlock().Header.PrevBlock)
+	if err != nil {
+		return nil, err
+	}
+
+	var stxos []spentTxOut
+	err = b.db.View(func(dbTx database.Tx) error {
+		var err error
+		stxos, err = dbFetchSpendJournalEntry(dbTx, block, parent)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The spent txouts are in the same order as the inputs of the
+	// transactions the spend journal entry was created for.
+	var txns []*hcutil.Tx
+	if hcutil.IsFlagSet16(block.MsgBlock().Header.VoteBits, hcutil.BlockValid) {
+		txns = append(txns, parent.Transactions()[1:]...)
+	}
+	txns = append(txns, block.STransactions()...)
+	spent := make([]SpentOutput, 0, len(stxos))
+	for _, tx := range txns {
+		isSSGen := stake.DetermineTxType(tx.MsgTx()) == stake.TxTypeSSGen
+		for txInIdx, txIn := range tx.MsgTx().TxIn {
+			if isSSGen && txInIdx == 0 {
+				continue
+			}
+
+			stxo := &stxos[len(spent)]
+			pkScript := stxo.pkScript
+			if stxo.compressed {
+				pkScript = decompressScript(pkScript,
+					currentCompressionVersion)
+			}
+			spent = append(spent, SpentOutput{
+				SpendingTx:    *tx.Hash(),
+				InputIndex:    uint32(txInIdx),
+				PrevOut:       txIn.PreviousOutPoint,
+				Amount:        stxo.amount,
+				Height:        int64(stxo.height),
+				BlockIndex:    stxo.index,
+				ScriptVersion: stxo.scriptVersion,
+				PkScript:      pkScript,
+			})
+		}
+	}
+
+	return spent, nil
+}
@@ -715,3 +715,15 @@ func DbCreate(dbTx database.Tx) error {
 	_, err = meta.CreateBucket(dbnamespace.TicketsInBlockBucketName)
 	return err
 }
+
+// DbBucketNames returns the names of all the buckets created by DbCreate.
+func DbBucketNames() [][]byte {
+	return [][]byte{
+		dbnamespace.StakeDbInfoBucketName,
+		dbnamespace.LiveTicketsBucketName,
+		dbnamespace.MissedTicketsBucketName,
+		dbnamespace.RevokedTicketsBucketName,
+		dbnamespace.StakeBlockUndoDataBucketName,
+		dbnamespace.TicketsInBlockBucketName,
+	}
+}
@@ -623,6 +623,19 @@ func CalculateRewards(amounts []int64, amountTicket int64,
 	return outputsAmounts
 }
 
+// FeeAllowance returns the maximum fee which may be deducted from the passed
+// amount calculated for an output of a vote or revocation when the ticket
+// commitment the output pays to enables fees with the passed limit.
+func FeeAllowance(amount int64, limit uint16) int64 {
+	// If 63 is given, the entire amount may be used as a fee.  Obviously
+	// we can't allow shifting 1 63 places because we'd get a negative
+	// number.
+	if limit < rangeLimitMax && int64(1<<uint64(limit)) < amount {
+		return int64(1 << uint64(limit))
+	}
+	return amount
+}
+
 // VerifySStxAmounts compares a list of calculated amounts for ticket commitments
 // to the list of commitment amounts from the actual SStx.
 func VerifySStxAmounts(sstxAmts []int64, sstxCalcAmts []int64) error {
@@ -719,16 +732,7 @@ func VerifyStakingPkhsAndAmounts(
 		// Apply the spending rules and see if the transaction is within
 		// the specified limits if it asks us to.
 		if rule {
-			// If 63 is given, the entire amount may be used as a fee.
-			// Obviously we can't allow shifting 1 63 places because
-			// we'd get a negative number.
-			feeAllowance := ssSpendCalcAmts[idx]
-			if limit < rangeLimitMax {
-				if int64(1<<uint64(limit)) < ssSpendCalcAmts[idx] {
-					feeAllowance = int64(1 << uint64(limit))
-				}
-			}
-
+			feeAllowance := FeeAllowance(ssSpendCalcAmts[idx], limit)
 			amtLimitLow := ssSpendCalcAmts[idx] - feeAllowance
 			amtLimitHigh := ssSpendCalcAmts[idx]
 
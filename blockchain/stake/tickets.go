@@ -98,6 +98,20 @@ func (sn *Node) MissedByBlock() []chainhash.Hash {
 	return missed
 }
 
+// VoteMissedByBlock returns the tickets which were called to vote and missed
+// it in this block.  Unlike MissedByBlock, this does not include tickets which
+// expired or were revoked.
+func (sn *Node) VoteMissedByBlock() []chainhash.Hash {
+	var missed []chainhash.Hash
+	for _, undo := range sn.databaseUndoUpdate {
+		if undo.Missed && !undo.Expired && !undo.Revoked {
+			missed = append(missed, undo.TicketHash)
+		}
+	}
+
+	return missed
+}
+
 // ExistsLiveTicket returns whether or not a ticket exists in the live ticket
 // treap for this stake node.
 func (sn *Node) ExistsLiveTicket(ticket chainhash.Hash) bool {
@@ -264,6 +278,13 @@ func InitDatabaseState(dbTx database.Tx, params *chaincfg.Params) (*Node, error)
 	return genesis, nil
 }
 
+// DatabaseBucketNames returns the names of the database buckets which house the
+// stake database state created by InitDatabaseState.  They must all be removed
+// before the state can be initialized again.
+func DatabaseBucketNames() [][]byte {
+	return ticketdb.DbBucketNames()
+}
+
 // LoadBestNode is used when the blockchain is initialized, to get the initial
 // stake node from the database bucket.  The blockchain must pass the height
 // and the blockHash to confirm that the ticket database is on the same
@@ -10,7 +10,6 @@ import (
 	"github.com/HcashOrg/hcd/chaincfg/chainhash"
 	"github.com/HcashOrg/hcd/database"
 	"github.com/HcashOrg/hcd/hcutil"
-	"github.com/HcashOrg/hcd/txscript"
 )
 
 // NextLotteryData returns the next tickets eligible for spending as SSGen
@@ -109,9 +108,8 @@ func (b *BlockChain) TicketsWithAddress(address hcutil.Address) ([]chainhash.Has
 				return err
 			}
 
-			_, addrs, _, err :=
-				txscript.ExtractPkScriptAddrs(txscript.DefaultScriptVersion,
-					utxo.PkScriptByIndex(0), b.chainParams)
+			_, addrs, _, err := utxo.PkScriptAddrsByIndex(0,
+				b.chainParams)
 			if err != nil {
 				return err
 			}
@@ -105,12 +105,6 @@ func TestCalcWantHeight(t *testing.T) {
 			interval:   chaincfg.TestNet2Params.StakeVersionInterval,
 			multiplier: 1000,
 		},
-		{
-			name:       "regnet params",
-			skip:       chaincfg.RegNetParams.StakeValidationHeight,
-			interval:   chaincfg.RegNetParams.StakeVersionInterval,
-			multiplier: 10000,
-		},
 		{
 			name:       "simnet params",
 			skip:       chaincfg.SimNetParams.StakeValidationHeight,
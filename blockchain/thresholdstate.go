@@ -517,6 +517,99 @@ func (b *BlockChain) ThresholdState(hash *chainhash.Hash, version uint32, deploy
 	return state, err
 }
 
+// deploymentVersion returns the highest stake version which defines the
+// deployment with the passed ID along with whether any version defines it.
+func (b *BlockChain) deploymentVersion(deploymentID string) (uint32, bool) {
+	var version uint32
+	var found bool
+	for v, deployments := range b.chainParams.Deployments {
+		for k := range deployments {
+			if deployments[k].Vote.Id != deploymentID {
+				continue
+			}
+			if !found || v > version {
+				version = v
+				found = true
+			}
+		}
+	}
+	return version, found
+}
+
+// activeDeploymentChoice returns the choice the deployment with the passed ID
+// activated with for the block AFTER the given node, or nil when it is not
+// active.  Deployments which are not defined by the network are never active,
+// so new consensus rules can be gated behind agendas which only exist on some
+// networks without special casing the others.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) activeDeploymentChoice(prevNode *blockNode, deploymentID string) (*chaincfg.Choice, error) {
+	version, ok := b.deploymentVersion(deploymentID)
+	if !ok {
+		return nil, nil
+	}
+	state, err := b.deploymentState(prevNode, version, deploymentID)
+	if err != nil {
+		return nil, err
+	}
+	if state.State != ThresholdActive || state.Choice == invalidChoice {
+		return nil, nil
+	}
+
+	for k := range b.chainParams.Deployments[version] {
+		vote := &b.chainParams.Deployments[version][k].Vote
+		if vote.Id != deploymentID {
+			continue
+		}
+		if state.Choice >= uint32(len(vote.Choices)) {
+			return nil, nil
+		}
+		choice := vote.Choices[state.Choice]
+		return &choice, nil
+	}
+	return nil, nil
+}
+
+// isDeploymentActive returns whether or not the deployment with the passed ID
+// is active with a choice which is neither abstain nor no for the block AFTER
+// the given node.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) isDeploymentActive(prevNode *blockNode, deploymentID string) (bool, error) {
+	choice, err := b.activeDeploymentChoice(prevNode, deploymentID)
+	if err != nil || choice == nil {
+		return false, err
+	}
+	return !choice.IsAbstain && !choice.IsNo, nil
+}
+
+// ActiveDeploymentChoice returns the choice the deployment with the passed ID
+// activated with for the block AFTER the end of the current best chain, or nil
+// when it is not active or not defined by the network.  It allows rules with
+// several possible outcomes to be selected by the choice of the stakeholders.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) ActiveDeploymentChoice(deploymentID string) (*chaincfg.Choice, error) {
+	b.chainLock.Lock()
+	choice, err := b.activeDeploymentChoice(b.bestNode, deploymentID)
+	b.chainLock.Unlock()
+	return choice, err
+}
+
+// IsDeploymentActive returns whether or not the rules introduced by the
+// deployment with the passed ID apply to the block AFTER the end of the
+// current best chain.  This is the case once the stakeholders voted the
+// deployment active with a choice which is neither abstain nor no.  It is
+// false for deployments which are not defined by the network.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) IsDeploymentActive(deploymentID string) (bool, error) {
+	b.chainLock.Lock()
+	active, err := b.isDeploymentActive(b.bestNode, deploymentID)
+	b.chainLock.Unlock()
+	return active, err
+}
+
 // VoteCounts is a compacted struct that is used to message vote counts.
 type VoteCounts struct {
 	Total        uint32
@@ -216,6 +216,24 @@ func TestThresholdState(t *testing.T) {
 		}
 	}
 
+	// testDeploymentActive queries whether the deployment is active for
+	// the block after the current best chain tip and expects the result
+	// to match the provided value.
+	testDeploymentActive := func(id string, want bool) {
+		active, err := chain.IsDeploymentActive(id)
+		if err != nil {
+			t.Fatalf("block %q (height %d) unexpected error when "+
+				"retrieving deployment activation of %s: %v",
+				g.TipName(), g.Tip().Header.Height, id, err)
+		}
+		if active != want {
+			t.Fatalf("block %q (height %d) unexpected deployment "+
+				"activation of %s -- got %v, want %v",
+				g.TipName(), g.Tip().Header.Height, id, active,
+				want)
+		}
+	}
+
 	// Shorter versions of useful params for convenience.
 	ticketsPerBlock := int64(params.TicketsPerBlock)
 	coinbaseMaturity := params.CoinbaseMaturity
@@ -641,6 +659,8 @@ func TestThresholdState(t *testing.T) {
 	g.AssertStakeVersion(4)
 	testThresholdState(testDummy1ID, blockchain.ThresholdLockedIn, testDummy1YesIndex)
 	testThresholdState(testDummy2ID, blockchain.ThresholdFailed, testDummy2NoIndex)
+	testDeploymentActive(testDummy1ID, false)
+	testDeploymentActive(testDummy2ID, false)
 
 	// ---------------------------------------------------------------------
 	// Generate enough blocks to reach the next rule change interval with
@@ -674,4 +694,18 @@ func TestThresholdState(t *testing.T) {
 	g.AssertStakeVersion(4)
 	testThresholdState(testDummy1ID, blockchain.ThresholdActive, testDummy1YesIndex)
 	testThresholdState(testDummy2ID, blockchain.ThresholdFailed, testDummy2NoIndex)
+	testDeploymentActive(testDummy1ID, true)
+	testDeploymentActive(testDummy2ID, false)
+	testDeploymentActive("undefined", false)
+
+	// The choice the first test dummy agenda activated with must be yes.
+	choice, err := chain.ActiveDeploymentChoice(testDummy1ID)
+	if err != nil {
+		t.Fatalf("unexpected error when retrieving the active choice "+
+			"of %s: %v", testDummy1ID, err)
+	}
+	if choice == nil || choice.Id != "yes" {
+		t.Fatalf("unexpected active choice of %s -- got %+v, want yes",
+			testDummy1ID, choice)
+	}
 }
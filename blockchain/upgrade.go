@@ -99,6 +99,55 @@ func (b *BlockChain) upgradeToVersion2() error {
 	return nil
 }
 
+// upgradeToVersion3 upgrades a version 2 blockchain to version 3, which tracks
+// the coin supply added by each main chain block along with the total coin
+// supply of the main chain ending at it.
+func (b *BlockChain) upgradeToVersion3() error {
+	log.Infof("Initializing upgrade to database version 3")
+	best := b.BestSnapshot()
+	progressLogger := progresslog.NewBlockProgressLogger("Upgraded", log)
+
+	// The upgrade is atomic, so there is no need to set the flag that
+	// the database is undergoing an upgrade here.
+	err := b.db.Update(func(dbTx database.Tx) error {
+		parent, errLocal := dbFetchBlockByHeight(dbTx, 0)
+		if errLocal != nil {
+			return errLocal
+		}
+		errLocal = dbCreateCoinSupplyBucket(dbTx, parent.Hash())
+		if errLocal != nil {
+			return errLocal
+		}
+
+		for i := int64(1); i <= best.Height; i++ {
+			block, errLocal := dbFetchBlockByHeight(dbTx, i)
+			if errLocal != nil {
+				return errLocal
+			}
+
+			errLocal = dbPutCoinSupplyEntry(dbTx, block, parent,
+				b.chainParams)
+			if errLocal != nil {
+				return errLocal
+			}
+
+			progressLogger.LogBlockHeight(block.MsgBlock(), parent.MsgBlock())
+			parent = block
+		}
+
+		// Write the new database version.
+		b.dbInfo.version = 3
+		return dbPutDatabaseInfo(dbTx, b.dbInfo)
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Upgrade to coin supply tracking was successful!")
+
+	return nil
+}
+
 // upgrade applies all possible upgrades to the blockchain database iteratively,
 // updating old clients to the newest version.
 func (b *BlockChain) upgrade() error {
@@ -109,5 +158,12 @@ func (b *BlockChain) upgrade() error {
 		}
 	}
 
+	if b.dbInfo.version == 2 {
+		err := b.upgradeToVersion3()
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,194 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/HcashOrg/hcd/blockchain/internal/dbnamespace"
+	"github.com/HcashOrg/hcd/blockchain/stake"
+	"github.com/HcashOrg/hcd/chaincfg/chainhash"
+	"github.com/HcashOrg/hcd/database"
+	"github.com/HcashOrg/hcd/wire"
+)
+
+// UtxoSetOutput describes an unspent transaction output returned by a
+// UtxoSetIterator.
+type UtxoSetOutput struct {
+	// OutPoint identifies the output.
+	OutPoint wire.OutPoint
+
+	// Amount, ScriptVersion and PkScript are the amount, the script
+	// version and the public key script of the output.
+	Amount        int64
+	ScriptVersion uint16
+	PkScript      []byte
+
+	// Height and BlockIndex are the height of the block containing the
+	// transaction which created the output and the index of that
+	// transaction within the block.
+	Height     int64
+	BlockIndex uint32
+
+	// IsCoinBase and TxType describe the transaction which created the
+	// output.
+	IsCoinBase bool
+	TxType     stake.TxType
+}
+
+// UtxoSetIterator iterates the unspent transaction outputs of the main chain
+// as of the best block at the time it was created.  It reads from a snapshot
+// of the database, so the outputs stay consistent while blocks keep being
+// connected and disconnected, and iterating does not block them.
+//
+// The snapshot is held until the iterator is closed, which must be done once
+// it is no longer needed, or the database can not be closed.
+//
+// The iterator is not safe for concurrent access.
+type UtxoSetIterator struct {
+	dbTx    database.Tx
+	cursor  database.Cursor
+	started bool
+	done    bool
+	pending []UtxoSetOutput
+	hash    chainhash.Hash
+	height  int64
+}
+
+// UtxoSetIterator returns an iterator over the unspent transaction outputs of
+// the main chain ending at the current best block.  The outputs are returned
+// ordered by the hash of the transaction which created them and by their
+// index.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) UtxoSetIterator() (*UtxoSetIterator, error) {
+	dbTx, err := b.db.Begin(false)
+	if err != nil {
+		return nil, err
+	}
+
+	// The utxo set and the best chain state are updated in the same
+	// database transaction, so the state stored in the snapshot is the
+	// one the utxo set belongs to.
+	serializedData := dbTx.Metadata().Get(dbnamespace.ChainStateKeyName)
+	if serializedData == nil {
+		dbTx.Rollback()
+		return nil, AssertError("missing chain state")
+	}
+	state, err := deserializeBestChainState(serializedData)
+	if err != nil {
+		dbTx.Rollback()
+		return nil, err
+	}
+
+	utxoBucket := dbTx.Metadata().Bucket(dbnamespace.UtxoSetBucketName)
+	return &UtxoSetIterator{
+		dbTx:   dbTx,
+		cursor: utxoBucket.Cursor(),
+		hash:   state.hash,
+		height: int64(state.height),
+	}, nil
+}
+
+// Hash returns the hash of the best block at the time the iterator was
+// created.
+func (it *UtxoSetIterator) Hash() chainhash.Hash {
+	return it.hash
+}
+
+// Height returns the height of the best block at the time the iterator was
+// created.
+func (it *UtxoSetIterator) Height() int64 {
+	return it.height
+}
+
+// Next returns up to the passed number of the next unspent transaction
+// outputs.  Fewer outputs are only returned once the end of the utxo set is
+// reached, after which no outputs are returned.
+func (it *UtxoSetIterator) Next(count int) ([]UtxoSetOutput, error) {
+	outputs := make([]UtxoSetOutput, 0, count)
+	for len(outputs) < count {
+		// Return the remaining outputs of the current entry first.
+		if len(it.pending) > 0 {
+			n := count - len(outputs)
+			if n > len(it.pending) {
+				n = len(it.pending)
+			}
+			outputs = append(outputs, it.pending[:n]...)
+			it.pending = it.pending[n:]
+			continue
+		}
+		if it.done {
+			break
+		}
+
+		var ok bool
+		if it.started {
+			ok = it.cursor.Next()
+		} else {
+			ok = it.cursor.First()
+			it.started = true
+		}
+		if !ok {
+			it.done = true
+			break
+		}
+
+		var txHash chainhash.Hash
+		copy(txHash[:], it.cursor.Key())
+		entry, err := deserializeUtxoEntry(it.cursor.Value())
+		if err != nil {
+			return nil, database.Error{
+				ErrorCode: database.ErrCorruption,
+				Description: fmt.Sprintf("corrupt utxo entry "+
+					"for %v: %v", txHash, err),
+			}
+		}
+		it.pending = utxoSetOutputs(&txHash, entry)
+	}
+
+	return outputs, nil
+}
+
+// Close releases the database snapshot held by the iterator.
+func (it *UtxoSetIterator) Close() error {
+	it.done = true
+	it.pending = nil
+	return it.dbTx.Rollback()
+}
+
+// utxoSetOutputs returns the unspent outputs of the passed utxo entry ordered
+// by their index.
+func utxoSetOutputs(txHash *chainhash.Hash, entry *UtxoEntry) []UtxoSetOutput {
+	tree := wire.TxTreeRegular
+	if entry.txType != stake.TxTypeRegular {
+		tree = wire.TxTreeStake
+	}
+
+	indexes := make([]int, 0, len(entry.sparseOutputs))
+	for index, out := range entry.sparseOutputs {
+		if !out.spent {
+			indexes = append(indexes, int(index))
+		}
+	}
+	sort.Ints(indexes)
+
+	outputs := make([]UtxoSetOutput, 0, len(indexes))
+	for _, index := range indexes {
+		index := uint32(index)
+		outputs = append(outputs, UtxoSetOutput{
+			OutPoint:      *wire.NewOutPoint(txHash, index, tree),
+			Amount:        entry.AmountByIndex(index),
+			ScriptVersion: entry.ScriptVersionByIndex(index),
+			PkScript:      entry.PkScriptByIndex(index),
+			Height:        entry.BlockHeight(),
+			BlockIndex:    entry.BlockIndex(),
+			IsCoinBase:    entry.IsCoinBase(),
+			TxType:        entry.TransactionType(),
+		})
+	}
+	return outputs
+}
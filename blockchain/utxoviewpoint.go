@@ -10,6 +10,7 @@ import (
 	"fmt"
 
 	"github.com/HcashOrg/hcd/blockchain/stake"
+	"github.com/HcashOrg/hcd/chaincfg"
 	"github.com/HcashOrg/hcd/chaincfg/chainhash"
 	"github.com/HcashOrg/hcd/database"
 	"github.com/HcashOrg/hcd/txscript"
@@ -63,6 +64,11 @@ const (
 // provides a mechanism to avoid the overhead of needlessly uncompressing all
 // outputs for a given utxo entry at the time of load.
 //
+// Likewise, the public key script is only parsed the first time its class or
+// addresses are requested, and the results are kept with the output so that
+// the repeated checks of the same output during validation and policy
+// enforcement do not parse it again.
+//
 // The struct is aligned for memory efficiency.
 type utxoOutput struct {
 	pkScript      []byte // The public key script for the output.
@@ -70,6 +76,22 @@ type utxoOutput struct {
 	scriptVersion uint16 // The script version
 	compressed    bool   // The public key script is compressed.
 	spent         bool   // Output is spent.
+
+	// analysis and addrs are the memoized results of parsing the public
+	// key script.  They are nil until first requested and are never
+	// modified once set, so clones of the output can share them.
+	analysis *txscript.ScriptAnalysis
+	addrs    *utxoScriptAddrs
+}
+
+// utxoScriptAddrs houses the addresses extracted from the public key script of
+// an output for the chain parameters they were extracted with.
+type utxoScriptAddrs struct {
+	params  *chaincfg.Params
+	class   txscript.ScriptClass
+	addrs   []hcutil.Address
+	reqSigs int
+	err     error
 }
 
 // maybeDecompress decompresses the amount and public key script fields of the
@@ -231,6 +253,75 @@ func (entry *UtxoEntry) PkScriptByIndex(outputIndex uint32) []byte {
 	return output.pkScript
 }
 
+// ScriptAnalysisByIndex returns the static analysis of the public key script
+// for the provided output index.  The script is only parsed the first time the
+// analysis is requested.  The returned analysis is shared and must not be
+// modified.
+//
+// Returns nil if the output index references an output that does not exist
+// either due to it being invalid or because the output is not part of the view
+// due to previously being spent/pruned.
+func (entry *UtxoEntry) ScriptAnalysisByIndex(outputIndex uint32) *txscript.ScriptAnalysis {
+	output, ok := entry.sparseOutputs[outputIndex]
+	if !ok {
+		return nil
+	}
+
+	if output.analysis == nil {
+		output.maybeDecompress(currentCompressionVersion)
+		output.analysis = txscript.Analyze(output.scriptVersion,
+			output.pkScript)
+	}
+	return output.analysis
+}
+
+// ScriptClassByIndex returns the class of the public key script for the
+// provided output index as returned by txscript.GetScriptClass.  The script is
+// only parsed the first time its class or analysis is requested.
+//
+// Returns NonStandardTy if the output index references an output that does
+// not exist either due to it being invalid or because the output is not part
+// of the view due to previously being spent/pruned.
+func (entry *UtxoEntry) ScriptClassByIndex(outputIndex uint32) txscript.ScriptClass {
+	analysis := entry.ScriptAnalysisByIndex(outputIndex)
+	if analysis == nil {
+		return txscript.NonStandardTy
+	}
+	return analysis.Class
+}
+
+// PkScriptAddrsByIndex returns the class, addresses and number of required
+// signatures of the public key script for the provided output index as
+// returned by txscript.ExtractPkScriptAddrs.  The results are kept for the
+// most recently passed chain parameters, so repeated requests with the same
+// parameters do not parse the script again.  The returned addresses must not
+// be modified.
+//
+// Returns NonStandardTy and no addresses if the output index references an
+// output that does not exist either due to it being invalid or because the
+// output is not part of the view due to previously being spent/pruned.
+func (entry *UtxoEntry) PkScriptAddrsByIndex(outputIndex uint32, params *chaincfg.Params) (txscript.ScriptClass, []hcutil.Address, int, error) {
+	output, ok := entry.sparseOutputs[outputIndex]
+	if !ok {
+		return txscript.NonStandardTy, nil, 0, nil
+	}
+
+	if output.addrs == nil || output.addrs.params != params {
+		output.maybeDecompress(currentCompressionVersion)
+		class, addrs, reqSigs, err := txscript.ExtractPkScriptAddrs(
+			output.scriptVersion, output.pkScript, params)
+		output.addrs = &utxoScriptAddrs{
+			params:  params,
+			class:   class,
+			addrs:   addrs,
+			reqSigs: reqSigs,
+			err:     err,
+		}
+	}
+	a := output.addrs
+	return a.class, a.addrs, a.reqSigs, a.err
+}
+
 // Clone returns a deep copy of the utxo entry.
 func (entry *UtxoEntry) Clone() *UtxoEntry {
 	if entry == nil {
@@ -255,6 +346,8 @@ func (entry *UtxoEntry) Clone() *UtxoEntry {
 			scriptVersion: output.scriptVersion,
 			compressed:    output.compressed,
 			spent:         output.spent,
+			analysis:      output.analysis,
+			addrs:         output.addrs,
 		}
 	}
 	return newEntry
@@ -872,19 +965,28 @@ func (view *UtxoViewpoint) fetchUtxosMain(db database.DB, txSet map[chainhash.Ha
 	// since other code uses the presence of an entry in the store as a way
 	// to optimize spend and unspend updates to apply only to the specific
 	// utxos that the caller needs access to.
+	//
+	// Entries which already exist in the view are skipped and the rest are
+	// fetched as one batch.
+	hashes := make([]chainhash.Hash, 0, len(txSet))
+	for hash := range txSet {
+		if _, ok := view.entries[hash]; ok {
+			continue
+		}
+		hashes = append(hashes, hash)
+	}
+	if len(hashes) == 0 {
+		return nil
+	}
+
 	return db.View(func(dbTx database.Tx) error {
-		for hash := range txSet {
-			hashCopy := hash
-			// If the UTX already exists in the view, skip adding it.
-			if _, ok := view.entries[hashCopy]; ok {
-				continue
-			}
-			entry, err := dbFetchUtxoEntry(dbTx, &hashCopy)
-			if err != nil {
-				return err
-			}
+		entries, err := dbFetchUtxoEntries(dbTx, hashes)
+		if err != nil {
+			return err
+		}
 
-			view.entries[hash] = entry
+		for i := range hashes {
+			view.entries[hashes[i]] = entries[i]
 		}
 
 		return nil
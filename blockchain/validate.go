@@ -430,6 +430,13 @@ func checkBlockHeaderSanity(block *hcutil.Block, timeSource MedianTimeSource, fl
 		return err
 	}
 
+	return checkBlockHeaderTime(header, timeSource)
+}
+
+// checkBlockHeaderTime ensures the timestamp of the block header has a
+// precision of one second and is not too far in the future.  These checks are
+// context free.
+func checkBlockHeaderTime(header *wire.BlockHeader, timeSource MedianTimeSource) error {
 	// A block timestamp must not have a greater precision than one second.
 	// This check is necessary because Go time.Time values support
 	// nanosecond precision whereas the consensus rules only apply to
@@ -714,6 +721,38 @@ func (b *BlockChain) checkBlockHeaderContext(header *wire.BlockHeader, prevNode
 		return nil
 	}
 
+	err := b.checkBlockHeaderPosition(header, prevNode, flags)
+	if err != nil {
+		return err
+	}
+
+	if flags&BFFastAdd != BFFastAdd {
+		var rulErr error
+		if b.chainParams.Net == wire.MainNet {
+			rulErr = b.CheckMainnetStakeVersion(header, prevNode)
+		} else {
+			rulErr = b.CheckTestnetStakeVersion(header, prevNode)
+		}
+		if rulErr != nil {
+			return rulErr
+		}
+	}
+
+	return nil
+}
+
+// checkBlockHeaderPosition performs the validation checks of
+// checkBlockHeaderContext which only depend on the headers of the previous
+// blocks, namely the difficulty, median time and checkpoint checks.  Unlike the
+// stake version checks, they can therefore be performed for headers without
+// their blocks.
+//
+// The flags modify the behavior of this function as follows:
+//  - BFFastAdd: All checks except those involving comparing the header against
+//    the checkpoints are not performed.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) checkBlockHeaderPosition(header *wire.BlockHeader, prevNode *blockNode, flags BehaviorFlags) error {
 	fastAdd := flags&BFFastAdd == BFFastAdd
 	if !fastAdd {
 		// Ensure the difficulty specified in the block header matches
@@ -774,18 +813,6 @@ func (b *BlockChain) checkBlockHeaderContext(header *wire.BlockHeader, prevNode
 		return ruleError(ErrForkTooOld, str)
 	}
 
-	if !fastAdd {
-		var rulErr error
-		if b.chainParams.Net == wire.MainNet {
-			rulErr = b.CheckMainnetStakeVersion(header, prevNode)
-		} else {
-			rulErr = b.CheckTestnetStakeVersion(header, prevNode)
-		}
-		if rulErr != nil {
-			return rulErr
-		}
-	}
-
 	return nil
 }
 
@@ -1363,7 +1390,7 @@ func CheckTransactionInputs(subsidyCache *SubsidyCache, tx *hcutil.Tx, txHeight
 			// Check and make sure that the input is P2PKH or P2SH.
 			pkVer := utxoEntry.ScriptVersionByIndex(originTxIndex)
 			pkScrpt := utxoEntry.PkScriptByIndex(originTxIndex)
-			class := txscript.GetScriptClass(pkVer, pkScrpt)
+			class := utxoEntry.ScriptClassByIndex(originTxIndex)
 			if txscript.IsStakeOutput(pkScrpt) {
 				class, _ = txscript.GetStakeOutSubclass(pkScrpt)
 			}
@@ -1531,8 +1558,7 @@ func CheckTransactionInputs(subsidyCache *SubsidyCache, tx *hcutil.Tx, txHeight
 
 		// 2. Check to make sure that the second input was an OP_SSTX
 		//    tagged output from the referenced SStx.
-		if txscript.GetScriptClass(utxoEntrySstx.ScriptVersionByIndex(0),
-			utxoEntrySstx.PkScriptByIndex(0)) !=
+		if utxoEntrySstx.ScriptClassByIndex(0) !=
 			txscript.StakeSubmissionTy {
 			errStr := fmt.Sprintf("First SStx output in SStx %v "+
 				"referenced by SSGen %v should have been "+
@@ -1657,8 +1683,7 @@ func CheckTransactionInputs(subsidyCache *SubsidyCache, tx *hcutil.Tx, txHeight
 
 		// 2. Check to make sure that the second input was an OP_SSTX
 		//    tagged output from the referenced SStx.
-		if txscript.GetScriptClass(utxoEntrySstx.ScriptVersionByIndex(0),
-			utxoEntrySstx.PkScriptByIndex(0)) !=
+		if utxoEntrySstx.ScriptClassByIndex(0) !=
 			txscript.StakeSubmissionTy {
 			errStr := fmt.Sprintf("First SStx output in SStx %v "+
 				"referenced by SSGen %v should have been "+
@@ -1816,9 +1841,7 @@ func CheckTransactionInputs(subsidyCache *SubsidyCache, tx *hcutil.Tx, txHeight
 		// the inputs from non SSGen or SSRtx and make sure that they
 		// spend no OP_SSTX tagged outputs.
 		if !(isSSGen || isSSRtx) {
-			if txscript.GetScriptClass(
-				utxoEntry.ScriptVersionByIndex(originTxIndex),
-				utxoEntry.PkScriptByIndex(originTxIndex)) ==
+			if utxoEntry.ScriptClassByIndex(originTxIndex) ==
 				txscript.StakeSubmissionTy {
 				_, errIsSSGen := stake.IsSSGen(msgTx)
 				_, errIsSSRtx := stake.IsSSRtx(msgTx)
@@ -1834,9 +1857,7 @@ func CheckTransactionInputs(subsidyCache *SubsidyCache, tx *hcutil.Tx, txHeight
 
 		// OP_SSGEN and OP_SSRTX tagged outputs can only be spent after
 		// coinbase maturity many blocks.
-		scriptClass := txscript.GetScriptClass(
-			utxoEntry.ScriptVersionByIndex(originTxIndex),
-			utxoEntry.PkScriptByIndex(originTxIndex))
+		scriptClass := utxoEntry.ScriptClassByIndex(originTxIndex)
 		if scriptClass == txscript.StakeGenTy ||
 			scriptClass == txscript.StakeRevocationTy {
 			originHeight := utxoEntry.BlockHeight()
@@ -2413,8 +2434,12 @@ func (b *BlockChain) consensusScriptVerifyFlags(node *blockNode) (txscript.Scrip
 // See the comments for CheckConnectBlock for some examples of the type of
 // checks performed by this function.
 //
+// The flags modify the behavior of this function as follows:
+//  - BFAssumeValid: Script validation is skipped since the block is known to
+//    be an ancestor of the block assumed to be valid.
+//
 // This function MUST be called with the chain state lock held (for writes).
-func (b *BlockChain) checkConnectBlock(node *blockNode, block *hcutil.Block, utxoView *UtxoViewpoint, stxos *[]spentTxOut) error {
+func (b *BlockChain) checkConnectBlock(node *blockNode, block *hcutil.Block, utxoView *UtxoViewpoint, stxos *[]spentTxOut, flags BehaviorFlags) error {
 	// If the side chain blocks end up in the database, a call to
 	// CheckBlockSanity should be done here in case a previous version
 	// allowed a block that is no longer valid.  However, since the
@@ -2466,6 +2491,13 @@ func (b *BlockChain) checkConnectBlock(node *blockNode, block *hcutil.Block, utx
 	if checkpoint != nil && node.height <= checkpoint.Height {
 		runScripts = false
 	}
+
+	// Likewise, don't run scripts for ancestors of the block assumed to be
+	// valid.  Unlike checkpoints, this is only an optimization which does
+	// not affect which chain is selected.
+	if flags&BFAssumeValid == BFAssumeValid {
+		runScripts = false
+	}
 	var scriptFlags txscript.ScriptFlags
 	if runScripts {
 		var err error
@@ -2564,7 +2596,7 @@ func (b *BlockChain) checkConnectBlock(node *blockNode, block *hcutil.Block, utx
 
 	if runScripts {
 		err = checkBlockScripts(block, utxoView, false, scriptFlags,
-			b.sigCache)
+			b.sigCache, b.scriptCache)
 		if err != nil {
 			log.Tracef("checkBlockScripts failed; error returned "+
 				"on txtreestake of cur block: %v", err)
@@ -2615,7 +2647,7 @@ func (b *BlockChain) checkConnectBlock(node *blockNode, block *hcutil.Block, utx
 
 	if runScripts {
 		err = checkBlockScripts(block, utxoView, true,
-			scriptFlags, b.sigCache)
+			scriptFlags, b.sigCache, b.scriptCache)
 		if err != nil {
 			log.Tracef("checkBlockScripts failed; error returned "+
 				"on txtreeregular of cur block: %v", err)
@@ -2687,7 +2719,7 @@ func (b *BlockChain) CheckConnectBlock(block *hcutil.Block) error {
 		prevNode.hash == b.bestNode.hash) {
 		view := NewUtxoViewpoint()
 		view.SetBestHash(&prevNode.hash)
-		return b.checkConnectBlock(newNode, block, view, nil)
+		return b.checkConnectBlock(newNode, block, view, nil, BFNone)
 	}
 
 	// The requested node is either on a side chain or is a node on the
@@ -2741,7 +2773,7 @@ func (b *BlockChain) CheckConnectBlock(block *hcutil.Block) error {
 	// if there are no nodes to attach, we're done.
 	if attachNodes.Len() == 0 {
 		view.SetBestHash(&parentHash)
-		return b.checkConnectBlock(newNode, block, view, nil)
+		return b.checkConnectBlock(newNode, block, view, nil, BFNone)
 	}
 
 	// The requested node is on a side chain, so we need to apply the
@@ -2767,5 +2799,5 @@ func (b *BlockChain) CheckConnectBlock(block *hcutil.Block) error {
 	}
 
 	view.SetBestHash(&parentHash)
-	return b.checkConnectBlock(newNode, block, view, &stxos)
+	return b.checkConnectBlock(newNode, block, view, &stxos, BFNone)
 }
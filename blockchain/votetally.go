@@ -0,0 +1,142 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"fmt"
+
+	"github.com/HcashOrg/hcd/chaincfg/chainhash"
+	"github.com/HcashOrg/hcd/hcutil"
+)
+
+// VoteBitsInBlock returns the version and the vote bits of each vote in the
+// passed block.
+func VoteBitsInBlock(block *hcutil.Block) []VoteVersionTuple {
+	return voteBitsInBlock(block)
+}
+
+// StakeVersionTally tallies the stake versions and the votes of the blocks of
+// a stake version interval.
+type StakeVersionTally struct {
+	// StartHeight and EndHeight are the heights of the first and the last
+	// block of the tallied range, which is the whole interval unless the
+	// interval is not complete yet.
+	StartHeight int64
+	EndHeight   int64
+
+	// StakeVersions counts the blocks by the stake version in their
+	// header.
+	StakeVersions map[uint32]uint32
+
+	// VoteVersions counts the votes by their version, and VoteBits counts
+	// them by their version and vote bits together.
+	VoteVersions map[uint32]uint32
+	VoteBits     map[VoteVersionTuple]uint32
+
+	// prevHash is the hash of the last block of the previous interval.
+	prevHash chainhash.Hash
+}
+
+// tallyStakeVersionInterval tallies the blocks from the passed node back to the
+// first block of its stake version interval, and returns the tally along with
+// the parent of that block.  Tallies of complete intervals are cached by the
+// hash of their last block, which, unlike its height, identifies the blocks
+// of the interval.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) tallyStakeVersionInterval(node *blockNode) (*StakeVersionTally, *blockNode, error) {
+	interval := b.chainParams.StakeVersionInterval
+	wantHeight := calcWantHeight(b.chainParams.StakeValidationHeight,
+		interval, node.height)
+	tally := &StakeVersionTally{
+		StartHeight:   wantHeight + 1,
+		EndHeight:     node.height,
+		StakeVersions: make(map[uint32]uint32),
+		VoteVersions:  make(map[uint32]uint32),
+		VoteBits:      make(map[VoteVersionTuple]uint32),
+	}
+	if tally.StartHeight < 0 {
+		tally.StartHeight = 0
+	}
+
+	iterNode := node
+	for iterNode != nil && iterNode.height >= tally.StartHeight {
+		tally.StakeVersions[iterNode.header.StakeVersion]++
+		for _, vote := range iterNode.votes {
+			tally.VoteVersions[vote.Version]++
+			tally.VoteBits[vote]++
+		}
+		tally.prevHash = iterNode.header.PrevBlock
+
+		var err error
+		iterNode, err = b.getPrevNodeFromNode(iterNode)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if node.height == wantHeight+interval {
+		b.stakeVersionTallyCache[node.hash] = tally
+	}
+	return tally, iterNode, nil
+}
+
+// StakeVersionTallies returns the tallies of the stake versions and the votes
+// of up to the passed number of stake version intervals, starting with the
+// interval of the block with the passed hash and going backwards.  The first
+// tally ends at that block and thus only covers part of its interval unless
+// it is the last block of it.
+//
+// The tallies of complete intervals are cached, so they do not need to be
+// tallied from the blocks again.  The maps of the returned tallies are shared
+// with the cache and must not be modified.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) StakeVersionTallies(hash *chainhash.Hash, count int32) ([]StakeVersionTally, error) {
+	exists, err := b.HaveBlock(hash)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("hash '%s' not found on chain", hash)
+	}
+	if count < 0 {
+		return nil, fmt.Errorf("count must not be less than zero - "+
+			"got %d", count)
+	}
+
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	// The node is only needed to tally intervals which are not cached, so
+	// it is only looked up once one is found.
+	var node *blockNode
+	tallies := make([]StakeVersionTally, 0, count)
+	for int32(len(tallies)) < count {
+		tally, ok := b.stakeVersionTallyCache[*hash]
+		if ok {
+			node = nil
+		} else {
+			if node == nil {
+				node, err = b.findNode(hash, 0)
+				if err != nil {
+					return nil, err
+				}
+			}
+			tally, node, err = b.tallyStakeVersionInterval(node)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		tallies = append(tallies, *tally)
+		if tally.StartHeight == 0 {
+			break
+		}
+		hash = &tally.prevHash
+	}
+
+	return tallies, nil
+}
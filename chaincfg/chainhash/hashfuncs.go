@@ -7,9 +7,18 @@
 package chainhash
 
 import (
+	"hash"
+
 	"github.com/dchest/blake256"
 )
 
+// NewHasher returns a new hash.Hash which calculates the same hash as HashB and
+// HashH.  It allows data to be hashed as it is written instead of collecting
+// it in a buffer first.
+func NewHasher() hash.Hash {
+	return blake256.New()
+}
+
 // HashFunc calculates the hash of the supplied bytes.
 // TODO(jcv) Should modify blake256 so it has the same interface as blake2
 // and sha256 so these function can look more like btcsuite.  Then should
@@ -18,10 +27,7 @@ func HashFunc(data []byte) [blake256.Size]byte {
 	var outB [blake256.Size]byte
 	a := blake256.New()
 	a.Write(data)
-	out := a.Sum(nil)
-	for i, el := range out {
-		outB[i] = el
-	}
+	a.Sum(outB[:0])
 
 	return outB
 }
@@ -36,15 +42,12 @@ func HashB(b []byte) []byte {
 
 // HashH calculates hash(b) and returns the resulting bytes as a Hash.
 func HashH(b []byte) Hash {
-	var outB [blake256.Size]byte
+	var outB Hash
 	a := blake256.New()
 	a.Write(b)
-	out := a.Sum(nil)
-	for i, el := range out {
-		outB[i] = el
-	}
+	a.Sum(outB[:0])
 
-	return Hash(outB)
+	return outB
 }
 
 // HashBlockSize is the block size of the hash algorithm in bytes.
@@ -69,4 +69,18 @@ func TestHashFuncs(t *testing.T) {
 			continue
 		}
 	}
+
+	// Ensure the hasher returns the expected result when the data is
+	// written in pieces.
+	for _, test := range tests {
+		hasher := NewHasher()
+		half := len(test.in) / 2
+		hasher.Write([]byte(test.in[:half]))
+		hasher.Write([]byte(test.in[half:]))
+		h := fmt.Sprintf("%x", hasher.Sum(nil))
+		if h != test.out {
+			t.Errorf("NewHasher(%q) = %s, want %s", test.in, h, test.out)
+			continue
+		}
+	}
 }
@@ -0,0 +1,158 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chaincfg
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/HcashOrg/hcd/chaincfg/chainhash"
+	"github.com/HcashOrg/hcd/wire"
+)
+
+// customNet is the JSON definition of a custom network as parsed by
+// ParseCustomParams.  Identifiers are hex encoded and fields which are not
+// set keep the value of the base network.
+type customNet struct {
+	Name                 string         `json:"name"`
+	Base                 string         `json:"base"`
+	Net                  uint32         `json:"net"`
+	DefaultPort          string         `json:"defaultport"`
+	DNSSeeds             []DNSSeed      `json:"dnsseeds"`
+	GenesisBlock         string         `json:"genesisblock"`
+	NetworkAddressPrefix string         `json:"networkaddressprefix"`
+	PubKeyAddrID         string         `json:"pubkeyaddrid"`
+	PubKeyBlissAddrID    string         `json:"pubkeyblissaddrid"`
+	PubKeyHashAddrID     string         `json:"pubkeyhashaddrid"`
+	PKHEdwardsAddrID     string         `json:"pkhedwardsaddrid"`
+	PKHSchnorrAddrID     string         `json:"pkhschnorraddrid"`
+	PKHBlissAddrID       string         `json:"pkhblissaddrid"`
+	ScriptHashAddrID     string         `json:"scripthashaddrid"`
+	PrivateKeyID         string         `json:"privatekeyid"`
+	HDPrivateKeyID       string         `json:"hdprivatekeyid"`
+	HDPublicKeyID        string         `json:"hdpublickeyid"`
+	HDCoinType           *uint32        `json:"hdcointype"`
+	BlockOneLedger       []*TokenPayout `json:"blockoneledger"`
+}
+
+// decodeID decodes the hex encoded identifier of the named field into dst,
+// which the identifier must fill exactly.  An empty identifier leaves dst
+// unchanged.
+func decodeID(field, id string, dst []byte) error {
+	if id == "" {
+		return nil
+	}
+	b, err := hex.DecodeString(id)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %v", field, err)
+	}
+	if len(b) != len(dst) {
+		return fmt.Errorf("invalid %s: %d bytes instead of %d", field,
+			len(b), len(dst))
+	}
+	copy(dst, b)
+	return nil
+}
+
+// ParseCustomParams returns the parameters of the custom network defined by
+// the passed JSON document so private networks can be run without changing
+// the source.  The network is derived from one of the standard networks named
+// by the base field, such as "simnet", and overrides its name, magic bytes,
+// default port, DNS seeds, genesis block, address and extended key identifiers
+// and block one ledger with the fields which are set.  The checkpoints and
+// the assumed valid block of the base network are discarded since they belong
+// to its chain.
+//
+// The genesis block is the hex encoded serialized block.  Identifiers are hex
+// encoded as well, for example "0e91" for a pay-to-pubkey-hash address
+// identifier.
+//
+// The returned parameters are not registered, which is up to the caller.
+func ParseCustomParams(data []byte) (*Params, error) {
+	var def customNet
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if err := dec.Decode(&def); err != nil {
+		return nil, fmt.Errorf("invalid custom network: %v", err)
+	}
+
+	var base *Params
+	for _, p := range []*Params{&MainNetParams, &TestNet2Params,
+		&SimNetParams} {
+
+		if p.Name == def.Base {
+			base = p
+			break
+		}
+	}
+	switch {
+	case base == nil:
+		return nil, fmt.Errorf("unknown base network %q, must be one "+
+			"of mainnet, testnet2 or simnet", def.Base)
+	case def.Name == "":
+		return nil, errors.New("the custom network has no name")
+	case def.Net == 0:
+		return nil, errors.New("the custom network has no magic bytes")
+	}
+
+	params := *base
+	params.Name = def.Name
+	params.Net = wire.CurrencyNet(def.Net)
+	params.DNSSeeds = def.DNSSeeds
+	params.Checkpoints = nil
+	params.AssumeValid = chainhash.Hash{}
+	params.AssumeValidHeight = 0
+	if def.DefaultPort != "" {
+		params.DefaultPort = def.DefaultPort
+	}
+	if def.GenesisBlock != "" {
+		b, err := hex.DecodeString(def.GenesisBlock)
+		if err != nil {
+			return nil, fmt.Errorf("invalid genesis block: %v", err)
+		}
+		var block wire.MsgBlock
+		if err := block.FromBytes(b); err != nil {
+			return nil, fmt.Errorf("invalid genesis block: %v", err)
+		}
+		hash := block.BlockHash()
+		params.GenesisBlock = &block
+		params.GenesisHash = &hash
+	}
+	if def.NetworkAddressPrefix != "" {
+		params.NetworkAddressPrefix = def.NetworkAddressPrefix
+	}
+
+	ids := []struct {
+		field string
+		id    string
+		dst   []byte
+	}{
+		{"pubkeyaddrid", def.PubKeyAddrID, params.PubKeyAddrID[:]},
+		{"pubkeyblissaddrid", def.PubKeyBlissAddrID, params.PubKeyBlissAddrID[:]},
+		{"pubkeyhashaddrid", def.PubKeyHashAddrID, params.PubKeyHashAddrID[:]},
+		{"pkhedwardsaddrid", def.PKHEdwardsAddrID, params.PKHEdwardsAddrID[:]},
+		{"pkhschnorraddrid", def.PKHSchnorrAddrID, params.PKHSchnorrAddrID[:]},
+		{"pkhblissaddrid", def.PKHBlissAddrID, params.PKHBlissAddrID[:]},
+		{"scripthashaddrid", def.ScriptHashAddrID, params.ScriptHashAddrID[:]},
+		{"privatekeyid", def.PrivateKeyID, params.PrivateKeyID[:]},
+		{"hdprivatekeyid", def.HDPrivateKeyID, params.HDPrivateKeyID[:]},
+		{"hdpublickeyid", def.HDPublicKeyID, params.HDPublicKeyID[:]},
+	}
+	for _, id := range ids {
+		if err := decodeID(id.field, id.id, id.dst); err != nil {
+			return nil, err
+		}
+	}
+	if def.HDCoinType != nil {
+		params.HDCoinType = *def.HDCoinType
+	}
+	if def.BlockOneLedger != nil {
+		params.BlockOneLedger = def.BlockOneLedger
+	}
+
+	return &params, nil
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chaincfg
+
+import (
+	"encoding/hex"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/HcashOrg/hcd/chaincfg/chainhash"
+)
+
+// TestParseCustomParams ensures custom networks are derived from their base
+// network with the fields set in their definition overridden.
+func TestParseCustomParams(t *testing.T) {
+	genesis := *SimNetParams.GenesisBlock
+	genesis.Header.Timestamp = time.Unix(1600000000, 0)
+	genesisBytes, err := genesis.Bytes()
+	if err != nil {
+		t.Fatalf("unable to serialize genesis block: %v", err)
+	}
+	genesisHash := genesis.BlockHash()
+
+	def := fmt.Sprintf(`{
+		"name": "consortium",
+		"base": "simnet",
+		"net": 305419896,
+		"defaultport": "15008",
+		"dnsseeds": [{"host": "seed.example.com", "hasfiltering": true}],
+		"genesisblock": "%s",
+		"networkaddressprefix": "C",
+		"pubkeyhashaddrid": "1f2e",
+		"hdprivatekeyid": "01020304",
+		"hdcointype": 42,
+		"blockoneledger": [{"address": "Csabc", "amount": 100}]
+	}`, hex.EncodeToString(genesisBytes))
+	params, err := ParseCustomParams([]byte(def))
+	if err != nil {
+		t.Fatalf("ParseCustomParams: unexpected error: %v", err)
+	}
+
+	if params.Name != "consortium" || params.Net != 305419896 ||
+		params.DefaultPort != "15008" ||
+		params.NetworkAddressPrefix != "C" {
+		t.Fatalf("unexpected network: %s %v %s %s", params.Name,
+			params.Net, params.DefaultPort,
+			params.NetworkAddressPrefix)
+	}
+	if len(params.DNSSeeds) != 1 ||
+		params.DNSSeeds[0] != (DNSSeed{"seed.example.com", true}) {
+		t.Fatalf("unexpected DNS seeds: %v", params.DNSSeeds)
+	}
+	if *params.GenesisHash != genesisHash ||
+		params.GenesisBlock.BlockHash() != genesisHash {
+		t.Fatalf("unexpected genesis hash: got %v, want %v",
+			params.GenesisHash, genesisHash)
+	}
+	if params.PubKeyHashAddrID != [2]byte{0x1f, 0x2e} ||
+		params.HDPrivateKeyID != [4]byte{0x01, 0x02, 0x03, 0x04} ||
+		params.HDCoinType != 42 {
+		t.Fatalf("unexpected identifiers: %x %x %d",
+			params.PubKeyHashAddrID, params.HDPrivateKeyID,
+			params.HDCoinType)
+	}
+	if len(params.BlockOneLedger) != 1 ||
+		*params.BlockOneLedger[0] != (TokenPayout{"Csabc", 100}) {
+		t.Fatalf("unexpected block one ledger: %v",
+			params.BlockOneLedger)
+	}
+
+	// Fields which are not set keep the values of the base network, apart
+	// from the checkpoints which belong to its chain.
+	if params.ScriptHashAddrID != SimNetParams.ScriptHashAddrID ||
+		params.HDPublicKeyID != SimNetParams.HDPublicKeyID ||
+		params.TargetTimePerBlock != SimNetParams.TargetTimePerBlock ||
+		params.TicketsPerBlock != SimNetParams.TicketsPerBlock {
+		t.Fatal("unset fields do not match the base network")
+	}
+	if params.Checkpoints != nil || params.AssumeValid != (chainhash.Hash{}) {
+		t.Fatal("the checkpoints of the base network were kept")
+	}
+	if SimNetParams.Name != "simnet" || SimNetParams.Net == params.Net {
+		t.Fatal("the base network was modified")
+	}
+}
+
+// TestParseCustomParamsErrors ensures invalid custom network definitions are
+// rejected.
+func TestParseCustomParamsErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		def  string
+	}{
+		{"not json", `{`},
+		{"unknown base", `{"name": "x", "base": "regnet", "net": 1}`},
+		{"no name", `{"base": "simnet", "net": 1}`},
+		{"no magic", `{"name": "x", "base": "simnet"}`},
+		{"bad genesis", `{"name": "x", "base": "simnet", "net": 1, "genesisblock": "00"}`},
+		{"bad id", `{"name": "x", "base": "simnet", "net": 1, "scripthashaddrid": "zz"}`},
+		{"short id", `{"name": "x", "base": "simnet", "net": 1, "hdpublickeyid": "0102"}`},
+	}
+	for _, test := range tests {
+		if _, err := ParseCustomParams([]byte(test.def)); err == nil {
+			t.Errorf("%s: expected an error", test.name)
+		}
+	}
+}
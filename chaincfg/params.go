@@ -74,6 +74,22 @@ type Checkpoint struct {
 	Hash   *chainhash.Hash
 }
 
+// DNSSeed identifies a DNS seed.
+type DNSSeed struct {
+	// Host defines the hostname of the seed.
+	Host string
+
+	// HasFiltering defines whether the seed supports filtering the nodes
+	// it returns by service flags via subdomains of the form
+	// x<hex service flags>.<host>.
+	HasFiltering bool
+}
+
+// String returns the hostname of the DNS seed in human-readable form.
+func (d DNSSeed) String() string {
+	return d.Host
+}
+
 // Vote describes a voting instance.  It is self-describing so that the UI can
 // be directly implemented using the fields.  Mask determines which bits can be
 // used.  Bits are enumerated and must be consecutive.  Each vote requires one
@@ -180,6 +196,28 @@ type ConsensusDeployment struct {
 	ExpireTime uint64
 }
 
+// HashFuncAddrParams defines the address identifiers of pay-to-pubkey-hash and
+// pay-to-script-hash addresses whose hashes are created with an alternative
+// hash function rather than the default ripemd160(blake256(b)).  Alternative
+// hash functions are only introduced with new address versions so the meaning
+// of existing addresses never changes.
+type HashFuncAddrParams struct {
+	// HashFunc identifies the hash function.  It must be registered with
+	// hcutil.RegisterHashFunc for the addresses to be encoded and decoded.
+	HashFunc uint8
+
+	// PubKeyHashAddrID and ScriptHashAddrID are the first 2 bytes of
+	// secp256k1 P2PKH and P2SH addresses created with the hash function.
+	PubKeyHashAddrID [2]byte
+	ScriptHashAddrID [2]byte
+
+	// AgendaVersion and AgendaID identify the deployment which must be
+	// active before the addresses may be paid to.  The addresses are always
+	// allowed when AgendaID is empty.
+	AgendaVersion uint32
+	AgendaID      string
+}
+
 // TokenPayout is a payout for block 1 which specifies an address and an amount
 // to pay to that address in a transaction output.
 type TokenPayout struct {
@@ -202,7 +240,7 @@ type Params struct {
 
 	// DNSSeeds defines a list of DNS seeds for the network that are used
 	// as one method to discover peers.
-	DNSSeeds []string
+	DNSSeeds []DNSSeed
 
 	// GenesisBlock defines the first block of the chain.
 	GenesisBlock *wire.MsgBlock
@@ -311,6 +349,17 @@ type Params struct {
 	// Checkpoints ordered from oldest to newest.
 	Checkpoints []Checkpoint
 
+	// AssumeValid is the hash of a block which is assumed to be valid, so
+	// the scripts of its ancestors are not validated during the initial
+	// block download.  All other validation is still performed.  The zero
+	// hash disables the optimization.
+	AssumeValid chainhash.Hash
+
+	// AssumeValidHeight is the height of the AssumeValid block.  The
+	// headers are only downloaded up to it, so a peer can't keep the node
+	// downloading headers which never lead to the block.
+	AssumeValidHeight int64
+
 	// These fields are related to voting on consensus rule changes as
 	// defined by BIP0009.
 	//
@@ -354,6 +403,10 @@ type Params struct {
 	ScriptHashAddrID  [2]byte // First 2 bytes of a P2SH address
 	PrivateKeyID      [2]byte // First 2 bytes of a WIF private key
 
+	// HashFuncAddrs defines the address identifiers of addresses created
+	// with alternative hash functions.
+	HashFuncAddrs []HashFuncAddrParams
+
 	// BIP32 hierarchical deterministic extended key magics
 	HDPrivateKeyID [4]byte
 	HDPublicKeyID  [4]byte
@@ -456,12 +509,12 @@ var MainNetParams = Params{
 	Name:        "mainnet",
 	Net:         wire.MainNet,
 	DefaultPort: "14008",
-	DNSSeeds: []string{
-		"mainnet1.h.cash",
-		"mainnet2.h.cash",
-		"mainnet3.h.cash",
-		"mainnet4.h.cash",
-		"mainnet5.h.cash",
+	DNSSeeds: []DNSSeed{
+		{"mainnet1.h.cash", false},
+		{"mainnet2.h.cash", false},
+		{"mainnet3.h.cash", false},
+		{"mainnet4.h.cash", false},
+		{"mainnet5.h.cash", false},
 	},
 
 	// Chain parameters
@@ -493,6 +546,10 @@ var MainNetParams = Params{
 	// Checkpoints ordered from oldest to newest.
 	Checkpoints: []Checkpoint{},
 
+	// There is no block assumed to be valid yet.
+	AssumeValid:       chainhash.Hash{},
+	AssumeValidHeight: 0,
+
 	// The miner confirmation window is defined as:
 	//   target proof of work timespan / target proof of work spacing
 	RuleChangeActivationQuorum:     4032, // 10 % of RuleChangeActivationInterval * TicketsPerBlock
@@ -569,10 +626,10 @@ var TestNet2Params = Params{
 	Name:        "testnet2",
 	Net:         wire.TestNet2,
 	DefaultPort: "12008",
-	DNSSeeds: []string{
-		"testnet1.h.cash",
-		"testnet2.h.cash",
-		"testnet3.h.cash",
+	DNSSeeds: []DNSSeed{
+		{"testnet1.h.cash", false},
+		{"testnet2.h.cash", false},
+		{"testnet3.h.cash", false},
 	},
 
 	// Chain parameters
@@ -604,6 +661,10 @@ var TestNet2Params = Params{
 	// Checkpoints ordered from oldest to newest.
 	Checkpoints: []Checkpoint{},
 
+	// There is no block assumed to be valid yet.
+	AssumeValid:       chainhash.Hash{},
+	AssumeValidHeight: 0,
+
 	// Consensus rule change deployments.
 	//
 	// The miner confirmation window is defined as:
@@ -715,7 +776,7 @@ var SimNetParams = Params{
 	Name:        "simnet",
 	Net:         wire.SimNet,
 	DefaultPort: "13008",
-	DNSSeeds:    []string{}, // NOTE: There must NOT be any seeds.
+	DNSSeeds:    []DNSSeed{}, // NOTE: There must NOT be any seeds.
 
 	// Chain parameters
 	GenesisBlock:             &simNetGenesisBlock,
@@ -746,6 +807,10 @@ var SimNetParams = Params{
 	// Checkpoints ordered from oldest to newest.
 	Checkpoints: nil,
 
+	// There is no block assumed to be valid yet.
+	AssumeValid:       chainhash.Hash{},
+	AssumeValidHeight: 0,
+
 	// Consensus rule change deployments.
 	//
 	// The miner confirmation window is defined as:
@@ -912,6 +977,10 @@ func Register(params *Params) error {
 	pubKeyAddrIDs[params.PubKeyAddrID] = struct{}{}
 	pubKeyHashAddrIDs[params.PubKeyHashAddrID] = struct{}{}
 	scriptHashAddrIDs[params.ScriptHashAddrID] = struct{}{}
+	for _, hashFuncAddr := range params.HashFuncAddrs {
+		pubKeyHashAddrIDs[hashFuncAddr.PubKeyHashAddrID] = struct{}{}
+		scriptHashAddrIDs[hashFuncAddr.ScriptHashAddrID] = struct{}{}
+	}
 	hdPrivToPubKeyIDs[params.HDPrivateKeyID] = params.HDPublicKeyID[:]
 	return nil
 }
@@ -967,6 +1036,34 @@ func IsScriptHashAddrID(id [2]byte) bool {
 	return ok
 }
 
+// HashFuncAddrParamsByID returns the alternative hash function address
+// parameters of the network which use the passed pay-to-pubkey-hash or
+// pay-to-script-hash address identifier.  It returns nil when the identifier is
+// not used by any of them.
+func (p *Params) HashFuncAddrParamsByID(id [2]byte) *HashFuncAddrParams {
+	for i := range p.HashFuncAddrs {
+		hashFuncAddr := &p.HashFuncAddrs[i]
+		if hashFuncAddr.PubKeyHashAddrID == id ||
+			hashFuncAddr.ScriptHashAddrID == id {
+
+			return hashFuncAddr
+		}
+	}
+	return nil
+}
+
+// HashFuncAddrParamsByHashFunc returns the address parameters of the network
+// for addresses created with the passed alternative hash function.  It returns
+// nil when the network does not define address identifiers for it.
+func (p *Params) HashFuncAddrParamsByHashFunc(hashFunc uint8) *HashFuncAddrParams {
+	for i := range p.HashFuncAddrs {
+		if p.HashFuncAddrs[i].HashFunc == hashFunc {
+			return &p.HashFuncAddrs[i]
+		}
+	}
+	return nil
+}
+
 // HDPrivateKeyToPublicKeyID accepts a private hierarchical deterministic
 // extended key id and returns the associated public key id.  When the provided
 // id is not registered, the ErrUnknownHDKeyID error will be returned.
@@ -337,8 +337,10 @@ func newBlockImporter(db database.DB, r io.ReadSeeker) (*blockImporter, error) {
 
 	// Create an index manager if any of the optional indexes are enabled.
 	var indexManager blockchain.IndexManager
+	var manager *indexers.Manager
 	if len(indexes) > 0 {
-		indexManager = indexers.NewManager(db, indexes, activeNetParams)
+		manager = indexers.NewManager(db, indexes, activeNetParams)
+		indexManager = manager
 	}
 
 	chain, err := blockchain.New(&blockchain.Config{
@@ -351,6 +353,16 @@ func newBlockImporter(db database.DB, r io.ReadSeeker) (*blockImporter, error) {
 		return nil, err
 	}
 
+	// Catch up any indexes which are behind the chain before importing the
+	// blocks since there is no chain to sync first.
+	if manager != nil {
+		if done := manager.CatchUp(nil); done != nil {
+			if err := <-done; err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	return &blockImporter{
 		db:           db,
 		r:            r,
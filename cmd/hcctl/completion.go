@@ -0,0 +1,100 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/HcashOrg/hcd/hcjson"
+)
+
+// describeServer requests the description of every method supported by the
+// RPC server using the describerpc command.
+func describeServer(cfg *config) ([]hcjson.DescribeRPCResult, error) {
+	marshalledJSON, err := hcjson.MarshalCmd(1, hcjson.NewDescribeRPCCmd())
+	if err != nil {
+		return nil, err
+	}
+	result, err := sendPostRequest(marshalledJSON, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var methods []hcjson.DescribeRPCResult
+	if err := json.Unmarshal(result, &methods); err != nil {
+		return nil, err
+	}
+	return methods, nil
+}
+
+// listServerCommands prints the usage of every method supported by the RPC
+// server.  Unlike listCommands, this includes methods the server supports which
+// are unknown to this version of hcctl.
+func listServerCommands(methods []hcjson.DescribeRPCResult) {
+	for _, method := range methods {
+		fmt.Println(method.Usage)
+	}
+}
+
+// completionScript returns a script for the passed shell which completes the
+// names of the methods supported by the RPC server as well as the values of
+// their boolean parameters.
+func completionScript(shell, appName string, methods []hcjson.DescribeRPCResult) string {
+	names := make([]string, 0, len(methods))
+	for _, method := range methods {
+		names = append(names, method.Method)
+	}
+
+	var buf bytes.Buffer
+	if shell == "zsh" {
+		// zsh is able to use bash completion functions once its
+		// compatibility layer is loaded.
+		buf.WriteString("autoload -U +X bashcompinit && bashcompinit\n\n")
+	}
+
+	fn := "_" + strings.Replace(appName, "-", "_", -1)
+	fmt.Fprintf(&buf, "%s() {\n", fn)
+	buf.WriteString("\tlocal cur=\"${COMP_WORDS[COMP_CWORD]}\"\n")
+	buf.WriteString("\tlocal i method= pos=0\n")
+	buf.WriteString("\tfor ((i = 1; i < COMP_CWORD; i++)); do\n")
+	buf.WriteString("\t\tcase \"${COMP_WORDS[i]}\" in\n")
+	buf.WriteString("\t\t-*) ;;\n")
+	buf.WriteString("\t\t*) if [ -z \"$method\" ]; then " +
+		"method=\"${COMP_WORDS[i]}\"; else pos=$((pos + 1)); fi ;;\n")
+	buf.WriteString("\t\tesac\n")
+	buf.WriteString("\tdone\n\n")
+	buf.WriteString("\tif [ -z \"$method\" ]; then\n")
+	fmt.Fprintf(&buf, "\t\tCOMPREPLY=($(compgen -W \"%s\" -- \"$cur\"))\n",
+		strings.Join(names, " "))
+	buf.WriteString("\t\treturn\n")
+	buf.WriteString("\tfi\n\n")
+
+	// Boolean parameters are the only ones with a fixed set of values
+	// worth completing.
+	var boolParams []string
+	for _, method := range methods {
+		for i, param := range method.Params {
+			if param.Type == "boolean" {
+				boolParams = append(boolParams,
+					fmt.Sprintf("%s:%d", method.Method, i))
+			}
+		}
+	}
+	if len(boolParams) > 0 {
+		buf.WriteString("\tcase \"$method:$pos\" in\n")
+		fmt.Fprintf(&buf, "\t%s)\n", strings.Join(boolParams, "|"))
+		buf.WriteString("\t\tCOMPREPLY=($(compgen -W \"true false\" " +
+			"-- \"$cur\"))\n")
+		buf.WriteString("\t\t;;\n")
+		buf.WriteString("\tesac\n")
+	}
+	buf.WriteString("}\n\n")
+	fmt.Fprintf(&buf, "complete -F %s %s\n", fn, appName)
+
+	return buf.String()
+}
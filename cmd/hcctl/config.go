@@ -105,9 +105,13 @@ func listCommands() {
 type config struct {
 	ShowVersion     bool   `short:"V" long:"version" description:"Display version information and exit"`
 	ListCommands    bool   `short:"l" long:"listcommands" description:"List all of the supported commands and exit"`
+	ListServerCmds  bool   `short:"L" long:"listservercommands" description:"List all of the commands supported by the RPC server along with their parameters and exit"`
+	Completion      string `long:"completion" description:"Print a shell completion script generated from the commands supported by the RPC server and exit {bash, zsh}"`
+	Filter          string `long:"filter" description:"Print only the parts of the result selected by a JSONPath expression (eg. $.blocks or $[*].txid)"`
 	ConfigFile      string `short:"C" long:"configfile" description:"Path to configuration file"`
 	RPCUser         string `short:"u" long:"rpcuser" description:"RPC username"`
 	RPCPassword     string `short:"P" long:"rpcpass" default-mask:"-" description:"RPC password"`
+	RPCCookieFile   string `long:"rpccookie" description:"RPC authentication cookie file used when no username and password are given (default: .cookie in the hcd data directory of the network)"`
 	RPCServer       string `short:"s" long:"rpcserver" description:"RPC server to connect to"`
 	WalletRPCServer string `short:"w" long:"walletrpcserver" description:"Wallet RPC server to connect to"`
 	RPCCert         string `short:"c" long:"rpccert" description:"RPC server certificate chain for validation"`
@@ -319,6 +323,28 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// Validate the requested shell completion script type.
+	switch cfg.Completion {
+	case "", "bash", "zsh":
+	default:
+		str := "%s: unsupported completion shell %q -- supported " +
+			"shells are bash and zsh"
+		err := fmt.Errorf(str, "loadConfig", cfg.Completion)
+		fmt.Fprintln(os.Stderr, err)
+		return nil, nil, err
+	}
+
+	// Ensure the result filter is a valid JSONPath expression before
+	// sending any requests.
+	if cfg.Filter != "" {
+		if _, err := parseJSONPath(cfg.Filter); err != nil {
+			err := fmt.Errorf("%s: invalid filter: %v", "loadConfig",
+				err)
+			fmt.Fprintln(os.Stderr, err)
+			return nil, nil, err
+		}
+	}
+
 	// Override the RPC certificate if the --wallet flag was specified and
 	// the user did not specify one.
 	if cfg.Wallet && cfg.RPCCert == defaultRPCCertFile {
@@ -333,6 +359,25 @@ func loadConfig() (*config, []string, error) {
 	// Handle environment variable expansion in the RPC certificate path.
 	cfg.RPCCert = cleanAndExpandPath(cfg.RPCCert)
 
+	// Authenticate with the cookie written by hcd when no credentials are
+	// configured.  A missing cookie is only an error when its path was
+	// given explicitly.
+	if !cfg.Wallet && cfg.RPCUser == "" && cfg.RPCPassword == "" {
+		cookieFile := cfg.RPCCookieFile
+		if cookieFile == "" {
+			cookieFile = defaultRPCCookieFile(cfg.TestNet, cfg.SimNet)
+		}
+		user, pass, err := readRPCCookie(cleanAndExpandPath(cookieFile))
+		switch {
+		case err == nil:
+			cfg.RPCUser, cfg.RPCPassword = user, pass
+		case cfg.RPCCookieFile != "":
+			err := fmt.Errorf("%s: %v", "loadConfig", err)
+			fmt.Fprintln(os.Stderr, err)
+			return nil, nil, err
+		}
+	}
+
 	// Add default port to RPC server based on --testnet and --wallet flags
 	// if needed.
 	cfg.RPCServer = normalizeAddress(cfg.RPCServer, cfg.TestNet,
@@ -341,6 +386,33 @@ func loadConfig() (*config, []string, error) {
 	return &cfg, remainingArgs, nil
 }
 
+// defaultRPCCookieFile returns the path of the RPC authentication cookie file
+// hcd writes to its default data directory for the selected network.
+func defaultRPCCookieFile(useTestNet, useSimNet bool) string {
+	netName := "mainnet"
+	switch {
+	case useTestNet:
+		netName = "testnet2"
+	case useSimNet:
+		netName = "simnet"
+	}
+	return filepath.Join(hcdHomeDir, "data", netName, ".cookie")
+}
+
+// readRPCCookie reads the RPC username and password from the passed RPC
+// authentication cookie file.
+func readRPCCookie(path string) (string, string, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", "", err
+	}
+	parts := strings.SplitN(strings.TrimSpace(string(content)), ":", 2)
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("malformed RPC cookie file %s", path)
+	}
+	return parts[0], parts[1], nil
+}
+
 // createDefaultConfig creates a basic config file at the given destination path.
 // For this it tries to read the hcd config file at its default path, and extract
 // the RPC user and password from it.
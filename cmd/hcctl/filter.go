@@ -0,0 +1,167 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// pathSegment is a single step of a JSONPath expression.  It selects the member
+// with the name of an object, the element at the index of an array, or every
+// member or element when it is a wildcard.
+type pathSegment struct {
+	name     string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// parseJSONPath parses the supported subset of JSONPath expressions, which
+// consist of an optional leading $ followed by any number of .name, .*,
+// ['name'], [n] and [*] selectors.  Negative indexes select elements counting
+// from the end of an array.
+func parseJSONPath(path string) ([]pathSegment, error) {
+	rest := strings.TrimPrefix(path, "$")
+	var segments []pathSegment
+	for len(rest) > 0 {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end == -1 {
+				end = len(rest)
+			}
+			name := rest[:end]
+			rest = rest[end:]
+			switch name {
+			case "":
+				return nil, fmt.Errorf("empty member name in %q", path)
+			case "*":
+				segments = append(segments, pathSegment{wildcard: true})
+			default:
+				segments = append(segments, pathSegment{name: name})
+			}
+
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated [ in %q", path)
+			}
+			selector := rest[1:end]
+			rest = rest[end+1:]
+			switch {
+			case selector == "*":
+				segments = append(segments, pathSegment{wildcard: true})
+
+			case len(selector) >= 2 && (selector[0] == '\'' ||
+				selector[0] == '"') &&
+				selector[len(selector)-1] == selector[0]:
+
+				segments = append(segments, pathSegment{
+					name: selector[1 : len(selector)-1],
+				})
+
+			default:
+				index, err := strconv.Atoi(selector)
+				if err != nil {
+					return nil, fmt.Errorf("invalid selector "+
+						"[%s] in %q", selector, path)
+				}
+				segments = append(segments, pathSegment{
+					index:   index,
+					isIndex: true,
+				})
+			}
+
+		default:
+			return nil, fmt.Errorf("unexpected %q in %q", rest[0], path)
+		}
+	}
+	return segments, nil
+}
+
+// selectJSONPath returns the values selected by the passed JSONPath segments
+// from the passed decoded JSON value.  The members of objects selected by a
+// wildcard are returned in the order of their names.
+func selectJSONPath(segments []pathSegment, value interface{}) []interface{} {
+	values := []interface{}{value}
+	for _, seg := range segments {
+		var next []interface{}
+		for _, v := range values {
+			switch v := v.(type) {
+			case map[string]interface{}:
+				if seg.wildcard {
+					names := make([]string, 0, len(v))
+					for name := range v {
+						names = append(names, name)
+					}
+					sort.Strings(names)
+					for _, name := range names {
+						next = append(next, v[name])
+					}
+				} else if member, ok := v[seg.name]; ok && !seg.isIndex {
+					next = append(next, member)
+				}
+
+			case []interface{}:
+				if seg.wildcard {
+					next = append(next, v...)
+					continue
+				}
+				if !seg.isIndex {
+					continue
+				}
+				index := seg.index
+				if index < 0 {
+					index += len(v)
+				}
+				if index >= 0 && index < len(v) {
+					next = append(next, v[index])
+				}
+			}
+		}
+		values = next
+	}
+	return values
+}
+
+// filterResult returns the parts of the passed JSON-RPC result selected by the
+// passed JSONPath expression.  The selected value is returned on its own when
+// the expression can only select a single value.  Otherwise, the selected
+// values are returned as an array.
+func filterResult(result []byte, path string) ([]byte, error) {
+	segments, err := parseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decode numbers as such to avoid losing the precision of large
+	// integers such as amounts in atoms.
+	var value interface{}
+	dec := json.NewDecoder(bytes.NewReader(result))
+	dec.UseNumber()
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	matches := selectJSONPath(segments, value)
+
+	for _, seg := range segments {
+		if seg.wildcard {
+			if matches == nil {
+				matches = []interface{}{}
+			}
+			return json.Marshal(matches)
+		}
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no value matches %s", path)
+	}
+	return json.Marshal(matches[0])
+}
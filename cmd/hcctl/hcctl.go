@@ -46,12 +46,61 @@ func usage(errorMessage string) {
 	fmt.Fprintln(os.Stderr, listCmdMessage)
 }
 
+// printResult displays the passed JSON-RPC result based on its type.  Objects
+// and arrays are indented, strings are unquoted, and null results are not
+// shown at all.
+func printResult(result []byte) {
+	strResult := string(result)
+	if strings.HasPrefix(strResult, "{") || strings.HasPrefix(strResult, "[") {
+		var dst bytes.Buffer
+		if err := json.Indent(&dst, result, "", "  "); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to format result: %v",
+				err)
+			os.Exit(1)
+		}
+		fmt.Println(dst.String())
+
+	} else if strings.HasPrefix(strResult, `"`) {
+		var str string
+		if err := json.Unmarshal(result, &str); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to unmarshal result: %v",
+				err)
+			os.Exit(1)
+		}
+		fmt.Println(str)
+
+	} else if strResult != "null" {
+		fmt.Println(strResult)
+	}
+}
+
 func main() {
 	cfg, args, err := loadConfig()
 	if err != nil {
 		os.Exit(1)
 	}
 
+	// Commands which describe the methods supported by the RPC server,
+	// rather than those known to hcctl, need to query the server first.
+	if cfg.ListServerCmds || cfg.Completion != "" {
+		methods, err := describeServer(cfg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to describe RPC server "+
+				"commands: %v\n", err)
+			os.Exit(1)
+		}
+		if cfg.ListServerCmds {
+			listServerCommands(methods)
+		} else {
+			appName := filepath.Base(os.Args[0])
+			appName = strings.TrimSuffix(appName,
+				filepath.Ext(appName))
+			fmt.Print(completionScript(cfg.Completion, appName,
+				methods))
+		}
+		os.Exit(0)
+	}
+
 	if len(args) < 1 {
 		usage("No command specified")
 		os.Exit(1)
@@ -142,27 +191,16 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Choose how to display the result based on its type.
-	strResult := string(result)
-	if strings.HasPrefix(strResult, "{") || strings.HasPrefix(strResult, "[") {
-		var dst bytes.Buffer
-		if err := json.Indent(&dst, result, "", "  "); err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to format result: %v",
-				err)
-			os.Exit(1)
-		}
-		fmt.Println(dst.String())
-
-	} else if strings.HasPrefix(strResult, `"`) {
-		var str string
-		if err := json.Unmarshal(result, &str); err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to unmarshal result: %v",
+	// Only show the parts of the result selected by the filter when one
+	// was specified.
+	if cfg.Filter != "" {
+		result, err = filterResult(result, cfg.Filter)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to filter result: %v\n",
 				err)
 			os.Exit(1)
 		}
-		fmt.Println(str)
-
-	} else if strResult != "null" {
-		fmt.Println(strResult)
 	}
+
+	printResult(result)
 }
@@ -7,8 +7,10 @@
 package connmgr
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"math/rand"
 	"net"
 	"sync"
 	"sync/atomic"
@@ -20,14 +22,29 @@ import (
 // be delayed by the configured retry duration.
 const maxFailedAttempts = 25
 
+// maxBackoffAddrs is the number of addresses with a failed connection attempt
+// after which addresses whose backoff has expired are forgotten.
+const maxBackoffAddrs = 1000
+
 var (
 	//ErrDialNil is used to indicate that Dial cannot be nil in the configuration.
 	ErrDialNil = errors.New("config: dial cannot be nil")
 
+	// ErrNetGroupLimit is used to indicate that a new connection request
+	// was not attempted because the maximum number of outbound connections
+	// to the network group of its address has been reached.
+	ErrNetGroupLimit = errors.New("outbound network group limit reached")
+
+	// ErrAddrBackoff is used to indicate that a new connection request was
+	// not attempted because a previous connection attempt to its address
+	// failed and the address is backed off.
+	ErrAddrBackoff = errors.New("address is backed off after failed attempt")
+
 	// maxRetryDuration is the max duration of time retrying of a persistent
 	// connection is allowed to grow to.  This is necessary since the retry
-	// logic uses a backoff mechanism which increases the interval base times
-	// the number of retries that have been done.
+	// logic uses a backoff mechanism which doubles the interval for each
+	// retry that has been done.  It also limits the backoff of addresses
+	// which failed to connect.
 	maxRetryDuration = time.Minute * 5
 
 	// defaultRetryDuration is the default duration of time for retrying
@@ -37,6 +54,10 @@ var (
 	// defaultTargetOutbound is the default number of outbound connections to
 	// maintain.
 	defaultTargetOutbound = uint32(8)
+
+	// defaultDialWorkers is the default number of connection attempts which
+	// are made concurrently.
+	defaultDialWorkers = 8
 )
 
 // ConnState represents the state of the requested connection.
@@ -62,10 +83,30 @@ type ConnReq struct {
 	Addr      net.Addr
 	Permanent bool
 
+	// BlockOnly indicates the connection should only be used to relay
+	// blocks.  It is set by the connection manager for the automatic
+	// connection requests which fill the block-only outbound slots.
+	BlockOnly bool
+
 	conn       net.Conn
 	state      ConnState
 	stateMtx   sync.RWMutex
 	retryCount uint32
+
+	// cancelDial aborts the connection attempt while the request is being
+	// dialed.  It is protected by the state mutex.
+	cancelDial context.CancelFunc
+
+	// reserved indicates the request is counted towards the outbound slot
+	// limits and netGroup is the network group it is counted towards.
+	// They are only set for automatic connection requests.
+	reserved bool
+	netGroup string
+
+	// removed indicates the request was removed while it was being dialed
+	// so it must neither be connected nor retried.  It is only accessed
+	// by the connection handler.
+	removed bool
 }
 
 // updateState updates the state of the connection request.
@@ -75,6 +116,25 @@ func (c *ConnReq) updateState(state ConnState) {
 	c.stateMtx.Unlock()
 }
 
+// setCancelDial sets the function which aborts the connection attempt of the
+// request.
+func (c *ConnReq) setCancelDial(cancel context.CancelFunc) {
+	c.stateMtx.Lock()
+	c.cancelDial = cancel
+	c.stateMtx.Unlock()
+}
+
+// abortDial aborts the connection attempt of the request if it is being
+// dialed.
+func (c *ConnReq) abortDial() {
+	c.stateMtx.RLock()
+	cancel := c.cancelDial
+	c.stateMtx.RUnlock()
+	if cancel != nil {
+		cancel()
+	}
+}
+
 // ID returns a unique identifier for the connection request.
 func (c *ConnReq) ID() uint64 {
 	return atomic.LoadUint64(&c.id)
@@ -125,10 +185,30 @@ type Config struct {
 	// maintain. Defaults to 8.
 	TargetOutbound uint32
 
-	// RetryDuration is the duration to wait before retrying connection
-	// requests. Defaults to 5s.
+	// BlockOnlyOutbound is the number of the automatic outbound
+	// connections maintained according to TargetOutbound which only relay
+	// blocks.  Their connection requests have BlockOnly set.  Defaults to
+	// none and is limited to TargetOutbound.
+	BlockOnlyOutbound uint32
+
+	// MaxOutboundPerNetGroup is the maximum number of automatic outbound
+	// connections to addresses in the same network group, that is the
+	// same /16 for IPv4 and /32 for IPv6 addresses, so the outbound peers
+	// can't all come from a single hosting provider.  Permanent connection
+	// requests are not limited.  Defaults to no limit.
+	MaxOutboundPerNetGroup uint32
+
+	// RetryDuration is the base duration to wait before retrying
+	// connection requests.  The duration doubles with each failed attempt
+	// to the same address up to a maximum of 5 minutes and is randomized
+	// so many connections don't retry in lockstep.  Defaults to 5s.
 	RetryDuration time.Duration
 
+	// DialWorkers is the number of connection attempts which are made
+	// concurrently.  Further connection requests are queued until a
+	// worker is available.  Defaults to 8.
+	DialWorkers int
+
 	// OnConnection is a callback that is fired when a new outbound
 	// connection is established.
 	OnConnection func(*ConnReq, net.Conn)
@@ -141,8 +221,20 @@ type Config struct {
 	// to.  If nil, no new connections will be made automatically.
 	GetNewAddress func() (net.Addr, error)
 
-	// Dial connects to the address on the named network. It cannot be nil.
+	// Dial connects to the address on the named network.  Either it or
+	// DialContext must be set.  Connection attempts made with it are
+	// abandoned when they are canceled, but the dial itself runs to
+	// completion and any resulting connection is closed.
 	Dial func(net.Addr) (net.Conn, error)
+
+	// DialContext connects to the address on the named network and aborts
+	// when the passed context is canceled.  It takes precedence over Dial.
+	DialContext func(context.Context, net.Addr) (net.Conn, error)
+}
+
+// registerPending is used to track a connection request while it is dialed.
+type registerPending struct {
+	c *ConnReq
 }
 
 // handleConnected is used to queue a successful connection.
@@ -163,6 +255,18 @@ type handleFailed struct {
 	err error
 }
 
+// setTargetOutbound is used to change the target number of outbound
+// connections.
+type setTargetOutbound struct {
+	target uint32
+}
+
+// addrBackoff tracks the failed connection attempts to an address.
+type addrBackoff struct {
+	failures uint32
+	retryAt  time.Time
+}
+
 // ConnManager provides a manager to handle network connections.
 type ConnManager struct {
 	// The following variables must only be used atomically.
@@ -174,25 +278,170 @@ type ConnManager struct {
 	wg             sync.WaitGroup
 	failedAttempts uint64
 	requests       chan interface{}
+	dialQueue      chan *ConnReq
 	quit           chan struct{}
+
+	// ctx is canceled when the connection manager is stopped in order to
+	// abort all connection attempts in progress.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// The following fields track the outbound slots taken by automatic
+	// connection requests and the addresses which are backed off after a
+	// failed connection attempt.  They are protected by the slots mutex.
+	slotsMtx     sync.Mutex
+	netGroups    map[string]uint32
+	numBlockOnly uint32
+	backoffs     map[string]*addrBackoff
+}
+
+// retryDelay returns the duration to wait before the next connection attempt
+// after the passed number of successive failed attempts.  The duration doubles
+// with each attempt up to the maximum retry duration and is randomized to
+// between half and all of it.
+func retryDelay(base time.Duration, attempts uint32) time.Duration {
+	d := base
+	for i := uint32(1); i < attempts && d < maxRetryDuration; i++ {
+		d *= 2
+	}
+	if d > maxRetryDuration {
+		d = maxRetryDuration
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// netGroup returns the network group of the passed address.  IPv4 addresses
+// are grouped by /16 and IPv6 addresses by /32.  Addresses which are not IP
+// addresses are their own group.
+func netGroup(addr net.Addr) string {
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		host = addr.String()
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return host
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4.Mask(net.CIDRMask(16, 32)).String() + "/16"
+	}
+	return ip.Mask(net.CIDRMask(32, 128)).String() + "/32"
+}
+
+// reserveSlot counts the passed automatic connection request towards the
+// outbound slot limits.  It returns ErrAddrBackoff when its address is backed
+// off and ErrNetGroupLimit when the network group of the address already has
+// the maximum number of connections, in which case the request is not
+// counted.  The request is marked as block-only when there are block-only
+// slots left.
+func (cm *ConnManager) reserveSlot(c *ConnReq) error {
+	cm.slotsMtx.Lock()
+	defer cm.slotsMtx.Unlock()
+
+	if b, ok := cm.backoffs[c.Addr.String()]; ok {
+		if time.Now().Before(b.retryAt) {
+			return ErrAddrBackoff
+		}
+	}
+
+	group := netGroup(c.Addr)
+	limit := cm.cfg.MaxOutboundPerNetGroup
+	if limit != 0 && cm.netGroups[group] >= limit {
+		return ErrNetGroupLimit
+	}
+	cm.netGroups[group]++
+	c.netGroup = group
+
+	if cm.numBlockOnly < cm.cfg.BlockOnlyOutbound {
+		cm.numBlockOnly++
+		c.BlockOnly = true
+	}
+	c.reserved = true
+	return nil
+}
+
+// releaseSlot stops counting the passed connection request towards the
+// outbound slot limits.
+func (cm *ConnManager) releaseSlot(c *ConnReq) {
+	if !c.reserved {
+		return
+	}
+
+	cm.slotsMtx.Lock()
+	cm.netGroups[c.netGroup]--
+	if cm.netGroups[c.netGroup] == 0 {
+		delete(cm.netGroups, c.netGroup)
+	}
+	if c.BlockOnly {
+		cm.numBlockOnly--
+	}
+	cm.slotsMtx.Unlock()
+	c.reserved = false
+	c.netGroup = ""
+}
+
+// backoffAddr backs off the passed address after a failed connection attempt
+// so it isn't retried before the retry delay for its number of successive
+// failed attempts has passed.
+func (cm *ConnManager) backoffAddr(addr net.Addr) {
+	now := time.Now()
+	key := addr.String()
+
+	cm.slotsMtx.Lock()
+	defer cm.slotsMtx.Unlock()
+
+	// Forget the addresses whose backoff has expired once too many are
+	// tracked.
+	if len(cm.backoffs) >= maxBackoffAddrs {
+		for k, b := range cm.backoffs {
+			if now.After(b.retryAt) {
+				delete(cm.backoffs, k)
+			}
+		}
+	}
+
+	b, ok := cm.backoffs[key]
+	if !ok {
+		if len(cm.backoffs) >= maxBackoffAddrs {
+			return
+		}
+		b = &addrBackoff{}
+		cm.backoffs[key] = b
+	}
+	b.failures++
+	b.retryAt = now.Add(retryDelay(cm.cfg.RetryDuration, b.failures))
+}
+
+// resetBackoff forgets the failed connection attempts to the passed address.
+func (cm *ConnManager) resetBackoff(addr net.Addr) {
+	cm.slotsMtx.Lock()
+	delete(cm.backoffs, addr.String())
+	cm.slotsMtx.Unlock()
+}
+
+// sendRequest sends the passed message to the connection handler.  It returns
+// false without sending it when the connection manager is stopped.
+func (cm *ConnManager) sendRequest(msg interface{}) bool {
+	select {
+	case cm.requests <- msg:
+		return true
+	case <-cm.quit:
+		return false
+	}
 }
 
 // handleFailedConn handles a connection failed due to a disconnect or any
 // other failure. If permanent, it retries the connection after the configured
-// retry duration. Otherwise, if required, it makes a new connection request.
-// After maxFailedConnectionAttempts new connections will be retried after the
-// configured retry duration.
+// retry duration, doubled for each successive retry. Otherwise, if required,
+// it makes a new connection request. After maxFailedConnectionAttempts new
+// connections will be retried after the configured retry duration.
 func (cm *ConnManager) handleFailedConn(c *ConnReq) {
 	if atomic.LoadInt32(&cm.stop) != 0 {
 		return
 	}
-	// TODO Check for canceled connection before connect
 	if c.Permanent {
 		c.retryCount++
-		d := time.Duration(c.retryCount) * cm.cfg.RetryDuration
-		if d > maxRetryDuration {
-			d = maxRetryDuration
-		}
+		d := retryDelay(cm.cfg.RetryDuration, c.retryCount)
 		log.Debugf("Retrying connection to %v in %v", c, d)
 		time.AfterFunc(d, func() {
 			cm.Connect(c)
@@ -220,14 +469,29 @@ func (cm *ConnManager) handleFailedConn(c *ConnReq) {
 // are processed and mapped by their assigned ids.
 func (cm *ConnManager) connHandler() {
 	conns := make(map[uint64]*ConnReq, cm.cfg.TargetOutbound)
+	pending := make(map[uint64]*ConnReq)
 out:
 	for {
 		select {
 		case req := <-cm.requests:
 			switch msg := req.(type) {
 
+			case registerPending:
+				connReq := msg.c
+				connReq.removed = false
+				pending[connReq.id] = connReq
+
 			case handleConnected:
 				connReq := msg.c
+				delete(pending, connReq.id)
+				if connReq.removed {
+					log.Debugf("Ignoring connection to removed %v",
+						connReq)
+					msg.conn.Close()
+					cm.releaseSlot(connReq)
+					continue
+				}
+
 				connReq.updateState(ConnEstablished)
 				connReq.conn = msg.conn
 				conns[connReq.id] = connReq
@@ -240,30 +504,68 @@ out:
 				}
 
 			case handleDisconnected:
-				if connReq, ok := conns[msg.id]; ok {
-					connReq.updateState(ConnDisconnected)
-					if connReq.conn != nil {
-						connReq.conn.Close()
+				connReq, ok := conns[msg.id]
+				if !ok {
+					// Abort the connection attempt when the
+					// request is still being dialed.  It is
+					// retried on failure unless it was removed.
+					if connReq, ok := pending[msg.id]; ok {
+						connReq.removed = !msg.retry
+						connReq.abortDial()
+						continue
 					}
-					log.Debugf("Disconnected from %v", connReq)
-					delete(conns, msg.id)
+					log.Errorf("Unknown connection: %d", msg.id)
+					continue
+				}
 
-					if cm.cfg.OnDisconnection != nil {
-						go cm.cfg.OnDisconnection(connReq)
-					}
+				connReq.updateState(ConnDisconnected)
+				if connReq.conn != nil {
+					connReq.conn.Close()
+				}
+				log.Debugf("Disconnected from %v", connReq)
+				delete(conns, msg.id)
+				cm.releaseSlot(connReq)
 
-					if uint32(len(conns)) < cm.cfg.TargetOutbound && msg.retry {
-						cm.handleFailedConn(connReq)
-					}
-				} else {
-					log.Errorf("Unknown connection: %d", msg.id)
+				if cm.cfg.OnDisconnection != nil {
+					go cm.cfg.OnDisconnection(connReq)
+				}
+
+				if uint32(len(conns)) < cm.cfg.TargetOutbound && msg.retry {
+					cm.handleFailedConn(connReq)
 				}
 
 			case handleFailed:
 				connReq := msg.c
+				delete(pending, connReq.id)
 				connReq.updateState(ConnFailed)
 				log.Debugf("Failed to connect to %v: %v", connReq, msg.err)
+				cm.releaseSlot(connReq)
+				if connReq.removed {
+					continue
+				}
 				cm.handleFailedConn(connReq)
+
+			case setTargetOutbound:
+				atomic.StoreUint32(&cm.cfg.TargetOutbound, msg.target)
+
+				// Make new automatic connection requests until
+				// the new target is reached.  Connections above
+				// it are kept, but not replaced once they are
+				// disconnected.
+				var automatic uint32
+				for _, connReq := range conns {
+					if !connReq.Permanent {
+						automatic++
+					}
+				}
+				for _, connReq := range pending {
+					if !connReq.Permanent {
+						automatic++
+					}
+				}
+				for i := automatic; i < msg.target; i++ {
+					go cm.NewConnReq()
+				}
 			}
 
 		case <-cm.quit:
@@ -290,17 +592,21 @@ func (cm *ConnManager) NewConnReq() {
 
 	addr, err := cm.cfg.GetNewAddress()
 	if err != nil {
-		cm.requests <- handleFailed{c, err}
+		cm.sendRequest(handleFailed{c, err})
 		return
 	}
 
 	c.Addr = addr
+	if err := cm.reserveSlot(c); err != nil {
+		cm.sendRequest(handleFailed{c, err})
+		return
+	}
 
 	cm.Connect(c)
 }
 
-// Connect assigns an id and dials a connection to the address of the
-// connection request.
+// Connect assigns an id and queues the connection request to be dialed by the
+// next available dial worker.
 func (cm *ConnManager) Connect(c *ConnReq) {
 	if atomic.LoadInt32(&cm.stop) != 0 {
 		return
@@ -308,32 +614,118 @@ func (cm *ConnManager) Connect(c *ConnReq) {
 	if atomic.LoadUint64(&c.id) == 0 {
 		atomic.StoreUint64(&c.id, atomic.AddUint64(&cm.connReqCount, 1))
 	}
-	log.Debugf("Attempting to connect to %v", c)
-	conn, err := cm.cfg.Dial(c.Addr)
-	if err != nil {
-		cm.requests <- handleFailed{c, err}
-	} else {
-		cm.requests <- handleConnected{c, conn}
+	c.updateState(ConnPending)
+	if !cm.sendRequest(registerPending{c}) {
+		return
+	}
+	select {
+	case cm.dialQueue <- c:
+	case <-cm.quit:
 	}
 }
 
+// dial connects to the passed address and aborts when the passed context is
+// canceled.
+func (cm *ConnManager) dial(ctx context.Context, addr net.Addr) (net.Conn, error) {
+	if cm.cfg.DialContext != nil {
+		return cm.cfg.DialContext(ctx, addr)
+	}
+
+	// Abandon the dial on cancellation and close the connection it
+	// eventually returns, if any.
+	type dialResult struct {
+		conn net.Conn
+		err  error
+	}
+	result := make(chan dialResult, 1)
+	go func() {
+		conn, err := cm.cfg.Dial(addr)
+		result <- dialResult{conn, err}
+	}()
+	select {
+	case r := <-result:
+		return r.conn, r.err
+	case <-ctx.Done():
+		go func() {
+			if r := <-result; r.conn != nil {
+				r.conn.Close()
+			}
+		}()
+		return nil, ctx.Err()
+	}
+}
+
+// dialHandler dials the queued connection requests one at a time.  It must be
+// run as a goroutine.
+func (cm *ConnManager) dialHandler() {
+out:
+	for {
+		select {
+		case c := <-cm.dialQueue:
+			ctx, cancel := context.WithCancel(cm.ctx)
+			c.setCancelDial(cancel)
+			log.Debugf("Attempting to connect to %v", c)
+			conn, err := cm.dial(ctx, c.Addr)
+			c.setCancelDial(nil)
+			canceled := ctx.Err() != nil
+			cancel()
+
+			// Back off addresses of automatic connection requests
+			// which failed to connect so they aren't retried right
+			// away.
+			if !c.Permanent {
+				if err == nil {
+					cm.resetBackoff(c.Addr)
+				} else if !canceled {
+					cm.backoffAddr(c.Addr)
+				}
+			}
+
+			if err != nil {
+				cm.sendRequest(handleFailed{c, err})
+				continue
+			}
+			if !cm.sendRequest(handleConnected{c, conn}) {
+				conn.Close()
+			}
+
+		case <-cm.quit:
+			break out
+		}
+	}
+
+	cm.wg.Done()
+	log.Trace("Dial handler done")
+}
+
 // Disconnect disconnects the connection corresponding to the given connection
 // id. If permanent, the connection will be retried with an increasing backoff
-// duration.
+// duration.  A connection attempt in progress is aborted.
 func (cm *ConnManager) Disconnect(id uint64) {
 	if atomic.LoadInt32(&cm.stop) != 0 {
 		return
 	}
-	cm.requests <- handleDisconnected{id, true}
+	cm.sendRequest(handleDisconnected{id, true})
 }
 
 // Remove removes the connection corresponding to the given connection
-// id from known connections.
+// id from known connections.  A connection attempt in progress is aborted.
 func (cm *ConnManager) Remove(id uint64) {
 	if atomic.LoadInt32(&cm.stop) != 0 {
 		return
 	}
-	cm.requests <- handleDisconnected{id, false}
+	cm.sendRequest(handleDisconnected{id, false})
+}
+
+// SetTargetOutbound changes the target number of outbound connections to
+// maintain.  New connection requests are made right away when the target is
+// raised, while connections above a lowered target are kept until they are
+// disconnected and then not replaced.
+func (cm *ConnManager) SetTargetOutbound(target uint32) {
+	if atomic.LoadInt32(&cm.stop) != 0 {
+		return
+	}
+	cm.sendRequest(setTargetOutbound{target})
 }
 
 // listenHandler accepts incoming connections on a given listener.  It must be
@@ -367,6 +759,11 @@ func (cm *ConnManager) Start() {
 	cm.wg.Add(1)
 	go cm.connHandler()
 
+	for i := 0; i < cm.cfg.DialWorkers; i++ {
+		cm.wg.Add(1)
+		go cm.dialHandler()
+	}
+
 	// Start all the listeners so long as the caller requested them and
 	// provided a callback to be invoked when connections are accepted.
 	if cm.cfg.OnAccept != nil {
@@ -376,7 +773,8 @@ func (cm *ConnManager) Start() {
 		}
 	}
 
-	for i := atomic.LoadUint64(&cm.connReqCount); i < uint64(cm.cfg.TargetOutbound); i++ {
+	target := uint64(atomic.LoadUint32(&cm.cfg.TargetOutbound))
+	for i := atomic.LoadUint64(&cm.connReqCount); i < target; i++ {
 		go cm.NewConnReq()
 	}
 }
@@ -386,7 +784,8 @@ func (cm *ConnManager) Wait() {
 	cm.wg.Wait()
 }
 
-// Stop gracefully shuts down the connection manager.
+// Stop gracefully shuts down the connection manager.  Connection attempts in
+// progress are aborted.
 func (cm *ConnManager) Stop() {
 	if atomic.AddInt32(&cm.stop, 1) != 1 {
 		log.Warnf("Connection manager already stopped")
@@ -401,6 +800,7 @@ func (cm *ConnManager) Stop() {
 		_ = listener.Close()
 	}
 
+	cm.cancel()
 	close(cm.quit)
 	log.Trace("Connection manager stopped")
 }
@@ -408,7 +808,7 @@ func (cm *ConnManager) Stop() {
 // New returns a new connection manager.
 // Use Start to start connecting to the network.
 func New(cfg *Config) (*ConnManager, error) {
-	if cfg.Dial == nil {
+	if cfg.Dial == nil && cfg.DialContext == nil {
 		return nil, ErrDialNil
 	}
 	// Default to sane values
@@ -418,10 +818,22 @@ func New(cfg *Config) (*ConnManager, error) {
 	if cfg.TargetOutbound == 0 {
 		cfg.TargetOutbound = defaultTargetOutbound
 	}
+	if cfg.BlockOnlyOutbound > cfg.TargetOutbound {
+		cfg.BlockOnlyOutbound = cfg.TargetOutbound
+	}
+	if cfg.DialWorkers <= 0 {
+		cfg.DialWorkers = defaultDialWorkers
+	}
+	ctx, cancel := context.WithCancel(context.Background())
 	cm := ConnManager{
-		cfg:      *cfg, // Copy so caller can't mutate
-		requests: make(chan interface{}),
-		quit:     make(chan struct{}),
+		cfg:       *cfg, // Copy so caller can't mutate
+		requests:  make(chan interface{}),
+		dialQueue: make(chan *ConnReq),
+		quit:      make(chan struct{}),
+		ctx:       ctx,
+		cancel:    cancel,
+		netGroups: make(map[string]uint32),
+		backoffs:  make(map[string]*addrBackoff),
 	}
 	return &cm, nil
 }
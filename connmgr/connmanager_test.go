@@ -5,6 +5,7 @@
 package connmgr
 
 import (
+	"context"
 	"errors"
 	"io"
 	"net"
@@ -218,6 +219,223 @@ func TestTargetOutbound(t *testing.T) {
 	cmgr.Stop()
 }
 
+// TestSetTargetOutbound tests that raising the target number of outbound
+// connections at runtime makes the missing connections right away.
+func TestSetTargetOutbound(t *testing.T) {
+	targetOutbound := uint32(2)
+	newTargetOutbound := uint32(5)
+	connected := make(chan *ConnReq)
+	cmgr, err := New(&Config{
+		TargetOutbound: targetOutbound,
+		Dial:           mockDialer,
+		GetNewAddress: func() (net.Addr, error) {
+			return &net.TCPAddr{
+				IP:   net.ParseIP("127.0.0.1"),
+				Port: 18555,
+			}, nil
+		},
+		OnConnection: func(c *ConnReq, conn net.Conn) {
+			connected <- c
+		},
+	})
+	if err != nil {
+		t.Fatalf("New error: %v", err)
+	}
+	cmgr.Start()
+	for i := uint32(0); i < targetOutbound; i++ {
+		<-connected
+	}
+
+	cmgr.SetTargetOutbound(newTargetOutbound)
+	for i := targetOutbound; i < newTargetOutbound; i++ {
+		select {
+		case <-connected:
+		case <-time.After(time.Second):
+			t.Fatalf("set target outbound: got %d connections, "+
+				"want %d", i, newTargetOutbound)
+		}
+	}
+
+	select {
+	case c := <-connected:
+		t.Fatalf("set target outbound: got unexpected connection - %v",
+			c.Addr)
+	case <-time.After(time.Millisecond):
+		break
+	}
+	cmgr.Stop()
+}
+
+// TestMaxOutboundPerNetGroup tests that the number of automatic outbound
+// connections to the same network group is limited.
+//
+// The first addresses returned all belong to the same /16 so only the limit of
+// them must be connected while the rest of the target outbound connections are
+// made to the addresses in other network groups returned afterwards.
+func TestMaxOutboundPerNetGroup(t *testing.T) {
+	targetOutbound := uint32(4)
+	maxPerNetGroup := uint32(2)
+	var numAddrs uint32
+	connected := make(chan *ConnReq)
+	cmgr, err := New(&Config{
+		TargetOutbound:         targetOutbound,
+		MaxOutboundPerNetGroup: maxPerNetGroup,
+		Dial:                   mockDialer,
+		GetNewAddress: func() (net.Addr, error) {
+			n := atomic.AddUint32(&numAddrs, 1)
+			ip := net.IPv4(10, 0, 0, byte(n))
+			if n > 6 {
+				ip = net.IPv4(10, byte(n), 0, 1)
+			}
+			return &net.TCPAddr{IP: ip, Port: 18555}, nil
+		},
+		OnConnection: func(c *ConnReq, conn net.Conn) {
+			connected <- c
+		},
+	})
+	if err != nil {
+		t.Fatalf("New error: %v", err)
+	}
+	cmgr.Start()
+	var sameGroup uint32
+	for i := uint32(0); i < targetOutbound; i++ {
+		c := <-connected
+		if netGroup(c.Addr) == "10.0.0.0/16" {
+			sameGroup++
+		}
+	}
+	if sameGroup != maxPerNetGroup {
+		t.Fatalf("network group limit: got %d connections to the same "+
+			"network group, want %d", sameGroup, maxPerNetGroup)
+	}
+
+	select {
+	case c := <-connected:
+		t.Fatalf("network group limit: got unexpected connection - %v",
+			c.Addr)
+	case <-time.After(time.Millisecond):
+		break
+	}
+	cmgr.Stop()
+}
+
+// TestBlockOnlyOutbound tests that the configured number of automatic outbound
+// connection requests are marked as block-only and the rest are not.
+func TestBlockOnlyOutbound(t *testing.T) {
+	targetOutbound := uint32(6)
+	blockOnlyOutbound := uint32(2)
+	connected := make(chan *ConnReq)
+	cmgr, err := New(&Config{
+		TargetOutbound:    targetOutbound,
+		BlockOnlyOutbound: blockOnlyOutbound,
+		Dial:              mockDialer,
+		GetNewAddress: func() (net.Addr, error) {
+			return &net.TCPAddr{
+				IP:   net.ParseIP("127.0.0.1"),
+				Port: 18555,
+			}, nil
+		},
+		OnConnection: func(c *ConnReq, conn net.Conn) {
+			connected <- c
+		},
+	})
+	if err != nil {
+		t.Fatalf("New error: %v", err)
+	}
+	cmgr.Start()
+	var blockOnly uint32
+	for i := uint32(0); i < targetOutbound; i++ {
+		if c := <-connected; c.BlockOnly {
+			blockOnly++
+		}
+	}
+	if blockOnly != blockOnlyOutbound {
+		t.Fatalf("block-only outbound: got %d block-only connections, "+
+			"want %d", blockOnly, blockOnlyOutbound)
+	}
+	cmgr.Stop()
+}
+
+// TestRetryDelay ensures the retry delay doubles with each attempt, is
+// randomized to between half and all of the doubled duration and never exceeds
+// the maximum retry duration.
+func TestRetryDelay(t *testing.T) {
+	defer func(d time.Duration) {
+		maxRetryDuration = d
+	}(maxRetryDuration)
+	maxRetryDuration = time.Minute
+
+	tests := []struct {
+		attempts uint32
+		want     time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{6, 32 * time.Second},
+		{7, time.Minute},
+		{100, time.Minute},
+	}
+	for _, test := range tests {
+		for i := 0; i < 100; i++ {
+			d := retryDelay(time.Second, test.attempts)
+			if d < test.want/2 || d > test.want {
+				t.Fatalf("retry delay after %d attempts: got %v, "+
+					"want between %v and %v", test.attempts, d,
+					test.want/2, test.want)
+			}
+		}
+	}
+}
+
+// TestRemovePending tests that removing a connection request while it is being
+// dialed aborts the connection attempt and the request is not retried.
+func TestRemovePending(t *testing.T) {
+	dialing := make(chan struct{})
+	var dials uint32
+	cmgr, err := New(&Config{
+		RetryDuration: time.Millisecond,
+		DialContext: func(ctx context.Context, addr net.Addr) (net.Conn, error) {
+			atomic.AddUint32(&dials, 1)
+			dialing <- struct{}{}
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+		OnConnection: func(c *ConnReq, conn net.Conn) {
+			t.Fatalf("remove pending: got unexpected connection - %v",
+				c.Addr)
+		},
+	})
+	if err != nil {
+		t.Fatalf("New error: %v", err)
+	}
+	cmgr.Start()
+	cr := &ConnReq{
+		Addr: &net.TCPAddr{
+			IP:   net.ParseIP("127.0.0.1"),
+			Port: 18555,
+		},
+		Permanent: true,
+	}
+	go cmgr.Connect(cr)
+	<-dialing
+	cmgr.Remove(cr.ID())
+
+	select {
+	case <-dialing:
+		t.Fatal("remove pending: removed request was retried")
+	case <-time.After(20 * time.Millisecond):
+	}
+	if got := cr.State(); got != ConnFailed {
+		t.Fatalf("remove pending: got state %v, want %v", got, ConnFailed)
+	}
+	cmgr.Stop()
+	cmgr.Wait()
+	if got := atomic.LoadUint32(&dials); got != 1 {
+		t.Fatalf("remove pending: got %d dials, want 1", got)
+	}
+}
+
 // TestRetryPermanent tests that permanent connection requests are retried.
 //
 // We make a permanent connection request using Connect, disconnect it using
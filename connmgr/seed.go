@@ -5,6 +5,7 @@
 package connmgr
 
 import (
+	"fmt"
 	mrand "math/rand"
 	"net"
 	"strconv"
@@ -29,8 +30,20 @@ type OnSeed func(addrs []*wire.NetAddress)
 type LookupFunc func(string) ([]net.IP, error)
 
 // SeedFromDNS uses DNS seeding to populate the address manager with peers.
-func SeedFromDNS(chainParams *chaincfg.Params, lookupFn LookupFunc, seedFn OnSeed) {
-	for _, seeder := range chainParams.DNSSeeds {
+// Seeds which support filtering by service flags are asked for nodes which
+// advertise all of the required services only.  The returned addresses are
+// assumed to provide the required services.
+func SeedFromDNS(chainParams *chaincfg.Params, reqServices wire.ServiceFlag,
+	lookupFn LookupFunc, seedFn OnSeed) {
+
+	for _, dnsseed := range chainParams.DNSSeeds {
+		var host string
+		if !dnsseed.HasFiltering || reqServices == wire.SFNodeNetwork {
+			host = dnsseed.Host
+		} else {
+			host = fmt.Sprintf("x%x.%s", uint64(reqServices), dnsseed.Host)
+		}
+
 		go func(seeder string) {
 			randSource := mrand.New(mrand.NewSource(time.Now().UnixNano()))
 
@@ -56,10 +69,10 @@ func SeedFromDNS(chainParams *chaincfg.Params, lookupFn LookupFunc, seedFn OnSee
 					// and 7 days ago.
 					time.Now().Add(-1*time.Second*time.Duration(secondsIn3Days+
 						randSource.Int31n(secondsIn4Days))),
-					0, peer, uint16(intPort))
+					reqServices, peer, uint16(intPort))
 			}
 
 			seedFn(addresses)
-		}(seeder)
+		}(host)
 	}
 }
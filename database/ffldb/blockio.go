@@ -430,9 +430,23 @@ func (s *blockStore) writeBlock(rawBlock []byte) (blockLocation, error) {
 		// with LRU tracking.  The close is done under the write lock
 		// for the file to prevent it from being closed out from under
 		// any readers currently reading from it.
+		//
+		// The file is synced first since only the current write file is
+		// synced when the metadata is flushed.  Otherwise, an unclean
+		// shutdown could lose the end of the file while the metadata,
+		// which only notices a truncated last file, claims its blocks
+		// are stored.
 		wc.Lock()
 		wc.curFile.Lock()
 		if wc.curFile.file != nil {
+			if err := wc.curFile.file.Sync(); err != nil {
+				wc.curFile.Unlock()
+				wc.Unlock()
+				str := fmt.Sprintf("failed to sync file %d: %v",
+					wc.curFileNum, err)
+				return blockLocation{}, makeDbErr(
+					database.ErrDriverSpecific, str, err)
+			}
 			_ = wc.curFile.file.Close()
 			wc.curFile.file = nil
 		}
@@ -1191,6 +1191,65 @@ func (tx *transaction) StoreBlock(block *hcutil.Block) error {
 	return nil
 }
 
+// RepairBlock stores the provided block in place of the stored copy of the
+// same block.  The provided copy is written to the end of the block files and
+// the block index is updated to point to it, so the stored copy is no longer
+// read, even when it can't be read at all.
+//
+// Returns the following errors as required by the interface contract:
+//   - ErrBlockNotFound when the block hash does not exist
+//   - ErrTxNotWritable if attempted against a read-only transaction
+//   - ErrTxClosed if the transaction has already been closed
+//
+// This function is part of the database.Tx interface implementation.
+func (tx *transaction) RepairBlock(block *hcutil.Block) error {
+	// Ensure transaction state is valid.
+	if err := tx.checkClosed(); err != nil {
+		return err
+	}
+
+	// Ensure the transaction is writable.
+	if !tx.writable {
+		str := "repair block requires a writable database transaction"
+		return makeDbErr(database.ErrTxNotWritable, str, nil)
+	}
+
+	// Reject the block if it does not exist.
+	blockHash := block.Hash()
+	if !tx.hasBlock(blockHash) {
+		str := fmt.Sprintf("block %s does not exist", blockHash)
+		return makeDbErr(database.ErrBlockNotFound, str, nil)
+	}
+
+	blockBytes, err := block.Bytes()
+	if err != nil {
+		str := fmt.Sprintf("failed to get serialized bytes for block %s",
+			blockHash)
+		return makeDbErr(database.ErrDriverSpecific, str, err)
+	}
+
+	// Replace the block when it is still pending to be written.
+	if idx, exists := tx.pendingBlocks[*blockHash]; exists {
+		tx.pendingBlockData[idx].bytes = blockBytes
+		return nil
+	}
+
+	// Add the block to the list of pending blocks to store when the
+	// transaction is committed, which also replaces the record in the block
+	// index for the block.
+	if tx.pendingBlocks == nil {
+		tx.pendingBlocks = make(map[chainhash.Hash]int)
+	}
+	tx.pendingBlocks[*blockHash] = len(tx.pendingBlockData)
+	tx.pendingBlockData = append(tx.pendingBlockData, pendingBlock{
+		hash:  blockHash,
+		bytes: blockBytes,
+	})
+	log.Tracef("Added block %s to pending blocks for repair", blockHash)
+
+	return nil
+}
+
 // HasBlock returns whether or not a block with the given hash exists in the
 // database.
 //
@@ -2042,7 +2101,18 @@ func initDB(ldb *leveldb.DB) error {
 
 // openDB opens the database at the provided path.  database.ErrDbDoesNotExist
 // is returned if the database doesn't exist and the create flag is not set.
-func openDB(dbPath string, network wire.CurrencyNet, create bool) (database.DB, error) {
+func openDB(dbPath, blocksPath string, network wire.CurrencyNet, create bool) (database.DB, error) {
+	// Resolve relative paths once, so the block files, which are opened
+	// and created on demand, are not affected by later changes of the
+	// working directory.
+	var err error
+	if dbPath, err = filepath.Abs(dbPath); err != nil {
+		return nil, convertErr(err.Error(), err)
+	}
+	if blocksPath, err = filepath.Abs(blocksPath); err != nil {
+		return nil, convertErr(err.Error(), err)
+	}
+
 	// Error if the database doesn't exist and the create flag is not set.
 	metadataDbPath := filepath.Join(dbPath, metadataDbName)
 	dbExists := fileExists(metadataDbPath)
@@ -2059,6 +2129,24 @@ func openDB(dbPath string, network wire.CurrencyNet, create bool) (database.DB,
 		_ = os.MkdirAll(dbPath, 0700)
 	}
 
+	// Ensure the blocks path exists when the block files are stored
+	// separately from the metadata.  Refuse to open an existing database
+	// whose block files are still stored with the metadata, since they
+	// would otherwise be considered missing.
+	if blocksPath != dbPath {
+		if dbExists && fileExists(blockFilePath(dbPath, 0)) &&
+			!fileExists(blockFilePath(blocksPath, 0)) {
+
+			str := fmt.Sprintf("the block files of the database are "+
+				"stored in %q instead of the blocks path %q -- "+
+				"move them to the blocks path", dbPath, blocksPath)
+			return nil, makeDbErr(database.ErrDriverSpecific, str, nil)
+		}
+		if err := os.MkdirAll(blocksPath, 0700); err != nil {
+			return nil, convertErr(err.Error(), err)
+		}
+	}
+
 	// Open the metadata database (will create it if needed).
 	opts := opt.Options{
 		ErrorIfExist: create,
@@ -2076,7 +2164,7 @@ func openDB(dbPath string, network wire.CurrencyNet, create bool) (database.DB,
 	// according to the data that is actually on disk.  Also create the
 	// database cache which wraps the underlying leveldb database to provide
 	// write caching.
-	store := newBlockStore(dbPath, network)
+	store := newBlockStore(blocksPath, network)
 	cache := newDbCache(ldb, store, defaultCacheSize, defaultFlushSecs)
 	pdb := &db{store: store, cache: cache}
 
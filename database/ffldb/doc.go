@@ -26,5 +26,15 @@ database path as a string and the block network:
 	if err != nil {
 		// Handle error
 	}
+
+The flat block files are stored in the database path along with the metadata
+unless the path to store them in is passed as an additional string parameter,
+such as to keep the blocks on a larger and slower volume than the metadata:
+
+	db, err := database.Open("ffldb", "path/to/database", wire.MainNet,
+		"path/to/blocks")
+	if err != nil {
+		// Handle error
+	}
 */
 package ffldb
@@ -19,49 +19,61 @@ const (
 	dbType = "ffldb"
 )
 
-// parseArgs parses the arguments from the database Open/Create methods.
-func parseArgs(funcName string, args ...interface{}) (string, wire.CurrencyNet, error) {
-	if len(args) != 2 {
-		return "", 0, fmt.Errorf("invalid arguments to %s.%s -- "+
-			"expected database path and block network", dbType,
-			funcName)
+// parseArgs parses the arguments from the database Open/Create methods.  The
+// blocks path defaults to the database path when it is not provided.
+func parseArgs(funcName string, args ...interface{}) (string, string, wire.CurrencyNet, error) {
+	if len(args) != 2 && len(args) != 3 {
+		return "", "", 0, fmt.Errorf("invalid arguments to %s.%s -- "+
+			"expected database path, block network and optional "+
+			"blocks path", dbType, funcName)
 	}
 
 	dbPath, ok := args[0].(string)
 	if !ok {
-		return "", 0, fmt.Errorf("first argument to %s.%s is invalid -- "+
-			"expected database path string", dbType, funcName)
+		return "", "", 0, fmt.Errorf("first argument to %s.%s is "+
+			"invalid -- expected database path string", dbType,
+			funcName)
 	}
 
 	network, ok := args[1].(wire.CurrencyNet)
 	if !ok {
-		return "", 0, fmt.Errorf("second argument to %s.%s is invalid -- "+
-			"expected block network", dbType, funcName)
+		return "", "", 0, fmt.Errorf("second argument to %s.%s is "+
+			"invalid -- expected block network", dbType, funcName)
+	}
+
+	blocksPath := dbPath
+	if len(args) == 3 {
+		blocksPath, ok = args[2].(string)
+		if !ok {
+			return "", "", 0, fmt.Errorf("third argument to %s.%s "+
+				"is invalid -- expected blocks path string",
+				dbType, funcName)
+		}
 	}
 
-	return dbPath, network, nil
+	return dbPath, blocksPath, network, nil
 }
 
 // openDBDriver is the callback provided during driver registration that opens
 // an existing database for use.
 func openDBDriver(args ...interface{}) (database.DB, error) {
-	dbPath, network, err := parseArgs("Open", args...)
+	dbPath, blocksPath, network, err := parseArgs("Open", args...)
 	if err != nil {
 		return nil, err
 	}
 
-	return openDB(dbPath, network, false)
+	return openDB(dbPath, blocksPath, network, false)
 }
 
 // createDBDriver is the callback provided during driver registration that
 // creates, initializes, and opens a database for use.
 func createDBDriver(args ...interface{}) (database.DB, error) {
-	dbPath, network, err := parseArgs("Create", args...)
+	dbPath, blocksPath, network, err := parseArgs("Create", args...)
 	if err != nil {
 		return nil, err
 	}
 
-	return openDB(dbPath, network, true)
+	return openDB(dbPath, blocksPath, network, true)
 }
 
 // useLogger is the callback provided during driver registration that sets the
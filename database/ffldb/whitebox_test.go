@@ -157,7 +157,7 @@ func TestCornerCases(t *testing.T) {
 	// directory is needed.
 	testName := "openDB: fail due to file at target location"
 	wantErrCode := database.ErrDriverSpecific
-	idb, err := openDB(dbPath, blockDataNet, true)
+	idb, err := openDB(dbPath, dbPath, blockDataNet, true)
 	if !checkDbError(t, testName, err, wantErrCode) {
 		if err == nil {
 			idb.Close()
@@ -169,7 +169,7 @@ func TestCornerCases(t *testing.T) {
 	// Remove the file and create the database to run tests against.  It
 	// should be successful this time.
 	_ = os.RemoveAll(dbPath)
-	idb, err = openDB(dbPath, blockDataNet, true)
+	idb, err = openDB(dbPath, dbPath, blockDataNet, true)
 	if err != nil {
 		t.Errorf("openDB: unexpected error: %v", err)
 		return
@@ -682,3 +682,200 @@ func TestFailureScenarios(t *testing.T) {
 	// Test various corruption scenarios.
 	testCorruption(tc)
 }
+
+// TestBlockFileRolloverSync ensures the current write file is synced before
+// the block store moves to the next file, since only the current write file is
+// synced when the metadata is flushed.
+func TestBlockFileRolloverSync(t *testing.T) {
+	t.Parallel()
+
+	files := make(map[uint32]*mockFile)
+	store := newBlockStore(t.TempDir(), blockDataNet)
+	store.maxBlockFileSize = 100
+	store.openWriteFileFunc = func(fileNum uint32) (filer, error) {
+		file, ok := files[fileNum]
+		if !ok {
+			file = &mockFile{maxSize: -1}
+			files[fileNum] = file
+		}
+		file.closed = false
+		return file, nil
+	}
+
+	// Fill the first file, then ensure a failure to sync it fails the
+	// write which moves to the next file.
+	rawBlock := make([]byte, 60)
+	if _, err := store.writeBlock(rawBlock); err != nil {
+		t.Fatalf("writeBlock: unexpected error: %v", err)
+	}
+	files[0].forceSyncErr = true
+	_, err := store.writeBlock(rawBlock)
+	if !checkDbError(t, "writeBlock: rollover sync failure", err,
+		database.ErrDriverSpecific) {
+		return
+	}
+	if len(files) != 1 {
+		t.Fatalf("writeBlock: moved to the next file despite the sync " +
+			"failure")
+	}
+
+	// Ensure the write moves to the next file once the sync succeeds.
+	files[0].forceSyncErr = false
+	loc, err := store.writeBlock(rawBlock)
+	if err != nil {
+		t.Fatalf("writeBlock: unexpected error: %v", err)
+	}
+	if loc.blockFileNum != 1 {
+		t.Fatalf("writeBlock: unexpected block file - got %d, want 1",
+			loc.blockFileNum)
+	}
+	if !files[0].closed {
+		t.Fatal("writeBlock: previous write file was not closed")
+	}
+}
+
+// TestRepairBlock ensures a stored block which is corrupted in the block files
+// can be replaced with an intact copy.
+func TestRepairBlock(t *testing.T) {
+	t.Parallel()
+
+	dbPath := t.TempDir()
+	idb, err := database.Create(dbType, dbPath, blockDataNet)
+	if err != nil {
+		t.Fatalf("Failed to create test database (%s) %v", dbType, err)
+	}
+	defer idb.Close()
+
+	block := hcutil.NewBlock(&wire.MsgBlock{
+		Header: wire.BlockHeader{Version: 1, Nonce: 1},
+	})
+	blockBytes, _ := block.Bytes()
+
+	// Ensure a block which does not exist can't be repaired.
+	err = idb.Update(func(tx database.Tx) error {
+		return tx.RepairBlock(block)
+	})
+	if !checkDbError(t, "RepairBlock: missing block", err,
+		database.ErrBlockNotFound) {
+		return
+	}
+
+	err = idb.Update(func(tx database.Tx) error {
+		return tx.StoreBlock(block)
+	})
+	if err != nil {
+		t.Fatalf("StoreBlock: unexpected error: %v", err)
+	}
+
+	// Corrupt the stored copy of the block after the network and length
+	// fields and ensure it fails the checksum.
+	file, err := os.OpenFile(blockFilePath(dbPath, 0), os.O_RDWR, 0)
+	if err != nil {
+		t.Fatalf("Failed to open block file: %v", err)
+	}
+	if _, err := file.WriteAt([]byte{0xff}, 8); err != nil {
+		t.Fatalf("Failed to corrupt block file: %v", err)
+	}
+	file.Close()
+	err = idb.View(func(tx database.Tx) error {
+		_, err := tx.FetchBlock(block.Hash())
+		return err
+	})
+	if !checkDbError(t, "FetchBlock: corrupted block", err,
+		database.ErrCorruption) {
+		return
+	}
+
+	// Ensure the repaired block is read from the intact copy.
+	err = idb.Update(func(tx database.Tx) error {
+		return tx.RepairBlock(block)
+	})
+	if err != nil {
+		t.Fatalf("RepairBlock: unexpected error: %v", err)
+	}
+	err = idb.View(func(tx database.Tx) error {
+		gotBytes, err := tx.FetchBlock(block.Hash())
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(gotBytes, blockBytes) {
+			return fmt.Errorf("got block %x, want %x", gotBytes,
+				blockBytes)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("FetchBlock: repaired block: %v", err)
+	}
+}
+
+// TestSeparateBlocksPath ensures the block files are stored in the blocks path
+// when it differs from the database path, relative blocks paths are resolved
+// when the database is opened, and a database whose block files are not in the
+// blocks path is not opened.
+func TestSeparateBlocksPath(t *testing.T) {
+	tempDir := t.TempDir()
+	oldWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatalf("Failed to change working directory: %v", err)
+	}
+	defer os.Chdir(oldWd)
+
+	dbPath := filepath.Join(tempDir, "db")
+	idb, err := openDB(dbPath, "blocks", blockDataNet, true)
+	if err != nil {
+		t.Fatalf("Failed to create test database (%s) %v", dbType, err)
+	}
+
+	// Change the working directory before storing a block to ensure the
+	// block files are created relative to the original one.
+	if err := os.Chdir(oldWd); err != nil {
+		t.Fatalf("Failed to change working directory: %v", err)
+	}
+	block := hcutil.NewBlock(&wire.MsgBlock{
+		Header: wire.BlockHeader{Version: 1, Nonce: 1},
+	})
+	err = idb.Update(func(tx database.Tx) error {
+		return tx.StoreBlock(block)
+	})
+	if err != nil {
+		t.Fatalf("StoreBlock: unexpected error: %v", err)
+	}
+	idb.Close()
+
+	blocksPath := filepath.Join(tempDir, "blocks")
+	if !fileExists(blockFilePath(blocksPath, 0)) {
+		t.Fatal("block file not stored in the blocks path")
+	}
+	if fileExists(blockFilePath(dbPath, 0)) {
+		t.Fatal("block file stored in the database path")
+	}
+
+	// Ensure the stored block is available when the database is opened
+	// with the same blocks path again.
+	idb, err = openDB(dbPath, blocksPath, blockDataNet, false)
+	if err != nil {
+		t.Fatalf("Failed to open test database (%s) %v", dbType, err)
+	}
+	err = idb.View(func(tx database.Tx) error {
+		_, err := tx.FetchBlock(block.Hash())
+		return err
+	})
+	idb.Close()
+	if err != nil {
+		t.Fatalf("FetchBlock: unexpected error: %v", err)
+	}
+
+	// Ensure a database whose block files are stored with the metadata is
+	// not opened with a separate blocks path.
+	err = os.Rename(blockFilePath(blocksPath, 0), blockFilePath(dbPath, 0))
+	if err != nil {
+		t.Fatalf("Failed to move block file: %v", err)
+	}
+	_, err = openDB(dbPath, blocksPath, blockDataNet, false)
+	checkDbError(t, "openDB: block files not in blocks path", err,
+		database.ErrDriverSpecific)
+}
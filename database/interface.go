@@ -230,6 +230,20 @@ type Tx interface {
 	// Other errors are possible depending on the implementation.
 	StoreBlock(block *hcutil.Block) error
 
+	// RepairBlock stores the provided block in place of the stored copy of
+	// the same block, such as when the stored copy is corrupted.  Like
+	// StoreBlock, it does not validate the block, so the caller must ensure
+	// the provided copy is intact.
+	//
+	// The interface contract guarantees at least the following errors will
+	// be returned (other implementation-specific errors are possible):
+	//   - ErrBlockNotFound when the block hash does not exist
+	//   - ErrTxNotWritable if attempted against a read-only transaction
+	//   - ErrTxClosed if the transaction has already been closed
+	//
+	// Other errors are possible depending on the implementation.
+	RepairBlock(block *hcutil.Block) error
+
 	// HasBlock returns whether or not a block with the given hash exists
 	// in the database.
 	//
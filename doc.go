@@ -26,7 +26,17 @@ Application Options:
   -V, --version             Display version information and exit
   -C, --configfile=         Path to configuration file
   -b, --datadir=            Directory to store data
+      --blocksdir=          Directory to store the block files of the block
+                            database in instead of the data directory
       --logdir=             Directory to log output.
+      --shutdowntimeout=    Maximum time to wait for the subsystems to stop on
+                            shutdown before exiting without closing the
+                            database.  Valid time units are {s, m, h}.  0 to
+                            wait indefinitely (2m0s)
+      --dbverifyinterval=   Verify the checksums, hashes and merkle roots of the
+                            stored blocks at this interval and repair the ones
+                            which fail with copies from peers.  Valid time units
+                            are {s, m, h}.  0 to disable (0s)
   -a, --addpeer=            Add a peer to connect with at startup
       --connect=            Connect only to the specified peers at startup
       --nolisten            Disable listening for incoming connections -- NOTE:
@@ -39,10 +49,33 @@ Application Options:
       --nobanning           Disable banning of misbehaving peers
       --banduration=        How long to ban misbehaving peers.  Valid time units
                             are {s, m, h}.  Minimum 1 second (24h0m0s)
+      --syncstalltimeout=   Switch to another sync peer when the current one
+                            does not deliver any of the requested blocks or
+                            headers within this time while syncing.  Valid
+                            time units are {s, m, h}.  0 to disable (2m0s)
       --banthreshold=       Maximum allowed ban score before disconnecting and
                             banning misbehaving peers.
       --whitelist=          Add an IP network or IP that will not be banned.
                             (eg. 192.168.1.0/24 or ::1)
+      --blacklist=          Add an IP network or IP that will be refused
+                            connections, unless whitelisted.
+                            (eg. 192.168.1.0/24 or ::1)
+      --getdatarate=        Max inventory items per second a peer may request
+                            via getdata before its ban score is increased (0
+                            to disable) (5000)
+      --getheadersrate=     Max getheaders messages per second a peer may send
+                            before its ban score is increased (0 to disable)
+                            (10)
+      --mempoolrate=        Max mempool messages per second a peer may send
+                            before its ban score is increased (0 to disable)
+                            (0.1)
+      --trickleinterval=    Average interval between the randomly timed batches
+                            of transaction inventory sent to outbound peers.
+                            Valid time units are {ms, s, m, h} (500ms)
+      --inboundtrickleinterval=
+                            Average interval between the randomly timed batches
+                            of transaction inventory sent to inbound peers.
+                            Valid time units are {ms, s, m, h} (1s)
   -u, --rpcuser=            Username for RPC connections
   -P, --rpcpass=            Password for RPC connections
       --rpclimituser=       Username for limited RPC connections
@@ -51,14 +84,42 @@ Application Options:
                             (default port: 14009, testnet: 12009)
       --rpccert=            File containing the certificate file
       --rpckey=             File containing the certificate key
+      --rpcpubliclisten=    Add an interface/port to listen for RPC connections
+                            which are only authorized for the limited RPC
+                            methods regardless of their credentials
+      --rpcpubliccert=      File containing the certificate file for the public
+                            RPC listeners (default: rpccert)
+      --rpcpublickey=       File containing the certificate key for the public
+                            RPC listeners (default: rpckey)
+      --rpccertvalidity=    Validity period of auto-generated RPC certificates.
+                            Valid time units are {s, m, h} (87600h0m0s)
+      --rpccertrotate=      Regenerate auto-generated RPC certificates once they
+                            expire within this duration and notify websocket
+                            clients (0 to disable).  Valid time units are
+                            {s, m, h} (720h0m0s)
+      --rpcextrahost=       Add an IP address or hostname to auto-generated RPC
+                            certificates, which are regenerated when they don't
+                            cover it
+      --rpccookie=          File to write the RPC authentication cookie to
+                            (default: .cookie in the data directory)
+      --norpccookie         Disable RPC authentication with a cookie file
       --rpcmaxclients=      Max number of RPC clients for standard connections
                             (10)
       --rpcmaxwebsockets=   Max number of RPC websocket connections (25)
       --norpc               Disable built-in RPC server -- NOTE: The RPC server
                             is disabled by default if no rpcuser/rpcpass or
-                            rpclimituser/rpclimitpass is specified
+                            rpclimituser/rpclimitpass is specified and cookie
+                            authentication is disabled
       --notls               Disable TLS for the RPC server -- NOTE: This is only
                             allowed if the RPC server is bound to localhost
+      --zmqpubhashblock=    Publish the hashes of connected blocks on the given
+                            interface/port
+      --zmqpubhashtx=       Publish the hashes of transactions accepted to the
+                            mempool on the given interface/port
+      --zmqpubrawblock=     Publish serialized connected blocks on the given
+                            interface/port
+      --zmqpubrawtx=        Publish serialized transactions accepted to the
+                            mempool on the given interface/port
       --nodnsseed           Disable DNS seeding for peers
       --externalip=         Add an ip to the list of local addresses we claim to
                             listen on to peers
@@ -70,19 +131,60 @@ Application Options:
       --onionuser=          Username for onion proxy server
       --onionpass=          Password for onion proxy server
       --noonion             Disable connecting to tor hidden services
+      --onlynet=            Only make automatic outbound connections to
+                            addresses of the specified network {ipv4, ipv6,
+                            onion} -- may be specified multiple times
       --torisolation        Enable Tor stream isolation by randomizing user
                             credentials for each connection.
+      --privacy             Never use the system DNS resolver: resolve all
+                            names, including DNS seeds, through the proxy or,
+                            without a proxy, skip DNS seeding and refuse
+                            lookups
       --testnet             Use the test network
       --simnet              Use the simulation test network
+      --customnet=          Use the custom network defined in the passed JSON
+                            file
       --nocheckpoints       Disable built-in checkpoints.  Don't do this unless
                             you know what you're doing.
+      --assumevalid=        Hash of a block assumed to be valid -- script
+                            validation is skipped for its ancestors during the
+                            initial block download (default: per network, 0 to
+                            disable)
+      --assumevalidheight=  Height of the block passed with --assumevalid --
+                            required with it
+      --maxreorgdepth=      Maximum number of blocks a reorganization may
+                            disconnect without being approved with the
+                            reconsiderblock RPC (0 for unlimited)
       --dbtype=             Database backend to use for the Block Chain (ffldb)
       --profile=            Enable HTTP profiling on given [addr:]port -- NOTE: port
                             must be between 1024 and 65536
       --cpuprofile=         Write CPU profile to the specified file
       --memprofile=         Write mem profile to the specified file
+      --slowquerythreshold= Log RPC calls and database transactions which take
+                            longer than this duration and keep them for the
+                            getslowqueries RPC (0 to disable).  Valid time units
+                            are {s, ms, us} (0s)
+      --lockholdthreshold=  Profile the contention of the mempool and chain
+                            locks for the getlockstats RPC and warn when they
+                            are held for longer than this duration (0 to
+                            disable).  Valid time units are {s, ms, us} (0s)
+      --tracingendpoint=    Export trace spans of RPC requests to the given
+                            OTLP/HTTP traces endpoint (eg.
+                            http://localhost:4318/v1/traces)
       --dumpblockchain=     Write blockchain as a gob-encoded map to the
                             specified file
+      --importbootstrap=    Import and fully validate the blocks of a flat file
+                            of blocks as written by --dumpblockchain, while the
+                            server is running -- may be specified multiple
+                            times to import files in order
+      --reindexchainstate   Rebuild the chain state, such as the utxo set and
+                            the ticket database, from the blocks stored in the
+                            database on start up without downloading them again
+                            -- the optional indexes are rebuilt along with it
+      --reindexindex=       Drop an enabled optional index on start up and
+                            rebuild it in the background once the chain is
+                            synced {txindex, addrindex, existsaddrindex} -- may
+                            be specified multiple times
       --miningtimeoffset=   Offset the mining timestamp of a block by this many
                             seconds (positive values are in the past)
   -d, --debuglevel=         Logging level for all subsystems {trace, debug,
@@ -93,6 +195,12 @@ Application Options:
       --upnp                Use UPnP to map our listening port outside of NAT
       --minrelaytxfee=      The minimum transaction fee in HC/kB to be
                             considered a non-zero fee.
+      --dustrelayfee=       The fee rate in HC/kB used to define dust, the
+                            value of an output below which it is considered
+                            non-standard.
+      --mempoolexpiry=      Evict transactions which have been in the memory
+                            pool for longer than this.  Valid time units are
+                            {s, m, h}.  0 to disable (72h0m0s)
       --limitfreerelay=     Limit relay of transactions with no transaction fee
                             to the given amount in thousands of bytes per
                             minute (15)
@@ -100,11 +208,24 @@ Application Options:
                             high priority for relaying
       --maxorphantx=        Max number of orphan transactions to keep in memory
                             (1000)
+      --maxmempool=         Max size in MB of memory used by the transactions in
+                            the memory pool, after which those with the lowest
+                            fee rate are evicted; 0 to disable (300)
       --generate            Generate (mine) bitcoins using the CPU
+      --genproclimit=       Number of goroutines the nonce space is split
+                            between when generating coins using the CPU --
+                            Negative values use the number of processor cores
+                            (-1)
       --miningaddr=         Add the specified payment address to the list of
                             addresses to use for generated blocks -- At least
                             one address is required if the generate option is
                             set
+      --miningaddrsplit=    Split the miner payout of generated blocks between
+                            the specified payment addresses in proportion to
+                            their weights, given as address:weight (eg.
+                            Hs...:3) -- May be specified multiple times and
+                            replaces the addresses given with --miningaddr as
+                            the payout
       --blockminsize=       Mininum block size in bytes to be used when creating
                             a block
       --blockmaxsize=       Maximum block size in bytes to be used when creating
@@ -118,9 +239,16 @@ Application Options:
       --allowoldvotes       Enable the addition of very old votes to the mempool
 
       --nopeerbloomfilters  Disable bloom filtering support.
-      --sigcachemaxsize=    The maximum number of entries in the signature
-                            verification cache.
-      --blocksonly          Do not accept transactions from remote peers.
+      --sigcachemaxsize=    The maximum size in MB of the signature verification
+                            cache -- 0 disables the cache (32)
+      --scriptcachemaxsize= The maximum size in MB of the cache of input scripts
+                            verified when accepting transactions to the mempool
+                            (16)
+      --blocksonly          Do not accept transactions from remote peers and
+                            reduce the default memory pool limits
+                            accordingly.
+      --nomempoolsync       Do not request the memory pool contents of outbound
+                            peers when connecting to them
       --relaynonstd         Relay non-standard transactions regardless of the
                             default settings for the active network.
       --rejectnonstd        Reject non-standard transactions regardless of the
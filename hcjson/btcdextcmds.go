@@ -60,16 +60,43 @@ func NewDebugLevelCmd(levelSpec string) *DebugLevelCmd {
 	}
 }
 
+// DumpBlockChainCmd defines the dumpblockchain JSON-RPC command.
+type DumpBlockChainCmd struct {
+	Filename    string
+	StartHeight *int64 `jsonrpcdefault:"1"`
+	EndHeight   *int64
+}
+
+// NewDumpBlockChainCmd returns a new instance which can be used to issue a
+// dumpblockchain JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewDumpBlockChainCmd(filename string, startHeight, endHeight *int64) *DumpBlockChainCmd {
+	return &DumpBlockChainCmd{
+		Filename:    filename,
+		StartHeight: startHeight,
+		EndHeight:   endHeight,
+	}
+}
+
 // GenerateCmd defines the generate JSON-RPC command.
 type GenerateCmd struct {
-	NumBlocks uint32
+	NumBlocks  uint32
+	Votes      *uint32
+	TimeOffset *int64
 }
 
 // NewGenerateCmd returns a new instance which can be used to issue a generate
 // JSON-RPC command.
-func NewGenerateCmd(numBlocks uint32) *GenerateCmd {
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGenerateCmd(numBlocks uint32, votes *uint32, timeOffset *int64) *GenerateCmd {
 	return &GenerateCmd{
-		NumBlocks: numBlocks,
+		NumBlocks:  numBlocks,
+		Votes:      votes,
+		TimeOffset: timeOffset,
 	}
 }
 
@@ -91,13 +118,128 @@ func NewGetCurrentNetCmd() *GetCurrentNetCmd {
 	return &GetCurrentNetCmd{}
 }
 
+// GetHealthCmd defines the gethealth JSON-RPC command.
+type GetHealthCmd struct{}
+
+// NewGetHealthCmd returns a new instance which can be used to issue a
+// gethealth JSON-RPC command.
+func NewGetHealthCmd() *GetHealthCmd {
+	return &GetHealthCmd{}
+}
+
+// GetLockStatsCmd defines the getlockstats JSON-RPC command.
+type GetLockStatsCmd struct{}
+
+// NewGetLockStatsCmd returns a new instance which can be used to issue a
+// getlockstats JSON-RPC command.
+func NewGetLockStatsCmd() *GetLockStatsCmd {
+	return &GetLockStatsCmd{}
+}
+
+// GetMemoryInfoCmd defines the getmemoryinfo JSON-RPC command.
+type GetMemoryInfoCmd struct{}
+
+// NewGetMemoryInfoCmd returns a new instance which can be used to issue a
+// getmemoryinfo JSON-RPC command.
+func NewGetMemoryInfoCmd() *GetMemoryInfoCmd {
+	return &GetMemoryInfoCmd{}
+}
+
+// GetProfileCmd defines the getprofile JSON-RPC command.
+type GetProfileCmd struct {
+	Type    string
+	Seconds *int32 `jsonrpcdefault:"30"`
+}
+
+// NewGetProfileCmd returns a new instance which can be used to issue a
+// getprofile JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetProfileCmd(profileType string, seconds *int32) *GetProfileCmd {
+	return &GetProfileCmd{
+		Type:    profileType,
+		Seconds: seconds,
+	}
+}
+
+// GetSlowQueriesCmd defines the getslowqueries JSON-RPC command.
+type GetSlowQueriesCmd struct{}
+
+// NewGetSlowQueriesCmd returns a new instance which can be used to issue a
+// getslowqueries JSON-RPC command.
+func NewGetSlowQueriesCmd() *GetSlowQueriesCmd {
+	return &GetSlowQueriesCmd{}
+}
+
+// GetIndexInfoCmd defines the getindexinfo JSON-RPC command.
+type GetIndexInfoCmd struct {
+	Index *string
+}
+
+// NewGetIndexInfoCmd returns a new instance which can be used to issue a
+// getindexinfo JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetIndexInfoCmd(index *string) *GetIndexInfoCmd {
+	return &GetIndexInfoCmd{
+		Index: index,
+	}
+}
+
+// ReloadConfigCmd defines the reloadconfig JSON-RPC command.
+type ReloadConfigCmd struct{}
+
+// NewReloadConfigCmd returns a new instance which can be used to issue a
+// reloadconfig JSON-RPC command.
+func NewReloadConfigCmd() *ReloadConfigCmd {
+	return &ReloadConfigCmd{}
+}
+
+// SetMaxPeersCmd defines the setmaxpeers JSON-RPC command.
+type SetMaxPeersCmd struct {
+	MaxPeers int32
+}
+
+// NewSetMaxPeersCmd returns a new instance which can be used to issue a
+// setmaxpeers JSON-RPC command.
+func NewSetMaxPeersCmd(maxPeers int32) *SetMaxPeersCmd {
+	return &SetMaxPeersCmd{
+		MaxPeers: maxPeers,
+	}
+}
+
+// StartIndexRebuildCmd defines the startindexrebuild JSON-RPC command.
+type StartIndexRebuildCmd struct {
+	Index string
+}
+
+// NewStartIndexRebuildCmd returns a new instance which can be used to issue a
+// startindexrebuild JSON-RPC command.
+func NewStartIndexRebuildCmd(index string) *StartIndexRebuildCmd {
+	return &StartIndexRebuildCmd{
+		Index: index,
+	}
+}
+
 func init() {
 	// No special flags for commands in this file.
 	flags := UsageFlag(0)
 
 	MustRegisterCmd("debuglevel", (*DebugLevelCmd)(nil), flags)
+	MustRegisterCmd("dumpblockchain", (*DumpBlockChainCmd)(nil), flags)
 	MustRegisterCmd("node", (*NodeCmd)(nil), flags)
 	MustRegisterCmd("generate", (*GenerateCmd)(nil), flags)
 	MustRegisterCmd("getbestblock", (*GetBestBlockCmd)(nil), flags)
 	MustRegisterCmd("getcurrentnet", (*GetCurrentNetCmd)(nil), flags)
+	MustRegisterCmd("gethealth", (*GetHealthCmd)(nil), flags)
+	MustRegisterCmd("getindexinfo", (*GetIndexInfoCmd)(nil), flags)
+	MustRegisterCmd("getlockstats", (*GetLockStatsCmd)(nil), flags)
+	MustRegisterCmd("getmemoryinfo", (*GetMemoryInfoCmd)(nil), flags)
+	MustRegisterCmd("getprofile", (*GetProfileCmd)(nil), flags)
+	MustRegisterCmd("getslowqueries", (*GetSlowQueriesCmd)(nil), flags)
+	MustRegisterCmd("reloadconfig", (*ReloadConfigCmd)(nil), flags)
+	MustRegisterCmd("setmaxpeers", (*SetMaxPeersCmd)(nil), flags)
+	MustRegisterCmd("startindexrebuild", (*StartIndexRebuildCmd)(nil), flags)
 }
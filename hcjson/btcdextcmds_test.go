@@ -102,19 +102,194 @@ func TestBtcdExtCmds(t *testing.T) {
 				ConnectSubCmd: hcjson.String("temp"),
 			},
 		},
+		{
+			name: "dumpblockchain",
+			newCmd: func() (interface{}, error) {
+				return hcjson.NewCmd("dumpblockchain", "bootstrap.dat")
+			},
+			staticCmd: func() interface{} {
+				return hcjson.NewDumpBlockChainCmd("bootstrap.dat", nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"dumpblockchain","params":["bootstrap.dat"],"id":1}`,
+			unmarshalled: &hcjson.DumpBlockChainCmd{
+				Filename:    "bootstrap.dat",
+				StartHeight: hcjson.Int64(1),
+			},
+		},
+		{
+			name: "dumpblockchain optional",
+			newCmd: func() (interface{}, error) {
+				return hcjson.NewCmd("dumpblockchain", "bootstrap.dat", 100, 200)
+			},
+			staticCmd: func() interface{} {
+				return hcjson.NewDumpBlockChainCmd("bootstrap.dat",
+					hcjson.Int64(100), hcjson.Int64(200))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"dumpblockchain","params":["bootstrap.dat",100,200],"id":1}`,
+			unmarshalled: &hcjson.DumpBlockChainCmd{
+				Filename:    "bootstrap.dat",
+				StartHeight: hcjson.Int64(100),
+				EndHeight:   hcjson.Int64(200),
+			},
+		},
 		{
 			name: "generate",
 			newCmd: func() (interface{}, error) {
 				return hcjson.NewCmd("generate", 1)
 			},
 			staticCmd: func() interface{} {
-				return hcjson.NewGenerateCmd(1)
+				return hcjson.NewGenerateCmd(1, nil, nil)
 			},
 			marshalled: `{"jsonrpc":"1.0","method":"generate","params":[1],"id":1}`,
 			unmarshalled: &hcjson.GenerateCmd{
 				NumBlocks: 1,
 			},
 		},
+		{
+			name: "generate optional",
+			newCmd: func() (interface{}, error) {
+				return hcjson.NewCmd("generate", 2, 3, -600)
+			},
+			staticCmd: func() interface{} {
+				return hcjson.NewGenerateCmd(2, hcjson.Uint32(3),
+					hcjson.Int64(-600))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"generate","params":[2,3,-600],"id":1}`,
+			unmarshalled: &hcjson.GenerateCmd{
+				NumBlocks:  2,
+				Votes:      hcjson.Uint32(3),
+				TimeOffset: hcjson.Int64(-600),
+			},
+		},
+		{
+			name: "gethealth",
+			newCmd: func() (interface{}, error) {
+				return hcjson.NewCmd("gethealth")
+			},
+			staticCmd: func() interface{} {
+				return hcjson.NewGetHealthCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"gethealth","params":[],"id":1}`,
+			unmarshalled: &hcjson.GetHealthCmd{},
+		},
+		{
+			name: "getlockstats",
+			newCmd: func() (interface{}, error) {
+				return hcjson.NewCmd("getlockstats")
+			},
+			staticCmd: func() interface{} {
+				return hcjson.NewGetLockStatsCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getlockstats","params":[],"id":1}`,
+			unmarshalled: &hcjson.GetLockStatsCmd{},
+		},
+		{
+			name: "getmemoryinfo",
+			newCmd: func() (interface{}, error) {
+				return hcjson.NewCmd("getmemoryinfo")
+			},
+			staticCmd: func() interface{} {
+				return hcjson.NewGetMemoryInfoCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getmemoryinfo","params":[],"id":1}`,
+			unmarshalled: &hcjson.GetMemoryInfoCmd{},
+		},
+		{
+			name: "getprofile",
+			newCmd: func() (interface{}, error) {
+				return hcjson.NewCmd("getprofile", "heap")
+			},
+			staticCmd: func() interface{} {
+				return hcjson.NewGetProfileCmd("heap", nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getprofile","params":["heap"],"id":1}`,
+			unmarshalled: &hcjson.GetProfileCmd{
+				Type:    "heap",
+				Seconds: hcjson.Int32(30),
+			},
+		},
+		{
+			name: "getprofile optional",
+			newCmd: func() (interface{}, error) {
+				return hcjson.NewCmd("getprofile", "cpu", 10)
+			},
+			staticCmd: func() interface{} {
+				return hcjson.NewGetProfileCmd("cpu", hcjson.Int32(10))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getprofile","params":["cpu",10],"id":1}`,
+			unmarshalled: &hcjson.GetProfileCmd{
+				Type:    "cpu",
+				Seconds: hcjson.Int32(10),
+			},
+		},
+		{
+			name: "getslowqueries",
+			newCmd: func() (interface{}, error) {
+				return hcjson.NewCmd("getslowqueries")
+			},
+			staticCmd: func() interface{} {
+				return hcjson.NewGetSlowQueriesCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getslowqueries","params":[],"id":1}`,
+			unmarshalled: &hcjson.GetSlowQueriesCmd{},
+		},
+		{
+			name: "getindexinfo",
+			newCmd: func() (interface{}, error) {
+				return hcjson.NewCmd("getindexinfo")
+			},
+			staticCmd: func() interface{} {
+				return hcjson.NewGetIndexInfoCmd(nil)
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getindexinfo","params":[],"id":1}`,
+			unmarshalled: &hcjson.GetIndexInfoCmd{Index: nil},
+		},
+		{
+			name: "getindexinfo optional",
+			newCmd: func() (interface{}, error) {
+				return hcjson.NewCmd("getindexinfo", "txindex")
+			},
+			staticCmd: func() interface{} {
+				return hcjson.NewGetIndexInfoCmd(hcjson.String("txindex"))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getindexinfo","params":["txindex"],"id":1}`,
+			unmarshalled: &hcjson.GetIndexInfoCmd{
+				Index: hcjson.String("txindex"),
+			},
+		},
+		{
+			name: "reloadconfig",
+			newCmd: func() (interface{}, error) {
+				return hcjson.NewCmd("reloadconfig")
+			},
+			staticCmd: func() interface{} {
+				return hcjson.NewReloadConfigCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"reloadconfig","params":[],"id":1}`,
+			unmarshalled: &hcjson.ReloadConfigCmd{},
+		},
+		{
+			name: "setmaxpeers",
+			newCmd: func() (interface{}, error) {
+				return hcjson.NewCmd("setmaxpeers", 50)
+			},
+			staticCmd: func() interface{} {
+				return hcjson.NewSetMaxPeersCmd(50)
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"setmaxpeers","params":[50],"id":1}`,
+			unmarshalled: &hcjson.SetMaxPeersCmd{MaxPeers: 50},
+		},
+		{
+			name: "startindexrebuild",
+			newCmd: func() (interface{}, error) {
+				return hcjson.NewCmd("startindexrebuild", "txindex")
+			},
+			staticCmd: func() interface{} {
+				return hcjson.NewStartIndexRebuildCmd("txindex")
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"startindexrebuild","params":["txindex"],"id":1}`,
+			unmarshalled: &hcjson.StartIndexRebuildCmd{Index: "txindex"},
+		},
 		{
 			name: "getbestblock",
 			newCmd: func() (interface{}, error) {
@@ -34,14 +34,15 @@ func TestBtcWalletExtCmds(t *testing.T) {
 		{
 			name: "createnewaccount",
 			newCmd: func() (interface{}, error) {
-				return hcjson.NewCmd("createnewaccount", "acct")
+				return hcjson.NewCmd("createnewaccount", "acct", "default")
 			},
 			staticCmd: func() interface{} {
-				return hcjson.NewCreateNewAccountCmd("acct","default")//return hcjson.NewCreateNewAccountCmd("acct")
+				return hcjson.NewCreateNewAccountCmd("acct", "default")
 			},
-			marshalled: `{"jsonrpc":"1.0","method":"createnewaccount","params":["acct"],"id":1}`,
+			marshalled: `{"jsonrpc":"1.0","method":"createnewaccount","params":["acct","default"],"id":1}`,
 			unmarshalled: &hcjson.CreateNewAccountCmd{
-				Account: "acct",
+				Account:     "acct",
+				AccountType: "default",
 			},
 		},
 		{
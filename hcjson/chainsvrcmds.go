@@ -54,12 +54,72 @@ type TransactionInput struct {
 	Tree int8   `json:"tree"`
 }
 
+// CombinePshtCmd defines the combinepsht JSON-RPC command.
+type CombinePshtCmd struct {
+	Pshts []string
+}
+
+// NewCombinePshtCmd returns a new instance which can be used to issue a
+// combinepsht JSON-RPC command.
+func NewCombinePshtCmd(pshts []string) *CombinePshtCmd {
+	return &CombinePshtCmd{
+		Pshts: pshts,
+	}
+}
+
+// CreateMultisigCmd defines the createmultisig JSON-RPC command.
+type CreateMultisigCmd struct {
+	NRequired int
+	Keys      []string
+}
+
+// NewCreateMultisigCmd returns a new instance which can be used to issue a
+// createmultisig JSON-RPC command.
+func NewCreateMultisigCmd(nRequired int, keys []string) *CreateMultisigCmd {
+	return &CreateMultisigCmd{
+		NRequired: nRequired,
+		Keys:      keys,
+	}
+}
+
+// CreateRawScriptCmd defines the createrawscript JSON-RPC command.
+type CreateRawScriptCmd struct {
+	Type      string `jsonrpcusage:"\"multisig|pubkey|pubkeyhash\""`
+	Keys      []string
+	NRequired *int
+	SigType   *string `jsonrpcdefault:"\"secp256k1\""`
+}
+
+// NewCreateRawScriptCmd returns a new instance which can be used to issue a
+// createrawscript JSON-RPC command.
+//
+// The nRequired parameter is only used with the multisig type, while the
+// sigType parameter selects the signature scheme of the pubkey and pubkeyhash
+// types.
+func NewCreateRawScriptCmd(scriptType string, keys []string, nRequired *int,
+	sigType *string) *CreateRawScriptCmd {
+
+	return &CreateRawScriptCmd{
+		Type:      scriptType,
+		Keys:      keys,
+		NRequired: nRequired,
+		SigType:   sigType,
+	}
+}
+
 // CreateRawTransactionCmd defines the createrawtransaction JSON-RPC command.
+//
+// The TxType selects the kind of transaction to create.  Ticket purchases pay
+// the single amount to the voting address and require a commitment for each
+// input, while revocations derive their outputs from the ticket spent by the
+// single input and do not accept amounts.
 type CreateRawTransactionCmd struct {
-	Inputs   []TransactionInput
-	Amounts  map[string]float64 `jsonrpcusage:"{\"address\":amount,...}"` // In HC
-	LockTime *int64
-	PayLoad  *string
+	Inputs      []TransactionInput
+	Amounts     map[string]float64 `jsonrpcusage:"{\"address\":amount,...}"` // In HC
+	LockTime    *int64
+	PayLoad     *string
+	TxType      *string `jsonrpcdefault:"\"regular\""`
+	Commitments *[]SStxCommitOut
 }
 
 // NewCreateRawTransactionCmd returns a new instance which can be used to issue
@@ -76,6 +136,19 @@ func NewCreateRawTransactionCmd(inputs []TransactionInput, amounts map[string]fl
 	}
 }
 
+// DecodePshtCmd defines the decodepsht JSON-RPC command.
+type DecodePshtCmd struct {
+	Psht string
+}
+
+// NewDecodePshtCmd returns a new instance which can be used to issue a
+// decodepsht JSON-RPC command.
+func NewDecodePshtCmd(psht string) *DecodePshtCmd {
+	return &DecodePshtCmd{
+		Psht: psht,
+	}
+}
+
 // DecodeRawTransactionCmd defines the decoderawtransaction JSON-RPC command.
 type DecodeRawTransactionCmd struct {
 	HexTx string
@@ -102,6 +175,25 @@ func NewDecodeScriptCmd(hexScript string) *DecodeScriptCmd {
 	}
 }
 
+// DisconnectNodeCmd defines the disconnectnode JSON-RPC command.
+type DisconnectNodeCmd struct {
+	Address *string `jsonrpcdefault:"\"\""`
+	NodeID  *int32
+}
+
+// NewDisconnectNodeCmd returns a new instance which can be used to issue a
+// disconnectnode JSON-RPC command.  The peer is selected by its address, or by
+// its node id when the address is empty.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewDisconnectNodeCmd(address *string, nodeID *int32) *DisconnectNodeCmd {
+	return &DisconnectNodeCmd{
+		Address: address,
+		NodeID:  nodeID,
+	}
+}
+
 // EstimateFeeCmd defines the estimatefee JSON-RPC command.
 type EstimateFeeCmd struct {
 	NumBlocks int64
@@ -115,6 +207,24 @@ func NewEstimateFeeCmd(numBlocks int64) *EstimateFeeCmd {
 	}
 }
 
+// FinalizePshtCmd defines the finalizepsht JSON-RPC command.
+type FinalizePshtCmd struct {
+	Psht    string
+	Extract *bool `jsonrpcdefault:"true"`
+}
+
+// NewFinalizePshtCmd returns a new instance which can be used to issue a
+// finalizepsht JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewFinalizePshtCmd(psht string, extract *bool) *FinalizePshtCmd {
+	return &FinalizePshtCmd{
+		Psht:    psht,
+		Extract: extract,
+	}
+}
+
 // GetAddedNodeInfoCmd defines the getaddednodeinfo JSON-RPC command.
 type GetAddedNodeInfoCmd struct {
 	DNS  bool
@@ -380,6 +490,19 @@ func NewGetHeadersCmd(blockLocators string, hashStop string) *GetHeadersCmd {
 	}
 }
 
+// GetMempoolEntryCmd defines the getmempoolentry JSON-RPC command.
+type GetMempoolEntryCmd struct {
+	TxID string
+}
+
+// NewGetMempoolEntryCmd returns a new instance which can be used to issue a
+// getmempoolentry JSON-RPC command.
+func NewGetMempoolEntryCmd(txHash string) *GetMempoolEntryCmd {
+	return &GetMempoolEntryCmd{
+		TxID: txHash,
+	}
+}
+
 // GetMempoolInfoCmd defines the getmempoolinfo JSON-RPC command.
 type GetMempoolInfoCmd struct{}
 
@@ -573,6 +696,23 @@ func NewPingCmd() *PingCmd {
 	return &PingCmd{}
 }
 
+// PrioritiseTransactionCmd defines the prioritisetransaction JSON-RPC command.
+type PrioritiseTransactionCmd struct {
+	TxID          string
+	PriorityDelta float64
+	FeeDelta      int64
+}
+
+// NewPrioritiseTransactionCmd returns a new instance which can be used to
+// issue a prioritisetransaction JSON-RPC command.
+func NewPrioritiseTransactionCmd(txID string, priorityDelta float64, feeDelta int64) *PrioritiseTransactionCmd {
+	return &PrioritiseTransactionCmd{
+		TxID:          txID,
+		PriorityDelta: priorityDelta,
+		FeeDelta:      feeDelta,
+	}
+}
+
 // SearchRawTransactionsCmd defines the searchrawtransactions JSON-RPC command.
 type SearchRawTransactionsCmd struct {
 	Address     string
@@ -619,6 +759,25 @@ func NewSendRawTransactionCmd(hexTx string, allowHighFees *bool) *SendRawTransac
 	}
 }
 
+// SubmitRawTransactionPackageCmd defines the submitrawtransactionpackage
+// JSON-RPC command.
+type SubmitRawTransactionPackageCmd struct {
+	HexTxs        []string
+	AllowHighFees *bool `jsonrpcdefault:"false"`
+}
+
+// NewSubmitRawTransactionPackageCmd returns a new instance which can be used
+// to issue a submitrawtransactionpackage JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewSubmitRawTransactionPackageCmd(hexTxs []string, allowHighFees *bool) *SubmitRawTransactionPackageCmd {
+	return &SubmitRawTransactionPackageCmd{
+		HexTxs:        hexTxs,
+		AllowHighFees: allowHighFees,
+	}
+}
+
 // SetGenerateCmd defines the setgenerate JSON-RPC command.
 type SetGenerateCmd struct {
 	Generate     bool
@@ -671,6 +830,23 @@ func NewSubmitBlockCmd(hexBlock string, options *SubmitBlockOptions) *SubmitBloc
 	}
 }
 
+// TraceScriptCmd defines the tracescript JSON-RPC command.
+type TraceScriptCmd struct {
+	HexTx      string
+	InputIndex int
+	PkScript   string
+}
+
+// NewTraceScriptCmd returns a new instance which can be used to issue a
+// tracescript JSON-RPC command.
+func NewTraceScriptCmd(hexTx string, inputIndex int, pkScript string) *TraceScriptCmd {
+	return &TraceScriptCmd{
+		HexTx:      hexTx,
+		InputIndex: inputIndex,
+		PkScript:   pkScript,
+	}
+}
+
 // ValidateAddressCmd defines the validateaddress JSON-RPC command.
 type ValidateAddressCmd struct {
 	Address string
@@ -741,10 +917,16 @@ func init() {
 	flags := UsageFlag(0)
 
 	MustRegisterCmd("addnode", (*AddNodeCmd)(nil), flags)
+	MustRegisterCmd("combinepsht", (*CombinePshtCmd)(nil), flags)
+	MustRegisterCmd("createmultisig", (*CreateMultisigCmd)(nil), flags)
+	MustRegisterCmd("createrawscript", (*CreateRawScriptCmd)(nil), flags)
 	MustRegisterCmd("createrawtransaction", (*CreateRawTransactionCmd)(nil), flags)
+	MustRegisterCmd("decodepsht", (*DecodePshtCmd)(nil), flags)
 	MustRegisterCmd("decoderawtransaction", (*DecodeRawTransactionCmd)(nil), flags)
 	MustRegisterCmd("decodescript", (*DecodeScriptCmd)(nil), flags)
+	MustRegisterCmd("disconnectnode", (*DisconnectNodeCmd)(nil), flags)
 	MustRegisterCmd("estimatefee", (*EstimateFeeCmd)(nil), flags)
+	MustRegisterCmd("finalizepsht", (*FinalizePshtCmd)(nil), flags)
 	MustRegisterCmd("getaddednodeinfo", (*GetAddedNodeInfoCmd)(nil), flags)
 	MustRegisterCmd("getbestblockhash", (*GetBestBlockHashCmd)(nil), flags)
 	MustRegisterCmd("getblock", (*GetBlockCmd)(nil), flags)
@@ -761,6 +943,7 @@ func init() {
 	MustRegisterCmd("gethashespersec", (*GetHashesPerSecCmd)(nil), flags)
 	MustRegisterCmd("getheaders", (*GetHeadersCmd)(nil), flags)
 	MustRegisterCmd("getinfo", (*GetInfoCmd)(nil), flags)
+	MustRegisterCmd("getmempoolentry", (*GetMempoolEntryCmd)(nil), flags)
 	MustRegisterCmd("getmempoolinfo", (*GetMempoolInfoCmd)(nil), flags)
 	MustRegisterCmd("getmininginfo", (*GetMiningInfoCmd)(nil), flags)
 	MustRegisterCmd("getnetworkinfo", (*GetNetworkInfoCmd)(nil), flags)
@@ -774,11 +957,14 @@ func init() {
 	MustRegisterCmd("getwork", (*GetWorkCmd)(nil), flags)
 	MustRegisterCmd("help", (*HelpCmd)(nil), flags)
 	MustRegisterCmd("ping", (*PingCmd)(nil), flags)
+	MustRegisterCmd("prioritisetransaction", (*PrioritiseTransactionCmd)(nil), flags)
 	MustRegisterCmd("searchrawtransactions", (*SearchRawTransactionsCmd)(nil), flags)
 	MustRegisterCmd("sendrawtransaction", (*SendRawTransactionCmd)(nil), flags)
 	MustRegisterCmd("setgenerate", (*SetGenerateCmd)(nil), flags)
 	MustRegisterCmd("stop", (*StopCmd)(nil), flags)
 	MustRegisterCmd("submitblock", (*SubmitBlockCmd)(nil), flags)
+	MustRegisterCmd("submitrawtransactionpackage", (*SubmitRawTransactionPackageCmd)(nil), flags)
+	MustRegisterCmd("tracescript", (*TraceScriptCmd)(nil), flags)
 	MustRegisterCmd("validateaddress", (*ValidateAddressCmd)(nil), flags)
 	MustRegisterCmd("verifychain", (*VerifyChainCmd)(nil), flags)
 	MustRegisterCmd("verifymessage", (*VerifyMessageCmd)(nil), flags)
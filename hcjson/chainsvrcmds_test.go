@@ -106,41 +106,72 @@ func TestChainSvrCmds(t *testing.T) {
 		{
 			name: "estimatefee",
 			newCmd: func() (interface{}, error) {
-				return NewCmd("estimatefee", 6)
+				return hcjson.NewCmd("estimatefee", 6)
 			},
 			staticCmd: func() interface{} {
-				return NewEstimateFeeCmd(6)
+				return hcjson.NewEstimateFeeCmd(6)
 			},
 			marshalled: `{"jsonrpc":"1.0","method":"estimatefee","params":[6],"id":1}`,
-			unmarshalled: &EstimateFeeCmd{
+			unmarshalled: &hcjson.EstimateFeeCmd{
 				NumBlocks: 6,
 			},
 		},
 		{
-			name: "estimatefee",
+			name: "combinepsht",
 			newCmd: func() (interface{}, error) {
-				return NewCmd("estimatefee", 8)
+				return hcjson.NewCmd("combinepsht", `["cHNodP8=","cHNodP8A"]`)
 			},
 			staticCmd: func() interface{} {
-				return NewEstimateFeeCmd(8)
+				return hcjson.NewCombinePshtCmd([]string{"cHNodP8=", "cHNodP8A"})
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"combinepsht","params":[["cHNodP8=","cHNodP8A"]],"id":1}`,
+			unmarshalled: &hcjson.CombinePshtCmd{Pshts: []string{"cHNodP8=", "cHNodP8A"}},
+		},
+		{
+			name: "createmultisig",
+			newCmd: func() (interface{}, error) {
+				return hcjson.NewCmd("createmultisig", 2, []string{"031234", "035678"})
 			},
-			marshalled: `{"jsonrpc":"1.0","method":"estimatefee","params":[8],"id":1}`,
-			unmarshalled: &EstimateFeeCmd{
-				NumBlocks:86,
+			staticCmd: func() interface{} {
+				keys := []string{"031234", "035678"}
+				return hcjson.NewCreateMultisigCmd(2, keys)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"createmultisig","params":[2,["031234","035678"]],"id":1}`,
+			unmarshalled: &hcjson.CreateMultisigCmd{
+				NRequired: 2,
+				Keys:      []string{"031234", "035678"},
 			},
 		},
 		{
-			name: "estimatesmartfee",
+			name: "createrawscript",
 			newCmd: func() (interface{}, error) {
-				return NewCmd("estimatesmartfee", 6),nil
+				return hcjson.NewCmd("createrawscript", "pubkeyhash", []string{"031234"})
 			},
 			staticCmd: func() interface{} {
-				return NewEstimateSmartFeeCmd(6, nil)
+				return hcjson.NewCreateRawScriptCmd("pubkeyhash", []string{"031234"}, nil, nil)
 			},
-			marshalled: `{"jsonrpc":"1.0","method":"estimatesmartfee","params":[6],"id":1}`,
-			unmarshalled: &EstimateSmartFeeCmd{
-				Confirmations: 6,
-				Mode:          EstimateSmartFeeModeAddr(EstimateSmartFeeConservative),
+			marshalled: `{"jsonrpc":"1.0","method":"createrawscript","params":["pubkeyhash",["031234"]],"id":1}`,
+			unmarshalled: &hcjson.CreateRawScriptCmd{
+				Type:    "pubkeyhash",
+				Keys:    []string{"031234"},
+				SigType: hcjson.String("secp256k1"),
+			},
+		},
+		{
+			name: "createrawscript optional",
+			newCmd: func() (interface{}, error) {
+				return hcjson.NewCmd("createrawscript", "multisig", []string{"031234", "035678"}, 2, "secp256k1")
+			},
+			staticCmd: func() interface{} {
+				keys := []string{"031234", "035678"}
+				return hcjson.NewCreateRawScriptCmd("multisig", keys, hcjson.Int(2), hcjson.String("secp256k1"))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"createrawscript","params":["multisig",["031234","035678"],2,"secp256k1"],"id":1}`,
+			unmarshalled: &hcjson.CreateRawScriptCmd{
+				Type:      "multisig",
+				Keys:      []string{"031234", "035678"},
+				NRequired: hcjson.Int(2),
+				SigType:   hcjson.String("secp256k1"),
 			},
 		},
 		{
@@ -160,6 +191,39 @@ func TestChainSvrCmds(t *testing.T) {
 			unmarshalled: &hcjson.CreateRawTransactionCmd{
 				Inputs:  []hcjson.TransactionInput{{Txid: "123", Vout: 1}},
 				Amounts: map[string]float64{"456": .0123},
+				TxType:  hcjson.String("regular"),
+			},
+		},
+		{
+			name: "createrawtransaction ticket",
+			newCmd: func() (interface{}, error) {
+				return hcjson.NewCmd("createrawtransaction", `[{"txid":"123","vout":1}]`,
+					`{"456":0.0123}`, 0, "", "ticket",
+					`[{"addr":"789","commitamt":0,"changeaddr":"012","changeamt":5}]`)
+			},
+			staticCmd: func() interface{} {
+				txInputs := []hcjson.TransactionInput{
+					{Txid: "123", Vout: 1},
+				}
+				amounts := map[string]float64{"456": .0123}
+				cmd := hcjson.NewCreateRawTransactionCmd(txInputs, amounts, hcjson.Int64(0))
+				cmd.PayLoad = hcjson.String("")
+				cmd.TxType = hcjson.String("ticket")
+				cmd.Commitments = &[]hcjson.SStxCommitOut{
+					{Addr: "789", ChangeAddr: "012", ChangeAmt: 5},
+				}
+				return cmd
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"createrawtransaction","params":[[{"txid":"123","vout":1,"tree":0}],{"456":0.0123},0,"","ticket",[{"addr":"789","commitamt":0,"changeaddr":"012","changeamt":5}]],"id":1}`,
+			unmarshalled: &hcjson.CreateRawTransactionCmd{
+				Inputs:   []hcjson.TransactionInput{{Txid: "123", Vout: 1}},
+				Amounts:  map[string]float64{"456": .0123},
+				LockTime: hcjson.Int64(0),
+				PayLoad:  hcjson.String(""),
+				TxType:   hcjson.String("ticket"),
+				Commitments: &[]hcjson.SStxCommitOut{
+					{Addr: "789", ChangeAddr: "012", ChangeAmt: 5},
+				},
 			},
 		},
 // 		{
@@ -182,6 +246,17 @@ func TestChainSvrCmds(t *testing.T) {
 // 				LockTime: hcjson.Int64(12312333333),
 // 			},
 // 		},
+		{
+			name: "decodepsht",
+			newCmd: func() (interface{}, error) {
+				return hcjson.NewCmd("decodepsht", "cHNodP8=")
+			},
+			staticCmd: func() interface{} {
+				return hcjson.NewDecodePshtCmd("cHNodP8=")
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"decodepsht","params":["cHNodP8="],"id":1}`,
+			unmarshalled: &hcjson.DecodePshtCmd{Psht: "cHNodP8="},
+		},
 		{
 			name: "decoderawtransaction",
 			newCmd: func() (interface{}, error) {
@@ -204,6 +279,61 @@ func TestChainSvrCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"decodescript","params":["00"],"id":1}`,
 			unmarshalled: &hcjson.DecodeScriptCmd{HexScript: "00"},
 		},
+		{
+			name: "disconnectnode",
+			newCmd: func() (interface{}, error) {
+				return hcjson.NewCmd("disconnectnode", "127.0.0.1:14008")
+			},
+			staticCmd: func() interface{} {
+				return hcjson.NewDisconnectNodeCmd(hcjson.String("127.0.0.1:14008"), nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"disconnectnode","params":["127.0.0.1:14008"],"id":1}`,
+			unmarshalled: &hcjson.DisconnectNodeCmd{
+				Address: hcjson.String("127.0.0.1:14008"),
+			},
+		},
+		{
+			name: "disconnectnode optional",
+			newCmd: func() (interface{}, error) {
+				return hcjson.NewCmd("disconnectnode", "", 5)
+			},
+			staticCmd: func() interface{} {
+				return hcjson.NewDisconnectNodeCmd(hcjson.String(""), hcjson.Int32(5))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"disconnectnode","params":["",5],"id":1}`,
+			unmarshalled: &hcjson.DisconnectNodeCmd{
+				Address: hcjson.String(""),
+				NodeID:  hcjson.Int32(5),
+			},
+		},
+		{
+			name: "finalizepsht",
+			newCmd: func() (interface{}, error) {
+				return hcjson.NewCmd("finalizepsht", "cHNodP8=")
+			},
+			staticCmd: func() interface{} {
+				return hcjson.NewFinalizePshtCmd("cHNodP8=", nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"finalizepsht","params":["cHNodP8="],"id":1}`,
+			unmarshalled: &hcjson.FinalizePshtCmd{
+				Psht:    "cHNodP8=",
+				Extract: hcjson.Bool(true),
+			},
+		},
+		{
+			name: "finalizepsht optional",
+			newCmd: func() (interface{}, error) {
+				return hcjson.NewCmd("finalizepsht", "cHNodP8=", false)
+			},
+			staticCmd: func() interface{} {
+				return hcjson.NewFinalizePshtCmd("cHNodP8=", hcjson.Bool(false))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"finalizepsht","params":["cHNodP8=",false],"id":1}`,
+			unmarshalled: &hcjson.FinalizePshtCmd{
+				Psht:    "cHNodP8=",
+				Extract: hcjson.Bool(false),
+			},
+		},
 		{
 			name: "getaddednodeinfo",
 			newCmd: func() (interface{}, error) {
@@ -499,6 +629,19 @@ func TestChainSvrCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"getinfo","params":[],"id":1}`,
 			unmarshalled: &hcjson.GetInfoCmd{},
 		},
+		{
+			name: "getmempoolentry",
+			newCmd: func() (interface{}, error) {
+				return hcjson.NewCmd("getmempoolentry", "txhash")
+			},
+			staticCmd: func() interface{} {
+				return hcjson.NewGetMempoolEntryCmd("txhash")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getmempoolentry","params":["txhash"],"id":1}`,
+			unmarshalled: &hcjson.GetMempoolEntryCmd{
+				TxID: "txhash",
+			},
+		},
 		{
 			name: "getmempoolinfo",
 			newCmd: func() (interface{}, error) {
@@ -768,6 +911,21 @@ func TestChainSvrCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"ping","params":[],"id":1}`,
 			unmarshalled: &hcjson.PingCmd{},
 		},
+		{
+			name: "prioritisetransaction",
+			newCmd: func() (interface{}, error) {
+				return hcjson.NewCmd("prioritisetransaction", "123", 0.5, 10000)
+			},
+			staticCmd: func() interface{} {
+				return hcjson.NewPrioritiseTransactionCmd("123", 0.5, 10000)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"prioritisetransaction","params":["123",0.5,10000],"id":1}`,
+			unmarshalled: &hcjson.PrioritiseTransactionCmd{
+				TxID:          "123",
+				PriorityDelta: 0.5,
+				FeeDelta:      10000,
+			},
+		},
 		{
 			name: "searchrawtransactions",
 			newCmd: func() (interface{}, error) {
@@ -1009,6 +1167,21 @@ func TestChainSvrCmds(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "tracescript",
+			newCmd: func() (interface{}, error) {
+				return hcjson.NewCmd("tracescript", "0100", 1, "76a9")
+			},
+			staticCmd: func() interface{} {
+				return hcjson.NewTraceScriptCmd("0100", 1, "76a9")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"tracescript","params":["0100",1,"76a9"],"id":1}`,
+			unmarshalled: &hcjson.TraceScriptCmd{
+				HexTx:      "0100",
+				InputIndex: 1,
+				PkScript:   "76a9",
+			},
+		},
 		{
 			name: "validateaddress",
 			newCmd: func() (interface{}, error) {
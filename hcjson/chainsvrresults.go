@@ -64,6 +64,7 @@ type GetBlockVerboseResult struct {
 	Difficulty    float64       `json:"difficulty"`
 	ExtraData     string        `json:"extradata"`
 	StakeVersion  uint32        `json:"stakeversion"`
+	Votes         []VersionBits `json:"votes,omitempty"`
 	PreviousHash  string        `json:"previousblockhash"`
 	NextHash      string        `json:"nextblockhash,omitempty"`
 }
@@ -75,6 +76,16 @@ type CreateMultiSigResult struct {
 	RedeemScript string `json:"redeemScript"`
 }
 
+// CreateRawScriptResult models the data returned from the createrawscript
+// command.
+type CreateRawScriptResult struct {
+	Type       string `json:"type"`
+	Script     string `json:"script"`
+	Address    string `json:"address,omitempty"`
+	P2sh       string `json:"p2sh"`
+	P2shScript string `json:"p2shscript"`
+}
+
 // DecodeScriptResult models the data returned from the decodescript command.
 type DecodeScriptResult struct {
 	Asm       string   `json:"asm"`
@@ -104,11 +115,16 @@ type GetBlockChainInfoResult struct {
 	Chain                string  `json:"chain"`
 	Blocks               int32   `json:"blocks"`
 	Headers              int32   `json:"headers"`
+	EstimatedHeaders     int64   `json:"estimatedheaders"`
 	BestBlockHash        string  `json:"bestblockhash"`
 	Difficulty           float64 `json:"difficulty"`
 	VerificationProgress float64 `json:"verificationprogress"`
 	ChainWork            string  `json:"chainwork"`
 	SyncHeight           int64   `json:"syncheight"`
+	InitialBlockDownload bool    `json:"initialblockdownload"`
+	SyncState            string  `json:"syncstate"`
+	SyncPeer             string  `json:"syncpeer,omitempty"`
+	DownloadSpeed        float64 `json:"downloadspeed"`
 	DifficultyRatio      float64 `json:"difficultyratio"`
 	MaxBlockSize         int64   `json:"maxblocksize"`
 }
@@ -177,11 +193,165 @@ type GetBlockTemplateResult struct {
 	RejectReasion string   `json:"reject-reason,omitempty"`
 }
 
+// HealthSyncResult models the sync field of the gethealth command.
+type HealthSyncResult struct {
+	Status          string  `json:"status"`
+	State           string  `json:"state"`
+	Height          int64   `json:"height"`
+	EstimatedHeight int64   `json:"estimatedheight"`
+	Progress        float64 `json:"progress"`
+}
+
+// HealthQuarantinedBlockResult models a stored block which failed to verify as
+// returned by the gethealth command.
+type HealthQuarantinedBlockResult struct {
+	Hash   string `json:"hash"`
+	Height int64  `json:"height"`
+	Reason string `json:"reason"`
+	Since  int64  `json:"since"`
+}
+
+// HealthDatabaseResult models the database field of the gethealth command.
+// The latencies are in milliseconds.
+type HealthDatabaseResult struct {
+	Status       string                         `json:"status"`
+	Samples      int                            `json:"samples"`
+	P50          float64                        `json:"p50"`
+	P90          float64                        `json:"p90"`
+	P99          float64                        `json:"p99"`
+	Error        string                         `json:"error,omitempty"`
+	LastVerified int64                          `json:"lastverified,omitempty"`
+	Quarantined  []HealthQuarantinedBlockResult `json:"quarantined,omitempty"`
+}
+
+// HealthMempoolResult models the mempool field of the gethealth command.
+type HealthMempoolResult struct {
+	Status     string `json:"status"`
+	Size       int64  `json:"size"`
+	Usage      int64  `json:"usage"`
+	MaxMempool int64  `json:"maxmempool"`
+}
+
+// HealthPeersResult models the peers field of the gethealth command.
+type HealthPeersResult struct {
+	Status    string `json:"status"`
+	Connected int    `json:"connected"`
+	Inbound   int    `json:"inbound"`
+	Outbound  int    `json:"outbound"`
+	NetGroups int    `json:"netgroups"`
+}
+
+// HealthErrorResult models the last error logged by a subsystem as returned
+// by the gethealth command.
+type HealthErrorResult struct {
+	Time    int64  `json:"time"`
+	Message string `json:"message"`
+}
+
+// GetHealthResult models the data returned from the gethealth command.
+type GetHealthResult struct {
+	Status   string                       `json:"status"`
+	Sync     HealthSyncResult             `json:"sync"`
+	Database HealthDatabaseResult         `json:"database"`
+	Mempool  HealthMempoolResult          `json:"mempool"`
+	Peers    HealthPeersResult            `json:"peers"`
+	Errors   map[string]HealthErrorResult `json:"errors"`
+}
+
+// MemoryRuntimeResult models the memory statistics of the Go runtime as
+// returned by the getmemoryinfo command.  The sizes are in bytes.
+type MemoryRuntimeResult struct {
+	Alloc        uint64  `json:"alloc"`
+	TotalAlloc   uint64  `json:"totalalloc"`
+	Sys          uint64  `json:"sys"`
+	HeapAlloc    uint64  `json:"heapalloc"`
+	HeapSys      uint64  `json:"heapsys"`
+	HeapIdle     uint64  `json:"heapidle"`
+	HeapInuse    uint64  `json:"heapinuse"`
+	HeapReleased uint64  `json:"heapreleased"`
+	HeapObjects  uint64  `json:"heapobjects"`
+	StackInuse   uint64  `json:"stackinuse"`
+	NumGC        uint32  `json:"numgc"`
+	GCPauseTotal float64 `json:"gcpausetotal"`
+	LastGC       int64   `json:"lastgc"`
+	Goroutines   int     `json:"goroutines"`
+}
+
+// MemorySubsystemResult models the memory used by a subsystem as returned by
+// the getmemoryinfo command.  The sizes are in bytes.
+type MemorySubsystemResult struct {
+	Entries int64 `json:"entries"`
+	Usage   int64 `json:"usage"`
+	Max     int64 `json:"max"`
+}
+
+// GetMemoryInfoResult models the data returned from the getmemoryinfo
+// command.
+type GetMemoryInfoResult struct {
+	Runtime    MemoryRuntimeResult              `json:"runtime"`
+	Subsystems map[string]MemorySubsystemResult `json:"subsystems"`
+}
+
+// GetProfileResult models the data returned from the getprofile command.
+type GetProfileResult struct {
+	Type    string `json:"type"`
+	Seconds int32  `json:"seconds,omitempty"`
+	Size    int    `json:"size"`
+	Data    string `json:"data"`
+}
+
+// DumpBlockChainResult models the data returned from the dumpblockchain
+// command.
+type DumpBlockChainResult struct {
+	Filename    string `json:"filename"`
+	StartHeight int64  `json:"startheight"`
+	EndHeight   int64  `json:"endheight"`
+	Blocks      int64  `json:"blocks"`
+	Size        int64  `json:"size"`
+}
+
+// GetLockStatsResult models the contention statistics of a lock at a call site
+// as returned by the getlockstats command.  The durations are in milliseconds.
+type GetLockStatsResult struct {
+	Lock         string  `json:"lock"`
+	Site         string  `json:"site"`
+	Acquisitions uint64  `json:"acquisitions"`
+	TotalWait    float64 `json:"totalwait"`
+	MaxWait      float64 `json:"maxwait"`
+	TotalHold    float64 `json:"totalhold"`
+	MaxHold      float64 `json:"maxhold"`
+}
+
+// GetSlowQueriesResult models a slow RPC call or database transaction as
+// returned by the getslowqueries command.  The durations are in milliseconds.
+type GetSlowQueriesResult struct {
+	Time         int64   `json:"time"`
+	Type         string  `json:"type"`
+	Name         string  `json:"name"`
+	ParamsDigest string  `json:"paramsdigest,omitempty"`
+	Duration     float64 `json:"duration"`
+	LockWait     float64 `json:"lockwait,omitempty"`
+}
+
+// IndexInfoResult models the state of an optional index as returned by the
+// getindexinfo command.
+type IndexInfoResult struct {
+	Synced     bool    `json:"synced"`
+	Height     int32   `json:"height"`
+	CatchingUp bool    `json:"catchingup"`
+	Rebuilding bool    `json:"rebuilding"`
+	Progress   float64 `json:"progress"`
+	Error      string  `json:"error,omitempty"`
+}
+
 // GetMempoolInfoResult models the data returned from the getmempoolinfo
 // command.
 type GetMempoolInfoResult struct {
-	Size  int64 `json:"size"`
-	Bytes int64 `json:"bytes"`
+	Size          int64   `json:"size"`
+	Bytes         int64   `json:"bytes"`
+	Usage         int64   `json:"usage"`
+	MaxMempool    int64   `json:"maxmempool"`
+	MempoolMinFee float64 `json:"mempoolminfee"`
 }
 
 // GetNetworkInfoResult models the data returned from the getnetworkinfo
@@ -198,25 +368,52 @@ type GetNetworkInfoResult struct {
 
 // GetPeerInfoResult models the data returned from the getpeerinfo command.
 type GetPeerInfoResult struct {
-	ID             int32   `json:"id"`
-	Addr           string  `json:"addr"`
-	AddrLocal      string  `json:"addrlocal,omitempty"`
-	Services       string  `json:"services"`
-	LastSend       int64   `json:"lastsend"`
-	LastRecv       int64   `json:"lastrecv"`
-	BytesSent      uint64  `json:"bytessent"`
-	BytesRecv      uint64  `json:"bytesrecv"`
-	ConnTime       int64   `json:"conntime"`
-	TimeOffset     int64   `json:"timeoffset"`
-	PingTime       float64 `json:"pingtime"`
-	PingWait       float64 `json:"pingwait,omitempty"`
-	Version        uint32  `json:"version"`
-	SubVer         string  `json:"subver"`
-	Inbound        bool    `json:"inbound"`
-	StartingHeight int64   `json:"startingheight"`
-	CurrentHeight  int64   `json:"currentheight,omitempty"`
-	BanScore       int32   `json:"banscore"`
-	SyncNode       bool    `json:"syncnode"`
+	ID             int32                          `json:"id"`
+	Addr           string                         `json:"addr"`
+	AddrLocal      string                         `json:"addrlocal,omitempty"`
+	Services       string                         `json:"services"`
+	LastSend       int64                          `json:"lastsend"`
+	LastRecv       int64                          `json:"lastrecv"`
+	BytesSent      uint64                         `json:"bytessent"`
+	BytesRecv      uint64                         `json:"bytesrecv"`
+	ConnTime       int64                          `json:"conntime"`
+	TimeOffset     int64                          `json:"timeoffset"`
+	PingTime       float64                        `json:"pingtime"`
+	PingWait       float64                        `json:"pingwait,omitempty"`
+	Version        uint32                         `json:"version"`
+	SubVer         string                         `json:"subver"`
+	Inbound        bool                           `json:"inbound"`
+	StartingHeight int64                          `json:"startingheight"`
+	CurrentHeight  int64                          `json:"currentheight,omitempty"`
+	BanScore       int32                          `json:"banscore"`
+	SyncNode       bool                           `json:"syncnode"`
+	SentPerMsg     map[string]GetPeerInfoMsgStats `json:"sentpermsg"`
+	RecvPerMsg     map[string]GetPeerInfoMsgStats `json:"recvpermsg"`
+	Features       map[string]uint32              `json:"features,omitempty"`
+}
+
+// GetPeerInfoMsgStats models the number and total size of the messages of a
+// single wire command sent to or received from a peer as returned by the
+// getpeerinfo command.
+type GetPeerInfoMsgStats struct {
+	Count uint64 `json:"count"`
+	Bytes uint64 `json:"bytes"`
+}
+
+// GetMempoolEntryResult models the data returned from the getmempoolentry
+// command.  The received fields describe when and from where the transaction
+// was first seen and are omitted when it is no longer known.
+type GetMempoolEntryResult struct {
+	Size               int32    `json:"size"`
+	Fee                float64  `json:"fee"`
+	Time               int64    `json:"time"`
+	Height             int64    `json:"height"`
+	StartingPriority   float64  `json:"startingpriority"`
+	CurrentPriority    float64  `json:"currentpriority"`
+	Depends            []string `json:"depends"`
+	ReceivedTime       int64    `json:"receivedtime,omitempty"`
+	ReceivedTimeMillis int64    `json:"receivedtimemillis,omitempty"`
+	ReceivedFrom       string   `json:"receivedfrom,omitempty"`
 }
 
 // GetRawMempoolVerboseResult models the data returned from the getrawmempool
@@ -512,16 +709,119 @@ type SearchRawTransactionsResult struct {
 	Blocktime     int64        `json:"blocktime,omitempty"`
 }
 
+// SubmitRawTransactionPackageTxResult models the per-transaction data
+// returned from the submitrawtransactionpackage command.
+type SubmitRawTransactionPackageTxResult struct {
+	Txid   string `json:"txid"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// SubmitRawTransactionPackageResult models the data returned from the
+// submitrawtransactionpackage command.
+type SubmitRawTransactionPackageResult struct {
+	Accepted     bool                                  `json:"accepted"`
+	Transactions []SubmitRawTransactionPackageTxResult `json:"transactions"`
+}
+
+// TraceScriptStep models a single executed opcode of the data returned from
+// the tracescript command.
+type TraceScriptStep struct {
+	ScriptIdx int      `json:"scriptidx"`
+	ScriptOff int      `json:"scriptoff"`
+	Opcode    string   `json:"opcode"`
+	Stack     []string `json:"stack"`
+	AltStack  []string `json:"altstack"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// TraceScriptResult models the data returned from the tracescript command.
+type TraceScriptResult struct {
+	Valid bool              `json:"valid"`
+	Error string            `json:"error,omitempty"`
+	Flags uint32            `json:"flags"`
+	Steps []TraceScriptStep `json:"steps"`
+}
+
+// PshtPrevOut models the previous output spent by an input of the data
+// returned from the decodepsht command.
+type PshtPrevOut struct {
+	Amount       float64            `json:"amount"`
+	Version      uint16             `json:"version"`
+	ScriptPubKey ScriptPubKeyResult `json:"scriptPubKey"`
+}
+
+// PshtPartialSig models a partial signature of the data returned from the
+// decodepsht command.
+type PshtPartialSig struct {
+	PubKey    string `json:"pubkey"`
+	Signature string `json:"signature"`
+}
+
+// PshtBip32Derivation models a key derivation of the data returned from the
+// decodepsht command.
+type PshtBip32Derivation struct {
+	PubKey            string `json:"pubkey"`
+	MasterFingerprint string `json:"masterfingerprint"`
+	Path              string `json:"path"`
+}
+
+// PshtUnknown models a key-value pair not known by the server of the data
+// returned from the decodepsht command.
+type PshtUnknown struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// DecodePshtInput models the metadata of an input of the data returned from
+// the decodepsht command.
+type DecodePshtInput struct {
+	PrevOut        *PshtPrevOut          `json:"prevout,omitempty"`
+	PartialSigs    []PshtPartialSig      `json:"partialsigs,omitempty"`
+	SigHashType    uint32                `json:"sighashtype,omitempty"`
+	RedeemScript   string                `json:"redeemscript,omitempty"`
+	FinalScriptSig string                `json:"finalscriptsig,omitempty"`
+	Bip32Derivs    []PshtBip32Derivation `json:"bip32derivs,omitempty"`
+	Unknowns       []PshtUnknown         `json:"unknowns,omitempty"`
+}
+
+// DecodePshtOutput models the metadata of an output of the data returned from
+// the decodepsht command.
+type DecodePshtOutput struct {
+	RedeemScript string                `json:"redeemscript,omitempty"`
+	Bip32Derivs  []PshtBip32Derivation `json:"bip32derivs,omitempty"`
+	Unknowns     []PshtUnknown         `json:"unknowns,omitempty"`
+}
+
+// DecodePshtResult models the data returned from the decodepsht command.
+type DecodePshtResult struct {
+	Tx       TxRawDecodeResult  `json:"tx"`
+	Type     string             `json:"type"`
+	Unknowns []PshtUnknown      `json:"unknowns,omitempty"`
+	Inputs   []DecodePshtInput  `json:"inputs"`
+	Outputs  []DecodePshtOutput `json:"outputs"`
+	Fee      *float64           `json:"fee,omitempty"`
+}
+
+// FinalizePshtResult models the data returned from the finalizepsht command.
+type FinalizePshtResult struct {
+	Psht     string `json:"psht,omitempty"`
+	Hex      string `json:"hex,omitempty"`
+	Complete bool   `json:"complete"`
+}
+
+// GetChainTipsResult models the data returned from the getchaintips command.
 type GetChainTipsResult struct {
 	Height    int64  `json:"height"`
 	Hash      string `json:"hash"`
 	BranchLen int64  `json:"branchlen"`
+	Work      string `json:"work"`
 	Status    string `json:"status"`
 }
 
 // TxRawDecodeResult models the data from the decoderawtransaction command.
 type TxRawDecodeResult struct {
 	Txid     string `json:"txid"`
+	Type     string `json:"type"`
 	Version  int32  `json:"version"`
 	Locktime uint32 `json:"locktime"`
 	Expiry   uint32 `json:"expiry"`
@@ -532,8 +832,13 @@ type TxRawDecodeResult struct {
 // ValidateAddressChainResult models the data returned by the chain server
 // validateaddress command.
 type ValidateAddressChainResult struct {
-	IsValid bool   `json:"isvalid"`
-	Address string `json:"address,omitempty"`
+	IsValid      bool   `json:"isvalid"`
+	Address      string `json:"address,omitempty"`
+	Type         string `json:"type,omitempty"`
+	SigType      string `json:"sigtype,omitempty"`
+	IsScript     bool   `json:"isscript,omitempty"`
+	PubKey       string `json:"pubkey,omitempty"`
+	ScriptPubKey string `json:"scriptpubkey,omitempty"`
 }
 
 // GetHeadersResult models the data returned by the chain server getheaders
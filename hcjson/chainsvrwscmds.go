@@ -102,6 +102,17 @@ func NewNotifyNewTicketsCmd() *NotifyNewTicketsCmd {
 	return &NotifyNewTicketsCmd{}
 }
 
+// NotifyRevocableTicketsCmd is a type handling custom marshaling and
+// unmarshaling of notifyrevocabletickets JSON websocket extension
+// commands.
+type NotifyRevocableTicketsCmd struct {
+}
+
+// NewNotifyRevocableTicketsCmd creates a new NotifyRevocableTicketsCmd.
+func NewNotifyRevocableTicketsCmd() *NotifyRevocableTicketsCmd {
+	return &NotifyRevocableTicketsCmd{}
+}
+
 // NotifyStakeDifficultyCmd is a type handling custom marshaling and
 // unmarshaling of notifystakedifficulty JSON websocket extension
 // commands.
@@ -181,6 +192,8 @@ func init() {
 	MustRegisterCmd("notifyblocks", (*NotifyBlocksCmd)(nil), flags)
 	MustRegisterCmd("notifynewtransactions", (*NotifyNewTransactionsCmd)(nil), flags)
 	MustRegisterCmd("notifynewtickets", (*NotifyNewTicketsCmd)(nil), flags)
+	MustRegisterCmd("notifyrevocabletickets",
+		(*NotifyRevocableTicketsCmd)(nil), flags)
 	MustRegisterCmd("notifyspentandmissedtickets",
 		(*NotifySpentAndMissedTicketsCmd)(nil), flags)
 	MustRegisterCmd("notifystakedifficulty",
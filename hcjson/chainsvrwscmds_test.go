@@ -53,20 +53,6 @@ func TestChainSvrWsCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"notifywinningtickets","params":[],"id":1}`,
 			unmarshalled: &hcjson.NotifyWinningTicketsCmd{},
 		},
-		{
-			name: "estimatesmartfee",
-			newCmd: func() (interface{}, error) {
-				return NewCmd("estimatesmartfee", 6)
-			},
-			staticCmd: func() interface{} {
-				return NewEstimateSmartFeeCmd(6, nil)
-			},
-			marshalled: `{"jsonrpc":"1.0","method":"estimatesmartfee","params":[6],"id":1}`,
-			unmarshalled: &EstimateSmartFeeCmd{
-				Confirmations: 6,
-				Mode:          EstimateSmartFeeModeAddr(EstimateSmartFeeConservative),
-			},
-		},
 		{
 			name: "notifyspentandmissedtickets",
 			newCmd: func() (interface{}, error) {
@@ -89,6 +75,17 @@ func TestChainSvrWsCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"notifynewtickets","params":[],"id":1}`,
 			unmarshalled: &hcjson.NotifyNewTicketsCmd{},
 		},
+		{
+			name: "notifyrevocabletickets",
+			newCmd: func() (interface{}, error) {
+				return hcjson.NewCmd("notifyrevocabletickets")
+			},
+			staticCmd: func() interface{} {
+				return hcjson.NewNotifyRevocableTicketsCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"notifyrevocabletickets","params":[],"id":1}`,
+			unmarshalled: &hcjson.NotifyRevocableTicketsCmd{},
+		},
 		{
 			name: "notifystakedifficulty",
 			newCmd: func() (interface{}, error) {
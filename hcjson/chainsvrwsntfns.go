@@ -36,6 +36,16 @@ const (
 	// from the chain server that inform a client that a relevant
 	// transaction was accepted by the mempool.
 	RelevantTxAcceptedNtfnMethod = "relevanttxaccepted"
+
+	// TxExpiredNtfnMethod is the method used for notifications from the
+	// chain server that a transaction has been evicted from the mempool
+	// after exceeding the maximum allowed age.
+	TxExpiredNtfnMethod = "txexpired"
+
+	// CertRotatedNtfnMethod is the method used for notifications from the
+	// chain server that the TLS certificate of the RPC server has been
+	// rotated.
+	CertRotatedNtfnMethod = "certrotated"
 )
 
 // BlockConnectedNtfn defines the blockconnected JSON-RPC notification.
@@ -126,6 +136,32 @@ func NewRelevantTxAcceptedNtfn(txHex string) *RelevantTxAcceptedNtfn {
 	return &RelevantTxAcceptedNtfn{Transaction: txHex}
 }
 
+// TxExpiredNtfn defines the txexpired JSON-RPC notification.
+type TxExpiredNtfn struct {
+	TxID string `json:"txid"`
+}
+
+// NewTxExpiredNtfn returns a new instance which can be used to issue a
+// txexpired JSON-RPC notification.
+func NewTxExpiredNtfn(txHash string) *TxExpiredNtfn {
+	return &TxExpiredNtfn{TxID: txHash}
+}
+
+// CertRotatedNtfn defines the certrotated JSON-RPC notification.
+type CertRotatedNtfn struct {
+	Certificate string `json:"certificate"`
+	NotAfter    int64  `json:"notafter"`
+}
+
+// NewCertRotatedNtfn returns a new instance which can be used to issue a
+// certrotated JSON-RPC notification.
+func NewCertRotatedNtfn(certificate string, notAfter int64) *CertRotatedNtfn {
+	return &CertRotatedNtfn{
+		Certificate: certificate,
+		NotAfter:    notAfter,
+	}
+}
+
 func init() {
 	// The commands in this file are only usable by websockets and are
 	// notifications.
@@ -137,4 +173,6 @@ func init() {
 	MustRegisterCmd(TxAcceptedNtfnMethod, (*TxAcceptedNtfn)(nil), flags)
 	MustRegisterCmd(TxAcceptedVerboseNtfnMethod, (*TxAcceptedVerboseNtfn)(nil), flags)
 	MustRegisterCmd(RelevantTxAcceptedNtfnMethod, (*RelevantTxAcceptedNtfn)(nil), flags)
+	MustRegisterCmd(TxExpiredNtfnMethod, (*TxExpiredNtfn)(nil), flags)
+	MustRegisterCmd(CertRotatedNtfnMethod, (*CertRotatedNtfn)(nil), flags)
 }
@@ -128,6 +128,33 @@ func TestChainSvrWsNtfns(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "txexpired",
+			newNtfn: func() (interface{}, error) {
+				return hcjson.NewCmd("txexpired", "123")
+			},
+			staticNtfn: func() interface{} {
+				return hcjson.NewTxExpiredNtfn("123")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"txexpired","params":["123"],"id":null}`,
+			unmarshalled: &hcjson.TxExpiredNtfn{
+				TxID: "123",
+			},
+		},
+		{
+			name: "certrotated",
+			newNtfn: func() (interface{}, error) {
+				return hcjson.NewCmd("certrotated", "pem", 1700000000)
+			},
+			staticNtfn: func() interface{} {
+				return hcjson.NewCertRotatedNtfn("pem", 1700000000)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"certrotated","params":["pem",1700000000],"id":null}`,
+			unmarshalled: &hcjson.CertRotatedNtfn{
+				Certificate: "pem",
+				NotAfter:    1700000000,
+			},
+		},
 	}
 
 	t.Logf("Running %d tests", len(tests))
@@ -165,7 +192,6 @@ func TestChainSvrWsNtfns(t *testing.T) {
 				test.name, err)
 			continue
 		}
-		fmt.Println(marshalled)
 
 		if !bytes.Equal(marshalled, []byte(test.marshalled)) {
 			t.Errorf("Test #%d (%s) unexpected marshalled data - "+
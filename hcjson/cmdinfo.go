@@ -249,3 +249,84 @@ func MethodUsageText(method string) (string, error) {
 	registerLock.Unlock()
 	return usage, nil
 }
+
+// MethodParam describes a parameter of a registered command as returned by
+// MethodParams.
+type MethodParam struct {
+	// Name is the lowercase name of the parameter.
+	Name string
+
+	// Type is the JSON type of the parameter, which is one of string,
+	// numeric, boolean, array, object or value.
+	Type string
+
+	// Optional indicates whether or not the parameter may be omitted.
+	Optional bool
+
+	// Default is the value used when an optional parameter is omitted.  It
+	// is nil when the parameter has no default value.
+	Default interface{}
+}
+
+// jsonTypeName returns the name of the JSON type the passed reflect type is
+// represented by.
+func jsonTypeName(rt reflect.Type) string {
+	kind := rt.Kind()
+	if isNumeric(kind) {
+		return "numeric"
+	}
+
+	switch kind {
+	case reflect.String:
+		return "string"
+
+	case reflect.Bool:
+		return "boolean"
+
+	case reflect.Array, reflect.Slice:
+		return "array"
+
+	case reflect.Struct, reflect.Map:
+		return "object"
+	}
+
+	return "value"
+}
+
+// MethodParams returns a description of each parameter of the provided method
+// in the order they are passed.  The provided method must be associated with a
+// registered type.  All commands provided by this package are registered by
+// default.
+func MethodParams(method string) ([]MethodParam, error) {
+	// Look up details about the provided method and error out if not
+	// registered.
+	registerLock.RLock()
+	rtp, ok := methodToConcreteType[method]
+	info := methodToInfo[method]
+	registerLock.RUnlock()
+	if !ok {
+		str := fmt.Sprintf("%q is not registered", method)
+		return nil, makeError(ErrUnregisteredMethod, str)
+	}
+
+	// Several simplifying assumptions are made here because the
+	// RegisterCmd function has already rigorously enforced the layout.
+	rt := rtp.Elem()
+	params := make([]MethodParam, 0, rt.NumField())
+	for i := 0; i < rt.NumField(); i++ {
+		rtf := rt.Field(i)
+		fieldType := rtf.Type
+		param := MethodParam{Name: strings.ToLower(rtf.Name)}
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+			param.Optional = true
+		}
+		param.Type = jsonTypeName(fieldType)
+		if defaultVal, ok := info.defaults[i]; ok {
+			param.Default = defaultVal.Elem().Interface()
+		}
+		params = append(params, param)
+	}
+
+	return params, nil
+}
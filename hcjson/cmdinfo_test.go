@@ -202,6 +202,68 @@ func TestMethodUsageText(t *testing.T) {
 	}
 }
 
+// TestMethodParams tests the MethodParams function ensure it returns the
+// expected parameter descriptions.
+func TestMethodParams(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		method   string
+		err      error
+		expected []hcjson.MethodParam
+	}{
+		{
+			name:   "unregistered type",
+			method: "bogusmethod",
+			err:    hcjson.Error{Code: hcjson.ErrUnregisteredMethod},
+		},
+		{
+			name:     "getblockcount",
+			method:   "getblockcount",
+			expected: []hcjson.MethodParam{},
+		},
+		{
+			name:   "getblock",
+			method: "getblock",
+			expected: []hcjson.MethodParam{
+				{Name: "hash", Type: "string"},
+				{Name: "verbose", Type: "boolean", Optional: true,
+					Default: true},
+				{Name: "verbosetx", Type: "boolean", Optional: true,
+					Default: false},
+			},
+		},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		params, err := hcjson.MethodParams(test.method)
+		if reflect.TypeOf(err) != reflect.TypeOf(test.err) {
+			t.Errorf("Test #%d (%s) wrong error - got %T (%[3]v), "+
+				"want %T", i, test.name, err, test.err)
+			continue
+		}
+		if err != nil {
+			gotErrorCode := err.(hcjson.Error).Code
+			if gotErrorCode != test.err.(hcjson.Error).Code {
+				t.Errorf("Test #%d (%s) mismatched error code "+
+					"- got %v (%v), want %v", i, test.name,
+					gotErrorCode, err,
+					test.err.(hcjson.Error).Code)
+			}
+			continue
+		}
+
+		// Ensure the parameters match the expected values.
+		if !reflect.DeepEqual(params, test.expected) {
+			t.Errorf("Test #%d (%s) mismatched params - got %+v, "+
+				"want %+v", i, test.name, params, test.expected)
+			continue
+		}
+	}
+}
+
 // TestFieldUsage tests the internal fieldUsage function ensure it returns the
 // expected text.
 func TestFieldUsage(t *testing.T) {
@@ -8,6 +8,15 @@
 
 package hcjson
 
+// DescribeRPCCmd defines the describerpc JSON-RPC command.
+type DescribeRPCCmd struct{}
+
+// NewDescribeRPCCmd returns a new instance which can be used to issue a
+// describerpc JSON-RPC command.
+func NewDescribeRPCCmd() *DescribeRPCCmd {
+	return &DescribeRPCCmd{}
+}
+
 // EstimateStakeDiffCmd defines the eststakedifficulty JSON-RPC command.
 type EstimateStakeDiffCmd struct {
 	Tickets *uint32
@@ -111,13 +120,142 @@ func NewExistsMempoolTxsCmd(txHashBlob string) *ExistsMempoolTxsCmd {
 	}
 }
 
+// ExistsTxsCmd defines the existstxs JSON-RPC command.
+type ExistsTxsCmd struct {
+	TxHashes []string
+}
+
+// NewExistsTxsCmd returns a new instance which can be used to issue an
+// existstxs JSON-RPC command.
+func NewExistsTxsCmd(txHashes []string) *ExistsTxsCmd {
+	return &ExistsTxsCmd{
+		TxHashes: txHashes,
+	}
+}
+
+// GetBlockHeadersCmd defines the getblockheaders JSON-RPC command.
+type GetBlockHeadersCmd struct {
+	BlockLocators []string
+	HashStop      *string
+	Count         *int32 `jsonrpcdefault:"2000"`
+}
+
+// NewGetBlockHeadersCmd returns a new instance which can be used to issue a
+// getblockheaders JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetBlockHeadersCmd(blockLocators []string, hashStop *string, count *int32) *GetBlockHeadersCmd {
+	return &GetBlockHeadersCmd{
+		BlockLocators: blockLocators,
+		HashStop:      hashStop,
+		Count:         count,
+	}
+}
+
+// GetBlockReceivedTimeCmd defines the getblockreceivedtime JSON-RPC command.
+type GetBlockReceivedTimeCmd struct {
+	Hash string
+}
+
+// NewGetBlockReceivedTimeCmd returns a new instance which can be used to issue
+// a getblockreceivedtime JSON-RPC command.
+func NewGetBlockReceivedTimeCmd(hash string) *GetBlockReceivedTimeCmd {
+	return &GetBlockReceivedTimeCmd{
+		Hash: hash,
+	}
+}
+
+// GetBlockUndoCmd defines the getblockundo JSON-RPC command.
+type GetBlockUndoCmd struct {
+	Hash string
+}
+
+// NewGetBlockUndoCmd returns a new instance which can be used to issue a
+// getblockundo JSON-RPC command.
+func NewGetBlockUndoCmd(hash string) *GetBlockUndoCmd {
+	return &GetBlockUndoCmd{
+		Hash: hash,
+	}
+}
+
+// GetChainStatsCmd defines the getchainstats JSON-RPC command.
+type GetChainStatsCmd struct {
+	Blocks    *int32 `jsonrpcdefault:"2016"`
+	BlockHash *string
+}
+
+// NewGetChainStatsCmd returns a new instance which can be used to issue a
+// getchainstats JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetChainStatsCmd(blocks *int32, blockHash *string) *GetChainStatsCmd {
+	return &GetChainStatsCmd{
+		Blocks:    blocks,
+		BlockHash: blockHash,
+	}
+}
+
 // GetCoinSupplyCmd defines the getcoinsupply JSON-RPC command.
-type GetCoinSupplyCmd struct{}
+type GetCoinSupplyCmd struct {
+	Verbose   *bool `jsonrpcdefault:"false"`
+	BlockHash *string
+}
 
 // NewGetCoinSupplyCmd returns a new instance which can be used to issue a
 // getcoinsupply JSON-RPC command.
-func NewGetCoinSupplyCmd() *GetCoinSupplyCmd {
-	return &GetCoinSupplyCmd{}
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetCoinSupplyCmd(verbose *bool, blockHash *string) *GetCoinSupplyCmd {
+	return &GetCoinSupplyCmd{
+		Verbose:   verbose,
+		BlockHash: blockHash,
+	}
+}
+
+// GetCheckpointCandidatesCmd defines the getcheckpointcandidates JSON-RPC
+// command.
+type GetCheckpointCandidatesCmd struct {
+	Count *int32 `jsonrpcdefault:"1"`
+}
+
+// NewGetCheckpointCandidatesCmd returns a new instance which can be used to
+// issue a getcheckpointcandidates JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetCheckpointCandidatesCmd(count *int32) *GetCheckpointCandidatesCmd {
+	return &GetCheckpointCandidatesCmd{
+		Count: count,
+	}
+}
+
+// GetNetworkUpgradeInfoCmd defines the getnetworkupgradeinfo JSON-RPC
+// command.
+type GetNetworkUpgradeInfoCmd struct {
+	Blocks *int32
+}
+
+// NewGetNetworkUpgradeInfoCmd returns a new instance which can be used to
+// issue a getnetworkupgradeinfo JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetNetworkUpgradeInfoCmd(blocks *int32) *GetNetworkUpgradeInfoCmd {
+	return &GetNetworkUpgradeInfoCmd{
+		Blocks: blocks,
+	}
+}
+
+// GetRevocableTicketsCmd defines the getrevocabletickets JSON-RPC command.
+type GetRevocableTicketsCmd struct{}
+
+// NewGetRevocableTicketsCmd returns a new instance which can be used to issue a
+// getrevocabletickets JSON-RPC command.
+func NewGetRevocableTicketsCmd() *GetRevocableTicketsCmd {
+	return &GetRevocableTicketsCmd{}
 }
 
 // GetStakeDifficultyCmd is a type handling custom marshaling and
@@ -169,6 +307,24 @@ func NewGetTicketPoolValueCmd() *GetTicketPoolValueCmd {
 	return &GetTicketPoolValueCmd{}
 }
 
+// GetUtxoSetCmd defines the getutxoset JSON-RPC command.
+type GetUtxoSetCmd struct {
+	Cursor *string
+	Count  *int32 `jsonrpcdefault:"1000"`
+}
+
+// NewGetUtxoSetCmd returns a new instance which can be used to issue a
+// getutxoset JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetUtxoSetCmd(cursor *string, count *int32) *GetUtxoSetCmd {
+	return &GetUtxoSetCmd{
+		Cursor: cursor,
+		Count:  count,
+	}
+}
+
 // GetVoteInfoCmd returns voting results over a range of blocks.  Count
 // indicates how many blocks are walked backwards.
 type GetVoteInfoCmd struct {
@@ -203,6 +359,19 @@ func NewMissedTicketsCmd() *MissedTicketsCmd {
 	return &MissedTicketsCmd{}
 }
 
+// ReconsiderBlockCmd defines the reconsiderblock JSON-RPC command.
+type ReconsiderBlockCmd struct {
+	BlockHash string
+}
+
+// NewReconsiderBlockCmd returns a new instance which can be used to issue a
+// reconsiderblock JSON-RPC command.
+func NewReconsiderBlockCmd(blockHash string) *ReconsiderBlockCmd {
+	return &ReconsiderBlockCmd{
+		BlockHash: blockHash,
+	}
+}
+
 // RebroadcastMissedCmd is a type handling custom marshaling and
 // unmarshaling of rebroadcastwinners JSON RPC commands.
 type RebroadcastMissedCmd struct{}
@@ -223,6 +392,26 @@ func NewRebroadcastWinnersCmd() *RebroadcastWinnersCmd {
 	return &RebroadcastWinnersCmd{}
 }
 
+// SetTxRebroadcastCmd defines the settxrebroadcast JSON-RPC command.
+type SetTxRebroadcastCmd struct {
+	Enable      *bool
+	Interval    *int64
+	MaxInterval *int64
+}
+
+// NewSetTxRebroadcastCmd returns a new instance which can be used to issue a
+// settxrebroadcast JSON-RPC command.  The intervals are in seconds.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will leave the corresponding setting unchanged.
+func NewSetTxRebroadcastCmd(enable *bool, interval, maxInterval *int64) *SetTxRebroadcastCmd {
+	return &SetTxRebroadcastCmd{
+		Enable:      enable,
+		Interval:    interval,
+		MaxInterval: maxInterval,
+	}
+}
+
 // TicketFeeInfoCmd defines the ticketsfeeinfo JSON-RPC command.
 type TicketFeeInfoCmd struct {
 	Blocks  *uint32
@@ -281,6 +470,19 @@ func NewTxFeeInfoCmd(blocks *uint32, start *uint32, end *uint32) *TxFeeInfoCmd {
 	}
 }
 
+// VerifyHeaderChainCmd defines the verifyheaderchain JSON-RPC command.
+type VerifyHeaderChainCmd struct {
+	Headers []string
+}
+
+// NewVerifyHeaderChainCmd returns a new instance which can be used to issue a
+// verifyheaderchain JSON-RPC command.
+func NewVerifyHeaderChainCmd(headers []string) *VerifyHeaderChainCmd {
+	return &VerifyHeaderChainCmd{
+		Headers: headers,
+	}
+}
+
 // VersionCmd defines the version JSON-RPC command.
 type VersionCmd struct{}
 
@@ -292,6 +494,7 @@ func init() {
 	// No special flags for commands in this file.
 	flags := UsageFlag(0)
 
+	MustRegisterCmd("describerpc", (*DescribeRPCCmd)(nil), flags)
 	MustRegisterCmd("estimatestakediff", (*EstimateStakeDiffCmd)(nil), flags)
 	MustRegisterCmd("existsaddress", (*ExistsAddressCmd)(nil), flags)
 	MustRegisterCmd("existsaddresses", (*ExistsAddressesCmd)(nil), flags)
@@ -300,19 +503,31 @@ func init() {
 	MustRegisterCmd("existsliveticket", (*ExistsLiveTicketCmd)(nil), flags)
 	MustRegisterCmd("existslivetickets", (*ExistsLiveTicketsCmd)(nil), flags)
 	MustRegisterCmd("existsmempooltxs", (*ExistsMempoolTxsCmd)(nil), flags)
+	MustRegisterCmd("existstxs", (*ExistsTxsCmd)(nil), flags)
+	MustRegisterCmd("getblockheaders", (*GetBlockHeadersCmd)(nil), flags)
+	MustRegisterCmd("getblockreceivedtime", (*GetBlockReceivedTimeCmd)(nil), flags)
+	MustRegisterCmd("getblockundo", (*GetBlockUndoCmd)(nil), flags)
+	MustRegisterCmd("getchainstats", (*GetChainStatsCmd)(nil), flags)
+	MustRegisterCmd("getcheckpointcandidates", (*GetCheckpointCandidatesCmd)(nil), flags)
 	MustRegisterCmd("getcoinsupply", (*GetCoinSupplyCmd)(nil), flags)
+	MustRegisterCmd("getnetworkupgradeinfo", (*GetNetworkUpgradeInfoCmd)(nil), flags)
+	MustRegisterCmd("getrevocabletickets", (*GetRevocableTicketsCmd)(nil), flags)
 	MustRegisterCmd("getstakedifficulty", (*GetStakeDifficultyCmd)(nil), flags)
 	MustRegisterCmd("getstakeversioninfo", (*GetStakeVersionInfoCmd)(nil), flags)
 	MustRegisterCmd("getstakeversions", (*GetStakeVersionsCmd)(nil), flags)
 	MustRegisterCmd("getticketpoolvalue", (*GetTicketPoolValueCmd)(nil), flags)
+	MustRegisterCmd("getutxoset", (*GetUtxoSetCmd)(nil), flags)
 	MustRegisterCmd("getvoteinfo", (*GetVoteInfoCmd)(nil), flags)
 	MustRegisterCmd("livetickets", (*LiveTicketsCmd)(nil), flags)
 	MustRegisterCmd("missedtickets", (*MissedTicketsCmd)(nil), flags)
+	MustRegisterCmd("reconsiderblock", (*ReconsiderBlockCmd)(nil), flags)
 	MustRegisterCmd("rebroadcastmissed", (*RebroadcastMissedCmd)(nil), flags)
 	MustRegisterCmd("rebroadcastwinners", (*RebroadcastWinnersCmd)(nil), flags)
+	MustRegisterCmd("settxrebroadcast", (*SetTxRebroadcastCmd)(nil), flags)
 	MustRegisterCmd("ticketfeeinfo", (*TicketFeeInfoCmd)(nil), flags)
 	MustRegisterCmd("ticketsforaddress", (*TicketsForAddressCmd)(nil), flags)
 	MustRegisterCmd("ticketvwap", (*TicketVWAPCmd)(nil), flags)
 	MustRegisterCmd("txfeeinfo", (*TxFeeInfoCmd)(nil), flags)
+	MustRegisterCmd("verifyheaderchain", (*VerifyHeaderChainCmd)(nil), flags)
 	MustRegisterCmd("version", (*VersionCmd)(nil), flags)
 }
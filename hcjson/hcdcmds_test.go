@@ -43,6 +43,207 @@ func TestDcrdCmds(t *testing.T) {
 				LevelSpec: "trace",
 			},
 		},
+		{
+			name: "describerpc",
+			newCmd: func() (interface{}, error) {
+				return hcjson.NewCmd("describerpc")
+			},
+			staticCmd: func() interface{} {
+				return hcjson.NewDescribeRPCCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"describerpc","params":[],"id":1}`,
+			unmarshalled: &hcjson.DescribeRPCCmd{},
+		},
+		{
+			name: "existstxs",
+			newCmd: func() (interface{}, error) {
+				return hcjson.NewCmd("existstxs", []string{"123", "456"})
+			},
+			staticCmd: func() interface{} {
+				return hcjson.NewExistsTxsCmd([]string{"123", "456"})
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"existstxs","params":[["123","456"]],"id":1}`,
+			unmarshalled: &hcjson.ExistsTxsCmd{
+				TxHashes: []string{"123", "456"},
+			},
+		},
+		{
+			name: "getblockheaders",
+			newCmd: func() (interface{}, error) {
+				return hcjson.NewCmd("getblockheaders", []string{"123"})
+			},
+			staticCmd: func() interface{} {
+				return hcjson.NewGetBlockHeadersCmd([]string{"123"}, nil,
+					nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getblockheaders","params":[["123"]],"id":1}`,
+			unmarshalled: &hcjson.GetBlockHeadersCmd{
+				BlockLocators: []string{"123"},
+				Count:         hcjson.Int32(2000),
+			},
+		},
+		{
+			name: "getblockheaders optional",
+			newCmd: func() (interface{}, error) {
+				return hcjson.NewCmd("getblockheaders", []string{"123"},
+					"456", 10)
+			},
+			staticCmd: func() interface{} {
+				return hcjson.NewGetBlockHeadersCmd([]string{"123"},
+					hcjson.String("456"), hcjson.Int32(10))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getblockheaders","params":[["123"],"456",10],"id":1}`,
+			unmarshalled: &hcjson.GetBlockHeadersCmd{
+				BlockLocators: []string{"123"},
+				HashStop:      hcjson.String("456"),
+				Count:         hcjson.Int32(10),
+			},
+		},
+		{
+			name: "getblockreceivedtime",
+			newCmd: func() (interface{}, error) {
+				return hcjson.NewCmd("getblockreceivedtime", "123")
+			},
+			staticCmd: func() interface{} {
+				return hcjson.NewGetBlockReceivedTimeCmd("123")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getblockreceivedtime","params":["123"],"id":1}`,
+			unmarshalled: &hcjson.GetBlockReceivedTimeCmd{
+				Hash: "123",
+			},
+		},
+		{
+			name: "getblockundo",
+			newCmd: func() (interface{}, error) {
+				return hcjson.NewCmd("getblockundo", "123")
+			},
+			staticCmd: func() interface{} {
+				return hcjson.NewGetBlockUndoCmd("123")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getblockundo","params":["123"],"id":1}`,
+			unmarshalled: &hcjson.GetBlockUndoCmd{
+				Hash: "123",
+			},
+		},
+		{
+			name: "getchainstats",
+			newCmd: func() (interface{}, error) {
+				return hcjson.NewCmd("getchainstats")
+			},
+			staticCmd: func() interface{} {
+				return hcjson.NewGetChainStatsCmd(nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getchainstats","params":[],"id":1}`,
+			unmarshalled: &hcjson.GetChainStatsCmd{
+				Blocks:    hcjson.Int32(2016),
+				BlockHash: nil,
+			},
+		},
+		{
+			name: "getchainstats optional",
+			newCmd: func() (interface{}, error) {
+				return hcjson.NewCmd("getchainstats", 144, "123")
+			},
+			staticCmd: func() interface{} {
+				return hcjson.NewGetChainStatsCmd(hcjson.Int32(144),
+					hcjson.String("123"))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getchainstats","params":[144,"123"],"id":1}`,
+			unmarshalled: &hcjson.GetChainStatsCmd{
+				Blocks:    hcjson.Int32(144),
+				BlockHash: hcjson.String("123"),
+			},
+		},
+		{
+			name: "getcoinsupply",
+			newCmd: func() (interface{}, error) {
+				return hcjson.NewCmd("getcoinsupply")
+			},
+			staticCmd: func() interface{} {
+				return hcjson.NewGetCoinSupplyCmd(nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getcoinsupply","params":[],"id":1}`,
+			unmarshalled: &hcjson.GetCoinSupplyCmd{
+				Verbose:   hcjson.Bool(false),
+				BlockHash: nil,
+			},
+		},
+		{
+			name: "getcoinsupply optional",
+			newCmd: func() (interface{}, error) {
+				return hcjson.NewCmd("getcoinsupply", true, "123")
+			},
+			staticCmd: func() interface{} {
+				return hcjson.NewGetCoinSupplyCmd(hcjson.Bool(true),
+					hcjson.String("123"))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getcoinsupply","params":[true,"123"],"id":1}`,
+			unmarshalled: &hcjson.GetCoinSupplyCmd{
+				Verbose:   hcjson.Bool(true),
+				BlockHash: hcjson.String("123"),
+			},
+		},
+		{
+			name: "getcheckpointcandidates",
+			newCmd: func() (interface{}, error) {
+				return hcjson.NewCmd("getcheckpointcandidates")
+			},
+			staticCmd: func() interface{} {
+				return hcjson.NewGetCheckpointCandidatesCmd(nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getcheckpointcandidates","params":[],"id":1}`,
+			unmarshalled: &hcjson.GetCheckpointCandidatesCmd{
+				Count: hcjson.Int32(1),
+			},
+		},
+		{
+			name: "getcheckpointcandidates optional",
+			newCmd: func() (interface{}, error) {
+				return hcjson.NewCmd("getcheckpointcandidates", 5)
+			},
+			staticCmd: func() interface{} {
+				return hcjson.NewGetCheckpointCandidatesCmd(hcjson.Int32(5))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getcheckpointcandidates","params":[5],"id":1}`,
+			unmarshalled: &hcjson.GetCheckpointCandidatesCmd{
+				Count: hcjson.Int32(5),
+			},
+		},
+		{
+			name: "getnetworkupgradeinfo",
+			newCmd: func() (interface{}, error) {
+				return hcjson.NewCmd("getnetworkupgradeinfo")
+			},
+			staticCmd: func() interface{} {
+				return hcjson.NewGetNetworkUpgradeInfoCmd(nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getnetworkupgradeinfo","params":[],"id":1}`,
+			unmarshalled: &hcjson.GetNetworkUpgradeInfoCmd{Blocks: nil},
+		},
+		{
+			name: "getnetworkupgradeinfo optional",
+			newCmd: func() (interface{}, error) {
+				return hcjson.NewCmd("getnetworkupgradeinfo", 100)
+			},
+			staticCmd: func() interface{} {
+				return hcjson.NewGetNetworkUpgradeInfoCmd(hcjson.Int32(100))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getnetworkupgradeinfo","params":[100],"id":1}`,
+			unmarshalled: &hcjson.GetNetworkUpgradeInfoCmd{
+				Blocks: hcjson.Int32(100),
+			},
+		},
+		{
+			name: "getrevocabletickets",
+			newCmd: func() (interface{}, error) {
+				return hcjson.NewCmd("getrevocabletickets")
+			},
+			staticCmd: func() interface{} {
+				return hcjson.NewGetRevocableTicketsCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getrevocabletickets","params":[],"id":1}`,
+			unmarshalled: &hcjson.GetRevocableTicketsCmd{},
+		},
 		{
 			name: "getstakeversions",
 			newCmd: func() (interface{}, error) {
@@ -57,6 +258,35 @@ func TestDcrdCmds(t *testing.T) {
 				Count: 1,
 			},
 		},
+		{
+			name: "getutxoset",
+			newCmd: func() (interface{}, error) {
+				return hcjson.NewCmd("getutxoset")
+			},
+			staticCmd: func() interface{} {
+				return hcjson.NewGetUtxoSetCmd(nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getutxoset","params":[],"id":1}`,
+			unmarshalled: &hcjson.GetUtxoSetCmd{
+				Cursor: nil,
+				Count:  hcjson.Int32(1000),
+			},
+		},
+		{
+			name: "getutxoset optional",
+			newCmd: func() (interface{}, error) {
+				return hcjson.NewCmd("getutxoset", "abc", 10)
+			},
+			staticCmd: func() interface{} {
+				return hcjson.NewGetUtxoSetCmd(hcjson.String("abc"),
+					hcjson.Int32(10))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getutxoset","params":["abc",10],"id":1}`,
+			unmarshalled: &hcjson.GetUtxoSetCmd{
+				Cursor: hcjson.String("abc"),
+				Count:  hcjson.Int32(10),
+			},
+		},
 		{
 			name: "getvoteinfo",
 			newCmd: func() (interface{}, error) {
@@ -70,6 +300,59 @@ func TestDcrdCmds(t *testing.T) {
 				Version: 1,
 			},
 		},
+		{
+			name: "reconsiderblock",
+			newCmd: func() (interface{}, error) {
+				return hcjson.NewCmd("reconsiderblock", "123")
+			},
+			staticCmd: func() interface{} {
+				return hcjson.NewReconsiderBlockCmd("123")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"reconsiderblock","params":["123"],"id":1}`,
+			unmarshalled: &hcjson.ReconsiderBlockCmd{
+				BlockHash: "123",
+			},
+		},
+		{
+			name: "settxrebroadcast",
+			newCmd: func() (interface{}, error) {
+				return hcjson.NewCmd("settxrebroadcast")
+			},
+			staticCmd: func() interface{} {
+				return hcjson.NewSetTxRebroadcastCmd(nil, nil, nil)
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"settxrebroadcast","params":[],"id":1}`,
+			unmarshalled: &hcjson.SetTxRebroadcastCmd{},
+		},
+		{
+			name: "settxrebroadcast optional",
+			newCmd: func() (interface{}, error) {
+				return hcjson.NewCmd("settxrebroadcast", true, 60, 3600)
+			},
+			staticCmd: func() interface{} {
+				return hcjson.NewSetTxRebroadcastCmd(hcjson.Bool(true),
+					hcjson.Int64(60), hcjson.Int64(3600))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"settxrebroadcast","params":[true,60,3600],"id":1}`,
+			unmarshalled: &hcjson.SetTxRebroadcastCmd{
+				Enable:      hcjson.Bool(true),
+				Interval:    hcjson.Int64(60),
+				MaxInterval: hcjson.Int64(3600),
+			},
+		},
+		{
+			name: "verifyheaderchain",
+			newCmd: func() (interface{}, error) {
+				return hcjson.NewCmd("verifyheaderchain", []string{"01", "02"})
+			},
+			staticCmd: func() interface{} {
+				return hcjson.NewVerifyHeaderChainCmd([]string{"01", "02"})
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"verifyheaderchain","params":[["01","02"]],"id":1}`,
+			unmarshalled: &hcjson.VerifyHeaderChainCmd{
+				Headers: []string{"01", "02"},
+			},
+		},
 	}
 
 	t.Logf("Running %d tests", len(tests))
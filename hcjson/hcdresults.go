@@ -6,6 +6,121 @@
 
 package hcjson
 
+// CheckpointCandidateResult models the data of a checkpoint candidate returned
+// from the getcheckpointcandidates command.
+type CheckpointCandidateResult struct {
+	Height int64  `json:"height"`
+	Hash   string `json:"hash"`
+}
+
+// DescribeRPCParam models a parameter of a method as returned by the
+// describerpc command.
+type DescribeRPCParam struct {
+	Name     string      `json:"name"`
+	Type     string      `json:"type"`
+	Optional bool        `json:"optional"`
+	Default  interface{} `json:"default,omitempty"`
+}
+
+// DescribeRPCResult models the data of a method returned from the describerpc
+// command.
+type DescribeRPCResult struct {
+	Method  string             `json:"method"`
+	Usage   string             `json:"usage"`
+	Limited bool               `json:"limited"`
+	Params  []DescribeRPCParam `json:"params"`
+}
+
+// ExistsTxsResult models the data returned from the existstxs command.  Each
+// field is a hex-encoded bitset with a bit for each of the queried hashes in
+// order.
+type ExistsTxsResult struct {
+	Mempool     string `json:"mempool"`
+	LiveTickets string `json:"livetickets"`
+}
+
+// GetBlockHeadersResult models the data returned from the getblockheaders
+// command.
+type GetBlockHeadersResult struct {
+	Headers     []string `json:"headers"`
+	StartHeight int64    `json:"startheight,omitempty"`
+	BestHeight  int64    `json:"bestheight"`
+}
+
+// GetBlockReceivedTimeResult models the data returned from the
+// getblockreceivedtime command.
+type GetBlockReceivedTimeResult struct {
+	Time       int64  `json:"time"`
+	TimeMillis int64  `json:"timemillis"`
+	Source     string `json:"source"`
+}
+
+// CoinSupplyResult models a breakdown of coins by how they were issued as
+// returned from the getcoinsupply command.  All amounts are in atoms.
+type CoinSupplyResult struct {
+	PoW           int64 `json:"pow"`
+	PoS           int64 `json:"pos"`
+	Dev           int64 `json:"dev"`
+	BlockOne      int64 `json:"blockone"`
+	Subsidy       int64 `json:"subsidy"`
+	FeesDestroyed int64 `json:"feesdestroyed"`
+	Supply        int64 `json:"supply"`
+}
+
+// GetCoinSupplyResult models the data returned from the getcoinsupply command
+// when the verbose flag is set.
+type GetCoinSupplyResult struct {
+	Hash   string           `json:"hash"`
+	Height int64            `json:"height"`
+	Block  CoinSupplyResult `json:"block"`
+	Total  CoinSupplyResult `json:"total"`
+}
+
+// SpentOutputResult models an output spent by a transaction of a block as
+// returned from the getblockundo command.
+type SpentOutputResult struct {
+	TxID         string             `json:"txid"`
+	Vin          uint32             `json:"vin"`
+	PrevTxID     string             `json:"prevtxid"`
+	PrevVout     uint32             `json:"prevvout"`
+	PrevTree     int8               `json:"prevtree"`
+	Amount       float64            `json:"amount"`
+	Height       int64              `json:"height"`
+	BlockIndex   uint32             `json:"blockindex"`
+	ScriptPubKey ScriptPubKeyResult `json:"scriptPubKey"`
+}
+
+// GetBlockUndoResult models the data returned from the getblockundo command.
+type GetBlockUndoResult struct {
+	Hash   string              `json:"hash"`
+	Height int64               `json:"height"`
+	Spent  []SpentOutputResult `json:"spent"`
+}
+
+// UtxoSetEntryResult models an unspent transaction output returned from the
+// getutxoset command.
+type UtxoSetEntryResult struct {
+	TxID          string  `json:"txid"`
+	Vout          uint32  `json:"vout"`
+	Tree          int8    `json:"tree"`
+	Amount        float64 `json:"amount"`
+	Height        int64   `json:"height"`
+	BlockIndex    uint32  `json:"blockindex"`
+	Coinbase      bool    `json:"coinbase"`
+	TxType        string  `json:"txtype"`
+	ScriptVersion uint16  `json:"scriptversion"`
+	PkScript      string  `json:"pkscript"`
+}
+
+// GetUtxoSetResult models the data returned from the getutxoset command.
+type GetUtxoSetResult struct {
+	Cursor string               `json:"cursor"`
+	Hash   string               `json:"hash"`
+	Height int64                `json:"height"`
+	Done   bool                 `json:"done"`
+	Utxos  []UtxoSetEntryResult `json:"utxos"`
+}
+
 // GetStakeDifficultyResult models the data returned from the
 // getstakedifficulty command.
 type GetStakeDifficultyResult struct {
@@ -19,12 +134,35 @@ type VersionCount struct {
 	Count   uint32 `json:"count"`
 }
 
+// VersionBitsCount models a generic version:bits:count tuple.
+type VersionBitsCount struct {
+	Version uint32 `json:"version"`
+	Bits    uint16 `json:"bits"`
+	Count   uint32 `json:"count"`
+}
+
+// AgendaChoiceCount models how many votes picked a choice of an agenda.
+type AgendaChoiceCount struct {
+	ID    string `json:"id"`
+	Count uint32 `json:"count"`
+}
+
+// AgendaTally models the choices the votes of an interval picked for an
+// agenda.
+type AgendaTally struct {
+	ID      string              `json:"id"`
+	Version uint32              `json:"version"`
+	Choices []AgendaChoiceCount `json:"choices"`
+}
+
 // VersionInterval models a cooked version count for an interval.
 type VersionInterval struct {
-	StartHeight  int64          `json:"startheight"`
-	EndHeight    int64          `json:"endheight"`
-	PoSVersions  []VersionCount `json:"posversions"`
-	VoteVersions []VersionCount `json:"voteversions"`
+	StartHeight  int64              `json:"startheight"`
+	EndHeight    int64              `json:"endheight"`
+	PoSVersions  []VersionCount     `json:"posversions"`
+	VoteVersions []VersionCount     `json:"voteversions"`
+	VoteBits     []VersionBitsCount `json:"votebits"`
+	Agendas      []AgendaTally      `json:"agendas"`
 }
 
 // GetStakeVersionInfoResult models the resulting data for getstakeversioninfo
@@ -56,6 +194,64 @@ type GetStakeVersionsResult struct {
 	StakeVersions []StakeVersions `json:"stakeversions"`
 }
 
+// VersionShare models how many peers, blocks or votes signal a version and
+// which percentage of them it represents.
+type VersionShare struct {
+	Version uint32  `json:"version"`
+	Count   uint32  `json:"count"`
+	Percent float64 `json:"percent"`
+}
+
+// NetworkUpgradePeersResult models the protocol versions of the connected peers
+// for the getnetworkupgradeinfo command.
+type NetworkUpgradePeersResult struct {
+	ProtocolVersion uint32         `json:"protocolversion"`
+	Connected       uint32         `json:"connected"`
+	Upgraded        uint32         `json:"upgraded"`
+	Versions        []VersionShare `json:"versions"`
+}
+
+// NetworkUpgradeBlocksResult models the versions signaled by the most recent
+// blocks for the getnetworkupgradeinfo command.
+type NetworkUpgradeBlocksResult struct {
+	Window          uint32         `json:"window"`
+	EnforceRequired uint64         `json:"enforcerequired"`
+	RejectRequired  uint64         `json:"rejectrequired"`
+	BlockVersions   []VersionShare `json:"blockversions"`
+	StakeVersions   []VersionShare `json:"stakeversions"`
+	VoteVersions    []VersionShare `json:"voteversions"`
+}
+
+// GetChainStatsResult models the data returned from the getchainstats
+// command.
+type GetChainStatsResult struct {
+	StartHeight        int64   `json:"startheight"`
+	EndHeight          int64   `json:"endheight"`
+	EndHash            string  `json:"endhash"`
+	Blocks             int64   `json:"blocks"`
+	WindowInterval     int64   `json:"windowinterval"`
+	AvgBlockInterval   float64 `json:"avgblockinterval"`
+	TxCount            int64   `json:"txcount"`
+	TxRate             float64 `json:"txrate"`
+	TotalFees          float64 `json:"totalfees"`
+	AvgBlockFees       float64 `json:"avgblockfees"`
+	Tickets            int64   `json:"tickets"`
+	Votes              int64   `json:"votes"`
+	Revocations        int64   `json:"revocations"`
+	StakeParticipation float64 `json:"stakeparticipation"`
+}
+
+// GetNetworkUpgradeInfoResult models the data returned from the
+// getnetworkupgradeinfo command.
+type GetNetworkUpgradeInfoResult struct {
+	Height             int64                      `json:"height"`
+	Hash               string                     `json:"hash"`
+	RuleChangeHeight   int64                      `json:"rulechangeheight"`
+	StakeVersionHeight int64                      `json:"stakeversionheight"`
+	Peers              NetworkUpgradePeersResult  `json:"peers"`
+	Blocks             NetworkUpgradeBlocksResult `json:"blocks"`
+}
+
 // Choice models an individual choice inside an Agenda.
 type Choice struct {
 	Id          string  `json:"id"`
@@ -75,6 +271,7 @@ type Agenda struct {
 	StartTime      uint64   `json:"starttime"`
 	ExpireTime     uint64   `json:"expiretime"`
 	Status         string   `json:"status"`
+	Choice         string   `json:"choice,omitempty"`
 	QuorumProgress float64  `json:"quorumprogress"`
 	Choices        []Choice `json:"choices"`
 }
@@ -112,6 +309,36 @@ type MissedTicketsResult struct {
 	Tickets []string `json:"tickets"`
 }
 
+// RevocationOutputResult models an output a revocation must pay to as returned
+// from the getrevocabletickets command.
+type RevocationOutputResult struct {
+	Address string  `json:"address"`
+	Amount  float64 `json:"amount"`
+	MaxFee  float64 `json:"maxfee"`
+}
+
+// RevocableTicketResult models a revocable ticket as returned from the
+// getrevocabletickets command.
+type RevocableTicketResult struct {
+	Ticket        string                   `json:"ticket"`
+	Height        int64                    `json:"height"`
+	Expired       bool                     `json:"expired"`
+	Vout          uint32                   `json:"vout"`
+	Tree          int8                     `json:"tree"`
+	Amount        float64                  `json:"amount"`
+	ScriptVersion uint16                   `json:"scriptversion"`
+	ScriptPubKey  string                   `json:"scriptpubkey"`
+	Outputs       []RevocationOutputResult `json:"outputs"`
+}
+
+// GetRevocableTicketsResult models the data returned from the
+// getrevocabletickets command.
+type GetRevocableTicketsResult struct {
+	Hash    string                  `json:"hash"`
+	Height  int64                   `json:"height"`
+	Tickets []RevocableTicketResult `json:"tickets"`
+}
+
 // Ticket is the structure representing a ticket.
 type Ticket struct {
 	Hash  string `json:"hash"`
@@ -161,6 +388,15 @@ type FeeInfoWindow struct {
 	StdDev      float64 `json:"stddev"`
 }
 
+// SetTxRebroadcastResult models the data returned from the settxrebroadcast
+// command.
+type SetTxRebroadcastResult struct {
+	Enabled     bool  `json:"enabled"`
+	Interval    int64 `json:"interval"`
+	MaxInterval int64 `json:"maxinterval"`
+	Pending     int   `json:"pending"`
+}
+
 // TicketFeeInfoResult models the data returned from the ticketfeeinfo command.
 // command.
 type TicketFeeInfoResult struct {
@@ -183,6 +419,17 @@ type TxFeeInfoResult struct {
 	FeeInfoRange   FeeInfoRange   `json:"feeinforange"`
 }
 
+// VerifyHeaderChainResult models the data returned from the verifyheaderchain
+// command.  The height, hash and cumulative chain work are those of the last
+// header and are only set when the headers are valid.
+type VerifyHeaderChainResult struct {
+	Valid     bool   `json:"valid"`
+	Error     string `json:"error,omitempty"`
+	Height    int64  `json:"height,omitempty"`
+	Hash      string `json:"hash,omitempty"`
+	ChainWork string `json:"chainwork,omitempty"`
+}
+
 // VersionResult models objects included in the version response.  In the actual
 // result, these objects are keyed by the program or API name.
 type VersionResult struct {
@@ -33,6 +33,10 @@ const (
 	// newtickets notification.
 	NewTicketsNtfnMethod = "newtickets"
 
+	// RevocableTicketsNtfnMethod is the method of the daemon
+	// revocabletickets notification.
+	RevocableTicketsNtfnMethod = "revocabletickets"
+
 	// StakeDifficultyNtfnMethod is the method of the daemon
 	// stakedifficulty notification.
 	StakeDifficultyNtfnMethod = "stakedifficulty"
@@ -144,6 +148,25 @@ func NewNewTicketsNtfn(hash string, height int32, stakeDiff int64, tickets []str
 	}
 }
 
+// RevocableTicketsNtfn is a type handling custom marshaling and
+// unmarshaling of revocabletickets JSON websocket notifications.
+type RevocableTicketsNtfn struct {
+	Hash    string
+	Height  int32
+	Missed  []string
+	Expired []string
+}
+
+// NewRevocableTicketsNtfn creates a new RevocableTicketsNtfn.
+func NewRevocableTicketsNtfn(hash string, height int32, missed, expired []string) *RevocableTicketsNtfn {
+	return &RevocableTicketsNtfn{
+		Hash:    hash,
+		Height:  height,
+		Missed:  missed,
+		Expired: expired,
+	}
+}
+
 // StakeDifficultyNtfn is a type handling custom marshaling and
 // unmarshaling of stakedifficulty JSON websocket notifications.
 type StakeDifficultyNtfn struct {
@@ -172,5 +195,6 @@ func init() {
 	MustRegisterCmd(WinningTicketsNtfnMethod, (*WinningTicketsNtfn)(nil), flags)
 	MustRegisterCmd(SpentAndMissedTicketsNtfnMethod, (*SpentAndMissedTicketsNtfn)(nil), flags)
 	MustRegisterCmd(NewTicketsNtfnMethod, (*NewTicketsNtfn)(nil), flags)
+	MustRegisterCmd(RevocableTicketsNtfnMethod, (*RevocableTicketsNtfn)(nil), flags)
 	MustRegisterCmd(StakeDifficultyNtfnMethod, (*StakeDifficultyNtfn)(nil), flags)
 }
@@ -121,6 +121,22 @@ func TestHcwalletChainSvrWsNtfns(t *testing.T) {
 				Tickets:   []string{"a", "b"},
 			},
 		},
+		{
+			name: "revocabletickets",
+			newNtfn: func() (interface{}, error) {
+				return hcjson.NewCmd("revocabletickets", "123", 100, []string{"a"}, []string{"b"})
+			},
+			staticNtfn: func() interface{} {
+				return hcjson.NewRevocableTicketsNtfn("123", 100, []string{"a"}, []string{"b"})
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"revocabletickets","params":["123",100,["a"],["b"]],"id":null}`,
+			unmarshalled: &hcjson.RevocableTicketsNtfn{
+				Hash:    "123",
+				Height:  100,
+				Missed:  []string{"a"},
+				Expired: []string{"b"},
+			},
+		},
 	}
 
 	t.Logf("Running %d tests", len(tests))
@@ -219,7 +219,7 @@ func TestIsValidIDTypeB(t *testing.T) {
 
 
 
-	t.log("for test start")
+	t.Log("for test start")
 
 	for i, test := range tests {
 
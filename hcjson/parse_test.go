@@ -113,7 +113,8 @@ func TestEncodeConcatenatedVoteBits(t *testing.T) {
 
 	// Test too long voteBits extended.
 	testVbs = []stake.VoteBits{
-		{Bits: 0, ExtendedBits: bytes.Repeat([]byte{0x00}, 74)},
+		{Bits: 0, ExtendedBits: bytes.Repeat([]byte{0x00},
+			stake.SSGenVoteBitsExtendedMaxSize+1)},
 	}
 	_, err = hcjson.EncodeConcatenatedVoteBits(testVbs)
 	if err == nil {
@@ -225,7 +226,7 @@ func TestInvalidDecodeConcatenatedHashes(t *testing.T) {
 		_, err := hcjson.DecodeConcatenatedHashes(str.str)
 		if err == nil {
 			t.Fatalf("DecodeConcatenatedHashes passed on '%s' "+
-				"when it should have failed", str)
+				"when it should have failed", str.str)
 		}
 		rpcError, ok := err.(*hcjson.RPCError)
 		if !ok {
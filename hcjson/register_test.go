@@ -26,6 +26,7 @@ func TestUsageFlagStringer(t *testing.T) {
 		{hcjson.UFWalletOnly, "UFWalletOnly"},
 		{hcjson.UFWebsocketOnly, "UFWebsocketOnly"},
 		{hcjson.UFNotification, "UFNotification"},
+		{hcjson.Omni, "Omni"},
 		{hcjson.UFWalletOnly | hcjson.UFWebsocketOnly,
 			"UFWalletOnly|UFWebsocketOnly"},
 		{hcjson.UFWalletOnly | hcjson.UFWebsocketOnly | (1 << 31),
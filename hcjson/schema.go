@@ -0,0 +1,315 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package hcjson
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// SchemaVersion is the JSON Schema draft the schemas generated by
+// GenerateSchema conform to.
+const SchemaVersion = "http://json-schema.org/draft-07/schema#"
+
+// MethodSchema describes the parameters and results of a registered command
+// as returned by GenerateSchema.
+type MethodSchema struct {
+	// Description is the synopsis of the command when one is available.
+	Description string `json:"description,omitempty"`
+
+	// WalletOnly, WebsocketOnly and Notification reflect the usage flags
+	// the command was registered with.
+	WalletOnly    bool `json:"walletonly,omitempty"`
+	WebsocketOnly bool `json:"websocketonly,omitempty"`
+	Notification  bool `json:"notification,omitempty"`
+
+	// Params is the JSON schema of the positional parameters array of the
+	// command.
+	Params map[string]interface{} `json:"params"`
+
+	// Result is the JSON schema of the result of the command.  It is nil
+	// when the result types of the command are unknown.
+	Result map[string]interface{} `json:"result,omitempty"`
+}
+
+// Schema is a machine-readable description of every registered command along
+// with the JSON schemas of the named types they refer to.
+type Schema struct {
+	Schema      string                            `json:"$schema"`
+	Methods     map[string]MethodSchema           `json:"methods"`
+	Definitions map[string]map[string]interface{} `json:"definitions"`
+}
+
+// schemaGenerator builds the JSON schemas of reflect types while collecting
+// the schemas of named struct types as definitions which are referenced
+// instead of being repeated.
+type schemaGenerator struct {
+	xT          descLookupFunc
+	definitions map[string]map[string]interface{}
+}
+
+// withDescription returns the passed schema along with the description
+// associated with the passed key, if there is one.
+func (g *schemaGenerator) withDescription(schema map[string]interface{}, key string) map[string]interface{} {
+	if desc := g.xT(key); desc != "" {
+		schema["description"] = desc
+	}
+	return schema
+}
+
+// typeSchema returns the JSON schema of the passed type as it is encoded by
+// the encoding/json package.
+func (g *schemaGenerator) typeSchema(rt reflect.Type) map[string]interface{} {
+	if rt == reflect.TypeOf(json.RawMessage{}) {
+		return map[string]interface{}{}
+	}
+
+	switch rt.Kind() {
+	case reflect.Ptr:
+		return g.typeSchema(rt.Elem())
+
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16,
+		reflect.Uint32, reflect.Uint64:
+
+		return map[string]interface{}{"type": "integer"}
+
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+
+	case reflect.Array, reflect.Slice:
+		// Byte slices are encoded as base64 strings.
+		if rt.Kind() == reflect.Slice && rt.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string"}
+		}
+		return map[string]interface{}{
+			"type":  "array",
+			"items": g.typeSchema(rt.Elem()),
+		}
+
+	case reflect.Map:
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": g.typeSchema(rt.Elem()),
+		}
+
+	case reflect.Struct:
+		name := rt.Name()
+		if name == "" {
+			return g.structSchema(rt)
+		}
+		if _, ok := g.definitions[name]; !ok {
+			// Add a placeholder before generating the schema of
+			// the struct to handle self referencing types.
+			g.definitions[name] = nil
+			g.definitions[name] = g.structSchema(rt)
+		}
+		return map[string]interface{}{"$ref": "#/definitions/" + name}
+	}
+
+	// Interfaces may hold any value.
+	return map[string]interface{}{}
+}
+
+// structSchema returns the JSON schema of the object the passed struct type is
+// encoded as.  The fields of embedded structs without a JSON name are promoted
+// to the object itself the same way the encoding/json package does.  The
+// property descriptions are looked up with the same keys as the help output.
+func (g *schemaGenerator) structSchema(rt reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	required := make([]string, 0, rt.NumField())
+
+	var addFields func(rt reflect.Type)
+	addFields = func(rt reflect.Type) {
+		typeName := strings.ToLower(rt.Name())
+		for i := 0; i < rt.NumField(); i++ {
+			rtf := rt.Field(i)
+			tag := rtf.Tag.Get("json")
+			if tag == "-" {
+				continue
+			}
+			tagParts := strings.Split(tag, ",")
+
+			fieldType := rtf.Type
+			if fieldType.Kind() == reflect.Ptr {
+				fieldType = fieldType.Elem()
+			}
+			if rtf.Anonymous && tagParts[0] == "" &&
+				fieldType.Kind() == reflect.Struct {
+
+				addFields(fieldType)
+				continue
+			}
+			if rtf.PkgPath != "" {
+				continue
+			}
+
+			// The property name is the json name when it's
+			// available, otherwise the field name.
+			fieldName := tagParts[0]
+			if fieldName == "" {
+				fieldName = rtf.Name
+			}
+			descKey := typeName + "-" + strings.ToLower(fieldName)
+			properties[fieldName] = g.withDescription(
+				g.typeSchema(fieldType), descKey)
+
+			omitEmpty := false
+			for _, opt := range tagParts[1:] {
+				if opt == "omitempty" {
+					omitEmpty = true
+				}
+			}
+			if !omitEmpty {
+				required = append(required, fieldName)
+			}
+		}
+	}
+	addFields(rt)
+
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// paramsSchema returns the JSON schema of the positional parameters array of
+// the passed registered command type.
+func (g *schemaGenerator) paramsSchema(rtp reflect.Type, info *methodInfo, method string) map[string]interface{} {
+	// Several simplifying assumptions are made here because the
+	// RegisterCmd function has already rigorously enforced the layout.
+	rt := rtp.Elem()
+	items := make([]interface{}, 0, rt.NumField())
+	for i := 0; i < rt.NumField(); i++ {
+		rtf := rt.Field(i)
+		fieldName := strings.ToLower(rtf.Name)
+		item := g.withDescription(g.typeSchema(rtf.Type),
+			method+"-"+fieldName)
+		item["title"] = fieldName
+		if defaultVal, ok := info.defaults[i]; ok {
+			item["default"] = defaultVal.Elem().Interface()
+		}
+		items = append(items, item)
+	}
+
+	return map[string]interface{}{
+		"type":            "array",
+		"items":           items,
+		"minItems":        info.numReqParams,
+		"maxItems":        info.maxParams,
+		"additionalItems": false,
+	}
+}
+
+// resultSchema returns the JSON schema of the result of the passed method
+// given its possible result types.  A nil result type indicates the command
+// does not return a value.
+func (g *schemaGenerator) resultSchema(method string, resultTypes []interface{}) map[string]interface{} {
+	if len(resultTypes) == 0 {
+		return map[string]interface{}{"type": "null"}
+	}
+
+	schemas := make([]interface{}, 0, len(resultTypes))
+	for i, resultType := range resultTypes {
+		var schema map[string]interface{}
+		if resultType == nil {
+			schema = map[string]interface{}{"type": "null"}
+		} else {
+			schema = g.typeSchema(reflect.TypeOf(resultType))
+		}
+		if len(resultTypes) > 1 {
+			key := fmt.Sprintf("%s--condition%d", method, i)
+			schema = g.withDescription(schema, key)
+		}
+		schemas = append(schemas, schema)
+	}
+	if len(schemas) == 1 {
+		return schemas[0].(map[string]interface{})
+	}
+	return map[string]interface{}{"oneOf": schemas}
+}
+
+// GenerateSchema generates and returns a machine-readable description of every
+// registered command in the form of JSON schemas, which allows bindings for
+// other languages to be generated.
+//
+// The resultTypes map specifies the result types of each method in the same
+// form as the resultTypes passed to GenerateHelp.  Methods which have no entry
+// in the map are described without a result schema.  The descriptions map is
+// used to add descriptions to the schemas using the same keys as GenerateHelp,
+// however, unlike GenerateHelp, missing descriptions are simply omitted.
+func GenerateSchema(descs map[string]string, resultTypes map[string][]interface{}) (*Schema, error) {
+	// Validate each result type is a pointer to a supported type (or nil).
+	for method, types := range resultTypes {
+		for i, resultType := range types {
+			if resultType == nil {
+				continue
+			}
+
+			rtp := reflect.TypeOf(resultType)
+			if rtp.Kind() != reflect.Ptr {
+				str := fmt.Sprintf("result #%d of %q (%v) is "+
+					"not a pointer", i, method, rtp.Kind())
+				return nil, makeError(ErrInvalidType, str)
+			}
+
+			elemKind := rtp.Elem().Kind()
+			if !isValidResultType(elemKind) {
+				str := fmt.Sprintf("result #%d of %q (%v) is "+
+					"not an allowed type", i, method,
+					elemKind)
+				return nil, makeError(ErrInvalidType, str)
+			}
+		}
+	}
+
+	g := schemaGenerator{
+		xT: func(key string) string {
+			if desc, ok := descs[key]; ok {
+				return desc
+			}
+			return ""
+		},
+		definitions: make(map[string]map[string]interface{}),
+	}
+
+	schema := &Schema{
+		Schema:      SchemaVersion,
+		Methods:     make(map[string]MethodSchema),
+		Definitions: g.definitions,
+	}
+	for _, method := range RegisteredCmdMethods() {
+		registerLock.RLock()
+		rtp := methodToConcreteType[method]
+		info := methodToInfo[method]
+		registerLock.RUnlock()
+
+		methodSchema := MethodSchema{
+			Description:   g.xT(method + "--synopsis"),
+			WalletOnly:    info.flags&UFWalletOnly != 0,
+			WebsocketOnly: info.flags&UFWebsocketOnly != 0,
+			Notification:  info.flags&UFNotification != 0,
+			Params:        g.paramsSchema(rtp, &info, method),
+		}
+		if types, ok := resultTypes[method]; ok {
+			methodSchema.Result = g.resultSchema(method, types)
+		}
+		schema.Methods[method] = methodSchema
+	}
+
+	return schema, nil
+}
@@ -0,0 +1,182 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package hcjson_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/HcashOrg/hcd/hcjson"
+)
+
+// TestGenerateSchema ensures GenerateSchema describes the parameters and
+// results of registered commands as expected.
+func TestGenerateSchema(t *testing.T) {
+	t.Parallel()
+
+	descs := map[string]string{
+		"getbestblock--synopsis":   "Best block synopsis",
+		"getbestblockresult-hash":  "Best block hash",
+		"getblock-verbose":         "Verbose description",
+		"getblock--condition0":     "verbose=false",
+		"getblock--condition1":     "verbose=true",
+		"getblockheader--synopsis": "unused",
+	}
+	resultTypes := map[string][]interface{}{
+		"getbestblock": {(*hcjson.GetBestBlockResult)(nil)},
+		"getblock": {(*string)(nil),
+			(*hcjson.GetBlockVerboseResult)(nil)},
+		"addnode": nil,
+	}
+	schema, err := hcjson.GenerateSchema(descs, resultTypes)
+	if err != nil {
+		t.Fatalf("GenerateSchema: unexpected error: %v", err)
+	}
+
+	// Compare the schemas in their JSON encoded form since that is how
+	// they are consumed.
+	toJSON := func(v interface{}) string {
+		b, err := json.Marshal(v)
+		if err != nil {
+			t.Fatalf("failed to marshal %v: %v", v, err)
+		}
+		return string(b)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		want   string
+	}{
+		{
+			name:   "no params with object result",
+			method: "getbestblock",
+			want: `{"description":"Best block synopsis","params":` +
+				`{"additionalItems":false,"items":[],"maxItems":0,` +
+				`"minItems":0,"type":"array"},"result":{"$ref":` +
+				`"#/definitions/GetBestBlockResult"}}`,
+		},
+		{
+			name:   "optional params with multiple results",
+			method: "getblock",
+			want: `{"params":{"additionalItems":false,"items":[` +
+				`{"title":"hash","type":"string"},` +
+				`{"default":true,"description":"Verbose ` +
+				`description","title":"verbose","type":"boolean"},` +
+				`{"default":false,"title":"verbosetx",` +
+				`"type":"boolean"}],"maxItems":3,"minItems":1,` +
+				`"type":"array"},"result":{"oneOf":[{"description":` +
+				`"verbose=false","type":"string"},{"$ref":` +
+				`"#/definitions/GetBlockVerboseResult",` +
+				`"description":"verbose=true"}]}}`,
+		},
+		{
+			name:   "no result",
+			method: "addnode",
+			want: `{"params":{"additionalItems":false,"items":[` +
+				`{"title":"addr","type":"string"},` +
+				`{"title":"subcmd","type":"string"}],"maxItems":2,` +
+				`"minItems":2,"type":"array"},"result":` +
+				`{"type":"null"}}`,
+		},
+		{
+			name:   "unknown result",
+			method: "getblockcount",
+			want: `{"params":{"additionalItems":false,"items":[],` +
+				`"maxItems":0,"minItems":0,"type":"array"}}`,
+		},
+		{
+			name:   "websocket notification",
+			method: "blockconnected",
+			want: `{"websocketonly":true,"notification":true,` +
+				`"params":{"additionalItems":false,"items":[` +
+				`{"title":"header","type":"string"},` +
+				`{"items":{"type":"string"},"title":` +
+				`"subscribedtxs","type":"array"}],"maxItems":2,` +
+				`"minItems":2,"type":"array"}}`,
+		},
+	}
+
+	for i, test := range tests {
+		methodSchema, ok := schema.Methods[test.method]
+		if !ok {
+			t.Errorf("Test #%d (%s) method %q is missing", i,
+				test.name, test.method)
+			continue
+		}
+		if got := toJSON(methodSchema); got != test.want {
+			t.Errorf("Test #%d (%s) mismatched schema - got %s, "+
+				"want %s", i, test.name, got, test.want)
+		}
+	}
+
+	// Ensure the referenced definitions describe the fields of the result
+	// types along with which of them are always present.
+	got := toJSON(schema.Definitions["GetBestBlockResult"])
+	want := `{"properties":{"hash":{"description":"Best block hash",` +
+		`"type":"string"},"height":{"type":"integer"}},"required":` +
+		`["hash","height"],"type":"object"}`
+	if got != want {
+		t.Errorf("mismatched GetBestBlockResult definition - got %s, "+
+			"want %s", got, want)
+	}
+	verbose := schema.Definitions["GetBlockVerboseResult"]
+	required := verbose["required"].([]string)
+	for _, field := range required {
+		if field == "rawtx" {
+			t.Errorf("omitempty field rawtx is marked required")
+		}
+	}
+	if _, ok := verbose["properties"].(map[string]interface{})["rawtx"]; !ok {
+		t.Errorf("GetBlockVerboseResult definition is missing rawtx")
+	}
+	if !reflect.DeepEqual(schema.Schema, hcjson.SchemaVersion) {
+		t.Errorf("mismatched schema version - got %s, want %s",
+			schema.Schema, hcjson.SchemaVersion)
+	}
+}
+
+// TestGenerateSchemaErrors ensures GenerateSchema returns the expected errors
+// for invalid result types.
+func TestGenerateSchemaErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		resultTypes map[string][]interface{}
+		err         hcjson.Error
+	}{
+		{
+			name: "result type not a pointer",
+			resultTypes: map[string][]interface{}{
+				"getblockcount": {int64(0)},
+			},
+			err: hcjson.Error{Code: hcjson.ErrInvalidType},
+		},
+		{
+			name: "result type is an invalid kind",
+			resultTypes: map[string][]interface{}{
+				"getblockcount": {(*complex64)(nil)},
+			},
+			err: hcjson.Error{Code: hcjson.ErrInvalidType},
+		},
+	}
+
+	for i, test := range tests {
+		_, err := hcjson.GenerateSchema(nil, test.resultTypes)
+		if reflect.TypeOf(err) != reflect.TypeOf(test.err) {
+			t.Errorf("Test #%d (%s) wrong error - got %T (%v), "+
+				"want %T", i, test.name, err, err, test.err)
+			continue
+		}
+		gotErrorCode := err.(hcjson.Error).Code
+		if gotErrorCode != test.err.Code {
+			t.Errorf("Test #%d (%s) mismatched error code - got "+
+				"%v (%v), want %v", i, test.name, gotErrorCode,
+				err, test.err.Code)
+		}
+	}
+}
@@ -29,21 +29,6 @@ func NewAddMultisigAddressCmd(nRequired int, keys []string, account *string) *Ad
 	}
 }
 
-// CreateMultisigCmd defines the createmultisig JSON-RPC command.
-type CreateMultisigCmd struct {
-	NRequired int
-	Keys      []string
-}
-
-// NewCreateMultisigCmd returns a new instance which can be used to issue a
-// createmultisig JSON-RPC command.
-func NewCreateMultisigCmd(nRequired int, keys []string) *CreateMultisigCmd {
-	return &CreateMultisigCmd{
-		NRequired: nRequired,
-		Keys:      keys,
-	}
-}
-
 // DumpPrivKeyCmd defines the dumpprivkey JSON-RPC command.
 type DumpPrivKeyCmd struct {
 	Address string
@@ -611,7 +596,6 @@ func init() {
 	flags := UFWalletOnly
 
 	MustRegisterCmd("addmultisigaddress", (*AddMultisigAddressCmd)(nil), flags)
-	MustRegisterCmd("createmultisig", (*CreateMultisigCmd)(nil), flags)
 	MustRegisterCmd("dumpprivkey", (*DumpPrivKeyCmd)(nil), flags)
 	MustRegisterCmd("estimatepriority", (*EstimatePriorityCmd)(nil), flags)
 	MustRegisterCmd("getaccount", (*GetAccountCmd)(nil), flags)
@@ -63,21 +63,6 @@ func TestWalletSvrCmds(t *testing.T) {
 				Account:   hcjson.String("test"),
 			},
 		},
-		{
-			name: "createmultisig",
-			newCmd: func() (interface{}, error) {
-				return hcjson.NewCmd("createmultisig", 2, []string{"031234", "035678"})
-			},
-			staticCmd: func() interface{} {
-				keys := []string{"031234", "035678"}
-				return hcjson.NewCreateMultisigCmd(2, keys)
-			},
-			marshalled: `{"jsonrpc":"1.0","method":"createmultisig","params":[2,["031234","035678"]],"id":1}`,
-			unmarshalled: &hcjson.CreateMultisigCmd{
-				NRequired: 2,
-				Keys:      []string{"031234", "035678"},
-			},
-		},
 		{
 			name: "dumpprivkey",
 			newCmd: func() (interface{}, error) {
@@ -167,7 +152,7 @@ func TestWalletSvrCmds(t *testing.T) {
 			marshalled: `{"jsonrpc":"1.0","method":"getbalance","params":[],"id":1}`,
 			unmarshalled: &hcjson.GetBalanceCmd{
 				Account: nil,
-				MinConf: hcjson.Int(1),
+				MinConf: hcjson.Int(2),
 			},
 		},
 		{
@@ -181,7 +166,7 @@ func TestWalletSvrCmds(t *testing.T) {
 			marshalled: `{"jsonrpc":"1.0","method":"getbalance","params":["acct"],"id":1}`,
 			unmarshalled: &hcjson.GetBalanceCmd{
 				Account: hcjson.String("acct"),
-				MinConf: hcjson.Int(1),
+				MinConf: hcjson.Int(2),
 			},
 		},
 		{
@@ -263,7 +248,7 @@ func TestWalletSvrCmds(t *testing.T) {
 			marshalled: `{"jsonrpc":"1.0","method":"getreceivedbyaccount","params":["acct"],"id":1}`,
 			unmarshalled: &hcjson.GetReceivedByAccountCmd{
 				Account: "acct",
-				MinConf: hcjson.Int(1),
+				MinConf: hcjson.Int(2),
 			},
 		},
 		{
@@ -291,7 +276,7 @@ func TestWalletSvrCmds(t *testing.T) {
 			marshalled: `{"jsonrpc":"1.0","method":"getreceivedbyaddress","params":["1Address"],"id":1}`,
 			unmarshalled: &hcjson.GetReceivedByAddressCmd{
 				Address: "1Address",
-				MinConf: hcjson.Int(1),
+				MinConf: hcjson.Int(2),
 			},
 		},
 		{
@@ -433,7 +418,7 @@ func TestWalletSvrCmds(t *testing.T) {
 			},
 			marshalled: `{"jsonrpc":"1.0","method":"listaccounts","params":[],"id":1}`,
 			unmarshalled: &hcjson.ListAccountsCmd{
-				MinConf: hcjson.Int(1),
+				MinConf: hcjson.Int(2),
 			},
 		},
 		{
@@ -470,7 +455,7 @@ func TestWalletSvrCmds(t *testing.T) {
 			},
 			marshalled: `{"jsonrpc":"1.0","method":"listreceivedbyaccount","params":[],"id":1}`,
 			unmarshalled: &hcjson.ListReceivedByAccountCmd{
-				MinConf:          hcjson.Int(1),
+				MinConf:          hcjson.Int(2),
 				IncludeEmpty:     hcjson.Bool(false),
 				IncludeWatchOnly: hcjson.Bool(false),
 			},
@@ -530,7 +515,7 @@ func TestWalletSvrCmds(t *testing.T) {
 			},
 			marshalled: `{"jsonrpc":"1.0","method":"listreceivedbyaddress","params":[],"id":1}`,
 			unmarshalled: &hcjson.ListReceivedByAddressCmd{
-				MinConf:          hcjson.Int(1),
+				MinConf:          hcjson.Int(2),
 				IncludeEmpty:     hcjson.Bool(false),
 				IncludeWatchOnly: hcjson.Bool(false),
 			},
@@ -732,7 +717,7 @@ func TestWalletSvrCmds(t *testing.T) {
 			},
 			marshalled: `{"jsonrpc":"1.0","method":"listunspent","params":[],"id":1}`,
 			unmarshalled: &hcjson.ListUnspentCmd{
-				MinConf:   hcjson.Int(1),
+				MinConf:   hcjson.Int(2),
 				MaxConf:   hcjson.Int(9999999),
 				Addresses: nil,
 			},
@@ -815,7 +800,7 @@ func TestWalletSvrCmds(t *testing.T) {
 				FromAccount: "from",
 				ToAddress:   "1Address",
 				Amount:      0.5,
-				MinConf:     hcjson.Int(1),
+				MinConf:     hcjson.Int(2),
 				Comment:     nil,
 				CommentTo:   nil,
 			},
@@ -889,7 +874,7 @@ func TestWalletSvrCmds(t *testing.T) {
 			unmarshalled: &hcjson.SendManyCmd{
 				FromAccount: "from",
 				Amounts:     map[string]float64{"1Address": 0.5},
-				MinConf:     hcjson.Int(1),
+				MinConf:     hcjson.Int(2),
 				Comment:     nil,
 			},
 		},
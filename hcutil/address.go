@@ -199,8 +199,54 @@ func DecodeAddress(addr string) (Address, error) {
 		return NewAddressScriptHashFromHash(decoded, net)
 
 	default:
+		return decodeHashFuncAddress(decoded, netID, net)
+	}
+}
+
+// decodeHashFuncAddress returns the pay-to-pubkey-hash or pay-to-script-hash
+// address for the passed decoded hash when netID is one of the address
+// identifiers the network defines for an alternative hash function.
+func decodeHashFuncAddress(decoded []byte, netID [2]byte,
+	net *chaincfg.Params) (Address, error) {
+
+	params := net.HashFuncAddrParamsByID(netID)
+	if params == nil || !HashFunc(params.HashFunc).IsRegistered() {
 		return nil, ErrUnknownAddressType
 	}
+
+	if netID == params.ScriptHashAddrID {
+		ash, err := newAddressScriptHashFromHash(decoded, netID)
+		if err != nil {
+			return nil, err
+		}
+		ash.net = net
+		ash.hashFunc = HashFunc(params.HashFunc)
+		return ash, nil
+	}
+
+	apkh, err := newAddressPubKeyHash(decoded, netID)
+	if err != nil {
+		return nil, err
+	}
+	apkh.net = net
+	apkh.hashFunc = HashFunc(params.HashFunc)
+	return apkh, nil
+}
+
+// hashFuncAddrParams returns the address parameters the passed network
+// defines for the alternative hash function.
+func hashFuncAddrParams(net *chaincfg.Params,
+	hashFunc HashFunc) (*chaincfg.HashFuncAddrParams, error) {
+
+	if !hashFunc.IsRegistered() {
+		return nil, ErrUnknownHashFunc
+	}
+	params := net.HashFuncAddrParamsByHashFunc(uint8(hashFunc))
+	if params == nil {
+		return nil, fmt.Errorf("network %s does not define addresses "+
+			"for hash function %v", net.Name, hashFunc)
+	}
+	return params, nil
 }
 
 // detectNetworkForAddress pops the first character from a string encoded
@@ -226,9 +272,10 @@ func detectNetworkForAddress(addr string) (*chaincfg.Params, error) {
 // AddressPubKeyHash is an Address for a pay-to-pubkey-hash (P2PKH)
 // transaction.
 type AddressPubKeyHash struct {
-	net   *chaincfg.Params
-	hash  [ripemd160.Size]byte
-	netID [2]byte
+	net      *chaincfg.Params
+	hash     [ripemd160.Size]byte
+	netID    [2]byte
+	hashFunc HashFunc
 }
 
 // NewAddressPubKeyHash returns a new AddressPubKeyHash.  pkHash must
@@ -256,6 +303,30 @@ func NewAddressPubKeyHash(pkHash []byte, net *chaincfg.Params,
 	return apkh, nil
 }
 
+// NewAddressPubKeyHashWithHashFunc returns a new secp256k1 AddressPubKeyHash
+// for a pubkey hash created with the passed hash function.  pkHash must be 20
+// bytes.  Addresses for alternative hash functions use the address identifier
+// the network defines for the hash function.
+func NewAddressPubKeyHashWithHashFunc(pkHash []byte, net *chaincfg.Params,
+	hashFunc HashFunc) (*AddressPubKeyHash, error) {
+
+	if hashFunc == HashFuncBlake256Ripemd160 {
+		return NewAddressPubKeyHash(pkHash, net, chainec.ECTypeSecp256k1)
+	}
+
+	params, err := hashFuncAddrParams(net, hashFunc)
+	if err != nil {
+		return nil, err
+	}
+	apkh, err := newAddressPubKeyHash(pkHash, params.PubKeyHashAddrID)
+	if err != nil {
+		return nil, err
+	}
+	apkh.net = net
+	apkh.hashFunc = hashFunc
+	return apkh, nil
+}
+
 // newAddressPubKeyHash is the internal API to create a pubkey hash address
 // with a known leading identifier byte for a network, rather than looking
 // it up through its parameters.  This is useful when creating a new address
@@ -287,6 +358,10 @@ func (a *AddressPubKeyHash) ScriptAddress() []byte {
 // IsForNet returns whether or not the pay-to-pubkey-hash address is associated
 // with the passed network.
 func (a *AddressPubKeyHash) IsForNet(net *chaincfg.Params) bool {
+	if a.hashFunc != HashFuncBlake256Ripemd160 {
+		params := net.HashFuncAddrParamsByHashFunc(uint8(a.hashFunc))
+		return params != nil && a.netID == params.PubKeyHashAddrID
+	}
 	return a.netID == net.PubKeyHashAddrID ||
 		a.netID == net.PKHEdwardsAddrID ||
 		a.netID == net.PKHSchnorrAddrID ||
@@ -310,6 +385,12 @@ func (a *AddressPubKeyHash) Hash160() *[ripemd160.Size]byte {
 // DSA returns the digital signature algorithm for the associated public key
 // hash.
 func (a *AddressPubKeyHash) DSA(net *chaincfg.Params) int {
+	// Addresses for alternative hash functions are only defined for
+	// secp256k1 public keys.
+	if a.hashFunc != HashFuncBlake256Ripemd160 {
+		return chainec.ECTypeSecp256k1
+	}
+
 	switch a.netID {
 	case net.PubKeyHashAddrID:
 		return chainec.ECTypeSecp256k1
@@ -328,12 +409,18 @@ func (a *AddressPubKeyHash) Net() *chaincfg.Params {
 	return a.net
 }
 
+// HashFunc returns the hash function the pubkey hash was created with.
+func (a *AddressPubKeyHash) HashFunc() HashFunc {
+	return a.hashFunc
+}
+
 // AddressScriptHash is an Address for a pay-to-script-hash (P2SH)
 // transaction.
 type AddressScriptHash struct {
-	net   *chaincfg.Params
-	hash  [ripemd160.Size]byte
-	netID [2]byte
+	net      *chaincfg.Params
+	hash     [ripemd160.Size]byte
+	netID    [2]byte
+	hashFunc HashFunc
 }
 
 // NewAddressScriptHash returns a new AddressScriptHash.
@@ -362,6 +449,35 @@ func NewAddressScriptHashFromHash(scriptHash []byte,
 	return ash, nil
 }
 
+// NewAddressScriptHashWithHashFunc returns a new AddressScriptHash for the
+// passed script hashed with the passed hash function.  Addresses for
+// alternative hash functions use the address identifier the network defines
+// for the hash function.
+func NewAddressScriptHashWithHashFunc(serializedScript []byte,
+	net *chaincfg.Params, hashFunc HashFunc) (*AddressScriptHash, error) {
+
+	if hashFunc == HashFuncBlake256Ripemd160 {
+		return NewAddressScriptHash(serializedScript, net)
+	}
+
+	params, err := hashFuncAddrParams(net, hashFunc)
+	if err != nil {
+		return nil, err
+	}
+	scriptHash, err := hashFunc.Hash(serializedScript)
+	if err != nil {
+		return nil, err
+	}
+	ash, err := newAddressScriptHashFromHash(scriptHash,
+		params.ScriptHashAddrID)
+	if err != nil {
+		return nil, err
+	}
+	ash.net = net
+	ash.hashFunc = hashFunc
+	return ash, nil
+}
+
 // newAddressScriptHashFromHash is the internal API to create a script hash
 // address with a known leading identifier byte for a network, rather than
 // looking it up through its parameters.  This is useful when creating a new
@@ -394,6 +510,10 @@ func (a *AddressScriptHash) ScriptAddress() []byte {
 // IsForNet returns whether or not the pay-to-script-hash address is associated
 // with the passed network.
 func (a *AddressScriptHash) IsForNet(net *chaincfg.Params) bool {
+	if a.hashFunc != HashFuncBlake256Ripemd160 {
+		params := net.HashFuncAddrParamsByHashFunc(uint8(a.hashFunc))
+		return params != nil && a.netID == params.ScriptHashAddrID
+	}
 	return a.netID == net.ScriptHashAddrID
 }
 
@@ -422,6 +542,11 @@ func (a *AddressScriptHash) Net() *chaincfg.Params {
 	return a.net
 }
 
+// HashFunc returns the hash function the script hash was created with.
+func (a *AddressScriptHash) HashFunc() HashFunc {
+	return a.hashFunc
+}
+
 // PubKeyFormat describes what format to use for a pay-to-pubkey address.
 type PubKeyFormat int
 
@@ -37,8 +37,8 @@ func TestAddresses(t *testing.T) {
 		// Positive P2PKH tests.
 		{
 			name:    "mainnet p2pkh",
-			addr:    "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu",
-			encoded: "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu",
+			addr:    "HsFFQ7LyFQosoGryqmEiUFgKkSGAq5FC8hm",
+			encoded: "HsFFQ7LyFQosoGryqmEiUFgKkSGAq5FC8hm",
 			valid:   true,
 			result: hcutil.TstAddressPubKeyHash(
 				[ripemd160.Size]byte{
@@ -57,8 +57,8 @@ func TestAddresses(t *testing.T) {
 		},
 		{
 			name:    "mainnet p2pkh 2",
-			addr:    "DsU7xcg53nxaKLLcAUSKyRndjG78Z2VZnX9",
-			encoded: "DsU7xcg53nxaKLLcAUSKyRndjG78Z2VZnX9",
+			addr:    "HsEoPmDkzksUzPzv4a6BYk1QsnfXtbnRUb3",
+			encoded: "HsEoPmDkzksUzPzv4a6BYk1QsnfXtbnRUb3",
 			valid:   true,
 			result: hcutil.TstAddressPubKeyHash(
 				[ripemd160.Size]byte{
@@ -122,8 +122,8 @@ func TestAddresses(t *testing.T) {
 			// output: 3c9018e8d5615c306d72397f8f5eef44308c98fb576a88e030c25456b4f3a7ac
 			// input:  837dea37ddc8b1e3ce646f1a656e79bbd8cc7f558ac56a169626d649ebe2a3ba.
 			name:    "mainnet p2sh",
-			addr:    "DcuQKx8BES9wU7C6Q5VmLBjw436r27hayjS",
-			encoded: "DcuQKx8BES9wU7C6Q5VmLBjw436r27hayjS",
+			addr:    "Hcg5m6fsBQ4r9ArQJB9cuVxiCZfFMbXWyPi",
+			encoded: "Hcg5m6fsBQ4r9ArQJB9cuVxiCZfFMbXWyPi",
 			valid:   true,
 			result: hcutil.TstAddressScriptHash(
 				[ripemd160.Size]byte{
@@ -145,8 +145,8 @@ func TestAddresses(t *testing.T) {
 			// output: b0539a45de13b3e0403909b8bd1a555b8cbe45fd4e3f3fda76f3a5f52835c29d
 			// input: (not yet redeemed at time test was written)
 			name:    "mainnet p2sh 2",
-			addr:    "DcqgK4N4Ccucu2Sq4VDAdu4wH4LASLhzLVp",
-			encoded: "DcqgK4N4Ccucu2Sq4VDAdu4wH4LASLhzLVp",
+			addr:    "HccMkCuk9apXa678xas2DDHiRatZmquzwm5",
+			encoded: "HccMkCuk9apXa678xas2DDHiRatZmquzwm5",
 			valid:   true,
 			result: hcutil.TstAddressScriptHash(
 				[ripemd160.Size]byte{
@@ -199,8 +199,8 @@ func TestAddresses(t *testing.T) {
 		// Positive P2PK tests.
 		{
 			name:    "mainnet p2pk compressed (0x02)",
-			addr:    "DsT4FDqBKYG1Xr8aGrT1rKP3kiv6TZ5K5th",
-			encoded: "DsT4FDqBKYG1Xr8aGrT1rKP3kiv6TZ5K5th",
+			addr:    "HsDjgNNsGWAvCuntAx6sRdbpuFUVnzCYZcA",
+			encoded: "HsDjgNNsGWAvCuntAx6sRdbpuFUVnzCYZcA",
 			valid:   true,
 			result: hcutil.TstAddressPubKey(
 				[]byte{
@@ -221,8 +221,8 @@ func TestAddresses(t *testing.T) {
 		},
 		{
 			name:    "mainnet p2pk compressed (0x03)",
-			addr:    "DsfiE2y23CGwKNxSGjbfPGeEW4xw1tamZdc",
-			encoded: "DsfiE2y23CGwKNxSGjbfPGeEW4xw1tamZdc",
+			addr:    "HsSPfBWhzABqzSckAqFWxas1ebXLMMCrm3L",
+			encoded: "HsSPfBWhzABqzSckAqFWxas1ebXLMMCrm3L",
 			valid:   true,
 			result: hcutil.TstAddressPubKey(
 				[]byte{
@@ -244,8 +244,8 @@ func TestAddresses(t *testing.T) {
 		// Hybrid, uncompressed and compressed key types are supported, hcd consensus rules require a compressed key type however.
 		{
 			name:    "mainnet p2pk uncompressed (0x04)",
-			addr:    "DkM3EyZ546GghVSkvzb6J47PvGDyntqiDtFgipQhNj78Xm2mUYRpf",
-			encoded: "DsfFjaADsV8c5oHWx85ZqfxCZy74K8RFuhK",
+			addr:    "Hk14gLHMnF1Q1ToVs3FCnkfYdzebLCALcp5Cq5mFdeTM8hQFHr29N",
+			encoded: "HsRwAihupT3WkrwprDjRQzAyiVfTeawiwmX",
 			valid:   true,
 			saddr:   "0264c44653d6567eff5753c5d24a682ddc2b2cadfe1b0c6433b16374dace6778f0",
 			result: hcutil.TstAddressPubKey(
@@ -273,8 +273,8 @@ func TestAddresses(t *testing.T) {
 		},
 		{
 			name:    "mainnet p2pk hybrid (0x06)",
-			addr:    "DkM3EyZ546GghVSkvzb6J47PvGDyntqiDtFgipQhNj78Xm2mUYRpf",
-			encoded: "DsfFjaADsV8c5oHWx85ZqfxCZy74K8RFuhK",
+			addr:    "Hk14gLHMnF1Q1ToVs3FCnkfYdzebLCALcp5Cq5mFdeTM8hQFHr29N",
+			encoded: "HsRwAihupT3WkrwprDjRQzAyiVfTeawiwmX",
 			valid:   true,
 			saddr:   "0264c44653d6567eff5753c5d24a682ddc2b2cadfe1b0c6433b16374dace6778f0",
 			result: hcutil.TstAddressPubKey(
@@ -302,8 +302,8 @@ func TestAddresses(t *testing.T) {
 		},
 		{
 			name:    "mainnet p2pk hybrid (0x07)",
-			addr:    "DkRKh2aTdwjKKL1mkCb2DFp2Hr7SqMyx3zWqNwyc37PYiGpKmGRsi",
-			encoded: "DskEQZMCs4nifL7wx7iHYGWxMQvR9ThCBKQ",
+			addr:    "Hk5M8PJkN6U2dJNWgFF8hxNB1aY4NfJaSvLMVDLAJ2jmKDBmFXowD",
+			encoded: "HsWuqhttp2hdLPnFrDN97ajjVwUpUyxJuz5",
 			valid:   true,
 			saddr:   "03348d8aeb4253ca52456fe5da94ab1263bfee16bb8192497f666389ca964f8479",
 			result: hcutil.TstAddressPubKey(
@@ -577,13 +577,3 @@ func TestAddresses(t *testing.T) {
 		}
 	}
 }
-// testAddressPubKey makes an AddressPubKey, setting the unexported fields with
-// the parameters.
-func testAddressPubKey(serializedPubKey []byte, pubKeyFormat PubKeyFormat, netID [2]byte) *AddressSecpPubKey {
-	pubKey, _ := secp256k1.ParsePubKey(serializedPubKey)
-	return &AddressSecpPubKey{
-		pubKeyFormat: pubKeyFormat,
-		pubKey:       chainec.PublicKey(pubKey),
-		pubKeyHashID: netID,
-	}
-}
@@ -10,6 +10,17 @@ import (
 	"errors"
 	"math"
 	"strconv"
+	"strings"
+)
+
+var (
+	// ErrAmountOverflow describes an error where the result of an
+	// arithmetic operation on Amounts cannot be represented by an Amount.
+	ErrAmountOverflow = errors.New("amount overflow")
+
+	// ErrInvalidAmount describes an error where a string could not be
+	// parsed as an amount.
+	ErrInvalidAmount = errors.New("invalid amount")
 )
 
 // AmountUnit describes a method of converting an Amount to something
@@ -105,9 +116,38 @@ func (a Amount) ToCoin() float64 {
 // string for a given unit.  The conversion will succeed for any unit,
 // however, known units will be formated with an appended label describing
 // the units with SI notation, or "atom" for the base unit.
+//
+// Units no smaller than an atom are formatted exactly, without rounding
+// through a floating point value.
 func (a Amount) Format(u AmountUnit) string {
-	units := " " + u.String()
-	return strconv.FormatFloat(a.ToUnit(u), 'f', -int(u+8), 64) + units
+	return a.formatNumber(u) + " " + u.String()
+}
+
+// formatNumber formats the amount as a decimal number of the given unit
+// without a label.  Trailing zeros of the fraction are omitted.
+func (a Amount) formatNumber(u AmountUnit) string {
+	shift := int(u + 8)
+	if shift < 0 || shift > 18 {
+		return strconv.FormatFloat(a.ToUnit(u), 'f', -shift, 64)
+	}
+
+	// Work on the magnitude as an unsigned value so the smallest Amount
+	// is formatted correctly.
+	sign := ""
+	magnitude := uint64(a)
+	if a < 0 {
+		sign = "-"
+		magnitude = uint64(-a)
+	}
+	divisor := uint64(math.Pow10(shift))
+	integer := strconv.FormatUint(magnitude/divisor, 10)
+	fraction := magnitude % divisor
+	if fraction == 0 {
+		return sign + integer
+	}
+	digits := strconv.FormatUint(fraction, 10)
+	digits = strings.Repeat("0", shift-len(digits)) + digits
+	return sign + integer + "." + strings.TrimRight(digits, "0")
 }
 
 // String is the equivalent of calling Format with AmountCoin.
@@ -123,6 +163,189 @@ func (a Amount) MulF64(f float64) Amount {
 	return round(float64(a) * f)
 }
 
+// Add returns the sum of two Amounts, or ErrAmountOverflow if the sum
+// cannot be represented by an Amount.
+func (a Amount) Add(b Amount) (Amount, error) {
+	sum := a + b
+	if (sum > a) != (b > 0) {
+		return 0, ErrAmountOverflow
+	}
+	return sum, nil
+}
+
+// Sub returns the difference of two Amounts, or ErrAmountOverflow if the
+// difference cannot be represented by an Amount.
+func (a Amount) Sub(b Amount) (Amount, error) {
+	diff := a - b
+	if (diff < a) != (b > 0) {
+		return 0, ErrAmountOverflow
+	}
+	return diff, nil
+}
+
+// Mul returns the Amount multiplied by n, or ErrAmountOverflow if the
+// product cannot be represented by an Amount.  Unlike MulF64, the result is
+// exact.
+func (a Amount) Mul(n int64) (Amount, error) {
+	if a == 0 || n == 0 {
+		return 0, nil
+	}
+	product := a * Amount(n)
+	if product/Amount(n) != a || (a == math.MinInt64 && n == -1) {
+		return 0, ErrAmountOverflow
+	}
+	return product, nil
+}
+
+// maxAmountExponent is the largest exponent magnitude accepted in decimal
+// amounts.  It is far beyond any representable amount and only keeps the
+// scaling of the digits bounded.
+const maxAmountExponent = 1000
+
+// amountUnits maps the labels accepted by ParseAmount to their units.
+// Besides the labels returned by AmountUnit.String, the micro sign and
+// "u" are accepted for micro coins, and atoms may be written in lower case
+// and the plural.
+var amountUnits = map[string]AmountUnit{
+	"MHC":      AmountMegaCoin,
+	"kHC":      AmountKiloCoin,
+	"HC":       AmountCoin,
+	"mHC":      AmountMilliCoin,
+	"μHC":      AmountMicroCoin,
+	"\u00b5HC": AmountMicroCoin,
+	"uHC":      AmountMicroCoin,
+	"Atom":     AmountAtom,
+	"Atoms":    AmountAtom,
+	"atom":     AmountAtom,
+	"atoms":    AmountAtom,
+}
+
+// ParseAmount parses a decimal amount followed by an optional unit label,
+// such as "1.5 HC", "150 mHC" or "100 Atom", and returns the Amount it
+// represents.  Amounts without a label are in coins.  The conversion is
+// exact: ErrInvalidAmount is returned for amounts with a precision finer
+// than an atom and ErrAmountOverflow for amounts which cannot be
+// represented.
+//
+// ParseAmount accepts everything returned by Format for the units defined
+// by this package.
+func ParseAmount(s string) (Amount, error) {
+	fields := strings.Fields(s)
+	unit := AmountCoin
+	switch len(fields) {
+	case 1:
+	case 2:
+		u, ok := amountUnits[fields[1]]
+		if !ok {
+			return 0, ErrInvalidAmount
+		}
+		unit = u
+	default:
+		return 0, ErrInvalidAmount
+	}
+	return parseDecimal(fields[0], int(unit+8))
+}
+
+// parseDecimal parses a decimal number, optionally with an exponent, and
+// returns it multiplied by 10^shift as an Amount.
+func parseDecimal(s string, shift int) (Amount, error) {
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		exp, err := strconv.Atoi(s[i+1:])
+		if err != nil || exp < -maxAmountExponent || exp > maxAmountExponent {
+			return 0, ErrInvalidAmount
+		}
+		s, shift = s[:i], shift+exp
+	}
+
+	negative := false
+	switch {
+	case strings.HasPrefix(s, "-"):
+		negative = true
+		s = s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+	integer, fraction := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		integer, fraction = s[:i], s[i+1:]
+	}
+	if integer == "" && fraction == "" {
+		return 0, ErrInvalidAmount
+	}
+	digits := integer + fraction
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return 0, ErrInvalidAmount
+		}
+	}
+
+	// Scale the digits to atoms.  Digits below an atom must all be zero.
+	// Leading zeros are dropped so they do not count towards the overflow
+	// check below.
+	shift -= len(fraction)
+	if shift < 0 {
+		cut := len(digits) + shift
+		if cut < 0 {
+			cut = 0
+		}
+		if strings.TrimRight(digits[cut:], "0") != "" {
+			return 0, ErrInvalidAmount
+		}
+		digits, shift = digits[:cut], 0
+	}
+	digits = strings.TrimLeft(digits, "0")
+	if digits == "" {
+		return 0, nil
+	}
+	if len(digits)+shift > 19 {
+		return 0, ErrAmountOverflow
+	}
+	digits += strings.Repeat("0", shift)
+	magnitude, err := strconv.ParseUint(digits, 10, 64)
+	if err != nil || magnitude > math.MaxInt64+1 ||
+		(magnitude == math.MaxInt64+1 && !negative) {
+		return 0, ErrAmountOverflow
+	}
+	if negative {
+		return Amount(-magnitude), nil
+	}
+	return Amount(magnitude), nil
+}
+
+// MarshalJSON encodes the Amount as a JSON number of coins.  The number is
+// formatted exactly rather than through a floating point value, so no
+// precision is lost for any Amount.
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.formatNumber(AmountCoin)), nil
+}
+
+// UnmarshalJSON decodes a JSON number of coins into the Amount without
+// rounding through a floating point value.  A string holding an amount in
+// any of the forms accepted by ParseAmount is decoded as well.
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+
+	var amount Amount
+	var err error
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		unquoted, uerr := strconv.Unquote(s)
+		if uerr != nil {
+			return ErrInvalidAmount
+		}
+		amount, err = ParseAmount(unquoted)
+	} else {
+		amount, err = parseDecimal(s, int(AmountCoin+8))
+	}
+	if err != nil {
+		return err
+	}
+	*a = amount
+	return nil
+}
+
 // AmountSorter implements sort.Interface to allow a slice of Amounts to
 // be sorted.
 type AmountSorter []Amount
@@ -7,6 +7,7 @@
 package hcutil_test
 
 import (
+	"encoding/json"
 	"math"
 	"reflect"
 	"sort"
@@ -31,27 +32,27 @@ func TestAmountCreation(t *testing.T) {
 		},
 		{
 			name:     "max producable",
-			amount:   21e6,
+			amount:   21e7,
 			valid:    true,
 			expected: MaxAmount,
 		},
 		{
 			name:     "min producable",
-			amount:   -21e6,
+			amount:   -21e7,
 			valid:    true,
 			expected: -MaxAmount,
 		},
 		{
 			name:     "exceeds max producable",
-			amount:   21e6 + 1e-8,
+			amount:   21e7 + 1,
 			valid:    true,
-			expected: MaxAmount + 1,
+			expected: MaxAmount + AtomsPerCoin,
 		},
 		{
 			name:     "exceeds min producable",
-			amount:   -21e6 - 1e-8,
+			amount:   -21e7 - 1,
 			valid:    true,
-			expected: -MaxAmount - 1,
+			expected: -MaxAmount - AtomsPerCoin,
 		},
 		{
 			name:     "one hundred",
@@ -126,8 +127,8 @@ func TestAmountUnitConversions(t *testing.T) {
 			name:      "MHC",
 			amount:    MaxAmount,
 			unit:      AmountMegaCoin,
-			converted: 21,
-			s:         "21 MHC",
+			converted: 210,
+			s:         "210 MHC",
 		},
 		{
 			name:      "kHC",
@@ -318,6 +319,207 @@ func TestAmountMulF64(t *testing.T) {
 	}
 }
 
+func TestAmountArithmetic(t *testing.T) {
+	tests := []struct {
+		name string
+		op   func() (Amount, error)
+		res  Amount
+		err  error
+	}{
+		{
+			name: "add",
+			op:   func() (Amount, error) { return Amount(100e5).Add(200e5) },
+			res:  300e5,
+		},
+		{
+			name: "add negative",
+			op:   func() (Amount, error) { return Amount(100e5).Add(-200e5) },
+			res:  -100e5,
+		},
+		{
+			name: "add overflow",
+			op:   func() (Amount, error) { return Amount(math.MaxInt64).Add(1) },
+			err:  ErrAmountOverflow,
+		},
+		{
+			name: "add underflow",
+			op:   func() (Amount, error) { return Amount(math.MinInt64).Add(-1) },
+			err:  ErrAmountOverflow,
+		},
+		{
+			name: "sub",
+			op:   func() (Amount, error) { return Amount(100e5).Sub(200e5) },
+			res:  -100e5,
+		},
+		{
+			name: "sub to min",
+			op:   func() (Amount, error) { return Amount(-1).Sub(math.MaxInt64) },
+			res:  math.MinInt64,
+		},
+		{
+			name: "sub overflow",
+			op:   func() (Amount, error) { return Amount(0).Sub(math.MinInt64) },
+			err:  ErrAmountOverflow,
+		},
+		{
+			name: "sub underflow",
+			op:   func() (Amount, error) { return Amount(math.MinInt64).Sub(1) },
+			err:  ErrAmountOverflow,
+		},
+		{
+			name: "mul",
+			op:   func() (Amount, error) { return Amount(100e5).Mul(-3) },
+			res:  -300e5,
+		},
+		{
+			name: "mul zero",
+			op:   func() (Amount, error) { return Amount(math.MinInt64).Mul(0) },
+			res:  0,
+		},
+		{
+			name: "mul overflow",
+			op:   func() (Amount, error) { return Amount(MaxAmount).Mul(1e5) },
+			err:  ErrAmountOverflow,
+		},
+		{
+			name: "mul min by -1",
+			op:   func() (Amount, error) { return Amount(math.MinInt64).Mul(-1) },
+			err:  ErrAmountOverflow,
+		},
+		{
+			name: "mul -1 by min",
+			op:   func() (Amount, error) { return Amount(-1).Mul(math.MinInt64) },
+			err:  ErrAmountOverflow,
+		},
+	}
+
+	for _, test := range tests {
+		res, err := test.op()
+		if err != test.err {
+			t.Errorf("%s: unexpected error: got %v, want %v", test.name, err, test.err)
+			continue
+		}
+		if res != test.res {
+			t.Errorf("%s: unexpected result: got %v, want %v", test.name, res, test.res)
+		}
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		s   string
+		res Amount
+		err error
+	}{
+		{s: "1.5", res: 15e7},
+		{s: "1.5 HC", res: 15e7},
+		{s: "-0.00000001 HC", res: -1},
+		{s: "444.333222111 kHC", res: 44433322211100},
+		{s: "21 MHC", res: 21e14},
+		{s: "150 mHC", res: 15e6},
+		{s: "12 μHC", res: 1200},
+		{s: "12 \u00b5HC", res: 1200},
+		{s: "12 uHC", res: 1200},
+		{s: "100 Atom", res: 100},
+		{s: "100 atoms", res: 100},
+		{s: "1.50000000", res: 15e7},
+		{s: ".5", res: 5e7},
+		{s: "+2", res: 2e8},
+		{s: "1e-8", res: 1},
+		{s: "15e-1", res: 15e7},
+		{s: "92233720368.54775807", res: math.MaxInt64},
+		{s: "-92233720368.54775808", res: math.MinInt64},
+		{s: "0e-2000", err: ErrInvalidAmount},
+		{s: "0.000000000 HC", res: 0},
+		{s: "0.000000001", err: ErrInvalidAmount},
+		{s: "1.5 Atom", err: ErrInvalidAmount},
+		{s: "1e-9", err: ErrInvalidAmount},
+		{s: "92233720368.54775808", err: ErrAmountOverflow},
+		{s: "1e30", err: ErrAmountOverflow},
+		{s: "1 BTC", err: ErrInvalidAmount},
+		{s: "1 HC HC", err: ErrInvalidAmount},
+		{s: "", err: ErrInvalidAmount},
+		{s: ".", err: ErrInvalidAmount},
+		{s: "1.2.3", err: ErrInvalidAmount},
+		{s: "0x10", err: ErrInvalidAmount},
+	}
+
+	for _, test := range tests {
+		res, err := ParseAmount(test.s)
+		if err != test.err {
+			t.Errorf("%q: unexpected error: got %v, want %v", test.s, err, test.err)
+			continue
+		}
+		if res != test.res {
+			t.Errorf("%q: unexpected result: got %d, want %d", test.s, res, test.res)
+		}
+	}
+
+	// Every amount formatted in a known unit must parse back to itself.
+	units := []AmountUnit{AmountMegaCoin, AmountKiloCoin, AmountCoin,
+		AmountMilliCoin, AmountMicroCoin, AmountAtom}
+	amounts := []Amount{0, 1, -1, 44433322211100, MaxAmount,
+		math.MaxInt64, math.MinInt64}
+	for _, u := range units {
+		for _, a := range amounts {
+			s := a.Format(u)
+			res, err := ParseAmount(s)
+			if err != nil || res != a {
+				t.Errorf("%q: did not round trip: got %d (%v), want %d", s, res, err, a)
+			}
+		}
+	}
+}
+
+func TestAmountJSON(t *testing.T) {
+	tests := []struct {
+		amount Amount
+		json   string
+	}{
+		{0, `0`},
+		{1, `0.00000001`},
+		{-15e7, `-1.5`},
+		{math.MaxInt64, `92233720368.54775807`},
+		{math.MinInt64, `-92233720368.54775808`},
+	}
+
+	for _, test := range tests {
+		b, err := json.Marshal(test.amount)
+		if err != nil {
+			t.Errorf("%d: unexpected marshal error: %v", test.amount, err)
+			continue
+		}
+		if string(b) != test.json {
+			t.Errorf("%d: unexpected JSON: got %s, want %s", test.amount, b, test.json)
+			continue
+		}
+
+		var amount Amount
+		if err := json.Unmarshal(b, &amount); err != nil {
+			t.Errorf("%s: unexpected unmarshal error: %v", test.json, err)
+			continue
+		}
+		if amount != test.amount {
+			t.Errorf("%s: unexpected amount: got %d, want %d", test.json, amount, test.amount)
+		}
+	}
+
+	var amounts []Amount
+	err := json.Unmarshal([]byte(`[1e-8, "1.5 mHC", null]`), &amounts)
+	if err != nil {
+		t.Fatalf("unexpected unmarshal error: %v", err)
+	}
+	if !reflect.DeepEqual(amounts, []Amount{1, 15e4, 0}) {
+		t.Errorf("unexpected amounts: %v", amounts)
+	}
+	for _, s := range []string{`1e-9`, `"1 BTC"`, `true`} {
+		var amount Amount
+		if err := json.Unmarshal([]byte(s), &amount); err == nil {
+			t.Errorf("%s: expected an error", s)
+		}
+	}
+}
+
 func TestAmountSorter(t *testing.T) {
 	tests := []struct {
 		name string
@@ -11,6 +11,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"math/rand"
 	"testing"
 
 	"github.com/HcashOrg/hcd/chaincfg/chainhash"
@@ -221,6 +222,99 @@ func TestMinPrioritySelector(t *testing.T) {
 	testCoinSelector(minPriorityTests, t)
 }
 
+var largestFirstSelectors = []coinset.LargestFirstCoinSelector{
+	{MaxInputs: 10, MinChangeAmount: 10000},
+	{MaxInputs: 2, MinChangeAmount: 10000},
+}
+
+var largestFirstTests = []coinSelectTest{
+	{largestFirstSelectors[0], coins, 100000000, []coinset.Coin{coins[0]}, nil},
+	{largestFirstSelectors[0], coins, 110000000, []coinset.Coin{coins[0], coins[2]}, nil},
+	{largestFirstSelectors[0], coins, 184990000, []coinset.Coin{coins[0], coins[2], coins[3], coins[1]}, nil},
+	{largestFirstSelectors[0], coins, 184990001, nil, coinset.ErrCoinsNoSelectionAvailable},
+	{largestFirstSelectors[1], coins, 140000000, []coinset.Coin{coins[0], coins[2]}, nil},
+	{largestFirstSelectors[1], coins, 160000000, nil, coinset.ErrCoinsNoSelectionAvailable},
+}
+
+func TestLargestFirstSelector(t *testing.T) {
+	testCoinSelector(largestFirstTests, t)
+}
+
+var branchAndBoundSelectors = []coinset.BranchAndBoundCoinSelector{
+	{MaxInputs: 10},
+	{MaxInputs: 10, CostOfChange: 20000000},
+	{MaxInputs: 1},
+	{MaxInputs: 10, MaxTries: 1},
+}
+
+var branchAndBoundTests = []coinSelectTest{
+	{branchAndBoundSelectors[0], coins, 35000000, []coinset.Coin{coins[3], coins[1]}, nil},
+	{branchAndBoundSelectors[0], coins, 75000000, []coinset.Coin{coins[2], coins[3]}, nil},
+	{branchAndBoundSelectors[0], coins, 185000000, []coinset.Coin{coins[0], coins[2], coins[3], coins[1]}, nil},
+	{branchAndBoundSelectors[0], coins, 36000000, nil, coinset.ErrCoinsNoSelectionAvailable},
+	{branchAndBoundSelectors[0], coins, 200000000, nil, coinset.ErrCoinsNoSelectionAvailable},
+	{branchAndBoundSelectors[1], coins, 36000000, []coinset.Coin{coins[2]}, nil},
+	{branchAndBoundSelectors[1], coins, 81000000, []coinset.Coin{coins[2], coins[3], coins[1]}, nil},
+	{branchAndBoundSelectors[2], coins, 35000000, nil, coinset.ErrCoinsNoSelectionAvailable},
+	{branchAndBoundSelectors[2], coins, 25000000, []coinset.Coin{coins[3]}, nil},
+	{branchAndBoundSelectors[3], coins, 10000000, nil, coinset.ErrCoinsNoSelectionAvailable},
+}
+
+func TestBranchAndBoundSelector(t *testing.T) {
+	testCoinSelector(branchAndBoundTests, t)
+}
+
+func TestRandomImproveSelector(t *testing.T) {
+	equalCoins := make([]coinset.Coin, 10)
+	for i := range equalCoins {
+		equalCoins[i] = NewCoin(int64(i), 10000000, 1)
+	}
+
+	tests := []struct {
+		selector      coinset.RandomImproveCoinSelector
+		inputCoins    []coinset.Coin
+		targetValue   hcutil.Amount
+		expectedNum   int
+		expectedError error
+	}{
+		// The target is reached with three coins and improved towards
+		// twice the target with three more.
+		{coinset.RandomImproveCoinSelector{MaxInputs: 10}, equalCoins, 30000000, 6, nil},
+		// Improvement stops at the maximum number of inputs.
+		{coinset.RandomImproveCoinSelector{MaxInputs: 4}, equalCoins, 30000000, 4, nil},
+		// Improvement is limited by the available coins.
+		{coinset.RandomImproveCoinSelector{MaxInputs: 10}, equalCoins, 70000000, 10, nil},
+		// Change below the minimum is avoided by adding a coin.
+		{coinset.RandomImproveCoinSelector{MaxInputs: 10, MinChangeAmount: 10000}, equalCoins, 95000000, 10, nil},
+		{coinset.RandomImproveCoinSelector{MaxInputs: 10}, equalCoins, 100000001, 0, coinset.ErrCoinsNoSelectionAvailable},
+		{coinset.RandomImproveCoinSelector{MaxInputs: 2}, equalCoins, 30000000, 0, coinset.ErrCoinsNoSelectionAvailable},
+		{coinset.RandomImproveCoinSelector{MaxInputs: 10, MinChangeAmount: 10000}, equalCoins, 99999999, 0, coinset.ErrCoinsNoSelectionAvailable},
+	}
+
+	for i, test := range tests {
+		for seed := int64(0); seed < 10; seed++ {
+			test.selector.Rand = rand.New(rand.NewSource(seed))
+			cs, err := test.selector.CoinSelect(test.targetValue, test.inputCoins)
+			if err != test.expectedError {
+				t.Errorf("[%d] expected a different error: got=%v, expected=%v", i, err, test.expectedError)
+				break
+			}
+			if err != nil {
+				continue
+			}
+			coinSet := coinset.NewCoinSet(cs.Coins())
+			if coinSet.Num() != test.expectedNum {
+				t.Errorf("[%d] expected different number of coins: got=%d, expected=%d", i, coinSet.Num(), test.expectedNum)
+				break
+			}
+			if coinSet.TotalValue() < test.targetValue {
+				t.Errorf("[%d] targetValue not satistifed", i)
+				break
+			}
+		}
+	}
+}
+
 var (
 	// should be two outpoints, with 1st one having 1.29994545hcd value.
 	testSimpleCoinNumConfs            = int64(1)
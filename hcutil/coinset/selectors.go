@@ -0,0 +1,212 @@
+// Copyright (c) 2018-2020 The Hcd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package coinset
+
+import (
+	"math/rand"
+	"sort"
+
+	"github.com/HcashOrg/hcd/hcutil"
+)
+
+// defaultBranchAndBoundTries is the number of search steps a
+// BranchAndBoundCoinSelector takes when MaxTries is not set.
+const defaultBranchAndBoundTries = 100000
+
+// LargestFirstCoinSelector is a CoinSelector that attempts to construct a
+// selection of coins whose total value is at least targetValue by adding
+// the coins with the highest value first.
+//
+// This is the same strategy as MinNumberCoinSelector under the name most
+// wallets know it by.
+type LargestFirstCoinSelector struct {
+	MaxInputs       int
+	MinChangeAmount hcutil.Amount
+}
+
+// CoinSelect will attempt to select coins using the algorithm described
+// in the LargestFirstCoinSelector struct.
+func (s LargestFirstCoinSelector) CoinSelect(targetValue hcutil.Amount, coins []Coin) (Coins, error) {
+	return (&MinNumberCoinSelector{
+		MaxInputs:       s.MaxInputs,
+		MinChangeAmount: s.MinChangeAmount,
+	}).CoinSelect(targetValue, coins)
+}
+
+// BranchAndBoundCoinSelector is a CoinSelector that searches for a
+// selection of coins whose total value is at least targetValue and at most
+// targetValue plus CostOfChange, so that the transaction needs no change
+// output.  The excess over the target, if any, is given up to fees.
+//
+// Of the selections found, the one with the least excess is returned.  The
+// search is depth-first over the coins ordered by descending value and
+// stops at the first exact match or after MaxTries steps, which defaults to
+// 100000 when unset.  ErrCoinsNoSelectionAvailable is returned when no
+// changeless selection was found, in which case callers will typically fall
+// back to another CoinSelector.
+type BranchAndBoundCoinSelector struct {
+	MaxInputs    int
+	CostOfChange hcutil.Amount
+	MaxTries     int
+}
+
+// CoinSelect will attempt to select coins using the algorithm described
+// in the BranchAndBoundCoinSelector struct.
+func (s BranchAndBoundCoinSelector) CoinSelect(targetValue hcutil.Amount, coins []Coin) (Coins, error) {
+	sortedCoins := make([]Coin, 0, len(coins))
+	var remaining hcutil.Amount
+	for _, coin := range coins {
+		// Coins without value can never improve a selection.
+		if coin.Value() <= 0 {
+			continue
+		}
+		sortedCoins = append(sortedCoins, coin)
+		remaining += coin.Value()
+	}
+	sort.Sort(sort.Reverse(byAmount(sortedCoins)))
+
+	search := &branchAndBound{
+		coins:     sortedCoins,
+		target:    targetValue,
+		upper:     targetValue + s.CostOfChange,
+		maxInputs: s.MaxInputs,
+		tries:     s.MaxTries,
+	}
+	if search.tries <= 0 {
+		search.tries = defaultBranchAndBoundTries
+	}
+	search.search(0, 0, remaining)
+	if search.best == nil {
+		return nil, ErrCoinsNoSelectionAvailable
+	}
+
+	cs := NewCoinSet(nil)
+	for _, i := range search.best {
+		cs.PushCoin(sortedCoins[i])
+	}
+	return cs, nil
+}
+
+// branchAndBound holds the state of a single BranchAndBoundCoinSelector
+// search.  Selections are recorded as indexes into coins.
+type branchAndBound struct {
+	coins     []Coin
+	target    hcutil.Amount
+	upper     hcutil.Amount
+	maxInputs int
+	tries     int
+
+	selected  []int
+	best      []int
+	bestTotal hcutil.Amount
+}
+
+// search explores the selections which extend the current one with coins
+// from index i onwards, given the total value of the current selection and
+// the total value of the coins not yet considered.  It returns true when the
+// search should stop, either because an exact match was found or because
+// the allowed number of tries is exhausted.
+func (b *branchAndBound) search(i int, total, remaining hcutil.Amount) bool {
+	if b.tries <= 0 {
+		return true
+	}
+	b.tries--
+
+	if total > b.upper {
+		return false
+	}
+	if total >= b.target {
+		if b.best == nil || total < b.bestTotal {
+			b.best = append(b.best[:0], b.selected...)
+			b.bestTotal = total
+		}
+		return total == b.target
+	}
+	if i == len(b.coins) || total+remaining < b.target ||
+		len(b.selected) >= b.maxInputs {
+		return false
+	}
+
+	// Try the selection including the coin first.
+	value := b.coins[i].Value()
+	remaining -= value
+	b.selected = append(b.selected, i)
+	if b.search(i+1, total+value, remaining) {
+		return true
+	}
+	b.selected = b.selected[:len(b.selected)-1]
+
+	// Excluding the coin and then including another of the same value
+	// would only repeat the branch above, so skip all of them.
+	next := i + 1
+	for next < len(b.coins) && b.coins[next].Value() == value {
+		remaining -= value
+		next++
+	}
+	return b.search(next, total, remaining)
+}
+
+// RandomImproveCoinSelector is a CoinSelector that attempts to construct a
+// selection of coins whose total value is at least targetValue by adding
+// coins in random order, then keeps adding random coins for as long as they
+// bring the total closer to twice targetValue without exceeding three times
+// it.
+//
+// Aiming for change of about the size of the payment keeps the wallet's
+// coins from fragmenting into dust over time and makes the change output
+// harder to tell apart from the payment.  Rand is used to order the coins
+// when set, otherwise the default source of math/rand is used.
+type RandomImproveCoinSelector struct {
+	MaxInputs       int
+	MinChangeAmount hcutil.Amount
+	Rand            *rand.Rand
+}
+
+// CoinSelect will attempt to select coins using the algorithm described
+// in the RandomImproveCoinSelector struct.
+func (s RandomImproveCoinSelector) CoinSelect(targetValue hcutil.Amount, coins []Coin) (Coins, error) {
+	var order []int
+	if s.Rand != nil {
+		order = s.Rand.Perm(len(coins))
+	} else {
+		order = rand.Perm(len(coins))
+	}
+
+	// Select random coins until the target is satisfied.
+	cs := NewCoinSet(nil)
+	n := 0
+	for ; n < len(order); n++ {
+		if satisfiesTargetValue(targetValue, s.MinChangeAmount, cs.TotalValue()) ||
+			cs.Num() >= s.MaxInputs {
+			break
+		}
+		cs.PushCoin(coins[order[n]])
+	}
+	if !satisfiesTargetValue(targetValue, s.MinChangeAmount, cs.TotalValue()) {
+		return nil, ErrCoinsNoSelectionAvailable
+	}
+
+	// Improve the selection with the remaining coins.
+	ideal, limit := 2*targetValue, 3*targetValue
+	for ; n < len(order) && cs.Num() < s.MaxInputs; n++ {
+		coin := coins[order[n]]
+		total := cs.TotalValue() + coin.Value()
+		if total > limit ||
+			absAmount(ideal-total) >= absAmount(ideal-cs.TotalValue()) ||
+			!satisfiesTargetValue(targetValue, s.MinChangeAmount, total) {
+			continue
+		}
+		cs.PushCoin(coin)
+	}
+	return cs, nil
+}
+
+// absAmount returns the absolute value of the passed amount.
+func absAmount(a hcutil.Amount) hcutil.Amount {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
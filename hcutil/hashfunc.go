@@ -0,0 +1,92 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package hcutil
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/ripemd160"
+)
+
+// HashFunc identifies a hash function used to create the 20-byte hashes of
+// public keys and scripts committed to by pay-to-pubkey-hash and
+// pay-to-script-hash addresses.
+type HashFunc uint8
+
+const (
+	// HashFuncBlake256Ripemd160 identifies the default hash function,
+	// ripemd160(blake256(b)), which is used by all addresses that do not
+	// have an address version for an alternative hash function.
+	HashFuncBlake256Ripemd160 HashFunc = 0
+)
+
+var (
+	// ErrDuplicateHashFunc describes an error where a hash function could
+	// not be registered due to its identifier already being registered.
+	ErrDuplicateHashFunc = errors.New("duplicate hash function")
+
+	// ErrUnknownHashFunc describes an error where a hash function is not
+	// registered.
+	ErrUnknownHashFunc = errors.New("unknown hash function")
+)
+
+// hashFuncInfo houses the name and implementation of a registered hash
+// function.
+type hashFuncInfo struct {
+	name string
+	hash func([]byte) []byte
+}
+
+// registeredHashFuncs houses the registered hash functions keyed by their
+// identifier.
+var registeredHashFuncs = map[HashFunc]hashFuncInfo{
+	HashFuncBlake256Ripemd160: {"blake256-ripemd160", Hash160},
+}
+
+// RegisterHashFunc registers an alternative hash function which may be used by
+// address versions defined in the HashFuncAddrs of the network parameters.
+// The passed function must return 20-byte hashes.  This may error with
+// ErrDuplicateHashFunc if the identifier is already registered.
+//
+// Hash functions should be registered into this package by a main package as
+// early as possible since the registry is not safe for concurrent access.
+func RegisterHashFunc(id HashFunc, name string, hash func([]byte) []byte) error {
+	if _, ok := registeredHashFuncs[id]; ok {
+		return ErrDuplicateHashFunc
+	}
+	registeredHashFuncs[id] = hashFuncInfo{name: name, hash: hash}
+	return nil
+}
+
+// IsRegistered returns whether or not the hash function is registered.
+func (h HashFunc) IsRegistered() bool {
+	_, ok := registeredHashFuncs[h]
+	return ok
+}
+
+// Hash returns the 20-byte hash of the passed data created with the hash
+// function.  ErrUnknownHashFunc is returned when the hash function is not
+// registered.
+func (h HashFunc) Hash(b []byte) ([]byte, error) {
+	info, ok := registeredHashFuncs[h]
+	if !ok {
+		return nil, ErrUnknownHashFunc
+	}
+	hash := info.hash(b)
+	if len(hash) != ripemd160.Size {
+		return nil, fmt.Errorf("hash function %v returned a %d byte "+
+			"hash instead of %d bytes", h, len(hash), ripemd160.Size)
+	}
+	return hash, nil
+}
+
+// String returns the name of the hash function in human-readable form.
+func (h HashFunc) String() string {
+	if info, ok := registeredHashFuncs[h]; ok {
+		return info.name
+	}
+	return fmt.Sprintf("Unknown HashFunc (%d)", uint8(h))
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package hcutil_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/HcashOrg/hcd/chaincfg"
+	"github.com/HcashOrg/hcd/hcutil"
+)
+
+// TestHashFuncAddresses ensures addresses created with a registered
+// alternative hash function encode, decode and identify their network and hash
+// function as expected.
+func TestHashFuncAddresses(t *testing.T) {
+	const testHashFunc = hcutil.HashFunc(200)
+	hash := func(b []byte) []byte {
+		h := sha256.Sum256(b)
+		return h[:20]
+	}
+
+	if got := testHashFunc.String(); got != "Unknown HashFunc (200)" {
+		t.Fatalf("String: unexpected name %q", got)
+	}
+	if err := hcutil.RegisterHashFunc(testHashFunc, "sha256-20", hash); err != nil {
+		t.Fatalf("RegisterHashFunc: unexpected error: %v", err)
+	}
+	err := hcutil.RegisterHashFunc(testHashFunc, "sha256-20", hash)
+	if err != hcutil.ErrDuplicateHashFunc {
+		t.Fatalf("RegisterHashFunc: unexpected error - got %v, want %v",
+			err, hcutil.ErrDuplicateHashFunc)
+	}
+	if got := testHashFunc.String(); got != "sha256-20" {
+		t.Fatalf("String: unexpected name %q", got)
+	}
+
+	// Addresses can't be created until the network defines identifiers
+	// for the hash function.
+	net := &chaincfg.MainNetParams
+	script := []byte{0x51}
+	_, err = hcutil.NewAddressScriptHashWithHashFunc(script, net, testHashFunc)
+	if err == nil {
+		t.Fatal("NewAddressScriptHashWithHashFunc: unexpected success " +
+			"without address identifiers")
+	}
+
+	defer func(hashFuncAddrs []chaincfg.HashFuncAddrParams) {
+		net.HashFuncAddrs = hashFuncAddrs
+	}(net.HashFuncAddrs)
+	net.HashFuncAddrs = []chaincfg.HashFuncAddrParams{{
+		HashFunc:         uint8(testHashFunc),
+		PubKeyHashAddrID: [2]byte{0x09, 0x70},
+		ScriptHashAddrID: [2]byte{0x09, 0x72},
+	}}
+
+	ash, err := hcutil.NewAddressScriptHashWithHashFunc(script, net,
+		testHashFunc)
+	if err != nil {
+		t.Fatalf("NewAddressScriptHashWithHashFunc: unexpected error: %v",
+			err)
+	}
+	if !bytes.Equal(ash.ScriptAddress(), hash(script)) {
+		t.Fatalf("ScriptAddress: unexpected hash %x", ash.ScriptAddress())
+	}
+	defaultAsh, err := hcutil.NewAddressScriptHash(script, net)
+	if err != nil {
+		t.Fatalf("NewAddressScriptHash: unexpected error: %v", err)
+	}
+	if ash.String() == defaultAsh.String() {
+		t.Fatal("String: alternative hash function address matches " +
+			"default address")
+	}
+
+	apkh, err := hcutil.NewAddressPubKeyHashWithHashFunc(hash([]byte{0x02}),
+		net, testHashFunc)
+	if err != nil {
+		t.Fatalf("NewAddressPubKeyHashWithHashFunc: unexpected error: %v",
+			err)
+	}
+
+	for _, addr := range []hcutil.Address{ash, apkh} {
+		decoded, err := hcutil.DecodeAddress(addr.EncodeAddress())
+		if err != nil {
+			t.Fatalf("DecodeAddress(%s): unexpected error: %v", addr,
+				err)
+		}
+		if decoded.String() != addr.String() {
+			t.Fatalf("DecodeAddress: unexpected address - got %s, "+
+				"want %s", decoded, addr)
+		}
+		if !decoded.IsForNet(net) {
+			t.Fatalf("IsForNet(%s): not for network %s", addr,
+				net.Name)
+		}
+		if decoded.IsForNet(&chaincfg.TestNet2Params) {
+			t.Fatalf("IsForNet(%s): unexpectedly for network %s",
+				addr, chaincfg.TestNet2Params.Name)
+		}
+
+		var hashFunc hcutil.HashFunc
+		switch a := decoded.(type) {
+		case *hcutil.AddressPubKeyHash:
+			hashFunc = a.HashFunc()
+		case *hcutil.AddressScriptHash:
+			hashFunc = a.HashFunc()
+		default:
+			t.Fatalf("DecodeAddress: unexpected type %T", decoded)
+		}
+		if hashFunc != testHashFunc {
+			t.Fatalf("HashFunc(%s): unexpected hash function %v",
+				addr, hashFunc)
+		}
+	}
+}
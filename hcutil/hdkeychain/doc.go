@@ -48,6 +48,21 @@ the HardenedKeyStart constant + the hardened key number as the index to the
 Child function.  This provides the ability to cascade the keys into a tree and
 hence generate the hierarchical deterministic key chains.
 
+Derivation Paths and Batches
+
+Derivation paths such as m/44'/171'/0'/0/0 are parsed with the ParsePath
+function and the resulting Path is derived with the DerivePath function.  The
+Children function derives a batch of consecutive child extended keys, such as
+the next addresses of an account branch, while skipping the rare unusable
+indexes.
+
+Watching-Only Derivation
+
+The PublicChild and PublicChildren functions derive child public extended keys
+without producing any child private key material to the caller.  They work with
+both private and public parent extended keys, so watching-only wallets can
+derive the same keys from a neutered account key as the full wallet.
+
 Normal vs Hardened Child Extended Keys
 
 A private extended key can be used to derive both hardened and non-hardened
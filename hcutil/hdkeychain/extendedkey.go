@@ -15,6 +15,7 @@ import (
 	"crypto/hmac"
 	"crypto/rand"
 	"crypto/sha512"
+	"crypto/subtle"
 	"encoding/binary"
 	"errors"
 	"fmt"
@@ -591,7 +592,7 @@ func NewKeyFromString(key string) (*ExtendedKey, error) {
 	payload := decoded[:len(decoded)-4]
 	checkSum := decoded[len(decoded)-4:]
 	expectedCheckSum := chainhash.HashB(chainhash.HashB(payload))[:4]
-	if !bytes.Equal(checkSum, expectedCheckSum) {
+	if subtle.ConstantTimeCompare(checkSum, expectedCheckSum) != 1 {
 		return nil, ErrBadChecksum
 	}
 
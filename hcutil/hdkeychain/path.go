@@ -0,0 +1,187 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package hdkeychain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidPath describes an error in which a derivation path is not of the
+// form m/44'/171'/0'/0/0.
+var ErrInvalidPath = errors.New("invalid derivation path")
+
+// Path is a BIP0032 derivation path.  Each element is the index of the child
+// extended key to derive at that depth, where indexes greater than or equal to
+// HardenedKeyStart are hardened.
+type Path []uint32
+
+// ParsePath parses a derivation path such as m/44'/171'/0'/0/0.  The leading
+// "m" is optional, in which case the path is relative to the key it is derived
+// from, and hardened indexes may be marked with a trailing ', h or H.
+func ParsePath(path string) (Path, error) {
+	elems := strings.Split(path, "/")
+	if elems[0] == "m" {
+		elems = elems[1:]
+	}
+
+	p := make(Path, 0, len(elems))
+	for _, elem := range elems {
+		hardened := false
+		if n := len(elem); n > 0 {
+			switch elem[n-1] {
+			case '\'', 'h', 'H':
+				hardened = true
+				elem = elem[:n-1]
+			}
+		}
+
+		// Signs and other leniencies accepted by strconv are not valid
+		// in a path.
+		if elem == "" || elem[0] < '0' || elem[0] > '9' {
+			return nil, fmt.Errorf("%v %q", ErrInvalidPath, path)
+		}
+		index, err := strconv.ParseUint(elem, 10, 32)
+		if err != nil || index >= HardenedKeyStart {
+			return nil, fmt.Errorf("%v %q", ErrInvalidPath, path)
+		}
+		if hardened {
+			index += HardenedKeyStart
+		}
+		p = append(p, uint32(index))
+	}
+
+	return p, nil
+}
+
+// String returns the path in the form m/44'/171'/0'/0/0.
+func (p Path) String() string {
+	var sb strings.Builder
+	sb.WriteString("m")
+	for _, index := range p {
+		sb.WriteByte('/')
+		if index >= HardenedKeyStart {
+			sb.WriteString(strconv.FormatUint(uint64(index-HardenedKeyStart), 10))
+			sb.WriteByte('\'')
+			continue
+		}
+		sb.WriteString(strconv.FormatUint(uint64(index), 10))
+	}
+	return sb.String()
+}
+
+// DerivePath returns the extended key derived from this one by successively
+// deriving the children at each index of the passed path.  The same rules as
+// Child apply, so ErrDeriveHardFromPublic is returned when the path contains a
+// hardened index and this is a public extended key, and ErrInvalidChild is
+// returned in the extremely unlikely event any key along the path is invalid.
+func (k *ExtendedKey) DerivePath(path Path) (*ExtendedKey, error) {
+	key := k
+	for _, index := range path {
+		child, err := key.Child(index)
+		if err != nil {
+			return nil, err
+		}
+		key = child
+	}
+	return key, nil
+}
+
+// Children derives count consecutive child extended keys starting at index
+// start.  This is typically used to derive a batch of addresses from an
+// account branch.  Indexes which do not derive to a usable child are skipped,
+// so the returned keys always number count and their indexes are available via
+// ChildIndex.
+//
+// The indexes must not cross from the normal into the hardened range, nor
+// overflow past the last hardened index.
+func (k *ExtendedKey) Children(start uint32, count uint32) ([]*ExtendedKey, error) {
+	children := make([]*ExtendedKey, 0, count)
+	for index := uint64(start); uint32(len(children)) < count; index++ {
+		if index > math.MaxUint32 || (start < HardenedKeyStart &&
+			index >= HardenedKeyStart) {
+
+			return nil, fmt.Errorf("child indexes from %d exhaust "+
+				"the index range", start)
+		}
+
+		child, err := k.Child(uint32(index))
+		if err == ErrInvalidChild {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, child)
+	}
+	return children, nil
+}
+
+// PublicChild returns the public extended key of the child at the given index.
+// Unlike Child, the result never contains private key material even when this
+// is a private extended key, which makes it suitable for handing child keys to
+// watching-only wallets.
+func (k *ExtendedKey) PublicChild(i uint32) (*ExtendedKey, error) {
+	child, err := k.Child(i)
+	if err != nil {
+		return nil, err
+	}
+	pub, err := child.Neuter()
+	if child.isPrivate {
+		// The public extended key shares the chain code and public key
+		// of the child, so only the private key is cleared.
+		zero(child.key)
+	}
+	return pub, err
+}
+
+// PublicChildren is the same as Children except the returned child extended
+// keys are always public extended keys.  When this is a public extended key,
+// only normal (non-hardened) indexes may be derived.
+func (k *ExtendedKey) PublicChildren(start uint32, count uint32) ([]*ExtendedKey, error) {
+	parent := k
+	if k.isPrivate && k.algtype == keyEc && start < HardenedKeyStart {
+		// Normal children of the neutered parent are the same as the
+		// neutered normal children of the private parent, so derive
+		// from the public parent to avoid creating any private keys.
+		var err error
+		parent, err = k.Neuter()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	children, err := parent.Children(start, count)
+	if err != nil {
+		return nil, err
+	}
+	for i, child := range children {
+		if !child.isPrivate {
+			continue
+		}
+		pub, err := child.Neuter()
+		zero(child.key)
+		if err != nil {
+			return nil, err
+		}
+		children[i] = pub
+	}
+	return children, nil
+}
+
+// ChildIndex returns the index at which this extended key was derived from its
+// parent.
+func (k *ExtendedKey) ChildIndex() uint32 {
+	return k.childNum
+}
+
+// Depth returns the number of derivations between this extended key and the
+// master node.
+func (k *ExtendedKey) Depth() uint16 {
+	return k.depth
+}
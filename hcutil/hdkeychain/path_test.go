@@ -0,0 +1,157 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package hdkeychain_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/HcashOrg/hcd/chaincfg"
+	"github.com/HcashOrg/hcd/hcutil/hdkeychain"
+)
+
+// TestParsePath ensures derivation paths are parsed and formatted as
+// expected.
+func TestParsePath(t *testing.T) {
+	const h = hdkeychain.HardenedKeyStart
+	tests := []struct {
+		path string
+		want hdkeychain.Path
+		str  string
+	}{
+		{"m", hdkeychain.Path{}, "m"},
+		{"m/44'/171'/0'/0/1", hdkeychain.Path{h + 44, h + 171, h, 0, 1},
+			"m/44'/171'/0'/0/1"},
+		{"44h/171H/0", hdkeychain.Path{h + 44, h + 171, 0},
+			"m/44'/171'/0"},
+		{"m/2147483647'", hdkeychain.Path{h + h - 1}, "m/2147483647'"},
+	}
+	for _, test := range tests {
+		got, err := hdkeychain.ParsePath(test.path)
+		if err != nil {
+			t.Errorf("ParsePath(%q): unexpected error: %v", test.path,
+				err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("ParsePath(%q): got %v, want %v", test.path,
+				[]uint32(got), []uint32(test.want))
+		}
+		if got.String() != test.str {
+			t.Errorf("String(%q): got %q, want %q", test.path,
+				got.String(), test.str)
+		}
+	}
+
+	invalid := []string{"", "m/", "m//1", "m/-1", "m/+1", "m/1''", "m/x",
+		"m/2147483648", "m/4294967296", "/1", "M/1"}
+	for _, path := range invalid {
+		if _, err := hdkeychain.ParsePath(path); err == nil {
+			t.Errorf("ParsePath(%q): unexpected success", path)
+		}
+	}
+}
+
+// TestBatchDerivation ensures deriving paths and batches of children, both
+// private and watching-only public, match deriving each child individually.
+func TestBatchDerivation(t *testing.T) {
+	seed := bytes.Repeat([]byte{0x01}, hdkeychain.RecommendedSeedLen)
+	master, err := hdkeychain.NewMaster(seed, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("NewMaster: unexpected error: %v", err)
+	}
+
+	path, err := hdkeychain.ParsePath("m/44'/171'/0'")
+	if err != nil {
+		t.Fatalf("ParsePath: unexpected error: %v", err)
+	}
+	account, err := master.DerivePath(path)
+	if err != nil {
+		t.Fatalf("DerivePath: unexpected error: %v", err)
+	}
+	want := master
+	for _, index := range path {
+		want, err = want.Child(index)
+		if err != nil {
+			t.Fatalf("Child: unexpected error: %v", err)
+		}
+	}
+	mustString := func(k *hdkeychain.ExtendedKey) string {
+		s, err := k.String()
+		if err != nil {
+			t.Fatalf("String: unexpected error: %v", err)
+		}
+		return s
+	}
+	if mustString(account) != mustString(want) {
+		t.Fatal("DerivePath: derived key does not match chained children")
+	}
+	if account.Depth() != 3 || account.ChildIndex() != path[2] {
+		t.Fatalf("DerivePath: unexpected depth %d and index %d",
+			account.Depth(), account.ChildIndex())
+	}
+
+	accountPub, err := account.Neuter()
+	if err != nil {
+		t.Fatalf("Neuter: unexpected error: %v", err)
+	}
+	if _, err := accountPub.DerivePath(path); err != hdkeychain.ErrDeriveHardFromPublic {
+		t.Fatalf("DerivePath: unexpected error - got %v, want %v", err,
+			hdkeychain.ErrDeriveHardFromPublic)
+	}
+
+	const start, count = 5, 4
+	privChildren, err := account.Children(start, count)
+	if err != nil {
+		t.Fatalf("Children: unexpected error: %v", err)
+	}
+	pubChildren, err := account.PublicChildren(start, count)
+	if err != nil {
+		t.Fatalf("PublicChildren: unexpected error: %v", err)
+	}
+	watchChildren, err := accountPub.PublicChildren(start, count)
+	if err != nil {
+		t.Fatalf("PublicChildren: unexpected error: %v", err)
+	}
+	if len(privChildren) != count || len(pubChildren) != count ||
+		len(watchChildren) != count {
+
+		t.Fatal("unexpected number of derived children")
+	}
+	for i := 0; i < count; i++ {
+		index := uint32(start + i)
+		if privChildren[i].ChildIndex() != index {
+			t.Fatalf("Children: unexpected index %d, want %d",
+				privChildren[i].ChildIndex(), index)
+		}
+		neutered, err := privChildren[i].Neuter()
+		if err != nil {
+			t.Fatalf("Neuter: unexpected error: %v", err)
+		}
+		single, err := account.PublicChild(index)
+		if err != nil {
+			t.Fatalf("PublicChild: unexpected error: %v", err)
+		}
+		for _, pub := range []*hdkeychain.ExtendedKey{pubChildren[i],
+			watchChildren[i], single} {
+
+			if pub.IsPrivate() {
+				t.Fatalf("child %d is unexpectedly private", index)
+			}
+			if mustString(pub) != mustString(neutered) {
+				t.Fatalf("child %d does not match the neutered "+
+					"private child", index)
+			}
+		}
+	}
+
+	// Batches may not cross into the hardened range.
+	_, err = account.Children(hdkeychain.HardenedKeyStart-1, 2)
+	if err == nil {
+		t.Fatal("Children: unexpected success crossing into the " +
+			"hardened range")
+	}
+}
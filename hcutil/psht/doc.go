@@ -0,0 +1,55 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package psht implements partially signed HC transactions (PSHT), an
+interchange format modeled after BIP0174 partially signed bitcoin transactions
+which allows the parties to a transaction to construct, sign and finalize it
+independently of each other.
+
+Overview
+
+A PSHT packet wraps an unsigned transaction together with per-input and
+per-output metadata.  Unlike BIP0174, the unsigned transaction is stored in
+the full (prefix and witness) serialization so the transaction version, expiry
+and input witness values, such as the value of a stakebase input, are carried
+along with it.  Every signature script of the unsigned transaction must be
+empty.
+
+The serialization is the magic bytes "psht" followed by 0xff, then a global
+key-value map followed by one key-value map for every input and output of the
+unsigned transaction, in order.  Keys and values are both encoded as variable
+length byte arrays and each map is terminated by a zero length key.  The first
+byte of a key is its type, and any remaining bytes are key data.  Key types
+which are not known by this package are preserved as unknowns so they survive a
+round trip through software which does not understand them.
+
+Roles
+
+The package supports the roles defined by BIP0174:
+
+  - Creator: New or NewFromUnsignedTx create a packet from an unsigned
+    transaction
+  - Updater: the AddIn* and AddOut* methods add the previous outputs,
+    signature hash types, redeem scripts and key derivation paths needed to
+    sign
+  - Signer: AddPartialSig adds a signature for an input
+  - Combiner: Combine merges packets for the same unsigned transaction
+  - Finalizer: Finalize and MaybeFinalizeAll create the final signature
+    scripts from the partial signatures
+  - Extractor: Extract returns the fully signed transaction
+
+Stake Transactions
+
+Previous outputs which are tagged with a stake opcode, such as ticket
+commitments and vote or revocation outputs, are finalized according to their
+pay-to-pubkey-hash or pay-to-script-hash subclass.  The stakebase input of a
+vote does not spend a previous output, so its signature script is set with
+FinalizeStakeBase instead.
+
+Multi-signature scripts in HC do not consume an additional stack item, so,
+unlike bitcoin, the final signature scripts of multi-signature redeem scripts
+do not contain a leading dummy push.
+*/
+package psht
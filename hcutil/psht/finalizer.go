@@ -0,0 +1,297 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package psht
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/HcashOrg/hcd/blockchain/stake"
+	"github.com/HcashOrg/hcd/chaincfg"
+	"github.com/HcashOrg/hcd/txscript"
+	"github.com/HcashOrg/hcd/wire"
+)
+
+var (
+	// ErrNotFinalizable describes an error where an input does not yet
+	// have the information required to create its final signature script.
+	ErrNotFinalizable = errors.New("input can not be finalized")
+
+	// ErrIncomplete describes an error where the signed transaction is
+	// extracted from a packet before all of its inputs are finalized.
+	ErrIncomplete = errors.New("psht is not fully finalized")
+
+	// ErrTxMismatch describes an error where packets for different
+	// unsigned transactions are combined.
+	ErrTxMismatch = errors.New("packets are for different transactions")
+)
+
+// Finalize creates the final signature script of the input at index i from its
+// partial signatures and redeem script, and then removes the data which is
+// only needed for signing from the input.  Pay-to-pubkey, pay-to-pubkey-hash,
+// their alternative signature scheme variants, and pay-to-script-hash of any of
+// those or of a multi-signature script are supported, including when the
+// previous output is tagged with a stake opcode.
+//
+// Inputs which are already finalized are left unchanged.
+func Finalize(p *Packet, i int) error {
+	if i < 0 || i >= len(p.Inputs) {
+		return ErrInvalidIndex
+	}
+	pi := &p.Inputs[i]
+	if pi.isFinalized() {
+		return nil
+	}
+	if i == 0 && stake.IsStakeBase(p.UnsignedTx) {
+		return fmt.Errorf("%v: input %d is a stakebase", ErrNotFinalizable,
+			i)
+	}
+	if pi.PrevOut == nil {
+		return fmt.Errorf("%v: input %d has no previous output",
+			ErrNotFinalizable, i)
+	}
+
+	class := scriptClass(pi.PrevOut.Version, pi.PrevOut.PkScript)
+	var builder *txscript.ScriptBuilder
+	var err error
+	if class == txscript.ScriptHashTy {
+		if pi.RedeemScript == nil {
+			return fmt.Errorf("%v: input %d has no redeem script",
+				ErrNotFinalizable, i)
+		}
+		redeemClass := txscript.GetScriptClass(pi.PrevOut.Version,
+			pi.RedeemScript)
+		builder, err = signatureScript(redeemClass, pi.RedeemScript, pi)
+		if err == nil {
+			builder.AddData(pi.RedeemScript)
+		}
+	} else {
+		builder, err = signatureScript(class, pi.PrevOut.PkScript, pi)
+	}
+	if err != nil {
+		return fmt.Errorf("%v: input %d: %v", ErrNotFinalizable, i, err)
+	}
+	sigScript, err := builder.Script()
+	if err != nil {
+		return err
+	}
+
+	*pi = PInput{
+		PrevOut:        pi.PrevOut,
+		FinalScriptSig: sigScript,
+		Unknowns:       pi.Unknowns,
+	}
+	return nil
+}
+
+// signatureScript returns a script builder containing the signatures which
+// satisfy a script of the passed class.
+func signatureScript(class txscript.ScriptClass, script []byte, pi *PInput) (*txscript.ScriptBuilder, error) {
+	builder := txscript.NewScriptBuilder()
+	switch class {
+	case txscript.PubKeyTy, txscript.PubkeyAltTy:
+		if len(pi.PartialSigs) != 1 {
+			return nil, errors.New("pay-to-pubkey requires exactly " +
+				"one signature")
+		}
+		builder.AddData(pi.PartialSigs[0].Signature)
+
+	case txscript.PubKeyHashTy, txscript.PubkeyHashAltTy:
+		if len(pi.PartialSigs) != 1 {
+			return nil, errors.New("pay-to-pubkey-hash requires " +
+				"exactly one signature")
+		}
+		ps := pi.PartialSigs[0]
+		builder.AddData(ps.Signature).AddData(ps.PubKey)
+
+	case txscript.MultiSigTy:
+		// The signatures must be in the same order as the public keys
+		// of the script.  Every push of a multi-signature script is a
+		// public key.
+		reqSigs, _, err := txscript.GetMultisigMandN(script)
+		if err != nil {
+			return nil, err
+		}
+		pubKeys, err := txscript.PushedData(script)
+		if err != nil {
+			return nil, err
+		}
+		numSigs := 0
+		for _, pubKey := range pubKeys {
+			if numSigs == int(reqSigs) {
+				break
+			}
+			if ps := pi.partialSig(pubKey); ps != nil {
+				builder.AddData(ps.Signature)
+				numSigs++
+			}
+		}
+		if numSigs < int(reqSigs) {
+			return nil, fmt.Errorf("multi-signature script requires "+
+				"%d signatures but has %d", reqSigs, numSigs)
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported script type %v", class)
+	}
+	return builder, nil
+}
+
+// MaybeFinalizeAll finalizes every input of the packet, returning the first
+// error encountered.  The stakebase input of a vote must already have been
+// finalized with FinalizeStakeBase.
+func MaybeFinalizeAll(p *Packet) error {
+	for i := range p.Inputs {
+		if err := Finalize(p, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FinalizeStakeBase sets the final signature script of the stakebase input of
+// a vote to the stakebase signature script required by the passed network.  It
+// has no effect on packets for other types of transactions.
+func FinalizeStakeBase(p *Packet, params *chaincfg.Params) {
+	if len(p.Inputs) == 0 || !stake.IsStakeBase(p.UnsignedTx) {
+		return
+	}
+	sigScript := make([]byte, len(params.StakeBaseSigScript))
+	copy(sigScript, params.StakeBaseSigScript)
+	p.Inputs[0] = PInput{
+		FinalScriptSig: sigScript,
+		Unknowns:       p.Inputs[0].Unknowns,
+	}
+}
+
+// Extract returns the signed transaction of a packet whose inputs are all
+// finalized.  The witness value of each input with a known previous output is
+// set to the value of that output.
+func Extract(p *Packet) (*wire.MsgTx, error) {
+	if err := p.SanityCheck(); err != nil {
+		return nil, err
+	}
+	if !p.IsComplete() {
+		return nil, ErrIncomplete
+	}
+
+	tx := p.UnsignedTx.Copy()
+	for i, txIn := range tx.TxIn {
+		pi := &p.Inputs[i]
+		txIn.SignatureScript = pi.FinalScriptSig
+		if pi.PrevOut != nil {
+			txIn.ValueIn = pi.PrevOut.Value
+		}
+	}
+	return tx, nil
+}
+
+// Combine merges packets for the same unsigned transaction into a new packet.
+// Data for an input or output which is present in several packets is taken
+// from the first packet containing it, except partial signatures, key
+// derivations and unknowns, which are merged.
+func Combine(packets ...*Packet) (*Packet, error) {
+	if len(packets) == 0 {
+		return nil, errors.New("no packets to combine")
+	}
+	for _, p := range packets {
+		if err := p.SanityCheck(); err != nil {
+			return nil, err
+		}
+	}
+
+	txHash := packets[0].UnsignedTx.TxHash()
+	combined, err := NewFromUnsignedTx(packets[0].UnsignedTx.Copy())
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range packets {
+		if p.UnsignedTx.TxHash() != txHash {
+			return nil, ErrTxMismatch
+		}
+
+		combined.Unknowns = mergeUnknowns(combined.Unknowns, p.Unknowns)
+		for i := range p.Inputs {
+			combineInput(&combined.Inputs[i], &p.Inputs[i])
+		}
+		for i := range p.Outputs {
+			dst, src := &combined.Outputs[i], &p.Outputs[i]
+			if dst.RedeemScript == nil {
+				dst.RedeemScript = src.RedeemScript
+			}
+			dst.Bip32Derivation = mergeBip32Derivations(
+				dst.Bip32Derivation, src.Bip32Derivation)
+			dst.Unknowns = mergeUnknowns(dst.Unknowns, src.Unknowns)
+		}
+	}
+
+	// The signing data of finalized inputs is no longer needed.
+	for i := range combined.Inputs {
+		pi := &combined.Inputs[i]
+		if pi.isFinalized() {
+			*pi = PInput{
+				PrevOut:        pi.PrevOut,
+				FinalScriptSig: pi.FinalScriptSig,
+				Unknowns:       pi.Unknowns,
+			}
+		}
+	}
+	return combined, nil
+}
+
+// combineInput merges the data of the src input into dst.
+func combineInput(dst, src *PInput) {
+	if dst.PrevOut == nil {
+		dst.PrevOut = src.PrevOut
+	}
+	for _, ps := range src.PartialSigs {
+		if dst.partialSig(ps.PubKey) == nil {
+			dst.PartialSigs = append(dst.PartialSigs, ps)
+		}
+	}
+	if dst.SighashType == 0 {
+		dst.SighashType = src.SighashType
+	}
+	if dst.RedeemScript == nil {
+		dst.RedeemScript = src.RedeemScript
+	}
+	if dst.FinalScriptSig == nil {
+		dst.FinalScriptSig = src.FinalScriptSig
+	}
+	dst.Bip32Derivation = mergeBip32Derivations(dst.Bip32Derivation,
+		src.Bip32Derivation)
+	dst.Unknowns = mergeUnknowns(dst.Unknowns, src.Unknowns)
+}
+
+// mergeBip32Derivations returns dst with the key derivations of src for public
+// keys not already in dst appended.
+func mergeBip32Derivations(dst, src []*Bip32Derivation) []*Bip32Derivation {
+next:
+	for _, d := range src {
+		for _, existing := range dst {
+			if bytes.Equal(existing.PubKey, d.PubKey) {
+				continue next
+			}
+		}
+		dst = append(dst, d)
+	}
+	return dst
+}
+
+// mergeUnknowns returns dst with the unknowns of src for keys not already in
+// dst appended.
+func mergeUnknowns(dst, src []*Unknown) []*Unknown {
+next:
+	for _, u := range src {
+		for _, existing := range dst {
+			if bytes.Equal(existing.Key, u.Key) {
+				continue next
+			}
+		}
+		dst = append(dst, u)
+	}
+	return dst
+}
@@ -0,0 +1,229 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package psht
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	"github.com/HcashOrg/hcd/hcutil/hdkeychain"
+	"github.com/HcashOrg/hcd/txscript"
+	"github.com/HcashOrg/hcd/wire"
+)
+
+// Input key types.
+const (
+	inPrevOutType         = 0x01
+	inPartialSigType      = 0x02
+	inSighashType         = 0x03
+	inRedeemScriptType    = 0x04
+	inFinalScriptSigType  = 0x05
+	inBip32DerivationType = 0x06
+)
+
+// PrevOut describes the previous output spent by an input.
+type PrevOut struct {
+	Value    int64
+	Version  uint16
+	PkScript []byte
+}
+
+// PartialSig is a signature, with the signature hash type appended, for an
+// input by the given public key.
+type PartialSig struct {
+	PubKey    []byte
+	Signature []byte
+}
+
+// Bip32Derivation describes how the private key for a public key is derived
+// from the master extended key with the given fingerprint.
+type Bip32Derivation struct {
+	PubKey               []byte
+	MasterKeyFingerprint uint32
+	Path                 hdkeychain.Path
+}
+
+// PInput houses the metadata of an input of the unsigned transaction.
+type PInput struct {
+	PrevOut         *PrevOut
+	PartialSigs     []*PartialSig
+	SighashType     txscript.SigHashType
+	RedeemScript    []byte
+	FinalScriptSig  []byte
+	Bip32Derivation []*Bip32Derivation
+	Unknowns        []*Unknown
+}
+
+// isFinalized returns whether or not the input has a final signature script.
+func (pi *PInput) isFinalized() bool {
+	return pi.FinalScriptSig != nil
+}
+
+// partialSig returns the partial signature by the passed public key, or nil
+// when there is none.
+func (pi *PInput) partialSig(pubKey []byte) *PartialSig {
+	for _, ps := range pi.PartialSigs {
+		if bytes.Equal(ps.PubKey, pubKey) {
+			return ps
+		}
+	}
+	return nil
+}
+
+// deserialize reads the key-value map of the input.
+func (pi *PInput) deserialize(r io.Reader) error {
+	seen := make(map[string]struct{})
+	for {
+		keyType, keyData, value, err := readKeyValue(r, seen)
+		if err != nil {
+			return err
+		}
+		if keyType == nil {
+			return nil
+		}
+
+		switch *keyType {
+		case inPrevOutType:
+			if len(keyData) != 0 || len(value) < 10 {
+				return ErrInvalidPshtFormat
+			}
+			vr := bytes.NewReader(value[10:])
+			pkScript, err := wire.ReadVarBytes(vr, 0,
+				MaxPshtKeyValueSize, "psht prevout script")
+			if err != nil || vr.Len() != 0 {
+				return ErrInvalidPshtFormat
+			}
+			pi.PrevOut = &PrevOut{
+				Value:    int64(binary.LittleEndian.Uint64(value)),
+				Version:  binary.LittleEndian.Uint16(value[8:]),
+				PkScript: pkScript,
+			}
+
+		case inPartialSigType:
+			if len(keyData) == 0 || len(value) == 0 {
+				return ErrInvalidPshtFormat
+			}
+			pi.PartialSigs = append(pi.PartialSigs, &PartialSig{
+				PubKey:    keyData,
+				Signature: value,
+			})
+
+		case inSighashType:
+			if len(keyData) != 0 || len(value) != 4 {
+				return ErrInvalidPshtFormat
+			}
+			pi.SighashType = txscript.SigHashType(
+				binary.LittleEndian.Uint32(value))
+
+		case inRedeemScriptType:
+			if len(keyData) != 0 {
+				return ErrInvalidPshtFormat
+			}
+			pi.RedeemScript = value
+
+		case inFinalScriptSigType:
+			if len(keyData) != 0 {
+				return ErrInvalidPshtFormat
+			}
+			pi.FinalScriptSig = value
+
+		case inBip32DerivationType:
+			d, err := readBip32Derivation(keyData, value)
+			if err != nil {
+				return err
+			}
+			pi.Bip32Derivation = append(pi.Bip32Derivation, d)
+
+		default:
+			pi.Unknowns = append(pi.Unknowns, newUnknown(*keyType,
+				keyData, value))
+		}
+	}
+}
+
+// serialize writes the key-value map of the input.
+func (pi *PInput) serialize(w io.Writer) error {
+	if pi.PrevOut != nil {
+		var buf bytes.Buffer
+		var b [10]byte
+		binary.LittleEndian.PutUint64(b[:], uint64(pi.PrevOut.Value))
+		binary.LittleEndian.PutUint16(b[8:], pi.PrevOut.Version)
+		buf.Write(b[:])
+		err := wire.WriteVarBytes(&buf, 0, pi.PrevOut.PkScript)
+		if err != nil {
+			return err
+		}
+		err = writeKeyValue(w, inPrevOutType, nil, buf.Bytes())
+		if err != nil {
+			return err
+		}
+	}
+	for _, ps := range pi.PartialSigs {
+		err := writeKeyValue(w, inPartialSigType, ps.PubKey, ps.Signature)
+		if err != nil {
+			return err
+		}
+	}
+	if pi.SighashType != 0 {
+		var b [4]byte
+		binary.LittleEndian.PutUint32(b[:], uint32(pi.SighashType))
+		if err := writeKeyValue(w, inSighashType, nil, b[:]); err != nil {
+			return err
+		}
+	}
+	if pi.RedeemScript != nil {
+		err := writeKeyValue(w, inRedeemScriptType, nil, pi.RedeemScript)
+		if err != nil {
+			return err
+		}
+	}
+	if pi.FinalScriptSig != nil {
+		err := writeKeyValue(w, inFinalScriptSigType, nil,
+			pi.FinalScriptSig)
+		if err != nil {
+			return err
+		}
+	}
+	for _, d := range pi.Bip32Derivation {
+		err := writeBip32Derivation(w, inBip32DerivationType, d)
+		if err != nil {
+			return err
+		}
+	}
+	if err := writeUnknowns(w, pi.Unknowns); err != nil {
+		return err
+	}
+	return writeSeparator(w)
+}
+
+// readBip32Derivation decodes a key derivation from the key data and value of
+// a key-value pair.  The key data is the public key and the value is the
+// master key fingerprint followed by the indexes of the path.
+func readBip32Derivation(keyData, value []byte) (*Bip32Derivation, error) {
+	if len(keyData) == 0 || len(value) < 4 || len(value)%4 != 0 {
+		return nil, ErrInvalidPshtFormat
+	}
+	path := make(hdkeychain.Path, 0, len(value)/4-1)
+	for i := 4; i < len(value); i += 4 {
+		path = append(path, binary.LittleEndian.Uint32(value[i:]))
+	}
+	return &Bip32Derivation{
+		PubKey:               keyData,
+		MasterKeyFingerprint: binary.LittleEndian.Uint32(value),
+		Path:                 path,
+	}, nil
+}
+
+// writeBip32Derivation writes a key derivation as a key-value pair of the
+// passed key type.
+func writeBip32Derivation(w io.Writer, keyType byte, d *Bip32Derivation) error {
+	value := make([]byte, 4*(len(d.Path)+1))
+	binary.LittleEndian.PutUint32(value, d.MasterKeyFingerprint)
+	for i, index := range d.Path {
+		binary.LittleEndian.PutUint32(value[4*(i+1):], index)
+	}
+	return writeKeyValue(w, keyType, d.PubKey, value)
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package psht
+
+import (
+	"io"
+)
+
+// Output key types.
+const (
+	outRedeemScriptType    = 0x00
+	outBip32DerivationType = 0x01
+)
+
+// POutput houses the metadata of an output of the unsigned transaction, which
+// allows signers to identify their own change outputs.
+type POutput struct {
+	RedeemScript    []byte
+	Bip32Derivation []*Bip32Derivation
+	Unknowns        []*Unknown
+}
+
+// deserialize reads the key-value map of the output.
+func (po *POutput) deserialize(r io.Reader) error {
+	seen := make(map[string]struct{})
+	for {
+		keyType, keyData, value, err := readKeyValue(r, seen)
+		if err != nil {
+			return err
+		}
+		if keyType == nil {
+			return nil
+		}
+
+		switch *keyType {
+		case outRedeemScriptType:
+			if len(keyData) != 0 {
+				return ErrInvalidPshtFormat
+			}
+			po.RedeemScript = value
+
+		case outBip32DerivationType:
+			d, err := readBip32Derivation(keyData, value)
+			if err != nil {
+				return err
+			}
+			po.Bip32Derivation = append(po.Bip32Derivation, d)
+
+		default:
+			po.Unknowns = append(po.Unknowns, newUnknown(*keyType,
+				keyData, value))
+		}
+	}
+}
+
+// serialize writes the key-value map of the output.
+func (po *POutput) serialize(w io.Writer) error {
+	if po.RedeemScript != nil {
+		err := writeKeyValue(w, outRedeemScriptType, nil, po.RedeemScript)
+		if err != nil {
+			return err
+		}
+	}
+	for _, d := range po.Bip32Derivation {
+		err := writeBip32Derivation(w, outBip32DerivationType, d)
+		if err != nil {
+			return err
+		}
+	}
+	if err := writeUnknowns(w, po.Unknowns); err != nil {
+		return err
+	}
+	return writeSeparator(w)
+}
@@ -0,0 +1,314 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package psht
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io"
+
+	"github.com/HcashOrg/hcd/wire"
+)
+
+// pshtMagic is the serialized prefix of every packet.
+var pshtMagic = [5]byte{'p', 's', 'h', 't', 0xff}
+
+// MaxPshtKeyValueSize is the maximum allowed size of a serialized key or
+// value of a packet.
+const MaxPshtKeyValueSize = wire.MaxMessagePayload
+
+var (
+	// ErrInvalidMagic describes an error where serialized data does not
+	// begin with the PSHT magic bytes.
+	ErrInvalidMagic = errors.New("invalid psht magic bytes")
+
+	// ErrInvalidPshtFormat describes an error where serialized data is not
+	// a well-formed packet.
+	ErrInvalidPshtFormat = errors.New("invalid psht serialization format")
+
+	// ErrDuplicateKey describes an error where a key appears more than once
+	// in the same key-value map.
+	ErrDuplicateKey = errors.New("duplicate psht key")
+
+	// ErrNonEmptySigScript describes an error where the unsigned
+	// transaction of a packet contains a signature script.
+	ErrNonEmptySigScript = errors.New("unsigned transaction has a " +
+		"non-empty signature script")
+
+	// ErrInvalidIndex describes an error where an input or output index is
+	// out of range for the unsigned transaction.
+	ErrInvalidIndex = errors.New("input or output index out of range")
+)
+
+// Global key types.
+const (
+	globalUnsignedTxType = 0x00
+)
+
+// Unknown houses a key-value pair whose key type is not known by this
+// package.  The key includes the key type byte.
+type Unknown struct {
+	Key   []byte
+	Value []byte
+}
+
+// Packet is a partially signed HC transaction.  It consists of the unsigned
+// transaction along with the metadata for each of its inputs and outputs
+// required to sign and finalize it.
+type Packet struct {
+	// UnsignedTx is the transaction being signed.  All of its signature
+	// scripts are empty.
+	UnsignedTx *wire.MsgTx
+
+	// Inputs and Outputs contain the metadata of the corresponding inputs
+	// and outputs of the unsigned transaction.
+	Inputs  []PInput
+	Outputs []POutput
+
+	// Unknowns are the global key-value pairs not known by this package.
+	Unknowns []*Unknown
+}
+
+// New returns a packet for a new unsigned transaction of the default version
+// which spends the passed outpoints to the passed outputs.
+func New(inputs []*wire.OutPoint, outputs []*wire.TxOut, lockTime,
+	expiry uint32) (*Packet, error) {
+
+	tx := wire.NewMsgTx()
+	for _, prevOut := range inputs {
+		tx.AddTxIn(wire.NewTxIn(prevOut, nil))
+	}
+	for _, txOut := range outputs {
+		tx.AddTxOut(txOut)
+	}
+	tx.LockTime = lockTime
+	tx.Expiry = expiry
+	return NewFromUnsignedTx(tx)
+}
+
+// NewFromUnsignedTx returns a packet for the passed unsigned transaction, which
+// may be of any transaction version or stake type.  ErrNonEmptySigScript is
+// returned if any of its signature scripts are not empty.
+func NewFromUnsignedTx(tx *wire.MsgTx) (*Packet, error) {
+	if err := checkUnsignedTx(tx); err != nil {
+		return nil, err
+	}
+	return &Packet{
+		UnsignedTx: tx,
+		Inputs:     make([]PInput, len(tx.TxIn)),
+		Outputs:    make([]POutput, len(tx.TxOut)),
+	}, nil
+}
+
+// checkUnsignedTx returns an error if the passed transaction can't be used as
+// the unsigned transaction of a packet.
+func checkUnsignedTx(tx *wire.MsgTx) error {
+	for _, txIn := range tx.TxIn {
+		if len(txIn.SignatureScript) != 0 {
+			return ErrNonEmptySigScript
+		}
+	}
+	return nil
+}
+
+// NewFromRawBytes deserializes a packet from the passed reader.  When b64 is
+// true the serialized packet is expected to be base64 encoded.
+func NewFromRawBytes(r io.Reader, b64 bool) (*Packet, error) {
+	if b64 {
+		r = base64.NewDecoder(base64.StdEncoding, r)
+	}
+
+	var magic [len(pshtMagic)]byte
+	if _, err := io.ReadFull(r, magic[:]); err != nil {
+		return nil, err
+	}
+	if magic != pshtMagic {
+		return nil, ErrInvalidMagic
+	}
+
+	// Read the global map, which must contain the unsigned transaction.
+	p := new(Packet)
+	seen := make(map[string]struct{})
+	for {
+		keyType, keyData, value, err := readKeyValue(r, seen)
+		if err != nil {
+			return nil, err
+		}
+		if keyType == nil {
+			break
+		}
+
+		switch *keyType {
+		case globalUnsignedTxType:
+			if len(keyData) != 0 {
+				return nil, ErrInvalidPshtFormat
+			}
+			tx := new(wire.MsgTx)
+			if err := tx.FromBytes(value); err != nil {
+				return nil, err
+			}
+			if err := checkUnsignedTx(tx); err != nil {
+				return nil, err
+			}
+			p.UnsignedTx = tx
+
+		default:
+			p.Unknowns = append(p.Unknowns, newUnknown(*keyType,
+				keyData, value))
+		}
+	}
+	if p.UnsignedTx == nil {
+		return nil, ErrInvalidPshtFormat
+	}
+
+	p.Inputs = make([]PInput, len(p.UnsignedTx.TxIn))
+	for i := range p.Inputs {
+		if err := p.Inputs[i].deserialize(r); err != nil {
+			return nil, err
+		}
+	}
+	p.Outputs = make([]POutput, len(p.UnsignedTx.TxOut))
+	for i := range p.Outputs {
+		if err := p.Outputs[i].deserialize(r); err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+// Serialize writes the packet to the passed writer.
+func (p *Packet) Serialize(w io.Writer) error {
+	if err := p.SanityCheck(); err != nil {
+		return err
+	}
+
+	if _, err := w.Write(pshtMagic[:]); err != nil {
+		return err
+	}
+
+	tx, err := p.UnsignedTx.Bytes()
+	if err != nil {
+		return err
+	}
+	err = writeKeyValue(w, globalUnsignedTxType, nil, tx)
+	if err != nil {
+		return err
+	}
+	if err := writeUnknowns(w, p.Unknowns); err != nil {
+		return err
+	}
+	if err := writeSeparator(w); err != nil {
+		return err
+	}
+
+	for i := range p.Inputs {
+		if err := p.Inputs[i].serialize(w); err != nil {
+			return err
+		}
+	}
+	for i := range p.Outputs {
+		if err := p.Outputs[i].serialize(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// B64Encode returns the base64 encoding of the serialized packet.
+func (p *Packet) B64Encode() (string, error) {
+	var buf bytes.Buffer
+	if err := p.Serialize(&buf); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// SanityCheck returns an error if the packet is not internally consistent.
+func (p *Packet) SanityCheck() error {
+	if p.UnsignedTx == nil {
+		return ErrInvalidPshtFormat
+	}
+	if len(p.Inputs) != len(p.UnsignedTx.TxIn) ||
+		len(p.Outputs) != len(p.UnsignedTx.TxOut) {
+
+		return ErrInvalidPshtFormat
+	}
+	return checkUnsignedTx(p.UnsignedTx)
+}
+
+// IsComplete returns whether or not every input of the packet has been
+// finalized, meaning the signed transaction can be extracted.
+func (p *Packet) IsComplete() bool {
+	for i := range p.Inputs {
+		if !p.Inputs[i].isFinalized() {
+			return false
+		}
+	}
+	return true
+}
+
+// newUnknown returns an unknown key-value pair from a decoded key type, key
+// data and value.
+func newUnknown(keyType byte, keyData, value []byte) *Unknown {
+	key := make([]byte, 0, 1+len(keyData))
+	key = append(key, keyType)
+	key = append(key, keyData...)
+	return &Unknown{Key: key, Value: value}
+}
+
+// readKeyValue reads a key-value pair from a map.  A nil key type is returned
+// once the separator terminating the map is read.  The seen keys are used to
+// detect duplicates and are updated with the key which was read.
+func readKeyValue(r io.Reader, seen map[string]struct{}) (*byte, []byte, []byte, error) {
+	key, err := wire.ReadVarBytes(r, 0, MaxPshtKeyValueSize, "psht key")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if len(key) == 0 {
+		return nil, nil, nil, nil
+	}
+	if _, ok := seen[string(key)]; ok {
+		return nil, nil, nil, ErrDuplicateKey
+	}
+	seen[string(key)] = struct{}{}
+
+	value, err := wire.ReadVarBytes(r, 0, MaxPshtKeyValueSize, "psht value")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return &key[0], key[1:], value, nil
+}
+
+// writeKeyValue writes a key-value pair to a map.
+func writeKeyValue(w io.Writer, keyType byte, keyData, value []byte) error {
+	key := make([]byte, 0, 1+len(keyData))
+	key = append(key, keyType)
+	key = append(key, keyData...)
+	if err := wire.WriteVarBytes(w, 0, key); err != nil {
+		return err
+	}
+	return wire.WriteVarBytes(w, 0, value)
+}
+
+// writeUnknowns writes the unknown key-value pairs of a map.
+func writeUnknowns(w io.Writer, unknowns []*Unknown) error {
+	for _, u := range unknowns {
+		if err := wire.WriteVarBytes(w, 0, u.Key); err != nil {
+			return err
+		}
+		if err := wire.WriteVarBytes(w, 0, u.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeSeparator writes the zero length key which terminates a map.
+func writeSeparator(w io.Writer) error {
+	_, err := w.Write([]byte{0x00})
+	return err
+}
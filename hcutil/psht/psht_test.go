@@ -0,0 +1,251 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package psht_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/HcashOrg/hcd/chaincfg"
+	"github.com/HcashOrg/hcd/chaincfg/chainec"
+	"github.com/HcashOrg/hcd/chaincfg/chainhash"
+	"github.com/HcashOrg/hcd/hcutil"
+	"github.com/HcashOrg/hcd/hcutil/hdkeychain"
+	"github.com/HcashOrg/hcd/hcutil/psht"
+	"github.com/HcashOrg/hcd/txscript"
+	"github.com/HcashOrg/hcd/wire"
+)
+
+// testKey is a private key along with its serialized public key.
+type testKey struct {
+	priv   chainec.PrivateKey
+	pubKey []byte
+}
+
+// newTestKey returns a deterministic key created from the passed seed byte.
+func newTestKey(b byte) testKey {
+	priv, pub := chainec.Secp256k1.PrivKeyFromBytes(bytes.Repeat([]byte{b}, 32))
+	return testKey{priv: priv, pubKey: pub.SerializeCompressed()}
+}
+
+// TestRoundTrip ensures packets, including unknown key-value pairs, survive
+// serialization unchanged and that malformed packets are rejected.
+func TestRoundTrip(t *testing.T) {
+	p, err := psht.New([]*wire.OutPoint{
+		wire.NewOutPoint(&chainhash.Hash{0x01}, 0, wire.TxTreeRegular),
+	}, []*wire.TxOut{wire.NewTxOut(1e8, []byte{txscript.OP_TRUE})}, 0, 100)
+	if err != nil {
+		t.Fatalf("New: unexpected error: %v", err)
+	}
+	key := newTestKey(0x01)
+	if err := p.AddInPrevOut(0, 2e8, 0, []byte{txscript.OP_TRUE}); err != nil {
+		t.Fatalf("AddInPrevOut: unexpected error: %v", err)
+	}
+	if err := p.AddInSighashType(0, txscript.SigHashSingle); err != nil {
+		t.Fatalf("AddInSighashType: unexpected error: %v", err)
+	}
+	path := hdkeychain.Path{hdkeychain.HardenedKeyStart + 44, 0, 7}
+	if err := p.AddInBip32Derivation(0, key.pubKey, 0xdeadbeef, path); err != nil {
+		t.Fatalf("AddInBip32Derivation: unexpected error: %v", err)
+	}
+	if err := p.AddOutBip32Derivation(0, key.pubKey, 0xdeadbeef, path); err != nil {
+		t.Fatalf("AddOutBip32Derivation: unexpected error: %v", err)
+	}
+	p.Unknowns = []*psht.Unknown{{Key: []byte{0xf0, 0x01}, Value: []byte{0x02}}}
+	p.Inputs[0].Unknowns = []*psht.Unknown{{Key: []byte{0xf1}, Value: nil}}
+
+	// A signature with a different hash type than requested is rejected.
+	sig := []byte{0x30, byte(txscript.SigHashAll)}
+	if err := p.AddPartialSig(0, key.pubKey, sig); err != psht.ErrSighashMismatch {
+		t.Fatalf("AddPartialSig: unexpected error - got %v, want %v", err,
+			psht.ErrSighashMismatch)
+	}
+	sig[1] = byte(txscript.SigHashSingle)
+	if err := p.AddPartialSig(0, key.pubKey, sig); err != nil {
+		t.Fatalf("AddPartialSig: unexpected error: %v", err)
+	}
+
+	b64, err := p.B64Encode()
+	if err != nil {
+		t.Fatalf("B64Encode: unexpected error: %v", err)
+	}
+	decoded, err := psht.NewFromRawBytes(strings.NewReader(b64), true)
+	if err != nil {
+		t.Fatalf("NewFromRawBytes: unexpected error: %v", err)
+	}
+	var want, got bytes.Buffer
+	if err := p.Serialize(&want); err != nil {
+		t.Fatalf("Serialize: unexpected error: %v", err)
+	}
+	if err := decoded.Serialize(&got); err != nil {
+		t.Fatalf("Serialize: unexpected error: %v", err)
+	}
+	if !bytes.Equal(got.Bytes(), want.Bytes()) {
+		t.Fatalf("round trip mismatch\ngot  %x\nwant %x", got.Bytes(),
+			want.Bytes())
+	}
+	if !reflect.DeepEqual(decoded.Inputs[0].Bip32Derivation[0].Path, path) ||
+		decoded.UnsignedTx.Expiry != 100 {
+
+		t.Fatal("round trip did not preserve the packet contents")
+	}
+
+	// Packets with bad magic, trailing separators missing, or signature
+	// scripts are rejected.
+	raw := want.Bytes()
+	if _, err := psht.NewFromRawBytes(bytes.NewReader(raw[1:]), false); err == nil {
+		t.Fatal("NewFromRawBytes: unexpected success with bad magic")
+	}
+	if _, err := psht.NewFromRawBytes(bytes.NewReader(raw[:len(raw)-1]), false); err == nil {
+		t.Fatal("NewFromRawBytes: unexpected success when truncated")
+	}
+	signed := p.UnsignedTx.Copy()
+	signed.TxIn[0].SignatureScript = []byte{txscript.OP_TRUE}
+	if _, err := psht.NewFromUnsignedTx(signed); err != psht.ErrNonEmptySigScript {
+		t.Fatalf("NewFromUnsignedTx: unexpected error - got %v, want %v",
+			err, psht.ErrNonEmptySigScript)
+	}
+}
+
+// TestSignAndFinalize ensures packets signed independently by the parties to
+// a pay-to-pubkey-hash and a multi-signature pay-to-script-hash input can be
+// combined, finalized and extracted into a valid transaction.
+func TestSignAndFinalize(t *testing.T) {
+	params := &chaincfg.MainNetParams
+	keys := []testKey{newTestKey(0x01), newTestKey(0x02), newTestKey(0x03)}
+
+	// Input 0 pays to the hash of the first key, while input 1 pays to a
+	// 2-of-3 multi-signature script of all keys.
+	pkAddr, err := hcutil.NewAddressSecpPubKey(keys[0].pubKey, params)
+	if err != nil {
+		t.Fatalf("NewAddressSecpPubKey: unexpected error: %v", err)
+	}
+	p2pkhScript, err := txscript.PayToAddrScript(pkAddr.AddressPubKeyHash())
+	if err != nil {
+		t.Fatalf("PayToAddrScript: unexpected error: %v", err)
+	}
+	var multiSigAddrs []hcutil.Address
+	for _, key := range keys {
+		addr, err := hcutil.NewAddressSecpPubKey(key.pubKey, params)
+		if err != nil {
+			t.Fatalf("NewAddressSecpPubKey: unexpected error: %v", err)
+		}
+		multiSigAddrs = append(multiSigAddrs, addr)
+	}
+	redeemScript, err := txscript.MultiSigScript(multiSigAddrs, 2)
+	if err != nil {
+		t.Fatalf("MultiSigScript: unexpected error: %v", err)
+	}
+	shAddr, err := hcutil.NewAddressScriptHash(redeemScript, params)
+	if err != nil {
+		t.Fatalf("NewAddressScriptHash: unexpected error: %v", err)
+	}
+	p2shScript, err := txscript.PayToAddrScript(shAddr)
+	if err != nil {
+		t.Fatalf("PayToAddrScript: unexpected error: %v", err)
+	}
+
+	// Create and update the packet.
+	p, err := psht.New([]*wire.OutPoint{
+		wire.NewOutPoint(&chainhash.Hash{0x01}, 0, wire.TxTreeRegular),
+		wire.NewOutPoint(&chainhash.Hash{0x02}, 1, wire.TxTreeRegular),
+	}, []*wire.TxOut{wire.NewTxOut(3e8, p2pkhScript)}, 0, 0)
+	if err != nil {
+		t.Fatalf("New: unexpected error: %v", err)
+	}
+	prevScripts := [][]byte{p2pkhScript, p2shScript}
+	for i, pkScript := range prevScripts {
+		if err := p.AddInPrevOut(i, 2e8, 0, pkScript); err != nil {
+			t.Fatalf("AddInPrevOut: unexpected error: %v", err)
+		}
+	}
+	if err := p.AddInRedeemScript(0, redeemScript); err == nil {
+		t.Fatal("AddInRedeemScript: unexpected success for p2pkh input")
+	}
+	if err := p.AddInRedeemScript(1, redeemScript); err != nil {
+		t.Fatalf("AddInRedeemScript: unexpected error: %v", err)
+	}
+	if err := psht.Finalize(p, 1); err == nil {
+		t.Fatal("Finalize: unexpected success without signatures")
+	}
+
+	// Each signer signs a copy of the packet.
+	sign := func(i int, subScript []byte, key testKey) *psht.Packet {
+		raw, err := p.B64Encode()
+		if err != nil {
+			t.Fatalf("B64Encode: unexpected error: %v", err)
+		}
+		cp, err := psht.NewFromRawBytes(strings.NewReader(raw), true)
+		if err != nil {
+			t.Fatalf("NewFromRawBytes: unexpected error: %v", err)
+		}
+		sig, err := txscript.RawTxInSignature(cp.UnsignedTx, i, subScript,
+			txscript.SigHashAll, key.priv)
+		if err != nil {
+			t.Fatalf("RawTxInSignature: unexpected error: %v", err)
+		}
+		if err := cp.AddPartialSig(i, key.pubKey, sig); err != nil {
+			t.Fatalf("AddPartialSig: unexpected error: %v", err)
+		}
+		return cp
+	}
+	// The multi-signature signatures are deliberately added out of order.
+	signed := []*psht.Packet{
+		sign(0, p2pkhScript, keys[0]),
+		sign(1, redeemScript, keys[2]),
+		sign(1, redeemScript, keys[0]),
+	}
+
+	combined, err := psht.Combine(signed...)
+	if err != nil {
+		t.Fatalf("Combine: unexpected error: %v", err)
+	}
+	if _, err := psht.Extract(combined); err != psht.ErrIncomplete {
+		t.Fatalf("Extract: unexpected error - got %v, want %v", err,
+			psht.ErrIncomplete)
+	}
+	if err := psht.MaybeFinalizeAll(combined); err != nil {
+		t.Fatalf("MaybeFinalizeAll: unexpected error: %v", err)
+	}
+	if !combined.IsComplete() {
+		t.Fatal("IsComplete: finalized packet is not complete")
+	}
+	if len(combined.Inputs[1].PartialSigs) != 0 ||
+		combined.Inputs[1].RedeemScript != nil {
+
+		t.Fatal("Finalize: signing data was not removed")
+	}
+
+	tx, err := psht.Extract(combined)
+	if err != nil {
+		t.Fatalf("Extract: unexpected error: %v", err)
+	}
+	flags := txscript.ScriptBip16 | txscript.ScriptVerifyDERSignatures
+	for i, pkScript := range prevScripts {
+		if tx.TxIn[i].ValueIn != 2e8 {
+			t.Fatalf("Extract: input %d has value %d", i,
+				tx.TxIn[i].ValueIn)
+		}
+		vm, err := txscript.NewEngine(pkScript, tx, i, flags, 0, nil)
+		if err != nil {
+			t.Fatalf("NewEngine: unexpected error: %v", err)
+		}
+		if err := vm.Execute(); err != nil {
+			t.Fatalf("Execute: input %d failed: %v", i, err)
+		}
+	}
+
+	// Packets for other transactions can't be combined.
+	other, err := psht.New(nil, []*wire.TxOut{wire.NewTxOut(1, nil)}, 0, 0)
+	if err != nil {
+		t.Fatalf("New: unexpected error: %v", err)
+	}
+	if _, err := psht.Combine(p, other); err != psht.ErrTxMismatch {
+		t.Fatalf("Combine: unexpected error - got %v, want %v", err,
+			psht.ErrTxMismatch)
+	}
+}
@@ -0,0 +1,220 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package psht
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/HcashOrg/hcd/hcutil"
+	"github.com/HcashOrg/hcd/hcutil/hdkeychain"
+	"github.com/HcashOrg/hcd/txscript"
+)
+
+var (
+	// ErrInputFinalized describes an error where an input which already
+	// has a final signature script is updated or signed.
+	ErrInputFinalized = errors.New("input is already finalized")
+
+	// ErrSighashMismatch describes an error where the signature hash type
+	// of a signature does not match the one requested for the input.
+	ErrSighashMismatch = errors.New("signature hash type does not match " +
+		"the input")
+
+	// ErrConflictingSig describes an error where a different signature by
+	// the same public key is already present for an input.
+	ErrConflictingSig = errors.New("conflicting signature for public key")
+)
+
+// input returns the input at the passed index after ensuring it may still be
+// updated.
+func (p *Packet) input(i int) (*PInput, error) {
+	if i < 0 || i >= len(p.Inputs) {
+		return nil, ErrInvalidIndex
+	}
+	pi := &p.Inputs[i]
+	if pi.isFinalized() {
+		return nil, ErrInputFinalized
+	}
+	return pi, nil
+}
+
+// output returns the output at the passed index.
+func (p *Packet) output(i int) (*POutput, error) {
+	if i < 0 || i >= len(p.Outputs) {
+		return nil, ErrInvalidIndex
+	}
+	return &p.Outputs[i], nil
+}
+
+// AddInPrevOut sets the previous output spent by the input at index i.  The
+// value is also used as the witness value of the input when the signed
+// transaction is extracted.
+func (p *Packet) AddInPrevOut(i int, value int64, version uint16, pkScript []byte) error {
+	pi, err := p.input(i)
+	if err != nil {
+		return err
+	}
+	pi.PrevOut = &PrevOut{Value: value, Version: version, PkScript: pkScript}
+	return nil
+}
+
+// AddInSighashType sets the signature hash type signers must use for the input
+// at index i.  Inputs without a signature hash type are signed with
+// SigHashAll.
+func (p *Packet) AddInSighashType(i int, hashType txscript.SigHashType) error {
+	pi, err := p.input(i)
+	if err != nil {
+		return err
+	}
+	for _, ps := range pi.PartialSigs {
+		if sigHashType(ps.Signature) != hashType {
+			return ErrSighashMismatch
+		}
+	}
+	pi.SighashType = hashType
+	return nil
+}
+
+// AddInRedeemScript sets the redeem script of the input at index i.  When the
+// previous output of the input is known, it must be a pay-to-script-hash
+// output, optionally tagged with a stake opcode, which commits to the script.
+func (p *Packet) AddInRedeemScript(i int, redeemScript []byte) error {
+	pi, err := p.input(i)
+	if err != nil {
+		return err
+	}
+	if pi.PrevOut != nil {
+		class := scriptClass(pi.PrevOut.Version, pi.PrevOut.PkScript)
+		if class != txscript.ScriptHashTy {
+			return fmt.Errorf("previous output of input %d is not "+
+				"pay-to-script-hash", i)
+		}
+		pushes, err := txscript.PushedData(pi.PrevOut.PkScript)
+		if err != nil || len(pushes) != 1 ||
+			!bytes.Equal(pushes[0], hcutil.Hash160(redeemScript)) {
+
+			return fmt.Errorf("redeem script does not match the "+
+				"previous output of input %d", i)
+		}
+	}
+	pi.RedeemScript = redeemScript
+	return nil
+}
+
+// AddInBip32Derivation adds the key derivation of a public key used to sign
+// the input at index i.
+func (p *Packet) AddInBip32Derivation(i int, pubKey []byte, fingerprint uint32,
+	path hdkeychain.Path) error {
+
+	pi, err := p.input(i)
+	if err != nil {
+		return err
+	}
+	pi.Bip32Derivation = addBip32Derivation(pi.Bip32Derivation, pubKey,
+		fingerprint, path)
+	return nil
+}
+
+// AddOutRedeemScript sets the redeem script of the output at index i.
+func (p *Packet) AddOutRedeemScript(i int, redeemScript []byte) error {
+	po, err := p.output(i)
+	if err != nil {
+		return err
+	}
+	po.RedeemScript = redeemScript
+	return nil
+}
+
+// AddOutBip32Derivation adds the key derivation of a public key the output at
+// index i pays to.
+func (p *Packet) AddOutBip32Derivation(i int, pubKey []byte, fingerprint uint32,
+	path hdkeychain.Path) error {
+
+	po, err := p.output(i)
+	if err != nil {
+		return err
+	}
+	po.Bip32Derivation = addBip32Derivation(po.Bip32Derivation, pubKey,
+		fingerprint, path)
+	return nil
+}
+
+// AddPartialSig adds the signature by the passed public key to the input at
+// index i.  The signature must have the signature hash type of the input
+// appended.  Adding the same signature again has no effect, while adding a
+// different signature by the same key returns ErrConflictingSig.
+func (p *Packet) AddPartialSig(i int, pubKey, sig []byte) error {
+	pi, err := p.input(i)
+	if err != nil {
+		return err
+	}
+	if len(pubKey) == 0 || len(sig) == 0 {
+		return fmt.Errorf("empty public key or signature for input %d", i)
+	}
+	hashType := pi.SighashType
+	if hashType == 0 {
+		hashType = txscript.SigHashAll
+	}
+	if sigHashType(sig) != hashType {
+		return ErrSighashMismatch
+	}
+	if ps := pi.partialSig(pubKey); ps != nil {
+		if !bytes.Equal(ps.Signature, sig) {
+			return ErrConflictingSig
+		}
+		return nil
+	}
+	pi.PartialSigs = append(pi.PartialSigs, &PartialSig{
+		PubKey:    pubKey,
+		Signature: sig,
+	})
+	return nil
+}
+
+// addBip32Derivation adds or replaces the key derivation of the passed public
+// key.
+func addBip32Derivation(derivations []*Bip32Derivation, pubKey []byte,
+	fingerprint uint32, path hdkeychain.Path) []*Bip32Derivation {
+
+	d := &Bip32Derivation{
+		PubKey:               pubKey,
+		MasterKeyFingerprint: fingerprint,
+		Path:                 path,
+	}
+	for i, existing := range derivations {
+		if bytes.Equal(existing.PubKey, pubKey) {
+			derivations[i] = d
+			return derivations
+		}
+	}
+	return append(derivations, d)
+}
+
+// sigHashType returns the signature hash type appended to a signature.
+func sigHashType(sig []byte) txscript.SigHashType {
+	if len(sig) == 0 {
+		return 0
+	}
+	return txscript.SigHashType(sig[len(sig)-1])
+}
+
+// scriptClass returns the class of the passed script, or the class of its
+// subscript when it is tagged with a stake opcode.
+func scriptClass(version uint16, pkScript []byte) txscript.ScriptClass {
+	class := txscript.GetScriptClass(version, pkScript)
+	switch class {
+	case txscript.StakeSubmissionTy, txscript.StakeGenTy,
+		txscript.StakeRevocationTy, txscript.StakeSubChangeTy:
+
+		subClass, err := txscript.GetStakeOutSubclass(pkScript)
+		if err != nil {
+			return txscript.NonStandardTy
+		}
+		return subClass
+	}
+	return class
+}
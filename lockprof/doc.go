@@ -0,0 +1,32 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package lockprof implements a reader/writer mutex which profiles lock
+contention and detects locks held for too long.
+
+Overview
+
+RWMutex is a drop-in replacement for sync.RWMutex.  Once profiling is enabled
+with Enable, every acquisition records how long it waited for the lock and how
+long the lock was held.  The statistics are aggregated by the name of the lock,
+which is set with RWMutex.SetName, and the function and line the lock was
+acquired at, so the code paths responsible for contention stand out in the
+results of Stats.
+
+While profiling is disabled, which is the default, a lock only costs an atomic
+load per call over sync.RWMutex.  Since the call sites are only looked up while
+profiling, it is meant to be enabled when diagnosing contention rather than
+being left on.
+
+Deadlock Detection
+
+Enable takes a hold threshold.  A warning is logged whenever a lock is released
+after being held for longer than the threshold.  A watchdog goroutine also
+periodically checks the current holders of all profiled locks and warns once
+about each one which has held its lock for longer than the threshold without
+releasing it, so deadlocks are reported while they happen along with the
+function holding the lock.
+*/
+package lockprof
@@ -0,0 +1,358 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package lockprof
+
+import (
+	"fmt"
+	"path"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// enabled is set while lock contention is profiled.  It must only be
+	// used atomically.
+	enabled int32
+
+	// The following variables are protected by profMtx.
+	profMtx   sync.Mutex
+	threshold time.Duration
+	quit      chan struct{}
+	mutexes   = make(map[*RWMutex]struct{})
+	sites     = make(map[siteKey]*SiteStats)
+)
+
+// siteKey identifies the call site of an acquisition of a named lock.
+type siteKey struct {
+	lock string
+	site string
+}
+
+// SiteStats houses the statistics of the acquisitions of a lock at a call
+// site.
+type SiteStats struct {
+	// Lock is the name of the lock and Site the function and line the lock
+	// was acquired at.
+	Lock string
+	Site string
+
+	// Acquisitions is the number of times the lock was acquired and
+	// released again.
+	Acquisitions uint64
+
+	// TotalWait and MaxWait are the total and longest time it took to
+	// acquire the lock.
+	TotalWait time.Duration
+	MaxWait   time.Duration
+
+	// TotalHold and MaxHold are the total and longest time the lock was
+	// held for.
+	TotalHold time.Duration
+	MaxHold   time.Duration
+}
+
+// holder is an acquisition of a lock which is not released yet.
+type holder struct {
+	site   string
+	fn     uintptr
+	start  time.Time
+	wait   time.Duration
+	warned bool
+}
+
+// RWMutex is a reader/writer mutual exclusion lock which records how long it
+// takes to acquire and how long it is held at each call site while lock
+// contention is profiled.  It is a drop-in replacement for sync.RWMutex and
+// only costs an atomic load per call while profiling is disabled.  The zero
+// value is an unlocked mutex named "unnamed".
+//
+// The hold time of a read lock is attributed to the read lock acquired in the
+// same function as it is released in.  When there is no such read lock, it is
+// attributed to the oldest one.
+type RWMutex struct {
+	mu         sync.RWMutex
+	name       string
+	registered int32
+
+	heldMtx sync.Mutex
+	writer  *holder
+	readers []*holder
+}
+
+// SetName sets the name the statistics of the lock are recorded under.  It
+// must be called before the lock is used.
+func (m *RWMutex) SetName(name string) {
+	m.name = name
+}
+
+// lockName returns the name the statistics of the lock are recorded under.
+func (m *RWMutex) lockName() string {
+	if m.name == "" {
+		return "unnamed"
+	}
+	return m.name
+}
+
+// callSite returns the program counter of the passed number of frames above
+// the caller of callSite along with a description of its function and line.
+func callSite(skip int) (uintptr, string) {
+	var pcs [1]uintptr
+	if runtime.Callers(skip+2, pcs[:]) == 0 {
+		return 0, "unknown"
+	}
+	frame, _ := runtime.CallersFrames(pcs[:]).Next()
+	return frame.PC, fmt.Sprintf("%s (%s:%d)", path.Base(frame.Function),
+		path.Base(frame.File), frame.Line)
+}
+
+// funcEntry returns the entry address of the function the passed program
+// counter is in, which identifies the function.
+func funcEntry(pc uintptr) uintptr {
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return 0
+	}
+	return fn.Entry()
+}
+
+// acquired returns a holder for an acquisition of the lock at the caller of
+// the lock method which started at the passed time.  It registers the lock
+// with the watchdog on its first profiled acquisition.
+func (m *RWMutex) acquired(start time.Time) *holder {
+	pc, site := callSite(2)
+	if atomic.CompareAndSwapInt32(&m.registered, 0, 1) {
+		profMtx.Lock()
+		mutexes[m] = struct{}{}
+		profMtx.Unlock()
+	}
+	now := time.Now()
+	return &holder{
+		site:  site,
+		fn:    funcEntry(pc),
+		start: now,
+		wait:  now.Sub(start),
+	}
+}
+
+// released records the statistics of the passed released holder of the lock
+// and warns when it held the lock for longer than the threshold.
+func (m *RWMutex) released(h *holder) {
+	hold := time.Since(h.start)
+
+	profMtx.Lock()
+	name := m.lockName()
+	key := siteKey{lock: name, site: h.site}
+	stats, ok := sites[key]
+	if !ok {
+		stats = &SiteStats{Lock: name, Site: h.site}
+		sites[key] = stats
+	}
+	stats.Acquisitions++
+	stats.TotalWait += h.wait
+	if h.wait > stats.MaxWait {
+		stats.MaxWait = h.wait
+	}
+	stats.TotalHold += hold
+	if hold > stats.MaxHold {
+		stats.MaxHold = hold
+	}
+	warn := threshold > 0 && hold >= threshold
+	profMtx.Unlock()
+
+	if warn {
+		log.Warnf("Lock %s held for %v by %s after waiting %v for it",
+			name, hold, h.site, h.wait)
+	}
+}
+
+// Lock locks the mutex for writing.
+func (m *RWMutex) Lock() {
+	if atomic.LoadInt32(&enabled) == 0 {
+		m.mu.Lock()
+		return
+	}
+
+	start := time.Now()
+	m.mu.Lock()
+	h := m.acquired(start)
+	m.heldMtx.Lock()
+	m.writer = h
+	m.heldMtx.Unlock()
+}
+
+// Unlock unlocks the mutex for writing.
+func (m *RWMutex) Unlock() {
+	m.heldMtx.Lock()
+	h := m.writer
+	m.writer = nil
+	m.heldMtx.Unlock()
+	m.mu.Unlock()
+
+	if h != nil {
+		m.released(h)
+	}
+}
+
+// RLock locks the mutex for reading.
+func (m *RWMutex) RLock() {
+	if atomic.LoadInt32(&enabled) == 0 {
+		m.mu.RLock()
+		return
+	}
+
+	start := time.Now()
+	m.mu.RLock()
+	h := m.acquired(start)
+	m.heldMtx.Lock()
+	m.readers = append(m.readers, h)
+	m.heldMtx.Unlock()
+}
+
+// RUnlock undoes a single RLock call.
+func (m *RWMutex) RUnlock() {
+	if atomic.LoadInt32(&m.registered) == 0 {
+		m.mu.RUnlock()
+		return
+	}
+
+	pc, _ := callSite(1)
+	fn := funcEntry(pc)
+	var h *holder
+	m.heldMtx.Lock()
+	if len(m.readers) > 0 {
+		idx := 0
+		for i, reader := range m.readers {
+			if reader.fn == fn {
+				idx = i
+				break
+			}
+		}
+		h = m.readers[idx]
+		m.readers = append(m.readers[:idx], m.readers[idx+1:]...)
+	}
+	m.heldMtx.Unlock()
+	m.mu.RUnlock()
+
+	if h != nil {
+		m.released(h)
+	}
+}
+
+// checkHolders warns once about every holder of a registered lock which has
+// held it for longer than the threshold without releasing it, which may be
+// a deadlock.
+func checkHolders() {
+	profMtx.Lock()
+	locks := make([]*RWMutex, 0, len(mutexes))
+	for m := range mutexes {
+		locks = append(locks, m)
+	}
+	limit := threshold
+	profMtx.Unlock()
+
+	now := time.Now()
+	for _, m := range locks {
+		m.heldMtx.Lock()
+		holders := m.readers
+		if m.writer != nil {
+			holders = append([]*holder{m.writer}, holders...)
+		}
+		for _, h := range holders {
+			if h.warned || now.Sub(h.start) < limit {
+				continue
+			}
+			h.warned = true
+			log.Warnf("Lock %s held for %v by %s and not released "+
+				"yet -- possible deadlock", m.lockName(),
+				now.Sub(h.start), h.site)
+		}
+		m.heldMtx.Unlock()
+	}
+}
+
+// watchdog periodically checks for locks which are held for longer than the
+// threshold until the passed channel is closed.  It must be run as a
+// goroutine.
+func watchdog(interval time.Duration, quit chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			checkHolders()
+		case <-quit:
+			return
+		}
+	}
+}
+
+// Enable starts profiling the contention of all RWMutex locks.  A warning is
+// logged whenever a lock is held for longer than the passed threshold, both
+// when it is released and, for instance due to a deadlock, while it is still
+// held.  No warnings are logged when the threshold is zero.
+//
+// Only acquisitions made after profiling is enabled are recorded.
+func Enable(holdThreshold time.Duration) {
+	profMtx.Lock()
+	defer profMtx.Unlock()
+
+	threshold = holdThreshold
+	if quit == nil && holdThreshold > 0 {
+		quit = make(chan struct{})
+		interval := holdThreshold / 2
+		if interval < 100*time.Millisecond {
+			interval = 100 * time.Millisecond
+		}
+		go watchdog(interval, quit)
+	}
+	atomic.StoreInt32(&enabled, 1)
+}
+
+// Disable stops profiling lock contention and discards the recorded
+// statistics.
+func Disable() {
+	atomic.StoreInt32(&enabled, 0)
+
+	profMtx.Lock()
+	defer profMtx.Unlock()
+
+	if quit != nil {
+		close(quit)
+		quit = nil
+	}
+	threshold = 0
+	sites = make(map[siteKey]*SiteStats)
+}
+
+// Enabled returns whether lock contention is profiled.
+func Enabled() bool {
+	return atomic.LoadInt32(&enabled) != 0
+}
+
+// Stats returns the statistics of all call sites the profiled locks were
+// acquired and released at, ordered by the total time they were held.
+func Stats() []SiteStats {
+	profMtx.Lock()
+	stats := make([]SiteStats, 0, len(sites))
+	for _, s := range sites {
+		stats = append(stats, *s)
+	}
+	profMtx.Unlock()
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].TotalHold != stats[j].TotalHold {
+			return stats[i].TotalHold > stats[j].TotalHold
+		}
+		if stats[i].Lock != stats[j].Lock {
+			return stats[i].Lock < stats[j].Lock
+		}
+		return stats[i].Site < stats[j].Site
+	})
+	return stats
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package lockprof
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// holdWrite holds the passed lock for writing for the passed duration.
+func holdWrite(m *RWMutex, d time.Duration) {
+	m.Lock()
+	time.Sleep(d)
+	m.Unlock()
+}
+
+// holdRead holds the passed lock for reading for the passed duration.
+func holdRead(m *RWMutex, d time.Duration) {
+	m.RLock()
+	defer m.RUnlock()
+	time.Sleep(d)
+}
+
+// TestRWMutex ensures acquisitions are only recorded while profiling is
+// enabled, that they are attributed to the call sites which acquired the lock
+// and that locks held for longer than the threshold are detected before they
+// are released.
+func TestRWMutex(t *testing.T) {
+	defer Disable()
+
+	var m RWMutex
+	m.SetName("test")
+	holdWrite(&m, 0)
+	if stats := Stats(); len(stats) != 0 {
+		t.Fatalf("unexpected stats while disabled: %+v", stats)
+	}
+
+	Enable(time.Hour)
+	holdWrite(&m, 20*time.Millisecond)
+	holdWrite(&m, 0)
+
+	// Read locks which are released in another order than they were
+	// acquired in are attributed to the function releasing them.
+	m.RLock()
+	done := make(chan struct{})
+	go func() {
+		holdRead(&m, 10*time.Millisecond)
+		close(done)
+	}()
+	<-done
+	m.RUnlock()
+
+	stats := Stats()
+	if len(stats) != 3 {
+		t.Fatalf("unexpected number of call sites: got %d, want 3 -- %+v",
+			len(stats), stats)
+	}
+	write := stats[0]
+	if write.Lock != "test" || !strings.HasPrefix(write.Site,
+		"lockprof.holdWrite (rwmutex_test.go:") {
+
+		t.Fatalf("unexpected write lock call site: %+v", write)
+	}
+	if write.Acquisitions != 2 || write.MaxHold < 20*time.Millisecond ||
+		write.TotalHold < write.MaxHold {
+
+		t.Fatalf("unexpected write lock stats: %+v", write)
+	}
+	for _, s := range stats[1:] {
+		if s.Acquisitions != 1 {
+			t.Fatalf("unexpected read lock stats: %+v", s)
+		}
+		if strings.HasPrefix(s.Site, "lockprof.holdRead") &&
+			s.MaxHold < 10*time.Millisecond {
+
+			t.Fatalf("unexpected read lock stats: %+v", s)
+		}
+	}
+	if len(m.readers) != 0 {
+		t.Fatalf("unexpected outstanding readers: %d", len(m.readers))
+	}
+
+	// A lock which is held for longer than the threshold is detected while
+	// it is still held.
+	Disable()
+	Enable(time.Millisecond)
+	m.Lock()
+	time.Sleep(2 * time.Millisecond)
+	checkHolders()
+	m.heldMtx.Lock()
+	warned := m.writer.warned
+	m.heldMtx.Unlock()
+	if !warned {
+		t.Fatal("lock held for longer than the threshold not detected")
+	}
+	m.Unlock()
+}
@@ -0,0 +1,231 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package mempool
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/HcashOrg/hcd/chaincfg"
+	"github.com/HcashOrg/hcd/chaincfg/chainhash"
+	"github.com/HcashOrg/hcd/hcutil"
+)
+
+// queryPool repeatedly queries the independently locked parts of the passed
+// pool for the passed transactions until the done channel is closed.
+func queryPool(txPool *TxPool, txns []*hcutil.Tx, done <-chan struct{}) {
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		for _, tx := range txns {
+			txPool.HaveTransaction(tx.Hash())
+			txPool.IsOrphanInPool(tx.Hash())
+		}
+		txPool.Snapshot()
+		txPool.Count()
+		txPool.VoteHashesForBlock(chainhash.Hash{})
+	}
+}
+
+// BenchmarkProcessTransaction benchmarks accepting a transaction to the main
+// pool while a varying number of goroutines query the pool, which shows the
+// cost of the lock contention between acceptance and queries.
+func BenchmarkProcessTransaction(b *testing.B) {
+	for _, numQueriers := range []int{0, 4, 16} {
+		b.Run(fmt.Sprintf("queriers=%d", numQueriers), func(b *testing.B) {
+			harness, spendableOuts, err := newPoolHarness(
+				&chaincfg.MainNetParams)
+			if err != nil {
+				b.Fatalf("unable to create test pool: %v", err)
+			}
+			txPool := harness.txPool
+			chainedTxns, err := harness.CreateTxChain(spendableOuts[0],
+				100)
+			if err != nil {
+				b.Fatalf("unable to create transaction chain: %v",
+					err)
+			}
+
+			done := make(chan struct{})
+			var wg sync.WaitGroup
+			for i := 0; i < numQueriers; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					queryPool(txPool, chainedTxns, done)
+				}()
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				// Empty the pool once the whole chain was accepted.
+				idx := i % len(chainedTxns)
+				if idx == 0 && i != 0 {
+					b.StopTimer()
+					txPool.RemoveTransaction(chainedTxns[0], true)
+					b.StartTimer()
+				}
+
+				_, err := txPool.ProcessTransaction(chainedTxns[idx],
+					false, false, true)
+				if err != nil {
+					b.Fatalf("ProcessTransaction: failed to accept "+
+						"tx %v: %v", chainedTxns[idx].Hash(), err)
+				}
+			}
+			b.StopTimer()
+			close(done)
+			wg.Wait()
+		})
+	}
+}
+
+// BenchmarkHaveTransactionParallel benchmarks querying the pool from multiple
+// goroutines while transactions are continuously being accepted and removed.
+func BenchmarkHaveTransactionParallel(b *testing.B) {
+	harness, spendableOuts, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		b.Fatalf("unable to create test pool: %v", err)
+	}
+	txPool := harness.txPool
+	chainedTxns, err := harness.CreateTxChain(spendableOuts[0], 20)
+	if err != nil {
+		b.Fatalf("unable to create transaction chain: %v", err)
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			for _, tx := range chainedTxns {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				txPool.ProcessTransaction(tx, false, false, true)
+			}
+			txPool.RemoveTransaction(chainedTxns[0], true)
+		}
+	}()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		var i int
+		for pb.Next() {
+			txPool.HaveTransaction(chainedTxns[i%len(chainedTxns)].Hash())
+			i++
+		}
+	})
+	b.StopTimer()
+	close(done)
+	wg.Wait()
+}
+
+// TestConcurrentStress processes, removes and queries independent chains of
+// transactions from multiple goroutines at once and ensures the pool ends up
+// in the expected state.  The outcome does not depend on the interleaving of
+// the goroutines, so the test is deterministic while still exercising the
+// locking of the pool when run with the race detector.
+func TestConcurrentStress(t *testing.T) {
+	t.Parallel()
+
+	harness, spendableOuts, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	txPool := harness.txPool
+
+	const numChains = 8
+	chainLen := uint32(25)
+	if testing.Short() {
+		chainLen = 5
+	}
+
+	// Split the spendable output into one output per chain.
+	splitTx, err := harness.CreateSignedTxWithFee(spendableOuts, numChains,
+		10000)
+	if err != nil {
+		t.Fatalf("unable to create split transaction: %v", err)
+	}
+	_, err = txPool.ProcessTransaction(splitTx, false, false, true)
+	if err != nil {
+		t.Fatalf("ProcessTransaction: failed to accept split tx: %v", err)
+	}
+	chains := make([][]*hcutil.Tx, numChains)
+	var allTxns []*hcutil.Tx
+	for i := range chains {
+		chains[i], err = harness.CreateTxChain(
+			txOutToSpendableOut(splitTx, uint32(i)), chainLen)
+		if err != nil {
+			t.Fatalf("unable to create transaction chain: %v", err)
+		}
+		allTxns = append(allTxns, chains[i]...)
+	}
+
+	done := make(chan struct{})
+	var queriers sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		queriers.Add(1)
+		go func() {
+			defer queriers.Done()
+			queryPool(txPool, allTxns, done)
+		}()
+	}
+
+	// Process every chain from its own goroutine and remove the odd chains
+	// again once they were accepted.
+	errs := make(chan error, numChains)
+	var workers sync.WaitGroup
+	for i := range chains {
+		workers.Add(1)
+		go func(i int) {
+			defer workers.Done()
+			for _, tx := range chains[i] {
+				_, err := txPool.ProcessTransaction(tx, false,
+					false, true)
+				if err != nil {
+					errs <- fmt.Errorf("chain %d: failed to "+
+						"accept tx %v: %v", i, tx.Hash(), err)
+					return
+				}
+			}
+			if i%2 == 1 {
+				txPool.RemoveTransaction(chains[i][0], true)
+			}
+		}(i)
+	}
+	workers.Wait()
+	close(done)
+	queriers.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+
+	wantCount := 1 + numChains/2*int(chainLen)
+	if count := txPool.Count(); count != wantCount {
+		t.Fatalf("Count: got %d, want %d", count, wantCount)
+	}
+	if count := txPool.Snapshot().Count(); count != wantCount {
+		t.Fatalf("Snapshot: got %d txns, want %d", count, wantCount)
+	}
+	for i, chain := range chains {
+		for _, tx := range chain {
+			if got, want := txPool.HaveTransaction(tx.Hash()), i%2 == 0; got != want {
+				t.Fatalf("HaveTransaction(%v) of chain %d: got %v, "+
+					"want %v", tx.Hash(), i, got, want)
+			}
+		}
+	}
+}
@@ -12,9 +12,11 @@ import (
 	"fmt"
 	"math"
 	"math/big"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
+	"unsafe"
 
 	"github.com/HcashOrg/hcd/blockchain"
 	"github.com/HcashOrg/hcd/blockchain/indexers"
@@ -22,6 +24,7 @@ import (
 	"github.com/HcashOrg/hcd/chaincfg"
 	"github.com/HcashOrg/hcd/chaincfg/chainhash"
 	"github.com/HcashOrg/hcd/hcjson"
+	"github.com/HcashOrg/hcd/lockprof"
 	"github.com/HcashOrg/hcd/mining"
 	"github.com/HcashOrg/hcd/txscript"
 	"github.com/HcashOrg/hcd/wire"
@@ -39,6 +42,14 @@ const (
 	// transaction to be considered high priority.
 	MinHighPriority = hcutil.AtomsPerCoin * 144.0 / 250
 
+	// DefaultMempoolExpiry is the default maximum amount of time a
+	// transaction may remain in the memory pool before it is evicted.
+	DefaultMempoolExpiry = time.Hour * 72
+
+	// DefaultMaxMempoolSize is the default maximum amount of memory in bytes
+	// the transactions in the memory pool may occupy.
+	DefaultMaxMempoolSize = 300 * 1000 * 1000
+
 	// mempoolHeight is the height used for the "block" height field of the
 	// contextual transaction information provided in a transaction view.
 	mempoolHeight = 0x7fffffff
@@ -68,6 +79,44 @@ const (
 	// maxNullDataOutputs is the maximum number of OP_RETURN null data
 	// pushes in a transaction, after which it is considered non-standard.
 	maxNullDataOutputs = 4
+
+	// maxPackageCount is the maximum number of transactions allowed in a
+	// package submitted via ProcessPackage.
+	maxPackageCount = 25
+
+	// rollingFeeHalfLife is the half-life of the dynamic minimum relay fee
+	// that is raised when transactions are evicted due to the pool
+	// exceeding its maximum size.  The fee decays faster when the pool is
+	// less than half and a quarter full.
+	rollingFeeHalfLife = time.Hour * 12
+
+	// trimTargetPercent is the percentage of the maximum size the pool is
+	// trimmed down to once it exceeds its maximum size.  Trimming below the
+	// maximum evicts transactions in batches, so the pool is not trimmed
+	// again for every transaction accepted while it is full.
+	trimTargetPercent = 95
+)
+
+var (
+	// txDescOverhead is the memory used by a pool entry excluding its
+	// inputs and outputs.  It consists of the entry itself, the wrapped
+	// transaction and its key and pointer in the pool map.
+	txDescOverhead = int64(unsafe.Sizeof(TxDesc{})) +
+		int64(unsafe.Sizeof(hcutil.Tx{})) +
+		int64(unsafe.Sizeof(wire.MsgTx{})) +
+		chainhash.HashSize + int64(unsafe.Sizeof(uintptr(0)))
+
+	// txInOverhead is the memory used by each transaction input excluding
+	// its signature script.  It consists of the input itself, its pointer
+	// in the transaction and its entry in the spent outpoints map.
+	txInOverhead = int64(unsafe.Sizeof(wire.TxIn{})) +
+		int64(unsafe.Sizeof(wire.OutPoint{})) +
+		2*int64(unsafe.Sizeof(uintptr(0)))
+
+	// txOutOverhead is the memory used by each transaction output
+	// excluding its public key script.
+	txOutOverhead = int64(unsafe.Sizeof(wire.TxOut{})) +
+		int64(unsafe.Sizeof(uintptr(0)))
 )
 
 // VoteTx is a struct describing a block vote (SSGen).
@@ -125,6 +174,11 @@ type Config struct {
 	// SigCache defines a signature cache to use.
 	SigCache *txscript.SigCache
 
+	// ScriptCache defines a script verification cache to use.  Inputs
+	// verified when accepting transactions are added to it so they do not
+	// need to be verified again when their block is connected.
+	ScriptCache *txscript.ScriptCache
+
 	// AddrIndex defines the optional address index instance to use for
 	// indexing the unconfirmed transactions in the memory pool.
 	// This can be nil if the address index is not enabled.
@@ -176,6 +230,25 @@ type Policy struct {
 	// considered a non-zero fee.
 	MinRelayTxFee hcutil.Amount
 
+	// DustRelayFee defines the fee rate in HC/kB used to determine the
+	// threshold below which transaction outputs are considered dust and
+	// therefore non-standard.  The threshold is calculated per output
+	// script type based on the typical size of the input which redeems it.
+	DustRelayFee hcutil.Amount
+
+	// MempoolExpiry is the maximum amount of time a transaction may remain
+	// in the memory pool.  Transactions older than this, along with any
+	// transactions which redeem them, are evicted by ExpireOldTx.  A value
+	// of zero disables time based expiration.
+	MempoolExpiry time.Duration
+
+	// MaxMempoolSize is the maximum amount of memory in bytes the
+	// transactions in the memory pool may occupy.  Once exceeded, regular
+	// transactions are evicted in order of lowest ancestor fee rate and the
+	// dynamic minimum relay fee is raised accordingly.  A value of zero
+	// disables the limit.
+	MaxMempoolSize int64
+
 	// AllowOldVotes defines whether or not votes on old blocks will be
 	// admitted and relayed.
 	AllowOldVotes bool
@@ -189,6 +262,16 @@ type Policy struct {
 	StandardVerifyFlags func() (txscript.ScriptFlags, error)
 }
 
+// AcceptHook defines the signature of an external policy hook which is
+// invoked for every transaction the memory pool is about to accept.  The hook
+// is provided with the transaction, its stake type and the utxo view which
+// contains all of the inputs it references.  Returning a non-nil error causes
+// the transaction to be rejected.
+//
+// Hooks are invoked with the mempool lock held, so they MUST NOT call back
+// into the pool and SHOULD return quickly.
+type AcceptHook func(tx *hcutil.Tx, txType stake.TxType, utxoView *blockchain.UtxoViewpoint) error
+
 // TxDesc is a descriptor containing a transaction in the mempool along with
 // additional metadata.
 type TxDesc struct {
@@ -197,22 +280,57 @@ type TxDesc struct {
 	// StartingPriority is the priority of the transaction when it was added
 	// to the pool.
 	StartingPriority float64
+
+	// usage is the estimated amount of memory in bytes used by the entry.
+	usage int64
+
+	// feeRate is the cached fee rate of the entry combined with all of its
+	// unconfirmed ancestors in the pool.  It is only valid when haveFeeRate
+	// is set and is invalidated when an ancestor enters or leaves the pool.
+	feeRate     float64
+	haveFeeRate bool
 }
 
 // TxPool is used as a source of transactions that need to be mined into blocks
 // and relayed to other peers.  It is safe for concurrent access from multiple
 // peers.
+//
+// The state of the pool is split into independently locked parts so that
+// queries of one part do not wait for updates of another:
+//
+//   - mtx protects the main pool and everything not listed below
+//   - orphanMtx protects the orphan pool
+//   - votesMtx protects the votes on blocks
+//   - snapshotMtx protects setting the cached snapshot of the main pool
+//
+// The locks must be acquired in the order listed above, so a lock must never
+// be acquired while holding a lock listed after it.  For instance, accepting
+// a transaction holds mtx while briefly acquiring orphanMtx and votesMtx, but
+// orphan and vote queries only acquire their own lock.
 type TxPool struct {
 	// The following variables must only be used atomically.
 	lastUpdated int64 // last time pool was updated.
 
-	mtx           sync.RWMutex
-	cfg           Config
-	pool          map[chainhash.Hash]*TxDesc
+	mtx       lockprof.RWMutex
+	cfg       Config
+	pool      map[chainhash.Hash]*TxDesc
+	addrindex map[string]map[chainhash.Hash]struct{} // maps address to txs
+	outpoints map[wire.OutPoint]*hcutil.Tx
+
+	// The orphan pool is protected by orphanMtx.
+	orphanMtx     sync.RWMutex
 	orphans       map[chainhash.Hash]*hcutil.Tx
 	orphansByPrev map[chainhash.Hash]map[chainhash.Hash]*hcutil.Tx
-	addrindex     map[string]map[chainhash.Hash]struct{} // maps address to txs
-	outpoints     map[wire.OutPoint]*hcutil.Tx
+
+	// snapshot caches the snapshot returned by Snapshot until the pool
+	// changes.  It is cleared with the mempool lock held for writes, and
+	// set with the mempool lock held for reads along with snapshotMtx.
+	snapshotMtx sync.Mutex
+	snapshot    *mining.TxSourceSnapshot
+
+	// acceptHooks houses the external policy hooks registered via
+	// RegisterAcceptHook.  It is protected by the mempool lock.
+	acceptHooks []AcceptHook
 
 	// Votes on blocks.
 	votesMtx sync.RWMutex
@@ -220,6 +338,30 @@ type TxPool struct {
 
 	pennyTotal    float64 // exponentially decaying total for penny spends.
 	lastPennyUnix int64   // unix time of last ``penny spend''
+
+	// totalUsage is the estimated amount of memory in bytes used by all of
+	// the transactions in the pool.
+	totalUsage int64
+
+	// rollingMinFee is the dynamic minimum relay fee in atoms/kB which is
+	// raised when transactions are evicted due to the pool exceeding its
+	// maximum size.  It decays exponentially from the time it was last
+	// updated.
+	rollingMinFee        float64
+	lastRollingFeeUpdate time.Time
+
+	// deltas houses the fee and priority adjustments applied with
+	// PrioritiseTransaction, keyed by transaction hash.  Adjustments may be
+	// applied before the transaction enters the pool and are dropped when
+	// it leaves the pool.  It is protected by the mempool lock.
+	deltas map[chainhash.Hash]txDelta
+}
+
+// txDelta houses the adjustments to the fee and priority of a transaction
+// which are used when ordering it for inclusion in a block.
+type txDelta struct {
+	fee      int64
+	priority float64
 }
 
 // insertVote inserts a vote into the map of block votes.
@@ -314,7 +456,7 @@ var _ mining.TxSource = (*TxPool)(nil)
 // removeOrphan is the internal function which implements the public
 // RemoveOrphan.  See the comment for RemoveOrphan for more details.
 //
-// This function MUST be called with the mempool lock held (for writes).
+// This function MUST be called with the orphan lock held (for writes).
 func (mp *TxPool) removeOrphan(txHash *chainhash.Hash) {
 
 	// Nothing to do if passed tx is not an orphan.
@@ -345,15 +487,15 @@ func (mp *TxPool) removeOrphan(txHash *chainhash.Hash) {
 //
 // This function is safe for concurrent access.
 func (mp *TxPool) RemoveOrphan(txHash *chainhash.Hash) {
-	mp.mtx.Lock()
+	mp.orphanMtx.Lock()
 	mp.removeOrphan(txHash)
-	mp.mtx.Unlock()
+	mp.orphanMtx.Unlock()
 }
 
 // limitNumOrphans limits the number of orphan transactions by evicting a random
 // orphan if adding a new one would cause it to overflow the max allowed.
 //
-// This function MUST be called with the mempool lock held (for writes).
+// This function MUST be called with the orphan lock held (for writes).
 func (mp *TxPool) limitNumOrphans() error {
 	if len(mp.orphans)+1 > mp.cfg.Policy.MaxOrphanTxs &&
 		mp.cfg.Policy.MaxOrphanTxs > 0 {
@@ -391,7 +533,7 @@ func (mp *TxPool) limitNumOrphans() error {
 
 // addOrphan adds an orphan transaction to the orphan pool.
 //
-// This function MUST be called with the mempool lock held (for writes).
+// This function MUST be called with the orphan lock held (for writes).
 func (mp *TxPool) addOrphan(tx *hcutil.Tx) {
 	// Limit the number orphan transactions to prevent memory exhaustion.  A
 	// random orphan is evicted to make room if needed.
@@ -413,7 +555,7 @@ func (mp *TxPool) addOrphan(tx *hcutil.Tx) {
 
 // maybeAddOrphan potentially adds an orphan to the orphan pool.
 //
-// This function MUST be called with the mempool lock held (for writes).
+// This function is safe for concurrent access.
 func (mp *TxPool) maybeAddOrphan(tx *hcutil.Tx) error {
 	// Ignore orphan transactions that are too large.  This helps avoid
 	// a memory exhaustion attack based on sending a lot of really large
@@ -434,7 +576,9 @@ func (mp *TxPool) maybeAddOrphan(tx *hcutil.Tx) error {
 	}
 
 	// Add the orphan if the none of the above disqualified it.
+	mp.orphanMtx.Lock()
 	mp.addOrphan(tx)
+	mp.orphanMtx.Unlock()
 
 	return nil
 }
@@ -467,7 +611,7 @@ func (mp *TxPool) IsTransactionInPool(hash *chainhash.Hash) bool {
 // isOrphanInPool returns whether or not the passed transaction already exists
 // in the orphan pool.
 //
-// This function MUST be called with the mempool lock held (for reads).
+// This function MUST be called with the orphan lock held (for reads).
 func (mp *TxPool) isOrphanInPool(hash *chainhash.Hash) bool {
 	if _, exists := mp.orphans[*hash]; exists {
 		return true
@@ -482,9 +626,9 @@ func (mp *TxPool) isOrphanInPool(hash *chainhash.Hash) bool {
 // This function is safe for concurrent access.
 func (mp *TxPool) IsOrphanInPool(hash *chainhash.Hash) bool {
 	// Protect concurrent access.
-	mp.mtx.RLock()
+	mp.orphanMtx.RLock()
 	inPool := mp.isOrphanInPool(hash)
-	mp.mtx.RUnlock()
+	mp.orphanMtx.RUnlock()
 
 	return inPool
 }
@@ -492,9 +636,17 @@ func (mp *TxPool) IsOrphanInPool(hash *chainhash.Hash) bool {
 // haveTransaction returns whether or not the passed transaction already exists
 // in the main pool or in the orphan pool.
 //
-// This function MUST be called with the mempool lock held (for reads).
+// This function MUST be called with the mempool lock held (for reads).  It
+// acquires the orphan lock itself.
 func (mp *TxPool) haveTransaction(hash *chainhash.Hash) bool {
-	return mp.isTransactionInPool(hash) || mp.isOrphanInPool(hash)
+	if mp.isTransactionInPool(hash) {
+		return true
+	}
+
+	mp.orphanMtx.RLock()
+	inPool := mp.isOrphanInPool(hash)
+	mp.orphanMtx.RUnlock()
+	return inPool
 }
 
 // HaveTransaction returns whether or not the passed transaction already exists
@@ -572,6 +724,10 @@ func (mp *TxPool) removeTransaction(tx *hcutil.Tx, removeRedeemers bool) {
 			delete(mp.outpoints, txIn.PreviousOutPoint)
 		}
 		delete(mp.pool, *txHash)
+		delete(mp.deltas, *txHash)
+		mp.invalidateFeeRates(tx)
+		mp.totalUsage -= txDesc.usage
+		mp.snapshot = nil
 		atomic.StoreInt64(&mp.lastUpdated, time.Now().Unix())
 	}
 }
@@ -620,19 +776,27 @@ func (mp *TxPool) addTransaction(utxoView *blockchain.UtxoViewpoint,
 	// Add the transaction to the pool and mark the referenced outpoints
 	// as spent by the pool.
 	msgTx := tx.MsgTx()
+	usage := txMemoryUsage(msgTx)
+	delta := mp.deltas[*tx.Hash()]
 	mp.pool[*tx.Hash()] = &TxDesc{
 		TxDesc: mining.TxDesc{
-			Tx:     tx,
-			Type:   txType,
-			Added:  time.Now(),
-			Height: height,
-			Fee:    fee,
+			Tx:            tx,
+			Type:          txType,
+			Added:         time.Now(),
+			Height:        height,
+			Fee:           fee,
+			FeeDelta:      delta.fee,
+			PriorityDelta: delta.priority,
 		},
 		StartingPriority: CalcPriority(msgTx, utxoView, height),
+		usage:            usage,
 	}
+	mp.totalUsage += usage
 	for _, txIn := range msgTx.TxIn {
 		mp.outpoints[txIn.PreviousOutPoint] = tx
 	}
+	mp.invalidateFeeRates(tx)
+	mp.snapshot = nil
 	atomic.StoreInt64(&mp.lastUpdated, time.Now().Unix())
 
 	// Add unconfirmed address index entries associated with the transaction
@@ -645,6 +809,45 @@ func (mp *TxPool) addTransaction(utxoView *blockchain.UtxoViewpoint,
 	}
 }
 
+// RegisterAcceptHook registers the passed external policy hook to be invoked
+// for every transaction that passes the built-in acceptance rules, before its
+// signatures are verified.  Hooks run in the order they were registered and
+// the first error returned rejects the transaction.  Errors which are not
+// already a RuleError are rejected as non-standard.
+//
+// This allows operators to enforce custom policies, such as limits on the
+// size of data carrier outputs or address blacklists, without modifying the
+// mempool itself.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) RegisterAcceptHook(hook AcceptHook) {
+	mp.mtx.Lock()
+	mp.acceptHooks = append(mp.acceptHooks, hook)
+	mp.mtx.Unlock()
+}
+
+// runAcceptHooks invokes all registered external policy hooks for the passed
+// transaction and returns the first error encountered, if any, converted to a
+// RuleError.
+//
+// This function MUST be called with the mempool lock held (for reads).
+func (mp *TxPool) runAcceptHooks(tx *hcutil.Tx, txType stake.TxType, utxoView *blockchain.UtxoViewpoint) error {
+	for _, hook := range mp.acceptHooks {
+		err := hook(tx, txType, utxoView)
+		if err == nil {
+			continue
+		}
+		if rerr, ok := err.(RuleError); ok {
+			return rerr
+		}
+		str := fmt.Sprintf("transaction %v rejected by policy hook: %v",
+			tx.Hash(), err)
+		return txRuleError(wire.RejectNonstandard, str)
+	}
+
+	return nil
+}
+
 // checkPoolDoubleSpend checks whether or not the passed transaction is
 // attempting to spend coins already spent by other transactions in the pool.
 // Note it does not check for double spends against transactions already in the
@@ -762,9 +965,43 @@ func (mp *TxPool) FetchTransaction(txHash *chainhash.Hash, includeRecentBlock bo
 	return nil, fmt.Errorf("transaction is not in the pool")
 }
 
-// maybeAcceptTransaction is the internal function which implements the public
-// MaybeAcceptTransaction.  See the comment for MaybeAcceptTransaction for
-// more details.
+// FetchTxDesc returns the descriptor of the requested transaction from the
+// transaction pool.  This only fetches from the main transaction pool and does
+// not include orphans.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) FetchTxDesc(txHash *chainhash.Hash) (*TxDesc, error) {
+	// Protect concurrent access.
+	mp.mtx.RLock()
+	txDesc, exists := mp.pool[*txHash]
+	mp.mtx.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("transaction is not in the pool")
+	}
+	return txDesc, nil
+}
+
+// validatedTx houses a transaction which passed validation along with the
+// details needed to add it to the pool.
+type validatedTx struct {
+	tx       *hcutil.Tx
+	txType   stake.TxType
+	utxoView *blockchain.UtxoViewpoint
+	height   int64
+	fee      int64
+}
+
+// validateTransaction checks whether the passed transaction may be accepted
+// into the pool without modifying the pool, except for the free transaction
+// rate limiter.  It returns the validated transaction, or the hashes of its
+// missing parents when it is an orphan.
+//
+// When the transaction is a member of the passed package, which may be nil,
+// the outputs of the members validated before it are available to it, it may
+// not spend the same outputs as them and the minimum relay fee, priority and
+// free transaction rate limiting checks are skipped so the caller can apply
+// them to the aggregate fee of the package instead.
 //
 // This function MUST be called with the mempool lock held (for writes).
 // hcd - TODO
@@ -772,15 +1009,20 @@ func (mp *TxPool) FetchTransaction(txHash *chainhash.Hash, includeRecentBlock bo
 // so that we can easily pick different stake tx types from the mempool later.
 // This should probably be done at the bottom using "IsSStx" etc functions.
 // It should also set the hcutil tree type for the tx as well.
-func (mp *TxPool) maybeAcceptTransaction(tx *hcutil.Tx, isNew, rateLimit, allowHighFees bool) ([]*chainhash.Hash, error) {
+func (mp *TxPool) validateTransaction(tx *hcutil.Tx, isNew, rateLimit, allowHighFees bool, pkg *txPackage) (*validatedTx, []*chainhash.Hash, error) {
 	msgTx := tx.MsgTx()
 	txHash := tx.Hash()
+	deferFeeChecks := pkg != nil
+
 	// Don't accept the transaction if it already exists in the pool.  This
-	// applies to orphan transactions as well.  This check is intended to
-	// be a quick check to weed out duplicates.
-	if mp.haveTransaction(txHash) {
+	// applies to orphan transactions as well, except for members of a
+	// package which may be accepted once their parents are provided by the
+	// package.  This check is intended to be a quick check to weed out
+	// duplicates.
+	if mp.isTransactionInPool(txHash) ||
+		(pkg == nil && mp.haveTransaction(txHash)) {
 		str := fmt.Sprintf("already have transaction %v", txHash)
-		return nil, txRuleError(wire.RejectDuplicate, str)
+		return nil, nil, txRuleError(wire.RejectDuplicate, str)
 	}
 
 	// Perform preliminary sanity checks on the transaction.  This makes
@@ -789,16 +1031,16 @@ func (mp *TxPool) maybeAcceptTransaction(tx *hcutil.Tx, isNew, rateLimit, allowH
 	err := blockchain.CheckTransactionSanity(msgTx, mp.cfg.ChainParams)
 	if err != nil {
 		if cerr, ok := err.(blockchain.RuleError); ok {
-			return nil, chainRuleError(cerr)
+			return nil, nil, chainRuleError(cerr)
 		}
-		return nil, err
+		return nil, nil, err
 	}
 
 	// A standalone transaction must not be a coinbase transaction.
 	if blockchain.IsCoinBase(tx) {
 		str := fmt.Sprintf("transaction %v is an individual coinbase",
 			txHash)
-		return nil, txRuleError(wire.RejectInvalid, str)
+		return nil, nil, txRuleError(wire.RejectInvalid, str)
 	}
 
 	// Don't accept transactions with a lock time after the maximum int32
@@ -808,7 +1050,7 @@ func (mp *TxPool) maybeAcceptTransaction(tx *hcutil.Tx, isNew, rateLimit, allowH
 // 	if msgTx.LockTime > math.MaxInt32 {
 // 		str := fmt.Sprintf("transaction %v has a lock time after "+
 // 			"2038 which is not accepted yet", txHash)
-// 		return nil, txRuleError(wire.RejectNonstandard, str)
+// 		return nil, nil, txRuleError(wire.RejectNonstandard, str)
 // 	}
 
 	// Get the current height of the main chain.  A standalone transaction
@@ -832,7 +1074,7 @@ func (mp *TxPool) maybeAcceptTransaction(tx *hcutil.Tx, isNew, rateLimit, allowH
 	medianTime := mp.cfg.PastMedianTime()
 	if !mp.cfg.Policy.RelayNonStd {
 		err := checkTransactionStandard(tx, txType, nextBlockHeight,
-			medianTime, mp.cfg.Policy.MinRelayTxFee,
+			medianTime, mp.cfg.Policy.DustRelayFee,
 			mp.cfg.Policy.MaxTxVersion)
 		if err != nil {
 			// Attempt to extract a reject code from the error so
@@ -844,7 +1086,7 @@ func (mp *TxPool) maybeAcceptTransaction(tx *hcutil.Tx, isNew, rateLimit, allowH
 			}
 			str := fmt.Sprintf("transaction %v is not standard: %v",
 				txHash, err)
-			return nil, txRuleError(rejectCode, str)
+			return nil, nil, txRuleError(rejectCode, str)
 		}
 	}
 
@@ -855,14 +1097,14 @@ func (mp *TxPool) maybeAcceptTransaction(tx *hcutil.Tx, isNew, rateLimit, allowH
 		if err != nil {
 			// This is an unexpected error so don't turn it into a
 			// rule error.
-			return nil, err
+			return nil, nil, err
 		}
 
 		if msgTx.TxOut[0].Value < sDiff {
 			str := fmt.Sprintf("transaction %v has not enough funds "+
 				"to meet stake difficuly (ticket diff %v < next diff %v)",
 				txHash, msgTx.TxOut[0].Value, sDiff)
-			return nil, txRuleError(wire.RejectInsufficientFee, str)
+			return nil, nil, txRuleError(wire.RejectInsufficientFee, str)
 		}
 	}
 
@@ -882,7 +1124,7 @@ func (mp *TxPool) maybeAcceptTransaction(tx *hcutil.Tx, isNew, rateLimit, allowH
 						"with more than %v ssgens",
 						msgTx.TxIn[1].PreviousOutPoint,
 						maxSSGensDoubleSpends)
-					return nil, txRuleError(wire.RejectDuplicate, str)
+					return nil, nil, txRuleError(wire.RejectDuplicate, str)
 				}
 			}
 		}
@@ -895,7 +1137,7 @@ func (mp *TxPool) maybeAcceptTransaction(tx *hcutil.Tx, isNew, rateLimit, allowH
 						str := fmt.Sprintf("transaction %v in the pool "+
 							" as a ssrtx. Only one ssrtx allowed.",
 							msgTx.TxIn[0].PreviousOutPoint)
-						return nil, txRuleError(wire.RejectDuplicate, str)
+						return nil, nil, txRuleError(wire.RejectDuplicate, str)
 					}
 				}
 			}
@@ -911,7 +1153,12 @@ func (mp *TxPool) maybeAcceptTransaction(tx *hcutil.Tx, isNew, rateLimit, allowH
 		// which examines the actual spend data and prevents double spends.
 		err = mp.checkPoolDoubleSpend(tx, txType)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
+		}
+		if pkg != nil {
+			if err := pkg.checkDoubleSpend(tx); err != nil {
+				return nil, nil, err
+			}
 		}
 	}
 
@@ -919,7 +1166,7 @@ func (mp *TxPool) maybeAcceptTransaction(tx *hcutil.Tx, isNew, rateLimit, allowH
 	if txType == stake.TxTypeSSGen {
 		_, voteHeight, err := stake.SSGenBlockVotedOn(msgTx)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		if (int64(voteHeight) < nextBlockHeight-maximumVoteAgeDelta) &&
@@ -928,7 +1175,7 @@ func (mp *TxPool) maybeAcceptTransaction(tx *hcutil.Tx, isNew, rateLimit, allowH
 				"block height of %v which is before the "+
 				"current cutoff height of %v",
 				tx.Hash(), voteHeight, nextBlockHeight-maximumVoteAgeDelta)
-			return nil, txRuleError(wire.RejectNonstandard, str)
+			return nil, nil, txRuleError(wire.RejectNonstandard, str)
 		}
 	}
 
@@ -939,16 +1186,19 @@ func (mp *TxPool) maybeAcceptTransaction(tx *hcutil.Tx, isNew, rateLimit, allowH
 	utxoView, err := mp.fetchInputUtxos(tx)
 	if err != nil {
 		if cerr, ok := err.(blockchain.RuleError); ok {
-			return nil, chainRuleError(cerr)
+			return nil, nil, chainRuleError(cerr)
 		}
-		return nil, err
+		return nil, nil, err
+	}
+	if pkg != nil {
+		pkg.addInputUtxos(utxoView)
 	}
 
 	// Don't allow the transaction if it exists in the main chain and is not
 	// not already fully spent.
 	txEntry := utxoView.LookupEntry(txHash)
 	if txEntry != nil && !txEntry.IsFullySpent() {
-		return nil, txRuleError(wire.RejectDuplicate,
+		return nil, nil, txRuleError(wire.RejectDuplicate,
 			"transaction already exists")
 	}
 	delete(utxoView.Entries(), *txHash)
@@ -986,7 +1236,7 @@ func (mp *TxPool) maybeAcceptTransaction(tx *hcutil.Tx, isNew, rateLimit, allowH
 	}
 
 	if len(missingParents) > 0 {
-		return missingParents, nil
+		return nil, missingParents, nil
 	}
 
 	// Don't allow the transaction into the mempool unless its sequence
@@ -995,12 +1245,12 @@ func (mp *TxPool) maybeAcceptTransaction(tx *hcutil.Tx, isNew, rateLimit, allowH
 	seqLock, err := mp.cfg.CalcSequenceLock(tx, utxoView)
 	if err != nil {
 		if cerr, ok := err.(blockchain.RuleError); ok {
-			return nil, chainRuleError(cerr)
+			return nil, nil, chainRuleError(cerr)
 		}
-		return nil, err
+		return nil, nil, err
 	}
 	if !blockchain.SequenceLockActive(seqLock, nextBlockHeight, medianTime) {
-		return nil, txRuleError(wire.RejectNonstandard,
+		return nil, nil, txRuleError(wire.RejectNonstandard,
 			"transaction sequence locks on inputs not met")
 	}
 
@@ -1013,9 +1263,9 @@ func (mp *TxPool) maybeAcceptTransaction(tx *hcutil.Tx, isNew, rateLimit, allowH
 		tx, nextBlockHeight, utxoView, false, mp.cfg.ChainParams)
 	if err != nil {
 		if cerr, ok := err.(blockchain.RuleError); ok {
-			return nil, chainRuleError(cerr)
+			return nil, nil, chainRuleError(cerr)
 		}
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Don't allow transactions with non-standard inputs if the network
@@ -1032,7 +1282,7 @@ func (mp *TxPool) maybeAcceptTransaction(tx *hcutil.Tx, isNew, rateLimit, allowH
 			}
 			str := fmt.Sprintf("transaction %v has a non-standard "+
 				"input: %v", txHash, err)
-			return nil, txRuleError(rejectCode, str)
+			return nil, nil, txRuleError(rejectCode, str)
 		}
 	}
 
@@ -1049,16 +1299,16 @@ func (mp *TxPool) maybeAcceptTransaction(tx *hcutil.Tx, isNew, rateLimit, allowH
 		(txType == stake.TxTypeSSGen), utxoView)
 	if err != nil {
 		if cerr, ok := err.(blockchain.RuleError); ok {
-			return nil, chainRuleError(cerr)
+			return nil, nil, chainRuleError(cerr)
 		}
-		return nil, err
+		return nil, nil, err
 	}
 
 	numSigOps += blockchain.CountSigOps(tx, false, (txType == stake.TxTypeSSGen))
 	if numSigOps > mp.cfg.Policy.MaxSigOpsPerTx {
 		str := fmt.Sprintf("transaction %v has too many sigops: %d > %d",
 			txHash, numSigOps, mp.cfg.Policy.MaxSigOpsPerTx)
-		return nil, txRuleError(wire.RejectNonstandard, str)
+		return nil, nil, txRuleError(wire.RejectNonstandard, str)
 	}
 
 	// Don't allow transactions with fees too low to get into a mined block.
@@ -1076,14 +1326,32 @@ func (mp *TxPool) maybeAcceptTransaction(tx *hcutil.Tx, isNew, rateLimit, allowH
 	serializedSize := int64(msgTx.SerializeSize())
 	minFee := calcMinRequiredTxRelayFee(serializedSize,
 		mp.cfg.Policy.MinRelayTxFee)
-	if txType == stake.TxTypeRegular { // Non-stake only
+	if txType == stake.TxTypeRegular && !deferFeeChecks { // Non-stake only
 		if serializedSize >= (DefaultBlockPrioritySize-1000) &&
 			txFee < minFee {
 
 			str := fmt.Sprintf("transaction %v has %v fees which "+
 				"is under the required amount of %v", txHash,
 				txFee, minFee)
-			return nil, txRuleError(wire.RejectInsufficientFee, str)
+			return nil, nil, txRuleError(wire.RejectInsufficientFee, str)
+		}
+	}
+
+	// Require new regular transactions to pay the dynamic minimum relay fee
+	// when it has been raised above the configured minimum due to the pool
+	// exceeding its maximum size.
+	if isNew && txType == stake.TxTypeRegular && !deferFeeChecks {
+		poolMinRelayTxFee := mp.minRelayTxFee()
+		if poolMinRelayTxFee > mp.cfg.Policy.MinRelayTxFee {
+			poolMinFee := calcMinRequiredTxRelayFee(serializedSize,
+				poolMinRelayTxFee)
+			if txFee < poolMinFee {
+				str := fmt.Sprintf("transaction %v has %v fees "+
+					"which is under the mempool minimum fee "+
+					"of %v", txHash, txFee, poolMinFee)
+				return nil, nil, txRuleError(wire.RejectInsufficientFee,
+					str)
+			}
 		}
 	}
 
@@ -1094,21 +1362,22 @@ func (mp *TxPool) maybeAcceptTransaction(tx *hcutil.Tx, isNew, rateLimit, allowH
 	//
 	// This applies to non-stake transactions only.
 	if isNew && !mp.cfg.Policy.DisableRelayPriority && txFee < minFee &&
-		txType == stake.TxTypeRegular {
+		txType == stake.TxTypeRegular && !deferFeeChecks {
 
 		currentPriority := CalcPriority(msgTx, utxoView,
 			nextBlockHeight)
 		if currentPriority <= MinHighPriority {
 			str := fmt.Sprintf("transaction %v has insufficient priority (%g <= %g)", txHash,
 				currentPriority, MinHighPriority)
-			return nil, txRuleError(wire.RejectInsufficientFee, str)
+			return nil, nil, txRuleError(wire.RejectInsufficientFee, str)
 		}
 	}
 
 	// Free-to-relay transactions are rate limited here to prevent
 	// penny-flooding with tiny transactions as a form of attack.
 	// This applies to non-stake transactions only.
-	if rateLimit && txFee < minFee && txType == stake.TxTypeRegular {
+	if rateLimit && txFee < minFee && txType == stake.TxTypeRegular &&
+		!deferFeeChecks {
 		nowUnix := time.Now().Unix()
 		// Decay passed data with an exponentially decaying ~10 minute
 		// window.
@@ -1120,7 +1389,7 @@ func (mp *TxPool) maybeAcceptTransaction(tx *hcutil.Tx, isNew, rateLimit, allowH
 		if mp.pennyTotal >= mp.cfg.Policy.FreeTxRelayLimit*10*1000 {
 			str := fmt.Sprintf("transaction %v has been rejected "+
 				"by the rate limiter due to low fees", txHash)
-			return nil, txRuleError(wire.RejectInsufficientFee, str)
+			return nil, nil, txRuleError(wire.RejectInsufficientFee, str)
 		}
 		oldTotal := mp.pennyTotal
 
@@ -1141,7 +1410,7 @@ func (mp *TxPool) maybeAcceptTransaction(tx *hcutil.Tx, isNew, rateLimit, allowH
 			str := fmt.Sprintf("ticket purchase transaction %v has a %v "+
 				"fee which is under the required threshold amount of %d",
 				txHash, txFee, minTicketFee)
-			return nil, txRuleError(wire.RejectInsufficientFee, str)
+			return nil, nil, txRuleError(wire.RejectInsufficientFee, str)
 		}
 	}
 
@@ -1155,43 +1424,90 @@ func (mp *TxPool) maybeAcceptTransaction(tx *hcutil.Tx, isNew, rateLimit, allowH
 			err = fmt.Errorf("transaction %v has %v fee which is above the "+
 				"allowHighFee check threshold amount of %v", txHash,
 				txFee, maxFee)
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
+	// Run any external policy hooks prior to the relatively expensive
+	// signature verification.
+	if err := mp.runAcceptHooks(tx, txType, utxoView); err != nil {
+		return nil, nil, err
+	}
+
 	// Verify crypto signatures for each input and reject the transaction if
 	// any don't verify.
 	flags, err := mp.cfg.Policy.StandardVerifyFlags()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	err = blockchain.ValidateTransactionScripts(tx, utxoView, flags,
-		mp.cfg.SigCache)
+		mp.cfg.SigCache, mp.cfg.ScriptCache)
 	if err != nil {
 		if cerr, ok := err.(blockchain.RuleError); ok {
-			return nil, chainRuleError(cerr)
+			return nil, nil, chainRuleError(cerr)
 		}
+		return nil, nil, err
+	}
+
+	return &validatedTx{
+		tx:       tx,
+		txType:   txType,
+		utxoView: utxoView,
+		height:   bestHeight,
+		fee:      txFee,
+	}, nil, nil
+}
+
+// maybeAcceptTransaction is the internal function which implements the public
+// MaybeAcceptTransaction.  See the comment for MaybeAcceptTransaction for
+// more details.
+//
+// This function MUST be called with the mempool lock held (for writes).
+func (mp *TxPool) maybeAcceptTransaction(tx *hcutil.Tx, isNew, rateLimit, allowHighFees bool) ([]*chainhash.Hash, error) {
+	vtx, missingParents, err := mp.validateTransaction(tx, isNew,
+		rateLimit, allowHighFees, nil)
+	if err != nil || len(missingParents) > 0 {
+		return missingParents, err
+	}
+	if err := mp.addValidatedTransaction(vtx); err != nil {
 		return nil, err
 	}
 
-	// Add to transaction pool.
-	mp.addTransaction(utxoView, tx, txType, bestHeight, txFee)
+	// Evict transactions when the pool now exceeds its maximum size and
+	// reject the transaction if it was among them.
+	txHash := tx.Hash()
+	mp.trimToSize()
+	if !mp.isTransactionInPool(txHash) {
+		str := fmt.Sprintf("transaction %v rejected since the "+
+			"mempool is full", txHash)
+		return nil, txRuleError(wire.RejectInsufficientFee, str)
+	}
+
+	log.Debugf("Accepted transaction %v (pool size: %v)", txHash,
+		len(mp.pool))
+
+	return nil, nil
+}
+
+// addValidatedTransaction adds the passed validated transaction to the pool
+// and, when it is a vote, to the votes on the block it votes on.
+//
+// This function MUST be called with the mempool lock held (for writes).
+func (mp *TxPool) addValidatedTransaction(vtx *validatedTx) error {
+	mp.addTransaction(vtx.utxoView, vtx.tx, vtx.txType, vtx.height,
+		vtx.fee)
 
 	// If it's an SSGen (vote), insert it into the list of
 	// votes.
-	if txType == stake.TxTypeSSGen {
+	if vtx.txType == stake.TxTypeSSGen {
 		mp.votesMtx.Lock()
-		err := mp.insertVote(tx)
+		err := mp.insertVote(vtx.tx)
 		mp.votesMtx.Unlock()
 		if err != nil {
-			return nil, err
+			return err
 		}
 	}
-
-	log.Debugf("Accepted transaction %v (pool size: %v)", txHash,
-		len(mp.pool))
-
-	return nil, nil
+	return nil
 }
 
 // MaybeAcceptTransaction is the main workhorse for handling insertion of new
@@ -1230,11 +1546,14 @@ func (mp *TxPool) processOrphans(hash *chainhash.Hash) []*hcutil.Tx {
 		// just accepted.  This will typically only be one, but it could
 		// be multiple if the referenced transaction contains multiple
 		// outputs.  Skip to the next item on the list of hashes to
-		// process if there are none.
-		orphans, exists := mp.orphansByPrev[*processHash]
-		if !exists || orphans == nil {
-			continue
+		// process if there are none.  The orphans are copied since the
+		// orphan lock can not be held while accepting them.
+		mp.orphanMtx.RLock()
+		orphans := make([]*hcutil.Tx, 0, len(mp.orphansByPrev[*processHash]))
+		for _, tx := range mp.orphansByPrev[*processHash] {
+			orphans = append(orphans, tx)
 		}
+		mp.orphanMtx.RUnlock()
 
 		for _, tx := range orphans {
 			// Remove the orphan from the orphan pool.  Current
@@ -1250,8 +1569,17 @@ func (mp *TxPool) processOrphans(hash *chainhash.Hash) []*hcutil.Tx {
 			// potentially moving orphans to the memory pool, but
 			// leaving them in the orphan pool if not all parent
 			// transactions are known yet.
+			//
+			// Orphans which were evicted since they were copied
+			// are skipped.
 			orphanHash := tx.Hash()
+			mp.orphanMtx.Lock()
+			if !mp.isOrphanInPool(orphanHash) {
+				mp.orphanMtx.Unlock()
+				continue
+			}
 			mp.removeOrphan(orphanHash)
+			mp.orphanMtx.Unlock()
 
 			// Potentially accept the transaction into the
 			// transaction pool.
@@ -1268,7 +1596,9 @@ func (mp *TxPool) processOrphans(hash *chainhash.Hash) []*hcutil.Tx {
 			if len(missingParents) > 0 {
 				// Transaction is still an orphan, so add it
 				// back.
+				mp.orphanMtx.Lock()
 				mp.addOrphan(tx)
+				mp.orphanMtx.Unlock()
 				continue
 			}
 
@@ -1348,6 +1678,86 @@ func (mp *TxPool) pruneExpiredTx(height int64) {
 	}
 }
 
+// expireOldTx is the internal function which implements the public
+// ExpireOldTx.  See the comment for ExpireOldTx for more details.
+//
+// This function MUST be called with the mempool lock held (for writes).
+func (mp *TxPool) expireOldTx(now time.Time) []*hcutil.Tx {
+	expiry := mp.cfg.Policy.MempoolExpiry
+	if expiry <= 0 {
+		return nil
+	}
+
+	// Collect the expired transactions prior to removing any of them since
+	// removing a transaction also removes its redeemers from the pool.
+	cutoff := now.Add(-expiry)
+	var stale []*hcutil.Tx
+	for _, txDesc := range mp.pool {
+		if txDesc.Added.Before(cutoff) {
+			stale = append(stale, txDesc.Tx)
+		}
+	}
+	if len(stale) == 0 {
+		return nil
+	}
+
+	// Remove the expired transactions along with all of their descendants
+	// and report every transaction which is no longer in the pool as a
+	// result.
+	var expired []*hcutil.Tx
+	seen := make(map[chainhash.Hash]struct{})
+	for _, tx := range stale {
+		expired = mp.appendRedeemers(expired, tx, seen)
+	}
+	for _, tx := range stale {
+		mp.removeTransaction(tx, true)
+	}
+
+	log.Debugf("Expired %d transaction(s) older than %v from the mempool",
+		len(expired), expiry)
+	return expired
+}
+
+// appendRedeemers appends the passed transaction followed by all transactions
+// in the pool which redeem its outputs, recursively, to the provided slice.
+// Transactions already in the seen set are skipped so that each transaction is
+// only appended once.
+//
+// This function MUST be called with the mempool lock held (for reads).
+func (mp *TxPool) appendRedeemers(txns []*hcutil.Tx, tx *hcutil.Tx, seen map[chainhash.Hash]struct{}) []*hcutil.Tx {
+	if _, ok := seen[*tx.Hash()]; ok {
+		return txns
+	}
+	seen[*tx.Hash()] = struct{}{}
+	txns = append(txns, tx)
+	msgTx := tx.MsgTx()
+	tree := wire.TxTreeRegular
+	if stake.DetermineTxType(msgTx) != stake.TxTypeRegular {
+		tree = wire.TxTreeStake
+	}
+	for i := uint32(0); i < uint32(len(msgTx.TxOut)); i++ {
+		outpoint := wire.NewOutPoint(tx.Hash(), i, tree)
+		if txRedeemer, exists := mp.outpoints[*outpoint]; exists {
+			txns = mp.appendRedeemers(txns, txRedeemer, seen)
+		}
+	}
+	return txns
+}
+
+// ExpireOldTx removes all transactions which have been in the memory pool for
+// longer than the configured MempoolExpiry as of the passed time, along with
+// any transactions which redeem their outputs.  It returns all of the
+// transactions which were removed as a result.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) ExpireOldTx(now time.Time) []*hcutil.Tx {
+	// Protect concurrent access.
+	mp.mtx.Lock()
+	expired := mp.expireOldTx(now)
+	mp.mtx.Unlock()
+	return expired
+}
+
 // ProcessOrphans determines if there are any orphans which depend on the passed
 // transaction hash (it is possible that they are no longer orphans) and
 // potentially accepts them to the memory pool.  It repeats the process for the
@@ -1436,6 +1846,555 @@ func (mp *TxPool) ProcessTransaction(tx *hcutil.Tx, allowOrphan, rateLimit, allo
 	return nil, err
 }
 
+// ProcessPackage handles insertion of a topologically ordered package of
+// dependent transactions into the memory pool as a single unit.  Every
+// transaction in the package must either spend outputs which are already
+// available or outputs of transactions which precede it in the package.
+//
+// Unlike ProcessTransaction, the minimum relay fee and priority requirements
+// are applied to the aggregate fee and size of the package rather than to
+// each transaction individually.  This allows a parent which pays less than
+// the minimum relay fee to be accepted when its children pay enough to cover
+// it (child-pays-for-parent).
+//
+// Transactions of the package which are already in the pool are skipped.  The
+// remaining transactions are all validated before any of them is added, so
+// the package is accepted atomically and a rejected package leaves the pool
+// unchanged.  It returns the transactions added to the mempool, which includes
+// any orphans that were accepted as a result of the package, along with a
+// slice of errors which parallels the passed transactions.  Every entry of the
+// error slice is nil when the package was accepted.  Otherwise, the entries
+// describe why each individual transaction was rejected and no transactions
+// are added to the pool.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) ProcessPackage(txns []*hcutil.Tx, allowHighFees bool) ([]*hcutil.Tx, []error) {
+	// Protect concurrent access.
+	mp.mtx.Lock()
+	defer mp.mtx.Unlock()
+
+	txErrs := make([]error, len(txns))
+	rejectAll := func(err error) ([]*hcutil.Tx, []error) {
+		for i := range txErrs {
+			if txErrs[i] == nil {
+				txErrs[i] = err
+			}
+		}
+		return nil, txErrs
+	}
+
+	if len(txns) == 0 {
+		return nil, txErrs
+	}
+	if len(txns) > maxPackageCount {
+		str := fmt.Sprintf("package contains %d transactions which is "+
+			"more than the max allowed %d", len(txns), maxPackageCount)
+		return rejectAll(txRuleError(wire.RejectNonstandard, str))
+	}
+
+	// Ensure the package only consists of unique regular transactions
+	// which are topologically ordered.  Stake transactions are excluded
+	// since they are subject to their own fee and replacement rules.
+	pkgIndex := make(map[chainhash.Hash]int, len(txns))
+	for i, tx := range txns {
+		if stake.DetermineTxType(tx.MsgTx()) != stake.TxTypeRegular {
+			str := fmt.Sprintf("package transaction %v is not a "+
+				"regular transaction", tx.Hash())
+			txErrs[i] = txRuleError(wire.RejectNonstandard, str)
+			return rejectAll(packageRejectedError(tx.Hash()))
+		}
+		if _, exists := pkgIndex[*tx.Hash()]; exists {
+			str := fmt.Sprintf("package contains duplicate "+
+				"transaction %v", tx.Hash())
+			txErrs[i] = txRuleError(wire.RejectDuplicate, str)
+			return rejectAll(packageRejectedError(tx.Hash()))
+		}
+		for _, txIn := range tx.MsgTx().TxIn {
+			idx, exists := pkgIndex[txIn.PreviousOutPoint.Hash]
+			if exists && idx >= i {
+				str := fmt.Sprintf("package transaction %v "+
+					"spends transaction %v which does not "+
+					"precede it", tx.Hash(),
+					txIn.PreviousOutPoint.Hash)
+				txErrs[i] = txRuleError(wire.RejectInvalid, str)
+				return rejectAll(packageRejectedError(tx.Hash()))
+			}
+		}
+		pkgIndex[*tx.Hash()] = i
+	}
+
+	// Validate the transactions which are not in the pool yet in order
+	// against the pool, the chain and the transactions preceding them in
+	// the package while deferring the fee checks to the package as a whole.
+	// Nothing is added to the pool until the whole package is known to be
+	// accepted, so a rejected package leaves the pool unchanged.
+	pkg := newTxPackage()
+	validated := make([]*validatedTx, 0, len(txns))
+	var totalFee, totalSize int64
+	for i, tx := range txns {
+		if mp.isTransactionInPool(tx.Hash()) {
+			continue
+		}
+
+		vtx, missingParents, err := mp.validateTransaction(tx, true,
+			false, allowHighFees, pkg)
+		if err == nil && len(missingParents) > 0 {
+			str := fmt.Sprintf("package transaction %v references "+
+				"outputs of unknown or fully-spent transaction %v",
+				tx.Hash(), missingParents[0])
+			err = txRuleError(wire.RejectDuplicate, str)
+		}
+		if err != nil {
+			txErrs[i] = err
+			return rejectAll(packageRejectedError(tx.Hash()))
+		}
+
+		pkg.add(vtx)
+		validated = append(validated, vtx)
+		totalFee += vtx.fee
+		totalSize += int64(tx.MsgTx().SerializeSize())
+	}
+	if len(validated) == 0 {
+		return nil, txErrs
+	}
+
+	// Ensure the package as a whole pays the minimum relay fee, including
+	// any increase due to the pool exceeding its maximum size.
+	minFee := calcMinRequiredTxRelayFee(totalSize, mp.minRelayTxFee())
+	if totalFee < minFee {
+		str := fmt.Sprintf("package has %v fees which is under the "+
+			"required amount of %v for its %d bytes", totalFee,
+			minFee, totalSize)
+		return rejectAll(txRuleError(wire.RejectInsufficientFee, str))
+	}
+
+	// Reject the package without evicting anything when the pool would
+	// evict any of its transactions to stay within its maximum size.
+	if !mp.packageFits(pkg) {
+		str := "package rejected since the mempool is full"
+		return rejectAll(txRuleError(wire.RejectInsufficientFee, str))
+	}
+
+	// Add the package to the pool, removing any of its transactions which
+	// were orphans from the orphan pool, and evict transactions when the
+	// pool now exceeds its maximum size.
+	accepted := make([]*hcutil.Tx, 0, len(validated))
+	for _, vtx := range validated {
+		mp.orphanMtx.Lock()
+		mp.removeOrphan(vtx.tx.Hash())
+		mp.orphanMtx.Unlock()
+
+		mp.addTransaction(vtx.utxoView, vtx.tx, vtx.txType, vtx.height,
+			vtx.fee)
+		accepted = append(accepted, vtx.tx)
+	}
+	mp.trimToSize()
+
+	// Accept any orphan transactions that depend on the package.
+	for _, vtx := range validated {
+		accepted = append(accepted, mp.processOrphans(vtx.tx.Hash())...)
+	}
+
+	log.Debugf("Accepted package of %d transactions (pool size: %v)",
+		len(validated), len(mp.pool))
+
+	return accepted, txErrs
+}
+
+// txPackage houses the transactions of a package which passed validation so
+// far, so the transactions following them may spend their outputs, but not the
+// same outputs as them.
+type txPackage struct {
+	txns  map[chainhash.Hash]*validatedTx
+	spent map[wire.OutPoint]*hcutil.Tx
+}
+
+// newTxPackage returns an empty transaction package.
+func newTxPackage() *txPackage {
+	return &txPackage{
+		txns:  make(map[chainhash.Hash]*validatedTx),
+		spent: make(map[wire.OutPoint]*hcutil.Tx),
+	}
+}
+
+// add adds the passed validated transaction to the package.
+func (pkg *txPackage) add(vtx *validatedTx) {
+	pkg.txns[*vtx.tx.Hash()] = vtx
+	for _, txIn := range vtx.tx.MsgTx().TxIn {
+		pkg.spent[txIn.PreviousOutPoint] = vtx.tx
+	}
+}
+
+// checkDoubleSpend returns an error when the passed transaction spends any of
+// the outputs spent by the transactions of the package.
+func (pkg *txPackage) checkDoubleSpend(tx *hcutil.Tx) error {
+	for _, txIn := range tx.MsgTx().TxIn {
+		if txR, exists := pkg.spent[txIn.PreviousOutPoint]; exists {
+			str := fmt.Sprintf("transaction %v in the package "+
+				"already spends the same coins", txR.Hash())
+			return txRuleError(wire.RejectDuplicate, str)
+		}
+	}
+	return nil
+}
+
+// addInputUtxos populates the inputs of the passed view which are missing with
+// the outputs of the transactions of the package.
+func (pkg *txPackage) addInputUtxos(utxoView *blockchain.UtxoViewpoint) {
+	for originHash, entry := range utxoView.Entries() {
+		if entry != nil && !entry.IsFullySpent() {
+			continue
+		}
+		if vtx, exists := pkg.txns[originHash]; exists {
+			utxoView.AddTxOuts(vtx.tx, mempoolHeight,
+				wire.NullBlockIndex)
+		}
+	}
+}
+
+// packageFits returns whether the transactions of the passed package may be
+// added to the pool without any of them being evicted again to keep the pool
+// within its maximum size.  It simulates trimToSize with the package added,
+// treating its transactions as evicted first among transactions with the same
+// ancestor fee rate, so a package which would not remain in the pool is
+// rejected before anything is evicted for it.
+//
+// This function MUST be called with the mempool lock held (for writes).
+func (mp *TxPool) packageFits(pkg *txPackage) bool {
+	maxSize := mp.cfg.Policy.MaxMempoolSize
+	usage := mp.totalUsage
+	for _, vtx := range pkg.txns {
+		usage += txMemoryUsage(vtx.tx.MsgTx())
+	}
+	if maxSize <= 0 || usage <= maxSize {
+		return true
+	}
+
+	type candidate struct {
+		evictionCandidate
+		inPackage bool
+	}
+	candidates := make([]candidate, 0, len(mp.pool)+len(pkg.txns))
+	for _, txDesc := range mp.pool {
+		if txDesc.Type != stake.TxTypeRegular {
+			continue
+		}
+		candidates = append(candidates, candidate{evictionCandidate: evictionCandidate{
+			tx:      txDesc.Tx,
+			feeRate: mp.ancestorFeeRate(txDesc),
+		}})
+	}
+	for _, vtx := range pkg.txns {
+		candidates = append(candidates, candidate{
+			evictionCandidate: evictionCandidate{
+				tx:      vtx.tx,
+				feeRate: mp.calcAncestorFeeRate(vtx.tx, vtx.fee, pkg),
+			},
+			inPackage: true,
+		})
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].feeRate != candidates[j].feeRate {
+			return candidates[i].feeRate < candidates[j].feeRate
+		}
+		return candidates[i].inPackage && !candidates[j].inPackage
+	})
+
+	// Evict the candidates along with the transactions of the pool and the
+	// package which redeem them until the trim target is reached.
+	targetSize := maxSize / 100 * trimTargetPercent
+	evicted := make(map[chainhash.Hash]struct{})
+	for _, c := range candidates {
+		if usage <= targetSize {
+			break
+		}
+		pending := []*hcutil.Tx{c.tx}
+		for len(pending) > 0 {
+			tx := pending[len(pending)-1]
+			pending = pending[:len(pending)-1]
+			txHash := tx.Hash()
+			if _, ok := evicted[*txHash]; ok {
+				continue
+			}
+			if _, ok := pkg.txns[*txHash]; ok {
+				return false
+			}
+			evicted[*txHash] = struct{}{}
+			usage -= mp.pool[*txHash].usage
+
+			for i := range tx.MsgTx().TxOut {
+				outpoint := wire.OutPoint{Hash: *txHash,
+					Index: uint32(i), Tree: tx.Tree()}
+				if txR, exists := mp.outpoints[outpoint]; exists {
+					pending = append(pending, txR)
+				}
+				if txR, exists := pkg.spent[outpoint]; exists {
+					pending = append(pending, txR)
+				}
+			}
+		}
+	}
+	return true
+}
+
+// txMemoryUsage returns the estimated amount of memory in bytes used by a pool
+// entry for the passed transaction, including its entries in the pool and
+// spent outpoint maps.
+func txMemoryUsage(msgTx *wire.MsgTx) int64 {
+	usage := txDescOverhead
+	for _, txIn := range msgTx.TxIn {
+		usage += txInOverhead + int64(cap(txIn.SignatureScript))
+	}
+	for _, txOut := range msgTx.TxOut {
+		usage += txOutOverhead + int64(cap(txOut.PkScript))
+	}
+	return usage
+}
+
+// ancestorFeeRate returns the fee rate in atoms/kB of the passed pool entry
+// combined with all of its unconfirmed ancestors in the pool.  The fee rate is
+// cached in the entry until it is invalidated by invalidateFeeRates.
+//
+// This function MUST be called with the mempool lock held (for writes).
+func (mp *TxPool) ancestorFeeRate(txDesc *TxDesc) float64 {
+	if txDesc.haveFeeRate {
+		return txDesc.feeRate
+	}
+
+	txDesc.feeRate = mp.calcAncestorFeeRate(txDesc.Tx, txDesc.Fee, nil)
+	txDesc.haveFeeRate = true
+	return txDesc.feeRate
+}
+
+// calcAncestorFeeRate calculates the ancestor fee rate of the passed
+// transaction paying the passed fee from its ancestors in the pool and in the
+// passed package, which may be nil.
+//
+// This function MUST be called with the mempool lock held (for reads).
+func (mp *TxPool) calcAncestorFeeRate(tx *hcutil.Tx, txFee int64, pkg *txPackage) float64 {
+	fee := txFee
+	size := int64(tx.MsgTx().SerializeSize())
+	seen := make(map[chainhash.Hash]struct{})
+	pending := []*hcutil.Tx{tx}
+	for len(pending) > 0 {
+		tx := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		for _, txIn := range tx.MsgTx().TxIn {
+			prevHash := txIn.PreviousOutPoint.Hash
+			if _, ok := seen[prevHash]; ok {
+				continue
+			}
+			var parent *hcutil.Tx
+			var parentFee int64
+			if txDesc, exists := mp.pool[prevHash]; exists {
+				parent, parentFee = txDesc.Tx, txDesc.Fee
+			} else if pkg != nil {
+				if vtx, exists := pkg.txns[prevHash]; exists {
+					parent, parentFee = vtx.tx, vtx.fee
+				}
+			}
+			if parent == nil {
+				continue
+			}
+			seen[prevHash] = struct{}{}
+			fee += parentFee
+			size += int64(parent.MsgTx().SerializeSize())
+			pending = append(pending, parent)
+		}
+	}
+	return float64(fee) * 1000 / float64(size)
+}
+
+// invalidateFeeRates invalidates the cached ancestor fee rates of all pool
+// entries which redeem outputs of the passed transaction, directly or through
+// other pool entries, since their ancestors change when it enters or leaves
+// the pool.
+//
+// This function MUST be called with the mempool lock held (for writes).
+func (mp *TxPool) invalidateFeeRates(tx *hcutil.Tx) {
+	redeemers := mp.appendRedeemers(nil, tx, make(map[chainhash.Hash]struct{}))
+	for _, redeemer := range redeemers[1:] {
+		if txDesc, exists := mp.pool[*redeemer.Hash()]; exists {
+			txDesc.haveFeeRate = false
+		}
+	}
+}
+
+// evictionCandidate describes a transaction which may be evicted from the pool
+// along with the ancestor fee rate it is ranked by.
+type evictionCandidate struct {
+	tx      *hcutil.Tx
+	feeRate float64
+}
+
+// evictionCandidates implements sort.Interface to allow a slice of eviction
+// candidates to be sorted by ascending ancestor fee rate.
+type evictionCandidates []evictionCandidate
+
+// Len returns the number of candidates in the slice.  It is part of the
+// sort.Interface implementation.
+func (s evictionCandidates) Len() int { return len(s) }
+
+// Swap swaps the candidates at the passed indices.  It is part of the
+// sort.Interface implementation.
+func (s evictionCandidates) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+
+// Less returns whether the candidate with index i should sort before the
+// candidate with index j.  It is part of the sort.Interface implementation.
+func (s evictionCandidates) Less(i, j int) bool {
+	return s[i].feeRate < s[j].feeRate
+}
+
+// trimToSize evicts regular transactions, along with any transactions which
+// redeem them, in order of lowest ancestor fee rate once the memory used by the
+// pool is above the configured maximum, until it is no longer above
+// trimTargetPercent of the maximum.  The dynamic minimum relay fee is raised
+// above the fee rate of each evicted package so that transactions which would
+// immediately be evicted again are rejected.  Stake transactions are never
+// evicted.
+//
+// It returns the transactions which were evicted.
+//
+// This function MUST be called with the mempool lock held (for writes).
+func (mp *TxPool) trimToSize() []*hcutil.Tx {
+	maxSize := mp.cfg.Policy.MaxMempoolSize
+	if maxSize <= 0 || mp.totalUsage <= maxSize {
+		return nil
+	}
+	targetSize := maxSize / 100 * trimTargetPercent
+
+	candidates := make(evictionCandidates, 0, len(mp.pool))
+	for _, txDesc := range mp.pool {
+		if txDesc.Type != stake.TxTypeRegular {
+			continue
+		}
+		candidates = append(candidates, evictionCandidate{
+			tx:      txDesc.Tx,
+			feeRate: mp.ancestorFeeRate(txDesc),
+		})
+	}
+	sort.Sort(candidates)
+
+	var evicted []*hcutil.Tx
+	seen := make(map[chainhash.Hash]struct{})
+	for _, candidate := range candidates {
+		if mp.totalUsage <= targetSize {
+			break
+		}
+		if _, exists := mp.pool[*candidate.tx.Hash()]; !exists {
+			// Already evicted as the descendant of another
+			// candidate.
+			continue
+		}
+		evicted = mp.appendRedeemers(evicted, candidate.tx, seen)
+		mp.removeTransaction(candidate.tx, true)
+		mp.raiseRollingMinFee(candidate.feeRate)
+	}
+
+	log.Debugf("Evicted %d transaction(s) to trim the mempool size to %d "+
+		"bytes (usage: %d bytes)", len(evicted), targetSize, mp.totalUsage)
+	return evicted
+}
+
+// decayRollingMinFee decays the dynamic minimum relay fee based on the time
+// elapsed since it was last updated.  Once it falls below half the configured
+// minimum relay fee it is reset to zero.
+//
+// This function MUST be called with the mempool lock held (for writes).
+func (mp *TxPool) decayRollingMinFee(now time.Time) {
+	if mp.rollingMinFee == 0 {
+		mp.lastRollingFeeUpdate = now
+		return
+	}
+
+	halfLife := rollingFeeHalfLife
+	maxSize := mp.cfg.Policy.MaxMempoolSize
+	if mp.totalUsage < maxSize/4 {
+		halfLife /= 4
+	} else if mp.totalUsage < maxSize/2 {
+		halfLife /= 2
+	}
+	elapsed := now.Sub(mp.lastRollingFeeUpdate)
+	mp.rollingMinFee /= math.Pow(2, elapsed.Seconds()/halfLife.Seconds())
+	mp.lastRollingFeeUpdate = now
+	if mp.rollingMinFee < float64(mp.cfg.Policy.MinRelayTxFee)/2 {
+		mp.rollingMinFee = 0
+	}
+}
+
+// raiseRollingMinFee raises the dynamic minimum relay fee to the passed fee
+// rate in atoms/kB plus the configured minimum relay fee if it is not already
+// higher.
+//
+// This function MUST be called with the mempool lock held (for writes).
+func (mp *TxPool) raiseRollingMinFee(feeRate float64) {
+	mp.decayRollingMinFee(time.Now())
+	newFee := feeRate + float64(mp.cfg.Policy.MinRelayTxFee)
+	if newFee > mp.rollingMinFee {
+		mp.rollingMinFee = newFee
+		log.Debugf("Raised mempool minimum relay fee to %v",
+			hcutil.Amount(newFee))
+	}
+}
+
+// minRelayTxFee returns the minimum relay fee currently required by the pool,
+// which is the greater of the configured minimum relay fee and the dynamic
+// minimum relay fee.
+//
+// This function MUST be called with the mempool lock held (for writes).
+func (mp *TxPool) minRelayTxFee() hcutil.Amount {
+	mp.decayRollingMinFee(time.Now())
+	rollingMinFee := hcutil.Amount(mp.rollingMinFee)
+	if rollingMinFee > mp.cfg.Policy.MinRelayTxFee {
+		return rollingMinFee
+	}
+	return mp.cfg.Policy.MinRelayTxFee
+}
+
+// MinRelayTxFee returns the minimum relay fee in atoms/kB currently required
+// by the pool.  This is the configured minimum relay fee unless it has been
+// raised due to the pool exceeding its maximum size.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) MinRelayTxFee() hcutil.Amount {
+	// Protect concurrent access.
+	mp.mtx.Lock()
+	minFee := mp.minRelayTxFee()
+	mp.mtx.Unlock()
+	return minFee
+}
+
+// SetMinRelayTxFee sets the configured minimum relay fee in atoms/kB.  It
+// applies to transactions accepted from then on, while the ones already in the
+// pool are kept.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) SetMinRelayTxFee(fee hcutil.Amount) {
+	mp.mtx.Lock()
+	mp.cfg.Policy.MinRelayTxFee = fee
+	mp.mtx.Unlock()
+}
+
+// MemoryUsage returns the estimated amount of memory in bytes used by the
+// transactions in the pool.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) MemoryUsage() int64 {
+	mp.mtx.RLock()
+	usage := mp.totalUsage
+	mp.mtx.RUnlock()
+	return usage
+}
+
+// packageRejectedError returns a RuleError which is used for the transactions
+// of a package that were not themselves invalid, but were rejected because
+// the passed transaction in the package was.
+func packageRejectedError(rejectedHash *chainhash.Hash) RuleError {
+	str := fmt.Sprintf("package rejected due to transaction %v",
+		rejectedHash)
+	return txRuleError(wire.RejectInvalid, str)
+}
+
 // Count returns the number of transactions in the main pool.  It does not
 // include the orphan pool.
 //
@@ -1483,6 +2442,40 @@ func (mp *TxPool) TxDescs() []*TxDesc {
 	return descs
 }
 
+// PrioritiseTransaction adds the passed deltas to the fee and priority of the
+// transaction with the passed hash when it is ordered for inclusion in a block.
+// The fee delta, in atoms, does not change the fee the transaction pays.  The
+// deltas accumulate over calls and may be applied before the transaction
+// enters the pool.  They are kept until the transaction leaves the pool, such
+// as when it is mined or evicted.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) PrioritiseTransaction(txHash *chainhash.Hash, priorityDelta float64, feeDelta int64) {
+	mp.mtx.Lock()
+	defer mp.mtx.Unlock()
+
+	delta := mp.deltas[*txHash]
+	delta.fee += feeDelta
+	delta.priority += priorityDelta
+	if delta == (txDelta{}) {
+		delete(mp.deltas, *txHash)
+	} else {
+		mp.deltas[*txHash] = delta
+	}
+
+	// Replace the descriptor of a pooled transaction rather than modifying
+	// it since the descriptors returned by MiningDescs are read without
+	// holding the lock.
+	if txDesc, exists := mp.pool[*txHash]; exists {
+		newDesc := *txDesc
+		newDesc.FeeDelta = delta.fee
+		newDesc.PriorityDelta = delta.priority
+		mp.pool[*txHash] = &newDesc
+		mp.snapshot = nil
+		atomic.StoreInt64(&mp.lastUpdated, time.Now().Unix())
+	}
+}
+
 // MiningDescs returns a slice of mining descriptors for all the transactions
 // in the pool.
 //
@@ -1501,6 +2494,64 @@ func (mp *TxPool) MiningDescs() []*mining.TxDesc {
 	return descs
 }
 
+// Snapshot returns a snapshot of the transactions in the pool.  The snapshot is
+// cached until the pool changes, so repeated calls while the pool is unchanged
+// return the same snapshot without copying the pool again.  Since descriptors
+// are replaced rather than modified when their transaction is prioritised,
+// the snapshot shares them with the pool.
+//
+// This is part of the mining.TxSource interface implementation and is safe for
+// concurrent access as required by the interface contract.
+func (mp *TxPool) Snapshot() *mining.TxSourceSnapshot {
+	mp.mtx.RLock()
+	defer mp.mtx.RUnlock()
+	mp.snapshotMtx.Lock()
+	defer mp.snapshotMtx.Unlock()
+
+	if mp.snapshot == nil {
+		descs := make([]*mining.TxDesc, 0, len(mp.pool))
+		for _, desc := range mp.pool {
+			descs = append(descs, &desc.TxDesc)
+		}
+		mp.snapshot = mining.NewTxSourceSnapshot(descs)
+	}
+	return mp.snapshot
+}
+
+// rawMempoolVerboseEntry returns the passed entry of the mempool as a fully
+// populated JSON result.
+//
+// This function MUST be called with the mempool lock held (for reads).
+func (mp *TxPool) rawMempoolVerboseEntry(desc *TxDesc, bestHeight int64) *hcjson.GetRawMempoolVerboseResult {
+	// Calculate the current priority based on the inputs to the
+	// transaction.  Use zero if one or more of the input transactions
+	// can't be found for some reason.
+	tx := desc.Tx
+	var currentPriority float64
+	utxos, err := mp.fetchInputUtxos(tx)
+	if err == nil {
+		currentPriority = CalcPriority(tx.MsgTx(), utxos, bestHeight+1)
+	}
+
+	mpd := &hcjson.GetRawMempoolVerboseResult{
+		Size:             int32(tx.MsgTx().SerializeSize()),
+		Fee:              hcutil.Amount(desc.Fee).ToCoin(),
+		Time:             desc.Added.Unix(),
+		Height:           desc.Height,
+		StartingPriority: desc.StartingPriority,
+		CurrentPriority:  currentPriority,
+		Depends:          make([]string, 0),
+	}
+	for _, txIn := range tx.MsgTx().TxIn {
+		hash := &txIn.PreviousOutPoint.Hash
+		if mp.haveTransaction(hash) {
+			mpd.Depends = append(mpd.Depends, hash.String())
+		}
+	}
+
+	return mpd
+}
+
 // RawMempoolVerbose returns all of the entries in the mempool filtered by the
 // provided stake type as a fully populated JSON result.  The filter type can be
 // nil in which case all transactions will be returned.
@@ -1521,40 +2572,29 @@ func (mp *TxPool) RawMempoolVerbose(filterType *stake.TxType) map[string]*hcjson
 			continue
 		}
 
-		// Calculate the current priority based on the inputs to
-		// the transaction.  Use zero if one or more of the
-		// input transactions can't be found for some reason.
-		tx := desc.Tx
-		var currentPriority float64
-		utxos, err := mp.fetchInputUtxos(tx)
-		if err == nil {
-			currentPriority = CalcPriority(tx.MsgTx(), utxos,
-				bestHeight+1)
-		}
-
-		mpd := &hcjson.GetRawMempoolVerboseResult{
-			Size:             int32(tx.MsgTx().SerializeSize()),
-			Fee:              hcutil.Amount(desc.Fee).ToCoin(),
-			Time:             desc.Added.Unix(),
-			Height:           desc.Height,
-			StartingPriority: desc.StartingPriority,
-			CurrentPriority:  currentPriority,
-			Depends:          make([]string, 0),
-		}
-		for _, txIn := range tx.MsgTx().TxIn {
-			hash := &txIn.PreviousOutPoint.Hash
-			if mp.haveTransaction(hash) {
-				mpd.Depends = append(mpd.Depends,
-					hash.String())
-			}
-		}
-
-		result[tx.Hash().String()] = mpd
+		result[desc.Tx.Hash().String()] = mp.rawMempoolVerboseEntry(desc,
+			bestHeight)
 	}
 
 	return result
 }
 
+// RawMempoolEntryVerbose returns the entry of the mempool for the transaction
+// with the passed hash as a fully populated JSON result.  It returns nil when
+// the transaction is not in the main pool.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) RawMempoolEntryVerbose(hash *chainhash.Hash) *hcjson.GetRawMempoolVerboseResult {
+	mp.mtx.RLock()
+	defer mp.mtx.RUnlock()
+
+	desc, exists := mp.pool[*hash]
+	if !exists {
+		return nil
+	}
+	return mp.rawMempoolVerboseEntry(desc, mp.cfg.BestHeight())
+}
+
 // LastUpdated returns the last time a transaction was added to or removed from
 // the main pool.  It does not include the orphan pool.
 //
@@ -1583,12 +2623,15 @@ func (mp *TxPool) CheckIfTxsExist(hashes []chainhash.Hash) bool {
 // New returns a new memory pool for validating and storing standalone
 // transactions until they are mined into a block.
 func New(cfg *Config) *TxPool {
-	return &TxPool{
+	mp := &TxPool{
 		cfg:           *cfg,
 		pool:          make(map[chainhash.Hash]*TxDesc),
 		orphans:       make(map[chainhash.Hash]*hcutil.Tx),
 		orphansByPrev: make(map[chainhash.Hash]map[chainhash.Hash]*hcutil.Tx),
 		outpoints:     make(map[wire.OutPoint]*hcutil.Tx),
 		votes:         make(map[chainhash.Hash][]VoteTx),
+		deltas:        make(map[chainhash.Hash]txDelta),
 	}
+	mp.mtx.SetName("mempool")
+	return mp
 }
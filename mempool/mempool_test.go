@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/HcashOrg/hcd/blockchain"
+	"github.com/HcashOrg/hcd/blockchain/stake"
 	"github.com/HcashOrg/hcd/chaincfg"
 	"github.com/HcashOrg/hcd/chaincfg/chainec"
 	"github.com/HcashOrg/hcd/chaincfg/chainhash"
@@ -118,16 +119,6 @@ func (s *fakeChain) SetHeight(height int64) {
 	s.Unlock()
 }
 
-// BestHash returns the current best hash associated with the fake chain
-// instance.
-func (s *fakeChain) BestHash() *chainhash.Hash {
-    s.RLock()
-    hash := &s.currentHash
-    s.RUnlock()
-    return hash
-}
-
-
 // PastMedianTime returns the current median time associated with the fake chain
 // instance.
 func (s *fakeChain) PastMedianTime() time.Time {
@@ -318,6 +309,57 @@ func (p *poolHarness) CreateSignedTx(inputs []spendableOutput, numOutputs uint32
 	return hcutil.NewTx(tx), nil
 }
 
+// CreateSignedTxWithFee creates a new signed transaction that consumes the
+// provided inputs and generates the provided number of outputs by evenly
+// splitting the total input amount less the provided fee.  All outputs will be
+// to the payment script associated with the harness and all inputs are assumed
+// to do the same.
+func (p *poolHarness) CreateSignedTxWithFee(inputs []spendableOutput, numOutputs uint32, fee hcutil.Amount) (*hcutil.Tx, error) {
+	// Calculate the total input amount less the fee and split it amongst
+	// the requested number of outputs.
+	var totalInput hcutil.Amount
+	for _, input := range inputs {
+		totalInput += input.amount
+	}
+	totalInput -= fee
+	amountPerOutput := int64(totalInput) / int64(numOutputs)
+	remainder := int64(totalInput) - amountPerOutput*int64(numOutputs)
+
+	tx := wire.NewMsgTx()
+	for _, input := range inputs {
+		tx.AddTxIn(&wire.TxIn{
+			PreviousOutPoint: input.outPoint,
+			SignatureScript:  nil,
+			Sequence:         wire.MaxTxInSequenceNum,
+			ValueIn:          int64(input.amount),
+		})
+	}
+	for i := uint32(0); i < numOutputs; i++ {
+		// Ensure the final output accounts for any remainder that might
+		// be left from splitting the input amount.
+		amount := amountPerOutput
+		if i == numOutputs-1 {
+			amount = amountPerOutput + remainder
+		}
+		tx.AddTxOut(&wire.TxOut{
+			PkScript: p.payScript,
+			Value:    amount,
+		})
+	}
+
+	// Sign the new transaction.
+	for i := range tx.TxIn {
+		sigScript, err := txscript.SignatureScript(tx, i, p.payScript,
+			txscript.SigHashAll, p.signKey, true)
+		if err != nil {
+			return nil, err
+		}
+		tx.TxIn[i].SignatureScript = sigScript
+	}
+
+	return hcutil.NewTx(tx), nil
+}
+
 // CreateTxChain creates a chain of zero-fee transactions (each subsequent
 // transaction spends the entire amount from the previous one) with the first
 // one spending the provided outpoint.  Each transaction spends the entire
@@ -530,7 +572,6 @@ func TestSimpleOrphanChain(t *testing.T) {
 			t.Fatalf("IsTransactionInPool: false for accepted tx %v",
 				tx.Hash())
 		}
-		fmt.Println(tx)
 	}
 }
 
@@ -577,7 +618,7 @@ func TestOrphanReject(t *testing.T) {
 
 		// Ensure no transactions were reported as accepted.
 		if len(acceptedTxns) != 0 {
-			t.Fatal("ProcessTransaction: reported %d accepted "+
+			t.Fatalf("ProcessTransaction: reported %d accepted "+
 				"transactions from failed orphan attempt",
 				len(acceptedTxns))
 		}
@@ -674,135 +715,630 @@ func TestOrphanEviction(t *testing.T) {
 	}
 }
 
-// add test for tx lock 
-func TestTxLockPool(t *testing.T) {
+// TestAcceptHooks ensures registered external policy hooks are invoked for
+// transactions being accepted and that an error from any hook rejects the
+// transaction.
+func TestAcceptHooks(t *testing.T) {
 	t.Parallel()
-	var txLen = 10
+
 	harness, spendableOuts, err := newPoolHarness(&chaincfg.MainNetParams)
-	for _, v := range spendableOuts {
-		t.Log(v.outPoint.String())
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
 	}
 
+	chainedTxns, err := harness.CreateTxChain(spendableOuts[0], 2)
+	if err != nil {
+		t.Fatalf("unable to create transaction chain: %v", err)
+	}
+
+	// Register a hook which rejects the second transaction in the chain
+	// and records every transaction it is invoked with.
+	var seen []chainhash.Hash
+	rejectHash := *chainedTxns[1].Hash()
+	harness.txPool.RegisterAcceptHook(func(tx *hcutil.Tx, txType stake.TxType, utxoView *blockchain.UtxoViewpoint) error {
+		seen = append(seen, *tx.Hash())
+		if *tx.Hash() == rejectHash {
+			return fmt.Errorf("blacklisted")
+		}
+		return nil
+	})
+
+	_, err = harness.txPool.ProcessTransaction(chainedTxns[0], false,
+		false, true)
+	if err != nil {
+		t.Fatalf("ProcessTransaction: failed to accept valid tx: %v", err)
+	}
+	_, err = harness.txPool.ProcessTransaction(chainedTxns[1], false,
+		false, true)
+	if err == nil {
+		t.Fatal("ProcessTransaction: accepted tx rejected by hook")
+	}
+	rerr, ok := err.(RuleError)
+	if !ok {
+		t.Fatalf("ProcessTransaction: unexpected error type %T", err)
+	}
+	if code, _ := extractRejectCode(rerr); code != wire.RejectNonstandard {
+		t.Fatalf("ProcessTransaction: unexpected reject code %v", code)
+	}
+	if harness.txPool.IsTransactionInPool(&rejectHash) {
+		t.Fatal("IsTransactionInPool: true for tx rejected by hook")
+	}
+	if len(seen) != 2 {
+		t.Fatalf("hook invoked %d times, want 2", len(seen))
+	}
+}
+
+// TestProcessPackage ensures packages of dependent transactions are validated
+// as a unit, that parents below the minimum relay fee are accepted when their
+// children pay for them, and that rejected packages leave the pool untouched.
+func TestProcessPackage(t *testing.T) {
+	t.Parallel()
+
+	harness, spendableOuts, err := newPoolHarness(&chaincfg.MainNetParams)
 	if err != nil {
 		t.Fatalf("unable to create test pool: %v", err)
 	}
+	harness.txPool.cfg.Policy.DisableRelayPriority = false
 
-	// Create a chain of transactions rooted with the first spendable output
-	// provided by the harness.
-	chainedTxns, err := harness.CreateLockTxChain(spendableOuts[0], uint32(txLen))
+	// Create a zero-fee parent along with a zero-fee child and a child
+	// which pays enough fees for both.
+	chainedTxns, err := harness.CreateTxChain(spendableOuts[0], 2)
 	if err != nil {
 		t.Fatalf("unable to create transaction chain: %v", err)
 	}
+	parent, zeroFeeChild := chainedTxns[0], chainedTxns[1]
+	feeChild, err := harness.CreateSignedTxWithFee([]spendableOutput{
+		txOutToSpendableOut(parent, 0)}, 1, 10000)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
 
-	// Ensure orphans are rejected when the allow orphans flag is not set.
-	for _, tx := range chainedTxns[:] {
-		harness.txPool.maybeAddtoLockPool(nil, tx, 0,
-			0, 0)
+	assertRejected := func(name string, txns []*hcutil.Tx, txErrs []error) {
+		t.Helper()
+		for i, tx := range txns {
+			if txErrs[i] == nil {
+				t.Fatalf("%s: tx %d has no rejection reason", name, i)
+			}
+			if _, ok := txErrs[i].(RuleError); !ok {
+				t.Fatalf("%s: unexpected error type %T", name,
+					txErrs[i])
+			}
+			if harness.txPool.IsTransactionInPool(tx.Hash()) {
+				t.Fatalf("%s: tx %d in pool after rejection", name, i)
+			}
+		}
+	}
+
+	// Ensure a package which does not pay the minimum relay fee in
+	// aggregate is rejected.
+	pkg := []*hcutil.Tx{parent, zeroFeeChild}
+	_, txErrs := harness.txPool.ProcessPackage(pkg, false)
+	assertRejected("zero fee package", pkg, txErrs)
+
+	// Ensure a package which is not topologically ordered is rejected.
+	pkg = []*hcutil.Tx{feeChild, parent}
+	_, txErrs = harness.txPool.ProcessPackage(pkg, false)
+	assertRejected("unordered package", pkg, txErrs)
+
+	// Ensure the fee paying child is rejected on its own due to its parent
+	// not being available.
+	_, err = harness.txPool.ProcessTransaction(feeChild, false, false, false)
+	if err == nil {
+		t.Fatal("ProcessTransaction: accepted child without parent")
 	}
 
-	if len(harness.txPool.txLockPool) != txLen {
-		t.Fatalf("maybeAddtoLockPool err")
+	// Ensure the package is accepted when the child pays for the parent.
+	pkg = []*hcutil.Tx{parent, feeChild}
+	acceptedTxns, txErrs := harness.txPool.ProcessPackage(pkg, false)
+	for i, err := range txErrs {
+		if err != nil {
+			t.Fatalf("ProcessPackage: tx %d rejected: %v", i, err)
+		}
+	}
+	if len(acceptedTxns) != len(pkg) {
+		t.Fatalf("ProcessPackage: reported %d accepted transactions, "+
+			"want %d", len(acceptedTxns), len(pkg))
+	}
+	for _, tx := range pkg {
+		if !harness.txPool.IsTransactionInPool(tx.Hash()) {
+			t.Fatalf("IsTransactionInPool: false for accepted tx %v",
+				tx.Hash())
+		}
 	}
+}
 
-	t.Log(harness.txPool.TxLockPoolInfo())
+// TestProcessPackageRejectLeavesPool ensures that a rejected package leaves
+// the pool unchanged, including any prioritisation of its transactions and
+// transactions which would otherwise be evicted for it, and that a package
+// containing transactions which are already in the pool is accepted.
+func TestProcessPackageRejectLeavesPool(t *testing.T) {
+	t.Parallel()
 
-	for _, tx := range chainedTxns[:] {
-		harness.txPool.ModifyLockTransaction(tx, 45668)
+	harness, spendableOuts, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
 	}
+	txPool := harness.txPool
 
-	for _, desc := range harness.txPool.txLockPool {
-		if len(harness.txPool.txLockPool) != txLen || desc.MineHeight != 45668 {
-			t.Fatalf("ModifyLockTransaction 45668 err")
+	// Create a transaction which splits the spendable output in two along
+	// with an unrelated transaction spending its second output.
+	splitTx, err := harness.CreateSignedTxWithFee(spendableOuts, 2, 100000)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	unrelated, err := harness.CreateSignedTxWithFee([]spendableOutput{
+		txOutToSpendableOut(splitTx, 1)}, 1, 50000)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	for _, tx := range []*hcutil.Tx{splitTx, unrelated} {
+		_, err := txPool.ProcessTransaction(tx, false, false, true)
+		if err != nil {
+			t.Fatalf("ProcessTransaction: failed to accept tx %v: %v",
+				tx.Hash(), err)
 		}
 	}
 
-	t.Log(harness.txPool.TxLockPoolInfo())
-	for _, tx := range chainedTxns[:] {
-		harness.txPool.ModifyLockTransaction(tx, 0)
-
+	// Create a zero-fee parent spending the first output along with a
+	// child which pays for it, another child which spends the same output
+	// as the first one and a grandchild.
+	parent, err := harness.CreateSignedTxWithFee([]spendableOutput{
+		txOutToSpendableOut(splitTx, 0)}, 1, 0)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	child, err := harness.CreateSignedTxWithFee([]spendableOutput{
+		txOutToSpendableOut(parent, 0)}, 1, 1000)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
 	}
-	for _, desc := range harness.txPool.txLockPool {
-		if len(harness.txPool.txLockPool) != txLen || desc.MineHeight != 0 {
-			t.Fatalf("ModifyLockTransaction 0 err")
+	doubleSpend, err := harness.CreateSignedTxWithFee([]spendableOutput{
+		txOutToSpendableOut(parent, 0)}, 1, 2000)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	grandchild, err := harness.CreateSignedTxWithFee([]spendableOutput{
+		txOutToSpendableOut(child, 0)}, 1, 1000)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+
+	assertUnchanged := func(name string, txErrs []error, usage int64) {
+		t.Helper()
+		for i, err := range txErrs {
+			if err == nil {
+				t.Fatalf("%s: tx %d has no rejection reason", name, i)
+			}
+		}
+		for _, tx := range []*hcutil.Tx{parent, child, doubleSpend} {
+			if txPool.IsTransactionInPool(tx.Hash()) {
+				t.Fatalf("%s: tx %v in pool after rejection", name,
+					tx.Hash())
+			}
+		}
+		for _, tx := range []*hcutil.Tx{splitTx, unrelated} {
+			if !txPool.IsTransactionInPool(tx.Hash()) {
+				t.Fatalf("%s: tx %v was evicted", name, tx.Hash())
+			}
+		}
+		if txPool.MemoryUsage() != usage {
+			t.Fatalf("%s: memory usage changed from %d to %d", name,
+				usage, txPool.MemoryUsage())
 		}
 	}
 
-	t.Log(harness.txPool.TxLockPoolInfo())
+	// Ensure a package which double spends within itself is rejected
+	// without dropping the prioritisation of its transactions.
+	txPool.PrioritiseTransaction(parent.Hash(), 0, 5000)
+	usage := txPool.MemoryUsage()
+	pkg := []*hcutil.Tx{parent, child, doubleSpend}
+	_, txErrs := txPool.ProcessPackage(pkg, false)
+	assertUnchanged("double spending package", txErrs, usage)
+	txPool.mtx.RLock()
+	delta := txPool.deltas[*parent.Hash()]
+	txPool.mtx.RUnlock()
+	if delta.fee != 5000 {
+		t.Fatalf("ProcessPackage: fee delta of rejected tx is %d, "+
+			"want 5000", delta.fee)
+	}
 
-	for _, tx := range chainedTxns[:] {
-		harness.txPool.ModifyLockTransaction(tx, 45668)
+	// Limit the pool such that adding the package evicts the package
+	// itself due to its low ancestor fee rate and ensure it is rejected
+	// without evicting the unrelated transaction.
+	txPool.cfg.Policy.MaxMempoolSize = usage +
+		txMemoryUsage(parent.MsgTx()) + txMemoryUsage(child.MsgTx())/2
+	pkg = []*hcutil.Tx{parent, child}
+	_, txErrs = txPool.ProcessPackage(pkg, false)
+	assertUnchanged("package exceeding pool size", txErrs, usage)
+
+	// Ensure the package is accepted once the pool is no longer limited
+	// and that the prioritisation was applied.
+	txPool.cfg.Policy.MaxMempoolSize = 0
+	_, txErrs = txPool.ProcessPackage(pkg, false)
+	for i, err := range txErrs {
+		if err != nil {
+			t.Fatalf("ProcessPackage: tx %d rejected: %v", i, err)
+		}
+	}
+	txDesc, err := txPool.FetchTxDesc(parent.Hash())
+	if err != nil {
+		t.Fatalf("FetchTxDesc: %v", err)
+	}
+	if txDesc.FeeDelta != 5000 {
+		t.Fatalf("FetchTxDesc: fee delta is %d, want 5000",
+			txDesc.FeeDelta)
 	}
-	for _, desc := range harness.txPool.txLockPool {
-		if len(harness.txPool.txLockPool) != txLen || desc.MineHeight != 45668 {
-			t.Fatalf("ModifyLockTransaction 45668 err")
+
+	// Ensure a package which contains transactions already in the pool is
+	// accepted and only reports the new transactions as accepted.
+	pkg = []*hcutil.Tx{parent, child, grandchild}
+	acceptedTxns, txErrs := txPool.ProcessPackage(pkg, false)
+	for i, err := range txErrs {
+		if err != nil {
+			t.Fatalf("ProcessPackage: tx %d rejected: %v", i, err)
 		}
 	}
+	if len(acceptedTxns) != 1 || *acceptedTxns[0].Hash() != *grandchild.Hash() {
+		t.Fatalf("ProcessPackage: reported %d accepted transactions, "+
+			"want only the grandchild", len(acceptedTxns))
+	}
+	if !txPool.IsTransactionInPool(grandchild.Hash()) {
+		t.Fatalf("IsTransactionInPool: false for accepted tx %v",
+			grandchild.Hash())
+	}
+}
 
-	t.Log(harness.txPool.TxLockPoolInfo())
+// TestExpireOldTx ensures that transactions which have been in the pool for
+// longer than the configured expiry are evicted along with their descendants
+// while younger unrelated transactions remain.
+func TestExpireOldTx(t *testing.T) {
+	t.Parallel()
 
-	harness.txPool.RemoveConfirmedLockTransaction(45768)
+	harness, spendableOuts, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
 
-	if len(harness.txPool.txLockPool) != 0||len(harness.txPool.lockOutpoints)!=0 {
-		t.Fatalf("RemoveConfirmedLockTransaction err")
+	// Add a transaction which splits the spendable output in two to the
+	// pool along with a chain of transactions spending the first output
+	// and an unrelated transaction spending the second.
+	splitTx, err := harness.CreateSignedTx(spendableOuts, 2)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	chainedTxns, err := harness.CreateTxChain(
+		txOutToSpendableOut(splitTx, 0), 3)
+	if err != nil {
+		t.Fatalf("unable to create transaction chain: %v", err)
+	}
+	unrelated, err := harness.CreateSignedTx([]spendableOutput{
+		txOutToSpendableOut(splitTx, 1)}, 1)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	txns := append([]*hcutil.Tx{splitTx}, chainedTxns...)
+	for _, tx := range append(txns, unrelated) {
+		_, err := harness.txPool.ProcessTransaction(tx, false, false,
+			true)
+		if err != nil {
+			t.Fatalf("ProcessTransaction: failed to accept tx %v: %v",
+				tx.Hash(), err)
+		}
 	}
 
-	//t.Log(harness.txPool.TxLockPoolInfo())
+	// Backdate the root of the chain so that it is older than the expiry.
+	harness.txPool.mtx.Lock()
+	harness.txPool.pool[*chainedTxns[0].Hash()].Added =
+		time.Now().Add(-2 * time.Hour)
+	harness.txPool.mtx.Unlock()
 
-	for _, tx := range chainedTxns[:] {
-		//t.Log(tx.MsgTx().TxIn[0].PreviousOutPoint.String())
-		harness.txPool.maybeAddtoLockPool(nil, tx, 0,
-			0, 0)
+	// Ensure nothing is expired when expiration is disabled.
+	if expired := harness.txPool.ExpireOldTx(time.Now()); len(expired) != 0 {
+		t.Fatalf("ExpireOldTx: expired %d transactions with expiration "+
+			"disabled", len(expired))
 	}
 
+	// Ensure the root of the chain and all of its descendants are expired.
+	harness.txPool.cfg.Policy.MempoolExpiry = time.Hour
+	expired := harness.txPool.ExpireOldTx(time.Now())
+	if len(expired) != len(chainedTxns) {
+		t.Fatalf("ExpireOldTx: expired %d transactions, want %d",
+			len(expired), len(chainedTxns))
+	}
+	for i, tx := range chainedTxns {
+		if *expired[i].Hash() != *tx.Hash() {
+			t.Fatalf("ExpireOldTx: expired tx %d is %v, want %v", i,
+				expired[i].Hash(), tx.Hash())
+		}
+		if harness.txPool.IsTransactionInPool(tx.Hash()) {
+			t.Fatalf("IsTransactionInPool: true for expired tx %v",
+				tx.Hash())
+		}
+	}
+	for _, tx := range []*hcutil.Tx{splitTx, unrelated} {
+		if !harness.txPool.IsTransactionInPool(tx.Hash()) {
+			t.Fatalf("IsTransactionInPool: false for unexpired tx %v",
+				tx.Hash())
+		}
+	}
+}
 
-	if len(harness.txPool.txLockPool) != txLen {
-		t.Fatalf("maybeAddtoLockPool err")
+// TestMempoolSizeLimit ensures that transactions with the lowest ancestor fee
+// rate are evicted once the pool exceeds its maximum size and that the dynamic
+// minimum relay fee is raised as a result.
+func TestMempoolSizeLimit(t *testing.T) {
+	t.Parallel()
+
+	harness, spendableOuts, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
 	}
-	harness.chain.currentHeight=45888
-	t.Log(harness.txPool.FetchPendingLockTx(1))
-	
-	t.Log(harness.txPool.TxLockPoolInfo())
+	txPool := harness.txPool
 
-	for _, tx := range chainedTxns[:] {
+	// Create a transaction which splits the spendable output followed by
+	// transactions spending its outputs with increasing fees.
+	splitTx, err := harness.CreateSignedTxWithFee(spendableOuts, 4, 100000)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	fees := []hcutil.Amount{1000, 5000, 20000, 1000}
+	txns := make([]*hcutil.Tx, 0, len(fees))
+	for i, fee := range fees {
+		tx, err := harness.CreateSignedTxWithFee([]spendableOutput{
+			txOutToSpendableOut(splitTx, uint32(i))}, 1, fee)
+		if err != nil {
+			t.Fatalf("unable to create transaction: %v", err)
+		}
+		txns = append(txns, tx)
+	}
+	lowTx, midTx, highTx, lateLowTx := txns[0], txns[1], txns[2], txns[3]
 
-		chainedTxns2, _ := harness.CreateTxChain(spendableOutput{tx.MsgTx().TxIn[0].PreviousOutPoint, 0}, 1)
+	for _, tx := range []*hcutil.Tx{splitTx, lowTx, midTx} {
+		_, err := txPool.ProcessTransaction(tx, false, false, true)
+		if err != nil {
+			t.Fatalf("ProcessTransaction: failed to accept tx %v: %v",
+				tx.Hash(), err)
+		}
+	}
+	if txPool.MemoryUsage() <= 0 {
+		t.Fatalf("MemoryUsage: unexpected usage %d",
+			txPool.MemoryUsage())
+	}
+	if txPool.MinRelayTxFee() != txPool.cfg.Policy.MinRelayTxFee {
+		t.Fatalf("MinRelayTxFee: got %v, want %v",
+			txPool.MinRelayTxFee(), txPool.cfg.Policy.MinRelayTxFee)
+	}
 
-		harness.txPool.RemoveTxLockDoubleSpends(chainedTxns2[0])
-		//t.Log(harness.txPool.TxLockPoolInfo())
+	// Limit the pool to slightly more than its current size and ensure
+	// adding another transaction evicts the one with the lowest ancestor fee
+	// rate to trim the pool below the trim target.
+	maxSize := txPool.MemoryUsage() + txMemoryUsage(highTx.MsgTx())/2
+	txPool.cfg.Policy.MaxMempoolSize = maxSize
+	_, err = txPool.ProcessTransaction(highTx, false, false, true)
+	if err != nil {
+		t.Fatalf("ProcessTransaction: failed to accept tx %v: %v",
+			highTx.Hash(), err)
+	}
+	if txPool.IsTransactionInPool(lowTx.Hash()) {
+		t.Fatalf("IsTransactionInPool: low fee tx %v was not evicted",
+			lowTx.Hash())
 	}
-	if len(harness.txPool.txLockPool) != 0 ||len(harness.txPool.lockOutpoints)!=0{
-		t.Fatalf("RemoveTxLockDoubleSpends err")
+	for _, tx := range []*hcutil.Tx{splitTx, midTx, highTx} {
+		if !txPool.IsTransactionInPool(tx.Hash()) {
+			t.Fatalf("IsTransactionInPool: tx %v was evicted",
+				tx.Hash())
+		}
+	}
+	targetSize := maxSize / 100 * trimTargetPercent
+	if txPool.MemoryUsage() > targetSize {
+		t.Fatalf("MemoryUsage: usage %d exceeds trim target %d",
+			txPool.MemoryUsage(), targetSize)
 	}
 
-	for _, tx := range chainedTxns[:] {
-		//t.Log(tx.MsgTx().TxIn[0].PreviousOutPoint.String())
-		harness.txPool.maybeAddtoLockPool(nil, tx, 0,
-			0, 0)
+	// Ensure the dynamic minimum relay fee was raised and that a new
+	// transaction which does not pay it is rejected.
+	if txPool.MinRelayTxFee() <= txPool.cfg.Policy.MinRelayTxFee {
+		t.Fatalf("MinRelayTxFee: %v was not raised above %v",
+			txPool.MinRelayTxFee(), txPool.cfg.Policy.MinRelayTxFee)
+	}
+	_, err = txPool.ProcessTransaction(lateLowTx, false, false, true)
+	if err == nil {
+		t.Fatalf("ProcessTransaction: accepted tx %v below the mempool "+
+			"minimum fee", lateLowTx.Hash())
+	}
+	if code, _ := extractRejectCode(err); code != wire.RejectInsufficientFee {
+		t.Fatalf("ProcessTransaction: unexpected reject code %v: %v",
+			code, err)
 	}
+}
+
+// TestAncestorFeeRateCache ensures the cached ancestor fee rate of a pool entry
+// is invalidated once one of its ancestors leaves the pool.
+func TestAncestorFeeRateCache(t *testing.T) {
+	t.Parallel()
 
-	if len(harness.txPool.txLockPool) != txLen {
-		t.Fatalf("maybeAddtoLockPool err")
+	harness, spendableOuts, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
 	}
-	t.Log(harness.txPool.TxLockPoolInfo())
+	txPool := harness.txPool
 
-	for _, tx := range chainedTxns[:txLen/2] {
-		harness.txPool.ModifyLockTransaction(tx, 45668)
+	parentTx, err := harness.CreateSignedTxWithFee(spendableOuts, 1, 100000)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	childTx, err := harness.CreateSignedTxWithFee([]spendableOutput{
+		txOutToSpendableOut(parentTx, 0)}, 1, 1000)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	for _, tx := range []*hcutil.Tx{parentTx, childTx} {
+		_, err := txPool.ProcessTransaction(tx, false, false, true)
+		if err != nil {
+			t.Fatalf("ProcessTransaction: failed to accept tx %v: %v",
+				tx.Hash(), err)
+		}
 	}
 
+	txPool.mtx.Lock()
+	defer txPool.mtx.Unlock()
+	childDesc := txPool.pool[*childTx.Hash()]
+	packageRate := txPool.ancestorFeeRate(childDesc)
+	if !childDesc.haveFeeRate {
+		t.Fatal("ancestorFeeRate: fee rate was not cached")
+	}
 
-	t.Log(harness.txPool.TxLockPoolInfo())
-	for _, tx := range chainedTxns[:] {
+	// Remove the parent as if it was mined and ensure the fee rate of the
+	// child only covers the child itself.
+	txPool.removeTransaction(parentTx, false)
+	if childDesc.haveFeeRate {
+		t.Fatal("removeTransaction: cached fee rate was not invalidated")
+	}
+	wantRate := float64(childDesc.Fee) * 1000 /
+		float64(childTx.MsgTx().SerializeSize())
+	if rate := txPool.ancestorFeeRate(childDesc); rate != wantRate ||
+		rate == packageRate {
+		t.Fatalf("ancestorFeeRate: got %v, want %v", rate, wantRate)
+	}
+}
 
-		chainedTxns2, _ := harness.CreateTxChain(spendableOutput{tx.MsgTx().TxIn[0].PreviousOutPoint, 0}, 1)
+// TestSnapshot ensures the snapshots of the pool are cached while the pool is
+// unchanged and that their diffs reflect added, prioritised and removed
+// transactions.
+func TestSnapshot(t *testing.T) {
+	t.Parallel()
 
-		harness.txPool.RemoveTxLockDoubleSpends(chainedTxns2[0])
-		//t.Log(harness.txPool.TxLockPoolInfo())
+	harness, spendableOuts, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
 	}
+	txPool := harness.txPool
 
-	if len(harness.txPool.txLockPool) != 0 || len(harness.txPool.lockOutpoints) != 0 {
-		t.Fatalf("RemoveTxLockDoubleSpends err")
+	chainedTxns, err := harness.CreateTxChain(spendableOuts[0], 2)
+	if err != nil {
+		t.Fatalf("unable to create transaction chain: %v", err)
+	}
+	empty := txPool.Snapshot()
+	for _, tx := range chainedTxns {
+		_, err := txPool.ProcessTransaction(tx, false, false, true)
+		if err != nil {
+			t.Fatalf("ProcessTransaction: failed to accept tx %v: %v",
+				tx.Hash(), err)
+		}
+	}
+
+	snapshot := txPool.Snapshot()
+	if snapshot.Count() != len(chainedTxns) {
+		t.Fatalf("Snapshot: got %d txns, want %d", snapshot.Count(),
+			len(chainedTxns))
+	}
+	for _, tx := range chainedTxns {
+		if !snapshot.HaveTransaction(tx.Hash()) {
+			t.Fatalf("Snapshot: tx %v is missing", tx.Hash())
+		}
+	}
+	if txPool.Snapshot() != snapshot {
+		t.Fatal("Snapshot: unchanged pool returned a new snapshot")
+	}
+	added, removed := snapshot.Diff(empty)
+	if len(added) != len(chainedTxns) || len(removed) != 0 {
+		t.Fatalf("Diff: got %d added and %d removed, want %d and 0",
+			len(added), len(removed), len(chainedTxns))
 	}
 
-	t.Log(harness.txPool.TxLockPoolInfo())
+	// Ensure prioritising a transaction shows up as a change.
+	txPool.PrioritiseTransaction(chainedTxns[0].Hash(), 0, 1000)
+	prioritised := txPool.Snapshot()
+	added, removed = prioritised.Diff(snapshot)
+	if len(added) != 1 || *added[0].Tx.Hash() != *chainedTxns[0].Hash() ||
+		added[0].FeeDelta != 1000 || len(removed) != 0 {
+		t.Fatalf("Diff: unexpected diff after prioritising: %d added, "+
+			"%d removed", len(added), len(removed))
+	}
+
+	// Ensure removed transactions are reported and the older snapshots
+	// are unaffected.
+	txPool.RemoveTransaction(chainedTxns[1], false)
+	added, removed = txPool.Snapshot().Diff(prioritised)
+	if len(added) != 0 || len(removed) != 1 ||
+		*removed[0].Tx.Hash() != *chainedTxns[1].Hash() {
+		t.Fatalf("Diff: unexpected diff after removing: %d added, "+
+			"%d removed", len(added), len(removed))
+	}
+	if !snapshot.HaveTransaction(chainedTxns[1].Hash()) {
+		t.Fatal("Snapshot: removing a tx changed an older snapshot")
+	}
 }
 
+// TestConcurrentAccess ensures the independently locked parts of the pool can
+// be queried while transactions and orphans are being processed.  It is
+// primarily useful when run with the race detector.
+func TestConcurrentAccess(t *testing.T) {
+	t.Parallel()
+
+	harness, spendableOuts, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	txPool := harness.txPool
+
+	maxOrphans := uint32(txPool.cfg.Policy.MaxOrphanTxs)
+	chainedTxns, err := harness.CreateTxChain(spendableOuts[0], maxOrphans+1)
+	if err != nil {
+		t.Fatalf("unable to create transaction chain: %v", err)
+	}
+
+	// Query every part of the pool while the chain is processed with its
+	// orphans first, so the orphan pool fills up before the transaction
+	// completing the chain moves them all to the main pool.
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				for _, tx := range chainedTxns {
+					txPool.HaveTransaction(tx.Hash())
+					txPool.IsOrphanInPool(tx.Hash())
+					txPool.IsTransactionInPool(tx.Hash())
+				}
+				txPool.Snapshot()
+				txPool.Count()
+				txPool.VoteHashesForBlock(chainhash.Hash{})
+				txPool.RemoveOrphan(&chainhash.Hash{})
+			}
+		}()
+	}
+
+	for _, tx := range chainedTxns[1:] {
+		_, err := txPool.ProcessTransaction(tx, true, false, true)
+		if err != nil {
+			t.Fatalf("ProcessTransaction: failed to accept valid "+
+				"orphan %v", err)
+		}
+	}
+	acceptedTxns, err := txPool.ProcessTransaction(chainedTxns[0], false,
+		false, true)
+	close(done)
+	wg.Wait()
+	if err != nil {
+		t.Fatalf("ProcessTransaction: failed to accept valid tx %v", err)
+	}
+	if len(acceptedTxns) != len(chainedTxns) {
+		t.Fatalf("ProcessTransaction: got %d accepted txns, want %d",
+			len(acceptedTxns), len(chainedTxns))
+	}
+	for _, tx := range chainedTxns {
+		if txPool.IsOrphanInPool(tx.Hash()) ||
+			!txPool.IsTransactionInPool(tx.Hash()) {
+
+			t.Fatalf("tx %v was not moved to the main pool", tx.Hash())
+		}
+	}
+}
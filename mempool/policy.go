@@ -54,6 +54,12 @@ const (
 	// transactions.  This value is in Atoms/1000 bytes.
 	DefaultMinRelayTxFee = hcutil.Amount(1e5)
 
+	// DefaultDustRelayFee is the default fee rate in atoms/1000 bytes used
+	// to determine whether or not a transaction output is considered dust.
+	// It defaults to the minimum relay fee so the dust threshold is
+	// unchanged unless explicitly configured.
+	DefaultDustRelayFee = DefaultMinRelayTxFee
+
 	// maxStandardMultiSigKeys is the maximum number of public keys allowed
 	// in a multi-signature transaction output script for it to be
 	// considered standard.
@@ -77,6 +83,14 @@ const (
 		txscript.ScriptVerifySHA256
 )
 
+// standardScriptPolicy is the policy used to determine whether or not public
+// key scripts and the inputs which spend them are standard.
+var standardScriptPolicy = txscript.StandardPolicy{
+	MaxMultiSigKeys: maxStandardMultiSigKeys,
+	MaxP2SHSigOps:   maxStandardP2SHSigOps,
+	ScriptFlags:     BaseStandardVerifyFlags,
+}
+
 // calcMinRequiredTxRelayFee returns the minimum transaction fee required for a
 // transaction with the passed serialized size to be accepted into the memory
 // pool and relayed.
@@ -203,23 +217,11 @@ func checkInputsStandard(tx *hcutil.Tx, txType stake.TxType, utxoView *blockchai
 		// function.
 		prevOut := txIn.PreviousOutPoint
 		entry := utxoView.LookupEntry(&prevOut.Hash)
-		originPkScriptVer := entry.ScriptVersionByIndex(prevOut.Index)
-		originPkScript := entry.PkScriptByIndex(prevOut.Index)
-		switch txscript.GetScriptClass(originPkScriptVer, originPkScript) {
-		case txscript.ScriptHashTy:
-			numSigOps := txscript.GetPreciseSigOpCount(
-				txIn.SignatureScript, originPkScript, true)
-			if numSigOps > maxStandardP2SHSigOps {
-				str := fmt.Sprintf("transaction input #%d has "+
-					"%d signature operations which is more "+
-					"than the allowed max amount of %d",
-					i, numSigOps, maxStandardP2SHSigOps)
-				return txRuleError(wire.RejectNonstandard, str)
-			}
-
-		case txscript.NonStandardTy:
-			str := fmt.Sprintf("transaction input #%d has a "+
-				"non-standard script form", i)
+		analysis := entry.ScriptAnalysisByIndex(prevOut.Index)
+		err := analysis.CheckStandardInput(txIn.SignatureScript,
+			&standardScriptPolicy)
+		if err != nil {
+			str := fmt.Sprintf("transaction input #%d: %v", i, err)
 			return txRuleError(wire.RejectNonstandard, str)
 		}
 	}
@@ -232,132 +234,91 @@ func checkInputsStandard(tx *hcutil.Tx, txType stake.TxType, utxoView *blockchai
 // A standard public key script is one that is a recognized form, and for
 // multi-signature scripts, only contains from 1 to maxStandardMultiSigKeys
 // public keys.
-func checkPkScriptStandard(version uint16, pkScript []byte,
-	scriptClass txscript.ScriptClass) error {
-	// Only default Bitcoin-style script is standard except for
-	// null data outputs.
-	if version != wire.DefaultPkScriptVersion {
-		str := fmt.Sprintf("versions other than default pkscript version " +
-			"are currently non-standard except for provably unspendable " +
-			"outputs")
-		return txRuleError(wire.RejectNonstandard, str)
+func checkPkScriptStandard(version uint16, pkScript []byte) error {
+	err := txscript.Analyze(version, pkScript).CheckStandard(
+		&standardScriptPolicy)
+	if err != nil {
+		return txRuleError(wire.RejectNonstandard, err.Error())
 	}
 
-	switch scriptClass {
-	case txscript.MultiSigTy:
-		numPubKeys, numSigs, err := txscript.CalcMultiSigStats(pkScript)
-		if err != nil {
-			str := fmt.Sprintf("multi-signature script parse "+
-				"failure: %v", err)
-			return txRuleError(wire.RejectNonstandard, str)
-		}
-
-		// A standard multi-signature public key script must contain
-		// from 1 to maxStandardMultiSigKeys public keys.
-		if numPubKeys < 1 {
-			str := "multi-signature script with no pubkeys"
-			return txRuleError(wire.RejectNonstandard, str)
-		}
-		if numPubKeys > maxStandardMultiSigKeys {
-			str := fmt.Sprintf("multi-signature script with %d "+
-				"public keys which is more than the allowed "+
-				"max of %d", numPubKeys, maxStandardMultiSigKeys)
-			return txRuleError(wire.RejectNonstandard, str)
-		}
-
-		// A standard multi-signature public key script must have at
-		// least 1 signature and no more signatures than available
-		// public keys.
-		if numSigs < 1 {
-			return txRuleError(wire.RejectNonstandard,
-				"multi-signature script with no signatures")
-		}
-		if numSigs > numPubKeys {
-			str := fmt.Sprintf("multi-signature script with %d "+
-				"signatures which is more than the available "+
-				"%d public keys", numSigs, numPubKeys)
-			return txRuleError(wire.RejectNonstandard, str)
-		}
+	return nil
+}
 
-	case txscript.NonStandardTy:
-		return txRuleError(wire.RejectNonstandard,
-			"non-standard script form")
+// dustInputSize returns the minimum size of a typical input that redeems an
+// output of the passed script class.  It is used to determine the cost to the
+// network of eventually spending an output when calculating whether or not it
+// is dust.
+//
+// Pay-to-pubkey-hash bytes breakdown:
+//
+//  Input with compressed pubkey (165 bytes):
+//   37 prev outpoint, 4 sequence, 16 fraud proof, 1 script len,
+//   107 script [1 OP_DATA_72, 72 sig, 1 OP_DATA_33, 33 compressed
+//   pubkey]
+//
+//  Input with uncompressed pubkey (197 bytes):
+//   37 prev outpoint, 4 sequence, 16 fraud proof, 1 script len,
+//   139 script [1 OP_DATA_72, 72 sig, 1 OP_DATA_65, 65 uncompressed
+//   pubkey]
+//
+// Pay-to-pubkey bytes breakdown:
+//
+//  Input (131 bytes):
+//   37 prev outpoint, 4 sequence, 16 fraud proof, 1 script len,
+//   73 script [1 OP_DATA_72, 72 sig]
+//
+// Stake tagged outputs (ticket change, vote and revocation outputs) are
+// redeemed with the same signature script as their untagged
+// pay-to-pubkey-hash or pay-to-script-hash counterparts, so they use the
+// pay-to-pubkey-hash figure as well.  The same figure is used for
+// pay-to-script-hash outputs since the redeem script is unknown and the most
+// common redemption is a single signature.
+func dustInputSize(scriptClass txscript.ScriptClass) int {
+	switch scriptClass {
+	case txscript.PubKeyTy, txscript.PubkeyAltTy:
+		return 131
 	}
 
-	return nil
+	return 165
 }
 
 // isDust returns whether or not the passed transaction output amount is
-// considered dust or not based on the passed minimum transaction relay fee.
-// Dust is defined in terms of the minimum transaction relay fee.  In
-// particular, if the cost to the network to spend coins is more than 1/3 of the
-// minimum transaction relay fee, it is considered dust.
-func isDust(txOut *wire.TxOut, minRelayTxFee hcutil.Amount) bool {
+// considered dust or not based on the passed dust relay fee.  In particular,
+// if the cost to the network to spend coins is more than 1/3 of the dust
+// relay fee, it is considered dust.
+func isDust(txOut *wire.TxOut, dustRelayFee hcutil.Amount) bool {
 	// Unspendable outputs are considered dust.
 	if txscript.IsUnspendable(txOut.Value, txOut.PkScript) {
 		return true
 	}
 
 	// The total serialized size consists of the output and the associated
-	// input script to redeem it.  Since there is no input script
-	// to redeem it yet, use the minimum size of a typical input script.
+	// input script to redeem it.  Since there is no input script to redeem
+	// it yet, use the minimum size of a typical input script for the class
+	// of the output script.
 	//
-	// Pay-to-pubkey-hash bytes breakdown:
+	// Pay-to-pubkey-hash output bytes breakdown:
 	//
 	//  Output to hash (36 bytes):
 	//   8 value, 2 script version, 1 script len, 25 script [1 OP_DUP,
 	//   1 OP_HASH_160, 1 OP_DATA_20, 20 hash, 1 OP_EQUALVERIFY,
 	//   1 OP_CHECKSIG]
 	//
-	//  Input with compressed pubkey (165 bytes):
-	//   37 prev outpoint, 4 sequence, 16 fraud proof, 1 script len,
-	//   107 script [1 OP_DATA_72, 72 sig, 1 OP_DATA_33, 33 compressed
-	//   pubkey]
-	//
-	//  Input with uncompressed pubkey (197 bytes):
-	//   37 prev outpoint, 4 sequence, 16 fraud proof, 1 script len,
-	//   139 script [1 OP_DATA_72, 72 sig, 1 OP_DATA_65, 65 uncompressed
-	//   pubkey]
-	//
-	// Pay-to-pubkey bytes breakdown:
-	//
-	//  Output to compressed pubkey (46 bytes):
-	//   8 value, 2 script version, 1 script len, 35 script [1 OP_DATA_33,
-	//   33 compressed pubkey, 1 OP_CHECKSIG]
-	//
-	//  Output to uncompressed pubkey (78 bytes):
-	//   8 value, 2 script version, 1 script len, 67 script [1 OP_DATA_65,
-	//   65 uncompressed pubkey, 1 OP_CHECKSIG]
-	//
-	//  Input (131 bytes):
-	//   37 prev outpoint, 4 sequence, 16 fraud proof, 1 script len,
-	//   73 script [1 OP_DATA_72, 72 sig]
-	//
-	// Theoretically this could examine the script type of the output script
-	// and use a different size for the typical input script size for
-	// pay-to-pubkey vs pay-to-pubkey-hash inputs per the above breakdowns,
-	// but the only combinination which is less than the value chosen is
-	// a pay-to-pubkey script with a compressed pubkey, which is not very
-	// common.
-	//
-	// The most common scripts are pay-to-pubkey-hash, and as per the above
-	// breakdown, the minimum size of a p2pkh input script is 165 bytes.  So
-	// that figure is used.
-	totalSize := txOut.SerializeSize() + 165
+	// See dustInputSize for the breakdown of the input sizes.
+	scriptClass := txscript.GetScriptClass(txOut.Version, txOut.PkScript)
+	totalSize := txOut.SerializeSize() + dustInputSize(scriptClass)
 
 	// The output is considered dust if the cost to the network to spend the
-	// coins is more than 1/3 of the minimum free transaction relay fee.
-	// minFreeTxRelayFee is in Atom/KB, so multiply by 1000 to convert to
-	// bytes.
+	// coins is more than 1/3 of the dust relay fee.  dustRelayFee is in
+	// Atom/KB, so multiply by 1000 to convert to bytes.
 	//
 	// Using the typical values for a pay-to-pubkey-hash transaction from
-	// the breakdown above and the default minimum free transaction relay
-	// fee of 100000, this equates to values less than 60300 atoms being
-	// considered dust.
+	// the breakdown above and the default dust relay fee of 100000, this
+	// equates to values less than 60300 atoms being considered dust.
 	//
 	// The following is equivalent to (value/totalSize) * (1/3) * 1000
 	// without needing to do floating point math.
-	return txOut.Value*1000/(3*int64(totalSize)) < int64(minRelayTxFee)
+	return txOut.Value*1000/(3*int64(totalSize)) < int64(dustRelayFee)
 }
 
 // checkTransactionStandard performs a series of checks on a transaction to
@@ -366,9 +327,10 @@ func isDust(txOut *wire.TxOut, minRelayTxFee hcutil.Amount) bool {
 // "sane" transaction such as having a version in the supported range, being
 // finalized, conforming to more stringent size constraints, having scripts
 // of recognized forms, and not containing "dust" outputs (those that are
-// so small it costs more to process them than they are worth).
+// so small it costs more to process them than they are worth as determined
+// by the passed dust relay fee).
 func checkTransactionStandard(tx *hcutil.Tx, txType stake.TxType, height int64,
-	medianTime time.Time, minRelayTxFee hcutil.Amount,
+	medianTime time.Time, dustRelayFee hcutil.Amount,
 	maxTxVersion uint16) error {
 
 	// The transaction must be a currently supported version and serialize
@@ -431,7 +393,7 @@ func checkTransactionStandard(tx *hcutil.Tx, txType stake.TxType, height int64,
 	numNullDataOutputs := 0
 	for i, txOut := range msgTx.TxOut {
 		scriptClass := txscript.GetScriptClass(txOut.Version, txOut.PkScript)
-		err := checkPkScriptStandard(txOut.Version, txOut.PkScript, scriptClass)
+		err := checkPkScriptStandard(txOut.Version, txOut.PkScript)
 		if err != nil {
 			// Attempt to extract a reject code from the error so
 			// it can be retained.  When not possible, fall back to
@@ -445,11 +407,24 @@ func checkTransactionStandard(tx *hcutil.Tx, txType stake.TxType, height int64,
 		}
 
 		// Accumulate the number of outputs which only carry data.  For
-		// all other script types, ensure the output value is not
-		// "dust".
-		if scriptClass == txscript.NullDataTy {
+		// all other regular transaction script types, ensure the output
+		// value is not "dust".  Stake transactions commit to exact
+		// amounts, so only their change outputs are checked and only
+		// when they actually carry value since ticket purchases without
+		// change still include zero-valued change outputs.
+		switch {
+		case scriptClass == txscript.NullDataTy:
 			numNullDataOutputs++
-		} else if txType == stake.TxTypeRegular && isDust(txOut, minRelayTxFee) {
+
+		case scriptClass == txscript.StakeSubChangeTy:
+			if txOut.Value != 0 && isDust(txOut, dustRelayFee) {
+				str := fmt.Sprintf("transaction output %d: "+
+					"stake change of %d is dust", i,
+					txOut.Value)
+				return txRuleError(wire.RejectDust, str)
+			}
+
+		case txType == stake.TxTypeRegular && isDust(txOut, dustRelayFee):
 			str := fmt.Sprintf("transaction output %d: payment "+
 				"of %d is dust", i, txOut.Value)
 			return txRuleError(wire.RejectDust, str)
@@ -89,7 +89,7 @@ func TestCalcMinRequiredTxRelayFee(t *testing.T) {
 			"2800 bytes with 2000 relay fee",
 			2800,
 			2000,
-			4500,
+			5600,
 		},
 	}
 
@@ -197,8 +197,7 @@ func TestCheckPkScriptStandard(t *testing.T) {
 				"failed: %v", test.name, err)
 			continue
 		}
-		scriptClass := txscript.GetScriptClass(0, script)
-		got := checkPkScriptStandard(0, script, scriptClass)
+		got := checkPkScriptStandard(0, script)
 		if (test.isStandard && got != nil) ||
 			(!test.isStandard && got == nil) {
 
@@ -215,6 +214,12 @@ func TestDust(t *testing.T) {
 		0xeb, 0x46, 0x14, 0xa3, 0x4b, 0x1e, 0x88, 0x61, 0xe7,
 		0x55, 0x4f, 0xd4, 0x13, 0xf7, 0xa6, 0x47, 0x88, 0xac}
 
+	// Pay-to-pubkey script with a compressed pubkey and a stake change
+	// tagged pay-to-pubkey-hash script.
+	p2pkScript := append(append([]byte{0x21, 0x02},
+		bytes.Repeat([]byte{0x01}, 32)...), 0xac)
+	sstxChangeScript := append([]byte{0xbd}, pkScript...)
+
 	tests := []struct {
 		name     string // test description
 		txOut    wire.TxOut
@@ -259,6 +264,30 @@ func TestDust(t *testing.T) {
 			1e5,
 			false,
 		},
+		{
+			"35 byte p2pk script with value 530, relay fee 1e3",
+			wire.TxOut{Value: 530, Version: 0, PkScript: p2pkScript},
+			1000,
+			true,
+		},
+		{
+			"35 byte p2pk script with value 531, relay fee 1e3",
+			wire.TxOut{Value: 531, Version: 0, PkScript: p2pkScript},
+			1000,
+			false,
+		},
+		{
+			"26 byte stake change script with value 605, relay fee 1e3",
+			wire.TxOut{Value: 605, Version: 0, PkScript: sstxChangeScript},
+			1000,
+			true,
+		},
+		{
+			"26 byte stake change script with value 606, relay fee 1e3",
+			wire.TxOut{Value: 606, Version: 0, PkScript: sstxChangeScript},
+			1000,
+			false,
+		},
 		{
 			// Maximum int64 value causes overflow.
 			"maximum int64 value",
@@ -529,7 +558,7 @@ func TestCheckTransactionStandard(t *testing.T) {
 		// Ensure standardness is as expected.
 		tx := hcutil.NewTx(&test.tx)
 		err := checkTransactionStandard(tx, stake.DetermineTxType(&test.tx),
-			test.height, medianTime, DefaultMinRelayTxFee,
+			test.height, medianTime, DefaultDustRelayFee,
 			maxTxVersion)
 		if err == nil && test.isStandard {
 			// Test passes since function returned standard for a
@@ -31,6 +31,14 @@ type TxDesc struct {
 
 	// Fee is the total fee the transaction associated with the entry pays.
 	Fee int64
+
+	// FeeDelta is added to Fee when ordering the transaction for inclusion
+	// in a block.  It does not change the fee the transaction pays.
+	FeeDelta int64
+
+	// PriorityDelta is added to the priority of the transaction when
+	// ordering it for inclusion in a block.
+	PriorityDelta float64
 }
 
 // TxSource represents a source of transactions to consider for inclusion in
@@ -47,6 +55,11 @@ type TxSource interface {
 	// transactions in the source pool.
 	MiningDescs() []*TxDesc
 
+	// Snapshot returns a consistent snapshot of the transactions in the
+	// source pool.  It should be cheap to call repeatedly while the source
+	// pool does not change.
+	Snapshot() *TxSourceSnapshot
+
 	// HaveTransaction returns whether or not the passed transaction hash
 	// exists in the source pool.
 	HaveTransaction(hash *chainhash.Hash) bool
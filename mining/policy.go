@@ -5,8 +5,6 @@
 
 package mining
 
-import "github.com/HcashOrg/hcd/hcutil"
-
 // Policy houses the policy (configuration parameters) which is used to control
 // the generation of block templates.  See the documentation for
 // NewBlockTemplate for more details on each of these parameters are used.
@@ -22,9 +20,4 @@ type Policy struct {
 	// BlockPrioritySize is the size in bytes for high-priority / low-fee
 	// transactions to be used when generating a block template.
 	BlockPrioritySize uint32
-
-	// TxMinFreeFee is the minimum fee in Atoms/1000 bytes that is
-	// required for a transaction to be treated as free for mining purposes
-	// (block template generation).
-	TxMinFreeFee hcutil.Amount
 }
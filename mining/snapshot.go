@@ -0,0 +1,80 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package mining
+
+import (
+	"github.com/HcashOrg/hcd/chaincfg/chainhash"
+)
+
+// TxSourceSnapshot is a consistent view of the transactions in a transaction
+// source at one point in time.  It is immutable, so it can be used without
+// holding any lock of the source while the source keeps changing, and it can
+// be shared by concurrent users.
+//
+// The descriptors of a snapshot must not be modified.  Sources replace the
+// descriptor of a transaction rather than modifying it, so comparing the
+// descriptors of two snapshots tells whether a transaction changed.
+type TxSourceSnapshot struct {
+	descs  []*TxDesc
+	byHash map[chainhash.Hash]*TxDesc
+}
+
+// NewTxSourceSnapshot returns a snapshot of the transactions with the passed
+// descriptors.  The caller must not modify the slice or the descriptors
+// afterwards.
+func NewTxSourceSnapshot(descs []*TxDesc) *TxSourceSnapshot {
+	byHash := make(map[chainhash.Hash]*TxDesc, len(descs))
+	for _, desc := range descs {
+		byHash[*desc.Tx.Hash()] = desc
+	}
+	return &TxSourceSnapshot{
+		descs:  descs,
+		byHash: byHash,
+	}
+}
+
+// Descs returns the descriptors of all transactions in the snapshot.  The
+// returned slice must not be modified.
+func (s *TxSourceSnapshot) Descs() []*TxDesc {
+	return s.descs
+}
+
+// Count returns the number of transactions in the snapshot.
+func (s *TxSourceSnapshot) Count() int {
+	return len(s.descs)
+}
+
+// HaveTransaction returns whether or not the passed transaction hash exists in
+// the snapshot.
+func (s *TxSourceSnapshot) HaveTransaction(hash *chainhash.Hash) bool {
+	_, ok := s.byHash[*hash]
+	return ok
+}
+
+// Diff returns the changes from the passed previous snapshot to this one.  The
+// added descriptors are those of transactions which are new or whose
+// descriptor changed, such as when their fee delta was updated, and the
+// removed descriptors are those of transactions which are gone.  A nil
+// previous snapshot is treated as empty.
+func (s *TxSourceSnapshot) Diff(prev *TxSourceSnapshot) (added, removed []*TxDesc) {
+	if prev == s {
+		return nil, nil
+	}
+	if prev == nil {
+		return s.descs, nil
+	}
+
+	for _, desc := range s.descs {
+		if prevDesc, ok := prev.byHash[*desc.Tx.Hash()]; !ok || prevDesc != desc {
+			added = append(added, desc)
+		}
+	}
+	for _, prevDesc := range prev.descs {
+		if _, ok := s.byHash[*prevDesc.Tx.Hash()]; !ok {
+			removed = append(removed, prevDesc)
+		}
+	}
+	return added, removed
+}
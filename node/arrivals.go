@@ -0,0 +1,91 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package node
+
+import (
+	"sync"
+	"time"
+
+	"github.com/HcashOrg/hcd/chaincfg/chainhash"
+)
+
+const (
+	// maxBlockArrivals is the maximum number of blocks the time they were
+	// first seen is remembered for.
+	maxBlockArrivals = 10000
+
+	// maxTxArrivals is the maximum number of transactions the time they
+	// were first seen is remembered for.
+	maxTxArrivals = 100000
+
+	// arrivalSourceRPC is the source recorded for blocks and transactions
+	// first seen via the RPC server.
+	arrivalSourceRPC = "rpc"
+)
+
+// arrival describes when and where a block or transaction was first seen.
+type arrival struct {
+	Time   time.Time
+	Source string
+}
+
+// arrivalIndex is a lightweight index of the time blocks or transactions were
+// first seen by the server along with the source they were received from,
+// which is either the address of the peer or arrivalSourceRPC.  It is intended
+// for research into propagation times and the analysis of double spend
+// attempts, so transactions are recorded whether or not they are accepted to
+// the memory pool.
+//
+// Only the most recently first seen entries up to the limit the index was
+// created with are remembered.  The oldest entries are evicted first.
+//
+// The index is safe for concurrent access.
+type arrivalIndex struct {
+	mtx      sync.Mutex
+	arrivals map[chainhash.Hash]arrival
+	order    []chainhash.Hash
+	next     int
+}
+
+// newArrivalIndex returns an arrival index which remembers up to limit
+// entries.
+func newArrivalIndex(limit int) *arrivalIndex {
+	return &arrivalIndex{
+		arrivals: make(map[chainhash.Hash]arrival),
+		order:    make([]chainhash.Hash, 0, limit),
+	}
+}
+
+// record records that the passed hash was seen at the passed time from the
+// passed source.  Nothing is recorded when the hash was seen before.
+func (a *arrivalIndex) record(hash *chainhash.Hash, source string,
+	now time.Time) {
+
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	if _, ok := a.arrivals[*hash]; ok {
+		return
+	}
+
+	// Evict the oldest entry once the index is full.
+	if len(a.order) < cap(a.order) {
+		a.order = append(a.order, *hash)
+	} else {
+		delete(a.arrivals, a.order[a.next])
+		a.order[a.next] = *hash
+		a.next = (a.next + 1) % len(a.order)
+	}
+	a.arrivals[*hash] = arrival{Time: now, Source: source}
+}
+
+// lookup returns when and where the passed hash was first seen and whether or
+// not it is known to the index.
+func (a *arrivalIndex) lookup(hash *chainhash.Hash) (arrival, bool) {
+	a.mtx.Lock()
+	r, ok := a.arrivals[*hash]
+	a.mtx.Unlock()
+	return r, ok
+}
@@ -0,0 +1,44 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/HcashOrg/hcd/chaincfg/chainhash"
+)
+
+// TestArrivalIndex ensures the arrival index remembers the first time and
+// source a hash was seen and evicts the oldest entries once full.
+func TestArrivalIndex(t *testing.T) {
+	idx := newArrivalIndex(2)
+	now := time.Unix(1500000000, 0)
+	hashes := []chainhash.Hash{{0x01}, {0x02}, {0x03}}
+
+	// Only the first sighting is recorded.
+	idx.record(&hashes[0], "127.0.0.1:14008", now)
+	idx.record(&hashes[0], arrivalSourceRPC, now.Add(time.Second))
+	r, ok := idx.lookup(&hashes[0])
+	if !ok {
+		t.Fatalf("hash %v not found", hashes[0])
+	}
+	if !r.Time.Equal(now) || r.Source != "127.0.0.1:14008" {
+		t.Fatalf("unexpected arrival: got %v from %q, want %v from %q",
+			r.Time, r.Source, now, "127.0.0.1:14008")
+	}
+
+	// The oldest entry is evicted once the index is full.
+	idx.record(&hashes[1], arrivalSourceRPC, now.Add(2*time.Second))
+	idx.record(&hashes[2], arrivalSourceRPC, now.Add(3*time.Second))
+	if _, ok := idx.lookup(&hashes[0]); ok {
+		t.Fatalf("hash %v was not evicted", hashes[0])
+	}
+	for _, hash := range hashes[1:] {
+		if _, ok := idx.lookup(&hash); !ok {
+			t.Fatalf("hash %v not found", hash)
+		}
+	}
+}
@@ -4,11 +4,10 @@
 // Use of this source code is governed by an ISC
 // license that can be found in the LICENSE file.
 
-package main
+package node
 
 import (
 	"container/list"
-	"encoding/binary"
 	"fmt"
 	"math/rand"
 	"os"
@@ -23,6 +22,7 @@ import (
 	"github.com/HcashOrg/hcd/chaincfg/chainhash"
 	"github.com/HcashOrg/hcd/database"
 	"github.com/HcashOrg/hcd/mempool"
+	"github.com/HcashOrg/hcd/tracing"
 	"github.com/HcashOrg/hcd/wire"
 	"github.com/HcashOrg/hcd/hcutil"
 )
@@ -33,6 +33,11 @@ const (
 	// more.
 	minInFlightBlocks = 10
 
+	// syncStallCheckInterval is the interval at which the sync peer is
+	// checked for failing to deliver the requested blocks or headers within
+	// the configured sync stall timeout.
+	syncStallCheckInterval = 5 * time.Second
+
 	// blockDbNamePrefix is the prefix for the block database name.  The
 	// database type is appended to this value to form the full block
 	// database name.
@@ -42,14 +47,25 @@ const (
 	// block or transaction before it is dropped.
 	maxResendLimit = 3
 
+	// maxUnrequestedBlockDepth is the maximum number of blocks the height
+	// of a block which was never requested may be below the best block for
+	// the block to be processed anyway.  Newly mined blocks pushed by peers
+	// are close to the tip, while other blocks are only useful when they
+	// were requested.
+	maxUnrequestedBlockDepth = 6
+
+	// unrequestedBlockBanScore is the transient ban score increase applied
+	// for each block which was never requested and is ignored.
+	unrequestedBlockBanScore = 50
+
+	// unrequestedTxBanScore is the transient ban score increase applied for
+	// each transaction which was not requested and is ignored.
+	unrequestedTxBanScore = 5
+
 	// maxRejectedTxns is the maximum number of rejected transactions
 	// hashes to store in memory.
 	maxRejectedTxns = 1000
 
-	// maxRequestedBlocks is the maximum number of requested block
-	// hashes to store in memory.
-	maxRequestedBlocks = wire.MaxInvPerMsg
-
 	// maxRequestedTxns is the maximum number of requested transactions
 	// hashes to store in memory.
 	maxRequestedTxns = wire.MaxInvPerMsg
@@ -86,6 +102,13 @@ type invMsg struct {
 	peer *serverPeer
 }
 
+// notFoundMsg packages a hcd notfound message and the peer it came from
+// together so the block handler has access to that information.
+type notFoundMsg struct {
+	notFound *wire.MsgNotFound
+	peer     *serverPeer
+}
+
 // headersMsg packages a hcd headers message and the peer it came from
 // together so the block handler has access to that information.
 type headersMsg struct {
@@ -208,6 +231,14 @@ type forceReorganizationMsg struct {
 	reply      chan forceReorganizationResponse
 }
 
+// reconsiderBlockMsg is a message type to be sent across the message channel
+// for approving a reorganization to the side chain containing a block which
+// exceeds the maximum automatic reorganization depth.
+type reconsiderBlockMsg struct {
+	hash  chainhash.Hash
+	reply chan error
+}
+
 // getTopBlockResponse is a response to the request for the block at HEAD of the
 // blockchain. We need to be able to obtain this from blockChain for mining
 // purposes.
@@ -235,10 +266,15 @@ type processBlockResponse struct {
 // above in that blockMsg is intended for blocks that came from peers and have
 // extra handling whereas this message essentially is just a concurrent safe
 // way to call ProcessBlock on the internal block chain instance.
+//
+// The span is the span of the traced request processing the block, if any,
+// and the queue span records the time the message waits to be handled.
 type processBlockMsg struct {
-	block *hcutil.Block
-	flags blockchain.BehaviorFlags
-	reply chan processBlockResponse
+	block     *hcutil.Block
+	flags     blockchain.BehaviorFlags
+	span      *tracing.Span
+	queueSpan *tracing.Span
+	reply     chan processBlockResponse
 }
 
 // processTransactionResponse is a response sent to the reply channel of a
@@ -251,14 +287,35 @@ type processTransactionResponse struct {
 // processTransactionMsg is a message type to be sent across the message
 // channel for requesting a transaction to be processed through the block
 // manager.
+//
+// The span is the span of the traced request processing the transaction, if
+// any, and the queue span records the time the message waits to be handled.
 type processTransactionMsg struct {
 	tx            *hcutil.Tx
 	allowOrphans  bool
 	rateLimit     bool
 	allowHighFees bool
+	span          *tracing.Span
+	queueSpan     *tracing.Span
 	reply         chan processTransactionResponse
 }
 
+// processPackageResponse is a response sent to the reply channel of a
+// processPackageMsg.
+type processPackageResponse struct {
+	acceptedTxs []*hcutil.Tx
+	txErrs      []error
+}
+
+// processPackageMsg is a message type to be sent across the message channel
+// for requesting a package of dependent transactions to be processed through
+// the block manager.
+type processPackageMsg struct {
+	txns          []*hcutil.Tx
+	allowHighFees bool
+	reply         chan processPackageResponse
+}
+
 // isCurrentMsg is a message type to be sent across the message channel for
 // requesting whether or not the block manager believes it is synced with
 // the currently connected peers.
@@ -418,12 +475,14 @@ type blockManager struct {
 	rejectedTxns        map[chainhash.Hash]struct{}
 	requestedTxns       map[chainhash.Hash]struct{}
 	requestedEverTxns   map[chainhash.Hash]uint8
-	requestedBlocks     map[chainhash.Hash]struct{}
+	requestedBlocks     *inFlightBlocks
 	requestedEverBlocks map[chainhash.Hash]uint8
 	progressLogger      *blockProgressLogger
 	syncPeer            *serverPeer
+	syncPeerProgress    time.Time
 	msgChan             chan interface{}
 	chainState          chainState
+	syncProgress        *syncProgress
 	wg                  sync.WaitGroup
 	quit                chan struct{}
 
@@ -433,6 +492,14 @@ type blockManager struct {
 	startHeader      *list.Element
 	nextCheckpoint   *chaincfg.Checkpoint
 
+	// assumeValid is the height and hash of the block assumed to be valid.
+	// Once past the final checkpoint, headers-first mode downloads the
+	// headers up to it so the scripts of its ancestors do not need to be
+	// validated.  It is nil when disabled, once the block is in the main
+	// chain or the best chain is past its height, or once a sync peer does
+	// not know it.
+	assumeValid *chaincfg.Checkpoint
+
 	// lotteryDataBroadcastMutex is a mutex protecting the map
 	// that checks if block lottery data has been broadcasted
 	// yet for any given block, so notifications are never
@@ -452,10 +519,11 @@ func (b *blockManager) resetHeaderState(newestHash *chainhash.Hash, newestHeight
 	b.headerList.Init()
 	b.startHeader = nil
 
-	// When there is a next checkpoint, add an entry for the latest known
-	// block into the header pool.  This allows the next downloaded header
-	// to prove it links to the chain properly.
-	if b.nextCheckpoint != nil {
+	// When there is a next checkpoint or a block assumed to be valid, add
+	// an entry for the latest known block into the header pool.  This
+	// allows the next downloaded header to prove it links to the chain
+	// properly.
+	if b.nextCheckpoint != nil || b.assumeValid != nil {
 		node := headerNode{height: newestHeight, hash: newestHash}
 		b.headerList.PushBack(&node)
 	}
@@ -484,6 +552,60 @@ func (b *blockManager) updateChainState(newestHash *chainhash.Hash,
 	b.chainState.curPrevHash = curPrevHash
 }
 
+// headersTarget returns the hash of the block headers-first mode downloads
+// the headers up to.  That is the next checkpoint or, once past the final
+// checkpoint, the block assumed to be valid.
+func (b *blockManager) headersTarget() *chainhash.Hash {
+	if b.nextCheckpoint != nil {
+		return b.nextCheckpoint.Hash
+	}
+	if b.assumeValid != nil {
+		return b.assumeValid.Hash
+	}
+	return nil
+}
+
+// assumeValidAhead returns whether the block assumed to be valid is ahead of
+// the best chain, so the headers up to it are still to be downloaded.  That is
+// not the case once the block is in the main chain or the best chain is at or
+// past its height without it.
+func (b *blockManager) assumeValidAhead() bool {
+	if b.chain.BestSnapshot().Height >= b.assumeValid.Height {
+		return false
+	}
+	inMainChain, err := b.chain.MainChainHasBlock(b.assumeValid.Hash)
+	if err != nil {
+		bmgrLog.Warnf("Failed to look up block %v assumed to be "+
+			"valid: %v", b.assumeValid.Hash, err)
+	}
+	return !inMainChain
+}
+
+// abandonAssumeValid gives up on downloading the headers up to the block
+// assumed to be valid because the sync peer does not know it, and switches to
+// normal mode so all blocks are fully validated.
+func (b *blockManager) abandonAssumeValid(sp *serverPeer) {
+	bmgrLog.Warnf("Peer %s does not know block %v assumed to be valid -- "+
+		"switching to normal mode", sp.Addr(), b.assumeValid.Hash)
+	b.assumeValid = nil
+	best := b.chain.BestSnapshot()
+	b.resetHeaderState(best.Hash, best.Height)
+
+	locator, err := b.chain.LatestBlockLocator()
+	if err != nil {
+		bmgrLog.Errorf("Failed to get block locator for the latest "+
+			"block: %v", err)
+		return
+	}
+	err = sp.PushGetBlocksMsg(locator, &zeroHash)
+	if err != nil {
+		bmgrLog.Warnf("Failed to send getblocks message to peer %s: %v",
+			sp.Addr(), err)
+		return
+	}
+	b.syncProgress.setState(syncStateBlocks)
+}
+
 // findNextHeaderCheckpoint returns the next checkpoint after the passed height.
 // It returns nil when there is not one either because the height is already
 // later than the final checkpoint or some other reason such as disabled
@@ -528,7 +650,7 @@ func (b *blockManager) startSync(peers *list.List) {
 	}
 
 	best := b.chain.BestSnapshot()
-	var bestPeer *serverPeer
+	candidates := make([]*serverPeer, 0, peers.Len())
 	var enext *list.Element
 	for e := peers.Front(); e != nil; e = enext {
 		enext = e.Next()
@@ -544,21 +666,19 @@ func (b *blockManager) startSync(peers *list.List) {
 			peers.Remove(e)
 			continue
 		}
-
-		if bestPeer == nil {
-			bestPeer = sp
-		}
-		if bestPeer.LastBlock() < sp.LastBlock() {
-			bestPeer = sp
-		}
+		candidates = append(candidates, sp)
 	}
 
+	// Prefer the candidates which are expected to serve the blocks the
+	// fastest over the one which merely announced the most blocks.
+	bestPeer := selectSyncPeer(candidates)
+
 	// Start syncing from the best peer if one was selected.
 	if bestPeer != nil {
 		// Clear the requestedBlocks if the sync peer changes, otherwise
 		// we may ignore blocks we need that the last sync peer failed
 		// to send.
-		b.requestedBlocks = make(map[chainhash.Hash]struct{})
+		b.requestedBlocks = newInFlightBlocks()
 
 		locator, err := b.chain.LatestBlockLocator()
 		if err != nil {
@@ -569,6 +689,8 @@ func (b *blockManager) startSync(peers *list.List) {
 
 		bmgrLog.Infof("Syncing to block height %d from peer %v",
 			bestPeer.LastBlock(), bestPeer.Addr())
+		bmgrLog.Debugf("Expected block download time from peer %v: %v",
+			bestPeer.Addr(), bestPeer.expectedBlockTime())
 
 		// When the current height is less than a known checkpoint we
 		// can use block headers to learn about which blocks comprise
@@ -587,20 +709,36 @@ func (b *blockManager) startSync(peers *list.List) {
 		// and fully validate them.  Finally, regression test mode does
 		// not support the headers-first approach so do normal block
 		// downloads when in regression test mode.
+		//
+		// Past the final checkpoint, the headers are downloaded up to
+		// the block assumed to be valid, if any, in the same way.  The
+		// blocks in between are fully validated except for their
+		// scripts.
 		if b.nextCheckpoint != nil &&
 			best.Height < b.nextCheckpoint.Height &&
-			!cfg.DisableCheckpoints {
+			!cfg.DisableCheckpoints ||
+			b.nextCheckpoint == nil && b.assumeValid != nil {
 
-			err := bestPeer.PushGetHeadersMsg(locator, b.nextCheckpoint.Hash)
+			b.resetHeaderState(best.Hash, best.Height)
+			err := bestPeer.PushGetHeadersMsg(locator, b.headersTarget())
 			if err != nil {
 				bmgrLog.Errorf("Failed to push getheadermsg for the "+
 					"latest blocks: %v", err)
 				return
 			}
 			b.headersFirstMode = true
-			bmgrLog.Infof("Downloading headers for blocks %d to "+
-				"%d from peer %s", best.Height+1,
-				b.nextCheckpoint.Height, bestPeer.Addr())
+			b.syncProgress.setState(syncStateHeaders)
+			if b.nextCheckpoint != nil {
+				bmgrLog.Infof("Downloading headers for blocks %d "+
+					"to %d from peer %s", best.Height+1,
+					b.nextCheckpoint.Height, bestPeer.Addr())
+			} else {
+				bmgrLog.Infof("Downloading headers for blocks %d "+
+					"to %d up to block %v assumed to be valid "+
+					"from peer %s", best.Height+1,
+					b.assumeValid.Height, b.assumeValid.Hash,
+					bestPeer.Addr())
+			}
 		} else {
 			err := bestPeer.PushGetBlocksMsg(locator, &zeroHash)
 			if err != nil {
@@ -608,8 +746,10 @@ func (b *blockManager) startSync(peers *list.List) {
 					"latest blocks: %v", err)
 				return
 			}
+			b.syncProgress.setState(syncStateBlocks)
 		}
 		b.syncPeer = bestPeer
+		b.syncPeerProgress = time.Now()
 	} else {
 		bmgrLog.Warnf("No sync peer candidates available")
 	}
@@ -690,25 +830,66 @@ func (b *blockManager) handleDonePeerMsg(peers *list.List, sp *serverPeer) {
 		delete(b.requestedTxns, k)
 	}
 
-	// Remove requested blocks from the global map so that they will be
+	// Remove the blocks in flight from the peer so that they will be
 	// fetched from elsewhere next time we get an inv.
 	// TODO(oga) we could possibly here check which peers have these blocks
 	// and request them now to speed things up a little.
-	for k := range sp.requestedBlocks {
-		delete(b.requestedBlocks, k)
-	}
+	b.requestedBlocks.removePeer(sp)
 
 	// Attempt to find a new peer to sync from if the quitting peer is the
-	// sync peer.  Also, reset the headers-first state if in headers-first
-	// mode so
+	// sync peer.
 	if b.syncPeer != nil && b.syncPeer == sp {
-		b.syncPeer = nil
-		if b.headersFirstMode {
-			best := b.chain.BestSnapshot()
-			b.resetHeaderState(best.Hash, best.Height)
+		b.replaceSyncPeer(peers)
+	}
+}
+
+// replaceSyncPeer clears the current sync peer, along with the headers-first
+// state if in headers-first mode, and starts syncing from the best of the
+// remaining candidate peers, if any.
+func (b *blockManager) replaceSyncPeer(peers *list.List) {
+	b.syncPeer = nil
+	b.syncProgress.setState(syncStateIdle)
+	if b.headersFirstMode {
+		best := b.chain.BestSnapshot()
+		b.resetHeaderState(best.Hash, best.Height)
+		b.syncProgress.resetHeaders()
+	}
+	b.startSync(peers)
+}
+
+// handleSyncStallCheck demotes the sync peer when it did not deliver any of the
+// requested blocks or headers within the configured sync stall timeout while
+// syncing.  The peer is no longer considered a sync candidate, the blocks
+// requested from it are made available to request from other peers and
+// syncing restarts from the best of the remaining candidates.  It is invoked
+// from the syncHandler goroutine.
+func (b *blockManager) handleSyncStallCheck(peers *list.List) {
+	sp := b.syncPeer
+	if sp == nil || cfg.SyncStallTimeout == 0 {
+		return
+	}
+
+	// The sync peer is not stalling when it has no blocks to deliver, so
+	// the timeout only starts once it has.
+	if b.chain.BestSnapshot().Height >= sp.LastBlock() {
+		b.syncPeerProgress = time.Now()
+		return
+	}
+	if time.Since(b.syncPeerProgress) < cfg.SyncStallTimeout {
+		return
+	}
+
+	bmgrLog.Warnf("Sync peer %s did not deliver the requested blocks or "+
+		"headers within %v -- switching to another sync peer", sp,
+		cfg.SyncStallTimeout)
+	for e := peers.Front(); e != nil; e = e.Next() {
+		if e.Value == sp {
+			peers.Remove(e)
+			break
 		}
-		b.startSync(peers)
 	}
+	b.requestedBlocks.removePeer(sp)
+	b.replaceSyncPeer(peers)
 }
 
 // handleTxMsg handles transaction messages from all peers.
@@ -718,10 +899,21 @@ func (b *blockManager) handleTxMsg(tmsg *txMsg) {
 	// whether or not they want to request the transaction via a getdata
 	// message.  Unfortunately, the reference implementation permits
 	// unrequested data, so it has allowed wallets that don't follow the
-	// spec to proliferate.  While this is not ideal, there is no check here
-	// to disconnect peers for sending unsolicited transactions to provide
-	// interoperability.
+	// spec to proliferate.  While this is not ideal, unsolicited
+	// transactions are still processed once the chain is current to provide
+	// interoperability.  While syncing, they can't be validated against the
+	// current chain state, so they are ignored and increase the ban score
+	// of the peer, unless it is whitelisted, to prevent peers from wasting
+	// memory and CPU with them.
 	txHash := tmsg.tx.Hash()
+	_, requested := tmsg.peer.requestedTxns[*txHash]
+	if !requested && !tmsg.peer.isWhitelisted && !b.current() {
+		bmgrLog.Debugf("Ignoring unrequested transaction %v from %s "+
+			"while syncing", txHash, tmsg.peer)
+		tmsg.peer.addBanScore(0, unrequestedTxBanScore,
+			"unrequested transaction while syncing")
+		return
+	}
 
 	// Ignore transactions that we have already rejected.  Do not
 	// send a reject message here because if the transaction was already
@@ -771,9 +963,28 @@ func (b *blockManager) handleTxMsg(tmsg *txMsg) {
 		return
 	}
 
+	if len(acceptedTxs) > 0 {
+		tmsg.peer.setLastTxTime(time.Now())
+	}
 	b.server.AnnounceNewTransactions(acceptedTxs)
 }
 
+// isCloseToTip returns whether the height of the passed block is at most
+// maxUnrequestedBlockDepth blocks below the best block and at most one block
+// above it.
+func (b *blockManager) isCloseToTip(block *hcutil.Block) bool {
+	return isCloseToHeight(int64(block.MsgBlock().Header.Height),
+		b.chain.BestSnapshot().Height)
+}
+
+// isCloseToHeight returns whether the passed height is at most
+// maxUnrequestedBlockDepth blocks below the passed best height and at most one
+// block above it.
+func isCloseToHeight(height, bestHeight int64) bool {
+	return height >= bestHeight-maxUnrequestedBlockDepth &&
+		height <= bestHeight+1
+}
+
 // current returns true if we believe we are synced with our peers, false if we
 // still have blocks to check
 func (b *blockManager) current() bool {
@@ -979,7 +1190,16 @@ func (b *blockManager) checkBlockForHiddenVotes(block *hcutil.Block) {
 func (b *blockManager) handleBlockMsg(bmsg *blockMsg) {
 	// If we didn't ask for this block then the peer is misbehaving.
 	blockHash := bmsg.block.Hash()
-	if _, exists := bmsg.peer.requestedBlocks[*blockHash]; !exists {
+	requested, exists := bmsg.peer.requestedBlocks[*blockHash]
+	if exists {
+		// Track how quickly the peer serves the blocks requested from
+		// it to prefer the fastest peers when requesting blocks.
+		bmsg.peer.blockStats.record(requested,
+			bmsg.block.MsgBlock().SerializeSize(), time.Now())
+		if bmsg.peer == b.syncPeer {
+			b.syncPeerProgress = time.Now()
+		}
+	} else {
 		// Check to see if we ever requested this block, since it may
 		// have been accidentally sent in duplicate. If it was,
 		// increment the counter in the ever requested map and make
@@ -994,10 +1214,18 @@ func (b *blockManager) handleBlockMsg(bmsg *blockMsg) {
 				return
 			}
 			b.requestedEverBlocks[*blockHash]++
-		} else {
-			bmgrLog.Warnf("Got unrequested block %v from %s -- "+
-				"disconnecting", blockHash, bmsg.peer.Addr())
-			bmsg.peer.Disconnect()
+		} else if !bmsg.peer.isWhitelisted &&
+			!b.isCloseToTip(bmsg.block) {
+
+			// Blocks which were never requested are only
+			// processed from whitelisted peers or when they are
+			// close to the tip, such as newly mined blocks pushed
+			// by peers.
+			bmgrLog.Debugf("Ignoring unrequested block %v (height "+
+				"%d) from %s", blockHash,
+				bmsg.block.MsgBlock().Header.Height, bmsg.peer)
+			bmsg.peer.addBanScore(0, unrequestedBlockBanScore,
+				"unrequested block")
 			return
 		}
 	}
@@ -1016,8 +1244,12 @@ func (b *blockManager) handleBlockMsg(bmsg *blockMsg) {
 		if firstNodeEl != nil {
 			firstNode := firstNodeEl.Value.(*headerNode)
 			if blockHash.IsEqual(firstNode.hash) {
-				behaviorFlags |= blockchain.BFFastAdd
-				if firstNode.hash.IsEqual(b.nextCheckpoint.Hash) {
+				if b.nextCheckpoint != nil {
+					behaviorFlags |= blockchain.BFFastAdd
+				} else {
+					behaviorFlags |= blockchain.BFAssumeValid
+				}
+				if firstNode.hash.IsEqual(b.headersTarget()) {
 					isCheckpointBlock = true
 				} else {
 					b.headerList.Remove(firstNodeEl)
@@ -1030,7 +1262,7 @@ func (b *blockManager) handleBlockMsg(bmsg *blockMsg) {
 	// so we shouldn't have any more instances of trying to fetch it, or we
 	// will fail the insert and thus we'll retry next time we get an inv.
 	delete(bmsg.peer.requestedBlocks, *blockHash)
-	delete(b.requestedBlocks, *blockHash)
+	b.requestedBlocks.remove(blockHash)
 
 	// Process the block to include validation, best chain selection, orphan
 	// handling, etc.
@@ -1100,7 +1332,11 @@ func (b *blockManager) handleBlockMsg(bmsg *blockMsg) {
 	} else {
 		// When the block is not an orphan, log information about it and
 		// update the chain state.
+		bmsg.peer.setLastBlockTime(time.Now())
 		b.progressLogger.logBlockHeight(bmsg.block)
+		header := &bmsg.block.MsgBlock().Header
+		b.syncProgress.blockReceived(int64(header.Height),
+			header.Timestamp, time.Now())
 		r := b.server.rpcServer
 
 		// Determine if this block is recent enough that we need to calculate
@@ -1250,30 +1486,53 @@ func (b *blockManager) handleBlockMsg(bmsg *blockMsg) {
 	// This is headers-first mode and the block is a checkpoint.  When
 	// there is a next checkpoint, get the next round of headers by asking
 	// for headers starting from the block after this one up to the next
-	// checkpoint.
-	prevHeight := b.nextCheckpoint.Height
-	prevHash := b.nextCheckpoint.Hash
-	b.nextCheckpoint = b.findNextHeaderCheckpoint(prevHeight)
+	// checkpoint.  Once past the final checkpoint, ask for the headers up
+	// to the block assumed to be valid instead when it is still ahead.
 	if b.nextCheckpoint != nil {
-		locator := blockchain.BlockLocator([]*chainhash.Hash{prevHash})
-		err := bmsg.peer.PushGetHeadersMsg(locator, b.nextCheckpoint.Hash)
-		if err != nil {
-			bmgrLog.Warnf("Failed to send getheaders message to "+
-				"peer %s: %v", bmsg.peer.Addr(), err)
+		prevHeight := b.nextCheckpoint.Height
+		prevHash := b.nextCheckpoint.Hash
+		b.nextCheckpoint = b.findNextHeaderCheckpoint(prevHeight)
+		if b.nextCheckpoint == nil && b.assumeValid != nil &&
+			!b.assumeValidAhead() {
+
+			b.assumeValid = nil
+		}
+		if b.nextCheckpoint != nil || b.assumeValid != nil {
+			locator := blockchain.BlockLocator([]*chainhash.Hash{prevHash})
+			err := bmsg.peer.PushGetHeadersMsg(locator, b.headersTarget())
+			if err != nil {
+				bmgrLog.Warnf("Failed to send getheaders message to "+
+					"peer %s: %v", bmsg.peer.Addr(), err)
+				return
+			}
+			b.syncProgress.setState(syncStateHeaders)
+			if b.nextCheckpoint != nil {
+				bmgrLog.Infof("Downloading headers for blocks %d "+
+					"to %d from peer %s", prevHeight+1,
+					b.nextCheckpoint.Height, b.syncPeer.Addr())
+			} else {
+				bmgrLog.Infof("Downloading headers for blocks %d "+
+					"to %d up to block %v assumed to be valid "+
+					"from peer %s", prevHeight+1,
+					b.assumeValid.Height, b.assumeValid.Hash,
+					b.syncPeer.Addr())
+			}
 			return
 		}
-		bmgrLog.Infof("Downloading headers for blocks %d to %d from "+
-			"peer %s", prevHeight+1, b.nextCheckpoint.Height,
-			b.syncPeer.Addr())
-		return
+		bmgrLog.Infof("Reached the final checkpoint -- switching to " +
+			"normal mode")
+	} else {
+		bmgrLog.Infof("Reached block %v assumed to be valid -- "+
+			"switching to normal mode", blockHash)
+		b.assumeValid = nil
 	}
 
-	// This is headers-first mode, the block is a checkpoint, and there are
-	// no more checkpoints, so switch to normal mode by requesting blocks
-	// from the block after this one up to the end of the chain (zero hash).
+	// This is headers-first mode, the block is the final checkpoint or the
+	// block assumed to be valid, and there are no more headers to download,
+	// so switch to normal mode by requesting blocks from the block after
+	// this one up to the end of the chain (zero hash).
 	b.headersFirstMode = false
 	b.headerList.Init()
-	bmgrLog.Infof("Reached the final checkpoint -- switching to normal mode")
 	locator := blockchain.BlockLocator([]*chainhash.Hash{blockHash})
 	err = bmsg.peer.PushGetBlocksMsg(locator, &zeroHash)
 	if err != nil {
@@ -1313,9 +1572,9 @@ func (b *blockManager) fetchHeaderBlocks() {
 			continue
 		}
 		if !haveInv {
-			b.requestedBlocks[*node.hash] = struct{}{}
+			b.requestedBlocks.add(node.hash, b.syncPeer, time.Now())
 			b.requestedEverBlocks[*node.hash] = 0
-			b.syncPeer.requestedBlocks[*node.hash] = struct{}{}
+			b.syncPeer.requestedBlocks[*node.hash] = time.Now()
 			err = gdmsg.AddInvVect(iv)
 			if err != nil {
 				bmgrLog.Warnf("Failed to add invvect while fetching "+
@@ -1344,9 +1603,17 @@ func (b *blockManager) handleHeadersMsg(hmsg *headersMsg) {
 		hmsg.peer.Disconnect()
 		return
 	}
+	if hmsg.peer == b.syncPeer {
+		b.syncPeerProgress = time.Now()
+	}
 
-	// Nothing to do for an empty headers message.
+	// Nothing to do for an empty headers message, unless the headers are
+	// being downloaded up to the block assumed to be valid, in which case
+	// the sync peer has no more headers and does not know the block.
 	if numHeaders == 0 {
+		if b.nextCheckpoint == nil {
+			b.abandonAssumeValid(hmsg.peer)
+		}
 		return
 	}
 
@@ -1354,6 +1621,8 @@ func (b *blockManager) handleHeadersMsg(hmsg *headersMsg) {
 	// previous and that checkpoints match.
 	receivedCheckpoint := false
 	var finalHash *chainhash.Hash
+	var finalHeight int64
+	var finalTime time.Time
 	for _, blockHeader := range msg.Headers {
 		blockHash := blockHeader.BlockHash()
 		finalHash = &blockHash
@@ -1373,6 +1642,8 @@ func (b *blockManager) handleHeadersMsg(hmsg *headersMsg) {
 		prevNode := prevNodeEl.Value.(*headerNode)
 		if prevNode.hash.IsEqual(&blockHeader.PrevBlock) {
 			node.height = prevNode.height + 1
+			finalHeight = node.height
+			finalTime = blockHeader.Timestamp
 			e := b.headerList.PushBack(&node)
 			if b.startHeader == nil {
 				b.startHeader = e
@@ -1385,6 +1656,33 @@ func (b *blockManager) handleHeadersMsg(hmsg *headersMsg) {
 			return
 		}
 
+		// Past the final checkpoint, the headers are downloaded up to
+		// the height of the block assumed to be valid.  A sync peer
+		// with a different block at that height is disconnected, and
+		// since the block may be misconfigured, the remaining blocks are
+		// fully validated.
+		if b.nextCheckpoint == nil {
+			if node.height < b.assumeValid.Height {
+				continue
+			}
+			if !node.hash.IsEqual(b.assumeValid.Hash) {
+				bmgrLog.Warnf("Block header at height %d/hash "+
+					"%s from peer %s does NOT match block "+
+					"%s assumed to be valid -- disconnecting "+
+					"and switching to normal mode",
+					node.height, node.hash,
+					hmsg.peer.Addr(), b.assumeValid.Hash)
+				b.assumeValid = nil
+				hmsg.peer.Disconnect()
+				return
+			}
+			receivedCheckpoint = true
+			bmgrLog.Infof("Downloaded headers up to block %s "+
+				"assumed to be valid at height %d", node.hash,
+				node.height)
+			break
+		}
+
 		// Verify the header at the next checkpoint height matches.
 		if node.height == b.nextCheckpoint.Height {
 			if node.hash.IsEqual(b.nextCheckpoint.Hash) {
@@ -1405,6 +1703,7 @@ func (b *blockManager) handleHeadersMsg(hmsg *headersMsg) {
 			break
 		}
 	}
+	b.syncProgress.headersReceived(finalHeight, finalTime)
 
 	// When this header is a checkpoint, switch to fetching the blocks for
 	// all of the headers since the last checkpoint.
@@ -1417,15 +1716,24 @@ func (b *blockManager) handleHeadersMsg(hmsg *headersMsg) {
 		bmgrLog.Infof("Received %v block headers: Fetching blocks",
 			b.headerList.Len())
 		b.progressLogger.SetLastLogTime(time.Now())
+		b.syncProgress.setState(syncStateBlocks)
 		b.fetchHeaderBlocks()
 		return
 	}
 
+	// The sync peer sends fewer than the maximum number of headers once it
+	// has no more, so it does not know the block assumed to be valid when
+	// the headers did not reach it.
+	if b.nextCheckpoint == nil && numHeaders < wire.MaxBlockHeadersPerMsg {
+		b.abandonAssumeValid(hmsg.peer)
+		return
+	}
+
 	// This header is not a checkpoint, so request the next batch of
 	// headers starting from the latest known header and ending with the
 	// next checkpoint.
 	locator := blockchain.BlockLocator([]*chainhash.Hash{finalHash})
-	err := hmsg.peer.PushGetHeadersMsg(locator, b.nextCheckpoint.Hash)
+	err := hmsg.peer.PushGetHeadersMsg(locator, b.headersTarget())
 	if err != nil {
 		bmgrLog.Warnf("Failed to send getheaders message to "+
 			"peer %s: %v", hmsg.peer.Addr(), err)
@@ -1489,6 +1797,12 @@ func (b *blockManager) handleInvMsg(imsg *invMsg) {
 		imsg.peer.UpdateLastAnnouncedBlock(&invVects[lastBlock].Hash)
 	}
 
+	// The sync peer announcing blocks in response to a getblocks request
+	// is progress.
+	if lastBlock != -1 && imsg.peer == b.syncPeer {
+		b.syncPeerProgress = time.Now()
+	}
+
 	// Ignore invs from peers that aren't the sync if we are not current.
 	// Helps prevent fetching a mass of orphans.
 	if imsg.peer != b.syncPeer && !b.current() {
@@ -1597,6 +1911,7 @@ func (b *blockManager) handleInvMsg(imsg *invMsg) {
 	// Request as much as possible at once.  Anything that won't fit into
 	// the request will be requested on the next inv message.
 	numRequested := 0
+	now := time.Now()
 	gdmsg := wire.NewMsgGetData()
 	requestQueue := imsg.peer.requestQueue
 	for len(requestQueue) != 0 {
@@ -1607,15 +1922,24 @@ func (b *blockManager) handleInvMsg(imsg *invMsg) {
 		switch iv.Type {
 		case wire.InvTypeBlock:
 			// Request the block if there is not already a pending
-			// request.
-			if _, exists := b.requestedBlocks[iv.Hash]; !exists {
-				b.requestedBlocks[iv.Hash] = struct{}{}
-				b.requestedEverBlocks[iv.Hash] = 0
-				b.limitMap(b.requestedBlocks, maxRequestedBlocks)
-				imsg.peer.requestedBlocks[iv.Hash] = struct{}{}
-				gdmsg.AddInvVect(iv)
-				numRequested++
+			// request from any peer.  Limit the number of blocks
+			// relayed by peers other than the sync peer which are
+			// requested at once, so the blocks announced by
+			// multiple peers are requested from the ones which
+			// deliver them.
+			if b.requestedBlocks.isInFlight(&iv.Hash, now) {
+				continue
 			}
+			if imsg.peer != b.syncPeer &&
+				b.requestedBlocks.peerCount(imsg.peer) >=
+					maxRelayBlocksInFlight {
+				continue
+			}
+			b.requestedBlocks.add(&iv.Hash, imsg.peer, now)
+			b.requestedEverBlocks[iv.Hash] = 0
+			imsg.peer.requestedBlocks[iv.Hash] = now
+			gdmsg.AddInvVect(iv)
+			numRequested++
 
 		case wire.InvTypeTx:
 			// Request the transaction if there is not already a
@@ -1640,6 +1964,28 @@ func (b *blockManager) handleInvMsg(imsg *invMsg) {
 	}
 }
 
+// handleNotFoundMsg handles notfound messages from all peers.  The requests of
+// the blocks and transactions the peer does not have are removed, so they are
+// requested from other peers which announce them.
+func (b *blockManager) handleNotFoundMsg(nfmsg *notFoundMsg) {
+	sp := nfmsg.peer
+	for _, iv := range nfmsg.notFound.InvList {
+		switch iv.Type {
+		case wire.InvTypeBlock:
+			if _, exists := sp.requestedBlocks[iv.Hash]; exists {
+				delete(sp.requestedBlocks, iv.Hash)
+				b.requestedBlocks.remove(&iv.Hash)
+			}
+
+		case wire.InvTypeTx:
+			if _, exists := sp.requestedTxns[iv.Hash]; exists {
+				delete(sp.requestedTxns, iv.Hash)
+				delete(b.requestedTxns, iv.Hash)
+			}
+		}
+	}
+}
+
 // limitMap is a helper function for maps that require a maximum limit by
 // evicting a random transaction if adding a new value would cause it to
 // overflow the maximum allowed.
@@ -1666,9 +2012,19 @@ func (b *blockManager) limitMap(m map[chainhash.Hash]struct{}, limit int) {
 // the fetching should proceed.
 func (b *blockManager) blockHandler() {
 	candidatePeers := list.New()
+	stallTicker := time.NewTicker(syncStallCheckInterval)
+	defer stallTicker.Stop()
+	pruneTicker := time.NewTicker(blockRequestTimeout)
+	defer pruneTicker.Stop()
 out:
 	for {
 		select {
+		case <-stallTicker.C:
+			b.handleSyncStallCheck(candidatePeers)
+
+		case <-pruneTicker.C:
+			b.requestedBlocks.pruneExpired(time.Now())
+
 		case m := <-b.msgChan:
 			switch msg := m.(type) {
 			case *newPeerMsg:
@@ -1688,6 +2044,9 @@ out:
 			case *headersMsg:
 				b.handleHeadersMsg(msg)
 
+			case *notFoundMsg:
+				b.handleNotFoundMsg(msg)
+
 			case *donePeerMsg:
 				b.handleDonePeerMsg(candidatePeers, msg.peer)
 
@@ -1723,61 +2082,21 @@ out:
 				// Reorganizing has succeeded, so we need to
 				// update the chain state.
 				if err == nil {
-					// Query the db for the latest best block since
-					// the block that was processed could be on a
-					// side chain or have caused a reorg.
-					best := b.chain.BestSnapshot()
-
-					// Fetch the required lottery data.
-					winningTickets, poolSize, finalState, err :=
-						b.chain.LotteryDataForBlock(best.Hash)
-
-					// Update registered websocket clients on the
-					// current stake difficulty.
-					nextStakeDiff, errSDiff :=
-						b.chain.CalcNextRequiredStakeDifficulty()
-					if err != nil {
-						bmgrLog.Warnf("Failed to get next stake difficulty "+
-							"calculation: %v", err)
-					}
-					r := b.server.rpcServer
-					if r != nil && errSDiff == nil {
-						r.ntfnMgr.NotifyStakeDifficulty(
-							&StakeDifficultyNtfnData{
-								*best.Hash,
-								best.Height,
-								nextStakeDiff,
-							})
-						b.server.txMemPool.PruneStakeTx(nextStakeDiff,
-							best.Height)
-						b.server.txMemPool.PruneExpiredTx(best.Height)
-					}
-
-					missedTickets, err := b.chain.MissedTickets()
-					if err != nil {
-						bmgrLog.Warnf("Failed to get missed tickets"+
-							": %v", err)
-					}
-
-					// The blockchain should be updated, so fetch the
-					// latest snapshot.
-					best = b.chain.BestSnapshot()
-					curPrevHash := b.chain.BestPrevHash()
-
-					b.updateChainState(best.Hash,
-						best.Height,
-						finalState,
-						uint32(poolSize),
-						nextStakeDiff,
-						winningTickets,
-						missedTickets,
-						curPrevHash)
+					b.refreshChainState()
 				}
 
 				msg.reply <- forceReorganizationResponse{
 					err: err,
 				}
 
+			case reconsiderBlockMsg:
+				best := b.chain.BestSnapshot()
+				err := b.chain.ReconsiderBlock(&msg.hash)
+				if err == nil && *b.chain.BestSnapshot().Hash != *best.Hash {
+					b.refreshChainState()
+				}
+				msg.reply <- err
+
 			case getGenerationMsg:
 				g, err := b.chain.GetGeneration(msg.hash)
 				msg.reply <- getGenerationResponse{
@@ -1800,8 +2119,15 @@ out:
 				}
 
 			case processBlockMsg:
+				msg.queueSpan.End()
+				span := msg.span.StartChild("blockchain.ProcessBlock")
+				span.SetAttribute("block.hash", msg.block.Hash().String())
 				onMainChain, isOrphan, err := b.chain.ProcessBlock(
 					msg.block, msg.flags)
+				span.SetAttribute("block.orphan", isOrphan)
+				span.SetAttribute("block.mainchain", onMainChain)
+				span.SetError(err)
+				span.End()
 				if err != nil {
 					msg.reply <- processBlockResponse{
 						onMainChain: onMainChain,
@@ -1928,13 +2254,27 @@ out:
 				}
 
 			case processTransactionMsg:
+				msg.queueSpan.End()
+				span := msg.span.StartChild("mempool.ProcessTransaction")
+				span.SetAttribute("tx.hash", msg.tx.Hash().String())
 				acceptedTxs, err := b.server.txMemPool.ProcessTransaction(msg.tx,
 					msg.allowOrphans, msg.rateLimit, msg.allowHighFees)
+				span.SetAttribute("tx.accepted", len(acceptedTxs))
+				span.SetError(err)
+				span.End()
 				msg.reply <- processTransactionResponse{
 					acceptedTxs: acceptedTxs,
 					err:         err,
 				}
 
+			case processPackageMsg:
+				acceptedTxs, txErrs := b.server.txMemPool.ProcessPackage(
+					msg.txns, msg.allowHighFees)
+				msg.reply <- processPackageResponse{
+					acceptedTxs: acceptedTxs,
+					txErrs:      txErrs,
+				}
+
 			case isCurrentMsg:
 				msg.reply <- b.current()
 
@@ -2130,6 +2470,12 @@ func (b *blockManager) handleNotifyMsg(notification *blockchain.Notification) {
 			r.ntfnMgr.NotifyBlockConnected(block)
 		}
 
+		// Publish the block to its subscribers.
+		b.server.publishBlock(block)
+
+		b.server.chainStats.connect(newBlockStats(block,
+			b.server.chainParams))
+
 	// Stake tickets are spent or missed from the most recently connected block.
 	case blockchain.NTSpentAndMissedTickets:
 		tnd, ok := notification.Data.(*blockchain.TicketNotificationsData)
@@ -2156,6 +2502,19 @@ func (b *blockManager) handleNotifyMsg(notification *blockchain.Notification) {
 			r.ntfnMgr.NotifyNewTickets(tnd)
 		}
 
+	// Stake tickets became revocable in the most recently connected block.
+	case blockchain.NTRevocableTickets:
+		rtnd, ok := notification.Data.(*blockchain.RevocableTicketsNtfnsData)
+		if !ok {
+			bmgrLog.Warnf("Revocable tickets notification is not " +
+				"RevocableTicketsNtfnsData")
+			break
+		}
+
+		if r := b.server.rpcServer; r != nil {
+			r.ntfnMgr.NotifyRevocableTickets(rtnd)
+		}
+
 	// A block has been disconnected from the main block chain.
 	case blockchain.NTBlockDisconnected:
 		blockSlice, ok := notification.Data.([]*hcutil.Block)
@@ -2209,6 +2568,8 @@ func (b *blockManager) handleNotifyMsg(notification *blockchain.Notification) {
 			}
 		}
 
+		b.server.chainStats.disconnect(block.Height())
+
 		// Notify registered websocket clients.
 		if r := b.server.rpcServer; r != nil {
 			r.ntfnMgr.NotifyBlockDisconnected(block)
@@ -2276,6 +2637,18 @@ func (b *blockManager) QueueInv(inv *wire.MsgInv, sp *serverPeer) {
 	b.msgChan <- &invMsg{inv: inv, peer: sp}
 }
 
+// QueueNotFound adds the passed notfound message and peer to the block handling
+// queue.
+func (b *blockManager) QueueNotFound(notFound *wire.MsgNotFound, sp *serverPeer) {
+	// No channel handling here because peers do not need to block on
+	// notfound messages.
+	if atomic.LoadInt32(&b.shutdown) != 0 {
+		return
+	}
+
+	b.msgChan <- &notFoundMsg{notFound: notFound, peer: sp}
+}
+
 // QueueHeaders adds the passed headers message and peer to the block handling
 // queue.
 func (b *blockManager) QueueHeaders(headers *wire.MsgHeaders, sp *serverPeer) {
@@ -2351,7 +2724,7 @@ func (b *blockManager) requestFromPeer(p *serverPeer, blocks, txs []*chainhash.H
 	for _, bh := range blocks {
 		// If we've already requested this block, skip it.
 		_, alreadyReqP := p.requestedBlocks[*bh]
-		_, alreadyReqB := b.requestedBlocks[*bh]
+		alreadyReqB := b.requestedBlocks.isInFlight(bh, time.Now())
 
 		if alreadyReqP || alreadyReqB {
 			continue
@@ -2374,8 +2747,8 @@ func (b *blockManager) requestFromPeer(p *serverPeer, blocks, txs []*chainhash.H
 				bh, err.Error())
 		}
 
-		p.requestedBlocks[*bh] = struct{}{}
-		b.requestedBlocks[*bh] = struct{}{}
+		p.requestedBlocks[*bh] = time.Now()
+		b.requestedBlocks.add(bh, p, time.Now())
 		b.requestedEverBlocks[*bh] = 0
 	}
 
@@ -2464,6 +2837,64 @@ func (b *blockManager) CalcNextRequiredStakeDifficulty() (int64, error) {
 	return response.stakeDifficulty, response.err
 }
 
+// refreshChainState updates the chain state tracked by the block manager, the
+// stake difficulty of registered websocket clients and the stake transactions
+// in the memory pool after the main chain was reorganized outside of the
+// processing of a block.
+//
+// This function MUST only be called from the block manager handler.
+func (b *blockManager) refreshChainState() {
+	// Query the db for the latest best block since
+	// the block that was processed could be on a
+	// side chain or have caused a reorg.
+	best := b.chain.BestSnapshot()
+
+	// Fetch the required lottery data.
+	winningTickets, poolSize, finalState, err :=
+		b.chain.LotteryDataForBlock(best.Hash)
+
+	// Update registered websocket clients on the
+	// current stake difficulty.
+	nextStakeDiff, errSDiff :=
+		b.chain.CalcNextRequiredStakeDifficulty()
+	if err != nil {
+		bmgrLog.Warnf("Failed to get next stake difficulty "+
+			"calculation: %v", err)
+	}
+	r := b.server.rpcServer
+	if r != nil && errSDiff == nil {
+		r.ntfnMgr.NotifyStakeDifficulty(
+			&StakeDifficultyNtfnData{
+				*best.Hash,
+				best.Height,
+				nextStakeDiff,
+			})
+		b.server.txMemPool.PruneStakeTx(nextStakeDiff,
+			best.Height)
+		b.server.txMemPool.PruneExpiredTx(best.Height)
+	}
+
+	missedTickets, err := b.chain.MissedTickets()
+	if err != nil {
+		bmgrLog.Warnf("Failed to get missed tickets"+
+			": %v", err)
+	}
+
+	// The blockchain should be updated, so fetch the
+	// latest snapshot.
+	best = b.chain.BestSnapshot()
+	curPrevHash := b.chain.BestPrevHash()
+
+	b.updateChainState(best.Hash,
+		best.Height,
+		finalState,
+		uint32(poolSize),
+		nextStakeDiff,
+		winningTickets,
+		missedTickets,
+		curPrevHash)
+}
+
 // ForceReorganization returns the hashes of all the children of a parent for the
 // block hash that is passed to the function. It is funneled through the block
 // manager since blockchain is not safe for concurrent access.
@@ -2477,6 +2908,17 @@ func (b *blockManager) ForceReorganization(formerBest, newBest chainhash.Hash) e
 	return response.err
 }
 
+// ReconsiderBlock approves a reorganization to the side chain containing the
+// block with the passed hash regardless of the maximum automatic reorganization
+// depth and reorganizes the chain to it when it has the most work.  It is
+// funneled through the block manager since blockchain is not safe for
+// concurrent access.
+func (b *blockManager) ReconsiderBlock(hash *chainhash.Hash) error {
+	reply := make(chan error)
+	b.msgChan <- reconsiderBlockMsg{hash: *hash, reply: reply}
+	return <-reply
+}
+
 // GetGeneration returns the hashes of all the children of a parent for the
 // block hash that is passed to the function. It is funneled through the block
 // manager since blockchain is not safe for concurrent access.
@@ -2509,9 +2951,18 @@ func (b *blockManager) GetTopBlockFromChain() (*hcutil.Block, error) {
 // ProcessBlock makes use of ProcessBlock on an internal instance of a block
 // chain.  It is funneled through the block manager since blockchain is not safe
 // for concurrent access.
-func (b *blockManager) ProcessBlock(block *hcutil.Block, flags blockchain.BehaviorFlags) (bool, error) {
+//
+// The processing is recorded as part of the trace of the passed span, which
+// may be nil when the caller is not traced.
+func (b *blockManager) ProcessBlock(block *hcutil.Block, flags blockchain.BehaviorFlags, span *tracing.Span) (bool, error) {
 	reply := make(chan processBlockResponse, 1)
-	b.msgChan <- processBlockMsg{block: block, flags: flags, reply: reply}
+	b.msgChan <- processBlockMsg{
+		block:     block,
+		flags:     flags,
+		span:      span,
+		queueSpan: span.StartChild("blockmanager.queue"),
+		reply:     reply,
+	}
 	response := <-reply
 	return response.isOrphan, response.err
 }
@@ -2519,15 +2970,28 @@ func (b *blockManager) ProcessBlock(block *hcutil.Block, flags blockchain.Behavi
 // ProcessTransaction makes use of ProcessTransaction on an internal instance of
 // a block chain.  It is funneled through the block manager since blockchain is
 // not safe for concurrent access.
+//
+// The processing is recorded as part of the trace of the passed span, which
+// may be nil when the caller is not traced.
 func (b *blockManager) ProcessTransaction(tx *hcutil.Tx, allowOrphans bool,
-	rateLimit bool, allowHighFees bool) ([]*hcutil.Tx, error) {
+	rateLimit bool, allowHighFees bool, span *tracing.Span) ([]*hcutil.Tx, error) {
 	reply := make(chan processTransactionResponse, 1)
 	b.msgChan <- processTransactionMsg{tx, allowOrphans, rateLimit,
-		allowHighFees, reply}
+		allowHighFees, span, span.StartChild("blockmanager.queue"), reply}
 	response := <-reply
 	return response.acceptedTxs, response.err
 }
 
+// ProcessPackage makes use of ProcessPackage on the memory pool to process a
+// package of dependent transactions as a unit.  It is funneled through the
+// block manager since blockchain is not safe for concurrent access.
+func (b *blockManager) ProcessPackage(txns []*hcutil.Tx, allowHighFees bool) ([]*hcutil.Tx, []error) {
+	reply := make(chan processPackageResponse, 1)
+	b.msgChan <- processPackageMsg{txns, allowHighFees, reply}
+	response := <-reply
+	return response.acceptedTxs, response.txErrs
+}
+
 // IsCurrent returns whether or not the block manager believes it is synced with
 // the connected peers.
 func (b *blockManager) IsCurrent() bool {
@@ -2592,7 +3056,7 @@ func newBlockManager(s *server, indexManager blockchain.IndexManager) (*blockMan
 		rejectedTxns:        make(map[chainhash.Hash]struct{}),
 		requestedTxns:       make(map[chainhash.Hash]struct{}),
 		requestedEverTxns:   make(map[chainhash.Hash]uint8),
-		requestedBlocks:     make(map[chainhash.Hash]struct{}),
+		requestedBlocks:     newInFlightBlocks(),
 		requestedEverBlocks: make(map[chainhash.Hash]uint8),
 		progressLogger:      newBlockProgressLogger("Processed", bmgrLog),
 		msgChan:             make(chan interface{}, cfg.MaxPeers*3),
@@ -2609,23 +3073,44 @@ func newBlockManager(s *server, indexManager blockchain.IndexManager) (*blockMan
 		TimeSource:    s.timeSource,
 		Notifications: bm.handleNotifyMsg,
 		SigCache:      s.sigCache,
+		ScriptCache:   s.scriptCache,
 		IndexManager:  indexManager,
+		MaxReorgDepth: cfg.MaxReorgDepth,
+		Interrupt:     chainInterrupt,
 	})
 	if err != nil {
 		return nil, err
 	}
 	best := bm.chain.BestSnapshot()
+
+	// Track the progress of the initial block download starting from the
+	// timestamp of the current best block.
+	var bestTime time.Time
+	if block, err := bm.chain.BlockByHash(best.Hash); err == nil {
+		bestTime = block.MsgBlock().Header.Timestamp
+	}
+	bm.syncProgress = newSyncProgress(best.Height, bestTime)
+
 	bm.chain.DisableCheckpoints(cfg.DisableCheckpoints)
 	if !cfg.DisableCheckpoints {
 		// Initialize the next checkpoint based on the current height.
 		bm.nextCheckpoint = bm.findNextHeaderCheckpoint(best.Height)
-		if bm.nextCheckpoint != nil {
-			bm.resetHeaderState(best.Hash, best.Height)
-		}
 	} else {
 		bmgrLog.Info("Checkpoints are disabled")
 	}
 
+	// Download the headers up to the block assumed to be valid once past
+	// the final checkpoint unless the best chain already reached it.
+	if cfg.assumeValid != nil {
+		bm.assumeValid = cfg.assumeValid
+		if !bm.assumeValidAhead() {
+			bm.assumeValid = nil
+		}
+	}
+	if bm.nextCheckpoint != nil || bm.assumeValid != nil {
+		bm.resetHeaderState(best.Hash, best.Height)
+	}
+
 	// Dump the blockchain here if asked for it, and quit.
 	if cfg.DumpBlockchain != "" {
 		err = dumpBlockChain(bm.chain, best.Height)
@@ -2679,6 +3164,13 @@ func blockDbPath(dbType string) string {
 	return dbPath
 }
 
+// blockFilesPath returns the path to the block files of the block database
+// given a database type when they are stored in the blocks directory instead of
+// the database path.
+func blockFilesPath(dbType string) string {
+	return filepath.Join(cfg.BlocksDir, blockDbNamePrefix+"_"+dbType)
+}
+
 // warnMultipleDBs shows a warning if multiple block database types are detected.
 // This is not a situation most users want.  It is handy for development however
 // to support multiple side-by-side databases.
@@ -2734,9 +3226,15 @@ func loadBlockDB() (database.DB, error) {
 
 	// The database name is based on the database type.
 	dbPath := blockDbPath(cfg.DbType)
+	dbArgs := []interface{}{dbPath, activeNetParams.Net}
+	if cfg.BlocksDir != "" {
+		blocksPath := blockFilesPath(cfg.DbType)
+		dbArgs = append(dbArgs, blocksPath)
+		hcdLog.Infof("Using block files from '%s'", blocksPath)
+	}
 
 	hcdLog.Infof("Loading block database from '%s'", dbPath)
-	db, err := database.Open(cfg.DbType, dbPath, activeNetParams.Net)
+	db, err := database.Open(cfg.DbType, dbArgs...)
 	if err != nil {
 		// Return the error if it's not because the database doesn't
 		// exist.
@@ -2751,7 +3249,7 @@ func loadBlockDB() (database.DB, error) {
 		if err != nil {
 			return nil, err
 		}
-		db, err = database.Create(cfg.DbType, dbPath, activeNetParams.Net)
+		db, err = database.Create(cfg.DbType, dbArgs...)
 		if err != nil {
 			return nil, err
 		}
@@ -2761,58 +3259,17 @@ func loadBlockDB() (database.DB, error) {
 	return db, nil
 }
 
-// dumpBlockChain dumps a map of the blockchain blocks as serialized bytes.
+// dumpBlockChain writes the main chain blocks after the genesis block through
+// the passed height to the file set with --dumpblockchain in the bootstrap file
+// format.
 func dumpBlockChain(b *blockchain.BlockChain, height int64) error {
 	bmgrLog.Infof("Writing the blockchain to disk as a flat file, " +
 		"please wait...")
 
-	progressLogger := newBlockProgressLogger("Written", bmgrLog)
-
-	file, err := os.Create(cfg.DumpBlockchain)
+	_, err := exportBlockChain(b, cfg.DumpBlockchain, 1, height, nil)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
-
-	// Store the network ID in an array for later writing.
-	var net [4]byte
-	binary.LittleEndian.PutUint32(net[:], uint32(activeNetParams.Net))
-
-	// Write the blocks sequentially, excluding the genesis block.
-	var sz [4]byte
-	for i := int64(1); i <= height; i++ {
-		bl, err := b.BlockByHeight(i)
-		if err != nil {
-			return err
-		}
-
-		// Serialize the block for writing.
-		blB, err := bl.Bytes()
-		if err != nil {
-			return err
-		}
-
-		// Write the network ID first.
-		_, err = file.Write(net[:])
-		if err != nil {
-			return err
-		}
-
-		// Write the size of the block as a little endian uint32,
-		// then write the block itself serialized.
-		binary.LittleEndian.PutUint32(sz[:], uint32(len(blB)))
-		_, err = file.Write(sz[:])
-		if err != nil {
-			return err
-		}
-
-		_, err = file.Write(blB)
-		if err != nil {
-			return err
-		}
-
-		progressLogger.logBlockHeight(bl)
-	}
 
 	bmgrLog.Infof("Successfully dumped the blockchain (%v blocks) to %v.",
 		height, cfg.DumpBlockchain)
@@ -0,0 +1,239 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package node
+
+import (
+	"container/list"
+	"testing"
+	"time"
+
+	"github.com/HcashOrg/hcd/blockchain"
+	"github.com/HcashOrg/hcd/blockchain/stake"
+	"github.com/HcashOrg/hcd/chaincfg"
+	"github.com/HcashOrg/hcd/chaincfg/chainhash"
+	"github.com/HcashOrg/hcd/database"
+	"github.com/HcashOrg/hcd/hcutil"
+	"github.com/HcashOrg/hcd/peer"
+	"github.com/HcashOrg/hcd/wire"
+	"github.com/btcsuite/btclog"
+)
+
+// newTestChain returns a simnet chain which only contains the genesis block
+// backed by a temporary database which is closed when the test finishes.
+func newTestChain(t *testing.T) *blockchain.BlockChain {
+	t.Helper()
+	db, err := database.Create("ffldb", t.TempDir(), chaincfg.SimNetParams.Net)
+	if err != nil {
+		t.Fatalf("unable to create database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	chain, err := blockchain.New(&blockchain.Config{
+		DB:          db,
+		ChainParams: &chaincfg.SimNetParams,
+		TimeSource:  blockchain.NewMedianTime(),
+	})
+	if err != nil {
+		t.Fatalf("unable to create chain: %v", err)
+	}
+	return chain
+}
+
+// TestSyncStallCheck ensures a sync peer which does not deliver the requested
+// blocks within the sync stall timeout is demoted and the blocks requested from
+// it are requested again from the next sync peer.
+func TestSyncStallCheck(t *testing.T) {
+	oldCfg, oldLog := cfg, bmgrLog
+	defer func() {
+		cfg, bmgrLog = oldCfg, oldLog
+		blockchain.UseLogger(chanLog)
+		stake.UseLogger(stkeLog)
+	}()
+	bmgrLog = btclog.Disabled
+	blockchain.UseLogger(btclog.Disabled)
+	stake.UseLogger(btclog.Disabled)
+	cfg = &Config{SyncStallTimeout: time.Minute}
+
+	chain := newTestChain(t)
+	best := chain.BestSnapshot()
+	b := &blockManager{
+		chain:           chain,
+		requestedBlocks: newInFlightBlocks(),
+		syncProgress:    newSyncProgress(best.Height, time.Now()),
+		headerList:      list.New(),
+	}
+
+	newCandidate := func(lastBlock int64) *serverPeer {
+		sp := newServerPeer(nil, false)
+		sp.Peer = peer.NewInboundPeer(&peer.Config{})
+		sp.UpdateLastBlockHeight(lastBlock)
+		return sp
+	}
+	stalled := newCandidate(best.Height)
+	candidates := list.New()
+	candidates.PushBack(stalled)
+	b.startSync(candidates)
+	if b.syncPeer != stalled {
+		t.Fatal("unexpected sync peer")
+	}
+
+	// A sync peer which has no blocks to deliver is not stalling.
+	b.syncPeerProgress = time.Now().Add(-2 * cfg.SyncStallTimeout)
+	b.handleSyncStallCheck(candidates)
+	if b.syncPeer != stalled {
+		t.Fatal("sync peer without blocks to deliver replaced")
+	}
+
+	// A sync peer which made progress within the timeout is kept.
+	stalled.UpdateLastBlockHeight(100)
+	next := newCandidate(100)
+	candidates.PushBack(next)
+	hash := chainhash.Hash{0x01}
+	stalled.requestedBlocks[hash] = time.Now()
+	b.requestedBlocks.add(&hash, stalled, time.Now())
+	b.handleSyncStallCheck(candidates)
+	if b.syncPeer != stalled {
+		t.Fatal("sync peer replaced before the stall timeout")
+	}
+
+	// A stalled sync peer is demoted in favor of the next candidate and
+	// its requested blocks may be requested again.
+	b.syncPeerProgress = time.Now().Add(-2 * cfg.SyncStallTimeout)
+	b.handleSyncStallCheck(candidates)
+	if b.syncPeer != next {
+		t.Fatal("stalled sync peer not replaced")
+	}
+	if b.requestedBlocks.isInFlight(&hash, time.Now()) {
+		t.Fatal("block requested from stalled sync peer still in flight")
+	}
+	for e := candidates.Front(); e != nil; e = e.Next() {
+		if e.Value == stalled {
+			t.Fatal("stalled sync peer is still a sync candidate")
+		}
+	}
+}
+
+// TestAssumeValidHeadersBound ensures the headers downloaded up to the block
+// assumed to be valid stop at its height, so a sync peer which sends headers
+// that never reach the block is disconnected instead of growing the header
+// list without bound.
+func TestAssumeValidHeadersBound(t *testing.T) {
+	oldCfg, oldLog := cfg, bmgrLog
+	defer func() {
+		cfg, bmgrLog = oldCfg, oldLog
+		blockchain.UseLogger(chanLog)
+		stake.UseLogger(stkeLog)
+	}()
+	bmgrLog = btclog.Disabled
+	blockchain.UseLogger(btclog.Disabled)
+	stake.UseLogger(btclog.Disabled)
+	cfg = &Config{}
+
+	chain := newTestChain(t)
+	best := chain.BestSnapshot()
+	const assumeValidHeight = 3
+	b := &blockManager{
+		chain:           chain,
+		requestedBlocks: newInFlightBlocks(),
+		syncProgress:    newSyncProgress(best.Height, time.Now()),
+		headerList:      list.New(),
+		assumeValid: &chaincfg.Checkpoint{
+			Height: best.Height + assumeValidHeight,
+			Hash:   &chainhash.Hash{0x01},
+		},
+	}
+	b.resetHeaderState(best.Hash, best.Height)
+	b.headersFirstMode = true
+
+	sp := newServerPeer(nil, false)
+	sp.Peer = peer.NewInboundPeer(&peer.Config{})
+	b.syncPeer = sp
+
+	// Send linked headers which never reach the block assumed to be valid.
+	msg := wire.NewMsgHeaders()
+	prevHash := *best.Hash
+	for i := 0; i < 2*assumeValidHeight; i++ {
+		header := wire.BlockHeader{
+			PrevBlock: prevHash,
+			Height:    uint32(best.Height) + uint32(i) + 1,
+		}
+		msg.AddBlockHeader(&header)
+		prevHash = header.BlockHash()
+	}
+	b.handleHeadersMsg(&headersMsg{headers: msg, peer: sp})
+
+	// Only the headers up to the height of the block assumed to be valid
+	// are kept in addition to the best block.
+	if got := b.headerList.Len(); got > assumeValidHeight+1 {
+		t.Fatalf("got %d headers in the header list, want at most %d",
+			got, assumeValidHeight+1)
+	}
+	if b.assumeValid != nil {
+		t.Fatal("block assumed to be valid not abandoned")
+	}
+	disconnected := make(chan struct{})
+	go func() {
+		sp.WaitForDisconnect()
+		close(disconnected)
+	}()
+	select {
+	case <-disconnected:
+	case <-time.After(time.Second):
+		t.Fatal("peer sending headers past the height of the block " +
+			"assumed to be valid was not disconnected")
+	}
+}
+
+// TestIsCloseToTip ensures only blocks at most maxUnrequestedBlockDepth blocks
+// below and one block above the best block are considered close to the tip.
+func TestIsCloseToTip(t *testing.T) {
+	defer func() {
+		blockchain.UseLogger(chanLog)
+		stake.UseLogger(stkeLog)
+	}()
+	blockchain.UseLogger(btclog.Disabled)
+	stake.UseLogger(btclog.Disabled)
+
+	b := &blockManager{chain: newTestChain(t)}
+	best := b.chain.BestSnapshot()
+	tests := []struct {
+		height uint32
+		want   bool
+	}{
+		{uint32(best.Height), true},
+		{uint32(best.Height) + 1, true},
+		{uint32(best.Height) + 2, false},
+		{uint32(best.Height) + maxUnrequestedBlockDepth, false},
+	}
+	for _, test := range tests {
+		block := hcutil.NewBlock(&wire.MsgBlock{
+			Header: wire.BlockHeader{Height: test.height},
+		})
+		if got := b.isCloseToTip(block); got != test.want {
+			t.Errorf("isCloseToTip(height %d): got %v, want %v",
+				test.height, got, test.want)
+		}
+	}
+
+	// The test chain only holds the genesis block, so test the lower bound
+	// against a best height deep enough to have one.
+	const bestHeight = 100
+	heightTests := []struct {
+		height int64
+		want   bool
+	}{
+		{bestHeight - maxUnrequestedBlockDepth - 1, false},
+		{bestHeight - maxUnrequestedBlockDepth, true},
+		{bestHeight, true},
+		{bestHeight + 1, true},
+		{bestHeight + 2, false},
+	}
+	for _, test := range heightTests {
+		got := isCloseToHeight(test.height, bestHeight)
+		if got != test.want {
+			t.Errorf("isCloseToHeight(%d, %d): got %v, want %v",
+				test.height, bestHeight, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package node
+
+import (
+	"time"
+)
+
+const (
+	// blockStatsWeight is the weight of a new sample in the moving
+	// averages of the time a peer takes to serve a block and its
+	// throughput.
+	blockStatsWeight = 0.2
+
+	// routingBlockSize is the block size the expected download time of a
+	// block from a peer is estimated for when choosing the peer to request
+	// blocks from.
+	routingBlockSize = 100 * 1000
+
+	// defaultBlockThroughput is the throughput in bytes per second assumed
+	// for a peer which did not serve any blocks yet.  It is conservative,
+	// so peers which proved to be fast are preferred, while peers which
+	// proved to be slower are not.
+	defaultBlockThroughput = 250 * 1000
+
+	// defaultPeerRTT is the round-trip time assumed for a peer which did
+	// not answer a ping yet.
+	defaultPeerRTT = 500 * time.Millisecond
+
+	// maxSyncPeerLag is the maximum number of blocks the last known block
+	// of a peer may be behind the one of the most advanced sync candidate
+	// for it to be chosen as the sync peer for being faster.
+	maxSyncPeerLag = 6
+)
+
+// peerBlockStats tracks how quickly a peer serves the blocks requested from
+// it.  It is only accessed from the block handler goroutine.
+type peerBlockStats struct {
+	// serveTime and throughput are the moving averages of the time the
+	// peer took to serve a block and the resulting throughput in bytes per
+	// second.
+	serveTime  time.Duration
+	throughput float64
+	samples    int
+
+	// lastReceived is the time the last requested block was received from
+	// the peer.  The blocks of a request are served one after the other,
+	// so the serve time of a block starts when the previous one was
+	// received unless it was requested afterwards.
+	lastReceived time.Time
+}
+
+// record updates the statistics with a block of the passed serialized size
+// requested at the passed time and received now.
+func (s *peerBlockStats) record(requested time.Time, size int, now time.Time) {
+	start := requested
+	if s.lastReceived.After(start) {
+		start = s.lastReceived
+	}
+	s.lastReceived = now
+
+	serveTime := now.Sub(start)
+	if serveTime <= 0 {
+		serveTime = time.Millisecond
+	}
+	throughput := float64(size) / serveTime.Seconds()
+
+	if s.samples == 0 {
+		s.serveTime = serveTime
+		s.throughput = throughput
+	} else {
+		s.serveTime = time.Duration((1-blockStatsWeight)*
+			float64(s.serveTime) + blockStatsWeight*float64(serveTime))
+		s.throughput = (1-blockStatsWeight)*s.throughput +
+			blockStatsWeight*throughput
+	}
+	s.samples++
+}
+
+// expectedBlockTime returns the expected time to download a block of
+// routingBlockSize bytes from a peer with the passed statistics and round-trip
+// time, which is zero when unknown.
+func (s *peerBlockStats) expectedBlockTime(rtt time.Duration) time.Duration {
+	if rtt <= 0 {
+		rtt = defaultPeerRTT
+	}
+	throughput := float64(defaultBlockThroughput)
+	if s.samples > 0 && s.throughput > 0 {
+		throughput = s.throughput
+	}
+	transfer := time.Duration(routingBlockSize / throughput *
+		float64(time.Second))
+	return rtt + transfer
+}
+
+// expectedBlockTime returns the expected time to download a block from the
+// peer based on its round-trip time and the blocks it served so far.  It must
+// only be called from the block handler goroutine.
+func (sp *serverPeer) expectedBlockTime() time.Duration {
+	rtt := time.Duration(sp.LastPingMicros()) * time.Microsecond
+	return sp.blockStats.expectedBlockTime(rtt)
+}
+
+// selectSyncPeer returns the candidate to sync the blockchain from.  It is the
+// fastest candidate according to its expected block download time among the
+// candidates which are at most maxSyncPeerLag blocks behind the most advanced
+// one.  Equally fast candidates are ordered by their last known block.  It
+// returns nil when there are no candidates.
+func selectSyncPeer(candidates []*serverPeer) *serverPeer {
+	var maxLastBlock int64
+	for _, sp := range candidates {
+		if sp.LastBlock() > maxLastBlock {
+			maxLastBlock = sp.LastBlock()
+		}
+	}
+
+	var bestPeer *serverPeer
+	var bestTime time.Duration
+	for _, sp := range candidates {
+		if sp.LastBlock() < maxLastBlock-maxSyncPeerLag {
+			continue
+		}
+		expected := sp.expectedBlockTime()
+		if bestPeer == nil || expected < bestTime ||
+			(expected == bestTime && sp.LastBlock() > bestPeer.LastBlock()) {
+
+			bestPeer = sp
+			bestTime = expected
+		}
+	}
+	return bestPeer
+}
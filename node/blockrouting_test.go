@@ -0,0 +1,82 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/HcashOrg/hcd/peer"
+)
+
+// TestPeerBlockStats ensures the serve time of blocks which were requested
+// together starts when the previous block was received and faster peers are
+// expected to serve blocks faster.
+func TestPeerBlockStats(t *testing.T) {
+	var fast, slow peerBlockStats
+	if fast.expectedBlockTime(0) != slow.expectedBlockTime(0) {
+		t.Fatal("peers without statistics are not expected to be " +
+			"equally fast")
+	}
+
+	// Two blocks requested at once are served in one second each.
+	requested := time.Now()
+	fast.record(requested, routingBlockSize, requested.Add(time.Second))
+	fast.record(requested, routingBlockSize, requested.Add(2*time.Second))
+	if fast.serveTime != time.Second {
+		t.Fatalf("got serve time %v, want %v", fast.serveTime,
+			time.Second)
+	}
+	if fast.throughput != routingBlockSize {
+		t.Fatalf("got throughput %v, want %v", fast.throughput,
+			routingBlockSize)
+	}
+
+	slow.record(requested, routingBlockSize, requested.Add(4*time.Second))
+	rtt := 50 * time.Millisecond
+	if fast.expectedBlockTime(rtt) >= slow.expectedBlockTime(rtt) {
+		t.Fatalf("fast peer expected to take %v, slow peer %v",
+			fast.expectedBlockTime(rtt), slow.expectedBlockTime(rtt))
+	}
+	if got, want := fast.expectedBlockTime(rtt), rtt+time.Second; got != want {
+		t.Fatalf("got expected block time %v, want %v", got, want)
+	}
+}
+
+// TestSelectSyncPeer ensures the fastest sync candidate which is not too far
+// behind the most advanced one is selected.
+func TestSelectSyncPeer(t *testing.T) {
+	newCandidate := func(lastBlock int64, serveTime time.Duration) *serverPeer {
+		sp := &serverPeer{Peer: peer.NewInboundPeer(&peer.Config{})}
+		sp.UpdateLastBlockHeight(lastBlock)
+		if serveTime > 0 {
+			requested := time.Now()
+			sp.blockStats.record(requested, routingBlockSize,
+				requested.Add(serveTime))
+		}
+		return sp
+	}
+
+	if selectSyncPeer(nil) != nil {
+		t.Fatal("selected sync peer without candidates")
+	}
+
+	advanced := newCandidate(1000, 0)
+	slow := newCandidate(998, 2*time.Second)
+	fast := newCandidate(997, 100*time.Millisecond)
+	lagging := newCandidate(900, 10*time.Millisecond)
+	candidates := []*serverPeer{advanced, slow, fast, lagging}
+	if got := selectSyncPeer(candidates); got != fast {
+		t.Fatalf("selected candidate with last block %d, want %d",
+			got.LastBlock(), fast.LastBlock())
+	}
+
+	// Equally fast candidates are ordered by their last known block.
+	candidates = []*serverPeer{newCandidate(999, 0), advanced}
+	if got := selectSyncPeer(candidates); got != advanced {
+		t.Fatalf("selected candidate with last block %d, want %d",
+			got.LastBlock(), advanced.LastBlock())
+	}
+}
@@ -0,0 +1,223 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package node
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/HcashOrg/hcd/blockchain"
+	"github.com/HcashOrg/hcd/chaincfg/chainhash"
+	"github.com/HcashOrg/hcd/hcutil"
+	"github.com/HcashOrg/hcd/wire"
+)
+
+// errExportAborted is returned when an export of the block chain is aborted
+// before all blocks were written.
+var errExportAborted = errors.New("block chain export aborted")
+
+// The bootstrap file format, which is the format used by --dumpblockchain and
+// the addblock utility, is a sequence of blocks which are each written as:
+//
+//   <network (uint32 LE)> <block length (uint32 LE)> <serialized block>
+
+// writeBootstrapBlock writes the passed serialized block to the passed writer
+// in the bootstrap file format of the passed network.
+func writeBootstrapBlock(w io.Writer, net wire.CurrencyNet, block []byte) error {
+	var hdr [8]byte
+	binary.LittleEndian.PutUint32(hdr[0:4], uint32(net))
+	binary.LittleEndian.PutUint32(hdr[4:8], uint32(len(block)))
+	if _, err := w.Write(hdr[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(block)
+	return err
+}
+
+// readBootstrapBlock reads the next serialized block of the passed network
+// from the passed reader in the bootstrap file format.  It returns nil without
+// an error once there are no more blocks.
+func readBootstrapBlock(r io.Reader, net wire.CurrencyNet) ([]byte, error) {
+	var hdr [8]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		if err == io.EOF {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if fileNet := binary.LittleEndian.Uint32(hdr[0:4]); fileNet != uint32(net) {
+		return nil, fmt.Errorf("network mismatch -- got %x, want %x",
+			fileNet, uint32(net))
+	}
+	blockLen := binary.LittleEndian.Uint32(hdr[4:8])
+	if blockLen > wire.MaxBlockPayload {
+		return nil, fmt.Errorf("block payload of %d bytes is larger "+
+			"than the max allowed %d bytes", blockLen,
+			wire.MaxBlockPayload)
+	}
+
+	block := make([]byte, blockLen)
+	if _, err := io.ReadFull(r, block); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+// exportBlockChain writes the main chain blocks from the start height through
+// the end height to a new file at the passed path in the bootstrap file format
+// and returns the number of bytes written.  The file is written to a temporary
+// file first, so the path only exists once the export is complete.  An export
+// fails when the main chain is reorganized below the end height while it is
+// written, and it is aborted when the passed channel is closed.
+func exportBlockChain(chain *blockchain.BlockChain, path string, startHeight, endHeight int64, abort <-chan struct{}) (int64, error) {
+	tmpPath := path + ".tmp"
+	file, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY,
+		0644)
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmpPath)
+	defer file.Close()
+
+	progressLogger := newBlockProgressLogger("Exported", bmgrLog)
+	w := bufio.NewWriter(file)
+	var size int64
+	var prevHash *chainhash.Hash
+	for height := startHeight; height <= endHeight; height++ {
+		select {
+		case <-abort:
+			return 0, errExportAborted
+		default:
+		}
+
+		block, err := chain.BlockByHeight(height)
+		if err != nil {
+			return 0, err
+		}
+		header := &block.MsgBlock().Header
+		if prevHash != nil && header.PrevBlock != *prevHash {
+			return 0, fmt.Errorf("the main chain was reorganized "+
+				"at height %d during the export", height)
+		}
+		prevHash = block.Hash()
+
+		serialized, err := block.Bytes()
+		if err != nil {
+			return 0, err
+		}
+		err = writeBootstrapBlock(w, activeNetParams.Net, serialized)
+		if err != nil {
+			return 0, err
+		}
+		size += int64(len(serialized)) + 8
+
+		progressLogger.logBlockHeight(block)
+	}
+
+	if err := w.Flush(); err != nil {
+		return 0, err
+	}
+	if err := file.Sync(); err != nil {
+		return 0, err
+	}
+	if err := file.Close(); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return 0, err
+	}
+	return size, nil
+}
+
+// importBootstrapFile submits the blocks of the bootstrap file at the passed
+// path to the block manager, which fully validates them, and returns the
+// number of blocks which were imported.  Blocks which are already known are
+// skipped and a block which does not connect to a known block ends the
+// import, since the blocks are expected in chain order.
+func (s *server) importBootstrapFile(path string) (int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	chain := s.blockManager.chain
+	progressLogger := newBlockProgressLogger("Imported", bmgrLog)
+	r := bufio.NewReader(file)
+	var imported int64
+	for {
+		select {
+		case <-s.quit:
+			return imported, nil
+		default:
+		}
+
+		serialized, err := readBootstrapBlock(r, activeNetParams.Net)
+		if err != nil {
+			return imported, err
+		}
+		if serialized == nil {
+			return imported, nil
+		}
+		block, err := hcutil.NewBlockFromBytes(serialized)
+		if err != nil {
+			return imported, err
+		}
+
+		exists, err := chain.HaveBlock(block.Hash())
+		if err != nil {
+			return imported, err
+		}
+		if exists {
+			continue
+		}
+		prevHash := &block.MsgBlock().Header.PrevBlock
+		exists, err = chain.HaveBlock(prevHash)
+		if err != nil {
+			return imported, err
+		}
+		if !exists {
+			return imported, fmt.Errorf("block %v does not connect "+
+				"to a known block", block.Hash())
+		}
+
+		_, err = s.blockManager.ProcessBlock(block, blockchain.BFNone, nil)
+		if err != nil {
+			return imported, fmt.Errorf("block %v is invalid: %v",
+				block.Hash(), err)
+		}
+		imported++
+		progressLogger.logBlockHeight(block)
+	}
+}
+
+// importBootstrapHandler imports the blocks of the passed bootstrap files in
+// order while the server keeps running.  It must be run as a goroutine.
+//
+// It is not waited for on shutdown since the block manager does not reply to
+// a block which is submitted after it stopped.  Instead, it stops between
+// blocks once the server is shutting down.
+func (s *server) importBootstrapHandler(paths []string) {
+	for _, path := range paths {
+		bmgrLog.Infof("Importing blocks from %s", path)
+		imported, err := s.importBootstrapFile(path)
+		if err != nil {
+			bmgrLog.Errorf("Unable to import the blocks from %s "+
+				"after %d blocks: %v", path, imported, err)
+			return
+		}
+
+		select {
+		case <-s.quit:
+			return
+		default:
+		}
+		bmgrLog.Infof("Imported %d blocks from %s", imported, path)
+	}
+}
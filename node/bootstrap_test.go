@@ -0,0 +1,67 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package node
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/HcashOrg/hcd/wire"
+)
+
+// TestBootstrapBlocks ensures blocks written in the bootstrap file format are
+// read back in order and that blocks of other networks and oversized blocks
+// are rejected.
+func TestBootstrapBlocks(t *testing.T) {
+	blocks := [][]byte{{0x01, 0x02, 0x03}, {}, bytes.Repeat([]byte{0xff}, 300)}
+	var buf bytes.Buffer
+	for _, block := range blocks {
+		err := writeBootstrapBlock(&buf, wire.SimNet, block)
+		if err != nil {
+			t.Fatalf("unable to write block: %v", err)
+		}
+	}
+	serialized := buf.Bytes()
+
+	r := bytes.NewReader(serialized)
+	for i, want := range blocks {
+		block, err := readBootstrapBlock(r, wire.SimNet)
+		if err != nil {
+			t.Fatalf("unable to read block %d: %v", i, err)
+		}
+		if !reflect.DeepEqual(block, want) {
+			t.Fatalf("block %d mismatch: got %x, want %x", i, block,
+				want)
+		}
+	}
+	block, err := readBootstrapBlock(r, wire.SimNet)
+	if block != nil || err != nil {
+		t.Fatalf("unexpected block %x and error %v after the last block",
+			block, err)
+	}
+
+	// Blocks of other networks are rejected.
+	_, err = readBootstrapBlock(bytes.NewReader(serialized), wire.MainNet)
+	if err == nil {
+		t.Fatal("did not receive expected error for network mismatch")
+	}
+
+	// Truncated files and oversized blocks are rejected.
+	_, err = readBootstrapBlock(bytes.NewReader(serialized[:5]), wire.SimNet)
+	if err == nil {
+		t.Fatal("did not receive expected error for truncated header")
+	}
+	_, err = readBootstrapBlock(bytes.NewReader(serialized[:10]), wire.SimNet)
+	if err == nil {
+		t.Fatal("did not receive expected error for truncated block")
+	}
+	oversized := append([]byte(nil), serialized[:8]...)
+	oversized[7] = 0xff
+	_, err = readBootstrapBlock(bytes.NewReader(oversized), wire.SimNet)
+	if err == nil {
+		t.Fatal("did not receive expected error for oversized block")
+	}
+}
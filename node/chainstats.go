@@ -0,0 +1,171 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package node
+
+import (
+	"sync"
+
+	"github.com/HcashOrg/hcd/chaincfg"
+	"github.com/HcashOrg/hcd/chaincfg/chainhash"
+	"github.com/HcashOrg/hcd/hcutil"
+	"github.com/HcashOrg/hcd/wire"
+)
+
+// maxChainStatsBlocks is the number of most recent main chain blocks the
+// statistics are kept for.
+const maxChainStatsBlocks = 10000
+
+// blockStats summarizes the contents of a main chain block for the
+// getchainstats RPC.
+type blockStats struct {
+	Hash          chainhash.Hash
+	Height        int64
+	Time          int64
+	Txns          int64
+	Fees          int64
+	Tickets       int64
+	Votes         int64
+	Revocations   int64
+	EligibleVotes int64
+}
+
+// newBlockStats returns the statistics of the passed block.
+//
+// The fees are the difference between the input and output amounts of all
+// transactions except the coinbase.  This relies on the input amounts having
+// been checked against the spent outputs when the block was connected.
+func newBlockStats(block *hcutil.Block, params *chaincfg.Params) blockStats {
+	msgBlock := block.MsgBlock()
+	header := &msgBlock.Header
+	stats := blockStats{
+		Hash:        *block.Hash(),
+		Height:      int64(header.Height),
+		Time:        header.Timestamp.Unix(),
+		Txns:        int64(len(msgBlock.Transactions) + len(msgBlock.STransactions)),
+		Tickets:     int64(header.FreshStake),
+		Votes:       int64(header.Voters),
+		Revocations: int64(header.Revocations),
+	}
+	if stats.Height >= params.StakeValidationHeight {
+		stats.EligibleVotes = int64(params.TicketsPerBlock)
+	}
+
+	trees := [][]*wire.MsgTx{msgBlock.STransactions}
+	if len(msgBlock.Transactions) > 1 {
+		trees = append(trees, msgBlock.Transactions[1:])
+	}
+	for _, txns := range trees {
+		for _, tx := range txns {
+			for _, txIn := range tx.TxIn {
+				stats.Fees += txIn.ValueIn
+			}
+			for _, txOut := range tx.TxOut {
+				stats.Fees -= txOut.Value
+			}
+		}
+	}
+
+	return stats
+}
+
+// chainStats is a rolling store of the statistics of the most recent main
+// chain blocks.  It is maintained incrementally as blocks are connected to and
+// disconnected from the main chain, and statistics of older blocks may be
+// added as they are loaded from the database.
+//
+// The store is safe for concurrent access.
+type chainStats struct {
+	mtx    sync.Mutex
+	blocks map[int64]blockStats
+	tip    int64
+	limit  int64
+}
+
+// newChainStats returns a chain statistics store which keeps the statistics
+// of up to limit of the most recent main chain blocks, starting out with the
+// main chain ending at the passed height.
+func newChainStats(limit, tip int64) *chainStats {
+	return &chainStats{
+		blocks: make(map[int64]blockStats),
+		tip:    tip,
+		limit:  limit,
+	}
+}
+
+// connect records the statistics of a block connected to the main chain and
+// evicts those which are no longer among the most recent blocks.
+func (c *chainStats) connect(stats blockStats) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.blocks[stats.Height] = stats
+	c.tip = stats.Height
+	delete(c.blocks, stats.Height-c.limit)
+}
+
+// disconnect removes the statistics of a block disconnected from the main
+// chain.
+func (c *chainStats) disconnect(height int64) {
+	c.mtx.Lock()
+	delete(c.blocks, height)
+	c.tip = height - 1
+	c.mtx.Unlock()
+}
+
+// add records the statistics of a main chain block loaded from the database.
+// Nothing is recorded when the block is not among the most recent blocks.
+func (c *chainStats) add(stats blockStats) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if stats.Height <= c.tip-c.limit || stats.Height > c.tip {
+		return
+	}
+	if _, ok := c.blocks[stats.Height]; !ok {
+		c.blocks[stats.Height] = stats
+	}
+}
+
+// lookup returns the statistics of the main chain block at the passed height
+// and whether or not they are known to the store.
+func (c *chainStats) lookup(height int64) (blockStats, bool) {
+	c.mtx.Lock()
+	stats, ok := c.blocks[height]
+	c.mtx.Unlock()
+	return stats, ok
+}
+
+// chainStatsWindow accumulates the statistics of a window of consecutive main
+// chain blocks.
+type chainStatsWindow struct {
+	Blocks        int64
+	TxCount       int64
+	Fees          int64
+	Tickets       int64
+	Votes         int64
+	Revocations   int64
+	EligibleVotes int64
+}
+
+// add accumulates the statistics of one more block of the window.
+func (w *chainStatsWindow) add(stats *blockStats) {
+	w.Blocks++
+	w.TxCount += stats.Txns
+	w.Fees += stats.Fees
+	w.Tickets += stats.Tickets
+	w.Votes += stats.Votes
+	w.Revocations += stats.Revocations
+	w.EligibleVotes += stats.EligibleVotes
+}
+
+// participation returns the fraction of the votes the blocks of the window
+// could include which they do include.  It is zero when no votes are possible
+// yet.
+func (w *chainStatsWindow) participation() float64 {
+	if w.EligibleVotes == 0 {
+		return 0
+	}
+	return float64(w.Votes) / float64(w.EligibleVotes)
+}
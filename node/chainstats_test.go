@@ -0,0 +1,81 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package node
+
+import (
+	"testing"
+
+	"github.com/HcashOrg/hcd/chaincfg/chainhash"
+)
+
+// TestChainStats ensures the chain statistics store follows the main chain as
+// blocks are connected and disconnected, only keeps the most recent blocks and
+// only accepts loaded statistics of those blocks.
+func TestChainStats(t *testing.T) {
+	store := newChainStats(3, 10)
+	stats := func(height int64, hash byte) blockStats {
+		return blockStats{Hash: chainhash.Hash{hash}, Height: height}
+	}
+
+	// Loaded statistics are only accepted for the most recent blocks.
+	store.add(stats(7, 0x07))
+	store.add(stats(8, 0x08))
+	store.add(stats(11, 0x0b))
+	if _, ok := store.lookup(7); ok {
+		t.Fatal("statistics of block 7 beyond the limit were added")
+	}
+	if _, ok := store.lookup(11); ok {
+		t.Fatal("statistics of block 11 beyond the tip were added")
+	}
+	if _, ok := store.lookup(8); !ok {
+		t.Fatal("statistics of block 8 not found")
+	}
+
+	// Connecting a block evicts the oldest one.
+	store.connect(stats(11, 0x0b))
+	if _, ok := store.lookup(8); ok {
+		t.Fatal("statistics of block 8 were not evicted")
+	}
+
+	// Disconnecting a block removes its statistics and statistics loaded
+	// for heights beyond the new tip are not accepted.
+	store.disconnect(11)
+	if _, ok := store.lookup(11); ok {
+		t.Fatal("statistics of disconnected block 11 not removed")
+	}
+	store.add(stats(11, 0x0b))
+	if _, ok := store.lookup(11); ok {
+		t.Fatal("statistics of disconnected block 11 were added")
+	}
+	store.connect(stats(11, 0x1b))
+	got, ok := store.lookup(11)
+	if !ok || got.Hash != (chainhash.Hash{0x1b}) {
+		t.Fatalf("unexpected statistics of block 11: %v", got)
+	}
+}
+
+// TestChainStatsWindow ensures the statistics of a window of blocks are
+// accumulated and the stake participation is only reported once votes are
+// possible.
+func TestChainStatsWindow(t *testing.T) {
+	var window chainStatsWindow
+	window.add(&blockStats{Txns: 2, Fees: 1000, Tickets: 3})
+	if got := window.participation(); got != 0 {
+		t.Fatalf("unexpected participation without eligible votes: %v",
+			got)
+	}
+
+	window.add(&blockStats{Txns: 8, Fees: 500, Votes: 4, Revocations: 1,
+		EligibleVotes: 5})
+	window.add(&blockStats{Txns: 5, Votes: 5, EligibleVotes: 5})
+	want := chainStatsWindow{Blocks: 3, TxCount: 15, Fees: 1500,
+		Tickets: 3, Votes: 9, Revocations: 1, EligibleVotes: 10}
+	if window != want {
+		t.Fatalf("unexpected window: got %+v, want %+v", window, want)
+	}
+	if got := window.participation(); got != 0.9 {
+		t.Fatalf("unexpected participation: got %v, want 0.9", got)
+	}
+}
@@ -4,7 +4,7 @@
 // Use of this source code is governed by an ISC
 // license that can be found in the LICENSE file.
 
-package main
+package node
 
 import (
 	"crypto/rand"
@@ -12,6 +12,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"net/url"
 	"os"
 	"os/user"
 	"path/filepath"
@@ -22,11 +23,15 @@ import (
 	"strings"
 	"time"
 
+	"github.com/HcashOrg/hcd/addrmgr"
+	"github.com/HcashOrg/hcd/chaincfg"
+	"github.com/HcashOrg/hcd/chaincfg/chainhash"
 	"github.com/HcashOrg/hcd/connmgr"
 	"github.com/HcashOrg/hcd/database"
 	_ "github.com/HcashOrg/hcd/database/ffldb"
 	"github.com/HcashOrg/hcd/hcutil"
 	"github.com/HcashOrg/hcd/mempool"
+	"github.com/HcashOrg/hcd/peer"
 	"github.com/HcashOrg/hcd/sampleconfig"
 	"github.com/btcsuite/btclog"
 	"github.com/btcsuite/go-socks/socks"
@@ -39,12 +44,21 @@ const (
 	defaultLogLevel              = "info"
 	defaultLogDirname            = "logs"
 	defaultLogFilename           = "hcd.log"
+	defaultShutdownTimeout       = time.Minute * 2
 	defaultMaxPeers              = 125
 	defaultBanDuration           = time.Hour * 24
+	defaultSyncStallTimeout      = time.Minute * 2
 	defaultBanThreshold          = 100
+	defaultGetDataRate           = 5000
+	defaultGetHeadersRate        = 10
+	defaultMemPoolRate           = 0.1
+	defaultTrickleInterval       = peer.DefaultTrickleInterval
+	defaultInboundTrickle        = peer.DefaultInboundTrickleInterval
 	defaultMaxRPCClients         = 10
 	defaultMaxRPCWebsockets      = 25
 	defaultMaxRPCConcurrentReqs  = 20
+	defaultRPCCertValidity       = 10 * 365 * 24 * time.Hour
+	defaultRPCCertRotate         = 30 * 24 * time.Hour
 	defaultDbType                = "ffldb"
 	defaultFreeTxRelayLimit      = 15.0
 	defaultBlockMinSize          = 0
@@ -52,11 +66,17 @@ const (
 	blockMaxSizeMin              = 1000
 	defaultAddrIndex             = false
 	defaultGenerate              = false
+	defaultGenProcLimit          = -1
 	defaultNoMiningStateSync     = false
 	defaultAllowOldVotes         = false
 	defaultMaxOrphanTransactions = 1000
+	defaultMaxMempool            = mempool.DefaultMaxMempoolSize / 1000 / 1000
 	defaultMaxOrphanTxSize       = 5000
-	defaultSigCacheMaxSize       = 100000
+	blocksOnlyMaxMempool         = 5
+	blocksOnlyMaxOrphanTxs       = 10
+	defaultSigCacheMaxSize       = 32
+	maxSigCacheMaxSize           = 2048
+	defaultScriptCacheMaxSize    = 16
 	defaultTxIndex               = false
 	defaultNoExistsAddrIndex     = false
 )
@@ -84,15 +104,18 @@ func minUint32(a, b uint32) uint32 {
 	return b
 }
 
-// config defines the configuration options for hcd.
+// Config defines the configuration options for hcd.
 //
-// See loadConfig for details on the configuration load process.
-type config struct {
+// See LoadConfig for details on the configuration load process.
+type Config struct {
 	HomeDir              string        `short:"A" long:"appdata" description:"Path to application home directory"`
 	ShowVersion          bool          `short:"V" long:"version" description:"Display version information and exit"`
 	ConfigFile           string        `short:"C" long:"configfile" description:"Path to configuration file"`
 	DataDir              string        `short:"b" long:"datadir" description:"Directory to store data"`
+	BlocksDir            string        `long:"blocksdir" description:"Directory to store the block files of the block database in instead of the data directory"`
 	LogDir               string        `long:"logdir" description:"Directory to log output."`
+	ShutdownTimeout      time.Duration `long:"shutdowntimeout" description:"Maximum time to wait for the subsystems to stop on shutdown before exiting without closing the database.  Valid time units are {s, m, h}.  0 to wait indefinitely"`
+	DBVerifyInterval     time.Duration `long:"dbverifyinterval" description:"Verify the checksums, hashes and merkle roots of the stored blocks at this interval and repair the ones which fail with copies from peers.  Valid time units are {s, m, h}.  0 to disable"`
 	AddPeers             []string      `short:"a" long:"addpeer" description:"Add a peer to connect with at startup"`
 	ConnectPeers         []string      `long:"connect" description:"Connect only to the specified peers at startup"`
 	DisableListen        bool          `long:"nolisten" description:"Disable listening for incoming connections -- NOTE: Listening is automatically disabled if the --connect or --proxy options are used without also specifying listen interfaces via --listen"`
@@ -100,8 +123,15 @@ type config struct {
 	MaxPeers             int           `long:"maxpeers" description:"Max number of inbound and outbound peers"`
 	DisableBanning       bool          `long:"nobanning" description:"Disable banning of misbehaving peers"`
 	BanDuration          time.Duration `long:"banduration" description:"How long to ban misbehaving peers.  Valid time units are {s, m, h}.  Minimum 1 second"`
+	SyncStallTimeout     time.Duration `long:"syncstalltimeout" description:"Switch to another sync peer when the current one does not deliver any of the requested blocks or headers within this time while syncing.  Valid time units are {s, m, h}.  0 to disable"`
 	BanThreshold         uint32        `long:"banthreshold" description:"Maximum allowed ban score before disconnecting and banning misbehaving peers."`
 	Whitelists           []string      `long:"whitelist" description:"Add an IP network or IP that will not be banned. (eg. 192.168.1.0/24 or ::1)"`
+	Blacklists           []string      `long:"blacklist" description:"Add an IP network or IP that will be refused connections, unless whitelisted. (eg. 192.168.1.0/24 or ::1)"`
+	GetDataRate          float64       `long:"getdatarate" description:"Max inventory items per second a peer may request via getdata before its ban score is increased (0 to disable)"`
+	GetHeadersRate       float64       `long:"getheadersrate" description:"Max getheaders messages per second a peer may send before its ban score is increased (0 to disable)"`
+	MemPoolRate          float64       `long:"mempoolrate" description:"Max mempool messages per second a peer may send before its ban score is increased (0 to disable)"`
+	TrickleInterval      time.Duration `long:"trickleinterval" description:"Average interval between the randomly timed batches of transaction inventory sent to outbound peers.  Valid time units are {ms, s, m, h}"`
+	InboundTrickle       time.Duration `long:"inboundtrickleinterval" description:"Average interval between the randomly timed batches of transaction inventory sent to inbound peers.  Valid time units are {ms, s, m, h}"`
 	RPCUser              string        `short:"u" long:"rpcuser" description:"Username for RPC connections"`
 	RPCPass              string        `short:"P" long:"rpcpass" default-mask:"-" description:"Password for RPC connections"`
 	RPCLimitUser         string        `long:"rpclimituser" description:"Username for limited RPC connections"`
@@ -109,11 +139,23 @@ type config struct {
 	RPCListeners         []string      `long:"rpclisten" description:"Add an interface/port to listen for RPC connections (default port: 14009, testnet: 12009)"`
 	RPCCert              string        `long:"rpccert" description:"File containing the certificate file"`
 	RPCKey               string        `long:"rpckey" description:"File containing the certificate key"`
+	RPCPublicListeners   []string      `long:"rpcpubliclisten" description:"Add an interface/port to listen for RPC connections which are only authorized for the limited RPC methods regardless of their credentials"`
+	RPCPublicCert        string        `long:"rpcpubliccert" description:"File containing the certificate file for the public RPC listeners (default: rpccert)"`
+	RPCPublicKey         string        `long:"rpcpublickey" description:"File containing the certificate key for the public RPC listeners (default: rpckey)"`
+	RPCCertValidity      time.Duration `long:"rpccertvalidity" description:"Validity period of auto-generated RPC certificates.  Valid time units are {s, m, h}"`
+	RPCCertRotate        time.Duration `long:"rpccertrotate" description:"Regenerate auto-generated RPC certificates once they expire within this duration and notify websocket clients (0 to disable).  Valid time units are {s, m, h}"`
+	RPCExtraHosts        []string      `long:"rpcextrahost" description:"Add an IP address or hostname to auto-generated RPC certificates, which are regenerated when they don't cover it"`
+	RPCCookieFile        string        `long:"rpccookie" description:"File to write the RPC authentication cookie to (default: .cookie in the data directory)"`
+	NoRPCCookie          bool          `long:"norpccookie" description:"Disable RPC authentication with a cookie file"`
 	RPCMaxClients        int           `long:"rpcmaxclients" description:"Max number of RPC clients for standard connections"`
 	RPCMaxWebsockets     int           `long:"rpcmaxwebsockets" description:"Max number of RPC websocket connections"`
 	RPCMaxConcurrentReqs int           `long:"rpcmaxconcurrentreqs" description:"Max number of concurrent RPC requests that may be processed concurrently"`
-	DisableRPC           bool          `long:"norpc" description:"Disable built-in RPC server -- NOTE: The RPC server is disabled by default if no rpcuser/rpcpass or rpclimituser/rpclimitpass is specified"`
+	DisableRPC           bool          `long:"norpc" description:"Disable built-in RPC server -- NOTE: The RPC server is disabled by default if no rpcuser/rpcpass or rpclimituser/rpclimitpass is specified and cookie authentication is disabled"`
 	DisableTLS           bool          `long:"notls" description:"Disable TLS for the RPC server -- NOTE: This is only allowed if the RPC server is bound to localhost"`
+	ZMQPubHashBlock      string        `long:"zmqpubhashblock" description:"Publish the hashes of connected blocks on the given interface/port"`
+	ZMQPubHashTx         string        `long:"zmqpubhashtx" description:"Publish the hashes of transactions accepted to the mempool on the given interface/port"`
+	ZMQPubRawBlock       string        `long:"zmqpubrawblock" description:"Publish serialized connected blocks on the given interface/port"`
+	ZMQPubRawTx          string        `long:"zmqpubrawtx" description:"Publish serialized transactions accepted to the mempool on the given interface/port"`
 	DisableDNSSeed       bool          `long:"nodnsseed" description:"Disable DNS seeding for peers"`
 	ExternalIPs          []string      `long:"externalip" description:"Add an ip to the list of local addresses we claim to listen on to peers"`
 	Proxy                string        `long:"proxy" description:"Connect via SOCKS5 proxy (eg. 127.0.0.1:9050)"`
@@ -123,34 +165,51 @@ type config struct {
 	OnionProxyUser       string        `long:"onionuser" description:"Username for onion proxy server"`
 	OnionProxyPass       string        `long:"onionpass" default-mask:"-" description:"Password for onion proxy server"`
 	NoOnion              bool          `long:"noonion" description:"Disable connecting to tor hidden services"`
+	OnlyNets             []string      `long:"onlynet" description:"Only make automatic outbound connections to addresses of the specified network {ipv4, ipv6, onion} -- may be specified multiple times"`
 	TorIsolation         bool          `long:"torisolation" description:"Enable Tor stream isolation by randomizing user credentials for each connection."`
+	Privacy              bool          `long:"privacy" description:"Never use the system DNS resolver: resolve all names, including DNS seeds, through the proxy or, without a proxy, skip DNS seeding and refuse lookups"`
 	TestNet              bool          `long:"testnet" description:"Use the test network"`
 	SimNet               bool          `long:"simnet" description:"Use the simulation test network"`
+	CustomNet            string        `long:"customnet" description:"Use the custom network defined in the passed JSON file"`
 	DisableCheckpoints   bool          `long:"nocheckpoints" description:"Disable built-in checkpoints.  Don't do this unless you know what you're doing."`
+	AssumeValid          string        `long:"assumevalid" description:"Hash of a block assumed to be valid -- script validation is skipped for its ancestors during the initial block download (default: per network, 0 to disable)"`
+	AssumeValidHeight    int64         `long:"assumevalidheight" description:"Height of the block passed with --assumevalid -- required with it"`
+	MaxReorgDepth        int64         `long:"maxreorgdepth" description:"Maximum number of blocks a reorganization may disconnect without being approved with the reconsiderblock RPC (0 for unlimited)"`
 	DbType               string        `long:"dbtype" description:"Database backend to use for the Block Chain"`
 	Profile              string        `long:"profile" description:"Enable HTTP profiling on given [addr:]port -- NOTE port must be between 1024 and 65536"`
 	CPUProfile           string        `long:"cpuprofile" description:"Write CPU profile to the specified file"`
 	MemProfile           string        `long:"memprofile" description:"Write mem profile to the specified file"`
+	SlowQueryThreshold   time.Duration `long:"slowquerythreshold" description:"Log RPC calls and database transactions which take longer than this duration and keep them for the getslowqueries RPC (0 to disable).  Valid time units are {s, ms, us}"`
+	LockHoldThreshold    time.Duration `long:"lockholdthreshold" description:"Profile the contention of the mempool and chain locks for the getlockstats RPC and warn when they are held for longer than this duration (0 to disable).  Valid time units are {s, ms, us}"`
+	TracingEndpoint      string        `long:"tracingendpoint" description:"Export trace spans of RPC requests to the given OTLP/HTTP traces endpoint (eg. http://localhost:4318/v1/traces)"`
 	DumpBlockchain       string        `long:"dumpblockchain" description:"Write blockchain as a flat file of blocks for use with addblock, to the specified filename"`
+	ImportBootstrap      []string      `long:"importbootstrap" description:"Import and fully validate the blocks of a flat file of blocks as written by --dumpblockchain, while the server is running -- may be specified multiple times to import files in order"`
 	MiningTimeOffset     int           `long:"miningtimeoffset" description:"Offset the mining timestamp of a block by this many seconds (positive values are in the past)"`
 	DebugLevel           string        `short:"d" long:"debuglevel" description:"Logging level for all subsystems {trace, debug, info, warn, error, critical} -- You may also specify <subsystem>=<level>,<subsystem2>=<level>,... to set the log level for individual subsystems -- Use show to list available subsystems"`
 	Upnp                 bool          `long:"upnp" description:"Use UPnP to map our listening port outside of NAT"`
 	MinRelayTxFee        float64       `long:"minrelaytxfee" description:"The minimum transaction fee in HC/kB to be considered a non-zero fee."`
+	DustRelayFee         float64       `long:"dustrelayfee" description:"The fee rate in HC/kB used to define dust, the value of an output below which it is considered non-standard."`
+	MempoolExpiry        time.Duration `long:"mempoolexpiry" description:"Evict transactions which have been in the memory pool for longer than this.  Valid time units are {s, m, h}.  0 to disable"`
 	FreeTxRelayLimit     float64       `long:"limitfreerelay" description:"Limit relay of transactions with no transaction fee to the given amount in thousands of bytes per minute"`
 	NoRelayPriority      bool          `long:"norelaypriority" description:"Do not require free or low-fee transactions to have high priority for relaying"`
 	MaxOrphanTxs         int           `long:"maxorphantx" description:"Max number of orphan transactions to keep in memory"`
+	MaxMempool           int           `long:"maxmempool" description:"Max size in MB of memory used by the transactions in the memory pool, after which those with the lowest fee rate are evicted; 0 to disable"`
 	Generate             bool          `long:"generate" description:"Generate (mine) coins using the CPU"`
+	GenProcLimit         int32         `long:"genproclimit" description:"Number of goroutines the nonce space is split between when generating coins using the CPU -- Negative values use the number of processor cores"`
 	MiningAddrs          []string      `long:"miningaddr" description:"Add the specified payment address to the list of addresses to use for generated blocks -- At least one address is required if the generate option is set"`
+	MiningAddrSplit      []string      `long:"miningaddrsplit" description:"Split the miner payout of generated blocks between the specified payment addresses in proportion to their weights, given as address:weight (eg. Hs...:3) -- May be specified multiple times and replaces the addresses given with --miningaddr as the payout"`
 	BlockMinSize         uint32        `long:"blockminsize" description:"Mininum block size in bytes to be used when creating a block"`
 	BlockMaxSize         uint32        `long:"blockmaxsize" description:"Maximum block size in bytes to be used when creating a block"`
 	BlockPrioritySize    uint32        `long:"blockprioritysize" description:"Size in bytes for high-priority/low-fee transactions when creating a block"`
 	GetWorkKeys          []string      `long:"getworkkey" description:"DEPRECATED -- Use the --miningaddr option instead"`
 	NoPeerBloomFilters   bool          `long:"nopeerbloomfilters" description:"Disable bloom filtering support"`
-	SigCacheMaxSize      uint          `long:"sigcachemaxsize" description:"The maximum number of entries in the signature verification cache"`
+	SigCacheMaxSize      uint          `long:"sigcachemaxsize" description:"The maximum size in MB of the signature verification cache -- 0 disables the cache"`
+	ScriptCacheMaxSize   uint          `long:"scriptcachemaxsize" description:"The maximum size in MB of the cache of input scripts verified when accepting transactions to the mempool"`
 	NonAggressive        bool          `long:"nonaggressive" description:"Disable mining off of the parent block of the blockchain if there aren't enough voters"`
 	NoMiningStateSync    bool          `long:"nominingstatesync" description:"Disable synchronizing the mining state with other nodes"`
 	AllowOldVotes        bool          `long:"allowoldvotes" description:"Enable the addition of very old votes to the mempool"`
-	BlocksOnly           bool          `long:"blocksonly" description:"Do not accept transactions from remote peers."`
+	BlocksOnly           bool          `long:"blocksonly" description:"Do not accept transactions from remote peers and reduce the default memory pool limits accordingly."`
+	NoMempoolSync        bool          `long:"nomempoolsync" description:"Do not request the memory pool contents of outbound peers when connecting to them"`
 	RelayNonStd          bool          `long:"relaynonstd" description:"Relay non-standard transactions regardless of the default settings for the active network."`
 	RejectNonStd         bool          `long:"rejectnonstd" description:"Reject non-standard transactions regardless of the default settings for the active network."`
 	TxIndex              bool          `long:"txindex" description:"Maintain a full hash-based transaction index which makes all transactions available via the getrawtransaction RPC"`
@@ -159,6 +218,8 @@ type config struct {
 	DropAddrIndex        bool          `long:"dropaddrindex" description:"Deletes the address-based transaction index from the database on start up and then exits."`
 	NoExistsAddrIndex    bool          `long:"noexistsaddrindex" description:"Disable the exists address index, which tracks whether or not an address has even been used."`
 	DropExistsAddrIndex  bool          `long:"dropexistsaddrindex" description:"Deletes the exists address index from the database on start up and then exits."`
+	ReindexChainState    bool          `long:"reindexchainstate" description:"Rebuild the chain state, such as the utxo set and the ticket database, from the blocks stored in the database on start up without downloading them again -- the optional indexes are rebuilt along with it"`
+	ReindexIndexes       []string      `long:"reindexindex" description:"Drop an enabled optional index on start up and rebuild it in the background once the chain is synced {txindex, addrindex, existsaddrindex} -- may be specified multiple times"`
 	PipeRx               uint          `long:"piperx" description:"File descriptor of read end pipe to enable parent -> child process communication"`
 	PipeTx               uint          `long:"pipetx" description:"File descriptor of write end pipe to enable parent <- child process communication"`
 	LifetimeEvents       bool          `long:"lifetimeevents" description:"Send lifetime notifications over the TX pipe"`
@@ -167,8 +228,14 @@ type config struct {
 	oniondial            func(string, string) (net.Conn, error)
 	dial                 func(string, string) (net.Conn, error)
 	miningAddrs          []hcutil.Address
+	miningPayouts        []minerPayout
 	minRelayTxFee        hcutil.Amount
+	dustRelayFee         hcutil.Amount
 	whitelists           []*net.IPNet
+	blacklists           []*net.IPNet
+	onlyNets             map[addrmgr.NetworkType]struct{}
+	assumeValid          *chaincfg.Checkpoint
+	args                 []string
 }
 
 // serviceOptions defines the configuration options for the daemon as a service on
@@ -342,6 +409,39 @@ func normalizeAddresses(addrs []string, defaultPort string) []string {
 	return removeDuplicateAddresses(addrs)
 }
 
+// parseIPNets parses the passed IP networks in CIDR notation and individual
+// IP addresses into a slice of IP networks.  Individual IP addresses are
+// treated as networks which only contain that address.
+func parseIPNets(addrs []string) ([]*net.IPNet, error) {
+	if len(addrs) == 0 {
+		return nil, nil
+	}
+
+	ipnets := make([]*net.IPNet, 0, len(addrs))
+	for _, addr := range addrs {
+		_, ipnet, err := net.ParseCIDR(addr)
+		if err != nil {
+			ip := net.ParseIP(addr)
+			if ip == nil {
+				return nil, fmt.Errorf("'%s' is invalid", addr)
+			}
+			var bits int
+			if ip.To4() == nil {
+				// IPv6
+				bits = 128
+			} else {
+				bits = 32
+			}
+			ipnet = &net.IPNet{
+				IP:   ip,
+				Mask: net.CIDRMask(bits, bits),
+			}
+		}
+		ipnets = append(ipnets, ipnet)
+	}
+	return ipnets, nil
+}
+
 // filesExists reports whether the named file or directory exists.
 func fileExists(name string) bool {
 	if _, err := os.Stat(name); err != nil {
@@ -353,7 +453,7 @@ func fileExists(name string) bool {
 }
 
 // newConfigParser returns a new command line flags parser.
-func newConfigParser(cfg *config, so *serviceOptions, options flags.Options) *flags.Parser {
+func newConfigParser(cfg *Config, so *serviceOptions, options flags.Options) *flags.Parser {
 	parser := flags.NewParser(cfg, options)
 	if runtime.GOOS == "windows" {
 		parser.AddGroup("Service Options", "Service Options", so)
@@ -405,8 +505,8 @@ func createDefaultConfigFile(destPath string) error {
 	return err
 }
 
-// loadConfig initializes and parses the config using a config file and command
-// line options.
+// LoadConfig initializes and parses the config using a config file and the
+// passed command line options, which do not include the program name.
 //
 // The configuration proceeds as follows:
 // 	1) Start with a default config with sane settings
@@ -417,31 +517,49 @@ func createDefaultConfigFile(destPath string) error {
 // The above results in hcd functioning properly without any config settings
 // while still allowing the user to override settings with config files and
 // command line options.  Command line options always take precedence.
-func loadConfig() (*config, []string, error) {
+//
+// It also initializes logging and the active network parameters, which are
+// process wide, and exits the process when the help or version flags or a
+// Windows service command are given.
+func LoadConfig(args []string) (*Config, []string, error) {
 	// Default config.
-	cfg := config{
+	cfg := Config{
 		HomeDir:              defaultHomeDir,
 		ConfigFile:           defaultConfigFile,
 		DebugLevel:           defaultLogLevel,
 		MaxPeers:             defaultMaxPeers,
 		BanDuration:          defaultBanDuration,
+		SyncStallTimeout:     defaultSyncStallTimeout,
 		BanThreshold:         defaultBanThreshold,
+		GetDataRate:          defaultGetDataRate,
+		GetHeadersRate:       defaultGetHeadersRate,
+		MemPoolRate:          defaultMemPoolRate,
+		TrickleInterval:      defaultTrickleInterval,
+		InboundTrickle:       defaultInboundTrickle,
 		RPCMaxClients:        defaultMaxRPCClients,
 		RPCMaxWebsockets:     defaultMaxRPCWebsockets,
 		RPCMaxConcurrentReqs: defaultMaxRPCConcurrentReqs,
+		RPCCertValidity:      defaultRPCCertValidity,
+		RPCCertRotate:        defaultRPCCertRotate,
 		DataDir:              defaultDataDir,
 		LogDir:               defaultLogDir,
+		ShutdownTimeout:      defaultShutdownTimeout,
 		DbType:               defaultDbType,
 		RPCKey:               defaultRPCKeyFile,
 		RPCCert:              defaultRPCCertFile,
 		MinRelayTxFee:        mempool.DefaultMinRelayTxFee.ToCoin(),
+		DustRelayFee:         mempool.DefaultDustRelayFee.ToCoin(),
+		MempoolExpiry:        mempool.DefaultMempoolExpiry,
 		FreeTxRelayLimit:     defaultFreeTxRelayLimit,
 		BlockMinSize:         defaultBlockMinSize,
 		BlockMaxSize:         defaultBlockMaxSize,
 		BlockPrioritySize:    mempool.DefaultBlockPrioritySize,
 		MaxOrphanTxs:         defaultMaxOrphanTransactions,
+		MaxMempool:           defaultMaxMempool,
 		SigCacheMaxSize:      defaultSigCacheMaxSize,
+		ScriptCacheMaxSize:   defaultScriptCacheMaxSize,
 		Generate:             defaultGenerate,
+		GenProcLimit:         defaultGenProcLimit,
 		NoMiningStateSync:    defaultNoMiningStateSync,
 		TxIndex:              defaultTxIndex,
 		AddrIndex:            defaultAddrIndex,
@@ -458,7 +576,7 @@ func loadConfig() (*config, []string, error) {
 	// the final parse below.
 	preCfg := cfg
 	preParser := newConfigParser(&preCfg, &serviceOpts, flags.HelpFlag)
-	_, err := preParser.Parse()
+	_, err := preParser.ParseArgs(args)
 	if err != nil {
 		if e, ok := err.(*flags.Error); ok && e.Type != flags.ErrHelp {
 			fmt.Fprintln(os.Stderr, err)
@@ -554,16 +672,19 @@ func loadConfig() (*config, []string, error) {
 	}
 
 	// Parse command line options again to ensure they take precedence.
-	remainingArgs, err := parser.Parse()
+	// They are kept to take precedence when the configuration is reloaded
+	// as well.
+	remainingArgs, err := parser.ParseArgs(args)
 	if err != nil {
 		if e, ok := err.(*flags.Error); !ok || e.Type != flags.ErrHelp {
 			fmt.Fprintln(os.Stderr, usageMessage)
 		}
 		return nil, nil, err
 	}
+	cfg.args = args
 
 	// Create the home directory if it doesn't already exist.
-	funcName := "loadConfig"
+	funcName := "LoadConfig"
 	err = os.MkdirAll(cfg.HomeDir, 0700)
 	if err != nil {
 		// Show a nicer error message if it's because a symlink is
@@ -597,9 +718,22 @@ func loadConfig() (*config, []string, error) {
 		activeNetParams = &simNetParams
 		cfg.DisableDNSSeed = true
 	}
+	if cfg.CustomNet != "" {
+		numNets++
+		customNetParams, err := loadCustomNetParams(
+			cleanAndExpandPath(cfg.CustomNet))
+		if err != nil {
+			str := "%s: unable to load the custom network: %v"
+			err := fmt.Errorf(str, funcName, err)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+		activeNetParams = customNetParams
+	}
 	if numNets > 1 {
-		str := "%s: the testnet and simnet params can't be " +
-			"used together -- choose one of the three"
+		str := "%s: the testnet, simnet and customnet params can't be " +
+			"used together -- choose one of the four"
 		err := fmt.Errorf(str, funcName)
 		fmt.Fprintln(os.Stderr, err)
 		fmt.Fprintln(os.Stderr, usageMessage)
@@ -642,6 +776,34 @@ func loadConfig() (*config, []string, error) {
 	
 	cfg.DataDir = filepath.Join(cfg.DataDir, netName(activeNetParams))
 
+	// The block files are namespaced per network the same way when they
+	// are stored separately from the data directory.
+	if cfg.BlocksDir != "" {
+		cfg.BlocksDir = cleanAndExpandPath(cfg.BlocksDir)
+		cfg.BlocksDir = filepath.Join(cfg.BlocksDir,
+			netName(activeNetParams))
+	}
+
+	// The RPC authentication cookie is namespaced per network along with
+	// the data directory unless a path is given.
+	if cfg.RPCCookieFile == "" {
+		cfg.RPCCookieFile = filepath.Join(cfg.DataDir, ".cookie")
+	} else {
+		cfg.RPCCookieFile = cleanAndExpandPath(cfg.RPCCookieFile)
+	}
+
+	// Ensure the bootstrap files to import exist.
+	for i, path := range cfg.ImportBootstrap {
+		cfg.ImportBootstrap[i] = cleanAndExpandPath(path)
+		if _, err := os.Stat(cfg.ImportBootstrap[i]); err != nil {
+			str := "%s: unable to access the bootstrap file to " +
+				"import: %v"
+			err := fmt.Errorf(str, funcName, err)
+			fmt.Fprintln(os.Stderr, err)
+			return nil, nil, err
+		}
+	}
+
 	// Append the network type to the log directory so it is "namespaced"
 	// per network in the same fashion as the data directory.
 	cfg.LogDir = cleanAndExpandPath(cfg.LogDir)
@@ -675,6 +837,16 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// The memory database does not store any block files.
+	if cfg.BlocksDir != "" && cfg.DbType == "memdb" {
+		str := "%s: the blocksdir option can't be used with the %v " +
+			"database type"
+		err := fmt.Errorf(str, funcName, cfg.DbType)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
 	// Validate format of profile, can be an address:port, or just a port.
 	if cfg.Profile != "" {
 		// if profile is just a number, then add a default host of "127.0.0.1" such that Profile is a valid tcp address
@@ -702,6 +874,40 @@ func loadConfig() (*config, []string, error) {
 		}
 	}
 
+	// Don't allow negative slow query thresholds.
+	if cfg.SlowQueryThreshold < 0 {
+		str := "%s: the slowquerythreshold option may not be negative " +
+			"-- parsed [%v]"
+		err := fmt.Errorf(str, funcName, cfg.SlowQueryThreshold)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Don't allow negative lock hold thresholds.
+	if cfg.LockHoldThreshold < 0 {
+		str := "%s: the lockholdthreshold option may not be negative " +
+			"-- parsed [%v]"
+		err := fmt.Errorf(str, funcName, cfg.LockHoldThreshold)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// The tracing endpoint must be an HTTP URL.
+	if cfg.TracingEndpoint != "" {
+		u, err := url.Parse(cfg.TracingEndpoint)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") ||
+			u.Host == "" {
+
+			str := "%s: tracingendpoint: %q is not an HTTP URL"
+			err := fmt.Errorf(str, funcName, cfg.TracingEndpoint)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+	}
+
 	// Don't allow ban durations that are too short.
 	if cfg.BanDuration < time.Second {
 		str := "%s: the banduration option may not be less than 1s -- parsed [%v]"
@@ -711,38 +917,65 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
-	// Validate any given whitelisted IP addresses and networks.
-	if len(cfg.Whitelists) > 0 {
-		var ip net.IP
-		cfg.whitelists = make([]*net.IPNet, 0, len(cfg.Whitelists))
+	// Don't allow trickle intervals that are too short to batch anything.
+	trickleIntervals := []struct {
+		option   string
+		interval time.Duration
+	}{
+		{"trickleinterval", cfg.TrickleInterval},
+		{"inboundtrickleinterval", cfg.InboundTrickle},
+	}
+	for _, trickle := range trickleIntervals {
+		if trickle.interval < time.Millisecond {
+			str := "%s: the %s option may not be less than 1ms " +
+				"-- parsed [%v]"
+			err := fmt.Errorf(str, funcName, trickle.option,
+				trickle.interval)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+	}
 
-		for _, addr := range cfg.Whitelists {
-			_, ipnet, err := net.ParseCIDR(addr)
-			if err != nil {
-				ip = net.ParseIP(addr)
-				if ip == nil {
-					str := "%s: the whitelist value of '%s' is invalid"
-					err = fmt.Errorf(str, funcName, addr)
-					fmt.Fprintln(os.Stderr, err)
-					fmt.Fprintln(os.Stderr, usageMessage)
-					return nil, nil, err
-				}
-				var bits int
-				if ip.To4() == nil {
-					// IPv6
-					bits = 128
-				} else {
-					bits = 32
-				}
-				ipnet = &net.IPNet{
-					IP:   ip,
-					Mask: net.CIDRMask(bits, bits),
-				}
-			}
-			cfg.whitelists = append(cfg.whitelists, ipnet)
+	// Don't allow negative peer message rate limits.
+	rateLimits := []struct {
+		option string
+		rate   float64
+	}{
+		{"getdatarate", cfg.GetDataRate},
+		{"getheadersrate", cfg.GetHeadersRate},
+		{"mempoolrate", cfg.MemPoolRate},
+	}
+	for _, limit := range rateLimits {
+		if limit.rate < 0 {
+			str := "%s: the %s option may not be less than 0 " +
+				"-- parsed [%v]"
+			err := fmt.Errorf(str, funcName, limit.option, limit.rate)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
 		}
 	}
 
+	// Validate any given whitelisted and blacklisted IP addresses and
+	// networks.
+	cfg.whitelists, err = parseIPNets(cfg.Whitelists)
+	if err != nil {
+		str := "%s: the whitelist value of %v"
+		err := fmt.Errorf(str, funcName, err)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+	cfg.blacklists, err = parseIPNets(cfg.Blacklists)
+	if err != nil {
+		str := "%s: the blacklist value of %v"
+		err := fmt.Errorf(str, funcName, err)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
 	// --addPeer and --connect do not mix.
 	if len(cfg.AddPeers) > 0 && len(cfg.ConnectPeers) > 0 {
 		str := "%s: the --addpeer and --connect options can not be " +
@@ -753,6 +986,29 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// Validate the networks automatic outbound connections are restricted
+	// to, if any.
+	if len(cfg.OnlyNets) > 0 {
+		cfg.onlyNets = make(map[addrmgr.NetworkType]struct{})
+		for _, name := range cfg.OnlyNets {
+			network, err := addrmgr.ParseNetworkType(name)
+			if err == nil && network == addrmgr.OnionNetwork &&
+				cfg.NoOnion {
+
+				err = errors.New("tor has been disabled")
+			}
+			if err != nil {
+				str := "%s: the specified onlynet [%v] is " +
+					"invalid: %v"
+				err := fmt.Errorf(str, funcName, name, err)
+				fmt.Fprintln(os.Stderr, err)
+				fmt.Fprintln(os.Stderr, usageMessage)
+				return nil, nil, err
+			}
+			cfg.onlyNets[network] = struct{}{}
+		}
+	}
+
 	// --proxy or --connect without --listen disables listening.
 	if (cfg.Proxy != "" || len(cfg.ConnectPeers) > 0) &&
 		len(cfg.Listeners) == 0 {
@@ -767,9 +1023,21 @@ func loadConfig() (*config, []string, error) {
 	// Add the default listener if none were specified. The default
 	// listener is all addresses on the listen port for the network
 	// we are to connect to.
+	//
+	// Only listen on the IPv6 or IPv4 addresses when the automatic outbound
+	// connections are restricted to one of them.
 	if len(cfg.Listeners) == 0 {
+		var host string
+		_, ipv4 := cfg.onlyNets[addrmgr.IPv4Network]
+		_, ipv6 := cfg.onlyNets[addrmgr.IPv6Network]
+		switch {
+		case ipv6 && !ipv4:
+			host = "::"
+		case ipv4 && !ipv6:
+			host = "0.0.0.0"
+		}
 		cfg.Listeners = []string{
-			net.JoinHostPort("", activeNetParams.DefaultPort),
+			net.JoinHostPort(host, activeNetParams.DefaultPort),
 		}
 	}
 
@@ -793,9 +1061,11 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
-	// The RPC server is disabled if no username or password is provided.
+	// The RPC server is disabled if no username or password is provided
+	// and clients can't authenticate with the cookie either.
 	if (cfg.RPCUser == "" || cfg.RPCPass == "") &&
-		(cfg.RPCLimitUser == "" || cfg.RPCLimitPass == "") {
+		(cfg.RPCLimitUser == "" || cfg.RPCLimitPass == "") &&
+		cfg.NoRPCCookie {
 		cfg.DisableRPC = true
 	}
 
@@ -831,6 +1101,120 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// Limit the size of the signature cache so the size in bytes also fits
+	// on 32-bit platforms.  A size of 0 disables the cache.
+	if cfg.SigCacheMaxSize > maxSigCacheMaxSize {
+		str := "%s: the sigcachemaxsize option may not be more than " +
+			"%d MB -- parsed [%d]"
+		err := fmt.Errorf(str, funcName, maxSigCacheMaxSize,
+			cfg.SigCacheMaxSize)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Validate the the dustrelayfee.
+	cfg.dustRelayFee, err = hcutil.NewAmount(cfg.DustRelayFee)
+	if err != nil || cfg.dustRelayFee < 0 {
+		if err == nil {
+			err = fmt.Errorf("may not be negative -- parsed [%v]",
+				cfg.DustRelayFee)
+		}
+		str := "%s: invalid dustrelayfee: %v"
+		err := fmt.Errorf(str, funcName, err)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Parse the block assumed to be valid.  It defaults to the one of the
+	// active network and a value of 0 disables it.  Its height must be
+	// known so the headers are only downloaded up to it.
+	switch cfg.AssumeValid {
+	case "", "0":
+		if cfg.AssumeValidHeight != 0 {
+			str := "%s: the assumevalidheight option requires a " +
+				"block passed with assumevalid"
+			err := fmt.Errorf(str, funcName)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+		if cfg.AssumeValid == "" && activeNetParams.AssumeValid != zeroHash {
+			hash := activeNetParams.AssumeValid
+			cfg.assumeValid = &chaincfg.Checkpoint{
+				Height: activeNetParams.AssumeValidHeight,
+				Hash:   &hash,
+			}
+		}
+	default:
+		hash, err := chainhash.NewHashFromStr(cfg.AssumeValid)
+		if err != nil {
+			str := "%s: invalid assumevalid: %v"
+			err := fmt.Errorf(str, funcName, err)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+		if cfg.AssumeValidHeight <= 0 {
+			str := "%s: the assumevalid option requires the height " +
+				"of the block with assumevalidheight -- parsed [%d]"
+			err := fmt.Errorf(str, funcName, cfg.AssumeValidHeight)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+		cfg.assumeValid = &chaincfg.Checkpoint{
+			Height: cfg.AssumeValidHeight,
+			Hash:   hash,
+		}
+	}
+
+	// Don't allow a negative maximum reorganization depth.
+	if cfg.MaxReorgDepth < 0 {
+		str := "%s: the maxreorgdepth option may not be negative -- parsed [%d]"
+		err := fmt.Errorf(str, funcName, cfg.MaxReorgDepth)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Don't allow negative shutdown timeouts.
+	if cfg.ShutdownTimeout < 0 {
+		str := "%s: the shutdowntimeout option may not be negative -- parsed [%v]"
+		err := fmt.Errorf(str, funcName, cfg.ShutdownTimeout)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Don't allow negative sync stall timeouts.
+	if cfg.SyncStallTimeout < 0 {
+		str := "%s: the syncstalltimeout option may not be negative -- parsed [%v]"
+		err := fmt.Errorf(str, funcName, cfg.SyncStallTimeout)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Don't allow negative database verification intervals.
+	if cfg.DBVerifyInterval < 0 {
+		str := "%s: the dbverifyinterval option may not be negative -- parsed [%v]"
+		err := fmt.Errorf(str, funcName, cfg.DBVerifyInterval)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Don't allow negative mempool expiry durations.
+	if cfg.MempoolExpiry < 0 {
+		str := "%s: the mempoolexpiry option may not be negative -- parsed [%v]"
+		err := fmt.Errorf(str, funcName, cfg.MempoolExpiry)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
 	// Ensure the specified max block size is not larger than the network will
 	// allow.  1000 bytes is subtracted from the max to account for overhead.
 	blockMaxSizeMax := uint32(activeNetParams.MaximumBlockSizes[0]) - 1000
@@ -856,6 +1240,28 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// Don't allow a negative max mempool size.
+	if cfg.MaxMempool < 0 {
+		str := "%s: the maxmempool option may not be less than 0 " +
+			"-- parsed [%d]"
+		err := fmt.Errorf(str, funcName, cfg.MaxMempool)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Transactions are only accepted from the local node in blocks only
+	// mode, so reduce the memory pool limits which were not explicitly
+	// set.
+	if cfg.BlocksOnly {
+		if cfg.MaxMempool == defaultMaxMempool {
+			cfg.MaxMempool = blocksOnlyMaxMempool
+		}
+		if cfg.MaxOrphanTxs == defaultMaxOrphanTransactions {
+			cfg.MaxOrphanTxs = blocksOnlyMaxOrphanTxs
+		}
+	}
+
 	// Limit the block priority and minimum block sizes to max block size.
 	cfg.BlockPrioritySize = minUint32(cfg.BlockPrioritySize, cfg.BlockMaxSize)
 	cfg.BlockMinSize = minUint32(cfg.BlockMinSize, cfg.BlockMaxSize)
@@ -901,6 +1307,32 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// Only enabled optional indexes may be rebuilt.
+	enabledIndexes := map[string]bool{
+		"txindex":         cfg.TxIndex || cfg.AddrIndex,
+		"addrindex":       cfg.AddrIndex,
+		"existsaddrindex": !cfg.NoExistsAddrIndex,
+	}
+	for _, name := range cfg.ReindexIndexes {
+		enabled, ok := enabledIndexes[name]
+		if !ok {
+			str := "%s: the --reindexindex option does not support " +
+				"index %q -- supported indexes are txindex, " +
+				"addrindex and existsaddrindex"
+			err := fmt.Errorf(str, funcName, name)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+		if !enabled {
+			str := "%s: the %s passed to --reindexindex is not enabled"
+			err := fmt.Errorf(str, funcName, name)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+	}
+
 	// Check getwork keys are valid and saved parsed versions.
 	cfg.miningAddrs = make([]hcutil.Address, 0, len(cfg.GetWorkKeys)+
 		len(cfg.MiningAddrs))
@@ -943,9 +1375,57 @@ func loadConfig() (*config, []string, error) {
 		cfg.miningAddrs = append(cfg.miningAddrs, addr)
 	}
 
+	// Check the mining address splits are valid and save parsed versions.
+	// The split addresses are also used as mining addresses, which marks the
+	// generated blocks as paying to an address, since they replace the
+	// payment address the blocks would otherwise pay to.
+	var totalPayoutWeight uint64
+	for _, split := range cfg.MiningAddrSplit {
+		var addr hcutil.Address
+		var weight uint64
+		i := strings.LastIndex(split, ":")
+		if i != -1 {
+			addr, err = hcutil.DecodeAddress(split[:i])
+			if err == nil {
+				weight, err = strconv.ParseUint(split[i+1:], 10, 32)
+			}
+		}
+		if i == -1 || err != nil || weight == 0 {
+			str := "%s: mining address split '%s' is not a valid " +
+				"address:weight pair with a positive weight"
+			err := fmt.Errorf(str, funcName, split)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+		if !addr.IsForNet(activeNetParams.Params) {
+			str := "%s: mining address split '%s' is on the wrong " +
+				"network"
+			err := fmt.Errorf(str, funcName, split)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+		totalPayoutWeight += weight
+		if totalPayoutWeight > maxMinerPayoutWeight {
+			str := "%s: the weights of the miningaddrsplit option " +
+				"may not add up to more than %d"
+			err := fmt.Errorf(str, funcName, maxMinerPayoutWeight)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+		cfg.miningAddrs = append(cfg.miningAddrs, addr)
+		cfg.miningPayouts = append(cfg.miningPayouts, minerPayout{
+			addr:   addr,
+			weight: uint32(weight),
+		})
+	}
+
 	// Ensure there is at least one mining address when the generate flag is
 	// set.
-	if cfg.Generate && len(cfg.MiningAddrs) == 0 {
+	if cfg.Generate && len(cfg.MiningAddrs) == 0 &&
+		len(cfg.MiningAddrSplit) == 0 {
 		str := "%s: the generate flag is set, but there are no mining " +
 			"addresses specified "
 		err := fmt.Errorf(str, funcName)
@@ -954,15 +1434,67 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// Ensure the number of CPU mining goroutines is sane.  Zero would
+	// never solve a block, so only negative values select the default.
+	if cfg.GenProcLimit == 0 {
+		str := "%s: the genproclimit option must not be 0 -- use a " +
+			"negative value for the default"
+		err := fmt.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
 	// Add default port to all listener addresses if needed and remove
 	// duplicate addresses.
 	cfg.Listeners = normalizeAddresses(cfg.Listeners,
 		activeNetParams.DefaultPort)
 
+	// Ensure the listener addresses are IP addresses, so invalid ones are
+	// reported on startup rather than skipped once the server starts.
+	if !cfg.DisableListen {
+		if _, _, _, err := parseListeners(cfg.Listeners); err != nil {
+			str := "%s: invalid listen address: %v"
+			err := fmt.Errorf(str, funcName, err)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+	}
+
 	// Add default port to all rpc listener addresses if needed and remove
 	// duplicate addresses.
 	cfg.RPCListeners = normalizeAddresses(cfg.RPCListeners,
 		activeNetParams.rpcPort)
+	cfg.RPCPublicListeners = normalizeAddresses(cfg.RPCPublicListeners,
+		activeNetParams.rpcPort)
+
+	// The public RPC listeners need both a certificate and a key when
+	// they don't share the ones of the other listeners.
+	if (cfg.RPCPublicCert == "") != (cfg.RPCPublicKey == "") {
+		str := "%s: the --rpcpubliccert and --rpcpublickey options " +
+			"must be used together"
+		err := fmt.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+	if cfg.RPCPublicCert != "" {
+		cfg.RPCPublicCert = cleanAndExpandPath(cfg.RPCPublicCert)
+		cfg.RPCPublicKey = cleanAndExpandPath(cfg.RPCPublicKey)
+	}
+
+	// Auto-generated RPC certificates must remain valid for longer than
+	// the rotation period, or they would be rotated continuously.
+	if cfg.RPCCertValidity <= cfg.RPCCertRotate {
+		str := "%s: the --rpccertvalidity option must be greater " +
+			"than the --rpccertrotate option -- parsed [%v, %v]"
+		err := fmt.Errorf(str, funcName, cfg.RPCCertValidity,
+			cfg.RPCCertRotate)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
 
 	// Only allow TLS to be disabled if the RPC is bound to localhost
 	// addresses.
@@ -972,7 +1504,11 @@ func loadConfig() (*config, []string, error) {
 			"127.0.0.1": {},
 			"::1":       {},
 		}
-		for _, addr := range cfg.RPCListeners {
+		rpcListeners := make([]string, 0, len(cfg.RPCListeners)+
+			len(cfg.RPCPublicListeners))
+		rpcListeners = append(rpcListeners, cfg.RPCListeners...)
+		rpcListeners = append(rpcListeners, cfg.RPCPublicListeners...)
+		for _, addr := range rpcListeners {
 			host, _, err := net.SplitHostPort(addr)
 			if err != nil {
 				str := "%s: RPC listen interface '%s' is " +
@@ -1049,6 +1585,29 @@ func loadConfig() (*config, []string, error) {
 		}
 	}
 
+	// Privacy mode never leaks DNS lookups to the system resolver.  Names
+	// are resolved through the proxy, or the onion proxy when only that is
+	// specified, even when --noonion is specified.  DNS seeding is skipped
+	// and lookups fail when there is no proxy to resolve names through.
+	if cfg.Privacy {
+		switch {
+		case cfg.Proxy != "":
+			cfg.lookup = func(host string) ([]net.IP, error) {
+				return connmgr.TorLookupIP(host, cfg.Proxy)
+			}
+		case cfg.OnionProxy != "":
+			cfg.lookup = func(host string) ([]net.IP, error) {
+				return connmgr.TorLookupIP(host, cfg.OnionProxy)
+			}
+		default:
+			cfg.DisableDNSSeed = true
+			cfg.lookup = func(host string) ([]net.IP, error) {
+				return nil, errors.New("DNS lookups require a " +
+					"proxy in privacy mode")
+			}
+		}
+	}
+
 	// Setup onion address dial and DNS resolution (lookup) functions
 	// depending on the specified options.  The default is to use the
 	// same dial and lookup functions selected above.  However, when an
@@ -1139,7 +1698,8 @@ func hcdDial(addr net.Addr) (net.Conn, error) {
 // otherwise treat the normal proxy as tor unless --noonion was specified in
 // which case the lookup will fail.  Meanwhile, normal IP addresses will be
 // resolved using tor if a proxy was specified unless --noonion was also
-// specified in which case the normal system DNS resolver will be used.
+// specified in which case the normal system DNS resolver will be used.  In
+// privacy mode, the system DNS resolver is never used.
 func hcdLookup(host string) ([]net.IP, error) {
 	if strings.HasSuffix(host, ".onion") {
 		return cfg.onionlookup(host)
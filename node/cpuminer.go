@@ -4,13 +4,15 @@
 // Use of this source code is governed by an ISC
 // license that can be found in the LICENSE file.
 
-package main
+package node
 
 import (
 	"errors"
 	"fmt"
+	"math/big"
 	"math/rand"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/HcashOrg/hcd/blockchain"
@@ -40,6 +42,11 @@ const (
 	// keep track of the hashes per second.
 	hashUpdateSecs = 15
 
+	// nonceCheckInterval is the number of nonces a nonce range worker
+	// hashes in between checking whether it should stop and publishing the
+	// number of hashes it has completed.
+	nonceCheckInterval = 1 << 16
+
 	// maxSimnetToMine is the maximum number of blocks to mine on HEAD~1
 	// for simnet so that you don't run out of memory if tickets for
 	// some reason run out during simulations.
@@ -136,7 +143,8 @@ func (m *CPUMiner) submitBlock(block *hcutil.Block) bool {
 
 	// Process this block using the same rules as blocks coming from other
 	// nodes. This will in turn relay it to the network like normal.
-	isOrphan, err := m.server.blockManager.ProcessBlock(block, blockchain.BFNone)
+	isOrphan, err := m.server.blockManager.ProcessBlock(block, blockchain.BFNone,
+		nil)
 	if err != nil {
 		// Anything other than a rule violation is an unexpected error,
 		// so log that error as an internal error.
@@ -179,22 +187,90 @@ func (m *CPUMiner) submitBlock(block *hcutil.Block) bool {
 	return true
 }
 
+// nonceRange returns the first and last nonce of the portion of the nonce space
+// the passed worker searches when it is split evenly between the given number
+// of workers.  The last worker also covers any remainder of the division.
+func nonceRange(worker, numWorkers uint32) (uint32, uint32) {
+	rangeSize := (uint64(maxNonce) + 1) / uint64(numWorkers)
+	first := uint64(worker) * rangeSize
+	last := first + rangeSize - 1
+	if worker == numWorkers-1 {
+		last = uint64(maxNonce)
+	}
+	return uint32(first), uint32(last)
+}
+
+// solveNonceRange hashes the passed header with every nonce from first to last
+// inclusive and sends the first nonce that makes the header hash to a value
+// less than the target difficulty to the solved channel.  The number of hashes
+// performed is periodically added to hashesCompleted so the caller can feed
+// the speed monitor.  It returns early when the stop channel is closed.
+//
+// It must be run as a goroutine.
+func solveNonceRange(header wire.BlockHeader, targetDifficulty *big.Int,
+	first, last uint32, hashesCompleted *uint64, solved chan<- uint32,
+	stop <-chan struct{}, wg *sync.WaitGroup) {
+
+	defer wg.Done()
+
+	var hashes uint64
+	for nonce := first; ; nonce++ {
+		if hashes == nonceCheckInterval {
+			atomic.AddUint64(hashesCompleted, hashes)
+			hashes = 0
+
+			select {
+			case <-stop:
+				return
+			default:
+				// Non-blocking select to fall through
+			}
+		}
+
+		// Update the nonce and hash the block header.
+		header.Nonce = nonce
+		hash := header.BlockHash()
+		hashes++
+
+		// The block is solved when the new block hash is less than the
+		// target difficulty.  Yay!
+		if blockchain.HashToBig(&hash).Cmp(targetDifficulty) <= 0 {
+			solved <- nonce
+			break
+		}
+
+		// Checked here rather than in the loop condition since the last
+		// nonce of the final range is the maximum value and incrementing
+		// it would wrap around to 0.
+		if nonce == last {
+			break
+		}
+	}
+	atomic.AddUint64(hashesCompleted, hashes)
+}
+
 // solveBlock attempts to find some combination of a nonce, extra nonce, and
 // current timestamp which makes the passed block hash to a value less than the
-// target difficulty.  The timestamp is updated periodically and the passed
-// block is modified with all tweaks during this process.  This means that
-// when the function returns true, the block is ready for submission.
+// target difficulty.  The nonce space is split evenly between the passed
+// number of worker goroutines for each extra nonce, and the extra nonce is only
+// rolled once every worker exhausted its range.  The timestamp is updated each
+// time the extra nonce is rolled and the passed block is modified with all
+// tweaks during this process.  This means that when the function returns true,
+// the block is ready for submission.
+//
+// The passed time offset shifts the timestamp away from the current time and
+// must be 0 unless blocks with specific timestamps are generated for testing.
 //
 // This function will return early with false when conditions that trigger a
 // stale block such as a new block showing up or periodically when there are
 // new transactions and enough time has elapsed without finding a solution.
-func (m *CPUMiner) solveBlock(msgBlock *wire.MsgBlock, ticker *time.Ticker,
-	quit chan struct{}) bool {
+func (m *CPUMiner) solveBlock(msgBlock *wire.MsgBlock, numWorkers uint32,
+	timeOffset time.Duration, ticker *time.Ticker, quit chan struct{}) bool {
 
 	blockHeight := int64(msgBlock.Header.Height)
 
-	// Choose a random extra nonce offset for this block template and
-	// worker.
+	// Choose a random extra nonce offset for this block template so
+	// concurrent templates paying to the same address do not repeat work.
 	enOffset, err := wire.RandomUint64()
 	if err != nil {
 		minrLog.Errorf("Unexpected error while generating random "+
@@ -202,26 +278,38 @@ func (m *CPUMiner) solveBlock(msgBlock *wire.MsgBlock, ticker *time.Ticker,
 		enOffset = 0
 	}
 
-	// Create a couple of convenience variables.
-	header := &msgBlock.Header
-	targetDifficulty := blockchain.CompactToBig(header.Bits)
-
 	// Initial state.
 	lastGenerated := time.Now()
 	lastTxUpdate := m.txSource.LastUpdated()
-	hashesCompleted := uint64(0)
+	var hashesCompleted uint64
+
+	// isStale returns whether the current block is stale, which is the case
+	// when the memory pool has been updated since the block template was
+	// generated and it has been at least 3 seconds, or if it's been one
+	// minute.
+	isStale := func() bool {
+		return (lastTxUpdate != m.txSource.LastUpdated() &&
+			time.Now().After(lastGenerated.Add(3*time.Second))) ||
+			time.Now().After(lastGenerated.Add(60*time.Second))
+	}
 
 	// Note that the entire extra nonce range is iterated and the offset is
 	// added relying on the fact that overflow will wrap around 0 as
 	// provided by the Go spec.
 	for extraNonce := uint64(0); extraNonce < maxExtraNonce; extraNonce++ {
-		// Get the old nonce values.
+		// The extra nonce can't be rolled for the first block since it
+		// has no extra nonce, so there is nothing left to try once its
+		// nonce space is exhausted.
+		if extraNonce > 0 && blockHeight == 1 {
+			break
+		}
+
+		// Update the extra nonce in the block template with the new
+		// value by regenerating the coinbase script and setting the
+		// merkle root to the new value.  Only the third extra nonce is
+		// rolled so the others keep the values of the template.
 		ens := getCoinbaseExtranonces(msgBlock)
 		ens[2] = extraNonce + enOffset
-
-		// Update the extra nonce in the block template with the
-		// new value by regenerating the coinbase script and
-		// setting the merkle root to the new value.  The
 		err := UpdateExtraNonce(msgBlock, blockHeight, ens)
 		if err != nil {
 			minrLog.Warnf("Unable to update CPU miner extranonce: %v",
@@ -229,50 +317,74 @@ func (m *CPUMiner) solveBlock(msgBlock *wire.MsgBlock, ticker *time.Ticker,
 			break
 		}
 
-		// Search through the entire nonce range for a solution while
-		// periodically checking for early quit and stale block
-		// conditions along with updates to the speed monitor.
-		for i := uint32(0); i <= maxNonce; i++ {
-			select {
-			case <-quit:
-				return false
+		err = updateBlockTime(msgBlock, m.server.blockManager, timeOffset)
+		if err != nil {
+			minrLog.Warnf("CPU miner unable to update block template "+
+				"time: %v", err)
+			return false
+		}
 
-			case <-ticker.C:
-				m.updateHashes <- hashesCompleted
-				hashesCompleted = 0
+		// Search through the entire nonce range for a solution by
+		// splitting it between the workers.  Each of them hashes its own
+		// copy of the header, so the block may not be modified until all
+		// of them returned.
+		targetDifficulty := blockchain.CompactToBig(msgBlock.Header.Bits)
+		solved := make(chan uint32, numWorkers)
+		stop := make(chan struct{})
+		var wg sync.WaitGroup
+		wg.Add(int(numWorkers))
+		for i := uint32(0); i < numWorkers; i++ {
+			first, last := nonceRange(i, numWorkers)
+			go solveNonceRange(msgBlock.Header, targetDifficulty, first,
+				last, &hashesCompleted, solved, stop, &wg)
+		}
+		exhausted := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(exhausted)
+		}()
+
+		// stopWorkers signals the workers to quit, waits for them to do
+		// so and notifies the speed monitor of the hashes they completed.
+		stopWorkers := func() {
+			close(stop)
+			<-exhausted
+			m.updateHashes <- atomic.SwapUint64(&hashesCompleted, 0)
+		}
 
-				// The current block is stale if the memory pool
-				// has been updated since the block template was
-				// generated and it has been at least 3 seconds,
-				// or if it's been one minute.
-				if (lastTxUpdate != m.txSource.LastUpdated() &&
-					time.Now().After(lastGenerated.Add(3*time.Second))) ||
-					time.Now().After(lastGenerated.Add(60*time.Second)) {
+		// Wait for a solution while periodically checking for early quit
+		// and stale block conditions along with updates to the speed
+		// monitor.
+	round:
+		for {
+			select {
+			case nonce := <-solved:
+				stopWorkers()
+				msgBlock.Header.Nonce = nonce
+				return true
 
-					return false
+			case <-exhausted:
+				// A worker might have found a solution right before
+				// the last of them finished.
+				select {
+				case nonce := <-solved:
+					msgBlock.Header.Nonce = nonce
+					m.updateHashes <- atomic.SwapUint64(&hashesCompleted, 0)
+					return true
+				default:
 				}
+				break round
 
-				err = UpdateBlockTime(msgBlock, m.server.blockManager)
-				if err != nil {
-					minrLog.Warnf("CPU miner unable to update block template "+
-						"time: %v", err)
+			case <-quit:
+				stopWorkers()
+				return false
+
+			case <-ticker.C:
+				m.updateHashes <- atomic.SwapUint64(&hashesCompleted, 0)
+				if isStale() {
+					stopWorkers()
 					return false
 				}
-
-			default:
-				// Non-blocking select to fall through
-			}
-
-			// Update the nonce and hash the block header.
-			header.Nonce = i
-			hash := header.BlockHash()
-			hashesCompleted++
-
-			// The block is solved when the new block hash is less
-			// than the target difficulty.  Yay!
-			if blockchain.HashToBig(&hash).Cmp(targetDifficulty) <= 0 {
-				m.updateHashes <- hashesCompleted
-				return true
 			}
 		}
 	}
@@ -280,14 +392,14 @@ func (m *CPUMiner) solveBlock(msgBlock *wire.MsgBlock, ticker *time.Ticker,
 	return false
 }
 
-// generateBlocks is a worker that is controlled by the miningWorkerController.
-// It is self contained in that it creates block templates and attempts to solve
-// them while detecting when it is performing stale work and reacting
-// accordingly by generating a new block template.  When a block is solved, it
-// is submitted.
+// generateBlocks is controlled by the miningWorkerController.  It is self
+// contained in that it creates block templates and attempts to solve them by
+// splitting the nonce space between the passed number of workers while
+// detecting when it is performing stale work and reacting accordingly by
+// generating a new block template.  When a block is solved, it is submitted.
 //
 // It must be run as a goroutine.
-func (m *CPUMiner) generateBlocks(quit chan struct{}) {
+func (m *CPUMiner) generateBlocks(numWorkers uint32, quit chan struct{}) {
 	minrLog.Tracef("Starting generate blocks worker")
 
 	// Start a ticker which is used to signal checks for stale work and
@@ -363,7 +475,7 @@ out:
 		// a new block template can be generated.  When the return is
 		// true a solution was found, so submit the solved block.
 		minrLog.Tracef("start to solve block")
-		if m.solveBlock(template.Block, ticker, quit) {
+		if m.solveBlock(template.Block, numWorkers, 0, ticker, quit) {
 			minrLog.Tracef("solve block success")
 			block := hcutil.NewBlock(template.Block)
 			m.submitBlock(block)
@@ -377,57 +489,46 @@ out:
 	minrLog.Tracef("Generate blocks worker done")
 }
 
-// miningWorkerController launches the worker goroutines that are used to
-// generate block templates and solve them.  It also provides the ability to
-// dynamically adjust the number of running worker goroutines.
+// miningWorkerController launches the goroutine that is used to generate block
+// templates and solve them with the configured number of workers.  It also
+// provides the ability to dynamically adjust the number of workers by
+// relaunching it.
 //
 // It must be run as a goroutine.
 func (m *CPUMiner) miningWorkerController() {
-	// launchWorkers groups common code to launch a specified number of
-	// workers for generating blocks.
-	var runningWorkers []chan struct{}
-	launchWorkers := func(numWorkers uint32) {
-		for i := uint32(0); i < numWorkers; i++ {
-			quit := make(chan struct{})
-			runningWorkers = append(runningWorkers, quit)
-
-			m.workerWg.Add(1)
-			go m.generateBlocks(quit)
-		}
+	// launchGenerator groups common code to launch the block generator
+	// with the current number of workers.
+	var generatorQuit chan struct{}
+	var numRunning uint32
+	launchGenerator := func() {
+		generatorQuit = make(chan struct{})
+		numRunning = m.numWorkers
+
+		m.workerWg.Add(1)
+		go m.generateBlocks(numRunning, generatorQuit)
 	}
 
-	// Launch the current number of workers by default.
-	runningWorkers = make([]chan struct{}, 0, m.numWorkers)
-	launchWorkers(m.numWorkers)
+	// Launch the generator with the current number of workers by default.
+	launchGenerator()
 
 out:
 	for {
 		select {
-		// Update the number of running workers.
+		// Update the number of running workers.  The nonce space of the
+		// block being solved is already split between the previous
+		// number of workers, so the generator is restarted.
 		case <-m.updateNumWorkers:
 			// No change.
-			numRunning := uint32(len(runningWorkers))
 			if m.numWorkers == numRunning {
 				continue
 			}
 
-			// Add new workers.
-			if m.numWorkers > numRunning {
-				launchWorkers(m.numWorkers - numRunning)
-				continue
-			}
-
-			// Signal the most recently created goroutines to exit.
-			for i := numRunning - 1; i >= m.numWorkers; i-- {
-				close(runningWorkers[i])
-				runningWorkers[i] = nil
-				runningWorkers = runningWorkers[:i]
-			}
+			close(generatorQuit)
+			m.workerWg.Wait()
+			launchGenerator()
 
 		case <-m.quit:
-			for _, quit := range runningWorkers {
-				close(quit)
-			}
+			close(generatorQuit)
 			break out
 		}
 	}
@@ -511,10 +612,21 @@ func (m *CPUMiner) HashesPerSecond() float64 {
 	return <-m.queryHashesPerSec
 }
 
+// configuredNumWorkers returns the default number of workers to use for mining,
+// which is the one specified with --genproclimit, or the number of processor
+// cores when it is negative.
+func configuredNumWorkers() uint32 {
+	if cfg.GenProcLimit > 0 {
+		return uint32(cfg.GenProcLimit)
+	}
+	return defaultNumWorkers
+}
+
 // SetNumWorkers sets the number of workers to create which solve blocks.  Any
-// negative values will cause a default number of workers to be used which is
-// based on the number of processor cores in the system.  A value of 0 will
-// cause all CPU mining to be stopped.
+// negative values will cause the default number of workers to be used which is
+// either the one configured with --genproclimit or based on the number of
+// processor cores in the system.  A value of 0 will cause all CPU mining to be
+// stopped.
 //
 // This function is safe for concurrent access.
 func (m *CPUMiner) SetNumWorkers(numWorkers int32) {
@@ -529,7 +641,7 @@ func (m *CPUMiner) SetNumWorkers(numWorkers int32) {
 
 	// Use default if provided value is negative.
 	if numWorkers < 0 {
-		m.numWorkers = defaultNumWorkers
+		m.numWorkers = configuredNumWorkers()
 	} else {
 		m.numWorkers = uint32(numWorkers)
 	}
@@ -555,8 +667,12 @@ func (m *CPUMiner) NumWorkers() int32 {
 // contained in that it creates block templates and attempts to solve them while
 // detecting when it is performing stale work and reacting accordingly by
 // generating a new block template.  When a block is solved, it is submitted.
+// Each block includes at most maxVotes votes and its timestamp is shifted by
+// timeOffset from the current time, which allows tests to generate blocks with
+// missed votes or specific timestamps.
 // The function returns a list of the hashes of generated blocks.
-func (m *CPUMiner) GenerateNBlocks(n uint32) ([]*chainhash.Hash, error) {
+func (m *CPUMiner) GenerateNBlocks(n uint32, maxVotes uint16,
+	timeOffset time.Duration) ([]*chainhash.Hash, error) {
 	m.Lock()
 
 	// Respond with an error if there's virtually 0 chance of CPU-mining a block.
@@ -595,12 +711,15 @@ func (m *CPUMiner) GenerateNBlocks(n uint32) ([]*chainhash.Hash, error) {
 
 	for {
 		// Read updateNumWorkers in case someone tries a `setgenerate` while
-		// we're generating. We can ignore it as the `generate` RPC call only
-		// uses 1 worker.
+		// we're generating.  The new number of workers is picked up below
+		// for the next block template.
 		select {
 		case <-m.updateNumWorkers:
 		default:
 		}
+		m.Lock()
+		numWorkers := m.numWorkers
+		m.Unlock()
 
 		// Grab the lock used for block submission, since the current block will
 		// be changing and this would otherwise end up building a new block
@@ -614,7 +733,8 @@ func (m *CPUMiner) GenerateNBlocks(n uint32) ([]*chainhash.Hash, error) {
 		// Create a new block template using the available transactions
 		// in the memory pool as a source of transactions to potentially
 		// include in the block.
-		template, err := NewBlockTemplate(m.policy, m.server, payToAddr)
+		template, err := newBlockTemplate(m.policy, m.server, payToAddr,
+			maxVotes)
 		m.submitBlockLock.Unlock()
 		if err != nil {
 			errStr := fmt.Sprintf("Failed to create new block "+
@@ -633,7 +753,8 @@ func (m *CPUMiner) GenerateNBlocks(n uint32) ([]*chainhash.Hash, error) {
 		// with false when conditions that trigger a stale block, so
 		// a new block template can be generated.  When the return is
 		// true a solution was found, so submit the solved block.
-		if m.solveBlock(template.Block, ticker, nil) {
+		if m.solveBlock(template.Block, numWorkers, timeOffset, ticker,
+			nil) {
 			block := hcutil.NewBlock(template.Block)
 			m.submitBlock(block)
 			blockHashes[i] = block.Hash()
@@ -660,7 +781,7 @@ func newCPUMiner(policy *mining.Policy, s *server) *CPUMiner {
 		policy:            policy,
 		txSource:          s.txMemPool,
 		server:            s,
-		numWorkers:        defaultNumWorkers,
+		numWorkers:        configuredNumWorkers(),
 		updateNumWorkers:  make(chan struct{}),
 		queryHashesPerSec: make(chan float64),
 		updateHashes:      make(chan uint64),
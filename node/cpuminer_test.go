@@ -0,0 +1,32 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package node
+
+import "testing"
+
+// TestNonceRange ensures splitting the nonce space between workers hands out
+// contiguous ranges which cover every nonce exactly once.
+func TestNonceRange(t *testing.T) {
+	tests := []uint32{1, 2, 3, 4, 7, 16, 1000}
+	for _, numWorkers := range tests {
+		var next uint64
+		for i := uint32(0); i < numWorkers; i++ {
+			first, last := nonceRange(i, numWorkers)
+			if uint64(first) != next {
+				t.Fatalf("nonceRange(%d, %d): first nonce %d, want %d",
+					i, numWorkers, first, next)
+			}
+			if last < first {
+				t.Fatalf("nonceRange(%d, %d): last nonce %d is before "+
+					"first nonce %d", i, numWorkers, last, first)
+			}
+			next = uint64(last) + 1
+		}
+		if next != uint64(maxNonce)+1 {
+			t.Fatalf("nonceRange with %d workers ends at %d, want %d",
+				numWorkers, next-1, maxNonce)
+		}
+	}
+}
@@ -0,0 +1,353 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package node
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/HcashOrg/hcd/blockchain"
+	"github.com/HcashOrg/hcd/chaincfg/chainhash"
+	"github.com/HcashOrg/hcd/database"
+	"github.com/HcashOrg/hcd/hcutil"
+	"github.com/HcashOrg/hcd/wire"
+)
+
+const (
+	// dbVerifyBatchSize is the number of stored blocks verified per
+	// database transaction.
+	dbVerifyBatchSize = 500
+
+	// dbVerifyBatchDelay is the time to wait between batches of verified
+	// blocks, so a verification pass does not compete with the block
+	// manager for the database.
+	dbVerifyBatchDelay = 100 * time.Millisecond
+
+	// dbRepairInterval is the interval at which copies of the quarantined
+	// blocks are requested from peers again until they are repaired.
+	dbRepairInterval = time.Minute
+
+	// maxDBRepairPeers is the maximum number of peers a copy of a
+	// quarantined block is requested from at a time.
+	maxDBRepairPeers = 3
+
+	// dbRepairConfirmations is the number of distinct peers which must
+	// send identical copies of a quarantined block before it is repaired
+	// with them.  The merkle roots in the block header do not commit to the
+	// signature scripts of the transactions, so a single peer could
+	// otherwise replace them.
+	dbRepairConfirmations = 2
+)
+
+// quarantinedBlock describes a stored main chain block which failed to verify,
+// along with the copies received from peers to repair it.
+type quarantinedBlock struct {
+	height int64
+	reason string
+	since  time.Time
+
+	// copies maps the hash of the serialized copies received from peers to
+	// the addresses of the peers which sent them.
+	copies map[chainhash.Hash]map[string]struct{}
+}
+
+// dbVerifier periodically verifies the checksums, hashes and merkle roots of
+// the stored main chain blocks.  The blocks which fail to verify are
+// quarantined, so they are no longer served to peers, and repaired with
+// identical copies received from multiple peers.  It is safe for concurrent
+// access.
+type dbVerifier struct {
+	server   *server
+	interval time.Duration
+
+	mtx         sync.Mutex
+	quarantined map[chainhash.Hash]*quarantinedBlock
+	lastPass    time.Time
+}
+
+// newDBVerifier returns a verifier of the blocks stored by the passed server
+// which starts a verification pass at the passed interval.
+func newDBVerifier(s *server, interval time.Duration) *dbVerifier {
+	return &dbVerifier{
+		server:      s,
+		interval:    interval,
+		quarantined: make(map[chainhash.Hash]*quarantinedBlock),
+	}
+}
+
+// checkMerkleRoots ensures the merkle roots of the regular and stake
+// transaction trees of the passed block match the ones in its header.
+func checkMerkleRoots(block *hcutil.Block) error {
+	header := &block.MsgBlock().Header
+	merkles := blockchain.BuildMerkleTreeStore(block.Transactions())
+	if root := merkles[len(merkles)-1]; !header.MerkleRoot.IsEqual(root) {
+		return fmt.Errorf("merkle root %v does not match the header "+
+			"merkle root %v", root, header.MerkleRoot)
+	}
+	merkles = blockchain.BuildMerkleTreeStore(block.STransactions())
+	if root := merkles[len(merkles)-1]; !header.StakeRoot.IsEqual(root) {
+		return fmt.Errorf("stake merkle root %v does not match the "+
+			"header stake root %v", root, header.StakeRoot)
+	}
+	return nil
+}
+
+// verifyStoredBlock ensures the stored block with the passed hash can be read,
+// which verifies the checksum of its data in the block files, and that it
+// matches its hash and merkle roots.
+func verifyStoredBlock(dbTx database.Tx, hash *chainhash.Hash) error {
+	blockBytes, err := dbTx.FetchBlock(hash)
+	if err != nil {
+		return err
+	}
+	block, err := hcutil.NewBlockFromBytes(blockBytes)
+	if err != nil {
+		return err
+	}
+	if !block.Hash().IsEqual(hash) {
+		return fmt.Errorf("stored block has hash %v", block.Hash())
+	}
+	return checkMerkleRoots(block)
+}
+
+// isQuarantined returns whether the block with the passed hash failed to
+// verify and was not repaired yet.
+func (v *dbVerifier) isQuarantined(hash *chainhash.Hash) bool {
+	v.mtx.Lock()
+	_, ok := v.quarantined[*hash]
+	v.mtx.Unlock()
+	return ok
+}
+
+// quarantine records that the stored block with the passed hash and height
+// failed to verify for the passed reason.
+func (v *dbVerifier) quarantine(hash *chainhash.Hash, height int64, reason error) {
+	v.mtx.Lock()
+	defer v.mtx.Unlock()
+	if _, ok := v.quarantined[*hash]; ok {
+		return
+	}
+	v.quarantined[*hash] = &quarantinedBlock{
+		height: height,
+		reason: reason.Error(),
+		since:  time.Now(),
+		copies: make(map[chainhash.Hash]map[string]struct{}),
+	}
+	srvrLog.Errorf("Quarantined stored block %v (height %d) which failed "+
+		"to verify: %v -- requesting copies from peers to repair it",
+		hash, height, reason)
+}
+
+// quarantinedBlockInfo describes a quarantined block for the gethealth RPC.
+type quarantinedBlockInfo struct {
+	hash   chainhash.Hash
+	height int64
+	reason string
+	since  time.Time
+}
+
+// status returns the time the last verification pass completed, which is zero
+// before the first one, and the quarantined blocks ordered by height.
+func (v *dbVerifier) status() (time.Time, []quarantinedBlockInfo) {
+	v.mtx.Lock()
+	defer v.mtx.Unlock()
+	blocks := make([]quarantinedBlockInfo, 0, len(v.quarantined))
+	for hash, qb := range v.quarantined {
+		blocks = append(blocks, quarantinedBlockInfo{
+			hash:   hash,
+			height: qb.height,
+			reason: qb.reason,
+			since:  qb.since,
+		})
+	}
+	sort.Slice(blocks, func(i, j int) bool {
+		return blocks[i].height < blocks[j].height
+	})
+	return v.lastPass, blocks
+}
+
+// verifyPass verifies all stored main chain blocks up to the current best
+// block and quarantines the ones which fail to verify.  It returns false when
+// the passed channel is closed before it is done.
+func (v *dbVerifier) verifyPass(quit <-chan struct{}) bool {
+	chain := v.server.blockManager.chain
+	bestHeight := chain.BestSnapshot().Height
+	srvrLog.Infof("Verifying the stored blocks up to height %d",
+		bestHeight)
+
+	var failed int
+	for start := int64(1); start <= bestHeight; start += dbVerifyBatchSize {
+		select {
+		case <-quit:
+			return false
+		case <-time.After(dbVerifyBatchDelay):
+		}
+
+		hashes, err := chain.HeightRange(start, start+dbVerifyBatchSize)
+		if err != nil {
+			srvrLog.Errorf("Unable to verify the stored blocks: %v",
+				err)
+			return true
+		}
+		errs := make([]error, len(hashes))
+		err = v.server.db.View(func(dbTx database.Tx) error {
+			for i := range hashes {
+				errs[i] = verifyStoredBlock(dbTx, &hashes[i])
+			}
+			return nil
+		})
+		if err != nil {
+			srvrLog.Errorf("Unable to verify the stored blocks: %v",
+				err)
+			return true
+		}
+		for i, err := range errs {
+			if err != nil {
+				failed++
+				v.quarantine(&hashes[i], start+int64(i), err)
+			}
+		}
+	}
+
+	v.mtx.Lock()
+	v.lastPass = time.Now()
+	v.mtx.Unlock()
+	srvrLog.Infof("Verified the stored blocks up to height %d (%d failed)",
+		bestHeight, failed)
+	return true
+}
+
+// requestRepairs requests copies of the quarantined blocks from the peers
+// which are known to have them.
+func (v *dbVerifier) requestRepairs(quit <-chan struct{}) {
+	v.mtx.Lock()
+	heights := make(map[chainhash.Hash]int64, len(v.quarantined))
+	for hash, qb := range v.quarantined {
+		heights[hash] = qb.height
+	}
+	v.mtx.Unlock()
+	if len(heights) == 0 {
+		return
+	}
+
+	// Query the peers directly instead of using Peers, since the peer
+	// handler no longer answers queries once it stopped.
+	replyChan := make(chan []*serverPeer)
+	select {
+	case v.server.query <- getPeersMsg{reply: replyChan}:
+	case <-quit:
+		return
+	}
+
+	var requested int
+	for _, sp := range <-replyChan {
+		if requested == maxDBRepairPeers {
+			break
+		}
+		if !sp.Connected() || sp.Services()&wire.SFNodeNetwork == 0 {
+			continue
+		}
+		gdmsg := wire.NewMsgGetData()
+		for hash, height := range heights {
+			if sp.LastBlock() < height {
+				continue
+			}
+			hash := hash
+			gdmsg.AddInvVect(wire.NewInvVect(wire.InvTypeBlock, &hash))
+		}
+		if len(gdmsg.InvList) == 0 {
+			continue
+		}
+		sp.QueueMessage(gdmsg, nil)
+		requested++
+	}
+}
+
+// maybeRepair repairs the quarantined block the passed block is a copy of
+// once enough peers sent identical copies of it.  It returns whether the block
+// is quarantined, in which case it must not be processed any further.
+func (v *dbVerifier) maybeRepair(block *hcutil.Block, source string) bool {
+	hash := block.Hash()
+	if !v.isQuarantined(hash) {
+		return false
+	}
+	if err := checkMerkleRoots(block); err != nil {
+		srvrLog.Warnf("Ignoring copy of quarantined block %v from %s: %v",
+			hash, source, err)
+		return true
+	}
+	blockBytes, err := block.Bytes()
+	if err != nil {
+		srvrLog.Warnf("Ignoring copy of quarantined block %v from %s: %v",
+			hash, source, err)
+		return true
+	}
+
+	v.mtx.Lock()
+	qb, ok := v.quarantined[*hash]
+	if !ok {
+		v.mtx.Unlock()
+		return true
+	}
+	copyHash := chainhash.HashH(blockBytes)
+	peers := qb.copies[copyHash]
+	if peers == nil {
+		peers = make(map[string]struct{})
+		qb.copies[copyHash] = peers
+	}
+	peers[source] = struct{}{}
+	confirmed := len(peers) >= dbRepairConfirmations
+	v.mtx.Unlock()
+	if !confirmed {
+		return true
+	}
+
+	err = v.server.db.Update(func(dbTx database.Tx) error {
+		return dbTx.RepairBlock(block)
+	})
+	if err != nil {
+		srvrLog.Errorf("Unable to repair quarantined block %v: %v", hash,
+			err)
+		return true
+	}
+
+	v.mtx.Lock()
+	delete(v.quarantined, *hash)
+	v.mtx.Unlock()
+	srvrLog.Infof("Repaired quarantined block %v (height %d) with the "+
+		"copies from %d peers", hash, qb.height, len(peers))
+	return true
+}
+
+// run verifies the stored blocks at the configured interval and requests
+// copies of the quarantined blocks until they are repaired.  It must be run as
+// a goroutine.
+func (v *dbVerifier) run(quit <-chan struct{}) {
+	defer v.server.wg.Done()
+
+	verifyTimer := time.NewTimer(v.interval)
+	defer verifyTimer.Stop()
+	repairTicker := time.NewTicker(dbRepairInterval)
+	defer repairTicker.Stop()
+
+	for {
+		select {
+		case <-verifyTimer.C:
+			if !v.verifyPass(quit) {
+				return
+			}
+			v.requestRepairs(quit)
+			verifyTimer.Reset(v.interval)
+
+		case <-repairTicker.C:
+			v.requestRepairs(quit)
+
+		case <-quit:
+			return
+		}
+	}
+}
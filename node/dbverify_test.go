@@ -0,0 +1,113 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package node
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/HcashOrg/hcd/blockchain"
+	"github.com/HcashOrg/hcd/database"
+	_ "github.com/HcashOrg/hcd/database/ffldb"
+	"github.com/HcashOrg/hcd/hcutil"
+	"github.com/HcashOrg/hcd/wire"
+	"github.com/btcsuite/btclog"
+)
+
+// testDBVerifyBlock returns a block with a single transaction and matching
+// merkle roots.
+func testDBVerifyBlock() *hcutil.Block {
+	tx := wire.NewMsgTx()
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&zeroHash, 0, 0),
+		[]byte{0x51}))
+	tx.AddTxOut(wire.NewTxOut(100, []byte{0x51}))
+	msgBlock := &wire.MsgBlock{
+		Header:       wire.BlockHeader{Version: 1, Height: 1},
+		Transactions: []*wire.MsgTx{tx},
+	}
+	block := hcutil.NewBlock(msgBlock)
+	merkles := blockchain.BuildMerkleTreeStore(block.Transactions())
+	msgBlock.Header.MerkleRoot = *merkles[len(merkles)-1]
+	merkles = blockchain.BuildMerkleTreeStore(block.STransactions())
+	msgBlock.Header.StakeRoot = *merkles[len(merkles)-1]
+	return hcutil.NewBlock(msgBlock)
+}
+
+// TestDBVerifierRepair ensures stored blocks are verified and quarantined
+// blocks are only repaired once enough peers sent identical copies with
+// matching merkle roots.
+func TestDBVerifierRepair(t *testing.T) {
+	oldLog := srvrLog
+	defer func() { srvrLog = oldLog }()
+	srvrLog = btclog.Disabled
+
+	db, err := database.Create("ffldb", t.TempDir(), wire.SimNet)
+	if err != nil {
+		t.Fatalf("unable to create database: %v", err)
+	}
+	defer db.Close()
+
+	block := testDBVerifyBlock()
+	err = db.Update(func(dbTx database.Tx) error {
+		return dbTx.StoreBlock(block)
+	})
+	if err != nil {
+		t.Fatalf("unable to store block: %v", err)
+	}
+	err = db.View(func(dbTx database.Tx) error {
+		return verifyStoredBlock(dbTx, block.Hash())
+	})
+	if err != nil {
+		t.Fatalf("unexpected error verifying intact block: %v", err)
+	}
+
+	// Blocks which are not quarantined are processed as usual.
+	v := newDBVerifier(&server{db: db}, time.Hour)
+	if v.maybeRepair(block, "peer1") {
+		t.Fatal("block handled as a repair copy without quarantine")
+	}
+
+	v.quarantine(block.Hash(), 1, errors.New("checksum mismatch"))
+	if !v.isQuarantined(block.Hash()) {
+		t.Fatal("block not quarantined")
+	}
+
+	// A copy which does not match the merkle roots is ignored.
+	badMsgBlock := *block.MsgBlock()
+	badTx := badMsgBlock.Transactions[0].Copy()
+	badTx.TxOut[0].Value++
+	badMsgBlock.Transactions = []*wire.MsgTx{badTx}
+	badBlock := hcutil.NewBlock(&badMsgBlock)
+	if err := checkMerkleRoots(badBlock); err == nil {
+		t.Fatal("mismatched merkle root not detected")
+	}
+	for _, source := range []string{"peer1", "peer2"} {
+		if !v.maybeRepair(badBlock, source) {
+			t.Fatal("copy of quarantined block not handled")
+		}
+	}
+	if !v.isQuarantined(block.Hash()) {
+		t.Fatal("block repaired with copies not matching the merkle roots")
+	}
+
+	// The block is repaired once enough distinct peers sent identical
+	// copies.
+	for i, source := range []string{"peer1", "peer1", "peer2"} {
+		if !v.maybeRepair(block, source) {
+			t.Fatal("copy of quarantined block not handled")
+		}
+		wantQuarantined := i < 2
+		if v.isQuarantined(block.Hash()) != wantQuarantined {
+			t.Fatalf("copy %d: got quarantined %v, want %v", i,
+				!wantQuarantined, wantQuarantined)
+		}
+	}
+	_, quarantined := v.status()
+	if len(quarantined) != 0 {
+		t.Fatalf("got %d quarantined blocks, want none",
+			len(quarantined))
+	}
+}
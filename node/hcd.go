@@ -0,0 +1,303 @@
+// Copyright (c) 2013-2016 The btcsuite developers
+// Copyright (c) 2015-2017 The Decred developers
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package node
+
+import (
+	"fmt"
+	"net/http"
+	_ "net/http/pprof"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"runtime/pprof"
+	"time"
+
+	"github.com/HcashOrg/hcd/blockchain"
+	"github.com/HcashOrg/hcd/blockchain/indexers"
+	"github.com/HcashOrg/hcd/limits"
+	"github.com/HcashOrg/hcd/lockprof"
+)
+
+var cfg *Config
+
+// winServiceMain is only invoked on Windows.  It detects when hcd is running
+// as a service and reacts accordingly.
+var winServiceMain func() (bool, error)
+
+// hcdMain is the real main function for hcd.  It is necessary to work around
+// the fact that deferred functions do not run when os.Exit() is called.  The
+// optional serverChan parameter is mainly used by the service code to be
+// notified with the server once it is setup so it can gracefully stop it when
+// requested from the service control manager.
+func hcdMain(serverChan chan<- *server) error {
+	// Load configuration and parse command line.  This function also
+	// initializes logging and configures it accordingly.
+	tcfg, _, err := LoadConfig(os.Args[1:])
+	if err != nil {
+		return err
+	}
+	cfg = tcfg
+	defer func() {
+		if logRotator != nil {
+			logRotator.Close()
+		}
+	}()
+
+	// Get a channel that will be closed when a shutdown signal has been
+	// triggered either from an OS signal such as SIGINT (Ctrl+C) or from
+	// another subsystem such as the RPC server.
+	ctx := shutdownListener()
+	chainInterrupt = ctx.Done()
+	defer hcdLog.Info("Shutdown complete")
+
+	// Show version and home dir at startup.
+	hcdLog.Infof("Version %s (Go version %s)", version(), runtime.Version())
+	hcdLog.Infof("Home dir: %s", cfg.HomeDir)
+
+	// Enable http profiling server if requested.
+	if cfg.Profile != "" {
+		go func() {
+			listenAddr := cfg.Profile
+			hcdLog.Infof("Creating profiling server "+
+				"listening on %s", listenAddr)
+			profileRedirect := http.RedirectHandler("/debug/pprof",
+				http.StatusSeeOther)
+			http.Handle("/", profileRedirect)
+			err := http.ListenAndServe(listenAddr, nil)
+			if err != nil {
+				fatalf(err.Error())
+			}
+		}()
+	}
+
+	// Write cpu profile if requested.
+	if cfg.CPUProfile != "" {
+		f, err := os.Create(cfg.CPUProfile)
+		if err != nil {
+			hcdLog.Errorf("Unable to create cpu profile: %v", err.Error())
+			return err
+		}
+		pprof.StartCPUProfile(f)
+		defer f.Close()
+		defer pprof.StopCPUProfile()
+	}
+
+	// Write mem profile if requested.
+	if cfg.MemProfile != "" {
+		f, err := os.Create(cfg.MemProfile)
+		if err != nil {
+			hcdLog.Errorf("Unable to create memory profile: %v", err)
+			return err
+		}
+		timer := time.NewTimer(time.Minute * 20) // 20 minutes
+		go func() {
+			<-timer.C
+			pprof.WriteHeapProfile(f)
+			f.Close()
+		}()
+	}
+
+	// Profile the contention of the mempool and chain locks if requested.
+	if cfg.LockHoldThreshold > 0 {
+		hcdLog.Infof("Profiling lock contention with a hold threshold "+
+			"of %v", cfg.LockHoldThreshold)
+		lockprof.Enable(cfg.LockHoldThreshold)
+		defer lockprof.Disable()
+	}
+
+	var lifetimeNotifier lifetimeEventServer
+	if cfg.LifetimeEvents {
+		lifetimeNotifier = newLifetimeEventServer(outgoingPipeMessages)
+	}
+
+	if cfg.PipeRx != 0 {
+		go serviceControlPipeRx(uintptr(cfg.PipeRx))
+	}
+	if cfg.PipeTx != 0 {
+		go serviceControlPipeTx(uintptr(cfg.PipeTx))
+	} else {
+		go drainOutgoingPipeMessages()
+	}
+
+	// Return now if an interrupt signal was triggered.
+	if interruptRequested(ctx) {
+		return nil
+	}
+
+	// Load the block database.
+	lifetimeNotifier.notifyStartupEvent(lifetimeEventDBOpen)
+	db, err := loadBlockDB()
+	if err != nil {
+		hcdLog.Errorf("%v", err)
+		return err
+	}
+	defer func() {
+		// Ensure the database is sync'd and closed on shutdown.
+		lifetimeNotifier.notifyShutdownEvent(lifetimeEventDBOpen)
+		hcdLog.Infof("Gracefully shutting down the database...")
+		db.Close()
+	}()
+
+	// Return now if an interrupt signal was triggered.
+	if interruptRequested(ctx) {
+		return nil
+	}
+
+	// Drop indexes and exit if requested.
+	//
+	// NOTE: The order is important here because dropping the tx index also
+	// drops the address index since it relies on it.
+	if cfg.DropAddrIndex {
+		if err := indexers.DropAddrIndex(db); err != nil {
+			hcdLog.Errorf("%v", err)
+			return err
+		}
+
+		return nil
+	}
+	if cfg.DropTxIndex {
+		if err := indexers.DropTxIndex(db); err != nil {
+			hcdLog.Errorf("%v", err)
+			return err
+		}
+
+		return nil
+	}
+	if cfg.DropExistsAddrIndex {
+		if err := indexers.DropExistsAddrIndex(db); err != nil {
+			hcdLog.Errorf("%v", err)
+			return err
+		}
+
+		return nil
+	}
+
+	// Drop the optional indexes which should be rebuilt.  They are created
+	// again when the node is started and caught up in the background once
+	// the chain is synced.
+	//
+	// NOTE: Rebuilding the chain state also drops all of the indexes since
+	// they are built on top of it.  The enabled ones are rebuilt along with
+	// it.
+	reindexIndexes := cfg.ReindexIndexes
+	if cfg.ReindexChainState {
+		reindexIndexes = []string{"txindex", "existsaddrindex"}
+	}
+	for _, name := range reindexIndexes {
+		var err error
+		switch name {
+		case "txindex":
+			err = indexers.DropTxIndex(db)
+		case "addrindex":
+			err = indexers.DropAddrIndex(db)
+		case "existsaddrindex":
+			err = indexers.DropExistsAddrIndex(db)
+		}
+		if err != nil {
+			hcdLog.Errorf("%v", err)
+			return err
+		}
+	}
+	if cfg.ReindexChainState {
+		numBlocks, err := blockchain.ResetChainState(db)
+		if err != nil {
+			hcdLog.Errorf("Unable to reset the chain state: %v", err)
+			return err
+		}
+		hcdLog.Infof("Rebuilding the chain state from %d stored blocks",
+			numBlocks)
+	}
+
+	// Create the node and start it.  The database is closed by the deferred
+	// function above rather than by stopping the node so the shutdown
+	// events are reported in order.
+	lifetimeNotifier.notifyStartupEvent(lifetimeEventP2PServer)
+	node, err := newNode(db)
+	if err == blockchain.ErrReindexInterrupted {
+		hcdLog.Infof("The chain state rebuild was interrupted and will " +
+			"resume on the next start")
+		return nil
+	}
+	if err != nil {
+		// TODO(oga) this logging could do with some beautifying.
+		hcdLog.Errorf("Unable to start server on %v: %v",
+			cfg.Listeners, err)
+		return err
+	}
+	defer func() {
+		lifetimeNotifier.notifyShutdownEvent(lifetimeEventP2PServer)
+		hcdLog.Infof("Gracefully shutting down the server...")
+		if err := node.stopServer(); err != nil {
+			// The database can't be closed safely while the server
+			// might still be using it, so exit right away.
+			hcdLog.Errorf("%v -- exiting without closing the "+
+				"database", err)
+			if logRotator != nil {
+				logRotator.Close()
+			}
+			os.Exit(1)
+		}
+		srvrLog.Infof("Server shutdown complete")
+	}()
+
+	node.Start()
+	if serverChan != nil {
+		serverChan <- node.server
+	}
+	reloadListener(ctx, node.server)
+
+	if interruptRequested(ctx) {
+		return nil
+	}
+
+	lifetimeNotifier.notifyStartupComplete()
+
+	// Wait until the interrupt signal is received from an OS signal or
+	// shutdown is requested through one of the subsystems such as the RPC
+	// server.
+	<-ctx.Done()
+	return nil
+}
+
+// Main runs hcd with the configuration given on the command line until it is
+// shut down, and exits the process when it fails.  It is the entry point of the
+// hcd command.
+func Main() {
+	// Use all processor cores.
+	runtime.GOMAXPROCS(runtime.NumCPU())
+
+	// Block and transaction processing can cause bursty allocations.  This
+	// limits the garbage collector from excessively overallocating during
+	// bursts.  This value was arrived at with the help of profiling live
+	// usage.
+	debug.SetGCPercent(20)
+
+	// Up some limits.
+	if err := limits.SetLimits(); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to set limits: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Call serviceMain on Windows to handle running as a service.  When
+	// the return isService flag is true, exit now since we ran as a
+	// service.  Otherwise, just fall through to normal operation.
+	if runtime.GOOS == "windows" {
+		isService, err := winServiceMain()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if isService {
+			os.Exit(0)
+		}
+	}
+
+	// Work around defer not working after os.Exit()
+	if err := hcdMain(nil); err != nil {
+		os.Exit(1)
+	}
+}
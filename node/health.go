@@ -0,0 +1,210 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package node
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/HcashOrg/hcd/database"
+)
+
+const (
+	// The following constants are the statuses reported by the gethealth
+	// RPC for the node as a whole and for each of its subsystems.  The
+	// status of the node is the worst status of its subsystems.
+	healthOK       = "ok"
+	healthDegraded = "degraded"
+	healthError    = "error"
+
+	// dbLatencySamples is the number of the most recent database
+	// transactions the latency percentiles are computed from.
+	dbLatencySamples = 1024
+
+	// dbSlowLatency is the 99th percentile of the database transaction
+	// latency above which the database is reported as degraded.
+	dbSlowLatency = time.Second
+
+	// minHealthyNetGroups is the number of distinct network groups the
+	// peers must be spread over for the peers not to be reported as
+	// degraded, unless only the peers given with --connect are used.
+	minHealthyNetGroups = 2
+)
+
+// healthRank maps the health statuses to their severity.
+var healthRank = map[string]int{
+	healthOK:       0,
+	healthDegraded: 1,
+	healthError:    2,
+}
+
+// worstHealth returns the most severe of the passed health statuses.
+func worstHealth(statuses ...string) string {
+	worst := healthOK
+	for _, status := range statuses {
+		if healthRank[status] > healthRank[worst] {
+			worst = status
+		}
+	}
+	return worst
+}
+
+// latencyTracker records the durations of the most recent operations in a
+// ring buffer in order to compute percentiles over them.  It is safe for
+// concurrent access.
+type latencyTracker struct {
+	mtx     sync.Mutex
+	samples []time.Duration
+	next    int
+}
+
+// newLatencyTracker returns a latency tracker which keeps up to the passed
+// number of samples.
+func newLatencyTracker(size int) *latencyTracker {
+	return &latencyTracker{samples: make([]time.Duration, 0, size)}
+}
+
+// add records the duration of an operation, replacing the oldest sample once
+// the tracker is full.
+func (t *latencyTracker) add(d time.Duration) {
+	t.mtx.Lock()
+	if len(t.samples) < cap(t.samples) {
+		t.samples = append(t.samples, d)
+	} else {
+		t.samples[t.next] = d
+		t.next = (t.next + 1) % len(t.samples)
+	}
+	t.mtx.Unlock()
+}
+
+// percentiles returns the number of recorded samples along with the passed
+// percentiles, each between 0 and 100, of their durations using the nearest
+// rank method.  The durations are zero when there are no samples.
+func (t *latencyTracker) percentiles(ps ...float64) (int, []time.Duration) {
+	t.mtx.Lock()
+	sorted := make([]time.Duration, len(t.samples))
+	copy(sorted, t.samples)
+	t.mtx.Unlock()
+
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	durations := make([]time.Duration, len(ps))
+	if len(sorted) == 0 {
+		return 0, durations
+	}
+	for i, p := range ps {
+		rank := int(p/100*float64(len(sorted)) + 0.5)
+		if rank < 1 {
+			rank = 1
+		}
+		if rank > len(sorted) {
+			rank = len(sorted)
+		}
+		durations[i] = sorted[rank-1]
+	}
+	return len(sorted), durations
+}
+
+// timedDB wraps a database to record the latency of its managed transactions
+// and to log the slow ones.
+type timedDB struct {
+	database.DB
+	latency *latencyTracker
+	slow    *slowQueryLog
+}
+
+// View invokes the passed function in the context of a managed read-only
+// transaction of the wrapped database and records how long it took.
+//
+// This function is part of the database.DB interface implementation.
+func (db *timedDB) View(fn func(tx database.Tx) error) error {
+	start := time.Now()
+	var lockWait time.Duration
+	err := db.DB.View(func(tx database.Tx) error {
+		lockWait = time.Since(start)
+		return fn(tx)
+	})
+	d := time.Since(start)
+	db.latency.add(d)
+	if db.slow.isSlow(d) {
+		db.slow.recordDB(slowQueryDBView, callerName(), start, lockWait)
+	}
+	return err
+}
+
+// Update invokes the passed function in the context of a managed read-write
+// transaction of the wrapped database and records how long it took.
+//
+// This function is part of the database.DB interface implementation.
+func (db *timedDB) Update(fn func(tx database.Tx) error) error {
+	start := time.Now()
+	var lockWait time.Duration
+	err := db.DB.Update(func(tx database.Tx) error {
+		lockWait = time.Since(start)
+		return fn(tx)
+	})
+	d := time.Since(start)
+	db.latency.add(d)
+	if db.slow.isSlow(d) {
+		db.slow.recordDB(slowQueryDBUpdate, callerName(), start,
+			lockWait)
+	}
+	return err
+}
+
+// subsystemError is the last error logged by a subsystem.
+type subsystemError struct {
+	time    time.Time
+	message string
+}
+
+// errorTracker keeps the last error or critical message logged by each
+// subsystem.  It is safe for concurrent access.
+type errorTracker struct {
+	mtx  sync.Mutex
+	errs map[string]subsystemError
+}
+
+// logErrors keeps the last errors logged by each subsystem for the gethealth
+// RPC.  It is fed by the log writer, so errors are only recorded while the
+// log level of their subsystem includes them.
+var logErrors = &errorTracker{errs: make(map[string]subsystemError)}
+
+// recordLogLine records the passed formatted log line as the last error of its
+// subsystem when it is logged at the error or critical level.  Log lines have
+// the form "<time> [<level>] <subsystem>: <message>".
+func (t *errorTracker) recordLogLine(line []byte) {
+	i := bytes.IndexByte(line, '[')
+	if i < 0 || len(line) < i+6 {
+		return
+	}
+	level := string(line[i+1 : i+4])
+	if level != "ERR" && level != "CRT" {
+		return
+	}
+	rest := line[i+6:]
+	j := bytes.Index(rest, []byte(": "))
+	if j < 0 {
+		return
+	}
+	subsystem := string(rest[:j])
+	message := string(bytes.TrimSpace(rest[j+2:]))
+
+	t.mtx.Lock()
+	t.errs[subsystem] = subsystemError{time: time.Now(), message: message}
+	t.mtx.Unlock()
+}
+
+// lastErrors returns a copy of the last errors logged by each subsystem.
+func (t *errorTracker) lastErrors() map[string]subsystemError {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	errs := make(map[string]subsystemError, len(t.errs))
+	for subsystem, err := range t.errs {
+		errs[subsystem] = err
+	}
+	return errs
+}
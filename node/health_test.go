@@ -0,0 +1,98 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package node
+
+import (
+	"testing"
+	"time"
+)
+
+// TestLatencyTracker ensures the latency tracker computes nearest rank
+// percentiles over the most recent samples only.
+func TestLatencyTracker(t *testing.T) {
+	tracker := newLatencyTracker(100)
+	n, latencies := tracker.percentiles(50, 99)
+	if n != 0 || latencies[0] != 0 || latencies[1] != 0 {
+		t.Fatalf("unexpected percentiles without samples: %d %v", n,
+			latencies)
+	}
+
+	for i := 100; i >= 1; i-- {
+		tracker.add(time.Duration(i) * time.Millisecond)
+	}
+	n, latencies = tracker.percentiles(0, 50, 90, 99, 100)
+	want := []time.Duration{1, 50, 90, 99, 100}
+	if n != 100 {
+		t.Fatalf("unexpected number of samples: got %d, want 100", n)
+	}
+	for i := range want {
+		if latencies[i] != want[i]*time.Millisecond {
+			t.Fatalf("unexpected percentiles: got %v, want %v (ms)",
+				latencies, want)
+		}
+	}
+
+	// Further samples replace the oldest ones, which are the slowest.
+	for i := 0; i < 50; i++ {
+		tracker.add(time.Millisecond)
+	}
+	n, latencies = tracker.percentiles(50, 100)
+	if n != 100 || latencies[0] != time.Millisecond ||
+		latencies[1] != 50*time.Millisecond {
+		t.Fatalf("unexpected percentiles after replacing samples: %d %v",
+			n, latencies)
+	}
+}
+
+// TestErrorTracker ensures only log lines at the error and critical levels are
+// recorded as the last error of their subsystem.
+func TestErrorTracker(t *testing.T) {
+	tracker := &errorTracker{errs: make(map[string]subsystemError)}
+	lines := []string{
+		"2020-01-02 15:04:05.000 [INF] SRVR: Server listening\n",
+		"2020-01-02 15:04:05.000 [ERR] SRVR: Can't accept connection: x\n",
+		"2020-01-02 15:04:05.000 [WRN] BMGR: Rejected block [stale]: y\n",
+		"2020-01-02 15:04:05.000 [CRT] BCDB: Write failed: disk full\n",
+		"2020-01-02 15:04:05.000 [ERR] SRVR: Second error\n",
+		"malformed [ERR\n",
+	}
+	for _, line := range lines {
+		tracker.recordLogLine([]byte(line))
+	}
+
+	errs := tracker.lastErrors()
+	want := map[string]string{
+		"SRVR": "Second error",
+		"BCDB": "Write failed: disk full",
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("unexpected errors: got %v, want %v", errs, want)
+	}
+	for subsystem, message := range want {
+		if errs[subsystem].message != message {
+			t.Fatalf("unexpected error of %s: got %q, want %q",
+				subsystem, errs[subsystem].message, message)
+		}
+	}
+}
+
+// TestWorstHealth ensures the most severe health status is returned.
+func TestWorstHealth(t *testing.T) {
+	tests := []struct {
+		statuses []string
+		want     string
+	}{
+		{nil, healthOK},
+		{[]string{healthOK, healthOK}, healthOK},
+		{[]string{healthOK, healthDegraded}, healthDegraded},
+		{[]string{healthError, healthDegraded, healthOK}, healthError},
+	}
+	for _, test := range tests {
+		if got := worstHealth(test.statuses...); got != test.want {
+			t.Errorf("worstHealth(%v): got %s, want %s",
+				test.statuses, got, test.want)
+		}
+	}
+}
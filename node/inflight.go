@@ -0,0 +1,106 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package node
+
+import (
+	"time"
+
+	"github.com/HcashOrg/hcd/chaincfg/chainhash"
+)
+
+const (
+	// blockRequestTimeout is the time after which a block requested from a
+	// peer which did not deliver it yet may be requested from another peer
+	// which announces it.
+	blockRequestTimeout = time.Minute
+
+	// maxRelayBlocksInFlight is the maximum number of blocks announced by a
+	// peer other than the sync peer which are requested from it at once.
+	// Further blocks it announces are left to the other peers announcing
+	// them.
+	maxRelayBlocksInFlight = 16
+)
+
+// inFlightBlock describes a block requested from a peer which was not received
+// yet.
+type inFlightBlock struct {
+	peer      *serverPeer
+	requested time.Time
+}
+
+// inFlightBlocks tracks the blocks requested from peers which were not received
+// yet, keyed by block hash, so each block is only requested from one peer at a
+// time.  A request which was not answered within blockRequestTimeout no longer
+// prevents the block from being requested from another peer.  It is only
+// accessed from the block handler goroutine.
+type inFlightBlocks struct {
+	blocks  map[chainhash.Hash]inFlightBlock
+	perPeer map[*serverPeer]int
+}
+
+// newInFlightBlocks returns an empty tracker of in-flight blocks.
+func newInFlightBlocks() *inFlightBlocks {
+	return &inFlightBlocks{
+		blocks:  make(map[chainhash.Hash]inFlightBlock),
+		perPeer: make(map[*serverPeer]int),
+	}
+}
+
+// add records that the block with the passed hash was requested from the
+// passed peer at the passed time, replacing any previous request of it.
+func (f *inFlightBlocks) add(hash *chainhash.Hash, sp *serverPeer, now time.Time) {
+	f.remove(hash)
+	f.blocks[*hash] = inFlightBlock{peer: sp, requested: now}
+	f.perPeer[sp]++
+}
+
+// remove removes the request of the block with the passed hash, if any.
+func (f *inFlightBlocks) remove(hash *chainhash.Hash) {
+	block, ok := f.blocks[*hash]
+	if !ok {
+		return
+	}
+	delete(f.blocks, *hash)
+	f.perPeer[block.peer]--
+	if f.perPeer[block.peer] <= 0 {
+		delete(f.perPeer, block.peer)
+	}
+}
+
+// removePeer removes the requests of all blocks requested from the passed peer
+// so they may be requested from other peers.
+func (f *inFlightBlocks) removePeer(sp *serverPeer) {
+	if f.perPeer[sp] == 0 {
+		return
+	}
+	for hash, block := range f.blocks {
+		if block.peer == sp {
+			delete(f.blocks, hash)
+		}
+	}
+	delete(f.perPeer, sp)
+}
+
+// isInFlight returns whether the block with the passed hash was requested from
+// a peer within blockRequestTimeout of the passed time and not received yet.
+func (f *inFlightBlocks) isInFlight(hash *chainhash.Hash, now time.Time) bool {
+	block, ok := f.blocks[*hash]
+	return ok && now.Sub(block.requested) < blockRequestTimeout
+}
+
+// peerCount returns the number of blocks in flight from the passed peer.
+func (f *inFlightBlocks) peerCount(sp *serverPeer) int {
+	return f.perPeer[sp]
+}
+
+// pruneExpired removes the requests which were not answered within
+// blockRequestTimeout of the passed time.
+func (f *inFlightBlocks) pruneExpired(now time.Time) {
+	for hash, block := range f.blocks {
+		if now.Sub(block.requested) >= blockRequestTimeout {
+			f.remove(&hash)
+		}
+	}
+}
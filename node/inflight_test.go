@@ -0,0 +1,60 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/HcashOrg/hcd/chaincfg/chainhash"
+)
+
+// TestInFlightBlocks ensures blocks are tracked as in flight from a single peer
+// until they are received, the peer is removed or the request expires.
+func TestInFlightBlocks(t *testing.T) {
+	f := newInFlightBlocks()
+	sp1, sp2 := &serverPeer{}, &serverPeer{}
+	hash1, hash2 := chainhash.Hash{0x01}, chainhash.Hash{0x02}
+	now := time.Now()
+
+	f.add(&hash1, sp1, now)
+	f.add(&hash2, sp1, now)
+	if !f.isInFlight(&hash1, now) || f.peerCount(sp1) != 2 {
+		t.Fatalf("got in flight %v and peer count %d, want true and 2",
+			f.isInFlight(&hash1, now), f.peerCount(sp1))
+	}
+
+	// Requesting a block again from another peer moves it to that peer.
+	f.add(&hash2, sp2, now)
+	if f.peerCount(sp1) != 1 || f.peerCount(sp2) != 1 {
+		t.Fatalf("got peer counts %d and %d, want 1 and 1",
+			f.peerCount(sp1), f.peerCount(sp2))
+	}
+
+	// Removing a peer only removes the blocks in flight from it.
+	f.removePeer(sp1)
+	if f.isInFlight(&hash1, now) || !f.isInFlight(&hash2, now) {
+		t.Fatal("removing a peer did not remove only its blocks")
+	}
+
+	// An expired request no longer prevents requesting the block again
+	// and is pruned.
+	expired := now.Add(blockRequestTimeout)
+	if f.isInFlight(&hash2, expired) {
+		t.Fatal("expired request is still in flight")
+	}
+	f.pruneExpired(expired)
+	if f.peerCount(sp2) != 0 || len(f.blocks) != 0 {
+		t.Fatal("expired request not pruned")
+	}
+
+	// Received blocks are removed.
+	f.add(&hash1, sp2, now)
+	f.remove(&hash1)
+	f.remove(&hash1)
+	if f.isInFlight(&hash1, now) || f.peerCount(sp2) != 0 {
+		t.Fatal("received block is still in flight")
+	}
+}
@@ -4,7 +4,7 @@
 // Use of this source code is governed by an ISC
 // license that can be found in the LICENSE file.
 
-package main
+package node
 
 import (
 	"fmt"
@@ -17,8 +17,11 @@ import (
 	"github.com/HcashOrg/hcd/blockchain/stake"
 	"github.com/HcashOrg/hcd/connmgr"
 	"github.com/HcashOrg/hcd/database"
+	"github.com/HcashOrg/hcd/lockprof"
 	"github.com/HcashOrg/hcd/mempool"
 	"github.com/HcashOrg/hcd/peer"
+	"github.com/HcashOrg/hcd/pubsub"
+	"github.com/HcashOrg/hcd/tracing"
 	"github.com/HcashOrg/hcd/txscript"
 	"github.com/btcsuite/btclog"
 	"github.com/jrick/logrotate/rotator"
@@ -31,12 +34,14 @@ const (
 )
 
 // logWriter implements an io.Writer that outputs to both standard output and
-// the write-end pipe of an initialized log rotator.
+// the write-end pipe of an initialized log rotator.  It also records the last
+// error of each subsystem for the gethealth RPC.
 type logWriter struct{}
 
 func (logWriter) Write(p []byte) (n int, err error) {
 	os.Stdout.Write(p)
 	logRotator.Write(p)
+	logErrors.recordLogLine(p)
 	return len(p), nil
 }
 
@@ -67,12 +72,15 @@ var (
 	chanLog = backendLog.Logger("CHAN")
 	discLog = backendLog.Logger("DISC")
 	indxLog = backendLog.Logger("INDX")
+	lockLog = backendLog.Logger("LOCK")
 	minrLog = backendLog.Logger("MINR")
 	peerLog = backendLog.Logger("PEER")
+	pubsLog = backendLog.Logger("PUBS")
 	rpcsLog = backendLog.Logger("RPCS")
 	scrpLog = backendLog.Logger("SCRP")
 	srvrLog = backendLog.Logger("SRVR")
 	stkeLog = backendLog.Logger("STKE")
+	trceLog = backendLog.Logger("TRCE")
 	txmpLog = backendLog.Logger("TXMP")
 )
 
@@ -83,9 +91,12 @@ func init() {
 	database.UseLogger(bcdbLog)
 	blockchain.UseLogger(chanLog)
 	indexers.UseLogger(indxLog)
+	lockprof.UseLogger(lockLog)
 	peer.UseLogger(peerLog)
+	pubsub.UseLogger(pubsLog)
 	txscript.UseLogger(scrpLog)
 	stake.UseLogger(stkeLog)
+	tracing.UseLogger(trceLog)
 	mempool.UseLogger(txmpLog)
 }
 
@@ -100,12 +111,15 @@ var subsystemLoggers = map[string]btclog.Logger{
 	"CHAN": chanLog,
 	"DISC": discLog,
 	"INDX": indxLog,
+	"LOCK": lockLog,
 	"MINR": minrLog,
 	"PEER": peerLog,
+	"PUBS": pubsLog,
 	"RPCS": rpcsLog,
 	"SCRP": scrpLog,
 	"SRVR": srvrLog,
 	"STKE": stkeLog,
+	"TRCE": trceLog,
 	"TXMP": txmpLog,
 }
 
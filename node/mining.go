@@ -4,7 +4,7 @@
 // Use of this source code is governed by an ISC
 // license that can be found in the LICENSE file.
 
-package main
+package node
 
 import (
 	"container/heap"
@@ -402,6 +402,10 @@ type BlockTemplate struct {
 	// NewBlockTemplate for details on which this can be useful to generate
 	// templates without a coinbase payment address.
 	ValidPayAddress bool
+
+	// TxSnapshot is the snapshot of the source transactions the template
+	// was built from.
+	TxSnapshot *mining.TxSourceSnapshot
 }
 
 // mergeUtxoView adds all of the entries in view to viewA.  The result is that
@@ -521,9 +525,73 @@ func UpdateExtraNonce(msgBlock *wire.MsgBlock, blockHeight int64,
 	return nil
 }
 
+// maxMinerPayoutWeight is the maximum sum of the weights of the addresses the
+// miner output of generated coinbase transactions is split between.
+const maxMinerPayoutWeight = 1000000
+
+// minerPayout houses an address the miner output of generated coinbase
+// transactions pays to along with its share of the output relative to the
+// other payout addresses.
+type minerPayout struct {
+	addr   hcutil.Address
+	weight uint32
+}
+
+// minerPayouts returns the payouts the miner output of a coinbase transaction
+// paying to the passed address is split between.  The output is split between
+// the addresses configured with --miningaddrsplit, if any, unless the address
+// is nil in which case the coinbase is redeemable by anyone.
+func minerPayouts(addr hcutil.Address) []minerPayout {
+	if addr != nil && len(cfg.miningPayouts) > 0 {
+		return cfg.miningPayouts
+	}
+	return []minerPayout{{addr: addr, weight: 1}}
+}
+
+// splitMinerValue splits the passed value between the passed payouts in
+// proportion to their weights.  The atoms left over due to rounding go to the
+// first payout so the returned values always add up to the passed value.  The
+// sum of the weights must not exceed maxMinerPayoutWeight.
+func splitMinerValue(value int64, payouts []minerPayout) []int64 {
+	var totalWeight int64
+	for _, payout := range payouts {
+		totalWeight += int64(payout.weight)
+	}
+
+	// Split the quotient and the remainder separately to avoid overflowing
+	// the product of the value and a weight.
+	values := make([]int64, len(payouts))
+	quotient, remainder := value/totalWeight, value%totalWeight
+	var paid int64
+	for i, payout := range payouts {
+		weight := int64(payout.weight)
+		values[i] = quotient*weight + remainder*weight/totalWeight
+		paid += values[i]
+	}
+	values[0] += value - paid
+	return values
+}
+
+// addMinerFees adds the passed fees to the miner outputs of the passed coinbase
+// transaction, which follow the tax and extranonce outputs, so the total value
+// of the outputs is split between the passed payouts the outputs were created
+// for.
+func addMinerFees(tx *wire.MsgTx, payouts []minerPayout, fees int64) {
+	minerOuts := tx.TxOut[2:]
+	total := fees
+	for _, txOut := range minerOuts {
+		total += txOut.Value
+	}
+	for i, value := range splitMinerValue(total, payouts) {
+		minerOuts[i].Value = value
+	}
+}
+
 // createCoinbaseTx returns a coinbase transaction paying an appropriate subsidy
 // based on the passed block height to the provided address.  When the address
-// is nil, the coinbase transaction will instead be redeemable by anyone.
+// is nil, the coinbase transaction will instead be redeemable by anyone.  When
+// --miningaddrsplit is set, the subsidy is split between the configured
+// addresses instead of being paid to the provided address.
 //
 // See the comment for NewBlockTemplate for more information about why the nil
 // address handling is useful.
@@ -612,29 +680,33 @@ func createCoinbaseTx(subsidyCache *blockchain.SubsidyCache,
 	// ValueIn.
 	tx.TxIn[0].ValueIn = subsidy + tax
 
-	// Create the script to pay to the provided payment address if one was
-	// specified.  Otherwise create a script that allows the coinbase to be
-	// redeemable by anyone.
-	var pksSubsidy []byte
-	if addr != nil {
-		var err error
-		pksSubsidy, err = txscript.PayToAddrScript(addr)
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		var err error
-		scriptBuilder := txscript.NewScriptBuilder()
-		pksSubsidy, err = scriptBuilder.AddOp(txscript.OP_TRUE).Script()
-		if err != nil {
-			return nil, err
+	// Create the scripts to pay to the payout addresses if a payment
+	// address was specified.  Otherwise create a script that allows the
+	// coinbase to be redeemable by anyone.
+	payouts := minerPayouts(addr)
+	values := splitMinerValue(subsidy, payouts)
+	for i, payout := range payouts {
+		var pksSubsidy []byte
+		if payout.addr != nil {
+			var err error
+			pksSubsidy, err = txscript.PayToAddrScript(payout.addr)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			var err error
+			scriptBuilder := txscript.NewScriptBuilder()
+			pksSubsidy, err = scriptBuilder.AddOp(txscript.OP_TRUE).Script()
+			if err != nil {
+				return nil, err
+			}
 		}
+		// Subsidy paid to miner.
+		tx.AddTxOut(&wire.TxOut{
+			Value:    values[i],
+			PkScript: pksSubsidy,
+		})
 	}
-	// Subsidy paid to miner.
-	tx.AddTxOut(&wire.TxOut{
-		Value:    subsidy,
-		PkScript: pksSubsidy,
-	})
 
 	return hcutil.NewTx(tx), nil
 }
@@ -798,6 +870,7 @@ func deepCopyBlockTemplate(blockTemplate *BlockTemplate) *BlockTemplate {
 		SigOpCounts:     sigOps,
 		Height:          blockTemplate.Height,
 		ValidPayAddress: blockTemplate.ValidPayAddress,
+		TxSnapshot:      blockTemplate.TxSnapshot,
 	}
 }
 
@@ -1086,7 +1159,8 @@ func handleCreatedBlockTemplate(blockTemplate *BlockTemplate,
 // the priority queue is updated to prioritize by fees per kilobyte (then
 // priority).
 //
-// When the fees per kilobyte drop below the TxMinFreeFee policy setting, the
+// When the fees per kilobyte drop below the minimum relay fee currently
+// configured, which may change when the configuration is reloaded, the
 // transaction will be skipped unless the BlockMinSize policy setting is
 // nonzero, in which case the block will be filled with the low-fee/free
 // transactions until the block size reaches that minimum size.
@@ -1108,7 +1182,7 @@ func handleCreatedBlockTemplate(blockTemplate *BlockTemplate,
 //  |                                   |   |
 //  |                                   |   |--- (policy.BlockMaxSize) / 2
 //  |  Transactions prioritized by fee  |   |
-//  |  until <= policy.TxMinFreeFee     |   |
+//  |  until <= min relay fee           |   |
 //  |                                   |   |
 //  |                                   |   |
 //  |                                   |   |
@@ -1140,11 +1214,23 @@ func handleCreatedBlockTemplate(blockTemplate *BlockTemplate,
 func NewBlockTemplate(policy *mining.Policy, server *server,
 	payToAddress hcutil.Address) (*BlockTemplate, error) {
 
+	return newBlockTemplate(policy, server, payToAddress,
+		server.chainParams.TicketsPerBlock)
+}
+
+// newBlockTemplate creates a new block template as described by
+// NewBlockTemplate, including at most the passed number of votes.  Limiting
+// the votes allows blocks with missed votes to be generated on demand for
+// testing.
+func newBlockTemplate(policy *mining.Policy, server *server,
+	payToAddress hcutil.Address, maxVotes uint16) (*BlockTemplate, error) {
+
 	// TODO: The mempool should be completely separated via the TxSource
 	// interface so this function is fully decoupled.
 	mp := server.txMemPool
 
 	var txSource mining.TxSource = server.txMemPool
+	txMinFreeFee := configuredMinRelayTxFee()
 	blockManager := server.blockManager
 	timeSource := server.timeSource
 	chainState := &blockManager.chainState
@@ -1257,13 +1343,17 @@ func NewBlockTemplate(policy *mining.Policy, server *server,
 		}
 	}
 
-	// Get the current source transactions and create a priority queue to
-	// hold the transactions which are ready for inclusion into a block
-	// along with some priority related and fee metadata.  Reserve the same
-	// number of items that are available for the priority queue.  Also,
-	// choose the initial sort order for the priority queue based on whether
-	// or not there is an area allocated for high-priority transactions.
-	sourceTxns := txSource.MiningDescs()
+	// Get a snapshot of the current source transactions and create a
+	// priority queue to hold the transactions which are ready for inclusion
+	// into a block along with some priority related and fee metadata.
+	// Working from the snapshot means the source is not locked while the
+	// template is built, and the dependency checks below see the same
+	// transactions as the ones being considered.  Reserve the same number
+	// of items that are available for the priority queue.  Also, choose the
+	// initial sort order for the priority queue based on whether or not
+	// there is an area allocated for high-priority transactions.
+	snapshot := txSource.Snapshot()
+	sourceTxns := snapshot.Descs()
 	sortedByFee := policy.BlockPrioritySize == 0
 	lessFunc := txPQByStakeAndFeeAndThenPriority
 	if sortedByFee {
@@ -1364,7 +1454,7 @@ mempoolLoop:
 			originIndex := txIn.PreviousOutPoint.Index
 			utxoEntry := utxos.LookupEntry(originHash)
 			if utxoEntry == nil || utxoEntry.IsOutputSpent(originIndex) {
-				if !txSource.HaveTransaction(originHash) {
+				if !snapshot.HaveTransaction(originHash) {
 					minrLog.Tracef("Skipping tx %s because "+
 						"it references unspent output "+
 						"%s which is not available",
@@ -1395,17 +1485,20 @@ mempoolLoop:
 		// Calculate the final transaction priority using the input
 		// value age sum as well as the adjusted transaction size.  The
 		// formula is: sum(inputValue * inputAge) / adjustedTxSize
+		// Any priority delta set with prioritisetransaction is added.
 		prioItem.priority = mempool.CalcPriority(tx.MsgTx(), utxos,
-			nextBlockHeight)
+			nextBlockHeight) + txDesc.PriorityDelta
 
 		// Calculate the fee in Atoms/KB.
 		// NOTE: This is a more precise value than the one calculated
 		// during calcMinRelayFee which rounds up to the nearest full
 		// kilobyte boundary.  This is beneficial since it provides an
-		// incentive to create smaller transactions.
+		// incentive to create smaller transactions.  Any fee delta set
+		// with prioritisetransaction only affects the ordering and not
+		// the fees collected by the block.
 		txSize := tx.MsgTx().SerializeSize()
-		prioItem.feePerKB = (float64(txDesc.Fee) * float64(kilobyte)) /
-			float64(txSize)
+		prioItem.feePerKB = (float64(txDesc.Fee+txDesc.FeeDelta) *
+			float64(kilobyte)) / float64(txSize)
 		prioItem.fee = txDesc.Fee
 
 		// Add the transaction to the priority queue to mark it ready
@@ -1446,6 +1539,7 @@ mempoolLoop:
 	for _, ticketHash := range winningTickets {
 		foundWinningTickets[ticketHash] = false
 	}
+	numVotes := uint16(0)
 
 	// Choose which transactions make it into the block.
 	for priorityQueue.Len() > 0 {
@@ -1539,6 +1633,12 @@ mempoolLoop:
 			if foundWinningTickets[tx.MsgTx().TxIn[1].PreviousOutPoint.Hash] {
 				continue
 			}
+			if numVotes >= maxVotes {
+				minrLog.Tracef("Skipping vote %s because the block "+
+					"already includes the maximum of %d votes",
+					tx.Hash(), maxVotes)
+				continue
+			}
 			msgTx := tx.MsgTx()
 			isEligible := false
 			for _, sstxHash := range winningTickets {
@@ -1555,14 +1655,14 @@ mempoolLoop:
 		// Skip free transactions once the block is larger than the
 		// minimum block size, except for stake transactions.
 		if sortedByFee &&
-			(prioItem.feePerKB < float64(policy.TxMinFreeFee)) &&
+			(prioItem.feePerKB < float64(txMinFreeFee)) &&
 			(tx.Tree() != wire.TxTreeStake) &&
 			(blockPlusTxSize >= policy.BlockMinSize) {
 
 			minrLog.Tracef("Skipping tx %s with feePerKB %.2f "+
-				"< TxMinFreeFee %d and block size %d >= "+
+				"< minRelayTxFee %d and block size %d >= "+
 				"minBlockSize %d", tx.Hash(), prioItem.feePerKB,
-				policy.TxMinFreeFee, blockPlusTxSize,
+				txMinFreeFee, blockPlusTxSize,
 				policy.BlockMinSize)
 			logSkippedDeps(tx, deps)
 			continue
@@ -1610,7 +1710,7 @@ mempoolLoop:
 			continue
 		}
 		err = blockchain.ValidateTransactionScripts(tx, blockUtxos,
-			scriptFlags, server.sigCache)
+			scriptFlags, server.sigCache, server.scriptCache)
 		if err != nil {
 			minrLog.Tracef("Skipping tx %s due to error in "+
 				"ValidateTransactionScripts: %v", tx.Hash(), err)
@@ -1643,6 +1743,7 @@ mempoolLoop:
 		}
 		if isSSGen {
 			foundWinningTickets[tx.MsgTx().TxIn[1].PreviousOutPoint.Hash] = true
+			numVotes++
 		}
 
 		txFeesMap[*tx.Hash()] = prioItem.fee
@@ -1933,7 +2034,8 @@ mempoolLoop:
 		blockSize -= wire.MaxVarIntPayload -
 			uint32(wire.VarIntSerializeSize(uint64(len(blockTxnsRegular)) +
 				uint64(len(blockTxnsStake))))
-		coinbaseTx.MsgTx().TxOut[2].Value += totalFees
+		addMinerFees(coinbaseTx.MsgTx(), minerPayouts(payToAddress),
+			totalFees)
 		txFees[0] = -totalFees
 	}
 
@@ -2120,6 +2222,7 @@ mempoolLoop:
 		SigOpCounts:     txSigOpCounts,
 		Height:          nextBlockHeight,
 		ValidPayAddress: payToAddress != nil,
+		TxSnapshot:      snapshot,
 	}
 
 	return handleCreatedBlockTemplate(blockTemplate, server.blockManager)
@@ -2132,6 +2235,16 @@ mempoolLoop:
 // based on the new time for the test networks since their target difficulty can
 // change based upon time.
 func UpdateBlockTime(msgBlock *wire.MsgBlock, bManager *blockManager) error {
+	return updateBlockTime(msgBlock, bManager, 0)
+}
+
+// updateBlockTime updates the timestamp in the header of the passed block like
+// UpdateBlockTime, but shifts it by the passed offset first.  Blocks with
+// timestamps that violate the consensus rules can be generated this way, so it
+// is only intended for testing.
+func updateBlockTime(msgBlock *wire.MsgBlock, bManager *blockManager,
+	offset time.Duration) error {
+
 	// The new timestamp is potentially adjusted to ensure it comes after
 	// the median time of the last several blocks per the chain consensus
 	// rules.
@@ -2140,6 +2253,7 @@ func UpdateBlockTime(msgBlock *wire.MsgBlock, bManager *blockManager) error {
 	if err != nil {
 		return miningRuleError(ErrGettingMedianTime, err.Error())
 	}
+	newTimestamp = newTimestamp.Add(offset)
 	msgBlock.Header.Timestamp = newTimestamp
 
 	// If running on a network that requires recalculating the difficulty,
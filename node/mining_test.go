@@ -4,11 +4,12 @@
 // Use of this source code is governed by an ISC
 // license that can be found in the LICENSE file.
 
-package main
+package node
 
 import (
 	"container/heap"
 	"math/rand"
+	"reflect"
 	"testing"
 
 	"github.com/HcashOrg/hcd/blockchain/stake"
@@ -128,3 +129,52 @@ func TestStakeTxFeePrioHeap(t *testing.T) {
 		}
 	}
 }
+
+// TestSplitMinerValue ensures the miner output of generated coinbase
+// transactions is split in proportion to the payout weights without losing or
+// creating any atoms.
+func TestSplitMinerValue(t *testing.T) {
+	weights := func(ws ...uint32) []minerPayout {
+		payouts := make([]minerPayout, len(ws))
+		for i, w := range ws {
+			payouts[i].weight = w
+		}
+		return payouts
+	}
+
+	tests := []struct {
+		name    string
+		value   int64
+		payouts []minerPayout
+		want    []int64
+	}{
+		{"single payout", 123456789, weights(1), []int64{123456789}},
+		{"even split", 1000, weights(1, 1), []int64{500, 500}},
+		{"weighted split", 1000, weights(3, 1), []int64{750, 250}},
+		{"remainder to first", 1000, weights(1, 1, 1), []int64{334, 333, 333}},
+		{"zero value", 0, weights(2, 5), []int64{0, 0}},
+		{"tiny share", 10, weights(999999, 1), []int64{10, 0}},
+		{
+			"no overflow",
+			21e14,
+			weights(maxMinerPayoutWeight-1, 1),
+			[]int64{2099997900000000, 2100000000},
+		},
+	}
+
+	for _, test := range tests {
+		got := splitMinerValue(test.value, test.payouts)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+			continue
+		}
+		var sum int64
+		for _, v := range got {
+			sum += v
+		}
+		if sum != test.value {
+			t.Errorf("%s: split adds up to %d, want %d", test.name,
+				sum, test.value)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package node implements a full hcd node that applications can embed in their own
+binary instead of running hcd as a separate process.
+
+The configuration is loaded with LoadConfig from the same options hcd accepts on
+its command line and in its configuration file.  New then opens the block
+database and creates the node, which joins the network once it is started:
+
+	cfg, _, err := node.LoadConfig([]string{"--simnet", "--notls"})
+	if err != nil {
+		// Handle error.
+	}
+	n, err := node.New(cfg)
+	if err != nil {
+		// Handle error.
+	}
+	n.Start()
+	defer n.Stop()
+
+The configuration, logging and active network parameters are process wide, so
+only a single node may run per process.  Shutdown requests made through the stop
+RPC are left to hcd itself, so embedding applications decide when to call Stop.
+*/
+package node
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/HcashOrg/hcd/blockchain"
+	"github.com/HcashOrg/hcd/database"
+	"github.com/HcashOrg/hcd/hcjson"
+	"github.com/HcashOrg/hcd/mempool"
+)
+
+// Node is a full node consisting of the block database, the chain, the memory
+// pool, the peer-to-peer server and, unless disabled, the RPC server.
+type Node struct {
+	db     database.DB
+	server *server
+}
+
+// New opens the block database and creates a node from the passed
+// configuration, which must have been loaded with LoadConfig.  The node does
+// not connect to the network until Start is called.
+func New(config *Config) (*Node, error) {
+	cfg = config
+
+	db, err := loadBlockDB()
+	if err != nil {
+		return nil, err
+	}
+	n, err := newNode(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return n, nil
+}
+
+// newNode creates a node on top of the passed block database according to the
+// active configuration.
+func newNode(db database.DB) (*Node, error) {
+	s, err := newServer(cfg.Listeners, db, activeNetParams.Params)
+	if err != nil {
+		return nil, err
+	}
+	return &Node{db: db, server: s}, nil
+}
+
+// Start begins connecting to peers and serving RPC clients.
+func (n *Node) Start() {
+	n.server.Start()
+}
+
+// stopServer shuts down the server of the node and waits for it to finish
+// without closing the block database.  It gives up waiting once the configured
+// shutdown timeout elapses and returns an error naming the step in progress.
+func (n *Node) stopServer() error {
+	done := make(chan struct{})
+	go func() {
+		n.server.Stop()
+		n.server.WaitForShutdown()
+		close(done)
+	}()
+
+	var timeout <-chan time.Time
+	if cfg.ShutdownTimeout > 0 {
+		timer := time.NewTimer(cfg.ShutdownTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case <-done:
+		return nil
+	case <-timeout:
+		return fmt.Errorf("shutdown timed out after %v while %s",
+			cfg.ShutdownTimeout, n.server.ShutdownStep())
+	}
+}
+
+// Stop shuts down the node, waits for all of its subsystems to finish and
+// closes the block database.  The node may not be used afterwards.  When the
+// subsystems do not finish within the configured shutdown timeout, the
+// database is left open since they might still be using it and an error is
+// returned.
+func (n *Node) Stop() error {
+	if err := n.stopServer(); err != nil {
+		return err
+	}
+	return n.db.Close()
+}
+
+// ReloadConfig reads the configuration file and the options the node was loaded
+// with again and applies the ones which may change while the node is running.
+// See the reloadconfig RPC for the options which are reloaded.
+func (n *Node) ReloadConfig() error {
+	return n.server.ReloadConfig()
+}
+
+// Chain returns the block chain of the node.
+func (n *Node) Chain() *blockchain.BlockChain {
+	return n.server.blockManager.chain
+}
+
+// TxMemPool returns the memory pool of transactions the node relays and mines.
+func (n *Node) TxMemPool() *mempool.TxPool {
+	return n.server.txMemPool
+}
+
+// Database returns the block database of the node.
+func (n *Node) Database() database.DB {
+	return n.db
+}
+
+// ExecuteRPC runs the passed command, which must be one of the command types
+// registered with the hcjson package such as the one returned by
+// hcjson.NewGetBestBlockCmd, with the RPC server of the node and returns its
+// result.  It bypasses authentication and client limits since the caller runs
+// in the same process.  An error is returned when the RPC server is disabled.
+func (n *Node) ExecuteRPC(cmd interface{}) (interface{}, error) {
+	s := n.server.rpcServer
+	if s == nil {
+		return nil, errors.New("the RPC server is disabled")
+	}
+
+	method, err := hcjson.CmdMethod(cmd)
+	if err != nil {
+		return nil, err
+	}
+	parsedCmd := &parsedRPCCmd{method: method, cmd: cmd}
+	return s.standardCmdResult(parsedCmd, nil)
+}
@@ -4,9 +4,14 @@
 // Use of this source code is governed by an ISC
 // license that can be found in the LICENSE file.
 
-package main
+package node
 
 import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
 	"github.com/HcashOrg/hcd/chaincfg"
 	"github.com/HcashOrg/hcd/wire"
 )
@@ -47,6 +52,57 @@ var simNetParams = params{
 	rpcPort: "13009",
 }
 
+// loadCustomNetParams reads the definition of a custom network from the passed
+// JSON file as described by chaincfg.ParseCustomParams, registers the network
+// and returns its parameters.  The RPC port is set with the rpcport field and
+// defaults to the one of the base network.
+func loadCustomNetParams(path string) (*params, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	chainParams, err := chaincfg.ParseCustomParams(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// The name of the network is used as the name of its data and log
+	// directories, so it must not refer to those of another network.
+	name := chainParams.Name
+	switch {
+	case name == "." || name == ".." || strings.ContainsAny(name, `/\`):
+		return nil, fmt.Errorf("invalid network name %q", name)
+	case name == "mainnet" || name == "testnet" || name == "testnet2" ||
+		name == "simnet":
+		return nil, fmt.Errorf("the network name %q is reserved for a "+
+			"standard network", name)
+	}
+
+	var def struct {
+		Base    string `json:"base"`
+		RPCPort string `json:"rpcport"`
+	}
+	if err := json.Unmarshal(data, &def); err != nil {
+		return nil, err
+	}
+	rpcPort := def.RPCPort
+	if rpcPort == "" {
+		for _, p := range []*params{&mainNetParams, &testNet2Params,
+			&simNetParams} {
+
+			if p.Name == def.Base {
+				rpcPort = p.rpcPort
+			}
+		}
+	}
+
+	if err := chaincfg.Register(chainParams); err != nil {
+		return nil, fmt.Errorf("unable to register network %s: %v", name,
+			err)
+	}
+	return &params{Params: chainParams, rpcPort: rpcPort}, nil
+}
+
 // netName returns the name used when referring to a hcd network.  At the
 // time of writing, hcd currently places blocks for testnet version 0 in the
 // data and log directory "testnet", which does not match the Name field of the
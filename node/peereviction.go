@@ -0,0 +1,222 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package node
+
+import (
+	"encoding/binary"
+	"sort"
+	"time"
+
+	"github.com/HcashOrg/hcd/addrmgr"
+	"github.com/HcashOrg/hcd/chaincfg/chainhash"
+)
+
+const (
+	// evictionProtectNetGroups is the number of inbound peers from
+	// distinct network groups which are protected from eviction.  The
+	// groups are selected with a per-node secret key so an attacker can't
+	// predict which groups are protected.
+	evictionProtectNetGroups = 4
+
+	// evictionProtectPing is the number of inbound peers with the lowest
+	// minimum ping time which are protected from eviction.
+	evictionProtectPing = 8
+
+	// evictionProtectTxs is the number of inbound peers which most
+	// recently relayed transactions new to the memory pool which are
+	// protected from eviction.
+	evictionProtectTxs = 4
+
+	// evictionProtectBlocks is the number of inbound peers which most
+	// recently relayed blocks new to the chain which are protected from
+	// eviction.
+	evictionProtectBlocks = 4
+)
+
+// evictionCandidate describes an inbound peer which may be evicted to make
+// room for a new inbound peer.
+type evictionCandidate struct {
+	id            int32
+	netGroup      uint64
+	timeConnected time.Time
+	minPingMicros int64
+	lastBlockTime time.Time
+	lastTxTime    time.Time
+}
+
+// keyedNetGroup returns the network group of the passed address group key
+// hashed with the passed secret key.
+func keyedNetGroup(key []byte, groupKey string) uint64 {
+	data := make([]byte, 0, len(key)+len(groupKey))
+	data = append(data, key...)
+	data = append(data, groupKey...)
+	return binary.LittleEndian.Uint64(chainhash.HashB(data))
+}
+
+// protectCandidates sorts the candidates so the n most valuable ones according
+// to the passed function are last and returns the remaining candidates.
+// Candidates which are equally valuable are ordered by uptime so older peers
+// are protected first.
+func protectCandidates(candidates []evictionCandidate, n int,
+	lessValuable func(a, b *evictionCandidate) bool) []evictionCandidate {
+
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := &candidates[i], &candidates[j]
+		if lessValuable(a, b) {
+			return true
+		}
+		if lessValuable(b, a) {
+			return false
+		}
+		return a.timeConnected.After(b.timeConnected)
+	})
+	if n > len(candidates) {
+		n = len(candidates)
+	}
+	return candidates[:len(candidates)-n]
+}
+
+// protectRecent protects up to n of the candidates with the most recent times
+// returned by the passed function and returns the remaining candidates.
+// Candidates with a zero time are never protected.
+func protectRecent(candidates []evictionCandidate, n int,
+	when func(c *evictionCandidate) time.Time) []evictionCandidate {
+
+	var numRecent int
+	for i := range candidates {
+		if !when(&candidates[i]).IsZero() {
+			numRecent++
+		}
+	}
+	if n > numRecent {
+		n = numRecent
+	}
+	return protectCandidates(candidates, n, func(a, b *evictionCandidate) bool {
+		return when(a).Before(when(b))
+	})
+}
+
+// selectEvictionCandidate returns the ID of the least valuable of the passed
+// inbound peers along with whether or not one could be selected.  Peers are
+// protected from eviction in turn by network group diversity, lowest ping
+// time, recent relaying of novel transactions and blocks and, finally, the
+// longest uptime of half of the remaining peers.  The youngest peer of the
+// network group with the most remaining peers is then selected so an attacker
+// controlling a single network group can't take over the inbound slots.
+//
+// No peer is selected when every peer is protected.  The passed slice is
+// reordered.
+func selectEvictionCandidate(candidates []evictionCandidate) (int32, bool) {
+	// Protect peers from distinct network groups with the highest keyed
+	// network groups.
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].netGroup > candidates[j].netGroup
+	})
+	remaining := make([]evictionCandidate, 0, len(candidates))
+	protected := 0
+	for i := range candidates {
+		distinct := i == 0 ||
+			candidates[i].netGroup != candidates[i-1].netGroup
+		if distinct && protected < evictionProtectNetGroups {
+			protected++
+			continue
+		}
+		remaining = append(remaining, candidates[i])
+	}
+
+	// Protect the peers with the lowest ping times.  Peers which have
+	// not replied to a ping yet are considered the slowest.
+	remaining = protectCandidates(remaining, evictionProtectPing,
+		func(a, b *evictionCandidate) bool {
+			if a.minPingMicros == 0 || b.minPingMicros == 0 {
+				return b.minPingMicros != 0
+			}
+			return a.minPingMicros > b.minPingMicros
+		})
+
+	// Protect the peers which most recently relayed novel transactions
+	// and blocks since they are the ones doing useful work.
+	remaining = protectRecent(remaining, evictionProtectTxs,
+		func(c *evictionCandidate) time.Time { return c.lastTxTime })
+	remaining = protectRecent(remaining, evictionProtectBlocks,
+		func(c *evictionCandidate) time.Time { return c.lastBlockTime })
+
+	// Protect the half of the remaining peers which have been connected
+	// the longest.
+	remaining = protectCandidates(remaining, len(remaining)/2,
+		func(a, b *evictionCandidate) bool {
+			return a.timeConnected.After(b.timeConnected)
+		})
+	if len(remaining) == 0 {
+		return 0, false
+	}
+
+	// Select the network group with the most peers, preferring the group
+	// with the most recently connected peer on ties, and evict its most
+	// recently connected peer.
+	type netGroupInfo struct {
+		count    int
+		youngest *evictionCandidate
+	}
+	groups := make(map[uint64]*netGroupInfo)
+	var selected *netGroupInfo
+	for i := range remaining {
+		c := &remaining[i]
+		info, ok := groups[c.netGroup]
+		if !ok {
+			info = &netGroupInfo{youngest: c}
+			groups[c.netGroup] = info
+		}
+		info.count++
+		if c.timeConnected.After(info.youngest.timeConnected) {
+			info.youngest = c
+		}
+	}
+	for _, info := range groups {
+		if selected == nil || info.count > selected.count ||
+			(info.count == selected.count &&
+				info.youngest.timeConnected.After(
+					selected.youngest.timeConnected)) {
+
+			selected = info
+		}
+	}
+	return selected.youngest.id, true
+}
+
+// evictInboundPeer disconnects the least valuable inbound peer which is not
+// whitelisted in order to make room for a new inbound peer and removes it from
+// the peer state.  It returns the evicted peer, or nil when every inbound peer
+// is protected from eviction.
+func (s *server) evictInboundPeer(state *peerState) *serverPeer {
+	candidates := make([]evictionCandidate, 0, len(state.inboundPeers))
+	for _, sp := range state.inboundPeers {
+		if sp.isWhitelisted {
+			continue
+		}
+		var groupKey string
+		if na := sp.NA(); na != nil {
+			groupKey = addrmgr.GroupKey(na)
+		}
+		minPing, lastBlockTime, lastTxTime := sp.evictionStats()
+		candidates = append(candidates, evictionCandidate{
+			id:            sp.ID(),
+			netGroup:      keyedNetGroup(s.netGroupKey[:], groupKey),
+			timeConnected: sp.TimeConnected(),
+			minPingMicros: minPing,
+			lastBlockTime: lastBlockTime,
+			lastTxTime:    lastTxTime,
+		})
+	}
+
+	id, ok := selectEvictionCandidate(candidates)
+	if !ok {
+		return nil
+	}
+	sp := state.inboundPeers[id]
+	delete(state.inboundPeers, id)
+	sp.Disconnect()
+	return sp
+}
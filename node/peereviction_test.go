@@ -0,0 +1,128 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package node
+
+import (
+	"testing"
+	"time"
+)
+
+// TestSelectEvictionCandidate ensures inbound peers are protected from eviction
+// by network group diversity, ping time, relaying novel transactions and
+// blocks and uptime, and that the youngest peer of the largest network group
+// is evicted otherwise.
+func TestSelectEvictionCandidate(t *testing.T) {
+	now := time.Now()
+
+	// newCandidates returns n peers in the same network group which were
+	// connected one minute apart and have the same ping time.  The peer
+	// with the highest ID is the youngest.
+	newCandidates := func(n int) []evictionCandidate {
+		candidates := make([]evictionCandidate, 0, n)
+		for i := 0; i < n; i++ {
+			candidates = append(candidates, evictionCandidate{
+				id:            int32(i),
+				netGroup:      1,
+				timeConnected: now.Add(time.Duration(i-n) * time.Minute),
+				minPingMicros: 1000,
+			})
+		}
+		return candidates
+	}
+
+	tests := []struct {
+		name       string
+		candidates []evictionCandidate
+		modify     func(c []evictionCandidate)
+		wantID     int32
+		wantOK     bool
+	}{
+		{
+			name:       "no candidates",
+			candidates: nil,
+			wantOK:     false,
+		},
+		{
+			name:       "all peers protected",
+			candidates: newCandidates(9),
+			wantOK:     false,
+		},
+		{
+			name:       "youngest peer evicted",
+			candidates: newCandidates(30),
+			wantID:     29,
+			wantOK:     true,
+		},
+		{
+			name:       "fast peer protected",
+			candidates: newCandidates(30),
+			modify: func(c []evictionCandidate) {
+				c[29].minPingMicros = 10
+			},
+			wantID: 28,
+			wantOK: true,
+		},
+		{
+			name:       "peer without ping time not protected",
+			candidates: newCandidates(10),
+			modify: func(c []evictionCandidate) {
+				c[1].minPingMicros = 0
+			},
+			wantID: 1,
+			wantOK: true,
+		},
+		{
+			name:       "tx and block relaying peers protected",
+			candidates: newCandidates(30),
+			modify: func(c []evictionCandidate) {
+				c[29].lastTxTime = now
+				c[28].lastBlockTime = now
+			},
+			wantID: 27,
+			wantOK: true,
+		},
+		{
+			name:       "distinct network group protected",
+			candidates: newCandidates(30),
+			modify: func(c []evictionCandidate) {
+				c[29].netGroup = 2
+			},
+			wantID: 28,
+			wantOK: true,
+		},
+		{
+			name:       "largest network group evicted from",
+			candidates: newCandidates(30),
+			modify: func(c []evictionCandidate) {
+				// Spread the old peers over many network groups
+				// with a single peer each so the young peers of
+				// the original group form the largest group.
+				for i := 0; i < 20; i++ {
+					c[i].netGroup = uint64(100 + i)
+				}
+				c[29].netGroup = 2
+				c[28].netGroup = 2
+			},
+			wantID: 27,
+			wantOK: true,
+		},
+	}
+
+	for _, test := range tests {
+		if test.modify != nil {
+			test.modify(test.candidates)
+		}
+		id, ok := selectEvictionCandidate(test.candidates)
+		if ok != test.wantOK {
+			t.Errorf("%s: unexpected result - got %v, want %v",
+				test.name, ok, test.wantOK)
+			continue
+		}
+		if ok && id != test.wantID {
+			t.Errorf("%s: unexpected evicted peer - got %d, want %d",
+				test.name, id, test.wantID)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package node
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"runtime"
+	"runtime/pprof"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// The following constants are the types of profiles which can be
+	// captured with the getprofile RPC.
+	profileCPU       = "cpu"
+	profileHeap      = "heap"
+	profileGoroutine = "goroutine"
+	profileMutex     = "mutex"
+	profileBlock     = "block"
+
+	// maxProfileDuration is the maximum duration of a timed profile.
+	maxProfileDuration = 5 * time.Minute
+
+	// profileMutexFraction is the fraction of mutex contention events
+	// which are sampled while a mutex profile is captured.
+	profileMutexFraction = 5
+
+	// profileBlockRate is the rate in nanoseconds of blocking events which
+	// are sampled while a block profile is captured.
+	profileBlockRate = int(time.Microsecond)
+)
+
+var (
+	// errProfileInProgress is returned when a timed profile is requested
+	// while another one is being captured.
+	errProfileInProgress = errors.New("another timed profile is being " +
+		"captured")
+
+	// errProfileAborted is returned when the capture of a timed profile is
+	// aborted before its duration elapsed.
+	errProfileAborted = errors.New("profile capture aborted")
+)
+
+// timedProfiling is set while a timed profile is captured, since only one CPU
+// profile may be active at a time and the mutex and block sampling rates are
+// global.
+var timedProfiling int32
+
+// isTimedProfile returns whether the passed type of profile samples events
+// over a duration as opposed to being a snapshot.
+func isTimedProfile(kind string) bool {
+	switch kind {
+	case profileCPU, profileMutex, profileBlock:
+		return true
+	}
+	return false
+}
+
+// captureProfile returns the pprof encoded profile of the passed type.  Timed
+// profiles sample events for the passed duration unless the abort channel is
+// closed first, while the other profiles are snapshots of the current state.
+//
+// Mutex contention and blocking events are only sampled while a profile of
+// them is captured.  Since the runtime never resets these profiles, they
+// include the events of all earlier captures as well.
+func captureProfile(kind string, d time.Duration, abort <-chan struct{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if !isTimedProfile(kind) {
+		profile := pprof.Lookup(kind)
+		if profile == nil {
+			return nil, fmt.Errorf("unknown profile type %q", kind)
+		}
+		if err := profile.WriteTo(&buf, 0); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	if !atomic.CompareAndSwapInt32(&timedProfiling, 0, 1) {
+		return nil, errProfileInProgress
+	}
+	defer atomic.StoreInt32(&timedProfiling, 0)
+
+	switch kind {
+	case profileCPU:
+		if err := pprof.StartCPUProfile(&buf); err != nil {
+			return nil, err
+		}
+		defer pprof.StopCPUProfile()
+
+	case profileMutex:
+		runtime.SetMutexProfileFraction(profileMutexFraction)
+		defer runtime.SetMutexProfileFraction(0)
+
+	case profileBlock:
+		runtime.SetBlockProfileRate(profileBlockRate)
+		defer runtime.SetBlockProfileRate(0)
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-abort:
+		return nil, errProfileAborted
+	}
+
+	if kind == profileCPU {
+		pprof.StopCPUProfile()
+		return buf.Bytes(), nil
+	}
+	if err := pprof.Lookup(kind).WriteTo(&buf, 0); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
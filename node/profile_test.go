@@ -0,0 +1,69 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package node
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestCaptureProfile ensures profiles are captured in the gzipped pprof format
+// and that timed profiles can be aborted and are not captured concurrently.
+func TestCaptureProfile(t *testing.T) {
+	tests := []struct {
+		kind     string
+		duration time.Duration
+	}{
+		{profileHeap, 0},
+		{profileGoroutine, 0},
+		{profileCPU, 10 * time.Millisecond},
+		{profileMutex, 10 * time.Millisecond},
+		{profileBlock, 10 * time.Millisecond},
+	}
+	for _, test := range tests {
+		data, err := captureProfile(test.kind, test.duration, nil)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.kind, err)
+			continue
+		}
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			t.Errorf("%s: profile is not gzipped: %v", test.kind, err)
+			continue
+		}
+		if _, err := ioutil.ReadAll(r); err != nil {
+			t.Errorf("%s: unable to decompress profile: %v",
+				test.kind, err)
+		}
+	}
+
+	if _, err := captureProfile("unknown", 0, nil); err == nil {
+		t.Errorf("did not receive expected error for unknown profile")
+	}
+
+	// A timed profile is aborted once the abort channel is closed and no
+	// other timed profile can be captured in the meantime.
+	abort := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		_, err := captureProfile(profileMutex, time.Hour, abort)
+		done <- err
+	}()
+	for atomic.LoadInt32(&timedProfiling) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	_, err := captureProfile(profileBlock, time.Millisecond, nil)
+	if err != errProfileInProgress {
+		t.Fatalf("unexpected error for concurrent profile: %v", err)
+	}
+	close(abort)
+	if err := <-done; err != errProfileAborted {
+		t.Fatalf("unexpected error for aborted profile: %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package node
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// rateLimitBurstSecs is the number of seconds worth of tokens a peer
+	// message rate limiter holds when full.  It allows peers to send short
+	// bursts of messages, such as a full getdata message, without being
+	// penalized.
+	rateLimitBurstSecs = 10
+
+	// rateLimitBanScore is the transient ban score increase applied each
+	// time a peer exceeds one of its message rate limits.
+	rateLimitBanScore = 10
+)
+
+// tokenBucket is a token bucket rate limiter.  Tokens are added continuously
+// at a fixed rate up to a maximum burst size and are taken for each unit of
+// work, such as a message or an inventory item, that is allowed.
+//
+// The limiter is not safe for concurrent access.  The peer message rate
+// limiters are only used from the input handler of the peer.
+type tokenBucket struct {
+	rate   float64 // tokens added per second
+	burst  float64 // maximum number of tokens
+	tokens float64
+	last   time.Time
+}
+
+// newTokenBucket returns a full token bucket which refills at the passed rate
+// per second and holds rateLimitBurstSecs seconds worth of tokens, but at
+// least a single token.  A rate of zero disables the limiter.
+func newTokenBucket(rate float64) *tokenBucket {
+	burst := rate * rateLimitBurstSecs
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{
+		rate:   rate,
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+	}
+}
+
+// take refills the bucket for the time elapsed since the previous call and
+// takes n tokens from it.  It returns false without taking any tokens when
+// there are fewer than n tokens available.  Requests for more tokens than the
+// bucket can ever hold are allowed when the bucket is full.
+func (b *tokenBucket) take(n float64, now time.Time) bool {
+	if b.rate == 0 {
+		return true
+	}
+
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens += elapsed * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+	}
+	b.last = now
+
+	if n > b.tokens && b.tokens < b.burst {
+		return false
+	}
+	b.tokens -= n
+	return true
+}
+
+// peerRateLimiters houses the message rate limiters of a peer for messages
+// which are expensive to serve.
+type peerRateLimiters struct {
+	getData    *tokenBucket
+	getHeaders *tokenBucket
+	memPool    *tokenBucket
+}
+
+// newPeerRateLimiters returns the message rate limiters for a new peer based
+// on the configured rates.
+func newPeerRateLimiters() *peerRateLimiters {
+	return &peerRateLimiters{
+		getData:    newTokenBucket(cfg.GetDataRate),
+		getHeaders: newTokenBucket(cfg.GetHeadersRate),
+		memPool:    newTokenBucket(cfg.MemPoolRate),
+	}
+}
+
+// exceedsRateLimit takes n tokens from the passed rate limiter of the peer and
+// returns whether or not doing so exceeded the limit.  The ban score of the
+// peer is increased when it does, so peers flooding the node with expensive
+// requests are eventually disconnected and banned.  Whitelisted peers are
+// never limited.
+func (sp *serverPeer) exceedsRateLimit(limiter *tokenBucket, n int,
+	command string) bool {
+
+	if sp.isWhitelisted {
+		return false
+	}
+	if limiter.take(float64(n), time.Now()) {
+		return false
+	}
+	sp.addBanScore(0, rateLimitBanScore,
+		fmt.Sprintf("%s rate limit exceeded", command))
+	return true
+}
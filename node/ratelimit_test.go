@@ -0,0 +1,87 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package node
+
+import (
+	"testing"
+	"time"
+)
+
+// TestTokenBucket ensures the token bucket rate limiter allows bursts up to its
+// size, refills at the configured rate and never holds more than its burst.
+func TestTokenBucket(t *testing.T) {
+	b := newTokenBucket(10)
+	now := b.last
+
+	// The bucket starts full with rateLimitBurstSecs seconds worth of
+	// tokens.
+	if !b.take(10*rateLimitBurstSecs, now) {
+		t.Fatal("full burst was not allowed")
+	}
+	if b.take(1, now) {
+		t.Fatal("take from empty bucket was allowed")
+	}
+
+	// Half a second refills five tokens.
+	now = now.Add(500 * time.Millisecond)
+	if !b.take(5, now) {
+		t.Fatal("refilled tokens were not allowed")
+	}
+	if b.take(1, now) {
+		t.Fatal("take beyond refilled tokens was allowed")
+	}
+
+	// The bucket never holds more than its burst.
+	now = now.Add(time.Hour)
+	if !b.take(10*rateLimitBurstSecs, now) {
+		t.Fatal("full burst after refill was not allowed")
+	}
+	if b.take(1, now) {
+		t.Fatal("bucket held more than its burst")
+	}
+
+	// Requests larger than the burst are only allowed when full.
+	if b.take(20*rateLimitBurstSecs, now) {
+		t.Fatal("oversized request allowed from partial bucket")
+	}
+	now = now.Add(time.Hour)
+	if !b.take(20*rateLimitBurstSecs, now) {
+		t.Fatal("oversized request not allowed from full bucket")
+	}
+
+	// Slow rates hold at least a single token.
+	b = newTokenBucket(0.1)
+	now = b.last
+	if !b.take(1, now) {
+		t.Fatal("single token was not allowed")
+	}
+	if b.take(1, now.Add(5*time.Second)) {
+		t.Fatal("take before refill was allowed")
+	}
+	if !b.take(1, now.Add(10*time.Second)) {
+		t.Fatal("take after refill was not allowed")
+	}
+
+	// A zero rate disables the limiter.
+	b = newTokenBucket(0)
+	for i := 0; i < 100; i++ {
+		if !b.take(1000, b.last) {
+			t.Fatal("disabled limiter did not allow request")
+		}
+	}
+}
+
+// TestExceedsRateLimitWhitelisted ensures whitelisted peers are never rate
+// limited.
+func TestExceedsRateLimitWhitelisted(t *testing.T) {
+	sp := &serverPeer{isWhitelisted: true}
+	b := newTokenBucket(1)
+	if !b.take(rateLimitBurstSecs, b.last) {
+		t.Fatal("full burst was not allowed")
+	}
+	if sp.exceedsRateLimit(b, 1000, "getdata") {
+		t.Fatal("whitelisted peer was rate limited")
+	}
+}
@@ -0,0 +1,241 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package node
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/HcashOrg/hcd/wire"
+)
+
+const (
+	// defaultTxRebroadcastInterval is the default amount of time to wait
+	// before the first rebroadcast of an unconfirmed local transaction.
+	// The wait doubles after every rebroadcast.
+	defaultTxRebroadcastInterval = time.Minute * 5
+
+	// defaultMaxTxRebroadcastInterval is the default maximum amount of
+	// time to wait in between rebroadcasts of an unconfirmed local
+	// transaction.
+	defaultMaxTxRebroadcastInterval = time.Hour * 2
+
+	// rebroadcastScanInterval is the amount of time to wait in between
+	// scans of the pending inventory for rebroadcasts which are due.
+	rebroadcastScanInterval = time.Second * 30
+)
+
+// rebroadcastEntry houses an inventory item which is rebroadcast until it makes
+// it into a block along with its rebroadcast schedule.
+type rebroadcastEntry struct {
+	data      interface{}
+	interval  time.Duration
+	nextRelay time.Time
+
+	// announced houses the IDs of the peers the inventory has already
+	// been rebroadcast to.  Peer IDs are never reused, so peers which
+	// reconnect are considered fresh.
+	announced map[int32]struct{}
+}
+
+// txRebroadcastState describes the rebroadcast settings along with the number
+// of inventory items pending rebroadcast.
+type txRebroadcastState struct {
+	enabled     bool
+	interval    time.Duration
+	maxInterval time.Duration
+	pending     int
+}
+
+// setTxRebroadcastMsg is a type used to change the rebroadcast settings.  Nil
+// fields leave the corresponding setting unchanged.  The resulting settings
+// are sent to the reply channel.
+type setTxRebroadcastMsg struct {
+	enable      *bool
+	interval    *time.Duration
+	maxInterval *time.Duration
+	reply       chan txRebroadcastState
+}
+
+// SetTxRebroadcast changes the settings of the rebroadcasting of unconfirmed
+// local transactions and returns the resulting settings.  Nil arguments leave
+// the corresponding setting unchanged.
+func (s *server) SetTxRebroadcast(enable *bool, interval, maxInterval *time.Duration) (txRebroadcastState, error) {
+	if atomic.LoadInt32(&s.shutdown) != 0 {
+		return txRebroadcastState{}, errors.New("server is shutting down")
+	}
+
+	reply := make(chan txRebroadcastState, 1)
+	msg := setTxRebroadcastMsg{
+		enable:      enable,
+		interval:    interval,
+		maxInterval: maxInterval,
+		reply:       reply,
+	}
+	select {
+	case s.modifyRebroadcastInv <- msg:
+	case <-s.quit:
+		return txRebroadcastState{}, errors.New("server is shutting down")
+	}
+	return <-reply, nil
+}
+
+// relayToNewPeers relays the passed pending inventory to the passed peers it
+// has not yet been rebroadcast to and returns the number of peers it was
+// relayed to.
+func (s *server) relayToNewPeers(peers []*serverPeer, iv *wire.InvVect, entry *rebroadcastEntry) int {
+	msg := relayMsg{invVect: iv, data: entry.data}
+	var relayed int
+	for _, sp := range peers {
+		if _, ok := entry.announced[sp.ID()]; ok {
+			continue
+		}
+		if s.relayInventoryToPeer(sp, msg) {
+			entry.announced[sp.ID()] = struct{}{}
+			relayed++
+		}
+	}
+	return relayed
+}
+
+// rebroadcastHandler keeps track of user submitted inventories that we have
+// sent out but have not yet made it into a block.  We periodically rebroadcast
+// them to peers which have not been sent them yet in case our peers restarted
+// or otherwise lost track of them, waiting twice as long after every
+// rebroadcast so long-lived transactions don't needlessly use bandwidth.
+func (s *server) rebroadcastHandler() {
+	state := txRebroadcastState{
+		enabled:     true,
+		interval:    defaultTxRebroadcastInterval,
+		maxInterval: defaultMaxTxRebroadcastInterval,
+	}
+	ticker := time.NewTicker(rebroadcastScanInterval)
+	pendingInvs := make(map[wire.InvVect]*rebroadcastEntry)
+
+out:
+	for {
+		select {
+		case riv := <-s.modifyRebroadcastInv:
+			switch msg := riv.(type) {
+			// Incoming InvVects are added to our map of RPC txs.
+			case broadcastInventoryAdd:
+				if _, ok := pendingInvs[*msg.invVect]; ok {
+					continue
+				}
+				srvrLog.Debugf("Add inventory : %v", msg.invVect)
+				pendingInvs[*msg.invVect] = &rebroadcastEntry{
+					data:      msg.data,
+					interval:  state.interval,
+					nextRelay: time.Now().Add(state.interval),
+					announced: make(map[int32]struct{}),
+				}
+
+			// When an InvVect has been added to a block, we can
+			// now remove it, if it was present.
+			case broadcastInventoryDel:
+				if _, ok := pendingInvs[*msg]; ok {
+					srvrLog.Debugf("Remove inventory : %v", msg)
+					delete(pendingInvs, *msg)
+				}
+
+			// Apply new settings.  Pending inventory which is
+			// waiting longer than the new maximum interval is
+			// rescheduled accordingly.
+			case setTxRebroadcastMsg:
+				if msg.enable != nil {
+					state.enabled = *msg.enable
+				}
+				if msg.interval != nil {
+					state.interval = *msg.interval
+				}
+				if msg.maxInterval != nil {
+					state.maxInterval = *msg.maxInterval
+				}
+				if state.interval > state.maxInterval {
+					state.interval = state.maxInterval
+				}
+				now := time.Now()
+				for _, entry := range pendingInvs {
+					if entry.interval <= state.maxInterval {
+						continue
+					}
+					entry.interval = state.maxInterval
+					if entry.nextRelay.After(now.Add(entry.interval)) {
+						entry.nextRelay = now.Add(entry.interval)
+					}
+				}
+				srvrLog.Infof("Transaction rebroadcasting enabled: "+
+					"%v, interval %v, max interval %v",
+					state.enabled, state.interval,
+					state.maxInterval)
+
+				state.pending = len(pendingInvs)
+				msg.reply <- state
+			}
+
+		case now := <-ticker.C:
+			if !state.enabled {
+				continue
+			}
+
+			// Any inventory we have has not made it into a block
+			// yet.  Resubmit the items which are due to peers which
+			// have not been sent them yet.
+			var peers []*serverPeer
+			for iv, entry := range pendingInvs {
+				if now.Before(entry.nextRelay) {
+					continue
+				}
+
+				// There is no point rebroadcasting transactions
+				// which are no longer in the memory pool, such as
+				// those which were double spent or evicted.
+				if iv.Type == wire.InvTypeTx &&
+					!s.txMemPool.HaveTransaction(&iv.Hash) {
+
+					srvrLog.Debugf("Remove inventory no longer "+
+						"in the mempool : %v", iv)
+					delete(pendingInvs, iv)
+					continue
+				}
+
+				if peers == nil {
+					peers = s.Peers()
+				}
+				ivCopy := iv
+				relayed := s.relayToNewPeers(peers, &ivCopy, entry)
+				srvrLog.Debugf("Relay inventory %v to %d new peers",
+					iv, relayed)
+
+				entry.interval *= 2
+				if entry.interval > state.maxInterval {
+					entry.interval = state.maxInterval
+				}
+				entry.nextRelay = now.Add(entry.interval)
+			}
+
+		case <-s.quit:
+			break out
+		}
+	}
+
+	ticker.Stop()
+
+	// Drain channels before exiting so nothing is left waiting around
+	// to send.
+cleanup:
+	for {
+		select {
+		case riv := <-s.modifyRebroadcastInv:
+			if msg, ok := riv.(setTxRebroadcastMsg); ok {
+				msg.reply <- state
+			}
+		default:
+			break cleanup
+		}
+	}
+	s.wg.Done()
+}
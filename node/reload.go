@@ -0,0 +1,219 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package node
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+
+	"github.com/HcashOrg/hcd/hcutil"
+	"github.com/HcashOrg/hcd/mempool"
+	flags "github.com/jessevdk/go-flags"
+)
+
+// cfgMtx protects the options of cfg which are changed when the configuration
+// is reloaded while the node is running.
+var cfgMtx sync.RWMutex
+
+// reloadSignals defines the signals to catch in order to reload the
+// configuration.  This may be modified during init depending on the platform.
+var reloadSignals []os.Signal
+
+// configuredMinRelayTxFee returns the minimum relay fee set with the
+// minrelaytxfee option, which does not include any increase of the memory pool
+// due to exceeding its maximum size.
+//
+// This function is safe for concurrent access.
+func configuredMinRelayTxFee() hcutil.Amount {
+	cfgMtx.RLock()
+	defer cfgMtx.RUnlock()
+	return cfg.minRelayTxFee
+}
+
+// persistentPeerAddrs returns the addresses of the peers the passed
+// configuration keeps connections to.
+func persistentPeerAddrs(c *Config) []string {
+	if len(c.ConnectPeers) > 0 {
+		return c.ConnectPeers
+	}
+	return c.AddPeers
+}
+
+// loadReloadableConfig parses the configuration file and the command line
+// options the node was started with again and validates the options which may
+// be changed by reloading the configuration.  Other options of the returned
+// config are parsed but neither validated nor derived, so they must not be
+// used.
+func loadReloadableConfig() (*Config, error) {
+	newCfg := Config{
+		MinRelayTxFee: mempool.DefaultMinRelayTxFee.ToCoin(),
+	}
+	parser := newConfigParser(&newCfg, &serviceOptions{}, flags.None)
+	if !cfg.SimNet || cfg.ConfigFile != defaultConfigFile {
+		err := flags.NewIniParser(parser).ParseFile(cfg.ConfigFile)
+		if err != nil {
+			if _, ok := err.(*os.PathError); !ok {
+				return nil, err
+			}
+		}
+	}
+	if _, err := parser.ParseArgs(cfg.args); err != nil {
+		return nil, err
+	}
+
+	var err error
+	newCfg.whitelists, err = parseIPNets(newCfg.Whitelists)
+	if err != nil {
+		return nil, fmt.Errorf("the whitelist value of %v", err)
+	}
+	newCfg.blacklists, err = parseIPNets(newCfg.Blacklists)
+	if err != nil {
+		return nil, fmt.Errorf("the blacklist value of %v", err)
+	}
+	if len(newCfg.AddPeers) > 0 && len(newCfg.ConnectPeers) > 0 {
+		return nil, errors.New("the --addpeer and --connect options " +
+			"can not be mixed")
+	}
+	if newCfg.RPCUser == newCfg.RPCLimitUser && newCfg.RPCUser != "" {
+		return nil, errors.New("--rpcuser and --rpclimituser must not " +
+			"specify the same username")
+	}
+	if newCfg.RPCPass == newCfg.RPCLimitPass && newCfg.RPCPass != "" {
+		return nil, errors.New("--rpcpass and --rpclimitpass must not " +
+			"specify the same password")
+	}
+	newCfg.minRelayTxFee, err = hcutil.NewAmount(newCfg.MinRelayTxFee)
+	if err != nil {
+		return nil, fmt.Errorf("invalid minrelaytxfee: %v", err)
+	}
+	newCfg.AddPeers = normalizeAddresses(newCfg.AddPeers,
+		activeNetParams.DefaultPort)
+	newCfg.ConnectPeers = normalizeAddresses(newCfg.ConnectPeers,
+		activeNetParams.DefaultPort)
+
+	return &newCfg, nil
+}
+
+// ReloadConfig reads the configuration file and the command line options the
+// node was started with again and applies the options which may change while
+// the node is running: the whitelisted and blacklisted networks, the RPC
+// credentials, the minimum relay fee and the peers given with the addpeer or
+// connect options.  All other options keep their values until the next
+// restart.  Nothing is changed when the new configuration is invalid.
+//
+// A changed minimum relay fee applies to the memory pool and to block templates
+// generated afterwards.  Peers which are already connected keep the fee filter
+// announced to them when they connected until they reconnect.
+func (s *server) ReloadConfig() error {
+	newCfg, err := loadReloadableConfig()
+	if err != nil {
+		return err
+	}
+
+	// Whether the RPC server runs and whether only the peers given with
+	// the connect option are used determine how the node is started, so
+	// they can't be changed by reloading the configuration.
+	rpcEnabled := (newCfg.RPCUser != "" && newCfg.RPCPass != "") ||
+		(newCfg.RPCLimitUser != "" && newCfg.RPCLimitPass != "") ||
+		!newCfg.NoRPCCookie
+	if rpcEnabled != (s.rpcServer != nil) {
+		return errors.New("the RPC server can't be enabled or disabled " +
+			"without a restart")
+	}
+	if (len(newCfg.ConnectPeers) > 0) != (len(cfg.ConnectPeers) > 0) {
+		return errors.New("the --connect option can't be added or " +
+			"removed without a restart")
+	}
+
+	cfgMtx.Lock()
+	oldPeers := persistentPeerAddrs(cfg)
+	cfg.Whitelists, cfg.whitelists = newCfg.Whitelists, newCfg.whitelists
+	cfg.Blacklists, cfg.blacklists = newCfg.Blacklists, newCfg.blacklists
+	cfg.RPCUser, cfg.RPCPass = newCfg.RPCUser, newCfg.RPCPass
+	cfg.RPCLimitUser = newCfg.RPCLimitUser
+	cfg.RPCLimitPass = newCfg.RPCLimitPass
+	cfg.MinRelayTxFee = newCfg.MinRelayTxFee
+	cfg.minRelayTxFee = newCfg.minRelayTxFee
+	cfg.AddPeers, cfg.ConnectPeers = newCfg.AddPeers, newCfg.ConnectPeers
+	cfgMtx.Unlock()
+
+	if s.rpcServer != nil {
+		s.rpcServer.setAuth(newCfg.RPCUser, newCfg.RPCPass,
+			newCfg.RPCLimitUser, newCfg.RPCLimitPass)
+	}
+	s.txMemPool.SetMinRelayTxFee(newCfg.minRelayTxFee)
+
+	// Connect to the peers which were added and disconnect the ones which
+	// were removed.  Removed peers which are not connected at the moment
+	// are only dropped after the next restart.
+	newPeers := persistentPeerAddrs(newCfg)
+	for _, addr := range newPeers {
+		if containsString(oldPeers, addr) {
+			continue
+		}
+		if err := s.ConnectNode(addr, true); err != nil {
+			srvrLog.Warnf("Unable to connect to persistent peer %s: %v",
+				addr, err)
+		}
+	}
+	for _, addr := range oldPeers {
+		if containsString(newPeers, addr) {
+			continue
+		}
+		if err := s.RemoveNodeByAddr(addr); err != nil {
+			srvrLog.Debugf("Unable to disconnect removed persistent "+
+				"peer %s: %v", addr, err)
+		}
+	}
+
+	srvrLog.Infof("Reloaded the configuration (%d whitelisted and %d "+
+		"blacklisted networks, minimum relay fee %v, %d persistent "+
+		"peers)", len(newCfg.whitelists), len(newCfg.blacklists),
+		newCfg.minRelayTxFee, len(newPeers))
+	return nil
+}
+
+// containsString returns whether the passed slice contains the passed string.
+func containsString(strs []string, str string) bool {
+	for _, s := range strs {
+		if s == str {
+			return true
+		}
+	}
+	return false
+}
+
+// reloadListener reloads the configuration of the passed server whenever one of
+// the reloadSignals is received until the passed context is done.
+func reloadListener(ctx context.Context, s *server) {
+	if len(reloadSignals) == 0 {
+		return
+	}
+
+	go func() {
+		reloadChannel := make(chan os.Signal, 1)
+		signal.Notify(reloadChannel, reloadSignals...)
+		defer signal.Stop(reloadChannel)
+
+		for {
+			select {
+			case sig := <-reloadChannel:
+				hcdLog.Infof("Received signal (%s).  Reloading "+
+					"the configuration...", sig)
+				if err := s.ReloadConfig(); err != nil {
+					hcdLog.Errorf("Unable to reload the "+
+						"configuration: %v", err)
+				}
+
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
@@ -0,0 +1,228 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package node
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	// rpcCertOrganization is the organization of the certificates generated
+	// by the RPC server.  Only certificates with this organization are
+	// regenerated to add extra hosts or to rotate them.
+	rpcCertOrganization = "hcd autogenerated cert"
+
+	// rpcCertCheckInterval is the interval at which the expiry of the RPC
+	// certificates is checked when rotation is enabled.
+	rpcCertCheckInterval = time.Hour
+)
+
+// rpcCert houses the TLS certificate of a set of RPC listeners.  The listeners
+// look up the certificate for each new connection, so a rotated certificate
+// is used without restarting them.
+type rpcCert struct {
+	certFile string
+	keyFile  string
+
+	mtx     sync.RWMutex
+	cert    *tls.Certificate
+	leaf    *x509.Certificate
+	certPEM []byte
+}
+
+// newRPCCert returns the certificate stored in the passed files.  The
+// certificate is generated when neither of the files exists, and an
+// auto-generated certificate is regenerated when it does not cover all of the
+// configured extra hosts.
+func newRPCCert(certFile, keyFile string) (*rpcCert, error) {
+	c := &rpcCert{certFile: certFile, keyFile: keyFile}
+
+	// Generate the TLS cert and key file if both don't already exist.
+	if !fileExists(keyFile) && !fileExists(certFile) {
+		err := genCertPair(certFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+
+	if host := c.missingHost(cfg.RPCExtraHosts); host != "" &&
+		c.autoGenerated() {
+
+		rpcsLog.Infof("Regenerating TLS certificate %s to add host %s",
+			certFile, host)
+		if err := c.regenerate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// load loads the certificate and key from the files of the certificate.
+func (c *rpcCert) load() error {
+	certPEM, err := ioutil.ReadFile(c.certFile)
+	if err != nil {
+		return err
+	}
+	keyPEM, err := ioutil.ReadFile(c.keyFile)
+	if err != nil {
+		return err
+	}
+	keypair, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return err
+	}
+	leaf, err := x509.ParseCertificate(keypair.Certificate[0])
+	if err != nil {
+		return err
+	}
+
+	c.mtx.Lock()
+	c.cert = &keypair
+	c.leaf = leaf
+	c.certPEM = certPEM
+	c.mtx.Unlock()
+	return nil
+}
+
+// regenerate generates a new certificate in place of the current one and
+// loads it.
+func (c *rpcCert) regenerate() error {
+	if err := genCertPair(c.certFile, c.keyFile); err != nil {
+		return err
+	}
+	return c.load()
+}
+
+// getCertificate returns the current certificate.  It is used as the
+// GetCertificate function of the TLS configuration of the listeners.
+func (c *rpcCert) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.mtx.RLock()
+	cert := c.cert
+	c.mtx.RUnlock()
+	return cert, nil
+}
+
+// pem returns the PEM-encoded current certificate along with its expiry.
+func (c *rpcCert) pem() ([]byte, time.Time) {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+	return c.certPEM, c.leaf.NotAfter
+}
+
+// autoGenerated returns whether the current certificate was generated by the
+// RPC server, as opposed to being provided by the user.
+func (c *rpcCert) autoGenerated() bool {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+	for _, org := range c.leaf.Subject.Organization {
+		if org == rpcCertOrganization {
+			return true
+		}
+	}
+	return false
+}
+
+// missingHost returns the first of the passed hosts which the current
+// certificate is not valid for, or an empty string when it is valid for all of
+// them.  Hosts may include a port, which is ignored.
+func (c *rpcCert) missingHost(hosts []string) string {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+	for _, hostStr := range hosts {
+		host, _, err := net.SplitHostPort(hostStr)
+		if err != nil {
+			host = hostStr
+		}
+		if c.leaf.VerifyHostname(host) != nil {
+			return host
+		}
+	}
+	return ""
+}
+
+// needsRotation returns whether the current certificate is auto-generated and
+// expires within the configured rotation period after the passed time.
+func (c *rpcCert) needsRotation(now time.Time) bool {
+	if cfg.RPCCertRotate <= 0 || !c.autoGenerated() {
+		return false
+	}
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+	return c.leaf.NotAfter.Sub(now) < cfg.RPCCertRotate
+}
+
+// rpcTLSListenFunc returns a function which listens for TLS connections using
+// the passed certificate.
+func rpcTLSListenFunc(c *rpcCert) func(string, string) (net.Listener, error) {
+	tlsConfig := tls.Config{
+		GetCertificate: c.getCertificate,
+		MinVersion:     tls.VersionTLS12,
+	}
+	return func(net string, laddr string) (net.Listener, error) {
+		return tls.Listen(net, laddr, &tlsConfig)
+	}
+}
+
+// listenerCert returns the certificate of the public or the other RPC
+// listeners.  It is nil when TLS is disabled.
+func (s *rpcServer) listenerCert(public bool) *rpcCert {
+	if public {
+		return s.publicCert
+	}
+	return s.cert
+}
+
+// rotateCerts regenerates the auto-generated RPC certificates which are about
+// to expire and notifies the websocket clients of the listeners using them.
+func (s *rpcServer) rotateCerts() {
+	certs := []*rpcCert{s.cert}
+	if s.publicCert != s.cert {
+		certs = append(certs, s.publicCert)
+	}
+	now := time.Now()
+	for _, c := range certs {
+		if !c.needsRotation(now) {
+			continue
+		}
+
+		rpcsLog.Infof("Rotating TLS certificate %s", c.certFile)
+		if err := c.regenerate(); err != nil {
+			rpcsLog.Errorf("Failed to rotate TLS certificate %s: %v",
+				c.certFile, err)
+			continue
+		}
+		s.ntfnMgr.NotifyCertRotated(c)
+	}
+}
+
+// certRotationHandler periodically rotates the RPC certificates which are about
+// to expire until the RPC server is shut down.
+//
+// This function MUST be run as a goroutine.
+func (s *rpcServer) certRotationHandler() {
+	ticker := time.NewTicker(rpcCertCheckInterval)
+	defer ticker.Stop()
+
+out:
+	for {
+		s.rotateCerts()
+
+		select {
+		case <-ticker.C:
+		case <-s.quit:
+			break out
+		}
+	}
+	s.wg.Done()
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package node
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btclog"
+)
+
+// TestRPCCert ensures auto-generated RPC certificates are generated with the
+// configured validity, are rotated once they expire within the rotation period
+// and are regenerated to cover the configured extra hosts.
+func TestRPCCert(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rpccert")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	oldCfg, oldLog := cfg, rpcsLog
+	defer func() { cfg, rpcsLog = oldCfg, oldLog }()
+	rpcsLog = btclog.Disabled
+	cfg = &Config{RPCCertValidity: 10 * 24 * time.Hour}
+
+	certFile := filepath.Join(dir, "rpc.cert")
+	keyFile := filepath.Join(dir, "rpc.key")
+	c, err := newRPCCert(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("newRPCCert: unexpected error: %v", err)
+	}
+	if !c.autoGenerated() {
+		t.Fatal("generated certificate is not auto-generated")
+	}
+	certPEM, notAfter := c.pem()
+	if d := time.Until(notAfter); d < 9*24*time.Hour || d > 10*24*time.Hour {
+		t.Fatalf("unexpected validity of generated certificate: %v", d)
+	}
+	if c.needsRotation(time.Now()) {
+		t.Fatal("certificate needs rotation with rotation disabled")
+	}
+
+	// The certificate needs rotation once it expires within the rotation
+	// period, and no longer after it was regenerated with a validity
+	// period exceeding it.
+	cfg.RPCCertRotate = 20 * 24 * time.Hour
+	if !c.needsRotation(time.Now()) {
+		t.Fatal("certificate does not need rotation")
+	}
+	cfg.RPCCertValidity = 30 * 24 * time.Hour
+	if err := c.regenerate(); err != nil {
+		t.Fatalf("regenerate: unexpected error: %v", err)
+	}
+	if c.needsRotation(time.Now()) {
+		t.Fatal("rotated certificate needs rotation")
+	}
+	rotatedPEM, _ := c.pem()
+	if bytes.Equal(rotatedPEM, certPEM) {
+		t.Fatal("certificate was not rotated")
+	}
+	cert, _ := c.getCertificate(nil)
+	if !bytes.Equal(cert.Certificate[0], c.leaf.Raw) {
+		t.Fatal("listeners do not use the rotated certificate")
+	}
+	filePEM, err := ioutil.ReadFile(certFile)
+	if err != nil {
+		t.Fatalf("unable to read certificate file: %v", err)
+	}
+	if !bytes.Equal(filePEM, rotatedPEM) {
+		t.Fatal("certificate file does not contain rotated certificate")
+	}
+
+	// Loading the certificate with extra hosts it does not cover must
+	// regenerate it.
+	cfg.RPCExtraHosts = []string{"hcd.example.com", "192.0.2.1:14009"}
+	if host := c.missingHost(cfg.RPCExtraHosts); host != "hcd.example.com" {
+		t.Fatalf("unexpected missing host: got %q", host)
+	}
+	c, err = newRPCCert(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("newRPCCert: unexpected error: %v", err)
+	}
+	if host := c.missingHost(cfg.RPCExtraHosts); host != "" {
+		t.Fatalf("regenerated certificate does not cover host %s", host)
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package node
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+)
+
+// rpcCookieUser is the username of the credentials in the RPC authentication
+// cookie file.
+const rpcCookieUser = "__cookie__"
+
+// writeRPCCookie writes admin credentials with a new random password to the
+// RPC authentication cookie file at the passed path and returns the password.
+// Only the owner of the file may read it, so local tools run by the same user
+// can authenticate without configuring any credentials.
+func writeRPCCookie(path string) (string, error) {
+	var secret [32]byte
+	if _, err := rand.Read(secret[:]); err != nil {
+		return "", err
+	}
+	pass := hex.EncodeToString(secret[:])
+
+	// Write to a temporary file which is then renamed so clients never
+	// read a partially written cookie.
+	tmpPath := path + ".tmp"
+	err := ioutil.WriteFile(tmpPath, []byte(rpcCookieUser+":"+pass), 0600)
+	if err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return "", err
+	}
+	return pass, nil
+}
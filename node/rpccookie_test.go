@@ -0,0 +1,60 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package node
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// TestWriteRPCCookie ensures the RPC authentication cookie is written with the
+// cookie username and a new random password that only its owner may read.
+func TestWriteRPCCookie(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rpccookie")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, ".cookie")
+	pass, err := writeRPCCookie(path)
+	if err != nil {
+		t.Fatalf("writeRPCCookie: unexpected error: %v", err)
+	}
+	if len(pass) != 64 {
+		t.Fatalf("unexpected password length: got %d, want 64", len(pass))
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unable to read cookie file: %v", err)
+	}
+	if want := rpcCookieUser + ":" + pass; string(content) != want {
+		t.Fatalf("unexpected cookie: got %q, want %q", content, want)
+	}
+	if runtime.GOOS != "windows" {
+		fi, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("unable to stat cookie file: %v", err)
+		}
+		if perm := fi.Mode().Perm(); perm != 0600 {
+			t.Fatalf("unexpected cookie file mode: got %v", perm)
+		}
+	}
+
+	// Rewriting the cookie must replace the password.
+	newPass, err := writeRPCCookie(path)
+	if err != nil {
+		t.Fatalf("writeRPCCookie: unexpected error: %v", err)
+	}
+	if newPass == pass {
+		t.Fatal("rewritten cookie has the same password")
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Fatalf("temporary cookie file left behind: %v", err)
+	}
+}
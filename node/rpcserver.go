@@ -4,14 +4,13 @@
 // Use of this source code is governed by an ISC
 // license that can be found in the LICENSE file.
 
-package main
+package node
 
 import (
 	"bytes"
 	"crypto/elliptic"
 	"crypto/sha256"
 	"crypto/subtle"
-	"crypto/tls"
 	"encoding/base64"
 	"encoding/binary"
 	"encoding/hex"
@@ -26,6 +25,8 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"path/filepath"
+	"runtime"
 	"sort"
 	"strconv"
 	"strings"
@@ -36,7 +37,9 @@ import (
 	"github.com/btcsuite/websocket"
 
 	"github.com/HcashOrg/bitset"
+	"github.com/HcashOrg/hcd/addrmgr"
 	"github.com/HcashOrg/hcd/blockchain"
+	"github.com/HcashOrg/hcd/blockchain/indexers"
 	"github.com/HcashOrg/hcd/blockchain/stake"
 	"github.com/HcashOrg/hcd/chaincfg"
 	"github.com/HcashOrg/hcd/chaincfg/chainec"
@@ -45,8 +48,12 @@ import (
 	"github.com/HcashOrg/hcd/database"
 	"github.com/HcashOrg/hcd/hcjson"
 	"github.com/HcashOrg/hcd/hcutil"
+	"github.com/HcashOrg/hcd/hcutil/psht"
+	"github.com/HcashOrg/hcd/lockprof"
 	"github.com/HcashOrg/hcd/mempool"
 	"github.com/HcashOrg/hcd/mining"
+	"github.com/HcashOrg/hcd/peer"
+	"github.com/HcashOrg/hcd/tracing"
 	"github.com/HcashOrg/hcd/txscript"
 	"github.com/HcashOrg/hcd/wire"
 )
@@ -172,81 +179,136 @@ var (
 
 type commandHandler func(*rpcServer, interface{}, <-chan struct{}) (interface{}, error)
 
+// tracedCommandHandler is a command handler which records the operations it
+// triggers as children of the span of the request.  The span is nil when
+// tracing is disabled.
+type tracedCommandHandler func(*rpcServer, interface{}, *tracing.Span, <-chan struct{}) (interface{}, error)
+
+// rpcTracedHandlers maps RPC command strings to the handler functions which
+// propagate the trace of the request into the subsystems they call.  They take
+// precedence over the entries of rpcHandlers, which use them without a span.
+var rpcTracedHandlers = map[string]tracedCommandHandler{
+	"sendrawtransaction": handleSendRawTransaction,
+	"submitblock":        handleSubmitBlock,
+}
+
+// untraced returns a command handler which calls the passed traced handler
+// without a span.
+func untraced(handler tracedCommandHandler) commandHandler {
+	return func(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+		return handler(s, cmd, nil, closeChan)
+	}
+}
+
 // rpcHandlers maps RPC command strings to appropriate handler functions.
 // This is set by init because help references rpcHandlers and thus causes
 // a dependency loop.
 var rpcHandlers map[string]commandHandler
 var rpcHandlersBeforeInit = map[string]commandHandler{
-	"addnode":               handleAddNode,
-	"createrawsstx":         handleCreateRawSStx,
-	"createrawssgentx":      handleCreateRawSSGenTx,
-	"createrawssrtx":        handleCreateRawSSRtx,
-	"createrawtransaction":  handleCreateRawTransaction,
-	"debuglevel":            handleDebugLevel,
-	"decoderawtransaction":  handleDecodeRawTransaction,
-	"decodescript":          handleDecodeScript,
-	"estimatefee":           handleEstimateFee,
-	"estimatestakediff":     handleEstimateStakeDiff,
-	"existsaddress":         handleExistsAddress,
-	"existsaddresses":       handleExistsAddresses,
-	"existsmissedtickets":   handleExistsMissedTickets,
-	"existsexpiredtickets":  handleExistsExpiredTickets,
-	"existsliveticket":      handleExistsLiveTicket,
-	"existslivetickets":     handleExistsLiveTickets,
-	"existsmempooltxs":      handleExistsMempoolTxs,
-	"generate":              handleGenerate,
-	"getaddednodeinfo":      handleGetAddedNodeInfo,
-	"getbestblock":          handleGetBestBlock,
-	"getbestblockhash":      handleGetBestBlockHash,
-	"getblock":              handleGetBlock,
-	"getblockcount":         handleGetBlockCount,
-	"getblockhash":          handleGetBlockHash,
-	"getblockheader":        handleGetBlockHeader,
-	"getblocksubsidy":       handleGetBlockSubsidy,
-	"getcoinsupply":         handleGetCoinSupply,
-	"getconnectioncount":    handleGetConnectionCount,
-	"getcurrentnet":         handleGetCurrentNet,
-	"getdifficulty":         handleGetDifficulty,
-	"getgenerate":           handleGetGenerate,
-	"gethashespersec":       handleGetHashesPerSec,
-	"getheaders":            handleGetHeaders,
-	"getinfo":               handleGetInfo,
-	"getblockchaininfo":     handleGetBlockchainInfo,
-	"getmempoolinfo":        handleGetMempoolInfo,
-	"getmininginfo":         handleGetMiningInfo,
-	"getnettotals":          handleGetNetTotals,
-	"getnetworkhashps":      handleGetNetworkHashPS,
-	"getpeerinfo":           handleGetPeerInfo,
-	"getrawmempool":         handleGetRawMempool,
-	"getrawtransaction":     handleGetRawTransaction,
-	"getstakedifficulty":    handleGetStakeDifficulty,
-	"getstakeversioninfo":   handleGetStakeVersionInfo,
-	"getstakeversions":      handleGetStakeVersions,
-	"getticketpoolvalue":    handleGetTicketPoolValue,
-	"getvoteinfo":           handleGetVoteInfo,
-	"gettxout":              handleGetTxOut,
-	"getwork":               handleGetWork,
-	"help":                  handleHelp,
-	"livetickets":           handleLiveTickets,
-	"missedtickets":         handleMissedTickets,
-	"node":                  handleNode,
-	"ping":                  handlePing,
-	"searchrawtransactions": handleSearchRawTransactions,
-	"rebroadcastmissed":     handleRebroadcastMissed,
-	"rebroadcastwinners":    handleRebroadcastWinners,
-	"sendrawtransaction":    handleSendRawTransaction,
-	"setgenerate":           handleSetGenerate,
-	"stop":                  handleStop,
-	"submitblock":           handleSubmitBlock,
-	"ticketfeeinfo":         handleTicketFeeInfo,
-	"ticketsforaddress":     handleTicketsForAddress,
-	"ticketvwap":            handleTicketVWAP,
-	"txfeeinfo":             handleTxFeeInfo,
-	"validateaddress":       handleValidateAddress,
-	"verifychain":           handleVerifyChain,
-	"verifymessage":         handleVerifyMessage,
-	"verifyblissmessage":    handleVerifyBlissMessage,
-	"version":               handleVersion,
+	"addnode":                     handleAddNode,
+	"combinepsht":                 handleCombinePsht,
+	"createmultisig":              handleCreateMultisig,
+	"createrawscript":             handleCreateRawScript,
+	"createrawsstx":               handleCreateRawSStx,
+	"createrawssgentx":            handleCreateRawSSGenTx,
+	"createrawssrtx":              handleCreateRawSSRtx,
+	"createrawtransaction":        handleCreateRawTransaction,
+	"debuglevel":                  handleDebugLevel,
+	"dumpblockchain":              handleDumpBlockChain,
+	"decodepsht":                  handleDecodePsht,
+	"decoderawtransaction":        handleDecodeRawTransaction,
+	"decodescript":                handleDecodeScript,
+	"describerpc":                 handleDescribeRPC,
+	"disconnectnode":              handleDisconnectNode,
+	"estimatefee":                 handleEstimateFee,
+	"estimatestakediff":           handleEstimateStakeDiff,
+	"existsaddress":               handleExistsAddress,
+	"existsaddresses":             handleExistsAddresses,
+	"existsmissedtickets":         handleExistsMissedTickets,
+	"existsexpiredtickets":        handleExistsExpiredTickets,
+	"existsliveticket":            handleExistsLiveTicket,
+	"existslivetickets":           handleExistsLiveTickets,
+	"existsmempooltxs":            handleExistsMempoolTxs,
+	"existstxs":                   handleExistsTxs,
+	"finalizepsht":                handleFinalizePsht,
+	"generate":                    handleGenerate,
+	"getaddednodeinfo":            handleGetAddedNodeInfo,
+	"getbestblock":                handleGetBestBlock,
+	"getbestblockhash":            handleGetBestBlockHash,
+	"getblock":                    handleGetBlock,
+	"getblockcount":               handleGetBlockCount,
+	"getblockhash":                handleGetBlockHash,
+	"getblockheader":              handleGetBlockHeader,
+	"getblockheaders":             handleGetBlockHeaders,
+	"getblockreceivedtime":        handleGetBlockReceivedTime,
+	"getblocksubsidy":             handleGetBlockSubsidy,
+	"getblockundo":                handleGetBlockUndo,
+	"getchainstats":               handleGetChainStats,
+	"getchaintips":                handleGetChainTips,
+	"getcheckpointcandidates":     handleGetCheckpointCandidates,
+	"getcoinsupply":               handleGetCoinSupply,
+	"getconnectioncount":          handleGetConnectionCount,
+	"getcurrentnet":               handleGetCurrentNet,
+	"getdifficulty":               handleGetDifficulty,
+	"getgenerate":                 handleGetGenerate,
+	"gethashespersec":             handleGetHashesPerSec,
+	"gethealth":                   handleGetHealth,
+	"getlockstats":                handleGetLockStats,
+	"getmemoryinfo":               handleGetMemoryInfo,
+	"getprofile":                  handleGetProfile,
+	"getslowqueries":              handleGetSlowQueries,
+	"getheaders":                  handleGetHeaders,
+	"getindexinfo":                handleGetIndexInfo,
+	"getinfo":                     handleGetInfo,
+	"getblockchaininfo":           handleGetBlockchainInfo,
+	"getmempoolentry":             handleGetMempoolEntry,
+	"getmempoolinfo":              handleGetMempoolInfo,
+	"getmininginfo":               handleGetMiningInfo,
+	"getnettotals":                handleGetNetTotals,
+	"getnetworkhashps":            handleGetNetworkHashPS,
+	"getnetworkupgradeinfo":       handleGetNetworkUpgradeInfo,
+	"getpeerinfo":                 handleGetPeerInfo,
+	"getrawmempool":               handleGetRawMempool,
+	"getrawtransaction":           handleGetRawTransaction,
+	"getrevocabletickets":         handleGetRevocableTickets,
+	"getstakedifficulty":          handleGetStakeDifficulty,
+	"getstakeversioninfo":         handleGetStakeVersionInfo,
+	"getstakeversions":            handleGetStakeVersions,
+	"getticketpoolvalue":          handleGetTicketPoolValue,
+	"getvoteinfo":                 handleGetVoteInfo,
+	"gettxout":                    handleGetTxOut,
+	"getutxoset":                  handleGetUtxoSet,
+	"getwork":                     handleGetWork,
+	"help":                        handleHelp,
+	"livetickets":                 handleLiveTickets,
+	"missedtickets":               handleMissedTickets,
+	"node":                        handleNode,
+	"ping":                        handlePing,
+	"prioritisetransaction":       handlePrioritiseTransaction,
+	"searchrawtransactions":       handleSearchRawTransactions,
+	"reconsiderblock":             handleReconsiderBlock,
+	"rebroadcastmissed":           handleRebroadcastMissed,
+	"rebroadcastwinners":          handleRebroadcastWinners,
+	"reloadconfig":                handleReloadConfig,
+	"sendrawtransaction":          untraced(handleSendRawTransaction),
+	"setgenerate":                 handleSetGenerate,
+	"setmaxpeers":                 handleSetMaxPeers,
+	"settxrebroadcast":            handleSetTxRebroadcast,
+	"startindexrebuild":           handleStartIndexRebuild,
+	"stop":                        handleStop,
+	"submitblock":                 untraced(handleSubmitBlock),
+	"submitrawtransactionpackage": handleSubmitRawTransactionPackage,
+	"ticketfeeinfo":               handleTicketFeeInfo,
+	"tracescript":                 handleTraceScript,
+	"ticketsforaddress":           handleTicketsForAddress,
+	"ticketvwap":                  handleTicketVWAP,
+	"txfeeinfo":                   handleTxFeeInfo,
+	"validateaddress":             handleValidateAddress,
+	"verifychain":                 handleVerifyChain,
+	"verifyheaderchain":           handleVerifyHeaderChain,
+	"verifymessage":               handleVerifyMessage,
+	"verifyblissmessage":          handleVerifyBlissMessage,
+	"version":                     handleVersion,
 }
 
 // list of commands that we recognize, but for which hcd has no support because
@@ -258,7 +320,6 @@ var rpcAskWallet = map[string]struct{}{
 	"addmultisigaddress":      {},
 	"addticket":               {},
 	"createencryptedwallet":   {},
-	"createmultisig":          {},
 	"dumpprivkey":             {},
 	"getaccount":              {},
 	"getaccountaddress":       {},
@@ -305,10 +366,15 @@ var rpcUnimplemented = map[string]struct{}{
 	"estimatepriority":  {},
 	"getblocktemplate":  {},
 	"getblockchaininfo": {},
-	"getchaintips":      {},
 	"getnetworkinfo":    {},
 }
 
+// Commands that are not listed in the help usage since they are only meant
+// for maintenance by the developers.
+var rpcHidden = map[string]struct{}{
+	"getcheckpointcandidates": {},
+}
+
 // Commands that are available to a limited user
 var rpcLimited = map[string]struct{}{
 	// Websockets commands
@@ -323,29 +389,47 @@ var rpcLimited = map[string]struct{}{
 	"help": {},
 
 	// HTTP/S-only commands
-	"createrawtransaction":  {},
-	"decoderawtransaction":  {},
-	"decodescript":          {},
-	"getbestblock":          {},
-	"getbestblockhash":      {},
-	"getblock":              {},
-	"getblockcount":         {},
-	"getblockhash":          {},
-	"getcurrentnet":         {},
-	"getdifficulty":         {},
-	"getinfo":               {},
-	"getnettotals":          {},
-	"getnetworkhashps":      {},
-	"getrawmempool":         {},
-	"getrawtransaction":     {},
-	"gettxout":              {},
-	"searchrawtransactions": {},
-	"sendrawtransaction":    {},
-	"submitblock":           {},
-	"validateaddress":       {},
-	"verifymessage":         {},
-	"verifyblissmessage":    {},
-	"version":               {},
+	"combinepsht":                 {},
+	"createmultisig":              {},
+	"createrawscript":             {},
+	"createrawtransaction":        {},
+	"decodepsht":                  {},
+	"decoderawtransaction":        {},
+	"decodescript":                {},
+	"describerpc":                 {},
+	"finalizepsht":                {},
+	"getbestblock":                {},
+	"getbestblockhash":            {},
+	"getblock":                    {},
+	"getblockcount":               {},
+	"getblockhash":                {},
+	"getblockheaders":             {},
+	"getblockreceivedtime":        {},
+	"getblockundo":                {},
+	"getchainstats":               {},
+	"getcurrentnet":               {},
+	"getdifficulty":               {},
+	"gethealth":                   {},
+	"getindexinfo":                {},
+	"getinfo":                     {},
+	"getmempoolentry":             {},
+	"getnettotals":                {},
+	"getnetworkhashps":            {},
+	"getnetworkupgradeinfo":       {},
+	"getrawmempool":               {},
+	"getrawtransaction":           {},
+	"getrevocabletickets":         {},
+	"gettxout":                    {},
+	"searchrawtransactions":       {},
+	"sendrawtransaction":          {},
+	"submitblock":                 {},
+	"submitrawtransactionpackage": {},
+	"tracescript":                 {},
+	"validateaddress":             {},
+	"verifyheaderchain":           {},
+	"verifymessage":               {},
+	"verifyblissmessage":          {},
+	"version":                     {},
 }
 
 // builderScript is a convenience function which is used for hard-coded scripts
@@ -592,6 +676,47 @@ func handleNode(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (inter
 	return nil, nil
 }
 
+// handleDisconnectNode handles disconnectnode commands.
+func handleDisconnectNode(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*hcjson.DisconnectNodeCmd)
+
+	var addr string
+	if c.Address != nil {
+		addr = *c.Address
+	}
+	var err error
+	switch {
+	case addr != "" && c.NodeID != nil:
+		return nil, rpcInvalidError("Only one of address and nodeid " +
+			"may be provided")
+	case addr != "":
+		addr = normalizeAddress(addr, activeNetParams.DefaultPort)
+		err = s.server.DisconnectNodeByAddr(addr)
+	case c.NodeID != nil:
+		err = s.server.DisconnectNodeByID(*c.NodeID)
+	default:
+		return nil, rpcInvalidError("Either address or nodeid must be " +
+			"provided")
+	}
+	if err != nil {
+		var nodeID int32 = -1
+		if c.NodeID != nil {
+			nodeID = *c.NodeID
+		}
+		if peerExists(s.server.Peers(), addr, nodeID) {
+			return nil, rpcMiscError("Can't disconnect a " +
+				"permanent peer, use addnode remove")
+		}
+		return nil, &hcjson.RPCError{
+			Code:    hcjson.ErrRPCClientNotConnected,
+			Message: "Node not found in connected nodes",
+		}
+	}
+
+	// no data returned unless an error.
+	return nil, nil
+}
+
 // peerExists determines if a certain peer is currently connected given
 // information about all currently connected peers. Peer existence is
 // determined using either a target address or node id.
@@ -616,6 +741,258 @@ func messageToHex(msg wire.Message) (string, error) {
 	return hex.EncodeToString(buf.Bytes()), nil
 }
 
+// decodePsht deserializes a base64-encoded partially signed transaction
+// passed to an RPC.
+func decodePsht(b64 string) (*psht.Packet, error) {
+	p, err := psht.NewFromRawBytes(strings.NewReader(b64), true)
+	if err != nil {
+		return nil, rpcDeserializationError("Could not decode PSHT: %v",
+			err)
+	}
+	return p, nil
+}
+
+// encodePsht returns the base64 encoding of a partially signed transaction
+// returned from an RPC.
+func encodePsht(p *psht.Packet) (string, error) {
+	b64, err := p.B64Encode()
+	if err != nil {
+		context := "Failed to encode PSHT"
+		return "", rpcInternalError(err.Error(), context)
+	}
+	return b64, nil
+}
+
+// handleCombinePsht implements the combinepsht command.
+func handleCombinePsht(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*hcjson.CombinePshtCmd)
+
+	if len(c.Pshts) == 0 {
+		return nil, rpcInvalidError("At least one PSHT is required")
+	}
+	packets := make([]*psht.Packet, 0, len(c.Pshts))
+	for _, b64 := range c.Pshts {
+		p, err := decodePsht(b64)
+		if err != nil {
+			return nil, err
+		}
+		packets = append(packets, p)
+	}
+
+	combined, err := psht.Combine(packets...)
+	if err != nil {
+		return nil, rpcInvalidError("Unable to combine PSHTs: %v", err)
+	}
+	return encodePsht(combined)
+}
+
+// addressSigTypes are the signature types addresses may be created for, in the
+// order of preference.
+var addressSigTypes = []int{
+	chainec.ECTypeSecp256k1,
+	chainec.ECTypeEdwards,
+	chainec.ECTypeSecSchnorr,
+	bliss.BSTypeBliss,
+}
+
+// sigTypeName returns the name of the signature scheme registered with the
+// passed signature type, or an empty string if there is none.
+func sigTypeName(sigType int) string {
+	if sigType < 0 || sigType > 255 {
+		return ""
+	}
+	scheme, ok := txscript.LookupSignatureScheme(uint8(sigType))
+	if !ok {
+		return ""
+	}
+	return scheme.Name
+}
+
+// pubKeyAddress is an address which commits to a public key and may therefore
+// be converted to a pay-to-pubkey-hash address.
+type pubKeyAddress interface {
+	hcutil.Address
+	AddressPubKeyHash() *hcutil.AddressPubKeyHash
+}
+
+// decodePubKeyAddress decodes the passed public key for the passed signature
+// type into an address.  The public key may either be a hex-encoded serialized
+// public key or an encoded public key address.
+func decodePubKeyAddress(key string, sigType int, params *chaincfg.Params) (pubKeyAddress, error) {
+	serialized, err := hex.DecodeString(key)
+	if err != nil {
+		addr, err := hcutil.DecodeAddress(key)
+		if err != nil || !addr.IsForNet(params) {
+			return nil, rpcAddressKeyError("Invalid public key or "+
+				"address %q", key)
+		}
+		pkAddr, ok := addr.(pubKeyAddress)
+		if !ok || addr.DSA(params) != sigType {
+			return nil, rpcAddressKeyError("Address %q is not a %s "+
+				"public key address", key, sigTypeName(sigType))
+		}
+		return pkAddr, nil
+	}
+
+	var addr pubKeyAddress
+	switch sigType {
+	case chainec.ECTypeSecp256k1:
+		addr, err = hcutil.NewAddressSecpPubKey(serialized, params)
+	case chainec.ECTypeEdwards:
+		addr, err = hcutil.NewAddressEdwardsPubKey(serialized, params)
+	case chainec.ECTypeSecSchnorr:
+		addr, err = hcutil.NewAddressSecSchnorrPubKey(serialized, params)
+	case bliss.BSTypeBliss:
+		addr, err = hcutil.NewAddressBlissPubKey(serialized, params)
+	default:
+		return nil, rpcInvalidError("Unsupported signature type %d",
+			sigType)
+	}
+	if err != nil {
+		return nil, rpcAddressKeyError("Invalid %s public key %q: %v",
+			sigTypeName(sigType), key, err)
+	}
+	return addr, nil
+}
+
+// multiSigRedeemScript returns a multisignature script which requires
+// nRequired signatures of the passed secp256k1 public keys.
+func multiSigRedeemScript(s *rpcServer, keys []string, nRequired int) ([]byte, error) {
+	if len(keys) == 0 || len(keys) > txscript.MaxPubKeysPerMultiSig {
+		return nil, rpcInvalidError("Number of keys must be between "+
+			"1 and %d", txscript.MaxPubKeysPerMultiSig)
+	}
+	if nRequired < 1 || nRequired > len(keys) {
+		return nil, rpcInvalidError("Number of required signatures "+
+			"must be between 1 and the number of keys (%d)",
+			len(keys))
+	}
+
+	pubKeys := make([]hcutil.Address, 0, len(keys))
+	for _, key := range keys {
+		addr, err := decodePubKeyAddress(key, chainec.ECTypeSecp256k1,
+			s.server.chainParams)
+		if err != nil {
+			return nil, err
+		}
+		pubKeys = append(pubKeys, addr)
+	}
+
+	script, err := txscript.MultiSigScript(pubKeys, nRequired)
+	if err != nil {
+		return nil, rpcInternalError(err.Error(),
+			"Failed to create multisig script")
+	}
+	return script, nil
+}
+
+// handleCreateMultisig handles createmultisig commands.
+func handleCreateMultisig(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*hcjson.CreateMultisigCmd)
+
+	script, err := multiSigRedeemScript(s, c.Keys, c.NRequired)
+	if err != nil {
+		return nil, err
+	}
+	p2sh, err := hcutil.NewAddressScriptHash(script, s.server.chainParams)
+	if err != nil {
+		return nil, rpcInternalError(err.Error(),
+			"Failed to convert script to pay-to-script-hash")
+	}
+
+	return hcjson.CreateMultiSigResult{
+		Address:      p2sh.EncodeAddress(),
+		RedeemScript: hex.EncodeToString(script),
+	}, nil
+}
+
+// handleCreateRawScript handles createrawscript commands.
+func handleCreateRawScript(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*hcjson.CreateRawScriptCmd)
+	params := s.server.chainParams
+
+	// Look up the requested signature scheme among those addresses may be
+	// created for.
+	sigType := -1
+	for _, t := range addressSigTypes {
+		if sigTypeName(t) == *c.SigType {
+			sigType = t
+			break
+		}
+	}
+	if sigType == -1 {
+		return nil, rpcInvalidError("Unsupported signature type %q",
+			*c.SigType)
+	}
+
+	var result hcjson.CreateRawScriptResult
+	var script []byte
+	switch c.Type {
+	case "multisig":
+		if sigType != chainec.ECTypeSecp256k1 {
+			return nil, rpcInvalidError("Multisig scripts only " +
+				"support secp256k1 keys")
+		}
+		if c.NRequired == nil {
+			return nil, rpcInvalidError("The number of required " +
+				"signatures must be specified for multisig scripts")
+		}
+		var err error
+		script, err = multiSigRedeemScript(s, c.Keys, *c.NRequired)
+		if err != nil {
+			return nil, err
+		}
+
+	case "pubkey", "pubkeyhash":
+		if len(c.Keys) != 1 {
+			return nil, rpcInvalidError("Exactly one key must be "+
+				"specified for %s scripts", c.Type)
+		}
+		addr, err := decodePubKeyAddress(c.Keys[0], sigType, params)
+		if err != nil {
+			return nil, err
+		}
+
+		// Pay to the public key itself or to its hash depending on
+		// the type.
+		var payAddr hcutil.Address = addr
+		if c.Type == "pubkeyhash" {
+			payAddr = addr.AddressPubKeyHash()
+		}
+		script, err = txscript.PayToAddrScript(payAddr)
+		if err != nil {
+			return nil, rpcInternalError(err.Error(),
+				"Failed to create script")
+		}
+		result.Address = payAddr.EncodeAddress()
+
+	default:
+		return nil, rpcInvalidError("Unsupported script type %q -- "+
+			"supported types are multisig, pubkey and pubkeyhash",
+			c.Type)
+	}
+
+	// Allow the script to also be used as the redeem script of a
+	// pay-to-script-hash output.
+	p2sh, err := hcutil.NewAddressScriptHash(script, params)
+	if err != nil {
+		return nil, rpcInternalError(err.Error(),
+			"Failed to convert script to pay-to-script-hash")
+	}
+	p2shScript, err := txscript.PayToAddrScript(p2sh)
+	if err != nil {
+		return nil, rpcInternalError(err.Error(),
+			"Failed to create pay-to-script-hash script")
+	}
+	result.Type = txscript.GetScriptClass(txscript.DefaultScriptVersion,
+		script).String()
+	result.Script = hex.EncodeToString(script)
+	result.P2sh = p2sh.EncodeAddress()
+	result.P2shScript = hex.EncodeToString(p2shScript)
+
+	return result, nil
+}
+
 // handleCreateRawTransaction handles createrawtransaction commands.
 func handleCreateRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*hcjson.CreateRawTransactionCmd)
@@ -627,6 +1004,22 @@ func handleCreateRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan
 		return nil, rpcInvalidError("Locktime out of range")
 	}
 
+	// Stake transactions are created by the same code as their dedicated
+	// commands.
+	switch *c.TxType {
+	case "regular":
+		if c.Commitments != nil {
+			return nil, rpcInvalidError("Commitments are only used " +
+				"by ticket purchases")
+		}
+	case "ticket", "revocation":
+		return createRawStakeTransaction(s, c)
+	default:
+		return nil, rpcInvalidError("Unsupported transaction type %q "+
+			"-- supported types are regular, ticket and revocation",
+			*c.TxType)
+	}
+
 	// Add all transaction inputs to a new transaction after performing
 	// some validity checks.
 	mtx := wire.NewMsgTx()
@@ -669,9 +1062,12 @@ func handleCreateRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan
 		// Ensure the address is one of the supported types and that
 		// the network encoded with the address matches the network the
 		// server is currently on.
-		switch addr.(type) {
+		var hashFunc hcutil.HashFunc
+		switch a := addr.(type) {
 		case *hcutil.AddressPubKeyHash:
+			hashFunc = a.HashFunc()
 		case *hcutil.AddressScriptHash:
+			hashFunc = a.HashFunc()
 		default:
 			return nil, rpcAddressKeyError("Invalid type: %T", addr)
 		}
@@ -680,6 +1076,15 @@ func handleCreateRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan
 				addr)
 		}
 
+		// Addresses created with an alternative hash function can't be
+		// paid to until a script version which commits to the hash
+		// function is enforced by consensus.
+		if hashFunc != hcutil.HashFuncBlake256Ripemd160 {
+			return nil, rpcAddressKeyError("Addresses using hash "+
+				"function %v can not be paid to yet: %v", hashFunc,
+				addr)
+		}
+
 		// Create a new script which pays to the provided address.
 		pkScript, err := txscript.PayToAddrScript(addr)
 		if err != nil {
@@ -726,26 +1131,133 @@ func handleCreateRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan
 	return mtxHex, nil
 }
 
+// inputAmount returns the value of the output spent by the passed input.  The
+// output may either be unspent in the main chain or belong to a transaction in
+// the memory pool.
+func inputAmount(s *rpcServer, input *hcjson.TransactionInput) (int64, error) {
+	txHash, err := chainhash.NewHashFromStr(input.Txid)
+	if err != nil {
+		return 0, rpcDecodeHexError(input.Txid)
+	}
+
+	entry, err := s.chain.FetchUtxoEntry(txHash)
+	if err == nil && entry != nil && !entry.IsOutputSpent(input.Vout) {
+		return entry.AmountByIndex(input.Vout), nil
+	}
+	tx, err := s.server.txMemPool.FetchTransaction(txHash, true)
+	if err == nil && input.Vout < uint32(len(tx.MsgTx().TxOut)) {
+		return tx.MsgTx().TxOut[input.Vout].Value, nil
+	}
+	return 0, rpcNoTxInfoError(txHash)
+}
+
+// createRawStakeTransaction creates the ticket purchase or revocation
+// requested by the passed createrawtransaction command.
+func createRawStakeTransaction(s *rpcServer, c *hcjson.CreateRawTransactionCmd) (interface{}, error) {
+	if c.PayLoad != nil && *c.PayLoad != "" {
+		return nil, rpcInvalidError("Payloads are only supported by " +
+			"regular transactions")
+	}
+
+	var mtx *wire.MsgTx
+	var err error
+	switch *c.TxType {
+	case "ticket":
+		if c.Commitments == nil {
+			return nil, rpcInvalidError("Ticket purchases require a " +
+				"commitment for each input")
+		}
+
+		// The committed amounts are derived from the values of the
+		// inputs, so look them up.
+		inputs := make([]hcjson.SStxInput, 0, len(c.Inputs))
+		for i := range c.Inputs {
+			input := &c.Inputs[i]
+			amt, err := inputAmount(s, input)
+			if err != nil {
+				return nil, err
+			}
+			inputs = append(inputs, hcjson.SStxInput{
+				Txid: input.Txid,
+				Vout: input.Vout,
+				Tree: input.Tree,
+				Amt:  amt,
+			})
+		}
+		amounts := make(map[string]int64, len(c.Amounts))
+		for encodedAddr, amount := range c.Amounts {
+			atoms, err := hcutil.NewAmount(amount)
+			if err != nil {
+				return nil, rpcInvalidError("Invalid amount: %v",
+					err)
+			}
+			amounts[encodedAddr] = int64(atoms)
+		}
+		mtx, err = createTicketPurchaseTx(s, inputs, amounts,
+			*c.Commitments)
+
+	case "revocation":
+		if len(c.Amounts) != 0 || c.Commitments != nil {
+			return nil, rpcInvalidError("Revocations pay to the " +
+				"commitments of the revoked ticket and do not " +
+				"accept amounts or commitments")
+		}
+		mtx, err = createRevocationTx(s, c.Inputs, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if c.LockTime != nil {
+		mtx.LockTime = uint32(*c.LockTime)
+	}
+
+	// Return the serialized and hex-encoded transaction.
+	mtxHex, err := messageToHex(mtx)
+	if err != nil {
+		return nil, err
+	}
+	return mtxHex, nil
+}
+
 // handleCreateRawSStx handles createrawsstx commands.
 func handleCreateRawSStx(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*hcjson.CreateRawSStxCmd)
 
+	mtx, err := createTicketPurchaseTx(s, c.Inputs, c.Amount, c.COuts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Return the serialized and hex-encoded transaction.
+	mtxHex, err := messageToHex(mtx)
+	if err != nil {
+		return nil, err
+	}
+	return mtxHex, nil
+}
+
+// createTicketPurchaseTx returns a new unsigned ticket purchase spending the
+// passed inputs, paying the single passed amount to its voting address and
+// committing the remaining input values, less change, to the passed commitment
+// addresses.
+func createTicketPurchaseTx(s *rpcServer, inputs []hcjson.SStxInput, amounts map[string]int64, couts []hcjson.SStxCommitOut) (*wire.MsgTx, error) {
 	// Basic sanity checks for the information coming from the cmd.
-	if len(c.Inputs) != len(c.COuts) {
+	if len(inputs) != len(couts) {
 		return nil, rpcInvalidError("Number of inputs should be equal "+
 			"to the number of future commitment/change outs for "+
 			"any sstx; %v inputs given, but %v COuts",
-			len(c.Inputs), len(c.COuts))
+			len(inputs), len(couts))
 	}
-	if len(c.Amount) != 1 {
+	if len(amounts) != 1 {
 		return nil, rpcInvalidError("Only one SSGen tagged output is "+
-			"allowed per sstx; len ssgenout %v", len(c.Amount))
+			"allowed per sstx; len ssgenout %v", len(amounts))
 	}
 
 	// Add all transaction inputs to a new transaction after performing
 	// some validity checks.
 	mtx := wire.NewMsgTx()
-	for _, input := range c.Inputs {
+	for _, input := range inputs {
 		txHash, err := chainhash.NewHashFromStr(input.Txid)
 		if err != nil {
 			return nil, rpcDecodeHexError(input.Txid)
@@ -769,7 +1281,7 @@ func handleCreateRawSStx(s *rpcServer, cmd interface{}, closeChan <-chan struct{
 	// some validity checks.
 	amtTicket := int64(0)
 
-	for encodedAddr, amount := range c.Amount {
+	for encodedAddr, amount := range amounts {
 		// Ensure amount is in the valid range for monetary amounts.
 		if amount <= 0 || amount > hcutil.MaxAmount {
 			return nil, rpcInvalidError("Invalid SSTx commitment "+
@@ -816,12 +1328,12 @@ func handleCreateRawSStx(s *rpcServer, cmd interface{}, closeChan <-chan struct{
 	// Calculated the commitment amounts, then create the
 	// addresses and payout proportions as null data
 	// outputs.
-	inputAmts := make([]int64, len(c.Inputs))
-	for i, input := range c.Inputs {
+	inputAmts := make([]int64, len(inputs))
+	for i, input := range inputs {
 		inputAmts[i] = input.Amt
 	}
-	changeAmts := make([]int64, len(c.COuts))
-	for i, cout := range c.COuts {
+	changeAmts := make([]int64, len(couts))
+	for i, cout := range couts {
 		changeAmts[i] = cout.ChangeAmt
 	}
 
@@ -842,7 +1354,7 @@ func handleCreateRawSStx(s *rpcServer, cmd interface{}, closeChan <-chan struct{
 			"Invalid SSTx output amounts")
 	}
 
-	for i, cout := range c.COuts {
+	for i, cout := range couts {
 		// 1. Append future commitment output.
 		addr, err := hcutil.DecodeAddress(cout.Addr)
 		if err != nil {
@@ -927,12 +1439,7 @@ func handleCreateRawSStx(s *rpcServer, cmd interface{}, closeChan <-chan struct{
 			"Invalid SStx")
 	}
 
-	// Return the serialized and hex-encoded transaction.
-	mtxHex, err := messageToHex(mtx)
-	if err != nil {
-		return nil, err
-	}
-	return mtxHex, nil
+	return mtx, nil
 }
 
 // handleCreateRawSSGenTx handles createrawssgentx commands.
@@ -1086,16 +1593,33 @@ func handleCreateRawSSGenTx(s *rpcServer, cmd interface{}, closeChan <-chan stru
 func handleCreateRawSSRtx(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*hcjson.CreateRawSSRtxCmd)
 
+	mtx, err := createRevocationTx(s, c.Inputs, c.Fee)
+	if err != nil {
+		return nil, err
+	}
+
+	// Return the serialized and hex-encoded transaction.
+	mtxHex, err := messageToHex(mtx)
+	if err != nil {
+		return nil, err
+	}
+	return mtxHex, nil
+}
+
+// createRevocationTx returns a new unsigned revocation of the ticket spent by
+// the single passed input, which returns the committed amounts of the ticket,
+// less the optional fee in coins, to its commitment addresses.
+func createRevocationTx(s *rpcServer, inputs []hcjson.TransactionInput, fee *float64) (*wire.MsgTx, error) {
 	// Only a single SStx should be given
-	if len(c.Inputs) != 1 {
+	if len(inputs) != 1 {
 		return nil, rpcInvalidError("SSRtx invalid number of inputs")
 	}
 
 	// Decode the fee as coins.
 	var feeAmt hcutil.Amount
-	if c.Fee != nil {
+	if fee != nil {
 		var err error
-		feeAmt, err = hcutil.NewAmount(*c.Fee)
+		feeAmt, err = hcutil.NewAmount(*fee)
 		if err != nil {
 			return nil, rpcInvalidError("Invalid fee amount: %v",
 				err)
@@ -1106,9 +1630,9 @@ func handleCreateRawSSRtx(s *rpcServer, cmd interface{}, closeChan <-chan struct
 	// for the generation of the SSGen tx outputs.
 	//
 	// Convert the provided transaction hash hex to a chainhash.Hash.
-	txHash, err := chainhash.NewHashFromStr(c.Inputs[0].Txid)
+	txHash, err := chainhash.NewHashFromStr(inputs[0].Txid)
 	if err != nil {
-		return nil, rpcDecodeHexError(c.Inputs[0].Txid)
+		return nil, rpcDecodeHexError(inputs[0].Txid)
 	}
 
 	// Try to fetch the ticket from the block database.
@@ -1130,7 +1654,7 @@ func handleCreateRawSSRtx(s *rpcServer, cmd interface{}, closeChan <-chan struct
 	// some validity checks; the only input for an SSRtx is an OP_SSTX tagged
 	// output.
 	mtx := wire.NewMsgTx()
-	for _, input := range c.Inputs {
+	for _, input := range inputs {
 		txHash, err := chainhash.NewHashFromStr(input.Txid)
 		if err != nil {
 			return nil, rpcDecodeHexError(input.Txid)
@@ -1200,12 +1724,7 @@ func handleCreateRawSSRtx(s *rpcServer, cmd interface{}, closeChan <-chan struct
 		return nil, rpcInternalError(err.Error(), "Invalid SSRtx")
 	}
 
-	// Return the serialized and hex-encoded transaction.
-	mtxHex, err := messageToHex(mtx)
-	if err != nil {
-		return nil, err
-	}
-	return mtxHex, nil
+	return mtx, nil
 }
 
 // handleDebugLevel handles debuglevel commands.
@@ -1227,6 +1746,52 @@ func handleDebugLevel(s *rpcServer, cmd interface{}, closeChan <-chan struct{})
 	return "Done.", nil
 }
 
+// handleDumpBlockChain implements the dumpblockchain command.
+func handleDumpBlockChain(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*hcjson.DumpBlockChainCmd)
+
+	best := s.chain.BestSnapshot()
+	startHeight := *c.StartHeight
+	endHeight := best.Height
+	if c.EndHeight != nil {
+		endHeight = *c.EndHeight
+	}
+	if startHeight < 0 || startHeight > endHeight || endHeight > best.Height {
+		return nil, rpcInvalidError("Heights must satisfy 0 <= start "+
+			"height <= end height <= %d", best.Height)
+	}
+
+	// Relative paths are relative to the data directory and existing files
+	// are never overwritten.
+	if c.Filename == "" {
+		return nil, rpcInvalidError("Filename must not be empty")
+	}
+	path := c.Filename
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(cfg.DataDir, path)
+	}
+	if _, err := os.Stat(path); err == nil {
+		return nil, rpcInvalidError("File %s already exists", path)
+	}
+
+	rpcsLog.Infof("Exporting blocks %d through %d to %s", startHeight,
+		endHeight, path)
+	size, err := exportBlockChain(s.chain, path, startHeight, endHeight,
+		closeChan)
+	if err != nil {
+		return nil, rpcMiscError(fmt.Sprintf("Failed to export the "+
+			"block chain: %v", err))
+	}
+
+	return &hcjson.DumpBlockChainResult{
+		Filename:    path,
+		StartHeight: startHeight,
+		EndHeight:   endHeight,
+		Blocks:      endHeight - startHeight + 1,
+		Size:        size,
+	}, nil
+}
+
 // createVinList returns a slice of JSON objects for the inputs of the passed
 // transaction.
 func createVinList(mtx *wire.MsgTx) []hcjson.Vin {
@@ -1389,8 +1954,159 @@ func createTxRawResult(chainParams *chaincfg.Params, mtx *wire.MsgTx, txHash str
 		txReply.BlockHash = blkHash
 		txReply.Confirmations = confirmations
 	}
-
-	return txReply, nil
+
+	return txReply, nil
+}
+
+// createPshtUnknowns returns JSON objects for the passed unknown key-value
+// pairs of a partially signed transaction.
+func createPshtUnknowns(unknowns []*psht.Unknown) []hcjson.PshtUnknown {
+	if len(unknowns) == 0 {
+		return nil
+	}
+	result := make([]hcjson.PshtUnknown, 0, len(unknowns))
+	for _, u := range unknowns {
+		result = append(result, hcjson.PshtUnknown{
+			Key:   hex.EncodeToString(u.Key),
+			Value: hex.EncodeToString(u.Value),
+		})
+	}
+	return result
+}
+
+// createPshtBip32Derivs returns JSON objects for the passed key derivations of
+// a partially signed transaction.
+func createPshtBip32Derivs(derivations []*psht.Bip32Derivation) []hcjson.PshtBip32Derivation {
+	if len(derivations) == 0 {
+		return nil
+	}
+	result := make([]hcjson.PshtBip32Derivation, 0, len(derivations))
+	for _, d := range derivations {
+		result = append(result, hcjson.PshtBip32Derivation{
+			PubKey:            hex.EncodeToString(d.PubKey),
+			MasterFingerprint: fmt.Sprintf("%08x", d.MasterKeyFingerprint),
+			Path:              d.Path.String(),
+		})
+	}
+	return result
+}
+
+// handleDecodePsht implements the decodepsht command.
+func handleDecodePsht(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*hcjson.DecodePshtCmd)
+
+	p, err := decodePsht(c.Psht)
+	if err != nil {
+		return nil, err
+	}
+
+	mtx := p.UnsignedTx
+	txTypeStr := txTypeName(stake.DetermineTxType(mtx))
+	result := &hcjson.DecodePshtResult{
+		Tx: hcjson.TxRawDecodeResult{
+			Txid:     mtx.TxHash().String(),
+			Type:     txTypeStr,
+			Version:  int32(mtx.Version),
+			Locktime: mtx.LockTime,
+			Expiry:   mtx.Expiry,
+			Vin:      createVinList(mtx),
+			Vout:     createVoutList(mtx, s.server.chainParams, nil),
+		},
+		Type:     txTypeStr,
+		Unknowns: createPshtUnknowns(p.Unknowns),
+		Inputs:   make([]hcjson.DecodePshtInput, 0, len(p.Inputs)),
+		Outputs:  make([]hcjson.DecodePshtOutput, 0, len(p.Outputs)),
+	}
+
+	// The fee is only known when the values of all inputs are known.  The
+	// value of the stakebase input of a vote is the witness value set by
+	// the creator of the transaction.
+	var totalIn int64
+	haveAllPrevOuts := true
+	isStakeBase := stake.IsStakeBase(mtx)
+	for i := range p.Inputs {
+		pi := &p.Inputs[i]
+		input := hcjson.DecodePshtInput{
+			SigHashType:    uint32(pi.SighashType),
+			RedeemScript:   hex.EncodeToString(pi.RedeemScript),
+			FinalScriptSig: hex.EncodeToString(pi.FinalScriptSig),
+			Bip32Derivs:    createPshtBip32Derivs(pi.Bip32Derivation),
+			Unknowns:       createPshtUnknowns(pi.Unknowns),
+		}
+		for _, ps := range pi.PartialSigs {
+			input.PartialSigs = append(input.PartialSigs,
+				hcjson.PshtPartialSig{
+					PubKey:    hex.EncodeToString(ps.PubKey),
+					Signature: hex.EncodeToString(ps.Signature),
+				})
+		}
+
+		switch {
+		case pi.PrevOut != nil:
+			totalIn += pi.PrevOut.Value
+
+			// Ignore the error here since an error means the script
+			// couldn't parse and there is no additional information
+			// about it anyways.
+			pkScript := pi.PrevOut.PkScript
+			disbuf, _ := txscript.DisasmString(pkScript)
+			class, addrs, reqSigs, _ := txscript.ExtractPkScriptAddrs(
+				pi.PrevOut.Version, pkScript, s.server.chainParams)
+			encodedAddrs := make([]string, len(addrs))
+			for j, addr := range addrs {
+				encodedAddrs[j] = addr.EncodeAddress()
+			}
+			input.PrevOut = &hcjson.PshtPrevOut{
+				Amount:  hcutil.Amount(pi.PrevOut.Value).ToCoin(),
+				Version: pi.PrevOut.Version,
+				ScriptPubKey: hcjson.ScriptPubKeyResult{
+					Asm:       disbuf,
+					Hex:       hex.EncodeToString(pkScript),
+					ReqSigs:   int32(reqSigs),
+					Type:      class.String(),
+					Addresses: encodedAddrs,
+				},
+			}
+
+		case i == 0 && isStakeBase:
+			totalIn += mtx.TxIn[i].ValueIn
+
+		default:
+			haveAllPrevOuts = false
+		}
+		result.Inputs = append(result.Inputs, input)
+	}
+	for i := range p.Outputs {
+		po := &p.Outputs[i]
+		result.Outputs = append(result.Outputs, hcjson.DecodePshtOutput{
+			RedeemScript: hex.EncodeToString(po.RedeemScript),
+			Bip32Derivs:  createPshtBip32Derivs(po.Bip32Derivation),
+			Unknowns:     createPshtUnknowns(po.Unknowns),
+		})
+	}
+	if haveAllPrevOuts {
+		var totalOut int64
+		for _, txOut := range mtx.TxOut {
+			totalOut += txOut.Value
+		}
+		result.Fee = hcjson.Float64(hcutil.Amount(totalIn - totalOut).ToCoin())
+	}
+
+	return result, nil
+}
+
+// txTypeName returns the name used to label the passed transaction type in RPC
+// results.
+func txTypeName(txType stake.TxType) string {
+	switch txType {
+	case stake.TxTypeSStx:
+		return "ticket"
+	case stake.TxTypeSSGen:
+		return "vote"
+	case stake.TxTypeSSRtx:
+		return "revocation"
+	}
+	return "regular"
 }
 
 // handleDecodeRawTransaction handles decoderawtransaction commands.
@@ -1416,6 +2132,7 @@ func handleDecodeRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan
 	// Create and return the result.
 	txReply := hcjson.TxRawDecodeResult{
 		Txid:     mtx.TxHash().String(),
+		Type:     txTypeName(stake.DetermineTxType(&mtx)),
 		Version:  int32(mtx.Version),
 		Locktime: mtx.LockTime,
 		Expiry:   mtx.Expiry,
@@ -1472,11 +2189,55 @@ func handleDecodeScript(s *rpcServer, cmd interface{}, closeChan <-chan struct{}
 	return reply, nil
 }
 
+// handleDescribeRPC implements the describerpc command.
+func handleDescribeRPC(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	methods := make([]string, 0, len(rpcHandlers))
+	for method := range rpcHandlers {
+		if _, ok := rpcHidden[method]; ok {
+			continue
+		}
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+
+	result := make([]hcjson.DescribeRPCResult, 0, len(methods))
+	for _, method := range methods {
+		usage, err := hcjson.MethodUsageText(method)
+		if err != nil {
+			return nil, rpcInternalError(err.Error(),
+				"Failed to describe method")
+		}
+		params, err := hcjson.MethodParams(method)
+		if err != nil {
+			return nil, rpcInternalError(err.Error(),
+				"Failed to describe method")
+		}
+
+		desc := hcjson.DescribeRPCResult{
+			Method: method,
+			Usage:  usage,
+			Params: make([]hcjson.DescribeRPCParam, 0, len(params)),
+		}
+		_, desc.Limited = rpcLimited[method]
+		for _, param := range params {
+			desc.Params = append(desc.Params, hcjson.DescribeRPCParam{
+				Name:     param.Name,
+				Type:     param.Type,
+				Optional: param.Optional,
+				Default:  param.Default,
+			})
+		}
+		result = append(result, desc)
+	}
+
+	return result, nil
+}
+
 // handleEstimateFee implenents the estimatefee command.
 // TODO this is a very basic implementation.  It should be
 // modified to match the bitcoin-core one.
 func handleEstimateFee(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
-	return cfg.minRelayTxFee.ToCoin(), nil
+	return configuredMinRelayTxFee().ToCoin(), nil
 }
 
 // handleEstimateStakeDiff implements the estimatestakediff command.
@@ -1743,6 +2504,78 @@ func handleExistsMempoolTxs(s *rpcServer, cmd interface{}, closeChan <-chan stru
 	return hex.EncodeToString([]byte(set)), nil
 }
 
+// handleExistsTxs implements the existstxs command.
+func handleExistsTxs(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*hcjson.ExistsTxsCmd)
+
+	hashes := make([]chainhash.Hash, len(c.TxHashes))
+	hashPtrs := make([]*chainhash.Hash, len(c.TxHashes))
+	for i, txHash := range c.TxHashes {
+		hash, err := chainhash.NewHashFromStr(txHash)
+		if err != nil {
+			return nil, rpcDecodeHexError(txHash)
+		}
+		hashes[i] = *hash
+		hashPtrs[i] = &hashes[i]
+	}
+
+	// Convert the slices of bools into compacted sets of bit flags.
+	toBitset := func(exists []bool) string {
+		set := bitset.NewBytes(len(exists))
+		for i := range exists {
+			if exists[i] {
+				set.Set(i)
+			}
+		}
+		return hex.EncodeToString([]byte(set))
+	}
+
+	return &hcjson.ExistsTxsResult{
+		Mempool:     toBitset(s.server.txMemPool.HaveTransactions(hashPtrs)),
+		LiveTickets: toBitset(s.chain.CheckLiveTickets(hashes)),
+	}, nil
+}
+
+// handleFinalizePsht implements the finalizepsht command.
+func handleFinalizePsht(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*hcjson.FinalizePshtCmd)
+
+	p, err := decodePsht(c.Psht)
+	if err != nil {
+		return nil, err
+	}
+
+	// Finalize as many inputs as possible.  Inputs which are still missing
+	// signatures are left unchanged so the result can be passed on to the
+	// remaining signers.
+	psht.FinalizeStakeBase(p, s.server.chainParams)
+	for i := range p.Inputs {
+		psht.Finalize(p, i)
+	}
+
+	result := &hcjson.FinalizePshtResult{Complete: p.IsComplete()}
+	if result.Complete && *c.Extract {
+		tx, err := psht.Extract(p)
+		if err != nil {
+			context := "Failed to extract transaction"
+			return nil, rpcInternalError(err.Error(), context)
+		}
+		serializedTx, err := tx.Bytes()
+		if err != nil {
+			context := "Failed to serialize transaction"
+			return nil, rpcInternalError(err.Error(), context)
+		}
+		result.Hex = hex.EncodeToString(serializedTx)
+		return result, nil
+	}
+
+	result.Psht, err = encodePsht(p)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 // handleGenerate handles generate commands.
 func handleGenerate(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	// Respond with an error if there are no addresses to pay the
@@ -1760,10 +2593,36 @@ func handleGenerate(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (i
 			"Configuration")
 	}
 
+	// Controlling the votes and timestamps of the generated blocks is only
+	// meant for exercising the consensus rules in tests, so it is limited
+	// to simnet.
+	if (c.Votes != nil || c.TimeOffset != nil) && !cfg.SimNet {
+		return nil, rpcInvalidError("The number of votes and the " +
+			"timestamp offset may only be specified on simnet")
+	}
+
+	// Fewer votes than the majority of tickets per block would never
+	// produce a valid block.
+	params := s.server.chainParams
+	maxVotes := params.TicketsPerBlock
+	if c.Votes != nil {
+		minVotes := uint32(params.TicketsPerBlock/2 + 1)
+		if *c.Votes < minVotes || *c.Votes > uint32(maxVotes) {
+			return nil, rpcInvalidError("Number of votes must be "+
+				"between %d and %d", minVotes, maxVotes)
+		}
+		maxVotes = uint16(*c.Votes)
+	}
+	var timeOffset time.Duration
+	if c.TimeOffset != nil {
+		timeOffset = time.Duration(*c.TimeOffset) * time.Second
+	}
+
 	// Create a reply
 	reply := make([]string, c.NumBlocks)
 
-	blockHashes, err := s.server.cpuMiner.GenerateNBlocks(c.NumBlocks)
+	blockHashes, err := s.server.cpuMiner.GenerateNBlocks(c.NumBlocks,
+		maxVotes, timeOffset)
 	if err != nil {
 		return nil, rpcInternalError(err.Error(), "Could not generate blocks")
 	}
@@ -1971,6 +2830,12 @@ func handleGetBlock(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (i
 		ExtraData:     hex.EncodeToString(blockHeader.ExtraData[:]),
 		NextHash:      nextHashString,
 	}
+	for _, vote := range blockchain.VoteBitsInBlock(blk) {
+		blockReply.Votes = append(blockReply.Votes, hcjson.VersionBits{
+			Version: vote.Version,
+			Bits:    vote.Bits,
+		})
+	}
 
 	if c.VerboseTx == nil || !*c.VerboseTx {
 		transactions := blk.Transactions()
@@ -2132,6 +2997,103 @@ func handleGetBlockHeader(s *rpcServer, cmd interface{}, closeChan <-chan struct
 
 }
 
+// handleGetBlockHeaders implements the getblockheaders command.
+func handleGetBlockHeaders(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*hcjson.GetBlockHeadersCmd)
+
+	count := int32(wire.MaxBlockHeadersPerMsg)
+	if c.Count != nil {
+		count = *c.Count
+	}
+	if count <= 0 || count > wire.MaxBlockHeadersPerMsg {
+		return nil, rpcInvalidError("Count must be between 1 and %d",
+			wire.MaxBlockHeadersPerMsg)
+	}
+
+	blockLocators := make([]*chainhash.Hash, len(c.BlockLocators))
+	for i, locator := range c.BlockLocators {
+		hash, err := chainhash.NewHashFromStr(locator)
+		if err != nil {
+			return nil, rpcDecodeHexError(locator)
+		}
+		blockLocators[i] = hash
+	}
+	var hashStop chainhash.Hash
+	if c.HashStop != nil && *c.HashStop != "" {
+		err := chainhash.Decode(&hashStop, *c.HashStop)
+		if err != nil {
+			return nil, rpcInvalidError("Failed to decode "+
+				"hashstop: %v", err)
+		}
+	}
+
+	blockHashes, err := s.server.locateBlocks(blockLocators, &hashStop)
+	if err != nil {
+		return nil, &hcjson.RPCError{
+			Code: hcjson.ErrRPCDatabase,
+			Message: "Failed to fetch hashes of block " +
+				"headers: " + err.Error(),
+		}
+	}
+	if len(blockHashes) > int(count) {
+		blockHashes = blockHashes[:count]
+	}
+	blockHeaders, err := fetchHeaders(s.server.db, blockHashes)
+	if err != nil {
+		return nil, &hcjson.RPCError{
+			Code: hcjson.ErrRPCDatabase,
+			Message: "Failed to fetch headers of located blocks: " +
+				err.Error(),
+		}
+	}
+
+	hexBlockHeaders := make([]string, len(blockHeaders))
+	var buf bytes.Buffer
+	buf.Grow(wire.MaxBlockHeaderPayload)
+	for i, h := range blockHeaders {
+		err := h.Serialize(&buf)
+		if err != nil {
+			return nil, rpcInternalError(err.Error(),
+				"Failed to serialize block header")
+		}
+		hexBlockHeaders[i] = hex.EncodeToString(buf.Bytes())
+		buf.Reset()
+	}
+
+	result := &hcjson.GetBlockHeadersResult{
+		Headers:    hexBlockHeaders,
+		BestHeight: s.chain.BestSnapshot().Height,
+	}
+	if len(blockHeaders) > 0 {
+		result.StartHeight = int64(blockHeaders[0].Height)
+	}
+	return result, nil
+}
+
+// handleGetBlockReceivedTime implements the getblockreceivedtime command.
+func handleGetBlockReceivedTime(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*hcjson.GetBlockReceivedTimeCmd)
+	hash, err := chainhash.NewHashFromStr(c.Hash)
+	if err != nil {
+		return nil, rpcDecodeHexError(c.Hash)
+	}
+
+	arrival, ok := s.server.blockArrivals.lookup(hash)
+	if !ok {
+		return nil, &hcjson.RPCError{
+			Code: hcjson.ErrRPCBlockNotFound,
+			Message: fmt.Sprintf("No received time known for block %v",
+				c.Hash),
+		}
+	}
+
+	return &hcjson.GetBlockReceivedTimeResult{
+		Time:       arrival.Time.Unix(),
+		TimeMillis: arrival.Time.UnixNano() / int64(time.Millisecond),
+		Source:     arrival.Source,
+	}, nil
+}
+
 // handleGetBlockSubsidy implements the getblocksubsidy command.
 func handleGetBlockSubsidy(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*hcjson.GetBlockSubsidyCmd)
@@ -2162,6 +3124,75 @@ func handleGetBlockSubsidy(s *rpcServer, cmd interface{}, closeChan <-chan struc
 	return rep, nil
 }
 
+// handleGetBlockUndo implements the getblockundo command.
+func handleGetBlockUndo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*hcjson.GetBlockUndoCmd)
+	hash, err := chainhash.NewHashFromStr(c.Hash)
+	if err != nil {
+		return nil, rpcDecodeHexError(c.Hash)
+	}
+
+	height, err := s.chain.BlockHeightByHash(hash)
+	if err != nil {
+		return nil, &hcjson.RPCError{
+			Code: hcjson.ErrRPCBlockNotFound,
+			Message: fmt.Sprintf("Block not found in the main chain: %v",
+				c.Hash),
+		}
+	}
+	spent, err := s.chain.SpentOutputs(hash)
+	if err != nil {
+		if blockchain.IsNotInMainChainErr(err) {
+			return nil, &hcjson.RPCError{
+				Code: hcjson.ErrRPCBlockNotFound,
+				Message: fmt.Sprintf("Block not found in the main "+
+					"chain: %v", c.Hash),
+			}
+		}
+		context := "Failed to load spent outputs"
+		return nil, rpcInternalError(err.Error(), context)
+	}
+
+	result := hcjson.GetBlockUndoResult{
+		Hash:   c.Hash,
+		Height: height,
+		Spent:  make([]hcjson.SpentOutputResult, 0, len(spent)),
+	}
+	for i := range spent {
+		so := &spent[i]
+
+		// Ignore the error here since an error means the script
+		// couldn't parse and there is no additional information about
+		// it anyways.
+		disbuf, _ := txscript.DisasmString(so.PkScript)
+		class, addrs, reqSigs, _ := txscript.ExtractPkScriptAddrs(
+			so.ScriptVersion, so.PkScript, s.server.chainParams)
+		encodedAddrs := make([]string, len(addrs))
+		for j, addr := range addrs {
+			encodedAddrs[j] = addr.EncodeAddress()
+		}
+		result.Spent = append(result.Spent, hcjson.SpentOutputResult{
+			TxID:       so.SpendingTx.String(),
+			Vin:        so.InputIndex,
+			PrevTxID:   so.PrevOut.Hash.String(),
+			PrevVout:   so.PrevOut.Index,
+			PrevTree:   so.PrevOut.Tree,
+			Amount:     hcutil.Amount(so.Amount).ToCoin(),
+			Height:     so.Height,
+			BlockIndex: so.BlockIndex,
+			ScriptPubKey: hcjson.ScriptPubKeyResult{
+				Asm:       disbuf,
+				Hex:       hex.EncodeToString(so.PkScript),
+				ReqSigs:   int32(reqSigs),
+				Type:      class.String(),
+				Addresses: encodedAddrs,
+			},
+		})
+	}
+
+	return result, nil
+}
+
 // encodeTemplateID encodes the passed details into an ID that can be used to
 // uniquely identify a block template.
 func encodeTemplateID(prevHash *chainhash.Hash, lastGenerated time.Time) string {
@@ -2328,11 +3359,23 @@ func (state *gbtWorkState) updateBlockTemplate(s *rpcServer, useCoinbaseValue bo
 	var targetDifficulty string
 	latestHash, _ := s.server.blockManager.chainState.Best()
 	template := state.template
-	if template == nil || state.prevHash == nil ||
-		!state.prevHash.IsEqual(latestHash) ||
-		(state.lastTxUpdate != lastTxUpdate &&
-			time.Now().After(state.lastGenerated.Add(time.Second*
-				gbtRegenerateSeconds))) {
+	regenerate := template == nil || state.prevHash == nil ||
+		!state.prevHash.IsEqual(latestHash)
+	if !regenerate && state.lastTxUpdate != lastTxUpdate &&
+		time.Now().After(state.lastGenerated.Add(time.Second*
+			gbtRegenerateSeconds)) {
+
+		// Only regenerate the template when the transactions it was
+		// built from actually changed, which is not the case when,
+		// for instance, a transaction was added and removed again.
+		added, removed := s.server.txMemPool.Snapshot().Diff(
+			template.TxSnapshot)
+		regenerate = len(added) != 0 || len(removed) != 0
+		if !regenerate {
+			state.lastTxUpdate = lastTxUpdate
+		}
+	}
+	if regenerate {
 
 		// Reset the previous best hash the block template was generated
 		// against so any errors below cause the next invocation to try
@@ -2910,6 +3953,19 @@ func handleGetBlockTemplateLongPoll(s *rpcServer, longPollID string, useCoinbase
 // coinbasetxn and coinbasevalue capabilities) and modifies the returned block
 // template accordingly.
 func handleGetBlockTemplateRequest(s *rpcServer, request *hcjson.TemplateRequest, closeChan <-chan struct{}) (interface{}, error) {
+	if s.server.cpuMiner.IsMining() {
+		return nil, rpcMiscError("Block template production is " +
+			"disallowed while CPU mining is enabled. " +
+			"Please disable CPU mining and try again.")
+	}
+
+	// Respond with an error if there are no addresses to pay the created
+	// blocks to.
+	if len(cfg.miningAddrs) == 0 {
+		return nil, rpcInternalError("No payment addresses specified "+
+			"via --miningaddr", "Configuration")
+	}
+
 	// Extract the relevant passed capabilities and restrict the result to
 	// either a coinbase value or a coinbase transaction object depending
 	// on the request.  Default to only providing a coinbase value.
@@ -2987,8 +4043,8 @@ func handleGetBlockTemplateRequest(s *rpcServer, request *hcjson.TemplateRequest
 
 // chainErrToGBTErrString converts an error returned from chain to a string
 // which matches the reasons and format described in BIP0022 for rejection
-// reasons.
-// TODO Hcd pop in the new errors from blockchain cj
+// reasons.  The stake rules of hcd, which BIP0022 does not cover, are reported
+// with reasons in the same format.
 func chainErrToGBTErrString(err error) string {
 	// When the passed error is not a RuleError, just return a generic
 	// rejected string with the error text.
@@ -3000,10 +4056,16 @@ func chainErrToGBTErrString(err error) string {
 	switch ruleErr.ErrorCode {
 	case blockchain.ErrDuplicateBlock:
 		return "duplicate"
+	case blockchain.ErrMissingParent:
+		return "bad-prevblk"
 	case blockchain.ErrBlockTooBig:
 		return "bad-block-size"
+	case blockchain.ErrWrongBlockSize:
+		return "bad-blk-length"
 	case blockchain.ErrBlockVersionTooOld:
 		return "bad-version"
+	case blockchain.ErrBadStakeVersion:
+		return "bad-stakeversion"
 	case blockchain.ErrInvalidTime:
 		return "bad-time"
 	case blockchain.ErrTimeTooOld:
@@ -3060,16 +4122,128 @@ func chainErrToGBTErrString(err error) string {
 		return "high-sigops"
 	case blockchain.ErrFirstTxNotCoinbase:
 		return "bad-txns-nocoinbase"
+	case blockchain.ErrCoinbaseHeight:
+		return "bad-cb-height"
 	case blockchain.ErrMultipleCoinbases:
 		return "bad-txns-multicoinbase"
+	case blockchain.ErrStakeTxInRegularTree:
+		return "bad-txns-stake-in-regular-tree"
+	case blockchain.ErrRegTxInStakeTree:
+		return "bad-txns-regular-in-stake-tree"
 	case blockchain.ErrBadCoinbaseScriptLen:
 		return "bad-cb-length"
 	case blockchain.ErrBadCoinbaseValue:
 		return "bad-cb-value"
+	case blockchain.ErrBadCoinbaseOutpoint:
+		return "bad-cb-outpoint"
+	case blockchain.ErrBadCoinbaseFraudProof:
+		return "bad-cb-fraudproof"
+	case blockchain.ErrBadCoinbaseAmountIn:
+		return "bad-cb-amountin"
+	case blockchain.ErrBadStakebaseAmountIn:
+		return "bad-sb-amountin"
+	case blockchain.ErrBadStakebaseScriptLen:
+		return "bad-sb-length"
+	case blockchain.ErrBadStakebaseScrVal:
+		return "bad-sb-script"
+	case blockchain.ErrBadStakebaseValue:
+		return "bad-sb-value"
 	case blockchain.ErrScriptMalformed:
 		return "bad-script-malformed"
 	case blockchain.ErrScriptValidation:
 		return "bad-script-validate"
+	case blockchain.ErrNotEnoughStake:
+		return "bad-stake-notenough"
+	case blockchain.ErrStakeBelowMinimum:
+		return "bad-stake-belowmin"
+	case blockchain.ErrNonstandardStakeTx:
+		return "bad-stake-nonstandard"
+	case blockchain.ErrNotEnoughVotes:
+		return "bad-stake-notenoughvotes"
+	case blockchain.ErrTooManyVotes:
+		return "bad-stake-toomanyvotes"
+	case blockchain.ErrFreshStakeMismatch:
+		return "bad-stake-freshstake"
+	case blockchain.ErrTooManySStxs:
+		return "bad-stake-toomanytickets"
+	case blockchain.ErrInvalidEarlyStakeTx:
+		return "bad-stake-early"
+	case blockchain.ErrTicketUnavailable:
+		return "bad-stake-ticketunavailable"
+	case blockchain.ErrVotesOnWrongBlock:
+		return "bad-stake-votewrongblock"
+	case blockchain.ErrVotesMismatch:
+		return "bad-stake-votes"
+	case blockchain.ErrIncongruentVotebit:
+		return "bad-stake-votebits"
+	case blockchain.ErrInvalidEarlyVoteBits:
+		return "bad-stake-earlyvotebits"
+	case blockchain.ErrInvalidSSRtx:
+		return "bad-stake-revocation"
+	case blockchain.ErrRevocationsMismatch:
+		return "bad-stake-revocations"
+	case blockchain.ErrTooManyRevocations:
+		return "bad-stake-toomanyrevocations"
+	case blockchain.ErrSStxCommitment:
+		return "bad-stake-ticketcommitment"
+	case blockchain.ErrUnparseableSSGen:
+		return "bad-stake-voteunparseable"
+	case blockchain.ErrInvalidSSGenInput:
+		return "bad-stake-voteinput"
+	case blockchain.ErrSSGenPayeeNum:
+		return "bad-stake-votepayeenum"
+	case blockchain.ErrSSGenPayeeOuts:
+		return "bad-stake-votepayees"
+	case blockchain.ErrSSGenSubsidy:
+		return "bad-stake-votesubsidy"
+	case blockchain.ErrSStxInImmature:
+		return "bad-stake-ticketimmature"
+	case blockchain.ErrSStxInScrType:
+		return "bad-stake-ticketinput"
+	case blockchain.ErrInvalidSSRtxInput:
+		return "bad-stake-revocationinput"
+	case blockchain.ErrSSRtxPayeesMismatch:
+		return "bad-stake-revocationpayeenum"
+	case blockchain.ErrSSRtxPayees:
+		return "bad-stake-revocationpayees"
+	case blockchain.ErrTxSStxOutSpend:
+		return "bad-txns-ticketspend"
+	case blockchain.ErrRegTxSpendStakeOut:
+		return "bad-txns-stakeoutspend"
+	case blockchain.ErrInvalidFinalState:
+		return "bad-stake-finalstate"
+	case blockchain.ErrPoolSize:
+		return "bad-stake-poolsize"
+	case blockchain.ErrDiscordantTxTree:
+		return "bad-txns-discordanttree"
+	case blockchain.ErrStakeFees:
+		return "bad-stake-fees"
+	case blockchain.ErrNoStakeTx:
+		return "bad-stake-none"
+	case blockchain.ErrBadBlockHeight:
+		return "bad-blk-height"
+	case blockchain.ErrBlockOneTx:
+		return "bad-bl1-tx"
+	case blockchain.ErrBlockOneInputs:
+		return "bad-bl1-inputs"
+	case blockchain.ErrBlockOneOutputs:
+		return "bad-bl1-outputs"
+	case blockchain.ErrNoTax:
+		return "bad-cb-notax"
+	case blockchain.ErrExpiredTx:
+		return "bad-txns-expired"
+	case blockchain.ErrExpiryTxSpentEarly:
+		return "bad-txns-expiryspend"
+	case blockchain.ErrFraudAmountIn:
+		return "bad-txns-fraudamountin"
+	case blockchain.ErrFraudBlockHeight:
+		return "bad-txns-fraudheight"
+	case blockchain.ErrFraudBlockIndex:
+		return "bad-txns-fraudindex"
+	case blockchain.ErrZeroValueOutputSpend:
+		return "bad-txns-zerovaluespend"
+	case blockchain.ErrCheckExtraData:
+		return "bad-extradata"
 	}
 
 	return "rejected: " + err.Error()
@@ -3110,7 +4284,7 @@ func handleGetBlockTemplateProposal(s *rpcServer, request *hcjson.TemplateReques
 	}
 
 	flags := blockchain.BFDryRun | blockchain.BFNoPoWCheck
-	isOrphan, err := s.server.blockManager.ProcessBlock(block, flags)
+	isOrphan, err := s.server.blockManager.ProcessBlock(block, flags, nil)
 	if err != nil {
 		if _, ok := err.(blockchain.RuleError); !ok {
 			errStr := fmt.Sprintf("Failed to process block "+
@@ -3135,19 +4309,6 @@ func handleGetBlockTemplateProposal(s *rpcServer, request *hcjson.TemplateReques
 // See https://en.bitcoin.it/wiki/BIP_0022 and
 // https://en.bitcoin.it/wiki/BIP_0023 for more details.
 func handleGetBlockTemplate(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
-	if s.server.cpuMiner.IsMining() {
-		return nil, rpcMiscError("Block template production is " +
-			"disallowed while CPU mining is enabled. " +
-			"Please disable CPU mining and try again.")
-	}
-
-	// Respond with an error if there are no addresses to pay the created
-	// blocks to.
-	if len(cfg.miningAddrs) == 0 {
-		return nil, rpcInternalError("No payment addresses specified "+
-			"via --miningaddr", "Configuration")
-	}
-
 	c := cmd.(*hcjson.GetBlockTemplateCmd)
 	request := c.Request
 
@@ -3167,9 +4328,220 @@ func handleGetBlockTemplate(s *rpcServer, cmd interface{}, closeChan <-chan stru
 	return nil, rpcInvalidError("Invalid mode: %v", mode)
 }
 
+// handleGetChainStats implements the getchainstats command.
+func handleGetChainStats(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*hcjson.GetChainStatsCmd)
+
+	// The window ends at the best chain block unless another main chain
+	// block is specified.
+	best := s.chain.BestSnapshot()
+	endHeight := best.Height
+	endHash := best.Hash
+	if c.BlockHash != nil {
+		hash, err := chainhash.NewHashFromStr(*c.BlockHash)
+		if err != nil {
+			return nil, rpcDecodeHexError(*c.BlockHash)
+		}
+		height, err := s.chain.BlockHeightByHash(hash)
+		if err != nil {
+			return nil, &hcjson.RPCError{
+				Code: hcjson.ErrRPCBlockNotFound,
+				Message: fmt.Sprintf("Block not found in the main "+
+					"chain: %v", *c.BlockHash),
+			}
+		}
+		endHeight = height
+		endHash = hash
+	}
+
+	blocks := int64(2016)
+	if c.Blocks != nil {
+		blocks = int64(*c.Blocks)
+	}
+	if blocks <= 0 || blocks > endHeight+1 {
+		return nil, rpcInvalidError("Blocks must be between 1 and the "+
+			"number of blocks up to the final block of the window (%d)",
+			endHeight+1)
+	}
+	if blocks > maxChainStatsBlocks {
+		return nil, rpcInvalidError("Blocks must not exceed %d",
+			maxChainStatsBlocks)
+	}
+	startHeight := endHeight - blocks + 1
+
+	// Look up the statistics of each block of the window along with the
+	// block preceding it, which marks the start of the window interval.
+	// Blocks which are not in the store are loaded from the database.
+	fetchStats := func(height int64) (blockStats, error) {
+		if stats, ok := s.server.chainStats.lookup(height); ok {
+			return stats, nil
+		}
+		block, err := s.chain.BlockByHeight(height)
+		if err != nil {
+			return blockStats{}, err
+		}
+		stats := newBlockStats(block, s.server.chainParams)
+		s.server.chainStats.add(stats)
+		return stats, nil
+	}
+	firstHeight := startHeight - 1
+	if firstHeight < 0 {
+		firstHeight = 0
+	}
+	var window chainStatsWindow
+	var startTime, endTime int64
+	for height := firstHeight; height <= endHeight; height++ {
+		select {
+		case <-closeChan:
+			return nil, ErrClientQuit
+		default:
+		}
+
+		stats, err := fetchStats(height)
+		if err != nil {
+			context := fmt.Sprintf("Failed to load block at height %d",
+				height)
+			return nil, rpcInternalError(err.Error(), context)
+		}
+		if height == firstHeight {
+			startTime = stats.Time
+		}
+		if height == endHeight {
+			endTime = stats.Time
+		}
+		if height >= startHeight {
+			window.add(&stats)
+		}
+	}
+
+	// The window interval spans the blocks of the window, so it is one
+	// block interval shorter when the window starts at the genesis block.
+	interval := endTime - startTime
+	intervals := blocks
+	if startHeight == 0 {
+		intervals--
+	}
+	result := hcjson.GetChainStatsResult{
+		StartHeight:        startHeight,
+		EndHeight:          endHeight,
+		EndHash:            endHash.String(),
+		Blocks:             window.Blocks,
+		WindowInterval:     interval,
+		TxCount:            window.TxCount,
+		TotalFees:          hcutil.Amount(window.Fees).ToCoin(),
+		AvgBlockFees:       hcutil.Amount(window.Fees / window.Blocks).ToCoin(),
+		Tickets:            window.Tickets,
+		Votes:              window.Votes,
+		Revocations:        window.Revocations,
+		StakeParticipation: window.participation(),
+	}
+	if intervals > 0 {
+		result.AvgBlockInterval = float64(interval) / float64(intervals)
+	}
+	if interval > 0 {
+		result.TxRate = float64(window.TxCount) / float64(interval)
+	}
+
+	return result, nil
+}
+
+// handleGetChainTips implements the getchaintips command.
+func handleGetChainTips(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	tips := s.chain.ChainTips()
+	result := make([]hcjson.GetChainTipsResult, 0, len(tips))
+	for _, tip := range tips {
+		result = append(result, hcjson.GetChainTipsResult{
+			Height:    tip.Height,
+			Hash:      tip.Hash.String(),
+			BranchLen: tip.BranchLen,
+			Work:      fmt.Sprintf("%064x", tip.WorkSum),
+			Status:    tip.Status.String(),
+		})
+	}
+	return result, nil
+}
+
+// handleGetCheckpointCandidates implements the getcheckpointcandidates command.
+func handleGetCheckpointCandidates(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*hcjson.GetCheckpointCandidatesCmd)
+	count := int32(1)
+	if c.Count != nil {
+		count = *c.Count
+		if count <= 0 {
+			return nil, rpcInvalidError("Count must be positive")
+		}
+	}
+
+	candidates, err := s.chain.CheckpointCandidates(int(count))
+	if err != nil {
+		return nil, rpcInternalError(err.Error(),
+			"Could not find checkpoint candidates")
+	}
+
+	result := make([]hcjson.CheckpointCandidateResult, 0, len(candidates))
+	for _, checkpoint := range candidates {
+		result = append(result, hcjson.CheckpointCandidateResult{
+			Height: checkpoint.Height,
+			Hash:   checkpoint.Hash.String(),
+		})
+	}
+	return result, nil
+}
+
+// coinSupplyResult converts the passed coin supply to its RPC result.
+func coinSupplyResult(supply *blockchain.CoinSupply) hcjson.CoinSupplyResult {
+	return hcjson.CoinSupplyResult{
+		PoW:           supply.PoW,
+		PoS:           supply.PoS,
+		Dev:           supply.Dev,
+		BlockOne:      supply.BlockOne,
+		Subsidy:       supply.Subsidy(),
+		FeesDestroyed: supply.FeesDestroyed,
+		Supply:        supply.Supply(),
+	}
+}
+
 // handleGetCoinSupply implements the getcoinsupply command.
 func handleGetCoinSupply(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
-	return s.chain.TotalSubsidy(), nil
+	c := cmd.(*hcjson.GetCoinSupplyCmd)
+
+	// The total subsidy of the best chain is kept in memory, so there is
+	// no need to load the coin supply when that is all which is requested.
+	verbose := c.Verbose != nil && *c.Verbose
+	if !verbose && c.BlockHash == nil {
+		return s.chain.TotalSubsidy(), nil
+	}
+
+	hash := s.chain.BestSnapshot().Hash
+	if c.BlockHash != nil {
+		var err error
+		hash, err = chainhash.NewHashFromStr(*c.BlockHash)
+		if err != nil {
+			return nil, rpcDecodeHexError(*c.BlockHash)
+		}
+	}
+	supply, err := s.chain.CoinSupply(hash)
+	if err != nil {
+		if blockchain.IsNotInMainChainErr(err) {
+			return nil, &hcjson.RPCError{
+				Code: hcjson.ErrRPCBlockNotFound,
+				Message: fmt.Sprintf("Block not found in the main "+
+					"chain: %v", hash),
+			}
+		}
+		context := "Failed to load coin supply"
+		return nil, rpcInternalError(err.Error(), context)
+	}
+
+	if !verbose {
+		return supply.Total.Subsidy(), nil
+	}
+	return hcjson.GetCoinSupplyResult{
+		Hash:   supply.Hash.String(),
+		Height: supply.Height,
+		Block:  coinSupplyResult(&supply.Block),
+		Total:  coinSupplyResult(&supply.Total),
+	}, nil
 }
 
 // handleGetConnectionCount implements the getconnectioncount command.
@@ -3198,6 +4570,312 @@ func handleGetHashesPerSec(s *rpcServer, cmd interface{}, closeChan <-chan struc
 	return int64(s.server.cpuMiner.HashesPerSecond()), nil
 }
 
+// handleGetLockStats implements the getlockstats command.
+func handleGetLockStats(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	stats := lockprof.Stats()
+	result := make([]hcjson.GetLockStatsResult, 0, len(stats))
+	for _, stat := range stats {
+		result = append(result, hcjson.GetLockStatsResult{
+			Lock:         stat.Lock,
+			Site:         stat.Site,
+			Acquisitions: stat.Acquisitions,
+			TotalWait:    float64(stat.TotalWait) / float64(time.Millisecond),
+			MaxWait:      float64(stat.MaxWait) / float64(time.Millisecond),
+			TotalHold:    float64(stat.TotalHold) / float64(time.Millisecond),
+			MaxHold:      float64(stat.MaxHold) / float64(time.Millisecond),
+		})
+	}
+	return result, nil
+}
+
+// handleGetMemoryInfo implements the getmemoryinfo command.
+func handleGetMemoryInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	var lastGC int64
+	if stats.LastGC != 0 {
+		lastGC = time.Unix(0, int64(stats.LastGC)).Unix()
+	}
+
+	// Report the subsystems which account for their memory usage.
+	txMemPool := s.server.txMemPool
+	sigStats := s.server.sigCache.Stats()
+	scriptStats := s.server.scriptCache.Stats()
+	subsystems := map[string]hcjson.MemorySubsystemResult{
+		"mempool": {
+			Entries: int64(txMemPool.Count()),
+			Usage:   txMemPool.MemoryUsage(),
+			Max:     int64(cfg.MaxMempool) * 1000 * 1000,
+		},
+		"sigcache": {
+			Entries: int64(sigStats.Entries),
+			Usage:   int64(sigStats.Size),
+			Max:     int64(sigStats.MaxSize),
+		},
+		"scriptcache": {
+			Entries: int64(scriptStats.Entries),
+			Usage:   int64(scriptStats.Size),
+			Max:     int64(scriptStats.MaxSize),
+		},
+	}
+
+	return &hcjson.GetMemoryInfoResult{
+		Runtime: hcjson.MemoryRuntimeResult{
+			Alloc:        stats.Alloc,
+			TotalAlloc:   stats.TotalAlloc,
+			Sys:          stats.Sys,
+			HeapAlloc:    stats.HeapAlloc,
+			HeapSys:      stats.HeapSys,
+			HeapIdle:     stats.HeapIdle,
+			HeapInuse:    stats.HeapInuse,
+			HeapReleased: stats.HeapReleased,
+			HeapObjects:  stats.HeapObjects,
+			StackInuse:   stats.StackInuse,
+			NumGC:        stats.NumGC,
+			GCPauseTotal: float64(stats.PauseTotalNs) / float64(time.Millisecond),
+			LastGC:       lastGC,
+			Goroutines:   runtime.NumGoroutine(),
+		},
+		Subsystems: subsystems,
+	}, nil
+}
+
+// handleGetProfile implements the getprofile command.
+func handleGetProfile(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*hcjson.GetProfileCmd)
+
+	var seconds int32
+	switch c.Type {
+	case profileCPU, profileMutex, profileBlock:
+		seconds = *c.Seconds
+		if seconds < 1 || time.Duration(seconds)*time.Second > maxProfileDuration {
+			return nil, rpcInvalidError("Seconds must be between 1 "+
+				"and %d", int(maxProfileDuration/time.Second))
+		}
+	case profileHeap, profileGoroutine:
+	default:
+		return nil, rpcInvalidError("Unknown profile type %q -- "+
+			"supported types are cpu, heap, goroutine, mutex and "+
+			"block", c.Type)
+	}
+
+	rpcsLog.Infof("Capturing %s profile", c.Type)
+	data, err := captureProfile(c.Type, time.Duration(seconds)*time.Second,
+		closeChan)
+	if err != nil {
+		return nil, rpcMiscError(fmt.Sprintf("Failed to capture %s "+
+			"profile: %v", c.Type, err))
+	}
+
+	return &hcjson.GetProfileResult{
+		Type:    c.Type,
+		Seconds: seconds,
+		Size:    len(data),
+		Data:    base64.StdEncoding.EncodeToString(data),
+	}, nil
+}
+
+// handleGetSlowQueries implements the getslowqueries command.
+func handleGetSlowQueries(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	queries := s.server.slowQueries.recent()
+	result := make([]hcjson.GetSlowQueriesResult, 0, len(queries))
+	for _, q := range queries {
+		result = append(result, hcjson.GetSlowQueriesResult{
+			Time:         q.time.Unix(),
+			Type:         q.kind,
+			Name:         q.name,
+			ParamsDigest: q.params,
+			Duration:     float64(q.duration) / float64(time.Millisecond),
+			LockWait:     float64(q.lockWait) / float64(time.Millisecond),
+		})
+	}
+	return result, nil
+}
+
+// handleGetHealth implements the gethealth command.
+func handleGetHealth(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	// The node is syncing until the chain is current.
+	bm := s.server.blockManager
+	best := s.chain.BestSnapshot()
+	var peerHeight int64
+	if syncPeer := bm.SyncPeer(); syncPeer != nil {
+		peerHeight = syncPeer.LastBlock()
+	}
+	current := bm.IsCurrent()
+	progress := bm.syncProgress.snapshot(best.Height, peerHeight, current,
+		s.server.chainParams.TargetTimePerBlock, time.Now())
+	syncHealth := hcjson.HealthSyncResult{
+		Status:          healthOK,
+		State:           progress.State.String(),
+		Height:          best.Height,
+		EstimatedHeight: progress.EstimatedHeight,
+		Progress:        progress.Progress,
+	}
+	if !current {
+		syncHealth.Status = healthDegraded
+	}
+
+	// Probe the database with a read-only transaction so a failing
+	// database is detected even when nothing else uses it, and report the
+	// latency percentiles of the most recent transactions, which include
+	// the probe.
+	dbHealth := hcjson.HealthDatabaseResult{Status: healthOK}
+	err := s.server.db.View(func(dbTx database.Tx) error {
+		return nil
+	})
+	if err != nil {
+		dbHealth.Status = healthError
+		dbHealth.Error = err.Error()
+	}
+	samples, latencies := s.server.dbLatency.percentiles(50, 90, 99)
+	toMillis := func(d time.Duration) float64 {
+		return float64(d) / float64(time.Millisecond)
+	}
+	dbHealth.Samples = samples
+	dbHealth.P50 = toMillis(latencies[0])
+	dbHealth.P90 = toMillis(latencies[1])
+	dbHealth.P99 = toMillis(latencies[2])
+	if dbHealth.Status == healthOK && latencies[2] > dbSlowLatency {
+		dbHealth.Status = healthDegraded
+	}
+
+	// Stored blocks which failed to verify are an error until they are
+	// repaired.
+	if v := s.server.dbVerifier; v != nil {
+		lastPass, quarantined := v.status()
+		if !lastPass.IsZero() {
+			dbHealth.LastVerified = lastPass.Unix()
+		}
+		for _, qb := range quarantined {
+			dbHealth.Quarantined = append(dbHealth.Quarantined,
+				hcjson.HealthQuarantinedBlockResult{
+					Hash:   qb.hash.String(),
+					Height: qb.height,
+					Reason: qb.reason,
+					Since:  qb.since.Unix(),
+				})
+		}
+		if len(quarantined) > 0 {
+			dbHealth.Status = healthError
+		}
+	}
+
+	// The memory pool is backed up once it nearly reached its maximum
+	// size, since transactions with the lowest fee rate are evicted and
+	// the minimum relay fee is raised from then on.
+	txMemPool := s.server.txMemPool
+	mempoolHealth := hcjson.HealthMempoolResult{
+		Status:     healthOK,
+		Size:       int64(txMemPool.Count()),
+		Usage:      txMemPool.MemoryUsage(),
+		MaxMempool: int64(cfg.MaxMempool) * 1000 * 1000,
+	}
+	if mempoolHealth.MaxMempool > 0 &&
+		mempoolHealth.Usage >= mempoolHealth.MaxMempool/10*9 {
+		mempoolHealth.Status = healthDegraded
+	}
+
+	// The node is cut off from the network without peers and is easier to
+	// partition when all of its peers are in too few network groups.
+	peersHealth := hcjson.HealthPeersResult{Status: healthOK}
+	netGroups := make(map[string]struct{})
+	for _, sp := range s.server.Peers() {
+		peersHealth.Connected++
+		if sp.Inbound() {
+			peersHealth.Inbound++
+		} else {
+			peersHealth.Outbound++
+		}
+		if na := sp.NA(); na != nil {
+			netGroups[addrmgr.GroupKey(na)] = struct{}{}
+		}
+	}
+	peersHealth.NetGroups = len(netGroups)
+	switch {
+	case peersHealth.Connected == 0:
+		peersHealth.Status = healthError
+	case peersHealth.NetGroups < minHealthyNetGroups &&
+		len(cfg.ConnectPeers) == 0:
+		peersHealth.Status = healthDegraded
+	}
+
+	errs := make(map[string]hcjson.HealthErrorResult)
+	for subsystem, err := range logErrors.lastErrors() {
+		errs[subsystem] = hcjson.HealthErrorResult{
+			Time:    err.time.Unix(),
+			Message: err.message,
+		}
+	}
+
+	return &hcjson.GetHealthResult{
+		Status: worstHealth(syncHealth.Status, dbHealth.Status,
+			mempoolHealth.Status, peersHealth.Status),
+		Sync:     syncHealth,
+		Database: dbHealth,
+		Mempool:  mempoolHealth,
+		Peers:    peersHealth,
+		Errors:   errs,
+	}, nil
+}
+
+// optionalIndexes returns the enabled optional indexes keyed by the names the
+// getindexinfo and startindexrebuild commands use for them.
+func (s *rpcServer) optionalIndexes() map[string]indexers.Indexer {
+	indexes := make(map[string]indexers.Indexer)
+	if s.server.txIndex != nil {
+		indexes["txindex"] = s.server.txIndex
+	}
+	if s.server.addrIndex != nil {
+		indexes["addrindex"] = s.server.addrIndex
+	}
+	if s.server.existsAddrIndex != nil {
+		indexes["existsaddrindex"] = s.server.existsAddrIndex
+	}
+	return indexes
+}
+
+// handleGetIndexInfo implements the getindexinfo command.
+func handleGetIndexInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*hcjson.GetIndexInfoCmd)
+
+	indexes := s.optionalIndexes()
+	if c.Index != nil {
+		indexer, ok := indexes[*c.Index]
+		if !ok {
+			return nil, rpcInvalidError("Index %q is not enabled",
+				*c.Index)
+		}
+		indexes = map[string]indexers.Indexer{*c.Index: indexer}
+	}
+
+	bestHeight := s.chain.BestSnapshot().Height
+	result := make(map[string]hcjson.IndexInfoResult, len(indexes))
+	for name, indexer := range indexes {
+		status, err := s.server.indexManager.IndexStatus(indexer)
+		if err != nil {
+			return nil, rpcInternalError(err.Error(),
+				"Could not obtain index status")
+		}
+
+		info := hcjson.IndexInfoResult{
+			Height:     status.Height,
+			CatchingUp: status.CatchingUp,
+			Rebuilding: status.Rebuilding,
+			Progress:   1,
+		}
+		if bestHeight > 0 {
+			info.Progress = float64(status.Height) / float64(bestHeight)
+		}
+		info.Synced = !status.CatchingUp && status.Err == nil &&
+			int64(status.Height) == bestHeight
+		if status.Err != nil {
+			info.Error = status.Err.Error()
+		}
+		result[name] = info
+	}
+	return result, nil
+}
+
 // handleGetHeaders implements the getheaders command.
 func handleGetHeaders(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*hcjson.GetHeadersCmd)
@@ -3268,12 +4946,43 @@ func handleGetInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (in
 		Proxy:           cfg.Proxy,
 		Difficulty:      getDifficultyRatio(best.Bits),
 		TestNet:         cfg.TestNet,
-		RelayFee:        cfg.minRelayTxFee.ToCoin(),
+		RelayFee:        configuredMinRelayTxFee().ToCoin(),
 	}
 
 	return ret, nil
 }
 
+// handleGetMempoolEntry implements the getmempoolentry command.
+func handleGetMempoolEntry(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*hcjson.GetMempoolEntryCmd)
+	txHash, err := chainhash.NewHashFromStr(c.TxID)
+	if err != nil {
+		return nil, rpcDecodeHexError(c.TxID)
+	}
+
+	entry := s.server.txMemPool.RawMempoolEntryVerbose(txHash)
+	if entry == nil {
+		return nil, rpcNoTxInfoError(txHash)
+	}
+
+	result := &hcjson.GetMempoolEntryResult{
+		Size:             entry.Size,
+		Fee:              entry.Fee,
+		Time:             entry.Time,
+		Height:           entry.Height,
+		StartingPriority: entry.StartingPriority,
+		CurrentPriority:  entry.CurrentPriority,
+		Depends:          entry.Depends,
+	}
+	if arrival, ok := s.server.txArrivals.lookup(txHash); ok {
+		result.ReceivedTime = arrival.Time.Unix()
+		result.ReceivedTimeMillis = arrival.Time.UnixNano() /
+			int64(time.Millisecond)
+		result.ReceivedFrom = arrival.Source
+	}
+	return result, nil
+}
+
 // handleGetMempoolInfo implements the getmempoolinfo command.
 func handleGetMempoolInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	mempoolTxns := s.server.txMemPool.TxDescs()
@@ -3283,9 +4992,13 @@ func handleGetMempoolInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct
 		numBytes += int64(txD.Tx.MsgTx().SerializeSize())
 	}
 
+	txMemPool := s.server.txMemPool
 	ret := &hcjson.GetMempoolInfoResult{
-		Size:  int64(len(mempoolTxns)),
-		Bytes: numBytes,
+		Size:          int64(len(mempoolTxns)),
+		Bytes:         numBytes,
+		Usage:         txMemPool.MemoryUsage(),
+		MaxMempool:    int64(cfg.MaxMempool) * 1000 * 1000,
+		MempoolMinFee: txMemPool.MinRelayTxFee().ToCoin(),
 	}
 
 	return ret, nil
@@ -3343,6 +5056,77 @@ func handleGetNetTotals(s *rpcServer, cmd interface{}, closeChan <-chan struct{}
 	return reply, nil
 }
 
+// handleGetNetworkUpgradeInfo implements the getnetworkupgradeinfo command.
+func handleGetNetworkUpgradeInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c, ok := cmd.(*hcjson.GetNetworkUpgradeInfoCmd)
+	if !ok {
+		return nil, rpcInvalidError("Invalid type: %T", c)
+	}
+
+	params := s.server.chainParams
+	blocks := int32(params.BlockUpgradeNumToCheck)
+	if c.Blocks != nil {
+		blocks = *c.Blocks
+		if blocks <= 0 {
+			return nil, rpcInvalidError("Blocks must be positive")
+		}
+	}
+
+	snapshot := s.chain.BestSnapshot()
+	ruleInterval := int64(params.RuleChangeActivationInterval)
+	svInterval := params.StakeVersionInterval
+	result := hcjson.GetNetworkUpgradeInfoResult{
+		Height: snapshot.Height,
+		Hash:   snapshot.Hash.String(),
+		RuleChangeHeight: s.chain.CalcWantHeight(ruleInterval,
+			snapshot.Height) + ruleInterval + 1,
+		StakeVersionHeight: s.chain.CalcWantHeight(svInterval,
+			snapshot.Height) + svInterval + 1,
+	}
+
+	// Tally the protocol versions of the connected peers.  Peers at the
+	// protocol version of this node or a later one count as upgraded.
+	peerVersions := newVersionTally()
+	for _, sp := range s.server.Peers() {
+		peerVersions.add(sp.StatsSnapshot().Version)
+	}
+	result.Peers = hcjson.NetworkUpgradePeersResult{
+		ProtocolVersion: maxProtocolVersion,
+		Connected:       peerVersions.total,
+		Upgraded:        peerVersions.atLeast(maxProtocolVersion),
+		Versions:        peerVersions.result(),
+	}
+
+	// Tally the block, stake and vote versions signaled by the most recent
+	// blocks.  The block version thresholds apply to the same window by
+	// default.
+	sv, err := s.chain.GetStakeVersions(snapshot.Hash, blocks)
+	if err != nil {
+		return nil, rpcInternalError(err.Error(),
+			"Could not obtain stake versions")
+	}
+	blockVersions := newVersionTally()
+	stakeVersions := newVersionTally()
+	voteVersions := newVersionTally()
+	for _, v := range sv {
+		blockVersions.add(uint32(v.BlockVersion))
+		stakeVersions.add(v.StakeVersion)
+		for _, vote := range v.Votes {
+			voteVersions.add(vote.Version)
+		}
+	}
+	result.Blocks = hcjson.NetworkUpgradeBlocksResult{
+		Window:          uint32(len(sv)),
+		EnforceRequired: params.BlockEnforceNumRequired,
+		RejectRequired:  params.BlockRejectNumRequired,
+		BlockVersions:   blockVersions.result(),
+		StakeVersions:   stakeVersions.result(),
+		VoteVersions:    voteVersions.result(),
+	}
+
+	return result, nil
+}
+
 // handleGetNetworkHashPS implements the getnetworkhashps command.
 func handleGetNetworkHashPS(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	// Note: All valid error return paths should return an int64.  Literal
@@ -3363,93 +5147,37 @@ func handleGetNetworkHashPS(s *rpcServer, cmd interface{}, closeChan <-chan stru
 	if endHeight > best.Height || endHeight == 0 {
 		return int64(0), nil
 	}
-	if endHeight < 0 {
-		endHeight = best.Height
-	}
-
-	// Calculate the number of blocks per retarget interval based on the
-	// chain parameters.
-	blocksPerRetarget := int64(s.server.chainParams.TargetTimespan /
-		s.server.chainParams.TargetTimePerBlock)
-
-	// Calculate the starting block height based on the passed number of
-	// blocks.  When the passed value is negative, use the last block the
-	// difficulty changed as the starting height.  Also make sure the
-	// starting height is not before the beginning of the chain.
 
 	numBlocks := int64(120)
 	if c.Blocks != nil {
 		numBlocks = int64(*c.Blocks)
 	}
 
-	var startHeight int64
-	if numBlocks <= 0 {
-		startHeight = endHeight - ((endHeight % blocksPerRetarget) + 1)
-	} else {
-		startHeight = endHeight - numBlocks
-	}
-	if startHeight < 0 {
-		startHeight = 0
+	// Estimate the hash rate from the work and the timestamps of the
+	// blocks.  A non-positive number of blocks estimates it from the blocks
+	// since the last difficulty change.
+	estimate, err := s.chain.EstimateNetworkHashRate(endHeight, numBlocks)
+	if err != nil {
+		context := "Failed to estimate network hash rate"
+		return nil, rpcInternalError(err.Error(), context)
 	}
-	rpcsLog.Debugf("Calculating network hashes per second from %d to %d",
-		startHeight, endHeight)
-
-	// Find the min and max block timestamps as well as calculate the total
-	// amount of work that happened between the start and end blocks.
-	var minTimestamp, maxTimestamp time.Time
-	totalWork := big.NewInt(0)
-	for curHeight := startHeight; curHeight <= endHeight; curHeight++ {
-		hash, err := s.chain.BlockHashByHeight(curHeight)
-		if err != nil {
-			context := "Failed to fetch block hash"
-			return nil, rpcInternalError(err.Error(), context)
-		}
-
-		// Load the raw header bytes.
-		var headerBytes []byte
-		err = s.server.db.View(func(dbTx database.Tx) error {
-			var err error
-			headerBytes, err = dbTx.FetchBlockHeader(hash)
-			return err
-		})
-		if err != nil {
-			context := "Failed to fetch block header"
-			return nil, rpcInternalError(err.Error(), context)
-		}
-
-		// Deserialize the header.
-		var header wire.BlockHeader
-		err = header.Deserialize(bytes.NewReader(headerBytes))
-		if err != nil {
-			context := "Failed to deserialize block header"
-			return nil, rpcInternalError(err.Error(), context)
-		}
+	rpcsLog.Debugf("Calculated network hashes per second from %d to %d",
+		estimate.StartHeight, estimate.EndHeight)
 
-		if curHeight == startHeight {
-			minTimestamp = header.Timestamp
-			maxTimestamp = minTimestamp
-		} else {
-			totalWork.Add(totalWork, blockchain.CalcWork(header.Bits))
+	return estimate.HashesPerSec(), nil
+}
 
-			if minTimestamp.After(header.Timestamp) {
-				minTimestamp = header.Timestamp
-			}
-			if maxTimestamp.Before(header.Timestamp) {
-				maxTimestamp = header.Timestamp
-			}
+// peerInfoMsgStats converts the passed per message statistics of a peer to
+// the form returned by the getpeerinfo command.
+func peerInfoMsgStats(perMsg map[string]peer.MsgStats) map[string]hcjson.GetPeerInfoMsgStats {
+	result := make(map[string]hcjson.GetPeerInfoMsgStats, len(perMsg))
+	for command, stats := range perMsg {
+		result[command] = hcjson.GetPeerInfoMsgStats{
+			Count: stats.Count,
+			Bytes: stats.Bytes,
 		}
 	}
-
-	// Calculate the difference in seconds between the min and max block
-	// timestamps and avoid division by zero in the case where there is no
-	// time difference.
-	timeDiff := int64(maxTimestamp.Sub(minTimestamp) / time.Second)
-	if timeDiff == 0 {
-		return int64(0), nil
-	}
-
-	hashesPerSec := new(big.Int).Div(totalWork, big.NewInt(timeDiff))
-	return hashesPerSec.Int64(), nil
+	return result
 }
 
 // handleGetPeerInfo implements the getpeerinfo command.
@@ -3483,6 +5211,11 @@ func handleGetPeerInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{})
 			// We actually want microseconds.
 			info.PingWait = wait / 1000
 		}
+		info.SentPerMsg = peerInfoMsgStats(statsSnap.SentPerMsg)
+		info.RecvPerMsg = peerInfoMsgStats(statsSnap.RecvPerMsg)
+		if len(statsSnap.Features) > 0 {
+			info.Features = statsSnap.Features
+		}
 		infos = append(infos, info)
 	}
 	return infos, nil
@@ -3669,6 +5402,45 @@ func handleGetRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan str
 	return *rawTxn, nil
 }
 
+// handleGetRevocableTickets implements the getrevocabletickets command.
+func handleGetRevocableTickets(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	tickets, hash, height, err := s.chain.RevocableTickets()
+	if err != nil {
+		context := "Failed to load revocable tickets"
+		return nil, rpcInternalError(err.Error(), context)
+	}
+
+	result := hcjson.GetRevocableTicketsResult{
+		Hash:    hash.String(),
+		Height:  height,
+		Tickets: make([]hcjson.RevocableTicketResult, 0, len(tickets)),
+	}
+	for _, ticket := range tickets {
+		outputs := make([]hcjson.RevocationOutputResult, 0,
+			len(ticket.Outputs))
+		for _, out := range ticket.Outputs {
+			outputs = append(outputs, hcjson.RevocationOutputResult{
+				Address: out.Address.EncodeAddress(),
+				Amount:  hcutil.Amount(out.Amount).ToCoin(),
+				MaxFee:  hcutil.Amount(out.MaxFee).ToCoin(),
+			})
+		}
+		result.Tickets = append(result.Tickets, hcjson.RevocableTicketResult{
+			Ticket:        ticket.Hash.String(),
+			Height:        ticket.Height,
+			Expired:       ticket.Expired,
+			Vout:          ticket.PrevOut.Index,
+			Tree:          ticket.PrevOut.Tree,
+			Amount:        hcutil.Amount(ticket.Amount).ToCoin(),
+			ScriptVersion: ticket.ScriptVersion,
+			ScriptPubKey:  hex.EncodeToString(ticket.PkScript),
+			Outputs:       outputs,
+		})
+	}
+
+	return result, nil
+}
+
 // handleGetStakeDifficulty implements the getstakedifficulty command.
 func handleGetStakeDifficulty(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	best := s.chain.BestSnapshot()
@@ -3725,12 +5497,19 @@ func handleGetBlockchainInfo(s *rpcServer, cmd interface{}, closeChan <-chan str
 		return nil, rpcInternalError(err.Error(), "Could not fetch chain work.")
 	}
 
-	// Estimate the verification progress of the node.
-	syncHeight := s.server.blockManager.chainState.newestHeight
-	var verifyProgress float64
-	if syncHeight > 0 {
-		verifyProgress = math.Min(float64(best.Height)/float64(syncHeight), 1.0)
+	// Estimate the verification progress of the node from the height of the
+	// best chain of the network as extrapolated by the initial block download
+	// progress tracker.
+	bm := s.server.blockManager
+	_, syncHeight := bm.chainState.Best()
+	var syncPeerAddr string
+	var peerHeight int64
+	if syncPeer := bm.SyncPeer(); syncPeer != nil {
+		syncPeerAddr = syncPeer.Addr()
+		peerHeight = syncPeer.LastBlock()
 	}
+	progress := bm.syncProgress.snapshot(best.Height, peerHeight,
+		bm.IsCurrent(), s.server.chainParams.TargetTimePerBlock, time.Now())
 
 	// Fetch the maximum allowed block size.
 	maxBlockSize, err := s.chain.MaxBlockSize()
@@ -3775,13 +5554,17 @@ func handleGetBlockchainInfo(s *rpcServer, cmd interface{}, closeChan <-chan str
 
 	// Generate rpc response.
 	response := hcjson.GetBlockChainInfoResult{
-		Chain:      s.server.chainParams.Name,
-		Blocks:     int32(best.Height),
-		Headers:    int32(best.Height),
-		SyncHeight: syncHeight,
-		ChainWork:  fmt.Sprintf("%064x", chainWork),
-		//InitialBlockDownload: !s.chain.IsCurrent(),
-		VerificationProgress: verifyProgress,
+		Chain:                s.server.chainParams.Name,
+		Blocks:               int32(best.Height),
+		Headers:              int32(progress.HeadersHeight),
+		EstimatedHeaders:     progress.EstimatedHeight,
+		SyncHeight:           syncHeight,
+		ChainWork:            fmt.Sprintf("%064x", chainWork),
+		InitialBlockDownload: progress.State != syncStateCurrent,
+		SyncState:            progress.State.String(),
+		SyncPeer:             syncPeerAddr,
+		DownloadSpeed:        progress.BlocksPerSec,
+		VerificationProgress: progress.Progress,
 		BestBlockHash:        best.Hash.String(),
 		Difficulty:           float64(best.Bits),
 		DifficultyRatio:      getDifficultyRatio(best.Bits),
@@ -3806,63 +5589,110 @@ func handleGetStakeVersionInfo(s *rpcServer, cmd interface{}, closeChan <-chan s
 		}
 	}
 
+	// The tallies of complete intervals are cached by the chain, so this
+	// does not need to rescan their blocks.
 	snapshot := s.chain.BestSnapshot()
+	tallies, err := s.chain.StakeVersionTallies(snapshot.Hash, count)
+	if err != nil {
+		return nil, rpcInternalError(err.Error(),
+			"handleGetStakeVersionInfo")
+	}
 
-	interval := s.server.chainParams.StakeVersionInterval
 	// Assemble JSON result.
 	result := hcjson.GetStakeVersionInfoResult{
 		CurrentHeight: snapshot.Height,
 		Hash:          snapshot.Hash.String(),
-		Intervals:     make([]hcjson.VersionInterval, 0, count),
-	}
-
-	startHeight := snapshot.Height
-	endHeight := s.chain.CalcWantHeight(interval,
-		snapshot.Height) + 1
-	hash := snapshot.Hash
-	adjust := int32(1) // We are off by one on the initial iteration.
-	for i := int32(0); i < count; i++ {
-		numBlocks := int32(startHeight - endHeight)
-		if numBlocks <= 0 {
-			// Just return what we got.
-			break
-		}
-		sv, err := s.chain.GetStakeVersions(hash, numBlocks+adjust)
-		if err != nil {
-			return nil, rpcInternalError(err.Error(),
-				"handleGetStakeVersionInfo")
+		Intervals:     make([]hcjson.VersionInterval, 0, len(tallies)),
+	}
+	for i := range tallies {
+		tally := &tallies[i]
+		posVersions := make(map[int]int, len(tally.StakeVersions))
+		for version, n := range tally.StakeVersions {
+			posVersions[int(version)] = int(n)
 		}
-
-		posVersions := make(map[int]int)
-		voteVersions := make(map[int]int)
-		for _, v := range sv {
-			posVersions[int(v.StakeVersion)]++
-			for _, vote := range v.Votes {
-				voteVersions[int(vote.Version)]++
-			}
+		voteVersions := make(map[int]int, len(tally.VoteVersions))
+		for version, n := range tally.VoteVersions {
+			voteVersions[int(version)] = int(n)
 		}
-		versionInterval := hcjson.VersionInterval{
-			StartHeight:  endHeight,
-			EndHeight:    startHeight,
+		result.Intervals = append(result.Intervals, hcjson.VersionInterval{
+			StartHeight:  tally.StartHeight,
+			EndHeight:    tally.EndHeight,
 			PoSVersions:  convertVersionMap(posVersions),
 			VoteVersions: convertVersionMap(voteVersions),
+			VoteBits:     convertVoteBitsMap(tally.VoteBits),
+			Agendas: tallyAgendaChoices(s.server.chainParams,
+				tally.VoteBits),
+		})
+	}
+
+	return result, nil
+}
+
+// convertVoteBitsMap converts the passed counts of the votes by their version
+// and vote bits to a slice which is sorted by version and then bits.
+func convertVoteBitsMap(m map[blockchain.VoteVersionTuple]uint32) []hcjson.VersionBitsCount {
+	sorted := make([]hcjson.VersionBitsCount, 0, len(m))
+	for vote, n := range m {
+		sorted = append(sorted, hcjson.VersionBitsCount{
+			Version: vote.Version,
+			Bits:    vote.Bits,
+			Count:   n,
+		})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Version != sorted[j].Version {
+			return sorted[i].Version < sorted[j].Version
 		}
-		result.Intervals = append(result.Intervals, versionInterval)
+		return sorted[i].Bits < sorted[j].Bits
+	})
 
-		// Adjust interval.
-		endHeight -= interval
-		startHeight = endHeight + interval
-		adjust = 0
+	return sorted
+}
 
-		// Get prior block hash.
-		hash, err = s.chain.BlockHashByHeight(startHeight - 1)
-		if err != nil {
-			return nil, rpcInternalError(err.Error(),
-				"handleGetStakeVersionInfo")
+// tallyAgendaChoices decodes the passed counts of the votes by their version
+// and vote bits into the choices picked for each agenda of the vote versions.
+// Votes whose bits do not match any choice of an agenda are not counted for
+// it.  The agendas are sorted by version and then in the order of the
+// deployments, and the choices are in the order of the agenda.
+func tallyAgendaChoices(params *chaincfg.Params, m map[blockchain.VoteVersionTuple]uint32) []hcjson.AgendaTally {
+	versions := make([]int, 0, len(params.Deployments))
+	for version := range params.Deployments {
+		versions = append(versions, int(version))
+	}
+	sort.Ints(versions)
+
+	agendas := make([]hcjson.AgendaTally, 0)
+	for _, version := range versions {
+		for _, deployment := range params.Deployments[uint32(version)] {
+			vote := &deployment.Vote
+			agenda := hcjson.AgendaTally{
+				ID:      vote.Id,
+				Version: uint32(version),
+				Choices: make([]hcjson.AgendaChoiceCount, 0,
+					len(vote.Choices)),
+			}
+			var voted bool
+			for _, choice := range vote.Choices {
+				var n uint32
+				for tuple, count := range m {
+					if tuple.Version == uint32(version) &&
+						tuple.Bits&vote.Mask == choice.Bits {
+						n += count
+					}
+				}
+				if n != 0 {
+					voted = true
+				}
+				agenda.Choices = append(agenda.Choices,
+					hcjson.AgendaChoiceCount{ID: choice.Id, Count: n})
+			}
+			if voted {
+				agendas = append(agendas, agenda)
+			}
 		}
 	}
 
-	return result, nil
+	return agendas
 }
 
 // handleGetStakeVersions implements the getstakeversions command.
@@ -3957,7 +5787,7 @@ func handleGetVoteInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{})
 	}
 
 	result.Agendas = make([]hcjson.Agenda, 0, len(vi.Agendas))
-	for _, agenda := range vi.Agendas {
+	for i, agenda := range vi.Agendas {
 		a := hcjson.Agenda{
 			Id:          agenda.Vote.Id,
 			Description: agenda.Vote.Description,
@@ -3980,15 +5810,13 @@ func handleGetVoteInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{})
 			a.Choices = append(a.Choices, c)
 		}
 
-		// Obtain status of agenda.
-		state, err := s.chain.ThresholdState(snapshot.Hash, c.Version,
-			agenda.Vote.Id)
-		if err != nil {
-			return nil, err
-		}
-
-		// Save off status.
+		// Save off status along with the choice the agenda locked in,
+		// activated or failed with.
+		state := vi.AgendaStatus[i]
 		a.Status = state.String()
+		if state.Choice < uint32(len(agenda.Vote.Choices)) {
+			a.Choice = agenda.Vote.Choices[state.Choice].Id
+		}
 
 		if state.State != blockchain.ThresholdStarted {
 			// Append transformed agenda without progress.
@@ -4010,9 +5838,13 @@ func handleGetVoteInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{})
 		}
 		a.QuorumProgress = float64(qmin) / float64(quorum)
 
-		// Calcualte choice progress.
+		// Calcualte choice progress.  There is no progress yet when no
+		// votes were cast in the current window.
 		for k := range a.Choices {
 			a.Choices[k].Count = counts.VoteChoices[k]
+			if counts.Total == 0 {
+				continue
+			}
 			a.Choices[k].Progress = float64(counts.VoteChoices[k]) /
 				float64(counts.Total)
 		}
@@ -4159,6 +5991,73 @@ func handleGetTxOut(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (i
 	return txOutReply, nil
 }
 
+// handleGetUtxoSet implements the getutxoset command.
+func handleGetUtxoSet(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*hcjson.GetUtxoSetCmd)
+
+	count := int32(1000)
+	if c.Count != nil {
+		count = *c.Count
+	}
+	if count <= 0 || count > maxUtxoSetCount {
+		return nil, rpcInvalidError("Count must be between 1 and %d",
+			maxUtxoSetCount)
+	}
+
+	// Start a new iteration over the utxo set unless an open cursor is
+	// passed.
+	var id string
+	if c.Cursor != nil {
+		id = *c.Cursor
+	}
+	if id == "" {
+		iter, err := s.chain.UtxoSetIterator()
+		if err != nil {
+			context := "Failed to open the utxo set"
+			return nil, rpcInternalError(err.Error(), context)
+		}
+		id, err = s.utxoSetCursors.open(iter)
+		if err != nil {
+			return nil, rpcMiscError(err.Error())
+		}
+	}
+	page, err := s.utxoSetCursors.next(id, int(count))
+	if err == errUtxoSetCursorUnknown {
+		return nil, rpcInvalidError("Unknown or expired cursor %q", id)
+	}
+	if err != nil {
+		context := "Failed to read the utxo set"
+		return nil, rpcInternalError(err.Error(), context)
+	}
+
+	result := hcjson.GetUtxoSetResult{
+		Hash:   page.hash.String(),
+		Height: page.height,
+		Done:   page.done,
+		Utxos:  make([]hcjson.UtxoSetEntryResult, 0, len(page.outputs)),
+	}
+	if !page.done {
+		result.Cursor = id
+	}
+	for i := range page.outputs {
+		out := &page.outputs[i]
+		result.Utxos = append(result.Utxos, hcjson.UtxoSetEntryResult{
+			TxID:          out.OutPoint.Hash.String(),
+			Vout:          out.OutPoint.Index,
+			Tree:          out.OutPoint.Tree,
+			Amount:        hcutil.Amount(out.Amount).ToCoin(),
+			Height:        out.Height,
+			BlockIndex:    out.BlockIndex,
+			Coinbase:      out.IsCoinBase,
+			TxType:        txTypeName(out.TxType),
+			ScriptVersion: out.ScriptVersion,
+			PkScript:      hex.EncodeToString(out.PkScript),
+		})
+	}
+
+	return result, nil
+}
+
 // pruneOldBlockTemplates prunes all old block templates from the templatePool
 // map. Must be called with the RPC workstate locked to avoid races to the map.
 func pruneOldBlockTemplates(s *rpcServer, bestHeight int64) {
@@ -4423,7 +6322,7 @@ func handleGetWorkSubmission(s *rpcServer, hexData string) (interface{}, error)
 	// Process this block using the same rules as blocks coming from other
 	// nodes.  This will in turn relay it to the network like normal.
 	isOrphan, err := s.server.blockManager.ProcessBlock(block,
-		blockchain.BFNone)
+		blockchain.BFNone, nil)
 	if err != nil || isOrphan {
 		// Anything other than a rule violation is an unexpected error,
 		// so return that error as an internal error.
@@ -4575,6 +6474,43 @@ func handlePing(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (inter
 	return nil, nil
 }
 
+// handlePrioritiseTransaction implements the prioritisetransaction command.
+func handlePrioritiseTransaction(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*hcjson.PrioritiseTransactionCmd)
+	txHash, err := chainhash.NewHashFromStr(c.TxID)
+	if err != nil {
+		return nil, rpcDecodeHexError(c.TxID)
+	}
+
+	s.server.txMemPool.PrioritiseTransaction(txHash, c.PriorityDelta,
+		c.FeeDelta)
+	return true, nil
+}
+
+// handleReloadConfig implements the reloadconfig command.
+func handleReloadConfig(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	if err := s.server.ReloadConfig(); err != nil {
+		return nil, rpcInternalError(err.Error(),
+			"Could not reload configuration")
+	}
+	return nil, nil
+}
+
+// handleReconsiderBlock implements the reconsiderblock command.
+func handleReconsiderBlock(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*hcjson.ReconsiderBlockCmd)
+	hash, err := chainhash.NewHashFromStr(c.BlockHash)
+	if err != nil {
+		return nil, rpcDecodeHexError(c.BlockHash)
+	}
+
+	err = s.server.blockManager.ReconsiderBlock(hash)
+	if err != nil {
+		return nil, rpcMiscError(err.Error())
+	}
+	return nil, nil
+}
+
 // handleRebroadcastMissed implements the rebroadcastmissed command.
 func handleRebroadcastMissed(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	hash, height := s.server.blockManager.chainState.Best()
@@ -5148,7 +7084,7 @@ func handleSearchRawTransactions(s *rpcServer, cmd interface{}, closeChan <-chan
 }
 
 // handleSendRawTransaction implements the sendrawtransaction command.
-func handleSendRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+func handleSendRawTransaction(s *rpcServer, cmd interface{}, span *tracing.Span, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*hcjson.SendRawTransactionCmd)
 	// Deserialize and send off to tx relay
 
@@ -5169,8 +7105,10 @@ func handleSendRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan st
 	}
 
 	tx := hcutil.NewTx(msgtx)
+	span.SetAttribute("tx.hash", tx.Hash().String())
+	s.server.txArrivals.record(tx.Hash(), arrivalSourceRPC, time.Now())
 	acceptedTxs, err := s.server.blockManager.ProcessTransaction(tx, false,
-		false, allowHighFees)
+		false, allowHighFees, span)
 	if err != nil {
 		// When the error is a rule error, it means the transaction was
 		// simply rejected as opposed to something actually going
@@ -5191,7 +7129,9 @@ func handleSendRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan st
 		return nil, rpcDeserializationError("rejected: %v", err)
 	}
 
+	relaySpan := span.StartChild("server.AnnounceNewTransactions")
 	s.server.AnnounceNewTransactions(acceptedTxs)
+	relaySpan.End()
 
 	// Keep track of all the sendrawtransaction request txns so that they
 	// can be rebroadcast if they don't make their way into a block.
@@ -5201,6 +7141,81 @@ func handleSendRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan st
 	return tx.Hash().String(), nil
 }
 
+// handleSubmitRawTransactionPackage implements the submitrawtransactionpackage
+// command.
+func handleSubmitRawTransactionPackage(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*hcjson.SubmitRawTransactionPackageCmd)
+
+	// Deserialize all of the transactions in the package.
+	txns := make([]*hcutil.Tx, 0, len(c.HexTxs))
+	for _, hexStr := range c.HexTxs {
+		if len(hexStr)%2 != 0 {
+			hexStr = "0" + hexStr
+		}
+		serializedTx, err := hex.DecodeString(hexStr)
+		if err != nil {
+			return nil, rpcDecodeHexError(hexStr)
+		}
+		msgtx := wire.NewMsgTx()
+		err = msgtx.Deserialize(bytes.NewReader(serializedTx))
+		if err != nil {
+			return nil, rpcDeserializationError("Could not decode "+
+				"Tx: %v", err)
+		}
+		tx := hcutil.NewTx(msgtx)
+		s.server.txArrivals.record(tx.Hash(), arrivalSourceRPC, time.Now())
+		txns = append(txns, tx)
+	}
+	if len(txns) == 0 {
+		return nil, rpcInvalidError("Package must contain at least " +
+			"one transaction")
+	}
+
+	acceptedTxs, txErrs := s.server.blockManager.ProcessPackage(txns,
+		*c.AllowHighFees)
+
+	// Build the per-transaction results.  The package is either accepted
+	// or rejected as a whole, so it is accepted when none of the
+	// transactions have a rejection reason.
+	result := &hcjson.SubmitRawTransactionPackageResult{
+		Accepted:     true,
+		Transactions: make([]hcjson.SubmitRawTransactionPackageTxResult, 0, len(txns)),
+	}
+	for i, tx := range txns {
+		txResult := hcjson.SubmitRawTransactionPackageTxResult{
+			Txid: tx.Hash().String(),
+		}
+		if err := txErrs[i]; err != nil {
+			// Log rule errors at debug level since they simply
+			// indicate the package was rejected.
+			if _, ok := err.(mempool.RuleError); ok {
+				rpcsLog.Debugf("Rejected package transaction %v: %v",
+					tx.Hash(), err)
+			} else {
+				rpcsLog.Errorf("Failed to process package "+
+					"transaction %v: %v", tx.Hash(), err)
+			}
+			result.Accepted = false
+			txResult.Reason = err.Error()
+		}
+		result.Transactions = append(result.Transactions, txResult)
+	}
+	if !result.Accepted {
+		return result, nil
+	}
+
+	s.server.AnnounceNewTransactions(acceptedTxs)
+
+	// Keep track of all of the package transactions so that they can be
+	// rebroadcast if they don't make their way into a block.
+	for _, tx := range txns {
+		iv := wire.NewInvVect(wire.InvTypeTx, tx.Hash())
+		s.server.AddRebroadcastInventory(iv, tx)
+	}
+
+	return result, nil
+}
+
 // handleSetGenerate implements the setgenerate command.
 func handleSetGenerate(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*hcjson.SetGenerateCmd)
@@ -5234,6 +7249,69 @@ func handleSetGenerate(s *rpcServer, cmd interface{}, closeChan <-chan struct{})
 	return nil, nil
 }
 
+// handleSetMaxPeers implements the setmaxpeers command.
+func handleSetMaxPeers(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*hcjson.SetMaxPeersCmd)
+	if c.MaxPeers < 0 {
+		return nil, rpcInvalidError("The maximum number of peers must " +
+			"not be negative")
+	}
+
+	s.server.SetMaxPeers(int(c.MaxPeers))
+	return nil, nil
+}
+
+// handleSetTxRebroadcast implements the settxrebroadcast command.
+func handleSetTxRebroadcast(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*hcjson.SetTxRebroadcastCmd)
+
+	// Convert the intervals, which are specified in seconds.
+	var interval, maxInterval *time.Duration
+	if c.Interval != nil {
+		if *c.Interval <= 0 {
+			return nil, rpcInvalidError("Interval must be positive")
+		}
+		d := time.Duration(*c.Interval) * time.Second
+		interval = &d
+	}
+	if c.MaxInterval != nil {
+		if *c.MaxInterval <= 0 {
+			return nil, rpcInvalidError("Max interval must be positive")
+		}
+		d := time.Duration(*c.MaxInterval) * time.Second
+		maxInterval = &d
+	}
+	if interval != nil && maxInterval != nil && *interval > *maxInterval {
+		return nil, rpcInvalidError("Interval must not exceed the max " +
+			"interval")
+	}
+
+	state, err := s.server.SetTxRebroadcast(c.Enable, interval, maxInterval)
+	if err != nil {
+		return nil, rpcInternalError(err.Error(), "")
+	}
+	return &hcjson.SetTxRebroadcastResult{
+		Enabled:     state.enabled,
+		Interval:    int64(state.interval / time.Second),
+		MaxInterval: int64(state.maxInterval / time.Second),
+		Pending:     state.pending,
+	}, nil
+}
+
+// handleStartIndexRebuild implements the startindexrebuild command.
+func handleStartIndexRebuild(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*hcjson.StartIndexRebuildCmd)
+
+	indexer, ok := s.optionalIndexes()[c.Index]
+	if !ok {
+		return nil, rpcInvalidError("Index %q is not enabled", c.Index)
+	}
+	if err := s.server.RebuildIndex(indexer); err != nil {
+		return nil, rpcMiscError(err.Error())
+	}
+	return nil, nil
+}
+
 // handleStop implements the stop command.
 func handleStop(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	select {
@@ -5244,7 +7322,7 @@ func handleStop(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (inter
 }
 
 // handleSubmitBlock implements the submitblock command.
-func handleSubmitBlock(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+func handleSubmitBlock(s *rpcServer, cmd interface{}, span *tracing.Span, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*hcjson.SubmitBlockCmd)
 	// Deserialize the submitted block.
 	hexStr := c.HexBlock
@@ -5261,8 +7339,12 @@ func handleSubmitBlock(s *rpcServer, cmd interface{}, closeChan <-chan struct{})
 		return nil, rpcInternalError(err.Error(), "Block decode")
 	}
 
-	_, err = s.server.blockManager.ProcessBlock(block, blockchain.BFNone)
+	span.SetAttribute("block.hash", block.Hash().String())
+	s.server.blockArrivals.record(block.Hash(), arrivalSourceRPC, time.Now())
+	_, err = s.server.blockManager.ProcessBlock(block, blockchain.BFNone,
+		span)
 	if err != nil {
+		span.SetError(err)
 		return fmt.Sprintf("rejected: %v", err), nil
 	}
 
@@ -5744,6 +7826,91 @@ func handleTxFeeInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (
 	}, nil
 }
 
+// handleTraceScript implements the tracescript command.
+func handleTraceScript(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*hcjson.TraceScriptCmd)
+
+	// Deserialize the transaction.
+	hexStr := c.HexTx
+	if len(hexStr)%2 != 0 {
+		hexStr = "0" + hexStr
+	}
+	serializedTx, err := hex.DecodeString(hexStr)
+	if err != nil {
+		return nil, rpcDecodeHexError(hexStr)
+	}
+	var mtx wire.MsgTx
+	err = mtx.Deserialize(bytes.NewReader(serializedTx))
+	if err != nil {
+		return nil, rpcDeserializationError("Could not decode Tx: %v",
+			err)
+	}
+	if c.InputIndex < 0 || c.InputIndex >= len(mtx.TxIn) {
+		return nil, rpcInvalidError("Input index %d is out of range "+
+			"for transaction with %d inputs", c.InputIndex,
+			len(mtx.TxIn))
+	}
+
+	// Convert the public key script being redeemed to bytes.
+	hexStr = c.PkScript
+	if len(hexStr)%2 != 0 {
+		hexStr = "0" + hexStr
+	}
+	pkScript, err := hex.DecodeString(hexStr)
+	if err != nil {
+		return nil, rpcDecodeHexError(hexStr)
+	}
+
+	// Execute the scripts with the same flags used to accept transactions
+	// to the memory pool while recording every executed opcode.
+	flags, err := standardScriptVerifyFlags(s.server.blockManager.chain)
+	if err != nil {
+		context := "Failed to obtain script verification flags"
+		return nil, rpcInternalError(err.Error(), context)
+	}
+	result := &hcjson.TraceScriptResult{
+		Flags: uint32(flags),
+		Steps: []hcjson.TraceScriptStep{},
+	}
+	vm, err := txscript.NewEngine(pkScript, &mtx, c.InputIndex, flags,
+		txscript.DefaultScriptVersion, nil)
+	if err != nil {
+		result.Error = err.Error()
+		return result, nil
+	}
+	vm.EnableTracing()
+	err = vm.Execute()
+	result.Valid = err == nil
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	hexStack := func(stack [][]byte) []string {
+		items := make([]string, len(stack))
+		for i, item := range stack {
+			items[i] = hex.EncodeToString(item)
+		}
+		return items
+	}
+	steps := vm.DebugSteps()
+	for steps.Next() {
+		step := steps.Step()
+		traceStep := hcjson.TraceScriptStep{
+			ScriptIdx: step.ScriptIdx,
+			ScriptOff: step.ScriptOff,
+			Opcode:    step.Opcode,
+			Stack:     hexStack(step.Stack),
+			AltStack:  hexStack(step.AltStack),
+		}
+		if step.Err != nil {
+			traceStep.Error = step.Err.Error()
+		}
+		result.Steps = append(result.Steps, traceStep)
+	}
+
+	return result, nil
+}
+
 // handleValidateAddress implements the validateaddress command.
 func handleValidateAddress(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*hcjson.ValidateAddressCmd)
@@ -5758,6 +7925,22 @@ func handleValidateAddress(s *rpcServer, cmd interface{}, closeChan <-chan struc
 	result.Address = addr.EncodeAddress()
 	result.IsValid = true
 
+	// Describe the script which pays to the address along with the public
+	// key and signature scheme it commits to, when known.
+	_, result.IsScript = addr.(*hcutil.AddressScriptHash)
+	if !result.IsScript {
+		result.SigType = sigTypeName(addr.DSA(s.server.chainParams))
+	}
+	if _, ok := addr.(pubKeyAddress); ok {
+		result.PubKey = hex.EncodeToString(addr.ScriptAddress())
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err == nil {
+		result.ScriptPubKey = hex.EncodeToString(pkScript)
+		result.Type = txscript.GetScriptClass(
+			txscript.DefaultScriptVersion, pkScript).String()
+	}
+
 	return result, nil
 }
 
@@ -5813,6 +7996,54 @@ func handleVerifyChain(s *rpcServer, cmd interface{}, closeChan <-chan struct{})
 	return err == nil, nil
 }
 
+// handleVerifyHeaderChain implements the verifyheaderchain command.
+func handleVerifyHeaderChain(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*hcjson.VerifyHeaderChainCmd)
+
+	if len(c.Headers) == 0 {
+		return nil, rpcInvalidError("No headers provided")
+	}
+	if len(c.Headers) > wire.MaxBlockHeadersPerMsg {
+		return nil, rpcInvalidError("Too many headers provided: %d "+
+			"(max %d)", len(c.Headers), wire.MaxBlockHeadersPerMsg)
+	}
+
+	headers := make([]wire.BlockHeader, len(c.Headers))
+	for i, hexHeader := range c.Headers {
+		serialized, err := hex.DecodeString(hexHeader)
+		if err != nil {
+			return nil, rpcDecodeHexError(hexHeader)
+		}
+		err = headers[i].FromBytes(serialized)
+		if err != nil {
+			return nil, rpcDeserializationError("Could not decode "+
+				"header %d: %v", i, err)
+		}
+	}
+
+	// Headers which violate the rules are reported in the result rather
+	// than as an error so callers can compare the reason with their own.
+	chainWork, err := s.chain.VerifyHeaderChain(headers)
+	if err != nil {
+		if _, ok := err.(blockchain.RuleError); !ok {
+			return nil, rpcInternalError(err.Error(),
+				"Could not verify headers")
+		}
+		return &hcjson.VerifyHeaderChainResult{
+			Valid: false,
+			Error: err.Error(),
+		}, nil
+	}
+
+	last := &headers[len(headers)-1]
+	return &hcjson.VerifyHeaderChainResult{
+		Valid:     true,
+		Height:    int64(last.Height),
+		Hash:      last.BlockHash().String(),
+		ChainWork: fmt.Sprintf("%064x", chainWork),
+	}, nil
+}
+
 // handleVerifyMessage implements the verifymessage command.
 func handleVerifyMessage(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*hcjson.VerifyMessageCmd)
@@ -5931,12 +8162,17 @@ type rpcServer struct {
 	chain                  *blockchain.BlockChain
 	authsha                [sha256.Size]byte
 	limitauthsha           [sha256.Size]byte
+	cookieauthsha          [sha256.Size]byte
+	authLock               sync.RWMutex
 	ntfnMgr                *wsNotificationManager
 	numClients             int32
 	statusLines            map[int]string
 	statusLock             sync.RWMutex
 	wg                     sync.WaitGroup
 	listeners              []net.Listener
+	publicListeners        []net.Listener
+	cert                   *rpcCert
+	publicCert             *rpcCert
 	workState              *workState
 	gbtWorkState           *gbtWorkState
 	templatePool           map[[merkleRootPairSize]byte]*workStateBlockInfo
@@ -5944,10 +8180,8 @@ type rpcServer struct {
 	requestProcessShutdown chan struct{}
 	quit                   chan int
 
-	// coin supply caching values
-	coinSupplyMtx    sync.Mutex
-	coinSupplyHeight int64
-	coinSupplyTotal  int64
+	// utxoSetCursors are the open getutxoset cursors.
+	utxoSetCursors *utxoSetCursors
 }
 
 // httpStatusLine returns a response Status-Line (RFC 2616 Section 6.1) for the
@@ -6013,7 +8247,7 @@ func (s *rpcServer) Stop() error {
 		return nil
 	}
 	rpcsLog.Warnf("RPC server shutting down")
-	for _, listener := range s.listeners {
+	for _, listener := range append(s.listeners, s.publicListeners...) {
 		err := listener.Close()
 		if err != nil {
 			rpcsLog.Errorf("Problem shutting down rpc: %v", err)
@@ -6024,6 +8258,12 @@ func (s *rpcServer) Stop() error {
 	s.ntfnMgr.WaitForShutdown()
 	close(s.quit)
 	s.wg.Wait()
+	s.utxoSetCursors.closeAll()
+	if !cfg.NoRPCCookie {
+		if err := os.Remove(cfg.RPCCookieFile); err != nil {
+			rpcsLog.Errorf("Failed to remove RPC cookie file: %v", err)
+		}
+	}
 	rpcsLog.Infof("RPC server shutdown complete")
 	return nil
 }
@@ -6092,16 +8332,18 @@ func (s *rpcServer) checkAuth(r *http.Request, require bool) (bool, bool, error)
 	}
 
 	authsha := sha256.Sum256([]byte(authhdr[0]))
+	adminsha, limitauthsha := s.authHashes()
 
 	// Check for limited auth first as in environments with limited users,
 	// those are probably expected to have a higher volume of calls
-	limitcmp := subtle.ConstantTimeCompare(authsha[:], s.limitauthsha[:])
+	limitcmp := subtle.ConstantTimeCompare(authsha[:], limitauthsha[:])
 	if limitcmp == 1 {
 		return true, false, nil
 	}
 
-	// Check for admin-level auth
-	cmp := subtle.ConstantTimeCompare(authsha[:], s.authsha[:])
+	// Check for admin-level auth, which includes the auth cookie.
+	cmp := subtle.ConstantTimeCompare(authsha[:], adminsha[:]) |
+		subtle.ConstantTimeCompare(authsha[:], s.cookieauthsha[:])
 	if cmp == 1 {
 		return true, true, nil
 	}
@@ -6111,6 +8353,39 @@ func (s *rpcServer) checkAuth(r *http.Request, require bool) (bool, bool, error)
 	return false, false, errors.New("auth failure")
 }
 
+// basicAuthHash returns the hash of the HTTP basic authorization header clients
+// send for the passed credentials, or all zeros when either is empty so no
+// client can authenticate with them.
+func basicAuthHash(user, pass string) [sha256.Size]byte {
+	if user == "" || pass == "" {
+		return [sha256.Size]byte{}
+	}
+	login := user + ":" + pass
+	auth := "Basic " + base64.StdEncoding.EncodeToString([]byte(login))
+	return sha256.Sum256([]byte(auth))
+}
+
+// setAuth sets the credentials of the admin and the limited RPC user.  Clients
+// which are already authenticated stay connected.
+//
+// This function is safe for concurrent access.
+func (s *rpcServer) setAuth(user, pass, limitUser, limitPass string) {
+	s.authLock.Lock()
+	s.authsha = basicAuthHash(user, pass)
+	s.limitauthsha = basicAuthHash(limitUser, limitPass)
+	s.authLock.Unlock()
+}
+
+// authHashes returns the hashes of the authorization headers of the admin and
+// the limited RPC user.
+//
+// This function is safe for concurrent access.
+func (s *rpcServer) authHashes() ([sha256.Size]byte, [sha256.Size]byte) {
+	s.authLock.RLock()
+	defer s.authLock.RUnlock()
+	return s.authsha, s.limitauthsha
+}
+
 // parsedRPCCmd represents a JSON-RPC request object that has been parsed into
 // a known concrete command along with any error that might have happened while
 // parsing it.
@@ -6126,6 +8401,24 @@ type parsedRPCCmd struct {
 // Any commands which are not recognized or not implemented will return an
 // error suitable for use in replies.
 func (s *rpcServer) standardCmdResult(cmd *parsedRPCCmd, closeChan <-chan struct{}) (interface{}, error) {
+	// Log the request when it is slow.
+	defer s.server.slowQueries.recordRPC(cmd.method, cmd.cmd, time.Now())
+
+	// Trace the request.  The span is nil when tracing is disabled.
+	span := s.server.tracer.StartSpan("rpc " + cmd.method)
+	if span != nil {
+		span.SetAttribute("rpc.method", cmd.method)
+		rpcsLog.Tracef("Tracing command <%s> with trace ID %s",
+			cmd.method, span.TraceID())
+		defer span.End()
+	}
+
+	if traced, ok := rpcTracedHandlers[cmd.method]; ok {
+		result, err := traced(s, cmd.cmd, span, closeChan)
+		span.SetError(err)
+		return result, err
+	}
+
 	handler, ok := rpcHandlers[cmd.method]
 	if ok {
 		goto handled
@@ -6143,7 +8436,9 @@ func (s *rpcServer) standardCmdResult(cmd *parsedRPCCmd, closeChan <-chan struct
 	return nil, hcjson.ErrRPCMethodNotFound
 handled:
 
-	return handler(s, cmd.cmd, closeChan)
+	result, err := handler(s, cmd.cmd, closeChan)
+	span.SetError(err)
+	return result, err
 }
 
 // parseCmd parses a JSON-RPC request object into known concrete command.  The
@@ -6322,14 +8617,47 @@ func (s *rpcServer) Start() {
 	}
 
 	rpcsLog.Trace("Starting RPC server")
-	rpcServeMux := http.NewServeMux()
+	s.serve(s.listeners, s.newServeMux(false), "RPC server")
+	s.serve(s.publicListeners, s.newServeMux(true), "Public RPC server")
+
+	s.ntfnMgr.Start()
+
+	// Rotate the auto-generated certificates before they expire.
+	if s.cert != nil && cfg.RPCCertRotate > 0 {
+		s.wg.Add(1)
+		go s.certRotationHandler()
+	}
+}
+
+// serve serves the passed handler on the passed listeners.  Each listener is
+// served from its own goroutine until it is closed.
+func (s *rpcServer) serve(listeners []net.Listener, handler http.Handler, name string) {
 	httpServer := &http.Server{
-		Handler: rpcServeMux,
+		Handler: handler,
 
 		// Timeout connections which don't complete the initial
 		// handshake within the allowed timeframe.
 		ReadTimeout: time.Second * rpcAuthTimeoutSeconds,
 	}
+	for _, listener := range listeners {
+		s.wg.Add(1)
+		go func(listener net.Listener) {
+			rpcsLog.Infof("%s listening on %s", name,
+				listener.Addr())
+			httpServer.Serve(listener)
+			rpcsLog.Tracef("RPC listener done for %s",
+				listener.Addr())
+			s.wg.Done()
+		}(listener)
+	}
+}
+
+// newServeMux returns the handler of the RPC endpoints.  Clients of a public
+// handler are only authorized for the limited methods regardless of the
+// credentials they provide, which allows the admin methods to only be served
+// on private interfaces.
+func (s *rpcServer) newServeMux(public bool) *http.ServeMux {
+	rpcServeMux := http.NewServeMux()
 	rpcServeMux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Connection", "close")
 		w.Header().Set("Content-Type", "application/json")
@@ -6350,7 +8678,38 @@ func (s *rpcServer) Start() {
 		}
 
 		// Read and respond to the request.
-		s.jsonRPCRead(w, r, isAdmin)
+		s.jsonRPCRead(w, r, isAdmin && !public)
+	})
+
+	// Machine-readable schema of the commands for generating bindings.
+	rpcServeMux.HandleFunc("/schema", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Connection", "close")
+		w.Header().Set("Content-Type", "application/json")
+		r.Close = true
+
+		// Limit the number of connections to max allowed.
+		if s.limitConnections(w, r.RemoteAddr) {
+			return
+		}
+
+		// Keep track of the number of connected clients.
+		s.incrementClients()
+		defer s.decrementClients()
+		if _, _, err := s.checkAuth(r, true); err != nil {
+			jsonAuthFail(w)
+			return
+		}
+
+		schema, err := s.helpCacher.rpcSchema()
+		if err != nil {
+			rpcsLog.Errorf("Failed to generate RPC schema: %v", err)
+			http.Error(w, "500 Internal server error.",
+				http.StatusInternalServerError)
+			return
+		}
+		if _, err := w.Write(schema); err != nil {
+			rpcsLog.Errorf("Failed to write RPC schema: %v", err)
+		}
 	})
 
 	// Websocket endpoint.
@@ -6372,42 +8731,46 @@ func (s *rpcServer) Start() {
 			http.Error(w, "400 Bad Request.", http.StatusBadRequest)
 			return
 		}
-		s.WebsocketHandler(ws, r.RemoteAddr, authenticated, isAdmin)
+		s.WebsocketHandler(ws, r.RemoteAddr, authenticated,
+			isAdmin && !public, public)
 	})
 
-	for _, listener := range s.listeners {
-		s.wg.Add(1)
-		go func(listener net.Listener) {
-			rpcsLog.Infof("RPC server listening on %s",
-				listener.Addr())
-			httpServer.Serve(listener)
-			rpcsLog.Tracef("RPC listener done for %s",
-				listener.Addr())
-			s.wg.Done()
-		}(listener)
-	}
-
-	s.ntfnMgr.Start()
+	return rpcServeMux
 }
 
-// genCertPair generates a key/cert pair to the paths provided.
+// genCertPair generates a key/cert pair to the paths provided.  The
+// certificate is valid for the configured validity period and additionally
+// covers the configured extra hosts.
+//
+// Existing files are replaced, so the pair is first written to temporary files
+// which are then renamed in order to never leave partially written files
+// behind.
 func genCertPair(certFile, keyFile string) error {
 	rpcsLog.Infof("Generating TLS certificates...")
 
-	org := "hcd autogenerated cert"
-	validUntil := time.Now().Add(10 * 365 * 24 * time.Hour)
-	cert, key, err := hcutil.NewTLSCertPair(elliptic.P521(), org,
-		validUntil, nil)
+	validUntil := time.Now().Add(cfg.RPCCertValidity)
+	cert, key, err := hcutil.NewTLSCertPair(elliptic.P521(),
+		rpcCertOrganization, validUntil, cfg.RPCExtraHosts)
 	if err != nil {
 		return err
 	}
 
 	// Write cert and key files.
-	if err = ioutil.WriteFile(certFile, cert, 0666); err != nil {
+	tmpCertFile, tmpKeyFile := certFile+".new", keyFile+".new"
+	if err = ioutil.WriteFile(tmpCertFile, cert, 0666); err != nil {
 		return err
 	}
-	if err = ioutil.WriteFile(keyFile, key, 0600); err != nil {
-		os.Remove(certFile)
+	if err = ioutil.WriteFile(tmpKeyFile, key, 0600); err != nil {
+		os.Remove(tmpCertFile)
+		return err
+	}
+	if err = os.Rename(tmpKeyFile, keyFile); err != nil {
+		os.Remove(tmpCertFile)
+		os.Remove(tmpKeyFile)
+		return err
+	}
+	if err = os.Rename(tmpCertFile, certFile); err != nil {
+		os.Remove(tmpCertFile)
 		return err
 	}
 
@@ -6415,8 +8778,40 @@ func genCertPair(certFile, keyFile string) error {
 	return nil
 }
 
-// newRPCServer returns a new instance of the rpcServer struct.
-func newRPCServer(listenAddrs []string, policy *mining.Policy, s *server) (*rpcServer, error) {
+// rpcListen listens on the passed addresses with the passed listen function.
+// Addresses which can't be listened on are skipped with a warning.
+func rpcListen(listenAddrs []string, listenFunc func(string, string) (net.Listener, error)) ([]net.Listener, error) {
+	// TODO(oga) this code is similar to that in server, should be
+	// factored into something shared.
+	ipv4ListenAddrs, ipv6ListenAddrs, _, err := parseListeners(listenAddrs)
+	if err != nil {
+		return nil, err
+	}
+	listeners := make([]net.Listener, 0,
+		len(ipv6ListenAddrs)+len(ipv4ListenAddrs))
+	for _, addr := range ipv4ListenAddrs {
+		listener, err := listenFunc("tcp4", addr)
+		if err != nil {
+			rpcsLog.Warnf("Can't listen on %s: %v", addr, err)
+			continue
+		}
+		listeners = append(listeners, listener)
+	}
+
+	for _, addr := range ipv6ListenAddrs {
+		listener, err := listenFunc("tcp6", addr)
+		if err != nil {
+			rpcsLog.Warnf("Can't listen on %s: %v", addr, err)
+			continue
+		}
+		listeners = append(listeners, listener)
+	}
+	return listeners, nil
+}
+
+// newRPCServer returns a new instance of the rpcServer struct.  Only the
+// limited methods are served on the public listen addresses.
+func newRPCServer(listenAddrs, publicListenAddrs []string, policy *mining.Policy, s *server) (*rpcServer, error) {
 	rpc := rpcServer{
 		policy:                 policy,
 		server:                 s,
@@ -6426,80 +8821,59 @@ func newRPCServer(listenAddrs []string, policy *mining.Policy, s *server) (*rpcS
 		templatePool:           make(map[[merkleRootPairSize]byte]*workStateBlockInfo),
 		gbtWorkState:           newGbtWorkState(s.timeSource),
 		helpCacher:             newHelpCacher(),
+		utxoSetCursors:         newUtxoSetCursors(),
 		requestProcessShutdown: make(chan struct{}),
 		quit:                   make(chan int),
 	}
-	if cfg.RPCUser != "" && cfg.RPCPass != "" {
-		login := cfg.RPCUser + ":" + cfg.RPCPass
-		auth := "Basic " +
-			base64.StdEncoding.EncodeToString([]byte(login))
-		rpc.authsha = sha256.Sum256([]byte(auth))
-	}
-	if cfg.RPCLimitUser != "" && cfg.RPCLimitPass != "" {
-		login := cfg.RPCLimitUser + ":" + cfg.RPCLimitPass
-		auth := "Basic " +
-			base64.StdEncoding.EncodeToString([]byte(login))
-		rpc.limitauthsha = sha256.Sum256([]byte(auth))
-	}
+	rpc.setAuth(cfg.RPCUser, cfg.RPCPass, cfg.RPCLimitUser, cfg.RPCLimitPass)
 	rpc.ntfnMgr = newWsNotificationManager(&rpc)
 
-	// Setup TLS if not disabled.
+	// Setup TLS if not disabled.  The public listeners use their own
+	// certificate when one is configured.
 	listenFunc := net.Listen
+	publicListenFunc := net.Listen
 	if !cfg.DisableTLS {
-		// Generate the TLS cert and key file if both don't already
-		// exist.
-		if !fileExists(cfg.RPCKey) && !fileExists(cfg.RPCCert) {
-			err := genCertPair(cfg.RPCCert, cfg.RPCKey)
-			if err != nil {
-				return nil, err
-			}
-		}
-		keypair, err := tls.LoadX509KeyPair(cfg.RPCCert, cfg.RPCKey)
+		var err error
+		rpc.cert, err = newRPCCert(cfg.RPCCert, cfg.RPCKey)
 		if err != nil {
 			return nil, err
 		}
-
-		tlsConfig := tls.Config{
-			Certificates: []tls.Certificate{keypair},
-			MinVersion:   tls.VersionTLS12,
-		}
-
-		// Change the standard net.Listen function to the tls one.
-		listenFunc = func(net string, laddr string) (net.Listener, error) {
-			return tls.Listen(net, laddr, &tlsConfig)
+		rpc.publicCert = rpc.cert
+		if cfg.RPCPublicCert != "" || cfg.RPCPublicKey != "" {
+			rpc.publicCert, err = newRPCCert(cfg.RPCPublicCert,
+				cfg.RPCPublicKey)
+			if err != nil {
+				return nil, err
+			}
 		}
+		listenFunc = rpcTLSListenFunc(rpc.cert)
+		publicListenFunc = rpcTLSListenFunc(rpc.publicCert)
 	}
 
-	// TODO(oga) this code is similar to that in server, should be
-	// factored into something shared.
-	ipv4ListenAddrs, ipv6ListenAddrs, _, err := parseListeners(listenAddrs)
+	listeners, err := rpcListen(listenAddrs, listenFunc)
 	if err != nil {
 		return nil, err
 	}
-	listeners := make([]net.Listener, 0,
-		len(ipv6ListenAddrs)+len(ipv4ListenAddrs))
-	for _, addr := range ipv4ListenAddrs {
-		listener, err := listenFunc("tcp4", addr)
-		if err != nil {
-			rpcsLog.Warnf("Can't listen on %s: %v", addr, err)
-			continue
-		}
-		listeners = append(listeners, listener)
-	}
-
-	for _, addr := range ipv6ListenAddrs {
-		listener, err := listenFunc("tcp6", addr)
-		if err != nil {
-			rpcsLog.Warnf("Can't listen on %s: %v", addr, err)
-			continue
-		}
-		listeners = append(listeners, listener)
+	publicListeners, err := rpcListen(publicListenAddrs, publicListenFunc)
+	if err != nil {
+		return nil, err
 	}
-	if len(listeners) == 0 {
+	if len(listeners)+len(publicListeners) == 0 {
 		return nil, errors.New("RPCS: No valid listen address")
 	}
 
 	rpc.listeners = listeners
+	rpc.publicListeners = publicListeners
+
+	// Write the auth cookie once the server is known to be usable.  The
+	// cookie is not affected by reloading the credentials.
+	if !cfg.NoRPCCookie {
+		cookiePass, err := writeRPCCookie(cfg.RPCCookieFile)
+		if err != nil {
+			return nil, err
+		}
+		rpc.cookieauthsha = basicAuthHash(rpcCookieUser, cookiePass)
+	}
 
 	return &rpc, nil
 }
@@ -6,7 +6,7 @@
 // This file is ignored during the regular tests due to the following build tag.
 // +build rpctest
 
-package main
+package node
 
 import (
 	"bytes"
@@ -0,0 +1,1737 @@
+// Copyright (c) 2015 The btcsuite developers
+// Copyright (c) 2015-2017 The Decred developers
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package node
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/HcashOrg/hcd/hcjson"
+)
+
+// helpDescsEnUS defines the English descriptions used for the help strings.
+var helpDescsEnUS = map[string]string{
+	// DebugLevelCmd help.
+	"debuglevel--synopsis": "Dynamically changes the debug logging level.\n" +
+		"The levelspec can either a debug level or of the form:\n" +
+		"<subsystem>=<level>,<subsystem2>=<level2>,...\n" +
+		"The valid debug levels are trace, debug, info, warn, error, and critical.\n" +
+		"The valid subsystems are AMGR, ADXR, BCDB, BMGR, HCD, CHAN, DISC, PEER, RPCS, SCRP, SRVR, and TXMP.\n" +
+		"Finally the keyword 'show' will return a list of the available subsystems.",
+	"debuglevel-levelspec":   "The debug level(s) to use or the keyword 'show'",
+	"debuglevel--condition0": "levelspec!=show",
+	"debuglevel--condition1": "levelspec=show",
+	"debuglevel--result0":    "The string 'Done.'",
+	"debuglevel--result1":    "The list of subsystems",
+
+	// DumpBlockChainCmd help.
+	"dumpblockchain--synopsis": "Writes the main chain blocks in the given range to a new file in the flat file format read by --importbootstrap and the addblock utility.\n" +
+		"Existing files are never overwritten and the file only appears once the export is complete.",
+	"dumpblockchain-filename":    "The path of the file to write, relative to the data directory unless absolute",
+	"dumpblockchain-startheight": "The height of the first block to write",
+	"dumpblockchain-endheight":   "The height of the last block to write (default: the best block)",
+
+	// DumpBlockChainResult help.
+	"dumpblockchainresult-filename":    "The absolute path of the written file",
+	"dumpblockchainresult-startheight": "The height of the first written block",
+	"dumpblockchainresult-endheight":   "The height of the last written block",
+	"dumpblockchainresult-blocks":      "The number of written blocks",
+	"dumpblockchainresult-size":        "The size of the written file in bytes",
+
+	// AddNodeCmd help.
+	"addnode--synopsis": "Attempts to add or remove a persistent peer.",
+	"addnode-addr":      "IP address and port of the peer to operate on",
+	"addnode-subcmd":    "'add' to add a persistent peer, 'remove' to remove a persistent peer, or 'onetry' to try a single connection to a peer",
+
+	// NodeCmd help.
+	"node--synopsis":     "Attempts to add or remove a peer.",
+	"node-subcmd":        "'disconnect' to remove all matching non-persistent peers, 'remove' to remove a persistent peer, or 'connect' to connect to a peer",
+	"node-target":        "Either the IP address and port of the peer to operate on, or a valid peer ID.",
+	"node-connectsubcmd": "'perm' to make the connected peer a permanent one, 'temp' to try a single connect to a peer",
+
+	// CombinePshtCmd help.
+	"combinepsht--synopsis": "Combines partially signed transactions for the same transaction, such as the ones returned by each of its signers, into a single partially signed transaction.",
+	"combinepsht-pshts":     "The base64-encoded partially signed transactions to combine",
+	"combinepsht--result0":  "The base64-encoded combined partially signed transaction",
+
+	// TransactionInput help.
+	"transactioninput-txid": "The hash of the input transaction",
+	"transactioninput-vout": "The specific output of the input transaction to redeem",
+	"transactioninput-tree": "The tree that the transaction input is located",
+	// TODO review cmd help messages for stake stuff
+	// CreateRawSSTxCmd help.
+	"createrawsstx--synopsis": "Returns a new transaction spending the provided inputs and sending to the provided addresses.\n" +
+		"The transaction inputs are not signed in the created transaction.\n" +
+		"The signrawtransaction RPC command provided by wallet must be used to sign the resulting transaction.",
+	"createrawsstx--result0":      "Hex-encoded bytes of the serialized transaction",
+	"createrawsstx-inputs":        "The inputs to the transaction of type sstxinput",
+	"sstxinput-txid":              "Unspent tx output hash",
+	"sstxinput-vout":              "Amount of utxo",
+	"sstxinput-amt":               "Amount of utxu",
+	"sstxinput-tree":              "Which tree utxo is located",
+	"createrawsstx-amount":        "JSON object with the destination addresses as keys and amounts as values",
+	"createrawsstx-amount--key":   "address",
+	"createrawsstx-amount--value": "n.nnn",
+	"createrawsstx-amount--desc":  "The destination address as the key and the amount in HC as the value",
+	"createrawsstx-couts":         "Array of sstx commit outs to use of type SSTxCommitOut",
+	"sstxcommitout-addr":          "Address to send sstx commit",
+	"sstxcommitout-commitamt":     "Amount to commit",
+	"sstxcommitout-changeamt":     "Amount for change",
+	"sstxcommitout-changeaddr":    "Address for change",
+
+	// CreateRawSSGenTxCmd help.
+	"createrawssgentx--synopsis": "Returns a new transaction spending the provided inputs and sending to the provided addresses.\n" +
+		"The transaction inputs are not signed in the created transaction.\n" +
+		"The signrawtransaction RPC command provided by wallet must be used to sign the resulting transaction.",
+	"createrawssgentx--result0": "Hex-encoded bytes of the serialized transaction",
+	"createrawssgentx-inputs":   "The inputs to the transaction of type sstxinput",
+	"createrawssgentx-votebits": "The inputs to the transaction of type sstxinput",
+
+	// CreateRawSSGenTxCmd help.
+	"createrawssrtx--synopsis": "Returns a new transaction spending the provided inputs and sending to the provided addresses.\n" +
+		"The transaction inputs are not signed in the created transaction.\n" +
+		"The signrawtransaction RPC command provided by wallet must be used to sign the resulting transaction.",
+	"createrawssrtx--result0": "Hex-encoded bytes of the serialized transaction",
+	"createrawssrtx-inputs":   "The inputs to the transaction of type sstxinput",
+	"createrawssrtx-fee":      "The fee to apply to the revocation in Coins",
+
+	// CreateMultisigCmd help.
+	"createmultisig--synopsis": "Creates a multisignature script requiring the specified number of signatures of the provided secp256k1 public keys and returns its pay-to-script-hash address.",
+	"createmultisig-nrequired": "The number of signatures required to redeem outputs paying to the script",
+	"createmultisig-keys":      "Hex-encoded public keys or public key addresses",
+
+	// CreateMultiSigResult help.
+	"createmultisigresult-address":      "The pay-to-script-hash address of the script",
+	"createmultisigresult-redeemScript": "The hex-encoded script",
+
+	// CreateRawScriptCmd help.
+	"createrawscript--synopsis": "Creates a multisig, pay-to-pubkey or pay-to-pubkey-hash script from the provided public keys without using a wallet.\n" +
+		"The script may be used as the output script of a transaction or wrapped in a pay-to-script-hash output.",
+	"createrawscript-type":      "The type of the script to create",
+	"createrawscript-keys":      "Hex-encoded public keys or public key addresses (exactly one for the pubkey and pubkeyhash types)",
+	"createrawscript-nrequired": "The number of signatures required by a multisig script",
+	"createrawscript-sigtype":   "The signature scheme of the keys (secp256k1, edwards, secschnorr or bliss); multisig scripts only support secp256k1",
+
+	// CreateRawScriptResult help.
+	"createrawscriptresult-type":       "The class of the created script (e.g. 'pubkeyhashalt' for non-secp256k1 pubkeyhash scripts)",
+	"createrawscriptresult-script":     "The hex-encoded script",
+	"createrawscriptresult-address":    "The address the script pays to (pubkey and pubkeyhash types only)",
+	"createrawscriptresult-p2sh":       "The pay-to-script-hash address which uses the script as its redeem script",
+	"createrawscriptresult-p2shscript": "The hex-encoded output script paying to the pay-to-script-hash address",
+
+	// CreateRawTransactionCmd help.
+	"createrawtransaction--synopsis": "Returns a new transaction spending the provided inputs and sending to the provided addresses.\n" +
+		"The transaction inputs are not signed in the created transaction.\n" +
+		"The signrawtransaction RPC command provided by wallet must be used to sign the resulting transaction.",
+	"createrawtransaction-inputs":         "The inputs to the transaction",
+	"createrawtransaction-amounts":        "JSON object with the destination addresses as keys and amounts as values",
+	"createrawtransaction-amounts--key":   "address",
+	"createrawtransaction-amounts--value": "n.nnn",
+	"createrawtransaction-amounts--desc":  "The destination address as the key and the amount in HC as the value",
+	"createrawtransaction-locktime":       "Locktime value; a non-zero value will also locktime-activate the inputs",
+	"createrawtransaction-payload":        "Hex-encoded data to embed in a provably pruneable output (regular transactions only)",
+	"createrawtransaction-txtype":         "The type of the transaction: regular, ticket (pays the single amount to the voting address) or revocation (revokes the ticket spent by the single input without amounts)",
+	"createrawtransaction-commitments":    "The commitment and change outputs for each input of a ticket purchase of type sstxcommitout; the committed amounts are derived from the input values",
+	"createrawtransaction--result0":       "Hex-encoded bytes of the serialized transaction",
+
+	// ScriptSig help.
+	"scriptsig-asm": "Disassembly of the script",
+	"scriptsig-hex": "Hex-encoded bytes of the script",
+
+	// PrevOut help.
+	"prevout-addresses": "previous output addresses",
+	"prevout-value":     "previous output value",
+
+	// VinPrevOut help.
+	"vinprevout-coinbase":    "The hex-encoded bytes of the signature script (coinbase txns only)",
+	"vinprevout-txid":        "The hash of the origin transaction (non-coinbase txns only)",
+	"vinprevout-vout":        "The index of the output being redeemed from the origin transaction (non-coinbase txns only)",
+	"vinprevout-tree":        "The transaction tree of the origin transaction (non-coinbase txns only)",
+	"vinprevout-amountin":    "The amount in for this transaction input, in coins",
+	"vinprevout-blockheight": "The height of the block that includes the origin transaction (non-coinbase txns only)",
+	"vinprevout-blockindex":  "The merkle tree index of the origin transaction (non-coinbase txns only)",
+	"vinprevout-scriptSig":   "The signature script used to redeem the origin transaction as a JSON object (non-coinbase txns only)",
+	"vinprevout-prevOut":     "Data from the origin transaction output with index vout.",
+	"vinprevout-sequence":    "The script sequence number",
+
+	// Vin help.
+	"vin-coinbase":    "The hex-encoded bytes of the signature script (coinbase txns only)",
+	"vin-stakebase":   "The hex-encoded bytes of the signature script (vote txns only)",
+	"vin-txid":        "The hash of the origin transaction (non-coinbase txns only)",
+	"vin-vout":        "The index of the output being redeemed from the origin transaction (non-coinbase txns only)",
+	"vin-scriptSig":   "The signature script used to redeem the origin transaction as a JSON object (non-coinbase txns only)",
+	"vin-sequence":    "The script sequence number",
+	"vin-tree":        "The tree of the transaction",
+	"vin-blockindex":  "The block idx of the origin transaction",
+	"vin-blockheight": "The block height of the origin transaction",
+	"vin-amountin":    "The amount in",
+
+	// ScriptPubKeyResult help.
+	"scriptpubkeyresult-asm":       "Disassembly of the script",
+	"scriptpubkeyresult-hex":       "Hex-encoded bytes of the script",
+	"scriptpubkeyresult-reqSigs":   "The number of required signatures",
+	"scriptpubkeyresult-type":      "The type of the script (e.g. 'pubkeyhash')",
+	"scriptpubkeyresult-addresses": "The HC addresses associated with this script",
+	"scriptpubkeyresult-commitamt": "The ticket commitment value if the script is for a staking commitment",
+
+	// Vout help.
+	"vout-value":        "The amount in HC",
+	"vout-n":            "The index of this transaction output",
+	"vout-scriptPubKey": "The public key script used to pay coins as a JSON object",
+	"vout-version":      "The version of the vout",
+
+	// TxRawDecodeResult help.
+	"txrawdecoderesult-txid":     "The hash of the transaction",
+	"txrawdecoderesult-type":     "The type of the transaction (regular, ticket, vote or revocation)",
+	"txrawdecoderesult-version":  "The transaction version",
+	"txrawdecoderesult-locktime": "The transaction lock time",
+	"txrawdecoderesult-vin":      "The transaction inputs as JSON objects",
+	"txrawdecoderesult-vout":     "The transaction outputs as JSON objects",
+	"txrawdecoderesult-expiry":   "The transaction expiry",
+
+	// DecodePshtCmd help.
+	"decodepsht--synopsis": "Returns a JSON object representing the provided base64-encoded partially signed transaction.",
+	"decodepsht-psht":      "The base64-encoded partially signed transaction",
+
+	// DecodePshtResult help.
+	"decodepshtresult-tx":       "The unsigned transaction as a JSON object",
+	"decodepshtresult-type":     "The type of the transaction (regular, ticket, vote or revocation)",
+	"decodepshtresult-unknowns": "The global key-value pairs not known by the server",
+	"decodepshtresult-inputs":   "The metadata of each transaction input",
+	"decodepshtresult-outputs":  "The metadata of each transaction output",
+	"decodepshtresult-fee":      "The transaction fee in HC when the values of all inputs are known",
+
+	// DecodePshtInput help.
+	"decodepshtinput-prevout":        "The previous output spent by the input",
+	"decodepshtinput-partialsigs":    "The signatures for the input collected so far",
+	"decodepshtinput-sighashtype":    "The signature hash type signers must use for the input",
+	"decodepshtinput-redeemscript":   "The hex-encoded redeem script of a pay-to-script-hash input",
+	"decodepshtinput-finalscriptsig": "The hex-encoded final signature script of a finalized input",
+	"decodepshtinput-bip32derivs":    "The key derivations of the public keys which sign the input",
+	"decodepshtinput-unknowns":       "The input key-value pairs not known by the server",
+
+	// DecodePshtOutput help.
+	"decodepshtoutput-redeemscript": "The hex-encoded redeem script of a pay-to-script-hash output",
+	"decodepshtoutput-bip32derivs":  "The key derivations of the public keys the output pays to",
+	"decodepshtoutput-unknowns":     "The output key-value pairs not known by the server",
+
+	// PshtPrevOut help.
+	"pshtprevout-amount":       "The value of the previous output in HC",
+	"pshtprevout-version":      "The script version of the previous output",
+	"pshtprevout-scriptPubKey": "The public key script of the previous output as a JSON object",
+
+	// PshtPartialSig help.
+	"pshtpartialsig-pubkey":    "The hex-encoded public key which created the signature",
+	"pshtpartialsig-signature": "The hex-encoded signature with the signature hash type appended",
+
+	// PshtBip32Derivation help.
+	"pshtbip32derivation-pubkey":            "The hex-encoded public key",
+	"pshtbip32derivation-masterfingerprint": "The hex-encoded fingerprint of the master extended key",
+	"pshtbip32derivation-path":              "The derivation path of the public key from the master extended key",
+
+	// PshtUnknown help.
+	"pshtunknown-key":   "The hex-encoded key",
+	"pshtunknown-value": "The hex-encoded value",
+
+	// DecodeRawTransactionCmd help.
+	"decoderawtransaction--synopsis": "Returns a JSON object representing the provided serialized, hex-encoded transaction.",
+	"decoderawtransaction-hextx":     "Serialized, hex-encoded transaction",
+
+	// DecodeScriptResult help.
+	"decodescriptresult-asm":       "Disassembly of the script",
+	"decodescriptresult-reqSigs":   "The number of required signatures",
+	"decodescriptresult-type":      "The type of the script (e.g. 'pubkeyhash')",
+	"decodescriptresult-addresses": "The HC addresses associated with this script",
+	"decodescriptresult-p2sh":      "The script hash for use in pay-to-script-hash transactions",
+
+	// DecodeScriptCmd help.
+	"decodescript--synopsis": "Returns a JSON object with information about the provided hex-encoded script.",
+	"decodescript-hexscript": "Hex-encoded script",
+
+	// DisconnectNodeCmd help.
+	"disconnectnode--synopsis": "Disconnects a connected peer selected by its address or, when the address is empty, by its node id.\n" +
+		"Persistent peers are not disconnected since they would reconnect, use addnode remove for them instead.",
+	"disconnectnode-address": "The IP address and port of the peer, the default port is used when it is omitted",
+	"disconnectnode-nodeid":  "The id of the peer as reported by getpeerinfo, only used when the address is empty",
+
+	// DescribeRPCCmd help.
+	"describerpc--synopsis": "Returns a machine-readable description of the parameters of all methods supported by the RPC server, ordered by method name.",
+
+	// DescribeRPCResult help.
+	"describerpcresult-method":  "The name of the method",
+	"describerpcresult-usage":   "The one-line usage of the method",
+	"describerpcresult-limited": "Whether or not the method is available to limited users",
+	"describerpcresult-params":  "The parameters of the method in the order they are passed",
+
+	// DescribeRPCParam help.
+	"describerpcparam-name":     "The name of the parameter",
+	"describerpcparam-type":     "The JSON type of the parameter (string, numeric, boolean, array, object or value)",
+	"describerpcparam-optional": "Whether or not the parameter may be omitted",
+	"describerpcparam-default":  "The value used when the parameter is omitted (only if there is one)",
+
+	// ExistsAddressCmd help.
+	"existsaddress--synopsis": "Test for the existance of the provided address",
+	"existsaddress-address":   "The address to check",
+	"existsaddress--result0":  "Bool showing if address exists or not",
+
+	// ExistsAddressesCmd help.
+	"existsaddresses--synopsis": "Test for the existance of the provided addresses in the blockchain or memory pool",
+	"existsaddresses-addresses": "The addresses to check",
+	"existsaddresses--result0":  "Bitset of bools showing if addresses exist or not",
+
+	// ExitsMissedTicketsCmd help.
+	"existsmissedtickets--synopsis":  "Test for the existance of the provided tickets in the missed ticket map",
+	"existsmissedtickets-txhashblob": "Blob containing the hashes to check",
+	"existsmissedtickets--result0":   "Bool blob showing if the ticket exists in the missed ticket database or not",
+
+	// ExistsExpiredTicketsCmd help.
+	"existsexpiredtickets--synopsis":  "Test for the existance of the provided tickets in the expired ticket map",
+	"existsexpiredtickets-txhashblob": "Blob containing the hashes to check",
+	"existsexpiredtickets--result0":   "Bool blob showing if ticket exists in the expired ticket database or not",
+
+	// ExistsLiveTicketCmd help.
+	"existsliveticket--synopsis": "Test for the existance of the provided ticket",
+	"existsliveticket-txhash":    "The ticket hash to check",
+	"existsliveticket--result0":  "Bool showing if address exists in the live ticket database or not",
+
+	// ExistsLiveTicketsCmd help.
+	"existslivetickets--synopsis":  "Test for the existance of the provided tickets in the live ticket map",
+	"existslivetickets-txhashblob": "Blob containing the hashes to check",
+	"existslivetickets--result0":   "Bool blob showing if ticket exists in the live ticket database or not",
+
+	// ExistsMempoolTxsCmd help.
+	"existsmempooltxs--synopsis":  "Test for the existance of the provided txs in the mempool",
+	"existsmempooltxs-txhashblob": "Blob containing the hashes to check",
+	"existsmempooltxs--result0":   "Bool blob showing if txs exist in the mempool or not",
+
+	// ExistsTxsCmd help.
+	"existstxs--synopsis": "Test for the existence of the provided transactions in the mempool and of the provided tickets in the live ticket map in a single call",
+	"existstxs-txhashes":  "The hashes to check",
+
+	// ExistsTxsResult help.
+	"existstxsresult-mempool":     "Bool blob showing if each hash is a transaction in the mempool or not",
+	"existstxsresult-livetickets": "Bool blob showing if each hash is a live ticket or not",
+
+	// FinalizePshtCmd help.
+	"finalizepsht--synopsis": "Creates the final signature scripts of the inputs of a partially signed transaction which have all the required signatures, and returns the signed transaction once all inputs are finalized.",
+	"finalizepsht-psht":      "The base64-encoded partially signed transaction",
+	"finalizepsht-extract":   "Return the serialized, hex-encoded signed transaction instead of the partially signed transaction when all inputs are finalized",
+
+	// FinalizePshtResult help.
+	"finalizepshtresult-psht":     "The base64-encoded partially signed transaction when it is not complete or not extracted",
+	"finalizepshtresult-hex":      "The serialized, hex-encoded signed transaction when it is complete and extracted",
+	"finalizepshtresult-complete": "Whether or not all inputs are finalized",
+
+	// GenerateCmd help
+	"generate--synopsis": "Generates a set number of blocks (simnet or regtest only) and returns a JSON\n" +
+		" array of their hashes.",
+	"generate-numblocks":  "Number of blocks to generate",
+	"generate-votes":      "Maximum number of votes to include in each block, between the majority and all of the tickets per block (simnet only)",
+	"generate-timeoffset": "Number of seconds to shift the timestamp of each block away from the current time (simnet only)",
+	"generate--result0":   "The hashes, in order, of blocks generated by the call",
+
+	// GetAddedNodeInfoResultAddr help.
+	"getaddednodeinforesultaddr-address":   "The ip address for this DNS entry",
+	"getaddednodeinforesultaddr-connected": "The connection 'direction' (inbound/outbound/false)",
+
+	// GetAddedNodeInfoResult help.
+	"getaddednodeinforesult-addednode": "The ip address or domain of the added peer",
+	"getaddednodeinforesult-connected": "Whether or not the peer is currently connected",
+	"getaddednodeinforesult-addresses": "DNS lookup and connection information about the peer",
+
+	// GetAddedNodeInfo help.
+	"getaddednodeinfo--synopsis":   "Returns information about manually added (persistent) peers.",
+	"getaddednodeinfo-dns":         "Specifies whether the returned data is a JSON object including DNS and connection information, or just a list of added peers",
+	"getaddednodeinfo-node":        "Only return information about this specific peer instead of all added peers",
+	"getaddednodeinfo--condition0": "dns=false",
+	"getaddednodeinfo--condition1": "dns=true",
+	"getaddednodeinfo--result0":    "List of added peers",
+
+	// GetBestBlockResult help.
+	"getbestblockresult-hash":   "Hex-encoded bytes of the best block hash",
+	"getbestblockresult-height": "Height of the best block",
+
+	// GetBestBlockCmd help.
+	"getbestblock--synopsis": "Get block height and hash of best block in the main chain.",
+	"getbestblock--result0":  "Get block height and hash of best block in the main chain.",
+
+	// GetBestBlockHashCmd help.
+	"getbestblockhash--synopsis": "Returns the hash of the of the best (most recent) block in the longest block chain.",
+	"getbestblockhash--result0":  "The hex-encoded block hash",
+
+	// GetBlockCmd help.
+	"getblock--synopsis":   "Returns information about a block given its hash.",
+	"getblock-hash":        "The hash of the block",
+	"getblock-verbose":     "Specifies the block is returned as a JSON object instead of hex-encoded string",
+	"getblock-verbosetx":   "Specifies that each transaction is returned as a JSON object and only applies if the verbose flag is true (hcd extension)",
+	"getblock--condition0": "verbose=false",
+	"getblock--condition1": "verbose=true",
+	"getblock--result0":    "Hex-encoded bytes of the serialized block",
+
+	// TxRawResult help.
+	"txrawresult-hex":           "Hex-encoded transaction",
+	"txrawresult-txid":          "The hash of the transaction",
+	"txrawresult-version":       "The transaction version",
+	"txrawresult-locktime":      "The transaction lock time",
+	"txrawresult-vin":           "The transaction inputs as JSON objects",
+	"txrawresult-vout":          "The transaction outputs as JSON objects",
+	"txrawresult-blockhash":     "Hash of the block the transaction is part of",
+	"txrawresult-confirmations": "Number of confirmations of the block",
+	"txrawresult-time":          "Transaction time in seconds since 1 Jan 1970 GMT",
+	"txrawresult-blocktime":     "Block time in seconds since the 1 Jan 1970 GMT",
+	"txrawresult-blockindex":    "Index of the containing block.",
+	"txrawresult-blockheight":   "Height of the block the transaction is part of",
+	"txrawresult-expiry":        "The transacion expiry",
+
+	// SearchRawTransactionsResult help.
+	"searchrawtransactionsresult-hex":           "Hex-encoded transaction",
+	"searchrawtransactionsresult-txid":          "The hash of the transaction",
+	"searchrawtransactionsresult-version":       "The transaction version",
+	"searchrawtransactionsresult-locktime":      "The transaction lock time",
+	"searchrawtransactionsresult-vin":           "The transaction inputs as JSON objects",
+	"searchrawtransactionsresult-vout":          "The transaction outputs as JSON objects",
+	"searchrawtransactionsresult-blockhash":     "Hash of the block the transaction is part of",
+	"searchrawtransactionsresult-confirmations": "Number of confirmations of the block",
+	"searchrawtransactionsresult-time":          "Transaction time in seconds since 1 Jan 1970 GMT",
+	"searchrawtransactionsresult-blocktime":     "Block time in seconds since the 1 Jan 1970 GMT",
+
+	// GetBlockVerboseResult help.
+	"getblockverboseresult-hash":              "The hash of the block (same as provided)",
+	"getblockverboseresult-confirmations":     "The number of confirmations",
+	"getblockverboseresult-size":              "The size of the block",
+	"getblockverboseresult-height":            "The height of the block in the block chain",
+	"getblockverboseresult-version":           "The block version",
+	"getblockverboseresult-merkleroot":        "Root hash of the merkle tree",
+	"getblockverboseresult-tx":                "The transaction hashes (only when verbosetx=false)",
+	"getblockverboseresult-rawtx":             "The transactions as JSON objects (only when verbosetx=true)",
+	"getblockverboseresult-time":              "The block time in seconds since 1 Jan 1970 GMT",
+	"getblockverboseresult-nonce":             "The block nonce",
+	"getblockverboseresult-bits":              "The bits which represent the block difficulty",
+	"getblockverboseresult-difficulty":        "The proof-of-work difficulty as a multiple of the minimum difficulty",
+	"getblockverboseresult-previousblockhash": "The hash of the previous block",
+	"getblockverboseresult-nextblockhash":     "The hash of the next block (only if there is one)",
+	"getblockverboseresult-sbits":             "The stake difficulty of theblock",
+	"getblockverboseresult-poolsize":          "The total number of valid, spendable sstx (tickets) in the chain",
+	"getblockverboseresult-revocations":       "The number of new ssrtx (tickets) of the given block",
+	"getblockverboseresult-freshstake":        "The number of new sstx (tickets) of the given block",
+	"getblockverboseresult-voters":            "The number of stake voters (ssgen) of the previous block",
+	"getblockverboseresult-potential":         "The number of potential",
+	"getblockverboseresult-overflow":          "The number of overflow",
+	"getblockverboseresult-winner":            "The winning bucket to determine ssgen",
+	"getblockverboseresult-votebits":          "The block's voting results",
+	"getblockverboseresult-rawstx":            "The block's raw sstx hashes the were included",
+	"getblockverboseresult-stx":               "The block's sstx hashes the were included",
+	"getblockverboseresult-stakeroot":         "The block's sstx hashes the were included",
+	"getblockverboseresult-finalstate":        "The block's finalstate",
+	"getblockverboseresult-extradata":         "Extra data field for the requested block",
+	"getblockverboseresult-stakeversion":      "Stake Version of the block",
+	"getblockverboseresult-votes":             "The version and the vote bits of each vote in the block (omitted when the block has no votes)",
+
+	// GetBlockChainInfoCmd help.
+	"getblockchaininfo--synopsis": "Returns information about the current state of the block chain and the progress of the initial block download.",
+
+	// GetBlockChainInfoResult help.
+	"getblockchaininforesult-chain":                "The name of the network the chain belongs to",
+	"getblockchaininforesult-blocks":               "The height of the best block in the main chain",
+	"getblockchaininforesult-headers":              "The height of the best known block header",
+	"getblockchaininforesult-estimatedheaders":     "The estimated height of the best chain of the network based on the timestamp of the best known header and the height announced by the sync peer",
+	"getblockchaininforesult-bestblockhash":        "The hash of the best block in the main chain",
+	"getblockchaininforesult-difficulty":           "The current target difficulty bits",
+	"getblockchaininforesult-verificationprogress": "The estimated fraction of the best chain of the network which has been verified",
+	"getblockchaininforesult-chainwork":            "The total cumulative work in the best chain",
+	"getblockchaininforesult-syncheight":           "The height of the most recently processed block",
+	"getblockchaininforesult-initialblockdownload": "Whether or not the node is in initial block download",
+	"getblockchaininforesult-syncstate":            "The stage of the initial block download (idle, headers, blocks or current)",
+	"getblockchaininforesult-syncpeer":             "The address of the peer the chain is being downloaded from, if any",
+	"getblockchaininforesult-downloadspeed":        "The number of blocks received per second over the last minute",
+	"getblockchaininforesult-difficultyratio":      "The proof-of-work difficulty as a multiple of the minimum difficulty",
+	"getblockchaininforesult-maxblocksize":         "The maximum allowed block size",
+
+	// GetBlockCountCmd help.
+	"getblockcount--synopsis": "Returns the number of blocks in the longest block chain.",
+	"getblockcount--result0":  "The current block count",
+
+	// GetBlockHashCmd help.
+	"getblockhash--synopsis": "Returns hash of the block in best block chain at the given height.",
+	"getblockhash-index":     "The block height",
+	"getblockhash--result0":  "The block hash",
+
+	// GetBlockHeaderCmd help.
+	"getblockheader--synopsis":   "Returns information about a block header given its hash.",
+	"getblockheader-hash":        "The hash of the block",
+	"getblockheader-verbose":     "Specifies the block header is returned as a JSON object instead of hex-encoded string",
+	"getblockheader--condition0": "verbose=false",
+	"getblockheader--condition1": "verbose=true",
+	"getblockheader--result0":    "The serialized block header.",
+
+	// GetBlockHeaderVerboseResult help.
+	"getblockheaderverboseresult-hash":              "The hash of the block (same as provided)",
+	"getblockheaderverboseresult-confirmations":     "The number of confirmations",
+	"getblockheaderverboseresult-height":            "The height of the block in the block chain",
+	"getblockheaderverboseresult-version":           "The block version",
+	"getblockheaderverboseresult-merkleroot":        "The merkle root of the regular transaction tree",
+	"getblockheaderverboseresult-time":              "The block time in seconds since 1 Jan 1970 GMT",
+	"getblockheaderverboseresult-nonce":             "The block nonce",
+	"getblockheaderverboseresult-bits":              "The bits which represent the block difficulty",
+	"getblockheaderverboseresult-difficulty":        "The proof-of-work difficulty as a multiple of the minimum difficulty",
+	"getblockheaderverboseresult-previousblockhash": "The hash of the previous block",
+	"getblockheaderverboseresult-nextblockhash":     "The hash of the next block (only if there is one)",
+	"getblockheaderverboseresult-size":              "The size of the block in bytes",
+	"getblockheaderverboseresult-sbits":             "The stake difficulty in coins",
+	"getblockheaderverboseresult-poolsize":          "The size of the live ticket pool",
+	"getblockheaderverboseresult-revocations":       "The number of revocations in the block",
+	"getblockheaderverboseresult-freshstake":        "The number of new tickets in the block",
+	"getblockheaderverboseresult-voters":            "The number of votes in the block",
+	"getblockheaderverboseresult-finalstate":        "The final state value of the ticket pool",
+	"getblockheaderverboseresult-votebits":          "The vote bits",
+	"getblockheaderverboseresult-stakeroot":         "The merkle root of the stake transaction tree",
+	"getblockheaderverboseresult-stakeversion":      "The stake version of the block",
+
+	// GetBlockHeadersCmd help.
+	"getblockheaders--synopsis": "Returns serialized block headers of the main chain starting after the first known block of the locator.\n" +
+		"Light clients which cannot use the peer-to-peer protocol can sync the headers by repeating the request with the hash of the last returned header as the locator.",
+	"getblockheaders-blocklocators": "Hashes of blocks ordered from the most recent one, such as the hashes of the latest known blocks of the client.  Headers are returned starting after the first hash known to the server, or after the genesis block when none is known",
+	"getblockheaders-hashstop":      "Optional block hash to stop including block headers for",
+	"getblockheaders-count":         "The maximum number of headers to return (max 2000)",
+
+	// GetBlockHeadersResult help.
+	"getblockheadersresult-headers":     "The hex-encoded serialized block headers in chain order",
+	"getblockheadersresult-startheight": "The height of the first returned header (omitted when no headers are returned)",
+	"getblockheadersresult-bestheight":  "The height of the current best block, which tells whether more headers are available",
+
+	// GetBlockReceivedTimeCmd help.
+	"getblockreceivedtime--synopsis": "Returns when and from where a block was first seen by the server.\n" +
+		"Only the most recently first seen blocks are remembered and the times are lost when the server restarts.",
+	"getblockreceivedtime-hash": "The hash of the block",
+
+	// GetBlockReceivedTimeResult help.
+	"getblockreceivedtimeresult-time":       "The time the block was first seen in seconds since 1 Jan 1970 GMT",
+	"getblockreceivedtimeresult-timemillis": "The time the block was first seen in milliseconds since 1 Jan 1970 GMT",
+	"getblockreceivedtimeresult-source":     "The address of the peer the block was first received from, or rpc when it was submitted via RPC",
+
+	// GetBlockSubsidyCmd help.
+	"getblocksubsidy--synopsis": "Returns information regarding subsidy amounts.",
+	"getblocksubsidy-height":    "The block height",
+	"getblocksubsidy-voters":    "The number of voters",
+
+	// GetBlockSubsidyResult help.
+	"getblocksubsidyresult-developer": "The developer subsidy",
+	"getblocksubsidyresult-pos":       "The Proof-of-Stake subsidy",
+	"getblocksubsidyresult-pow":       "The Proof-of-Work subsidy",
+	"getblocksubsidyresult-total":     "The total subsidy",
+
+	// GetBlockUndoCmd help.
+	"getblockundo--synopsis": "Returns the outputs spent by the transactions connected to the main chain along with a block, which is the undo data of the block.\n" +
+		"These are the outputs spent by the regular transactions of the parent block, when the block approves them, followed by those spent by the stake transactions of the block.",
+	"getblockundo-hash": "The hash of the main chain block",
+
+	// GetBlockUndoResult help.
+	"getblockundoresult-hash":   "The hash of the block",
+	"getblockundoresult-height": "The height of the block",
+	"getblockundoresult-spent":  "The spent outputs in the order of the spending transactions and their inputs",
+
+	// SpentOutputResult help.
+	"spentoutputresult-txid":         "The hash of the spending transaction",
+	"spentoutputresult-vin":          "The index of the input of the spending transaction",
+	"spentoutputresult-prevtxid":     "The hash of the transaction which created the spent output",
+	"spentoutputresult-prevvout":     "The index of the spent output",
+	"spentoutputresult-prevtree":     "The transaction tree of the transaction which created the spent output",
+	"spentoutputresult-amount":       "The amount of the spent output in coins",
+	"spentoutputresult-height":       "The height of the block containing the transaction which created the spent output",
+	"spentoutputresult-blockindex":   "The index of the transaction which created the spent output within its block",
+	"spentoutputresult-scriptPubKey": "The public key script of the spent output",
+
+	// TemplateRequest help.
+	"templaterequest-mode":         "This is 'template', 'proposal', or omitted",
+	"templaterequest-capabilities": "List of capabilities",
+	"templaterequest-longpollid":   "The long poll ID of a job to monitor for expiration; required and valid only for long poll requests ",
+	"templaterequest-sigoplimit":   "Number of signature operations allowed in blocks (this parameter is ignored)",
+	"templaterequest-sizelimit":    "Number of bytes allowed in blocks (this parameter is ignored)",
+	"templaterequest-maxversion":   "Highest supported block version number (this parameter is ignored)",
+	"templaterequest-target":       "The desired target for the block template (this parameter is ignored)",
+	"templaterequest-data":         "Hex-encoded block data (only for mode=proposal)",
+	"templaterequest-workid":       "The server provided workid if provided in block template (not applicable)",
+
+	// GetBlockTemplateResultTx help.
+	"getblocktemplateresulttx-data":    "Hex-encoded transaction data (byte-for-byte)",
+	"getblocktemplateresulttx-hash":    "Hex-encoded transaction hash (little endian if treated as a 256-bit number)",
+	"getblocktemplateresulttx-depends": "Other transactions before this one (by 1-based index in the 'transactions'  list) that must be present in the final block if this one is",
+	"getblocktemplateresulttx-fee":     "Difference in value between transaction inputs and outputs (in Atoms)",
+	"getblocktemplateresulttx-sigops":  "Total number of signature operations as counted for purposes of block limits",
+	"getblocktemplateresulttx-txtype":  "Type of the transaction",
+
+	// GetBlockTemplateResultAux help.
+	"getblocktemplateresultaux-flags": "Hex-encoded byte-for-byte data to include in the coinbase signature script",
+
+	// GetBlockTemplateResult help.
+	"getblocktemplateresult-bits":              "Hex-encoded compressed difficulty",
+	"getblocktemplateresult-curtime":           "Current time as seen by the server (recommended for block time); must fall within mintime/maxtime rules",
+	"getblocktemplateresult-height":            "Height of the block to be solved",
+	"getblocktemplateresult-previousblockhash": "Hex-encoded big-endian hash of the previous block",
+	"getblocktemplateresult-sigoplimit":        "Number of sigops allowed in blocks ",
+	"getblocktemplateresult-sizelimit":         "Number of bytes allowed in blocks",
+	"getblocktemplateresult-transactions":      "Array of transactions as JSON objects",
+	"getblocktemplateresult-version":           "The block version",
+	"getblocktemplateresult-coinbaseaux":       "Data that should be included in the coinbase signature script",
+	"getblocktemplateresult-coinbasetxn":       "Information about the coinbase transaction",
+	"getblocktemplateresult-coinbasevalue":     "Total amount available for the coinbase in Atoms",
+	"getblocktemplateresult-workid":            "This value must be returned with result if provided (not provided)",
+	"getblocktemplateresult-longpollid":        "Identifier for long poll request which allows monitoring for expiration",
+	"getblocktemplateresult-longpolluri":       "An alternate URI to use for long poll requests if provided (not provided)",
+	"getblocktemplateresult-submitold":         "Not applicable",
+	"getblocktemplateresult-target":            "Hex-encoded big-endian number which valid results must be less than",
+	"getblocktemplateresult-expires":           "Maximum number of seconds (starting from when the server sent the response) this work is valid for",
+	"getblocktemplateresult-maxtime":           "Maximum allowed time",
+	"getblocktemplateresult-mintime":           "Minimum allowed time",
+	"getblocktemplateresult-mutable":           "List of mutations the server explicitly allows",
+	"getblocktemplateresult-noncerange":        "Two concatenated hex-encoded big-endian 32-bit integers which represent the valid ranges of nonces the miner may scan",
+	"getblocktemplateresult-capabilities":      "List of server capabilities including 'proposal' to indicate support for block proposals",
+	"getblocktemplateresult-reject-reason":     "Reason the proposal was invalid as-is (only applies to proposal responses)",
+	"getblocktemplateresult-stransactions":     "Stake transactions",
+	"getblocktemplateresult-header":            "Block header",
+
+	// GetBlockTemplateCmd help.
+	"getblocktemplate--synopsis": "Returns a JSON object with information necessary to construct a block to mine or accepts a proposal to validate.\n" +
+		"See BIP0022 and BIP0023 for the full specification.\n" +
+		"Proposals are validated against all consensus rules except proof of work, and neither stored nor relayed.\n" +
+		"Unlike templates, they may be validated while CPU mining and without --miningaddr.",
+	"getblocktemplate-request":     "Request object which controls the mode and several parameters",
+	"getblocktemplate--condition0": "mode=template",
+	"getblocktemplate--condition1": "mode=proposal, rejected",
+	"getblocktemplate--condition2": "mode=proposal, accepted",
+	"getblocktemplate--result1":    "An error string which represents why the proposal was rejected, such as bad-txnmrklroot or bad-stake-votes, or nothing if accepted",
+
+	// GetConnectionCountCmd help.
+	"getconnectioncount--synopsis": "Returns the number of active connections to other peers.",
+	"getconnectioncount--result0":  "The number of connections",
+
+	// GetCurrentNetCmd help.
+	"getcurrentnet--synopsis": "Get HC network the server is running on.",
+	"getcurrentnet--result0":  "The network identifer",
+
+	// GetDifficultyCmd help.
+	"getdifficulty--synopsis": "Returns the proof-of-work difficulty as a multiple of the minimum difficulty.",
+	"getdifficulty--result0":  "The difficulty",
+
+	// GetRevocableTicketsCmd help.
+	"getrevocabletickets--synopsis": "Returns the tickets which missed their vote or expired and are not revoked yet, along with the input and the outputs needed to build their revocations.",
+
+	// GetRevocableTicketsResult help.
+	"getrevocableticketsresult-hash":    "The hash of the best block the tickets are revocable as of",
+	"getrevocableticketsresult-height":  "The height of the best block the tickets are revocable as of",
+	"getrevocableticketsresult-tickets": "The revocable tickets",
+
+	// RevocableTicketResult help.
+	"revocableticketresult-ticket":        "The hash of the ticket, which is the transaction the revocation spends",
+	"revocableticketresult-height":        "The height of the block which contains the ticket",
+	"revocableticketresult-expired":       "Whether the ticket expired instead of missing its vote",
+	"revocableticketresult-vout":          "The index of the ticket output the revocation spends",
+	"revocableticketresult-tree":          "The tree of the ticket output the revocation spends",
+	"revocableticketresult-amount":        "The amount of the ticket output",
+	"revocableticketresult-scriptversion": "The script version of the ticket output",
+	"revocableticketresult-scriptpubkey":  "The hex-encoded public key script of the ticket output",
+	"revocableticketresult-outputs":       "The outputs the revocation must pay to, in order",
+
+	// RevocationOutputResult help.
+	"revocationoutputresult-address": "The address committed to by the ticket",
+	"revocationoutputresult-amount":  "The amount returned when no fee is paid from the output",
+	"revocationoutputresult-maxfee":  "The maximum fee which may be paid from the output",
+
+	// GetStakeDifficultyCmd help.
+	"getstakedifficulty--synopsis":     "Returns the proof-of-stake difficulty.",
+	"getstakedifficultyresult-current": "The current top block's stake difficulty",
+	"getstakedifficultyresult-next":    "The calculated stake difficulty of the next block",
+
+	// GetStakeVersionInfoCmd help.
+	"getstakeversioninfo--synopsis":           "Returns stake version statistics for one or more stake version intervals.",
+	"getstakeversioninfo-count":               "Number of intervals to return.",
+	"getstakeversioninforesult-currentheight": "Top of the chain height.",
+	"getstakeversioninforesult-hash":          "Top of the chain hash.",
+	"getstakeversioninforesult-intervals":     "Array of total stake and vote counts.",
+	"versioncount-count":                      "Number of votes.",
+	"versioncount-version":                    "Version of the vote.",
+	"versioninterval-startheight":             "Start of the interval.",
+	"versioninterval-endheight":               "End of the interval.",
+	"versioninterval-voteversions":            "Tally of all vote versions.",
+	"versioninterval-posversions":             "Tally of the stake versions.",
+	"versioninterval-votebits":                "Tally of the votes by their version and vote bits.",
+	"versioninterval-agendas":                 "Tally of the choices the votes picked for the agendas of their version, omitting agendas nobody voted on.",
+	"versionbitscount-version":                "Version of the votes.",
+	"versionbitscount-bits":                   "Vote bits of the votes.",
+	"versionbitscount-count":                  "Number of votes.",
+	"agendatally-id":                          "Unique identifier of the agenda.",
+	"agendatally-version":                     "Vote version the agenda belongs to.",
+	"agendatally-choices":                     "Tally of the choices of the agenda.",
+	"agendachoicecount-id":                    "Unique identifier of the choice.",
+	"agendachoicecount-count":                 "Number of votes which picked the choice.",
+
+	// GetStakeDifficultyCmd help.
+	"getstakeversions--synopsis":           "Returns the stake versions statistics.",
+	"getstakeversions-hash":                "The start block hash.",
+	"getstakeversions-count":               "The number of blocks that will be returned.",
+	"getstakeversionsresult-stakeversions": "Array of stake versions per block.",
+	"stakeversions-hash":                   "Hash of the block.",
+	"stakeversions-height":                 "Height of the block.",
+	"stakeversions-blockversion":           "The block version",
+	"stakeversions-stakeversion":           "The stake version of the block",
+	"stakeversions-votes":                  "The version and bits of each vote in the block",
+	"versionbits-version":                  "The version of the vote.",
+	"versionbits-bits":                     "The bits assigned by the vote.",
+
+	// GetVoteInfo
+	"getvoteinfo--synopsis":           "Returns the vote info statistics.",
+	"getvoteinfo-version":             "The stake version.",
+	"getvoteinforesult-currentheight": "Top of the chain height.",
+	"getvoteinforesult-startheight":   "The start height of this voting window.",
+	"getvoteinforesult-endheight":     "The end height of this voting window.",
+	"getvoteinforesult-hash":          "The hash of the current height block.",
+	"getvoteinforesult-voteversion":   "Selected vote version.",
+	"getvoteinforesult-quorum":        "Minimum amount of votes required.",
+	"getvoteinforesult-totalvotes":    "Total votes.",
+	"getvoteinforesult-agendas":       "All agendas for this stake version.",
+	"agenda-id":                       "Unique identifier of this agenda.",
+	"agenda-description":              "Description of this agenda.",
+	"agenda-mask":                     "Agenda mask.",
+	"agenda-starttime":                "Time aganda becomes valid.",
+	"agenda-expiretime":               "Time aganda becomes invalid.",
+	"agenda-status":                   "Aganda status.",
+	"agenda-choice":                   "The choice the agenda locked in, activated or failed with, if any.",
+	"agenda-quorumprogress":           "Progress of quorum reached.",
+	"agenda-choices":                  "All choices in this agenda.",
+	"choice-id":                       "Unique identifier of this choice.",
+	"choice-description":              "Description of this choice.",
+	"choice-bits":                     "Bits that dentify this choice.",
+	"choice-isabstain":                "This choice is to abstain from change.",
+	"choice-isno":                     "Hard no choice (1 and only 1 per agenda).",
+	"choice-count":                    "How many votes received.",
+	"choice-progress":                 "Progress of the overall count.",
+
+	// GetGenerateCmd help.
+	"getgenerate--synopsis": "Returns if the server is set to generate coins (mine) or not.",
+	"getgenerate--result0":  "True if mining, false if not",
+
+	// GetHashesPerSecCmd help.
+	"gethashespersec--synopsis": "Returns a recent hashes per second performance measurement while generating coins (mining).",
+	"gethashespersec--result0":  "The number of hashes per second",
+
+	// InfoChainResult help.
+	"infochainresult-version":         "The version of the server",
+	"infochainresult-protocolversion": "The latest supported protocol version",
+	"infochainresult-blocks":          "The number of blocks processed",
+	"infochainresult-timeoffset":      "The time offset",
+	"infochainresult-connections":     "The number of connected peers",
+	"infochainresult-proxy":           "The proxy used by the server",
+	"infochainresult-difficulty":      "The current target difficulty",
+	"infochainresult-testnet":         "Whether or not server is using testnet",
+	"infochainresult-relayfee":        "The minimum relay fee for non-free transactions in HC/KB",
+	"infochainresult-errors":          "Any current errors",
+
+	// InfoWalletResult help.
+	"infowalletresult-version":         "The version of the server",
+	"infowalletresult-protocolversion": "The latest supported protocol version",
+	"infowalletresult-walletversion":   "The version of the wallet server",
+	"infowalletresult-balance":         "The total HC balance of the wallet",
+	"infowalletresult-blocks":          "The number of blocks processed",
+	"infowalletresult-timeoffset":      "The time offset",
+	"infowalletresult-connections":     "The number of connected peers",
+	"infowalletresult-proxy":           "The proxy used by the server",
+	"infowalletresult-difficulty":      "The current target difficulty",
+	"infowalletresult-testnet":         "Whether or not server is using testnet",
+	"infowalletresult-keypoololdest":   "Seconds since 1 Jan 1970 GMT of the oldest pre-generated key in the key pool",
+	"infowalletresult-keypoolsize":     "The number of new keys that are pre-generated",
+	"infowalletresult-unlocked_until":  "The timestamp in seconds since 1 Jan 1970 GMT that the wallet is unlocked for transfers, or 0 if the wallet is locked",
+	"infowalletresult-paytxfee":        "The transaction fee set in HC/KB",
+	"infowalletresult-relayfee":        "The minimum relay fee for non-free transactions in HC/KB",
+	"infowalletresult-errors":          "Any current errors",
+
+	// GetMemoryInfoCmd help.
+	"getmemoryinfo--synopsis": "Returns the memory statistics of the Go runtime along with the memory used by the subsystems which account for it.",
+
+	// GetMemoryInfoResult help.
+	"getmemoryinforesult-runtime":           "The memory statistics of the Go runtime",
+	"getmemoryinforesult-subsystems":        "The memory used by the memory pool, the signature cache and the script cache",
+	"getmemoryinforesult-subsystems--key":   "subsystem",
+	"getmemoryinforesult-subsystems--value": "{\"entries\": n, \"usage\": n, \"max\": n}",
+	"getmemoryinforesult-subsystems--desc":  "The number of entries along with the approximate current and maximum memory usage of the subsystem in bytes",
+
+	// MemoryRuntimeResult help.
+	"memoryruntimeresult-alloc":        "The bytes of allocated heap objects",
+	"memoryruntimeresult-totalalloc":   "The cumulative bytes allocated for heap objects",
+	"memoryruntimeresult-sys":          "The total bytes of memory obtained from the operating system",
+	"memoryruntimeresult-heapalloc":    "The bytes of allocated heap objects",
+	"memoryruntimeresult-heapsys":      "The bytes of heap memory obtained from the operating system",
+	"memoryruntimeresult-heapidle":     "The bytes in idle heap spans",
+	"memoryruntimeresult-heapinuse":    "The bytes in in-use heap spans",
+	"memoryruntimeresult-heapreleased": "The bytes of physical memory returned to the operating system",
+	"memoryruntimeresult-heapobjects":  "The number of allocated heap objects",
+	"memoryruntimeresult-stackinuse":   "The bytes in stack spans",
+	"memoryruntimeresult-numgc":        "The number of completed garbage collection cycles",
+	"memoryruntimeresult-gcpausetotal": "The cumulative garbage collection pause time in milliseconds",
+	"memoryruntimeresult-lastgc":       "The time the last garbage collection finished in seconds since 1 Jan 1970 GMT, or 0 if none has",
+	"memoryruntimeresult-goroutines":   "The number of goroutines",
+
+	// GetProfileCmd help.
+	"getprofile--synopsis": "Captures a pprof profile of the server and returns it base64-encoded for analysis with go tool pprof.\n" +
+		"The cpu, mutex and block profiles sample the events of the given number of seconds, while the heap and goroutine profiles are snapshots.\n" +
+		"Mutex contention and blocking events are only sampled while a profile of them is captured, and their profiles include the events of all earlier captures.\n" +
+		"Only one cpu, mutex or block profile can be captured at a time.",
+	"getprofile-type":    "The type of profile: cpu, heap, goroutine, mutex or block",
+	"getprofile-seconds": "The number of seconds to sample events for cpu, mutex and block profiles, at most 300",
+
+	// GetProfileResult help.
+	"getprofileresult-type":    "The type of the profile",
+	"getprofileresult-seconds": "The number of seconds events were sampled for, if any",
+	"getprofileresult-size":    "The size of the profile in bytes",
+	"getprofileresult-data":    "The base64-encoded profile in the gzipped protocol buffer format of pprof",
+
+	// GetLockStatsCmd help.
+	"getlockstats--synopsis": "Returns how long the mempool and chain locks were waited for and held at each call site while lock contention is profiled (--lockholdthreshold), ordered by the total hold time.",
+
+	// GetLockStatsResult help.
+	"getlockstatsresult-lock":         "The name of the lock: 'mempool' or 'chain'",
+	"getlockstatsresult-site":         "The function, file and line the lock was acquired at",
+	"getlockstatsresult-acquisitions": "The number of times the lock was acquired and released at the call site",
+	"getlockstatsresult-totalwait":    "The total time spent waiting to acquire the lock in milliseconds",
+	"getlockstatsresult-maxwait":      "The longest time spent waiting to acquire the lock in milliseconds",
+	"getlockstatsresult-totalhold":    "The total time the lock was held in milliseconds",
+	"getlockstatsresult-maxhold":      "The longest time the lock was held in milliseconds",
+
+	// GetSlowQueriesCmd help.
+	"getslowqueries--synopsis": "Returns the most recent RPC calls and database transactions which took longer than the slow query threshold (--slowquerythreshold), most recent first.",
+
+	// GetSlowQueriesResult help.
+	"getslowqueriesresult-time":         "The time the call or transaction started in seconds since 1 Jan 1970 GMT",
+	"getslowqueriesresult-type":         "The type of the query: 'rpc', 'dbview' or 'dbupdate'",
+	"getslowqueriesresult-name":         "The method of an RPC call or the function which made a database transaction",
+	"getslowqueriesresult-paramsdigest": "The first 8 bytes of the SHA-256 hash of the JSON-encoded parameters of an RPC call, which are equal for calls with the same parameters",
+	"getslowqueriesresult-duration":     "The time the call or transaction took in milliseconds",
+	"getslowqueriesresult-lockwait":     "The time a database transaction waited for locks before it started in milliseconds, including waiting for other write transactions to finish",
+
+	// GetHealthCmd help.
+	"gethealth--synopsis": "Returns the health of the server and of its subsystems, suitable for load balancer health checks.\n" +
+		"Each status is 'ok', 'degraded' or 'error' and the status of the server is the worst status of its subsystems.",
+
+	// GetHealthResult help.
+	"gethealthresult-status":        "The health of the server: 'ok', 'degraded' or 'error'",
+	"gethealthresult-sync":          "The state of the chain sync, degraded until the chain is current",
+	"gethealthresult-database":      "The latency of the database transactions and the stored blocks which failed to verify, degraded when the 99th percentile exceeds one second and an error when the database can't be read or blocks are quarantined",
+	"gethealthresult-mempool":       "The backlog of the memory pool, degraded once it uses 90% of its maximum size",
+	"gethealthresult-peers":         "The connected peers, an error without peers and degraded when they are in fewer than two network groups unless --connect is used",
+	"gethealthresult-errors":        "The last error logged by each subsystem since the server started, as long as its log level includes errors",
+	"gethealthresult-errors--key":   "subsystem",
+	"gethealthresult-errors--value": "{\"time\": n, \"message\": \"value\"}",
+	"gethealthresult-errors--desc":  "The time in seconds since 1 Jan 1970 GMT and the message of the last error of the subsystem",
+
+	// GetIndexInfoCmd help.
+	"getindexinfo--synopsis":       "Returns the state of the enabled optional indexes, including the progress of a rebuild started with startindexrebuild.",
+	"getindexinfo-index":           "Only return the state of this index: 'txindex', 'addrindex' or 'existsaddrindex'",
+	"getindexinfo--result0--desc":  "The state of the enabled optional indexes keyed by their name",
+	"getindexinfo--result0--key":   "index",
+	"getindexinfo--result0--value": "{\"synced\": true|false, \"height\": n, \"catchingup\": true|false, \"rebuilding\": true|false, \"progress\": n.nnn, \"error\": \"value\"}",
+
+	// IndexInfoResult help.
+	"indexinforesult-synced":     "Whether or not the index is caught up to the best block",
+	"indexinforesult-height":     "The height of the last block indexed",
+	"indexinforesult-catchingup": "Whether or not the index is behind the best block and being caught up in the background",
+	"indexinforesult-rebuilding": "Whether or not the index is being rebuilt",
+	"indexinforesult-progress":   "The height of the index relative to the best block between 0 and 1",
+	"indexinforesult-error":      "The error which stopped the last catch up or rebuild of the index, if any",
+
+	// HealthSyncResult help.
+	"healthsyncresult-status":          "The health of the chain sync",
+	"healthsyncresult-state":           "The sync state: 'idle', 'headers', 'blocks' or 'current'",
+	"healthsyncresult-height":          "The height of the best block",
+	"healthsyncresult-estimatedheight": "The estimated height of the best chain of the network",
+	"healthsyncresult-progress":        "The estimated progress of the sync between 0 and 1",
+
+	// HealthDatabaseResult help.
+	"healthdatabaseresult-status":       "The health of the database",
+	"healthdatabaseresult-samples":      "The number of recent transactions the latencies are computed from",
+	"healthdatabaseresult-p50":          "The median transaction latency in milliseconds",
+	"healthdatabaseresult-p90":          "The 90th percentile of the transaction latency in milliseconds",
+	"healthdatabaseresult-p99":          "The 99th percentile of the transaction latency in milliseconds",
+	"healthdatabaseresult-error":        "The error reading the database, if any",
+	"healthdatabaseresult-lastverified": "The time in seconds since 1 Jan 1970 GMT the last verification of the stored blocks enabled with --dbverifyinterval completed, omitted before the first one",
+	"healthdatabaseresult-quarantined":  "The stored blocks which failed to verify and were not repaired with copies from peers yet",
+
+	// HealthQuarantinedBlockResult help.
+	"healthquarantinedblockresult-hash":   "The hash of the block",
+	"healthquarantinedblockresult-height": "The height of the block",
+	"healthquarantinedblockresult-reason": "The reason the block failed to verify",
+	"healthquarantinedblockresult-since":  "The time in seconds since 1 Jan 1970 GMT the block was quarantined",
+
+	// HealthMempoolResult help.
+	"healthmempoolresult-status":     "The health of the memory pool",
+	"healthmempoolresult-size":       "The number of transactions in the memory pool",
+	"healthmempoolresult-usage":      "The estimated memory used by the transactions in bytes",
+	"healthmempoolresult-maxmempool": "The maximum memory used by the transactions in bytes, 0 when unlimited",
+
+	// HealthPeersResult help.
+	"healthpeersresult-status":    "The health of the peer connections",
+	"healthpeersresult-connected": "The number of connected peers",
+	"healthpeersresult-inbound":   "The number of inbound peers",
+	"healthpeersresult-outbound":  "The number of outbound peers",
+	"healthpeersresult-netgroups": "The number of distinct network groups of the peers",
+
+	// GetHeadersCmd help.
+	"getheaders--synopsis":     "Returns block headers starting with the first known block hash from the request",
+	"getheaders-blocklocators": "Concatenated hashes of blocks.  Headers are returned starting from the first known hash in this list",
+	"getheaders-hashstop":      "Optional block hash to stop including block headers for",
+	"getheadersresult-headers": "Serialized block headers of all located blocks, limited to some arbitrary maximum number of hashes (currently 2000, which matches the wire protocol headers message, but this is not guaranteed)",
+
+	// GetInfoCmd help.
+	"getinfo--synopsis": "Returns a JSON object containing various state info.",
+
+	// GetMempoolEntryCmd help.
+	"getmempoolentry--synopsis": "Returns information about a transaction in the memory pool, including when and from where it was first seen by the server.",
+	"getmempoolentry-txid":      "The hash of the transaction",
+
+	// GetMempoolEntryResult help.
+	"getmempoolentryresult-size":               "Transaction size in bytes",
+	"getmempoolentryresult-fee":                "Transaction fee in HC",
+	"getmempoolentryresult-time":               "Local time transaction entered pool in seconds since 1 Jan 1970 GMT",
+	"getmempoolentryresult-height":             "Block height when transaction entered the pool",
+	"getmempoolentryresult-startingpriority":   "Priority when transaction entered the pool",
+	"getmempoolentryresult-currentpriority":    "Current priority",
+	"getmempoolentryresult-depends":            "Unconfirmed transactions used as inputs for this transaction",
+	"getmempoolentryresult-receivedtime":       "The time the transaction was first seen in seconds since 1 Jan 1970 GMT (omitted when no longer known)",
+	"getmempoolentryresult-receivedtimemillis": "The time the transaction was first seen in milliseconds since 1 Jan 1970 GMT (omitted when no longer known)",
+	"getmempoolentryresult-receivedfrom":       "The address of the peer the transaction was first received from, or rpc when it was submitted via RPC (omitted when no longer known)",
+
+	// GetMempoolInfoCmd help.
+	"getmempoolinfo--synopsis": "Returns memory pool information",
+
+	// GetMempoolInfoResult help.
+	"getmempoolinforesult-bytes":         "Size in bytes of the mempool",
+	"getmempoolinforesult-size":          "Number of transactions in the mempool",
+	"getmempoolinforesult-usage":         "Estimated memory usage in bytes of the mempool",
+	"getmempoolinforesult-maxmempool":    "Maximum memory usage in bytes of the mempool (0 when unlimited)",
+	"getmempoolinforesult-mempoolminfee": "Minimum fee rate in HC/kB for transactions to be accepted, which is raised above the minimum relay fee when the mempool is full",
+
+	// GetMiningInfoResult help.
+	"getmininginforesult-blocks":           "Height of the latest best block",
+	"getmininginforesult-currentblocksize": "Size of the latest best block",
+	"getmininginforesult-currentblocktx":   "Number of transactions in the latest best block",
+	"getmininginforesult-difficulty":       "Current target difficulty",
+	"getmininginforesult-stakedifficulty":  "Stake difficulty required for the next block",
+	"getmininginforesult-errors":           "Any current errors",
+	"getmininginforesult-generate":         "Whether or not server is set to generate coins",
+	"getmininginforesult-genproclimit":     "Number of processors to use for coin generation (-1 when disabled)",
+	"getmininginforesult-hashespersec":     "Recent hashes per second performance measurement while generating coins",
+	"getmininginforesult-networkhashps":    "Estimated network hashes per second for the most recent blocks",
+	"getmininginforesult-pooledtx":         "Number of transactions in the memory pool",
+	"getmininginforesult-testnet":          "Whether or not server is using testnet",
+
+	// GetMiningInfoCmd help.
+	"getmininginfo--synopsis": "Returns a JSON object containing mining-related information.",
+
+	// GetNetworkHashPSCmd help.
+	"getnetworkhashps--synopsis": "Returns the estimated network hashes per second for the block heights provided by the parameters.",
+	"getnetworkhashps-blocks":    "The number of blocks, or -1 for blocks since last difficulty change",
+	"getnetworkhashps-height":    "Perform estimate ending with this height or -1 for current best chain block height",
+	"getnetworkhashps--result0":  "Estimated hashes per second",
+
+	// GetNetworkUpgradeInfoCmd help.
+	"getnetworkupgradeinfo--synopsis": "Returns which fraction of the connected peers and of the most recent blocks signal each protocol, block, stake and vote version, to follow network upgrades before they activate.",
+	"getnetworkupgradeinfo-blocks":    "The number of most recent blocks to examine (default: the block version upgrade window of the network)",
+
+	// GetNetworkUpgradeInfoResult help.
+	"getnetworkupgradeinforesult-height":             "The height of the current best chain block",
+	"getnetworkupgradeinforesult-hash":               "The hash of the current best chain block",
+	"getnetworkupgradeinforesult-rulechangeheight":   "The height of the first block the outcome of the current rule change voting window applies to",
+	"getnetworkupgradeinforesult-stakeversionheight": "The height of the first block the outcome of the current stake version interval applies to",
+	"getnetworkupgradeinforesult-peers":              "The protocol versions of the connected peers",
+	"getnetworkupgradeinforesult-blocks":             "The versions signaled by the most recent blocks",
+
+	// NetworkUpgradePeersResult help.
+	"networkupgradepeersresult-protocolversion": "The protocol version of this node",
+	"networkupgradepeersresult-connected":       "The number of connected peers",
+	"networkupgradepeersresult-upgraded":        "The number of connected peers at the protocol version of this node or a later one",
+	"networkupgradepeersresult-versions":        "The protocol versions of the connected peers, latest first",
+
+	// NetworkUpgradeBlocksResult help.
+	"networkupgradeblocksresult-window":          "The number of blocks examined",
+	"networkupgradeblocksresult-enforcerequired": "The number of blocks out of the block version upgrade window which must signal a block version for its rules to be enforced",
+	"networkupgradeblocksresult-rejectrequired":  "The number of blocks out of the block version upgrade window which must signal a block version for older block versions to be rejected",
+	"networkupgradeblocksresult-blockversions":   "The block versions of the examined blocks, latest first",
+	"networkupgradeblocksresult-stakeversions":   "The stake versions of the examined blocks, latest first",
+	"networkupgradeblocksresult-voteversions":    "The versions of the votes in the examined blocks, latest first",
+
+	// VersionShare help.
+	"versionshare-version": "The version",
+	"versionshare-count":   "The number of peers, blocks or votes signaling the version",
+	"versionshare-percent": "The percentage of all peers, blocks or votes signaling the version",
+
+	// GetNetTotalsCmd help.
+	"getnettotals--synopsis": "Returns a JSON object containing network traffic statistics.",
+
+	// GetNetTotalsResult help.
+	"getnettotalsresult-totalbytesrecv": "Total bytes received",
+	"getnettotalsresult-totalbytessent": "Total bytes sent",
+	"getnettotalsresult-timemillis":     "Number of milliseconds since 1 Jan 1970 GMT",
+
+	// GetPeerInfoResult help.
+	"getpeerinforesult-id":                "A unique node ID",
+	"getpeerinforesult-addr":              "The ip address and port of the peer",
+	"getpeerinforesult-addrlocal":         "Local address",
+	"getpeerinforesult-services":          "Services bitmask which represents the services supported by the peer",
+	"getpeerinforesult-lastsend":          "Time the last message was received in seconds since 1 Jan 1970 GMT",
+	"getpeerinforesult-lastrecv":          "Time the last message was sent in seconds since 1 Jan 1970 GMT",
+	"getpeerinforesult-bytessent":         "Total bytes sent",
+	"getpeerinforesult-bytesrecv":         "Total bytes received",
+	"getpeerinforesult-conntime":          "Time the connection was made in seconds since 1 Jan 1970 GMT",
+	"getpeerinforesult-timeoffset":        "The time offset of the peer",
+	"getpeerinforesult-pingtime":          "Number of microseconds the last ping took",
+	"getpeerinforesult-pingwait":          "Number of microseconds a queued ping has been waiting for a response",
+	"getpeerinforesult-version":           "The protocol version of the peer",
+	"getpeerinforesult-subver":            "The user agent of the peer",
+	"getpeerinforesult-inbound":           "Whether or not the peer is an inbound connection",
+	"getpeerinforesult-startingheight":    "The latest block height the peer knew about when the connection was established",
+	"getpeerinforesult-currentheight":     "The current height of the peer",
+	"getpeerinforesult-banscore":          "The ban score",
+	"getpeerinforesult-syncnode":          "Whether or not the peer is the sync peer",
+	"getpeerinforesult-sentpermsg":        "Number and total size of the messages sent to the peer per wire command",
+	"getpeerinforesult-sentpermsg--key":   "command",
+	"getpeerinforesult-sentpermsg--value": "{\"count\": n, \"bytes\": n}",
+	"getpeerinforesult-sentpermsg--desc":  "The number and total size in bytes of the messages of the command",
+	"getpeerinforesult-recvpermsg":        "Number and total size of the messages received from the peer per wire command",
+	"getpeerinforesult-recvpermsg--key":   "command",
+	"getpeerinforesult-recvpermsg--value": "{\"count\": n, \"bytes\": n}",
+	"getpeerinforesult-recvpermsg--desc":  "The number and total size in bytes of the messages of the command",
+	"getpeerinforesult-features":          "Optional protocol features negotiated with the peer and their versions",
+	"getpeerinforesult-features--key":     "feature",
+	"getpeerinforesult-features--value":   "n",
+	"getpeerinforesult-features--desc":    "The negotiated version of the feature",
+
+	// GetPeerInfoCmd help.
+	"getpeerinfo--synopsis": "Returns data about each connected network peer as an array of json objects.",
+
+	// GetRawMempoolVerboseResult help.
+	"getrawmempoolverboseresult-size":             "Transaction size in bytes",
+	"getrawmempoolverboseresult-fee":              "Transaction fee in HC",
+	"getrawmempoolverboseresult-time":             "Local time transaction entered pool in seconds since 1 Jan 1970 GMT",
+	"getrawmempoolverboseresult-height":           "Block height when transaction entered the pool",
+	"getrawmempoolverboseresult-startingpriority": "Priority when transaction entered the pool",
+	"getrawmempoolverboseresult-currentpriority":  "Current priority",
+	"getrawmempoolverboseresult-depends":          "Unconfirmed transactions used as inputs for this transaction",
+
+	// GetRawMempoolCmd help.
+	"getrawmempool--synopsis":   "Returns information about all of the transactions currently in the memory pool.",
+	"getrawmempool-verbose":     "Returns JSON object when true or an array of transaction hashes when false",
+	"getrawmempool-txtype":      "Type of tx to return. (all/regular/tickets/votes/revocations)",
+	"getrawmempool--condition0": "verbose=false",
+	"getrawmempool--condition1": "verbose=true",
+	"getrawmempool--result0":    "Array of transaction hashes",
+
+	// GetRawTransactionCmd help.
+	"getrawtransaction--synopsis":   "Returns information about a transaction given its hash.",
+	"getrawtransaction-txid":        "The hash of the transaction",
+	"getrawtransaction-verbose":     "Specifies the transaction is returned as a JSON object instead of a hex-encoded string",
+	"getrawtransaction--condition0": "verbose=false",
+	"getrawtransaction--condition1": "verbose=true",
+	"getrawtransaction--result0":    "Hex-encoded bytes of the serialized transaction",
+
+	// GetTicketPoolValue help.
+	"getticketpoolvalue--synopsis": "Return the current value of all locked funds in the ticket pool",
+	"getticketpoolvalue--result0":  "Total value of ticket pool",
+
+	// GetTxOutResult help.
+	"gettxoutresult-bestblock":     "The block hash that contains the transaction output",
+	"gettxoutresult-confirmations": "The number of confirmations",
+	"gettxoutresult-value":         "The transaction amount in HC",
+	"gettxoutresult-scriptPubKey":  "The public key script used to pay coins as a JSON object",
+	"gettxoutresult-version":       "The transaction version",
+	"gettxoutresult-coinbase":      "Whether or not the transaction is a coinbase",
+
+	// GetTxOutCmd help.
+	"gettxout--synopsis":      "Returns information about an unspent transaction output..",
+	"gettxout-txid":           "The hash of the transaction",
+	"gettxout-vout":           "The index of the output",
+	"gettxout-includemempool": "Include the mempool when true",
+
+	// GetUtxoSetCmd help.
+	"getutxoset--synopsis": "Returns the unspent transaction outputs of the main chain a batch at a time.\n" +
+		"A call without a cursor starts a new iteration over the outputs as of the current best block and the returned cursor continues it.\n" +
+		"The outputs stay consistent with that block while new blocks are processed.\n" +
+		"A cursor is closed once all outputs were returned or after it went unused for 5 minutes, and only 4 cursors may be open at the same time.",
+	"getutxoset-cursor": "The cursor returned by the previous call to continue an iteration, or empty to start a new one",
+	"getutxoset-count":  "The maximum number of outputs to return (at most 10000)",
+
+	// GetUtxoSetResult help.
+	"getutxosetresult-cursor": "The cursor to pass to the next call, or empty when done",
+	"getutxosetresult-hash":   "The hash of the best block at the time the iteration started",
+	"getutxosetresult-height": "The height of the best block at the time the iteration started",
+	"getutxosetresult-done":   "Whether or not all outputs have been returned",
+	"getutxosetresult-utxos":  "The outputs ordered by the hash of their transaction and their index",
+
+	// UtxoSetEntryResult help.
+	"utxosetentryresult-txid":          "The hash of the transaction which created the output",
+	"utxosetentryresult-vout":          "The index of the output",
+	"utxosetentryresult-tree":          "The transaction tree of the transaction which created the output",
+	"utxosetentryresult-amount":        "The amount of the output in coins",
+	"utxosetentryresult-height":        "The height of the block containing the transaction which created the output",
+	"utxosetentryresult-blockindex":    "The index of the transaction which created the output within its block",
+	"utxosetentryresult-coinbase":      "Whether or not the output was created by a coinbase",
+	"utxosetentryresult-txtype":        "The type of the transaction which created the output (regular, ticket, vote or revocation)",
+	"utxosetentryresult-scriptversion": "The version of the public key script",
+	"utxosetentryresult-pkscript":      "The hex-encoded public key script",
+
+	// GetWorkResult help.
+	"getworkresult-data":     "Hex-encoded block data",
+	"getworkresult-hash1":    "(DEPRECATED) Hex-encoded formatted hash buffer",
+	"getworkresult-midstate": "(DEPRECATED) Hex-encoded precomputed hash state after hashing first half of the data",
+	"getworkresult-target":   "Hex-encoded little-endian hash target",
+
+	// GetWorkCmd help.
+	"getwork--synopsis":   "(DEPRECATED - Use getblocktemplate instead) Returns formatted hash data to work on or checks and submits solved data.",
+	"getwork-data":        "Hex-encoded data to check",
+	"getwork--condition0": "no data provided",
+	"getwork--condition1": "data provided",
+	"getwork--result1":    "Whether or not the solved data is valid and was added to the chain",
+
+	// HelpCmd help.
+	"help--synopsis":   "Returns a list of all commands or help for a specified command.",
+	"help-command":     "The command to retrieve help for",
+	"help--condition0": "no command provided",
+	"help--condition1": "command specified",
+	"help--result0":    "List of commands",
+	"help--result1":    "Help for specified command",
+
+	// PingCmd help.
+	"ping--synopsis": "Queues a ping to be sent to each connected peer.\n" +
+		"Ping times are provided by getpeerinfo via the pingtime and pingwait fields.",
+
+	// PrioritiseTransactionCmd help.
+	"prioritisetransaction--synopsis": "Adds a fee and a priority delta to a transaction so the block template generator orders it accordingly.\n" +
+		"The deltas accumulate over calls and are kept until the transaction leaves the memory pool, such as when it is mined or evicted.\n" +
+		"They may be set before the transaction enters the memory pool.  The fee delta does not change the fee the transaction pays.",
+	"prioritisetransaction-txid":          "The hash of the transaction",
+	"prioritisetransaction-prioritydelta": "The amount to add to the priority of the transaction",
+	"prioritisetransaction-feedelta":      "The amount in atoms to add to the fee of the transaction when ordering it, negative to lower it",
+	"prioritisetransaction--result0":      "Always true",
+
+	// ReconsiderBlockCmd help.
+	"reconsiderblock--synopsis": "Approves reorganizing the chain to the side chain containing the block regardless of the maximum automatic reorganization depth (--maxreorgdepth).\n" +
+		"The chain is reorganized to the tip with the most work descending from the block right away when it has more work than the current best chain, otherwise the approval takes effect once it does.",
+	"reconsiderblock-blockhash": "The hash of the block",
+
+	// RebroadcastMissed help.
+	"rebroadcastmissed--synopsis": "Asks the daemon to rebroadcast missed votes.\n",
+
+	// RebroadcastWinnerCmd help.
+	"rebroadcastwinners--synopsis": "Asks the daemon to rebroadcast the winners of the voting lottery.\n",
+
+	// ReloadConfigCmd help.
+	"reloadconfig--synopsis": "Reads the configuration file and the command line options the daemon was started with again and applies the whitelisted and blacklisted networks, the RPC credentials, the minimum relay fee and the peers given with --addpeer or --connect.\n" +
+		"All other options keep their values until the daemon is restarted and nothing is changed when the new configuration is invalid.\n" +
+		"Sending SIGHUP to the daemon has the same effect.",
+
+	// SearchRawTransactionsCmd help.
+	"searchrawtransactions--synopsis": "Returns raw data for transactions involving the passed address.\n" +
+		"Returned transactions are pulled from both the database, and transactions currently in the mempool.\n" +
+		"Transactions pulled from the mempool will have the 'confirmations' field set to 0.\n" +
+		"Usage of this RPC requires the optional --addrindex flag to be activated, otherwise all responses will simply return with an error stating the address index has not yet been built.\n" +
+		"Similarly, until the address index has caught up with the current best height, all requests will return an error response in order to avoid serving stale data.",
+	"searchrawtransactions-address":     "The Hcd address to search for",
+	"searchrawtransactions-verbose":     "Specifies the transaction is returned as a JSON object instead of hex-encoded string",
+	"searchrawtransactions--condition0": "verbose=0",
+	"searchrawtransactions--condition1": "verbose=1",
+	"searchrawtransactions-skip":        "The number of leading transactions to leave out of the final response",
+	"searchrawtransactions-count":       "The maximum number of transactions to return",
+	"searchrawtransactions-vinextra":    "Specify that extra data from previous output will be returned in vin",
+	"searchrawtransactions-reverse":     "Specifies that the transactions should be returned in reverse chronological order",
+	"searchrawtransactions-filteraddrs": "Address list.  Only inputs or outputs with matching address will be returned",
+	"searchrawtransactions--result0":    "Hex-encoded serialized transaction",
+
+	// SendRawTransactionCmd help.
+	"sendrawtransaction--synopsis":     "Submits the serialized, hex-encoded transaction to the local peer and relays it to the network.",
+	"sendrawtransaction-hextx":         "Serialized, hex-encoded signed transaction",
+	"sendrawtransaction-allowhighfees": "Whether or not to allow insanely high fees (hcd does not yet implement this parameter, so it has no effect)",
+	"sendrawtransaction--result0":      "The hash of the transaction",
+
+	// SubmitRawTransactionPackageCmd help.
+	"submitrawtransactionpackage--synopsis":     "Submits a topologically ordered package of serialized, hex-encoded dependent transactions which are validated and accepted to the memory pool as a unit.\nThe minimum relay fee is applied to the package as a whole, so parents which pay less than the minimum may be accepted when their children pay for them.",
+	"submitrawtransactionpackage-hextxs":        "Serialized, hex-encoded signed transactions ordered such that parents precede the children which spend them",
+	"submitrawtransactionpackage-allowhighfees": "Whether or not to allow insanely high fees",
+
+	// SubmitRawTransactionPackageResult help.
+	"submitrawtransactionpackageresult-accepted":     "Whether or not the package was accepted to the memory pool",
+	"submitrawtransactionpackageresult-transactions": "The results for each transaction in the package in the order they were submitted",
+
+	// SubmitRawTransactionPackageTxResult help.
+	"submitrawtransactionpackagetxresult-txid":   "The hash of the transaction",
+	"submitrawtransactionpackagetxresult-reason": "The reason the transaction was rejected when the package was not accepted",
+
+	// TraceScriptCmd help.
+	"tracescript--synopsis":  "Executes the signature script of the specified transaction input along with the public key script it redeems using the standard verification flags and returns every executed opcode along with the resulting stacks.\nThis is intended to help debug scripts which fail to validate.",
+	"tracescript-hextx":      "Serialized, hex-encoded transaction",
+	"tracescript-inputindex": "The index of the input whose signature script is executed",
+	"tracescript-pkscript":   "Hex-encoded public key script of the output the input redeems",
+
+	// TraceScriptResult help.
+	"tracescriptresult-valid": "Whether or not the scripts executed successfully",
+	"tracescriptresult-error": "The reason the scripts failed to execute",
+	"tracescriptresult-flags": "The script verification flags the scripts were executed with",
+	"tracescriptresult-steps": "The executed opcodes in order of execution",
+
+	// TraceScriptStep help.
+	"tracescriptstep-scriptidx": "The index of the script the opcode is part of (0 for the signature script, 1 for the public key script and 2 for a pay-to-script-hash redeem script)",
+	"tracescriptstep-scriptoff": "The offset of the opcode within its script",
+	"tracescriptstep-opcode":    "The disassembled opcode",
+	"tracescriptstep-stack":     "The hex-encoded data stack after executing the opcode, bottom up",
+	"tracescriptstep-altstack":  "The hex-encoded alternate data stack after executing the opcode, bottom up",
+	"tracescriptstep-error":     "The error the opcode failed with",
+
+	// SetTxRebroadcastCmd help.
+	"settxrebroadcast--synopsis":   "Changes the settings of the rebroadcasting of unconfirmed transactions submitted via RPC, which are periodically announced to peers they have not been announced to yet until they are included in a block.\nThe time to wait between rebroadcasts of a transaction starts at the interval and doubles after every rebroadcast up to the max interval.\nOmitted settings are left unchanged, so the current settings are returned when no parameters are specified.",
+	"settxrebroadcast-enable":      "Use true to enable rebroadcasting, false to disable it",
+	"settxrebroadcast-interval":    "The number of seconds to wait before the first rebroadcast of a transaction",
+	"settxrebroadcast-maxinterval": "The maximum number of seconds to wait between rebroadcasts of a transaction",
+
+	// SetTxRebroadcastResult help.
+	"settxrebroadcastresult-enabled":     "Whether or not rebroadcasting is enabled",
+	"settxrebroadcastresult-interval":    "The number of seconds to wait before the first rebroadcast of a transaction",
+	"settxrebroadcastresult-maxinterval": "The maximum number of seconds to wait between rebroadcasts of a transaction",
+	"settxrebroadcastresult-pending":     "The number of transactions pending rebroadcast",
+
+	// SetGenerateCmd help.
+	"setgenerate--synopsis":    "Set the server to generate coins (mine) or not.",
+	"setgenerate-generate":     "Use true to enable generation, false to disable it",
+	"setgenerate-genproclimit": "The number of processors (cores) to limit generation to or -1 for default",
+
+	// SetMaxPeersCmd help.
+	"setmaxpeers--synopsis": "Changes the maximum number of inbound and outbound peers (--maxpeers) and the number of outbound peers maintained along with it until the server is restarted.\n" +
+		"Peers above a lowered maximum stay connected, but new peers are only accepted once enough of them disconnected.",
+	"setmaxpeers-maxpeers": "The new maximum number of peers",
+
+	// StartIndexRebuildCmd help.
+	"startindexrebuild--synopsis": "Drops an optional index and rebuilds it in the background while the server keeps processing blocks, without restarting it.\n" +
+		"The address index is rebuilt along with the transaction index since it refers to it.\n" +
+		"The index is incomplete until the rebuild is done, which getindexinfo reports, and a rebuild interrupted by a shutdown is finished on the next start.",
+	"startindexrebuild-index": "The index to rebuild: 'txindex', 'addrindex' or 'existsaddrindex'",
+
+	// StopCmd help.
+	"stop--synopsis": "Shutdown hcd.",
+	"stop--result0":  "The string 'hcd stopping.'",
+
+	// SubmitBlockOptions help.
+	"submitblockoptions-workid": "This parameter is currently ignored",
+
+	// SubmitBlockCmd help.
+	"submitblock--synopsis":   "Attempts to submit a new serialized, hex-encoded block to the network.",
+	"submitblock-hexblock":    "Serialized, hex-encoded block",
+	"submitblock-options":     "This parameter is currently ignored",
+	"submitblock--condition0": "Block successfully submitted",
+	"submitblock--condition1": "Block rejected",
+	"submitblock--result1":    "The reason the block was rejected",
+
+	// ValidateAddressResult help.
+	"validateaddresschainresult-isvalid":      "Whether or not the address is valid",
+	"validateaddresschainresult-address":      "The HC address (only when isvalid is true)",
+	"validateaddresschainresult-type":         "The type of the script which pays to the address (e.g. 'pubkeyhash')",
+	"validateaddresschainresult-sigtype":      "The signature scheme of the address (omitted for pay-to-script-hash addresses)",
+	"validateaddresschainresult-isscript":     "Whether or not the address is a pay-to-script-hash address",
+	"validateaddresschainresult-pubkey":       "The hex-encoded public key (only for public key addresses)",
+	"validateaddresschainresult-scriptpubkey": "The hex-encoded script which pays to the address",
+
+	// ValidateAddressCmd help.
+	"validateaddress--synopsis": "Verify an address is valid.",
+	"validateaddress-address":   "Hcd address to validate",
+
+	// VerifyChainCmd help.
+	"verifychain--synopsis": "Verifies the block chain database.\n" +
+		"The actual checks performed by the checklevel parameter are implementation specific.\n" +
+		"For hcd this is:\n" +
+		"checklevel=0 - Look up each block and ensure it can be loaded from the database.\n" +
+		"checklevel=1 - Perform basic context-free sanity checks on each block.",
+	"verifychain-checklevel": "How thorough the block verification is",
+	"verifychain-checkdepth": "The number of blocks to check",
+	"verifychain--result0":   "Whether or not the chain verified",
+
+	// VerifyHeaderChainCmd help.
+	"verifyheaderchain--synopsis": "Verifies a chain of block headers which extends a block known to the node against the proof of work, difficulty, timestamp and checkpoint rules without storing them.\n" +
+		"The stake version rules depend on the votes of the blocks and are not checked.",
+	"verifyheaderchain-headers": "The hex-encoded serialized block headers in chain order (max 2000)",
+
+	// VerifyHeaderChainResult help.
+	"verifyheaderchainresult-valid":     "Whether or not the headers are valid",
+	"verifyheaderchainresult-error":     "The rule violated by the first invalid header (only when invalid)",
+	"verifyheaderchainresult-height":    "The height of the last header (only when valid)",
+	"verifyheaderchainresult-hash":      "The hash of the last header (only when valid)",
+	"verifyheaderchainresult-chainwork": "The hex-encoded cumulative work of the chain up to and including the last header (only when valid)",
+
+	// VerifyMessageCmd help.
+	"verifymessage--synopsis": "Verify a signed message.",
+	"verifymessage-address":   "The HC address to use for the signature",
+	"verifymessage-signature": "The base-64 encoded signature provided by the signer",
+	"verifymessage-message":   "The signed message",
+	"verifymessage--result0":  "Whether or not the signature verified",
+
+	// VerifyBlissMessageCmd help.
+	"verifyblissmessage--synopsis": "Verify a signed message.",
+	"verifyblissmessage-pubKey":    "The hypercash bliss public key to use for the signature",
+	"verifyblissmessage-signature": "The base-64 encoded signature provided by the signer",
+	"verifyblissmessage-message":   "The signed message",
+	"verifyblissmessage--result0":  "Whether or not the signature verified",
+
+	// -------- Websocket-specific help --------
+
+	// Session help.
+	"session--synopsis":       "Return details regarding a websocket client's current connection session.",
+	"sessionresult-sessionid": "The unique session ID for a client's websocket connection.",
+
+	// NotifySpentAndMissedTicketsCmd help
+	"notifyspentandmissedtickets--synopsis": "Request notifications for whenever tickets are spent or missed.",
+
+	// NotifyNewTicketsCmd help
+	"notifynewtickets--synopsis": "Request notifications for whenever new tickets are found.",
+
+	// NotifyRevocableTicketsCmd help
+	"notifyrevocabletickets--synopsis": "Request notifications for whenever tickets miss their vote or expire and become revocable.",
+
+	// NotifyStakeDifficultyCmd help
+	"notifystakedifficulty--synopsis": "Request notifications for whenever stake difficulty goes up.",
+
+	// NotifyWinningTicketsCmd help
+	"notifywinningtickets--synopsis": "Request notifications for whenever any tickets is chosen to vote.",
+
+	// NotifyBlocksCmd help.
+	"notifyblocks--synopsis": "Request notifications for whenever a block is connected or disconnected from the main (best) chain.",
+
+	// StopNotifyBlocksCmd help.
+	"stopnotifyblocks--synopsis": "Cancel registered notifications for whenever a block is connected or disconnected from the main (best) chain.",
+
+	// NotifyNewTransactionsCmd help.
+	"notifynewtransactions--synopsis": "Send either a txaccepted or a txacceptedverbose notification when a new transaction is accepted into the mempool.",
+	"notifynewtransactions-verbose":   "Specifies which type of notification to receive. If verbose is true, then the caller receives txacceptedverbose, otherwise the caller receives txaccepted",
+
+	// StopNotifyNewTransactionsCmd help.
+	"stopnotifynewtransactions--synopsis": "Stop sending either a txaccepted or a txacceptedverbose notification when a new transaction is accepted into the mempool.",
+
+	// OutPoint help.
+	"outpoint-hash":  "The hex-encoded bytes of the outpoint hash",
+	"outpoint-index": "The index of the outpoint",
+	"outpoint-tree":  "The tree of the outpoint",
+
+	// LoadTxFilterCmd help.
+	"loadtxfilter--synopsis": "Load, add to, or reload a websocket client's transaction filter for mempool transactions, new blocks and rescans.",
+	"loadtxfilter-reload":    "Load a new filter instead of adding data to an existing one",
+	"loadtxfilter-addresses": "Array of addresses to add to the transaction filter",
+	"loadtxfilter-outpoints": "Array of outpoints to add to the transaction filter",
+
+	// Rescan help.
+	"rescan--synopsis":   "Rescan blocks for transactions matching the loaded transaction filter.",
+	"rescan-blockhashes": "Concatenated block hashes to rescan.  Each next block must be a child of the previous.",
+
+	// -------- Hcd-specific help --------
+
+	// EstimateFee help.
+	"estimatefee--synopsis": "Returns the estimated fee in hc/kb.",
+	"estimatefee-numblocks": "(unused)",
+	"estimatefee--result0":  "Estimated fee.",
+
+	// EstimateStakeDiff help.
+	"estimatestakediff--synopsis":      "Estimate the next minimum, maximum, expected, and user-specified stake difficulty",
+	"estimatestakediff-tickets":        "Use this number of new tickets in blocks to estimate the next difficulty",
+	"estimatestakediffresult-min":      "Minimum estimate for stake difficulty",
+	"estimatestakediffresult-max":      "Maximum estimate for stake difficulty",
+	"estimatestakediffresult-expected": "Expected estimate for stake difficulty",
+	"estimatestakediffresult-user":     "Estimate for stake difficulty with the passed user amount of tickets",
+
+	// GetChainStatsCmd help.
+	"getchainstats--synopsis": "Returns statistics about the transactions, fees and stake participation of a window of consecutive main chain blocks.",
+	"getchainstats-blocks":    "The number of blocks in the window (max: 10000)",
+	"getchainstats-blockhash": "The hash of the final block of the window (default: the best chain block)",
+
+	// GetChainStatsResult help.
+	"getchainstatsresult-startheight":        "The height of the first block of the window",
+	"getchainstatsresult-endheight":          "The height of the final block of the window",
+	"getchainstatsresult-endhash":            "The hash of the final block of the window",
+	"getchainstatsresult-blocks":             "The number of blocks in the window",
+	"getchainstatsresult-windowinterval":     "The number of seconds between the timestamps of the block preceding the window, or the genesis block, and the final block of the window",
+	"getchainstatsresult-avgblockinterval":   "The average number of seconds between the blocks of the window",
+	"getchainstatsresult-txcount":            "The number of regular and stake transactions in the window, including coinbases",
+	"getchainstatsresult-txrate":             "The average number of transactions per second over the window interval",
+	"getchainstatsresult-totalfees":          "The total fees paid by the transactions of the window in coins",
+	"getchainstatsresult-avgblockfees":       "The average fees paid per block of the window in coins",
+	"getchainstatsresult-tickets":            "The number of tickets purchased in the window",
+	"getchainstatsresult-votes":              "The number of votes cast in the window",
+	"getchainstatsresult-revocations":        "The number of tickets revoked in the window",
+	"getchainstatsresult-stakeparticipation": "The fraction of the possible votes of the window which were cast",
+
+	// GetChainTipsCmd help.
+	"getchaintips--synopsis": "Returns information about the tips of all known branches of the block chain, including the main chain.",
+
+	// GetChainTipsResult help.
+	"getchaintipsresult-height":    "The height of the tip",
+	"getchaintipsresult-hash":      "The hash of the tip",
+	"getchaintipsresult-branchlen": "The number of blocks between the tip and the main chain (0 for the main chain)",
+	"getchaintipsresult-work":      "The total cumulative work in the chain ending at the tip",
+	"getchaintipsresult-status":    "The status of the branch (active, valid-fork, valid-headers or invalid)",
+
+	// GetCheckpointCandidatesCmd help.
+	"getcheckpointcandidates--synopsis": "Returns candidate checkpoints for inclusion in the chain parameters, most recent first.\n" +
+		"Candidates are main chain blocks after the latest checkpoint which are buried deeply enough, have timestamps in order with the surrounding blocks and contain only standard transactions.\n" +
+		"Run with --nocheckpoints to fully validate the chain and search it back to the genesis block.",
+	"getcheckpointcandidates-count": "The maximum number of candidates to return",
+
+	// CheckpointCandidateResult help.
+	"checkpointcandidateresult-height": "The height of the candidate block",
+	"checkpointcandidateresult-hash":   "The hash of the candidate block",
+
+	// GetCoinSupply help
+	"getcoinsupply--synopsis": "Returns the total subsidy of the main chain in atoms, or a breakdown of the coin supply of the main chain when verbose is true.\n" +
+		"The coin supply is tracked as blocks are connected and disconnected.",
+	"getcoinsupply-verbose":     "Specifies the coin supply is broken down by how the coins were issued",
+	"getcoinsupply-blockhash":   "The hash of the main chain block to return the coin supply as of (default: best block)",
+	"getcoinsupply--condition0": "verbose=false",
+	"getcoinsupply--condition1": "verbose=true",
+	"getcoinsupply--result0":    "Total subsidy of the main chain in atoms",
+
+	// CoinSupplyResult help.
+	"coinsupplyresult-pow":           "The subsidy paid to miners in atoms",
+	"coinsupplyresult-pos":           "The subsidy paid to voters in atoms",
+	"coinsupplyresult-dev":           "The subsidy paid to the organization in atoms",
+	"coinsupplyresult-blockone":      "The subsidy of the first block in atoms",
+	"coinsupplyresult-subsidy":       "The sum of all subsidies in atoms",
+	"coinsupplyresult-feesdestroyed": "The transaction fees not claimed by any coinbase in atoms",
+	"coinsupplyresult-supply":        "The subsidy less the destroyed fees in atoms",
+
+	// GetCoinSupplyResult help.
+	"getcoinsupplyresult-hash":   "The hash of the block",
+	"getcoinsupplyresult-height": "The height of the block",
+	"getcoinsupplyresult-block":  "The coin supply added by the block",
+	"getcoinsupplyresult-total":  "The total coin supply of the main chain ending at the block",
+
+	// LiveTickets help.
+	"livetickets--synopsis":     "Request tickets the live ticket hashes from the ticket database",
+	"liveticketsresult-tickets": "List of live tickets",
+
+	// MissedTickets help.
+	"missedtickets--synopsis":     "Request tickets the client missed",
+	"missedticketsresult-tickets": "List of missed tickets",
+
+	// TicketBuckets help.
+	"ticketbuckets--synopsis": "Request for the number of tickets currently in each bucket of the ticket database.",
+	"ticketbucket-tickets":    "Number of tickets in bucket.",
+	"ticketbucket-number":     "Bucket number.",
+
+	// TicketFeeInfo help.
+	"ticketfeeinfo--synopsis":            "Get various information about ticket fees from the mempool, blocks, and difficulty windows (units: HC/kB)",
+	"ticketfeeinfo-blocks":               "The number of blocks, starting from the chain tip and descending, to return fee information about",
+	"ticketfeeinfo-windows":              "The number of difficulty windows to return ticket fee information about",
+	"ticketfeeinforesult-feeinfomempool": "Ticket fee information for all tickets in the mempool (units: HC/kB)",
+	"ticketfeeinforesult-feeinfoblocks":  "Ticket fee information for a given list of blocks descending from the chain tip (units: HC/kB)",
+	"ticketfeeinforesult-feeinfowindows": "Ticket fee information for a window period where the stake difficulty was the same (units: HC/kB)",
+
+	"feeinfomempool-number": "Number of transactions in the mempool",
+	"feeinfomempool-min":    "Minimum transaction fee in the mempool",
+	"feeinfomempool-max":    "Maximum transaction fee in the mempool",
+	"feeinfomempool-mean":   "Mean of transaction fees in the mempool",
+	"feeinfomempool-median": "Median of transaction fees in the mempool",
+	"feeinfomempool-stddev": "Standard deviation of transaction fees in the mempool",
+
+	"feeinfoblock-height": "Height of the block",
+	"feeinfoblock-number": "Number of transactions in the block",
+	"feeinfoblock-min":    "Minimum transaction fee in the block",
+	"feeinfoblock-max":    "Maximum transaction fee in the block",
+	"feeinfoblock-mean":   "Mean of transaction fees in the block",
+	"feeinfoblock-median": "Median of transaction fees in the block",
+	"feeinfoblock-stddev": "Standard deviation of transaction fees in the block",
+
+	"feeinfowindow-startheight": "First block in the window (inclusive)",
+	"feeinfowindow-endheight":   "Last block in the window (exclusive)",
+	"feeinfowindow-number":      "Number of transactions in the window",
+	"feeinfowindow-min":         "Minimum transaction fee in the window",
+	"feeinfowindow-max":         "Maximum transaction fee in the window",
+	"feeinfowindow-mean":        "Mean of transaction fees in the window",
+	"feeinfowindow-median":      "Median of transaction fees in the window",
+	"feeinfowindow-stddev":      "Standard deviation of transaction fees in the window",
+
+	// TicketsForAddress help.
+	"ticketsforaddress--synopsis":     "Request all the tickets for an address.",
+	"ticketsforaddress-address":       "Address to look for.",
+	"ticketsforaddressresult-tickets": "Tickets owned by the specified address.",
+
+	// TicketsForBucket help.
+	"ticketsforbucket--synopsis":     "Request all the tickets and owners in a given bucket.",
+	"ticketsforbucket-bucket":        "Bucket to look for.",
+	"ticketsforbucketresult-tickets": "Result for the ticketsfor bucket command.",
+	"ticket-owner":                   "Address owning the ticket.",
+	"ticket-hash":                    "Hash of the ticket.",
+
+	// TicketVWAP help.
+	"ticketvwap--synopsis": "Calculate the volume weighted average price of tickets for a range of blocks (default: full PoS difficulty adjustment depth)",
+	"ticketvwap-start":     "The start height to begin calculating the VWAP from",
+	"ticketvwap-end":       "The end height to begin calculating the VWAP from",
+	"ticketvwap--result0":  "The volume weighted average price",
+
+	// TxFeeInfo help.
+	"txfeeinfo--synopsis":            "Get various information about regular transaction fees from the mempool, blocks, and difficulty windows",
+	"txfeeinfo-blocks":               "The number of blocks to calculate transaction fees for, starting from the end of the tip moving backwards",
+	"txfeeinfo-rangestart":           "The start height of the block range to calculate transaction fees for",
+	"txfeeinfo-rangeend":             "The end height of the block range to calculate transaction fees for",
+	"txfeeinforesult-feeinfomempool": "Transaction fee information for all regular transactions in the mempool",
+	"txfeeinforesult-feeinfoblocks":  "Transaction fee information for a given list of blocks descending from the chain tip",
+	"txfeeinforesult-feeinforange":   "Transaction fee information for a window period where the stake difficulty was the same",
+
+	"feeinforange-number": "Number of transactions in the window",
+	"feeinforange-min":    "Minimum transaction fee in the window",
+	"feeinforange-max":    "Maximum transaction fee in the window",
+	"feeinforange-mean":   "Mean of transaction fees in the window",
+	"feeinforange-median": "Median of transaction fees in the window",
+	"feeinforange-stddev": "Standard deviation of transaction fees in the window",
+
+	// Version help
+	"version--synopsis":       "Returns the JSON-RPC API version (semver)",
+	"version--result0--desc":  "Version objects keyed by the program or API name",
+	"version--result0--key":   "Program or API name",
+	"version--result0--value": "Object containing the semantic version",
+}
+
+// rpcResultTypes specifies the result types that each RPC command can return.
+// This information is used to generate the help.  Each result type must be a
+// pointer to the type (or nil to indicate no return value).
+var rpcResultTypes = map[string][]interface{}{
+	"addnode":                     nil,
+	"combinepsht":                 {(*string)(nil)},
+	"createmultisig":              {(*hcjson.CreateMultiSigResult)(nil)},
+	"createrawscript":             {(*hcjson.CreateRawScriptResult)(nil)},
+	"createrawsstx":               {(*string)(nil)},
+	"createrawssgentx":            {(*string)(nil)},
+	"createrawssrtx":              {(*string)(nil)},
+	"createrawtransaction":        {(*string)(nil)},
+	"debuglevel":                  {(*string)(nil), (*string)(nil)},
+	"dumpblockchain":              {(*hcjson.DumpBlockChainResult)(nil)},
+	"decodepsht":                  {(*hcjson.DecodePshtResult)(nil)},
+	"decoderawtransaction":        {(*hcjson.TxRawDecodeResult)(nil)},
+	"decodescript":                {(*hcjson.DecodeScriptResult)(nil)},
+	"describerpc":                 {(*[]hcjson.DescribeRPCResult)(nil)},
+	"disconnectnode":              nil,
+	"estimatefee":                 {(*float64)(nil)},
+	"estimatestakediff":           {(*hcjson.EstimateStakeDiffResult)(nil)},
+	"existsaddress":               {(*bool)(nil)},
+	"existsaddresses":             {(*string)(nil)},
+	"existsmissedtickets":         {(*string)(nil)},
+	"existsexpiredtickets":        {(*string)(nil)},
+	"existsliveticket":            {(*bool)(nil)},
+	"existslivetickets":           {(*string)(nil)},
+	"existsmempooltxs":            {(*string)(nil)},
+	"existstxs":                   {(*hcjson.ExistsTxsResult)(nil)},
+	"finalizepsht":                {(*hcjson.FinalizePshtResult)(nil)},
+	"getaddednodeinfo":            {(*[]string)(nil), (*[]hcjson.GetAddedNodeInfoResult)(nil)},
+	"getbestblock":                {(*hcjson.GetBestBlockResult)(nil)},
+	"generate":                    {(*[]string)(nil)},
+	"getbestblockhash":            {(*string)(nil)},
+	"getblock":                    {(*string)(nil), (*hcjson.GetBlockVerboseResult)(nil)},
+	"getblockcount":               {(*int64)(nil)},
+	"getblockhash":                {(*string)(nil)},
+	"getblockheader":              {(*string)(nil), (*hcjson.GetBlockHeaderVerboseResult)(nil)},
+	"getblocksubsidy":             {(*hcjson.GetBlockSubsidyResult)(nil)},
+	"getblockundo":                {(*hcjson.GetBlockUndoResult)(nil)},
+	"getblocktemplate":            {(*hcjson.GetBlockTemplateResult)(nil), (*string)(nil), nil},
+	"getconnectioncount":          {(*int32)(nil)},
+	"getcurrentnet":               {(*uint32)(nil)},
+	"getdifficulty":               {(*float64)(nil)},
+	"getrevocabletickets":         {(*hcjson.GetRevocableTicketsResult)(nil)},
+	"getstakedifficulty":          {(*hcjson.GetStakeDifficultyResult)(nil)},
+	"getstakeversioninfo":         {(*hcjson.GetStakeVersionInfoResult)(nil)},
+	"getblockchaininfo":           {(*hcjson.GetBlockChainInfoResult)(nil)},
+	"getstakeversions":            {(*hcjson.GetStakeVersionsResult)(nil)},
+	"getgenerate":                 {(*bool)(nil)},
+	"gethashespersec":             {(*float64)(nil)},
+	"gethealth":                   {(*hcjson.GetHealthResult)(nil)},
+	"getlockstats":                {(*[]hcjson.GetLockStatsResult)(nil)},
+	"getmemoryinfo":               {(*hcjson.GetMemoryInfoResult)(nil)},
+	"getprofile":                  {(*hcjson.GetProfileResult)(nil)},
+	"getslowqueries":              {(*[]hcjson.GetSlowQueriesResult)(nil)},
+	"getheaders":                  {(*hcjson.GetHeadersResult)(nil)},
+	"getindexinfo":                {(*map[string]hcjson.IndexInfoResult)(nil)},
+	"getinfo":                     {(*hcjson.InfoChainResult)(nil)},
+	"getmempoolentry":             {(*hcjson.GetMempoolEntryResult)(nil)},
+	"getmempoolinfo":              {(*hcjson.GetMempoolInfoResult)(nil)},
+	"getmininginfo":               {(*hcjson.GetMiningInfoResult)(nil)},
+	"getnettotals":                {(*hcjson.GetNetTotalsResult)(nil)},
+	"getnetworkhashps":            {(*int64)(nil)},
+	"getnetworkupgradeinfo":       {(*hcjson.GetNetworkUpgradeInfoResult)(nil)},
+	"getpeerinfo":                 {(*[]hcjson.GetPeerInfoResult)(nil)},
+	"getrawmempool":               {(*[]string)(nil), (*hcjson.GetRawMempoolVerboseResult)(nil)},
+	"getrawtransaction":           {(*string)(nil), (*hcjson.TxRawResult)(nil)},
+	"getticketpoolvalue":          {(*float64)(nil)},
+	"gettxout":                    {(*hcjson.GetTxOutResult)(nil)},
+	"getutxoset":                  {(*hcjson.GetUtxoSetResult)(nil)},
+	"getvoteinfo":                 {(*hcjson.GetVoteInfoResult)(nil)},
+	"getwork":                     {(*hcjson.GetWorkResult)(nil), (*bool)(nil)},
+	"getcoinsupply":               {(*int64)(nil), (*hcjson.GetCoinSupplyResult)(nil)},
+	"getcheckpointcandidates":     {(*[]hcjson.CheckpointCandidateResult)(nil)},
+	"getblockheaders":             {(*hcjson.GetBlockHeadersResult)(nil)},
+	"getblockreceivedtime":        {(*hcjson.GetBlockReceivedTimeResult)(nil)},
+	"getchainstats":               {(*hcjson.GetChainStatsResult)(nil)},
+	"getchaintips":                {(*[]hcjson.GetChainTipsResult)(nil)},
+	"help":                        {(*string)(nil), (*string)(nil)},
+	"livetickets":                 {(*hcjson.LiveTicketsResult)(nil)},
+	"missedtickets":               {(*hcjson.MissedTicketsResult)(nil)},
+	"node":                        nil,
+	"ping":                        nil,
+	"prioritisetransaction":       {(*bool)(nil)},
+	"reconsiderblock":             nil,
+	"rebroadcastmissed":           nil,
+	"rebroadcastwinners":          nil,
+	"reloadconfig":                nil,
+	"searchrawtransactions":       {(*string)(nil), (*[]hcjson.SearchRawTransactionsResult)(nil)},
+	"sendrawtransaction":          {(*string)(nil)},
+	"setgenerate":                 nil,
+	"setmaxpeers":                 nil,
+	"startindexrebuild":           nil,
+	"settxrebroadcast":            {(*hcjson.SetTxRebroadcastResult)(nil)},
+	"stop":                        {(*string)(nil)},
+	"submitblock":                 {nil, (*string)(nil)},
+	"submitrawtransactionpackage": {(*hcjson.SubmitRawTransactionPackageResult)(nil)},
+	"tracescript":                 {(*hcjson.TraceScriptResult)(nil)},
+	"ticketfeeinfo":               {(*hcjson.TicketFeeInfoResult)(nil)},
+	"ticketsforaddress":           {(*hcjson.TicketsForAddressResult)(nil)},
+	"ticketvwap":                  {(*float64)(nil)},
+	"txfeeinfo":                   {(*hcjson.TxFeeInfoResult)(nil)},
+	"validateaddress":             {(*hcjson.ValidateAddressChainResult)(nil)},
+	"verifychain":                 {(*bool)(nil)},
+	"verifyheaderchain":           {(*hcjson.VerifyHeaderChainResult)(nil)},
+	"verifymessage":               {(*bool)(nil)},
+	"verifyblissmessage":          {(*bool)(nil)},
+	"version":                     {(*map[string]hcjson.VersionResult)(nil)},
+
+	// Websocket commands.
+	"loadtxfilter":                nil,
+	"session":                     {(*hcjson.SessionResult)(nil)},
+	"notifywinningtickets":        nil,
+	"notifyspentandmissedtickets": nil,
+	"notifynewtickets":            nil,
+	"notifyrevocabletickets":      nil,
+	"notifystakedifficulty":       nil,
+	"notifyblocks":                nil,
+	"notifynewtransactions":       nil,
+	"notifyreceived":              nil,
+	"notifyspent":                 nil,
+	"rescan":                      nil,
+	"stopnotifyblocks":            nil,
+	"stopnotifynewtransactions":   nil,
+	"stopnotifyreceived":          nil,
+	"stopnotifyspent":             nil,
+}
+
+// helpCacher provides a concurrent safe type that provides help and usage for
+// the RPC server commands and caches the results for future calls.
+type helpCacher struct {
+	sync.Mutex
+	usage      string
+	methodHelp map[string]string
+	schema     []byte
+}
+
+// rpcMethodHelp returns an RPC help string for the provided method.
+//
+// This function is safe for concurrent access.
+func (c *helpCacher) rpcMethodHelp(method string) (string, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	// Return the cached method help if it exists.
+	if help, exists := c.methodHelp[method]; exists {
+		return help, nil
+	}
+
+	// Look up the result types for the method.
+	resultTypes, ok := rpcResultTypes[method]
+	if !ok {
+		return "", errors.New("no result types specified for method " +
+			method)
+	}
+
+	// Generate, cache, and return the help.
+	help, err := hcjson.GenerateHelp(method, helpDescsEnUS, resultTypes...)
+	if err != nil {
+		return "", err
+	}
+	c.methodHelp[method] = help
+	return help, nil
+}
+
+// rpcUsage returns one-line usage for all support RPC commands.
+//
+// This function is safe for concurrent access.
+func (c *helpCacher) rpcUsage(includeWebsockets bool) (string, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	// Return the cached usage if it is available.
+	if c.usage != "" {
+		return c.usage, nil
+	}
+
+	// Generate a list of one-line usage for every command.
+	usageTexts := make([]string, 0, len(rpcHandlers))
+	for k := range rpcHandlers {
+		if _, ok := rpcHidden[k]; ok {
+			continue
+		}
+		usage, err := hcjson.MethodUsageText(k)
+		if err != nil {
+			return "", err
+		}
+		usageTexts = append(usageTexts, usage)
+	}
+
+	// Include websockets commands if requested.
+	if includeWebsockets {
+		for k := range wsHandlers {
+			usage, err := hcjson.MethodUsageText(k)
+			if err != nil {
+				return "", err
+			}
+			usageTexts = append(usageTexts, usage)
+		}
+	}
+
+	sort.Sort(sort.StringSlice(usageTexts))
+	c.usage = strings.Join(usageTexts, "\n")
+	return c.usage, nil
+}
+
+// rpcSchema returns the JSON encoded machine-readable description of every
+// registered command along with the result types of the commands supported by
+// the RPC server.
+//
+// This function is safe for concurrent access.
+func (c *helpCacher) rpcSchema() ([]byte, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	// Return the cached schema if it is available.
+	if c.schema != nil {
+		return c.schema, nil
+	}
+
+	schema, err := hcjson.GenerateSchema(helpDescsEnUS, rpcResultTypes)
+	if err != nil {
+		return nil, err
+	}
+	c.schema, err = json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+	return c.schema, nil
+}
+
+// newHelpCacher returns a new instance of a help cacher which provides help and
+// usage for the RPC server commands and caches the results for future calls.
+func newHelpCacher() *helpCacher {
+	return &helpCacher{
+		methodHelp: make(map[string]string),
+	}
+}
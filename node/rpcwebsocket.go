@@ -4,7 +4,7 @@
 // Use of this source code is governed by an ISC
 // license that can be found in the LICENSE file.
 
-package main
+package node
 
 import (
 	"bytes"
@@ -70,6 +70,7 @@ var wsHandlersBeforeInit = map[string]wsCommandHandler{
 	"notifywinningtickets":        handleWinningTickets,
 	"notifyspentandmissedtickets": handleSpentAndMissedTickets,
 	"notifynewtickets":            handleNewTickets,
+	"notifyrevocabletickets":      handleRevocableTickets,
 	"notifystakedifficulty":       handleStakeDifficulty,
 	"notifynewtransactions":       handleNotifyNewTransactions,
 	"session":                     handleSession,
@@ -85,7 +86,7 @@ var wsHandlersBeforeInit = map[string]wsCommandHandler{
 // server handler which runs each new connection in a new goroutine thereby
 // satisfying the requirement.
 func (s *rpcServer) WebsocketHandler(conn *websocket.Conn, remoteAddr string,
-	authenticated bool, isAdmin bool) {
+	authenticated bool, isAdmin bool, public bool) {
 
 	// Clear the read deadline that was set before the websocket hijacked
 	// the connection.
@@ -104,7 +105,8 @@ func (s *rpcServer) WebsocketHandler(conn *websocket.Conn, remoteAddr string,
 	// Create a new websocket client to handle the new websocket connection
 	// and wait for it to shutdown.  Once it has shutdown (and hence
 	// disconnected), remove it and any notifications it registered for.
-	client, err := newWebsocketClient(s, conn, remoteAddr, authenticated, isAdmin)
+	client, err := newWebsocketClient(s, conn, remoteAddr, authenticated,
+		isAdmin, public)
 	if err != nil {
 		rpcsLog.Errorf("Failed to serve client %s: %v", remoteAddr, err)
 		conn.Close()
@@ -269,6 +271,21 @@ func (m *wsNotificationManager) NotifySpentAndMissedTickets(
 	}
 }
 
+// NotifyRevocableTickets passes the tickets which became revocable in an
+// incoming block from the best chain to the notification manager for block
+// notification processing.
+func (m *wsNotificationManager) NotifyRevocableTickets(
+	rtnd *blockchain.RevocableTicketsNtfnsData) {
+	// As NotifyRevocableTickets will be called by the block manager
+	// and the RPC server may no longer be running, use a select
+	// statement to unblock enqueuing the notification once the RPC
+	// server has begun shutting down.
+	select {
+	case m.queueNotification <- (*notificationRevocableTickets)(rtnd):
+	case <-m.quit:
+	}
+}
+
 // NotifyNewTickets passes a new ticket data for an incoming block from the best
 // chain to the notification manager for block notification processing.
 func (m *wsNotificationManager) NotifyNewTickets(
@@ -317,6 +334,22 @@ func (m *wsNotificationManager) NotifyMempoolTx(tx *hcutil.Tx, isNew bool) {
 	}
 }
 
+// NotifyTxExpired passes a transaction which was evicted from the mempool
+// after exceeding the maximum allowed age to the notification manager for
+// transaction notification processing.
+func (m *wsNotificationManager) NotifyTxExpired(tx *hcutil.Tx) {
+	n := (*notificationTxExpiredFromMempool)(tx)
+
+	// As NotifyTxExpired will be called by the server and the RPC server
+	// may no longer be running, use a select statement to unblock
+	// enqueuing the notification once the RPC server has begun
+	// shutting down.
+	select {
+	case m.queueNotification <- n:
+	case <-m.quit:
+	}
+}
+
 // WinningTicketsNtfnData is the data that is used to generate
 // winning ticket notifications (which indicate a block and
 // the tickets eligible to vote on it).
@@ -500,11 +533,14 @@ type notificationReorganization blockchain.ReorganizationNtfnsData
 type notificationWinningTickets WinningTicketsNtfnData
 type notificationSpentAndMissedTickets blockchain.TicketNotificationsData
 type notificationNewTickets blockchain.TicketNotificationsData
+type notificationRevocableTickets blockchain.RevocableTicketsNtfnsData
 type notificationStakeDifficulty StakeDifficultyNtfnData
 type notificationTxAcceptedByMempool struct {
 	isNew bool
 	tx    *hcutil.Tx
 }
+type notificationTxExpiredFromMempool hcutil.Tx
+type notificationCertRotated rpcCert
 
 // Notification control requests
 type notificationRegisterClient wsClient
@@ -517,6 +553,8 @@ type notificationRegisterSpentAndMissedTickets wsClient
 type notificationUnregisterSpentAndMissedTickets wsClient
 type notificationRegisterNewTickets wsClient
 type notificationUnregisterNewTickets wsClient
+type notificationRegisterRevocableTickets wsClient
+type notificationUnregisterRevocableTickets wsClient
 type notificationRegisterStakeDifficulty wsClient
 type notificationUnregisterStakeDifficulty wsClient
 type notificationRegisterNewMempoolTxs wsClient
@@ -524,6 +562,20 @@ type notificationUnregisterNewMempoolTxs wsClient
 
 // notificationHandler reads notifications and control messages from the queue
 // handler and processes one at a time.
+// NotifyCertRotated passes an RPC certificate which was rotated to the
+// notification manager for notifying the websocket clients using it.
+func (m *wsNotificationManager) NotifyCertRotated(c *rpcCert) {
+	n := (*notificationCertRotated)(c)
+
+	// As NotifyCertRotated may be called while the RPC server is shutting
+	// down, use a select statement to unblock enqueuing the notification
+	// once the RPC server has begun shutting down.
+	select {
+	case m.queueNotification <- n:
+	case <-m.quit:
+	}
+}
+
 func (m *wsNotificationManager) notificationHandler() {
 	// clients is a map of all currently connected websocket clients.
 	clients := make(map[chan struct{}]*wsClient)
@@ -539,6 +591,7 @@ func (m *wsNotificationManager) notificationHandler() {
 	winningTicketNotifications := make(map[chan struct{}]*wsClient)
 	ticketSMNotifications := make(map[chan struct{}]*wsClient)
 	ticketNewNotifications := make(map[chan struct{}]*wsClient)
+	ticketRevocableNotifications := make(map[chan struct{}]*wsClient)
 	stakeDifficultyNotifications := make(map[chan struct{}]*wsClient)
 	txNotifications := make(map[chan struct{}]*wsClient)
 
@@ -582,6 +635,10 @@ out:
 				m.notifyNewTickets(ticketNewNotifications,
 					(*blockchain.TicketNotificationsData)(n))
 
+			case *notificationRevocableTickets:
+				m.notifyRevocableTickets(ticketRevocableNotifications,
+					(*blockchain.RevocableTicketsNtfnsData)(n))
+
 			case *notificationStakeDifficulty:
 				m.notifyStakeDifficulty(stakeDifficultyNotifications,
 					(*StakeDifficultyNtfnData)(n))
@@ -592,6 +649,15 @@ out:
 				}
 				m.notifyRelevantTxAccepted(n.tx, clients)
 
+			case *notificationTxExpiredFromMempool:
+				if len(txNotifications) != 0 {
+					m.notifyTxExpired(txNotifications,
+						(*hcutil.Tx)(n))
+				}
+
+			case *notificationCertRotated:
+				m.notifyCertRotated(clients, (*rpcCert)(n))
+
 			case *notificationRegisterBlocks:
 				wsc := (*wsClient)(n)
 				blockNotifications[wsc.quit] = wsc
@@ -624,6 +690,14 @@ out:
 				wsc := (*wsClient)(n)
 				delete(ticketNewNotifications, wsc.quit)
 
+			case *notificationRegisterRevocableTickets:
+				wsc := (*wsClient)(n)
+				ticketRevocableNotifications[wsc.quit] = wsc
+
+			case *notificationUnregisterRevocableTickets:
+				wsc := (*wsClient)(n)
+				delete(ticketRevocableNotifications, wsc.quit)
+
 			case *notificationRegisterStakeDifficulty:
 				wsc := (*wsClient)(n)
 				stakeDifficultyNotifications[wsc.quit] = wsc
@@ -642,6 +716,7 @@ out:
 				// the client itself.
 				delete(blockNotifications, wsc.quit)
 				delete(txNotifications, wsc.quit)
+				delete(ticketRevocableNotifications, wsc.quit)
 				delete(clients, wsc.quit)
 
 			case *notificationRegisterNewMempoolTxs:
@@ -963,6 +1038,18 @@ func (m *wsNotificationManager) UnregisterNewTickets(wsc *wsClient) {
 	m.queueNotification <- (*notificationUnregisterNewTickets)(wsc)
 }
 
+// RegisterRevocableTickets requests revocable ticket notifications to the
+// passed websocket client.
+func (m *wsNotificationManager) RegisterRevocableTickets(wsc *wsClient) {
+	m.queueNotification <- (*notificationRegisterRevocableTickets)(wsc)
+}
+
+// UnregisterRevocableTickets removes revocable ticket notifications for the
+// passed websocket client.
+func (m *wsNotificationManager) UnregisterRevocableTickets(wsc *wsClient) {
+	m.queueNotification <- (*notificationUnregisterRevocableTickets)(wsc)
+}
+
 // RegisterStakeDifficulty requests stake difficulty notifications
 // to the passed websocket client.
 func (m *wsNotificationManager) RegisterStakeDifficulty(wsc *wsClient) {
@@ -1001,6 +1088,42 @@ func (*wsNotificationManager) notifyNewTickets(clients map[chan struct{}]*wsClie
 	}
 }
 
+// notifyRevocableTickets notifies websocket clients that have registered for
+// revocable ticket updates.  Nothing is sent for blocks which did not make any
+// ticket revocable.
+func (*wsNotificationManager) notifyRevocableTickets(
+	clients map[chan struct{}]*wsClient,
+	rtnd *blockchain.RevocableTicketsNtfnsData) {
+
+	if len(clients) == 0 ||
+		(len(rtnd.TicketsMissed) == 0 && len(rtnd.TicketsExpired) == 0) {
+		return
+	}
+
+	missed := make([]string, 0, len(rtnd.TicketsMissed))
+	for _, h := range rtnd.TicketsMissed {
+		missed = append(missed, h.String())
+	}
+	expired := make([]string, 0, len(rtnd.TicketsExpired))
+	for _, h := range rtnd.TicketsExpired {
+		expired = append(expired, h.String())
+	}
+
+	// Notify interested websocket clients about the connected block.
+	ntfn := hcjson.NewRevocableTicketsNtfn(rtnd.Hash.String(),
+		int32(rtnd.Height), missed, expired)
+
+	marshalledJSON, err := hcjson.MarshalCmd(nil, ntfn)
+	if err != nil {
+		rpcsLog.Errorf("Failed to marshal revocable tickets "+
+			"notification: %v", err)
+		return
+	}
+	for _, wsc := range clients {
+		wsc.QueueNotification(marshalledJSON)
+	}
+}
+
 // notifyStakeDifficulty notifies websocket clients that have registered for
 // maturing ticket updates.
 func (*wsNotificationManager) notifyStakeDifficulty(
@@ -1086,6 +1209,42 @@ func (m *wsNotificationManager) notifyForNewTx(clients map[chan struct{}]*wsClie
 	}
 }
 
+// notifyTxExpired notifies websocket clients that have registered for updates
+// when a transaction is evicted from the memory pool due to its age.
+func (m *wsNotificationManager) notifyTxExpired(clients map[chan struct{}]*wsClient, tx *hcutil.Tx) {
+	ntfn := hcjson.NewTxExpiredNtfn(tx.Hash().String())
+	marshalledJSON, err := hcjson.MarshalCmd(nil, ntfn)
+	if err != nil {
+		rpcsLog.Errorf("Failed to marshal tx expired notification: %s",
+			err.Error())
+		return
+	}
+	for _, wsc := range clients {
+		wsc.QueueNotification(marshalledJSON)
+	}
+}
+
+// notifyCertRotated notifies the websocket clients connected to the listeners
+// using the passed certificate that it was rotated.  The notification carries
+// the new certificate so clients which pin it can trust it before
+// reconnecting.
+func (m *wsNotificationManager) notifyCertRotated(clients map[chan struct{}]*wsClient, c *rpcCert) {
+	certPEM, notAfter := c.pem()
+	ntfn := hcjson.NewCertRotatedNtfn(string(certPEM), notAfter.Unix())
+	marshalledJSON, err := hcjson.MarshalCmd(nil, ntfn)
+	if err != nil {
+		rpcsLog.Errorf("Failed to marshal cert rotated notification: %s",
+			err.Error())
+		return
+	}
+	for _, wsc := range clients {
+		if m.server.listenerCert(wsc.public) != c {
+			continue
+		}
+		wsc.QueueNotification(marshalledJSON)
+	}
+}
+
 // txHexString returns the serialized transaction encoded in hexadecimal.
 func txHexString(tx *wire.MsgTx) string {
 	buf := bytes.NewBuffer(make([]byte, 0, tx.SerializeSize()))
@@ -1256,6 +1415,11 @@ type wsClient struct {
 	// false means its access is only to the limited set of RPC calls.
 	isAdmin bool
 
+	// public specifies whether the client connected to a public listener,
+	// which limits it to the limited set of RPC calls regardless of its
+	// credentials.
+	public bool
+
 	// sessionID is a random ID generated for each client when connected.
 	// These IDs may be queried by a client using the session RPC.  A change
 	// to the session ID indicates that the client reconnected.
@@ -1366,14 +1530,17 @@ out:
 			login := authCmd.Username + ":" + authCmd.Passphrase
 			auth := "Basic " + base64.StdEncoding.EncodeToString([]byte(login))
 			authSha := sha256.Sum256([]byte(auth))
-			cmp := subtle.ConstantTimeCompare(authSha[:], c.server.authsha[:])
-			limitcmp := subtle.ConstantTimeCompare(authSha[:], c.server.limitauthsha[:])
+			adminSha, limitSha := c.server.authHashes()
+			cmp := subtle.ConstantTimeCompare(authSha[:], adminSha[:]) |
+				subtle.ConstantTimeCompare(authSha[:],
+					c.server.cookieauthsha[:])
+			limitcmp := subtle.ConstantTimeCompare(authSha[:], limitSha[:])
 			if cmp != 1 && limitcmp != 1 {
 				rpcsLog.Warnf("Auth failure.")
 				break out
 			}
 			c.authenticated = true
-			c.isAdmin = cmp == 1
+			c.isAdmin = cmp == 1 && !c.public
 
 			// Marshal and send response.
 			reply, err := createMarshalledReply(cmd.id, nil, nil)
@@ -1670,7 +1837,7 @@ func (c *wsClient) WaitForShutdown() {
 // incoming and outgoing messages in separate goroutines complete with queuing
 // and asynchrous handling for long-running operations.
 func newWebsocketClient(server *rpcServer, conn *websocket.Conn,
-	remoteAddr string, authenticated bool, isAdmin bool) (*wsClient, error) {
+	remoteAddr string, authenticated bool, isAdmin bool, public bool) (*wsClient, error) {
 
 	sessionID, err := wire.RandomUint64()
 	if err != nil {
@@ -1682,6 +1849,7 @@ func newWebsocketClient(server *rpcServer, conn *websocket.Conn,
 		addr:              remoteAddr,
 		authenticated:     authenticated,
 		isAdmin:           isAdmin,
+		public:            public,
 		sessionID:         sessionID,
 		server:            server,
 		serviceRequestSem: makeSemaphore(cfg.RPCMaxConcurrentReqs),
@@ -1826,6 +1994,14 @@ func handleNewTickets(wsc *wsClient, icmd interface{}) (interface{},
 	return nil, nil
 }
 
+// handleRevocableTickets implements the notifyrevocabletickets command
+// extension for websocket connections.
+func handleRevocableTickets(wsc *wsClient, icmd interface{}) (interface{},
+	error) {
+	wsc.server.ntfnMgr.RegisterRevocableTickets(wsc)
+	return nil, nil
+}
+
 // handleStakeDifficulty implements the notifystakedifficulty command extension
 // for websocket connections.
 func handleStakeDifficulty(wsc *wsClient, icmd interface{}) (interface{},
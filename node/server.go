@@ -4,7 +4,7 @@
 // Use of this source code is governed by an ISC
 // license that can be found in the LICENSE file.
 
-package main
+package node
 
 import (
 	"bytes"
@@ -16,6 +16,7 @@ import (
 	"net"
 	"regexp"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -25,6 +26,7 @@ import (
 	"github.com/HcashOrg/hcd/addrmgr"
 	"github.com/HcashOrg/hcd/blockchain"
 	"github.com/HcashOrg/hcd/blockchain/indexers"
+	"github.com/HcashOrg/hcd/blockchain/stake"
 	"github.com/HcashOrg/hcd/chaincfg"
 	"github.com/HcashOrg/hcd/chaincfg/chainhash"
 	"github.com/HcashOrg/hcd/connmgr"
@@ -34,6 +36,8 @@ import (
 	"github.com/HcashOrg/hcd/mempool"
 	"github.com/HcashOrg/hcd/mining"
 	"github.com/HcashOrg/hcd/peer"
+	"github.com/HcashOrg/hcd/pubsub"
+	"github.com/HcashOrg/hcd/tracing"
 	"github.com/HcashOrg/hcd/txscript"
 	"github.com/HcashOrg/hcd/wire"
 )
@@ -51,13 +55,41 @@ const (
 	// target.
 	defaultTargetOutbound = 8
 
+	// maxAnchorPeers is the maximum number of outbound peers which are
+	// saved on shutdown and connected to first at the next start.
+	maxAnchorPeers = 2
+
+	// maxOutboundPerNetGroup is the maximum number of automatic outbound
+	// peers in the same /16 (IPv4) or /32 (IPv6) network group.  Addresses
+	// in network groups which already have an outbound peer are skipped
+	// when selecting new addresses, but concurrent connection attempts can
+	// still end up in the same group, so the connection manager enforces
+	// this hard limit.
+	maxOutboundPerNetGroup = 2
+
 	// connectionRetryInterval is the base amount of time to wait in between
 	// retries when connecting to persistent peers.  It is adjusted by the
 	// number of retries such that there is a retry backoff.
 	connectionRetryInterval = time.Second * 5
 
+	// mempoolExpiryScanInterval is the amount of time to wait in between
+	// scans of the memory pool for transactions which have exceeded the
+	// maximum allowed age.
+	mempoolExpiryScanInterval = time.Minute * 5
+
+	// indexCatchUpCheckInterval is the amount of time to wait in between
+	// checks of whether or not the chain is synced enough to catch up the
+	// optional indexes which are behind it.
+	indexCatchUpCheckInterval = time.Second * 5
+
+	// indexCatchUpStallTimeout is the amount of time the best block may
+	// remain unchanged before the optional indexes which are behind the
+	// chain are caught up even though the chain does not believe it is
+	// current, such as when there are no peers to sync from.
+	indexCatchUpStallTimeout = time.Minute
+
 	// maxProtocolVersion is the max protocol version the server supports.
-	maxProtocolVersion = wire.MaxBlockSizeVersion
+	maxProtocolVersion = wire.FeatureNegotiationVersion
 )
 
 var (
@@ -112,6 +144,10 @@ type peerState struct {
 	persistentPeers map[int32]*serverPeer
 	banned          map[string]time.Time
 	outboundGroups  map[string]int
+
+	// maxPeers is the maximum number of inbound and outbound peers.  It
+	// starts out as the maxpeers option and may be changed at runtime.
+	maxPeers int
 }
 
 // Count returns the count of all known peers.
@@ -140,6 +176,28 @@ func (ps *peerState) forAllPeers(closure func(sp *serverPeer)) {
 	ps.forAllOutboundPeers(closure)
 }
 
+// anchorAddresses returns the addresses of up to maxAnchorPeers outbound peers
+// which are not persistent, preferring the ones connected the longest.
+func (ps *peerState) anchorAddresses() []*wire.NetAddress {
+	peers := make([]*serverPeer, 0, len(ps.outboundPeers))
+	for _, sp := range ps.outboundPeers {
+		if sp.VerAckReceived() && sp.NA() != nil {
+			peers = append(peers, sp)
+		}
+	}
+	sort.Slice(peers, func(i, j int) bool {
+		return peers[i].TimeConnected().Before(peers[j].TimeConnected())
+	})
+	if len(peers) > maxAnchorPeers {
+		peers = peers[:maxAnchorPeers]
+	}
+	addrs := make([]*wire.NetAddress, 0, len(peers))
+	for _, sp := range peers {
+		addrs = append(addrs, sp.NA())
+	}
+	return addrs
+}
+
 // server provides a hcd server for handling communications to and from
 // hcd peers.
 type server struct {
@@ -150,11 +208,13 @@ type server struct {
 	started       int32
 	shutdown      int32
 	shutdownSched int32
+	shutdownStep  atomic.Value // string
 
 	chainParams          *chaincfg.Params
 	addrManager          *addrmgr.AddrManager
 	connManager          *connmgr.ConnManager
 	sigCache             *txscript.SigCache
+	scriptCache          *txscript.ScriptCache
 	rpcServer            *rpcServer
 	blockManager         *blockManager
 	txMemPool            *mempool.TxPool
@@ -171,9 +231,37 @@ type server struct {
 	quit                 chan struct{}
 	nat                  NAT
 	db                   database.DB
+	dbLatency            *latencyTracker
+	slowQueries          *slowQueryLog
 	timeSource           blockchain.MedianTimeSource
 	services             wire.ServiceFlag
 
+	// netGroupKey is a secret key used to randomize the network groups of
+	// inbound peers which are protected from eviction.
+	netGroupKey [32]byte
+
+	// blockArrivals and txArrivals record when and from where blocks and
+	// transactions were first seen.
+	blockArrivals *arrivalIndex
+	txArrivals    *arrivalIndex
+
+	// chainStats keeps the statistics of the most recent main chain blocks
+	// for the getchainstats RPC.
+	chainStats *chainStats
+
+	// dbVerifier periodically verifies the stored blocks and repairs the
+	// ones which fail to verify.  It is nil when the verification is
+	// disabled.
+	dbVerifier *dbVerifier
+
+	// publisher publishes blocks and transactions to subscribers.  It is
+	// nil when no topics are published.
+	publisher *pubsub.Publisher
+
+	// tracer exports the spans of traced RPC requests.  It is nil when
+	// tracing is disabled, in which case no spans are recorded.
+	tracer *tracing.Tracer
+
 	// The following fields are used for optional indexes.  They will be nil
 	// if the associated index is not enabled.  These fields are set during
 	// initial creation of the server and never changed afterwards, so they
@@ -181,6 +269,7 @@ type server struct {
 	txIndex         *indexers.TxIndex
 	addrIndex       *indexers.AddrIndex
 	existsAddrIndex *indexers.ExistsAddrIndex
+	indexManager    *indexers.Manager
 }
 
 // serverPeer extends the peer to maintain state shared by the server and
@@ -194,13 +283,17 @@ type serverPeer struct {
 	continueHash    *chainhash.Hash
 	relayMtx        sync.Mutex
 	disableRelayTx  bool
+	feeFilter       int64 // min fee rate in atoms/kB, accessed atomically
 	isWhitelisted   bool
+	blockOnly       bool
 	requestQueue    []*wire.InvVect
 	requestedTxns   map[chainhash.Hash]struct{}
-	requestedBlocks map[chainhash.Hash]struct{}
+	requestedBlocks map[chainhash.Hash]time.Time
+	blockStats      peerBlockStats
 	filter          *bloom.Filter
 	knownAddresses  map[string]struct{}
 	banScore        connmgr.DynamicBanScore
+	rateLimiters    *peerRateLimiters
 	quit            chan struct{}
 	// It is used to prevent more than one response per connection.
 	addrsSent bool
@@ -211,6 +304,14 @@ type serverPeer struct {
 	// The following chans are used to sync blockmanager and server.
 	txProcessed    chan struct{}
 	blockProcessed chan struct{}
+
+	// The following fields are used to decide which inbound peer to evict
+	// when the maximum number of peers is reached.  They are protected by
+	// the eviction mutex.
+	evictMtx      sync.Mutex
+	minPingMicros int64
+	lastBlockTime time.Time
+	lastTxTime    time.Time
 }
 // Only respond with addresses once per connection
 //if sp.addrsSent {
@@ -227,15 +328,41 @@ func newServerPeer(s *server, isPersistent bool) *serverPeer {
 		server:          s,
 		persistent:      isPersistent,
 		requestedTxns:   make(map[chainhash.Hash]struct{}),
-		requestedBlocks: make(map[chainhash.Hash]struct{}),
+		requestedBlocks: make(map[chainhash.Hash]time.Time),
 		filter:          bloom.LoadFilter(nil),
 		knownAddresses:  make(map[string]struct{}),
+		rateLimiters:    newPeerRateLimiters(),
 		quit:            make(chan struct{}),
 		txProcessed:     make(chan struct{}, 1),
 		blockProcessed:  make(chan struct{}, 1),
 	}
 }
 
+// evictionStats returns the lowest ping time observed for the peer along with
+// the last times it relayed a block new to the chain and a transaction new to
+// the memory pool.  It is safe for concurrent access.
+func (sp *serverPeer) evictionStats() (int64, time.Time, time.Time) {
+	sp.evictMtx.Lock()
+	defer sp.evictMtx.Unlock()
+	return sp.minPingMicros, sp.lastBlockTime, sp.lastTxTime
+}
+
+// setLastBlockTime records that the peer relayed a block new to the chain.  It
+// is safe for concurrent access.
+func (sp *serverPeer) setLastBlockTime(t time.Time) {
+	sp.evictMtx.Lock()
+	sp.lastBlockTime = t
+	sp.evictMtx.Unlock()
+}
+
+// setLastTxTime records that the peer relayed a transaction new to the memory
+// pool.  It is safe for concurrent access.
+func (sp *serverPeer) setLastTxTime(t time.Time) {
+	sp.evictMtx.Lock()
+	sp.lastTxTime = t
+	sp.evictMtx.Unlock()
+}
+
 // newestBlock returns the current best block hash and height using the format
 // required by the configuration for the peer package.
 func (sp *serverPeer) newestBlock() (*chainhash.Hash, int64, error) {
@@ -257,6 +384,15 @@ func (sp *serverPeer) addressKnown(na *wire.NetAddress) bool {
 	return exists
 }
 
+// addKnownAddressesV2 adds the given addresses in the versioned address format
+// to the set of known addresses to the peer to prevent sending duplicate
+// addresses.
+func (sp *serverPeer) addKnownAddressesV2(addresses []*wire.NetAddressV2) {
+	for _, na := range addresses {
+		sp.knownAddresses[addrmgr.NetAddressV2Key(na)] = struct{}{}
+	}
+}
+
 // setDisableRelayTx toggles relaying of transactions for the given peer.
 // It is safe for concurrent access.
 func (sp *serverPeer) setDisableRelayTx(disable bool) {
@@ -270,12 +406,38 @@ func (sp *serverPeer) setDisableRelayTx(disable bool) {
 // It is safe for concurrent access.
 func (sp *serverPeer) relayTxDisabled() bool {
 	sp.relayMtx.Lock()
-	isDisabled := sp.disableRelayTx
+	isDisabled := sp.disableRelayTx || sp.blockOnly
 	sp.relayMtx.Unlock()
 
 	return isDisabled
 }
 
+// belowFeeFilter returns whether or not the fee rate of the passed mempool
+// transaction is below the minimum fee rate announced by the peer with a
+// feefilter message, in which case the transaction is not announced to it.
+// Only regular transactions are filtered since votes and revocations normally
+// pay no fee and the memory pool exempts stake transactions from the minimum
+// fee, so filtering them could keep them from reaching miners.
+func (sp *serverPeer) belowFeeFilter(txDesc *mempool.TxDesc) bool {
+	if txDesc.Type != stake.TxTypeRegular {
+		return false
+	}
+	feeFilter := atomic.LoadInt64(&sp.feeFilter)
+	if feeFilter <= 0 {
+		return false
+	}
+	txSize := int64(txDesc.Tx.MsgTx().SerializeSize())
+	return txDesc.Fee*1000/txSize < feeFilter
+}
+
+// relayBlocksOnly returns whether or not only blocks are relayed with the
+// peer, either because the node is running in blocks only mode or because the
+// peer is a block-only outbound peer.  Transactions are neither requested from
+// nor accepted from such peers.
+func (sp *serverPeer) relayBlocksOnly() bool {
+	return cfg.BlocksOnly || sp.blockOnly
+}
+
 // pushAddrMsg sends an addr message to the connected peer using the provided
 // addresses.
 func (sp *serverPeer) pushAddrMsg(addresses []*wire.NetAddress) {
@@ -295,6 +457,38 @@ func (sp *serverPeer) pushAddrMsg(addresses []*wire.NetAddress) {
 	sp.addKnownAddresses(known)
 }
 
+// pushAddrV2Msg sends an addrv2 message with the provided addresses to the
+// connected peer when it supports them.  Otherwise, an addr message with the
+// addresses which can be represented in the legacy address format is sent.
+func (sp *serverPeer) pushAddrV2Msg(addresses []*wire.NetAddressV2) {
+	if sp.ProtocolVersion() < wire.AddrV2Version {
+		legacy := make([]*wire.NetAddress, 0, len(addresses))
+		for _, na := range addresses {
+			if l := na.ToLegacy(); l != nil {
+				legacy = append(legacy, l)
+			}
+		}
+		sp.pushAddrMsg(legacy)
+		return
+	}
+
+	// Filter addresses already known to the peer.
+	addrs := make([]*wire.NetAddressV2, 0, len(addresses))
+	for _, addr := range addresses {
+		key := addrmgr.NetAddressV2Key(addr)
+		if _, exists := sp.knownAddresses[key]; !exists {
+			addrs = append(addrs, addr)
+		}
+	}
+	known, err := sp.PushAddrV2Msg(addrs)
+	if err != nil {
+		peerLog.Errorf("Can't push address message to %s: %v", sp.Peer, err)
+		sp.Disconnect()
+		return
+	}
+	sp.addKnownAddressesV2(known)
+}
+
 // addBanScore increases the persistent and decaying ban score fields by the
 // values passed as parameters. If the resulting score exceeds half of the ban
 // threshold, a warning is logged including the reason provided. Further, if
@@ -461,15 +655,52 @@ func (sp *serverPeer) OnVersion(p *peer.Peer, msg *wire.MsgVersion) {
 		}
 	}
 
+	// Ask the peer not to announce transactions the memory pool would
+	// reject for their fee anyway, and request the transactions in its
+	// memory pool from outbound peers so the memory pool of a restarted
+	// node converges without waiting for new transactions to be relayed.
+	// This is skipped during the initial block download since the
+	// transactions can't be validated before the chain is current.
+	if !sp.relayBlocksOnly() && msg.ProtocolVersion >= int32(wire.FeeFilterVersion) {
+		minFee := int64(sp.server.txMemPool.MinRelayTxFee())
+		p.QueueMessage(wire.NewMsgFeeFilter(minFee), nil)
+	}
+	if !sp.relayBlocksOnly() && !cfg.NoMempoolSync && !p.Inbound() &&
+		sp.server.blockManager.IsCurrent() {
+		p.QueueMessage(wire.NewMsgMemPool(), nil)
+	}
+
 	// Add valid peer to the server.
 	sp.server.AddPeer(sp)
 }
 
+// OnPong is invoked when a peer receives a pong wire message.  It records the
+// lowest ping time observed for the peer, which protects fast peers from
+// eviction.
+func (sp *serverPeer) OnPong(p *peer.Peer, msg *wire.MsgPong) {
+	pingMicros := p.LastPingMicros()
+	if pingMicros <= 0 {
+		return
+	}
+	sp.evictMtx.Lock()
+	if sp.minPingMicros == 0 || pingMicros < sp.minPingMicros {
+		sp.minPingMicros = pingMicros
+	}
+	sp.evictMtx.Unlock()
+}
+
 // OnMemPool is invoked when a peer receives a mempool wire message.  It creates
 // and sends an inventory message with the contents of the memory pool up to the
 // maximum inventory allowed per message.  When the peer has a bloom filter
-// loaded, the contents are filtered accordingly.
+// loaded, the contents are filtered accordingly, and transactions with a fee
+// rate below the one announced by the peer with a feefilter message are left
+// out.
 func (sp *serverPeer) OnMemPool(p *peer.Peer, msg *wire.MsgMemPool) {
+	// Ignore mempool requests from peers exceeding the configured rate.
+	if sp.exceedsRateLimit(sp.rateLimiters.memPool, 1, "mempool") {
+		return
+	}
+
 	// A decaying ban score increase is applied to prevent flooding.
 	// The ban score accumulates and passes the ban threshold if a burst of
 	// mempool messages comes from a peer. The score decays each minute to
@@ -485,14 +716,20 @@ func (sp *serverPeer) OnMemPool(p *peer.Peer, msg *wire.MsgMemPool) {
 	txDescs := txMemPool.TxDescs()
 	invMsg := wire.NewMsgInvSizeHint(uint(len(txDescs)))
 
-	for i, txDesc := range txDescs {
+	for _, txDesc := range txDescs {
+		// Don't announce transactions the peer asked not to be told
+		// about.
+		if sp.belowFeeFilter(txDesc) {
+			continue
+		}
+
 		// Either add all transactions when there is no bloom filter,
 		// or only the transactions that match the filter when there is
 		// one.
 		if !sp.filter.IsLoaded() || sp.filter.MatchTxAndUpdate(txDesc.Tx) {
 			iv := wire.NewInvVect(wire.InvTypeTx, txDesc.Tx.Hash())
 			invMsg.AddInvVect(iv)
-			if i+1 >= wire.MaxInvPerMsg {
+			if len(invMsg.InvList) >= wire.MaxInvPerMsg {
 				break
 			}
 		}
@@ -621,8 +858,8 @@ func (sp *serverPeer) OnMiningState(p *peer.Peer, msg *wire.MsgMiningState) {
 // serialize all transactions through a single thread transactions don't rely on
 // the previous one in a linear fashion like blocks.
 func (sp *serverPeer) OnTx(p *peer.Peer, msg *wire.MsgTx) {
-	if cfg.BlocksOnly {
-		peerLog.Tracef("Ignoring tx %v from %v - blocksonly enabled",
+	if sp.relayBlocksOnly() {
+		peerLog.Tracef("Ignoring tx %v from %v - only relaying blocks",
 			msg.TxHash(), p)
 		return
 	}
@@ -633,6 +870,7 @@ func (sp *serverPeer) OnTx(p *peer.Peer, msg *wire.MsgTx) {
 	tx := hcutil.NewTx(msg)
 	iv := wire.NewInvVect(wire.InvTypeTx, tx.Hash())
 	p.AddKnownInventory(iv)
+	sp.server.txArrivals.record(tx.Hash(), p.Addr(), time.Now())
 
 	// Queue the transaction up to be handled by the block manager and
 	// intentionally block further receives until the transaction is fully
@@ -649,11 +887,18 @@ func (sp *serverPeer) OnBlock(p *peer.Peer, msg *wire.MsgBlock, buf []byte) {
 	// Convert the raw MsgBlock to a hcutil.Block which provides some
 	// convenience methods and things such as hash caching.
 	block := hcutil.NewBlockFromBlockAndBytes(msg, buf)
+	sp.server.blockArrivals.record(block.Hash(), p.Addr(), time.Now())
 
 	// Add the block to the known inventory for the peer.
 	iv := wire.NewInvVect(wire.InvTypeBlock, block.Hash())
 	p.AddKnownInventory(iv)
 
+	// Copies of quarantined blocks requested to repair them are not
+	// processed by the block manager since the blocks are already known.
+	if v := sp.server.dbVerifier; v != nil && v.maybeRepair(block, p.Addr()) {
+		return
+	}
+
 	// Queue the block up to be handled by the block manager and
 	// intentionally block further receives until the network block is fully
 	// processed and known good or bad.  This helps prevent a malicious peer
@@ -672,20 +917,21 @@ func (sp *serverPeer) OnBlock(p *peer.Peer, msg *wire.MsgBlock, buf []byte) {
 // accordingly.  We pass the message down to blockmanager which will call
 // QueueMessage with any appropriate responses.
 func (sp *serverPeer) OnInv(p *peer.Peer, msg *wire.MsgInv) {
-	if !cfg.BlocksOnly {
+	if !sp.relayBlocksOnly() {
 		if len(msg.InvList) > 0 {
 			sp.server.blockManager.QueueInv(msg, sp)
 		}
 		return
 	}
 
+	// Ignore the announced transactions since they are never requested
+	// when only relaying blocks.
 	newInv := wire.NewMsgInvSizeHint(uint(len(msg.InvList)))
 	for _, invVect := range msg.InvList {
 		if invVect.Type == wire.InvTypeTx {
-			peerLog.Infof("Peer %v is announcing transactions -- "+
-				"disconnecting", p)
-			p.Disconnect()
-			return
+			peerLog.Tracef("Ignoring tx %v in inventory from %v - "+
+				"only relaying blocks", invVect.Hash, p)
+			continue
 		}
 		err := newInv.AddInvVect(invVect)
 		if err != nil {
@@ -699,6 +945,15 @@ func (sp *serverPeer) OnInv(p *peer.Peer, msg *wire.MsgInv) {
 	}
 }
 
+// OnNotFound is invoked when a peer receives a notfound wire message.  The
+// message is passed down to the block manager, so the blocks and transactions
+// the peer does not have are requested from other peers.
+func (sp *serverPeer) OnNotFound(p *peer.Peer, msg *wire.MsgNotFound) {
+	if len(msg.InvList) > 0 {
+		sp.server.blockManager.QueueNotFound(msg, sp)
+	}
+}
+
 // OnHeaders is invoked when a peer receives a headers wire message.  The
 // message is passed down to the block manager.
 func (sp *serverPeer) OnHeaders(p *peer.Peer, msg *wire.MsgHeaders) {
@@ -713,6 +968,20 @@ func (sp *serverPeer) OnGetData(p *peer.Peer, msg *wire.MsgGetData) {
 		return
 	}
 
+	// Reply with notfound for all of the requested inventory when the peer
+	// requests more than the configured rate allows, so it doesn't wait
+	// for the dropped items until it times out.
+	if sp.exceedsRateLimit(sp.rateLimiters.getData, len(msg.InvList),
+		"getdata") {
+
+		notFound := wire.NewMsgNotFound()
+		for _, iv := range msg.InvList {
+			notFound.AddInvVect(iv)
+		}
+		p.QueueMessage(notFound, nil)
+		return
+	}
+
 	numAdded := 0
 	notFound := wire.NewMsgNotFound()
 
@@ -926,6 +1195,11 @@ func fetchHeaders(db database.DB, blockHashes []chainhash.Hash) ([]*wire.BlockHe
 
 // OnGetHeaders is invoked when a peer receives a getheaders wire message.
 func (sp *serverPeer) OnGetHeaders(p *peer.Peer, msg *wire.MsgGetHeaders) {
+	// Ignore getheaders requests from peers exceeding the configured rate.
+	if sp.exceedsRateLimit(sp.rateLimiters.getHeaders, 1, "getheaders") {
+		return
+	}
+
 	// Ignore getheaders requests if not in sync.
 	if !sp.server.blockManager.IsCurrent() {
 		return
@@ -1032,6 +1306,22 @@ func (sp *serverPeer) OnFilterClear(p *peer.Peer, msg *wire.MsgFilterClear) {
 	sp.filter.Unload()
 }
 
+// OnFeeFilter is invoked when a peer receives a feefilter wire message and it
+// is used by remote peers to request that no transactions which have a fee rate
+// lower than provided value are inventoried to them.  The peer will be
+// disconnected if an invalid fee filter value is provided.
+func (sp *serverPeer) OnFeeFilter(p *peer.Peer, msg *wire.MsgFeeFilter) {
+	// Check that the passed minimum fee is a valid amount.
+	if msg.MinFee < 0 || msg.MinFee > hcutil.MaxAmount {
+		peerLog.Debugf("Peer %v sent an invalid feefilter '%v' -- "+
+			"disconnecting", sp, hcutil.Amount(msg.MinFee))
+		sp.Disconnect()
+		return
+	}
+
+	atomic.StoreInt64(&sp.feeFilter, msg.MinFee)
+}
+
 // OnFilterLoad is invoked when a peer receives a filterload wire message and it
 // is used to load a bloom filter that should be used for delivering merkle
 // blocks and associated transactions that match the filter.
@@ -1066,11 +1356,14 @@ func (sp *serverPeer) OnGetAddr(p *peer.Peer, msg *wire.MsgGetAddr) {
 		return
 	}
 
-	// Get the current known addresses from the address manager.
-	addrCache := sp.server.addrManager.AddressCache()
-
-	// Push the addresses.
-	sp.pushAddrMsg(addrCache)
+	// Push the current known addresses from the address manager.  Peers
+	// which support the versioned address format are also sent addresses
+	// of networks which are not based on IP.
+	if p.ProtocolVersion() >= wire.AddrV2Version {
+		sp.pushAddrV2Msg(sp.server.addrManager.AddressCacheV2())
+		return
+	}
+	sp.pushAddrMsg(sp.server.addrManager.AddressCache())
 }
 
 // OnAddr is invoked when a peer receives an addr wire message and is used to
@@ -1119,10 +1412,65 @@ func (sp *serverPeer) OnAddr(p *peer.Peer, msg *wire.MsgAddr) {
 	sp.server.addrManager.AddAddresses(msg.AddrList, p.NA())
 }
 
+// OnAddrV2 is invoked when a peer receives an addrv2 wire message and is used
+// to notify the server about advertised addresses, including addresses of
+// networks which are not based on IP.
+func (sp *serverPeer) OnAddrV2(p *peer.Peer, msg *wire.MsgAddrV2) {
+	// Ignore addresses when running on the simulation test network.  This
+	// helps prevent the network from becoming another public test network
+	// since it will not be able to learn about other peers that have not
+	// specifically been provided.
+	if cfg.SimNet {
+		return
+	}
+
+	// A message that has no addresses is invalid.
+	if len(msg.AddrList) == 0 {
+		peerLog.Errorf("Command [%s] from %s does not contain any addresses",
+			msg.Command(), p)
+		p.Disconnect()
+		return
+	}
+
+	now := time.Now()
+	for _, na := range msg.AddrList {
+		// Don't add more address if we're disconnecting.
+		if !p.Connected() {
+			return
+		}
+
+		// Set the timestamp to 5 days ago if it's more than 10 minutes
+		// in the future so this address is one of the first to be
+		// removed when space is needed.
+		if na.Timestamp.After(now.Add(time.Minute * 10)) {
+			na.Timestamp = now.Add(-1 * time.Hour * 24 * 5)
+		}
+	}
+
+	// Add addresses to known addresses for this peer and to the server
+	// address manager.
+	sp.addKnownAddressesV2(msg.AddrList)
+	sp.server.addrManager.AddAddressesV2(msg.AddrList, p.NA())
+}
+
 // OnRead is invoked when a peer receives a message and it is used to update
-// the bytes received by the server.
+// the bytes received by the server.  It also increases the ban score of peers
+// which sent a message with an oversized or malformed payload, or one which was
+// rejected while decoding it, such as a message not allowed by the negotiated
+// protocol version.  The peer package disconnects the peer on any error.
 func (sp *serverPeer) OnRead(p *peer.Peer, bytesRead int, msg wire.Message, err error) {
 	sp.server.AddBytesReceived(uint64(bytesRead))
+
+	if msgErr, ok := err.(*wire.MessageError); ok {
+		switch msgErr.ErrorCode {
+		case wire.ErrPayloadTooLarge, wire.ErrMalformedMessage:
+			sp.addBanScore(50, 0, fmt.Sprintf("protocol violation: %v",
+				err))
+		case wire.ErrUnclassified:
+			sp.addBanScore(20, 0, fmt.Sprintf("rejected message: %v",
+				err))
+		}
+	}
 }
 
 // OnWrite is invoked when a peer sends a message and it is used to update
@@ -1183,6 +1531,7 @@ func (s *server) AnnounceNewTransactions(newTxs []*hcutil.Tx) {
 		// Generate the inventory vector and relay it.
 		iv := wire.NewInvVect(wire.InvTypeTx, tx.Hash())
 		s.RelayInventory(iv, tx)
+		s.publishTx(tx)
 
 		if s.rpcServer != nil {
 			// Notify websocket clients about mempool transactions.
@@ -1196,6 +1545,54 @@ func (s *server) AnnounceNewTransactions(newTxs []*hcutil.Tx) {
 	}
 }
 
+// reversedHash returns the bytes of the passed hash in the order it is
+// displayed in, which is the reverse of the byte order of the wire protocol.
+func reversedHash(hash *chainhash.Hash) []byte {
+	b := make([]byte, chainhash.HashSize)
+	for i := range hash {
+		b[chainhash.HashSize-1-i] = hash[i]
+	}
+	return b
+}
+
+// publishTx publishes the passed transaction accepted to the mempool to the
+// subscribers of the hashtx and rawtx topics, if any.
+func (s *server) publishTx(tx *hcutil.Tx) {
+	if s.publisher == nil {
+		return
+	}
+
+	s.publisher.Publish(pubsub.TopicHashTx, reversedHash(tx.Hash()))
+	if s.publisher.HasTopic(pubsub.TopicRawTx) {
+		txBytes, err := tx.MsgTx().Bytes()
+		if err != nil {
+			srvrLog.Errorf("Failed to serialize transaction %v: %v",
+				tx.Hash(), err)
+			return
+		}
+		s.publisher.Publish(pubsub.TopicRawTx, txBytes)
+	}
+}
+
+// publishBlock publishes the passed block connected to the main chain to the
+// subscribers of the hashblock and rawblock topics, if any.
+func (s *server) publishBlock(block *hcutil.Block) {
+	if s.publisher == nil {
+		return
+	}
+
+	s.publisher.Publish(pubsub.TopicHashBlock, reversedHash(block.Hash()))
+	if s.publisher.HasTopic(pubsub.TopicRawBlock) {
+		blockBytes, err := block.Bytes()
+		if err != nil {
+			srvrLog.Errorf("Failed to serialize block %v: %v",
+				block.Hash(), err)
+			return
+		}
+		s.publisher.Publish(pubsub.TopicRawBlock, blockBytes)
+	}
+}
+
 // pushTxMsg sends a tx message for the provided transaction hash to the
 // connected peer.  An error is returned if the transaction hash is not known.
 func (s *server) pushTxMsg(sp *serverPeer, hash *chainhash.Hash, doneChan chan<- struct{}, waitChan <-chan struct{}) error {
@@ -1229,6 +1626,16 @@ func (s *server) pushTxMsg(sp *serverPeer, hash *chainhash.Hash, doneChan chan<-
 // pushBlockMsg sends a block message for the provided block hash to the
 // connected peer.  An error is returned if the block hash is not known.
 func (s *server) pushBlockMsg(sp *serverPeer, hash *chainhash.Hash, doneChan chan<- struct{}, waitChan <-chan struct{}) error {
+	// Quarantined blocks failed to verify, so they are not served until
+	// they are repaired.
+	if v := s.dbVerifier; v != nil && v.isQuarantined(hash) {
+		peerLog.Debugf("Not serving quarantined block %v to %v", hash, sp)
+		if doneChan != nil {
+			doneChan <- struct{}{}
+		}
+		return fmt.Errorf("block %v is quarantined", hash)
+	}
+
 	block, err := sp.server.blockManager.chain.FetchBlockByHash(hash)
 	if err != nil {
 		peerLog.Tracef("Unable to fetch requested block hash %v: %v",
@@ -1336,13 +1743,23 @@ func (s *server) handleAddPeerMsg(state *peerState, sp *serverPeer) bool {
 
 	// Limit max number of total peers.
 	// allow whitelisted inbound peers regardless.
-	if state.Count() >= cfg.MaxPeers && !(sp.Inbound() && sp.isWhitelisted) {
-		srvrLog.Infof("Max peers reached [%d] - disconnecting peer %s",
-			cfg.MaxPeers, sp)
-		sp.Disconnect()
-		// TODO(oga) how to handle permanent peers here?
-		// they should be rescheduled.
-		return false
+	if state.Count() >= state.maxPeers && !(sp.Inbound() && sp.isWhitelisted) {
+		// Make room for new inbound peers by evicting the least valuable
+		// existing inbound peer when there is one which isn't protected.
+		var evicted *serverPeer
+		if sp.Inbound() {
+			evicted = s.evictInboundPeer(state)
+		}
+		if evicted == nil {
+			srvrLog.Infof("Max peers reached [%d] - disconnecting "+
+				"peer %s", state.maxPeers, sp)
+			sp.Disconnect()
+			// TODO(oga) how to handle permanent peers here?
+			// they should be rescheduled.
+			return false
+		}
+		srvrLog.Infof("Max peers reached [%d] - evicted inbound peer %s "+
+			"for peer %s", state.maxPeers, evicted, sp)
 	}
 
 	// Add the new peer and start it.
@@ -1416,57 +1833,76 @@ func (s *server) handleBanPeerMsg(state *peerState, sp *serverPeer) {
 // known to have it.  It is invoked from the peerHandler goroutine.
 func (s *server) handleRelayInvMsg(state *peerState, msg relayMsg) {
 	state.forAllPeers(func(sp *serverPeer) {
-		if !sp.Connected() {
-			return
+		s.relayInventoryToPeer(sp, msg)
+	})
+}
+
+// relayInventoryToPeer relays inventory to the passed peer unless the peer is
+// not interested in it, and returns whether or not it was relayed.  The
+// inventory is ignored by the peer if it is already known to have it.
+func (s *server) relayInventoryToPeer(sp *serverPeer, msg relayMsg) bool {
+	if !sp.Connected() {
+		return false
+	}
+
+	// If the inventory is a block and the peer prefers headers,
+	// generate and send a headers message instead of an inventory
+	// message.
+	if msg.invVect.Type == wire.InvTypeBlock && sp.WantsHeaders() {
+		blockHeader, ok := msg.data.(wire.BlockHeader)
+		if !ok {
+			peerLog.Warnf("Underlying data for headers" +
+				" is not a block header")
+			return false
+		}
+		msgHeaders := wire.NewMsgHeaders()
+		if err := msgHeaders.AddBlockHeader(&blockHeader); err != nil {
+			peerLog.Errorf("Failed to add block"+
+				" header: %v", err)
+			return false
 		}
+		sp.QueueMessage(msgHeaders, nil)
+		return true
+	}
 
-		// If the inventory is a block and the peer prefers headers,
-		// generate and send a headers message instead of an inventory
-		// message.
-		if msg.invVect.Type == wire.InvTypeBlock && sp.WantsHeaders() {
-			blockHeader, ok := msg.data.(wire.BlockHeader)
+	if msg.invVect.Type == wire.InvTypeTx {
+		// Don't relay the transaction to the peer when it has
+		// transaction relaying disabled.
+		if sp.relayTxDisabled() {
+			return false
+		}
+		// Don't relay the transaction if there is a bloom
+		// filter loaded and the transaction doesn't match it.
+		if sp.filter.IsLoaded() {
+			tx, ok := msg.data.(*hcutil.Tx)
 			if !ok {
-				peerLog.Warnf("Underlying data for headers" +
-					" is not a block header")
-				return
-			}
-			msgHeaders := wire.NewMsgHeaders()
-			if err := msgHeaders.AddBlockHeader(&blockHeader); err != nil {
-				peerLog.Errorf("Failed to add block"+
-					" header: %v", err)
-				return
+				peerLog.Warnf("Underlying data for tx" +
+					" inv relay is not a transaction")
+				return false
 			}
-			sp.QueueMessage(msgHeaders, nil)
-			return
-		}
 
-		if msg.invVect.Type == wire.InvTypeTx {
-			// Don't relay the transaction to the peer when it has
-			// transaction relaying disabled.
-			if sp.relayTxDisabled() {
-				return
+			if !sp.filter.MatchTxAndUpdate(tx) {
+				return false
 			}
-			// Don't relay the transaction if there is a bloom
-			// filter loaded and the transaction doesn't match it.
-			if sp.filter.IsLoaded() {
-				tx, ok := msg.data.(*hcutil.Tx)
-				if !ok {
-					peerLog.Warnf("Underlying data for tx" +
-						" inv relay is not a transaction")
-					return
-				}
+		}
 
-				if !sp.filter.MatchTxAndUpdate(tx) {
-					return
-				}
+		// Don't relay the transaction if its fee rate is below the
+		// one the peer announced with a feefilter message.  It is
+		// relayed when it is no longer in the memory pool since its
+		// fee can't be checked then.
+		if atomic.LoadInt64(&sp.feeFilter) > 0 {
+			txDesc, err := s.txMemPool.FetchTxDesc(&msg.invVect.Hash)
+			if err == nil && sp.belowFeeFilter(txDesc) {
+				return false
 			}
 		}
+	}
 
-		// Queue the inventory to be relayed with the next batch.
-		// It will be ignored if the peer is already known to
-		// have the inventory.
-		sp.QueueInventory(msg.invVect)
-	})
+	// Queue the inventory to be relayed with the next batch.
+	// It will be ignored if the peer is already known to
+	// have the inventory.
+	sp.QueueInventory(msg.invVect)
+	return true
 }
 
 // handleBroadcastMsg deals with broadcasting messages to peers.  It is invoked
@@ -1520,6 +1956,11 @@ type removeNodeMsg struct {
 	reply chan error
 }
 
+type setMaxPeersMsg struct {
+	maxPeers int
+	reply    chan struct{}
+}
+
 // handleQuery is the central handler for all queries and commands from other
 // goroutines related to peer state.
 func (s *server) handleQuery(state *peerState, querymsg interface{}) {
@@ -1546,7 +1987,7 @@ func (s *server) handleQuery(state *peerState, querymsg interface{}) {
 	case connectNodeMsg:
 		// XXX(oga) duplicate oneshots?
 		// Limit max number of total peers.
-		if state.Count() >= cfg.MaxPeers {
+		if state.Count() >= state.maxPeers {
 			msg.reply <- errors.New("max peers reached")
 			return
 		}
@@ -1629,6 +2070,15 @@ func (s *server) handleQuery(state *peerState, querymsg interface{}) {
 		}
 
 		msg.reply <- errors.New("peer not found")
+
+	case setMaxPeersMsg:
+		// Existing peers above the new maximum stay connected, but
+		// no new peers are accepted until enough of them leave.
+		state.maxPeers = msg.maxPeers
+		target := targetOutboundPeers(msg.maxPeers)
+		s.connManager.SetTargetOutbound(uint32(target))
+		srvrLog.Infof("Max peers set to %d", msg.maxPeers)
+		msg.reply <- struct{}{}
 	}
 }
 
@@ -1661,13 +2111,16 @@ func newPeerConfig(sp *serverPeer) *peer.Config {
 	return &peer.Config{
 		Listeners: peer.MessageListeners{
 			OnVersion:        sp.OnVersion,
+			OnPong:           sp.OnPong,
 			OnMemPool:        sp.OnMemPool,
+			OnFeeFilter:      sp.OnFeeFilter,
 			OnGetMiningState: sp.OnGetMiningState,
 			OnMiningState:    sp.OnMiningState,
 			OnTx:             sp.OnTx,
 			OnBlock:          sp.OnBlock,
 			OnInv:            sp.OnInv,
 			OnHeaders:        sp.OnHeaders,
+			OnNotFound:       sp.OnNotFound,
 			OnGetData:        sp.OnGetData,
 			OnGetBlocks:      sp.OnGetBlocks,
 			OnGetHeaders:     sp.OnGetHeaders,
@@ -1676,18 +2129,21 @@ func newPeerConfig(sp *serverPeer) *peer.Config {
 			OnFilterLoad:     sp.OnFilterLoad,
 			OnGetAddr:        sp.OnGetAddr,
 			OnAddr:           sp.OnAddr,
+			OnAddrV2:         sp.OnAddrV2,
 			OnRead:           sp.OnRead,
 			OnWrite:          sp.OnWrite,
 		},
-		NewestBlock:      sp.newestBlock,
-		HostToNetAddress: sp.server.addrManager.HostToNetAddress,
-		Proxy:            cfg.Proxy,
-		UserAgentName:    userAgentName,
-		UserAgentVersion: userAgentVersion,
-		ChainParams:      sp.server.chainParams,
-		Services:         sp.server.services,
-		DisableRelayTx:   cfg.BlocksOnly,
-		ProtocolVersion:  maxProtocolVersion,
+		NewestBlock:            sp.newestBlock,
+		HostToNetAddress:       sp.server.addrManager.HostToNetAddress,
+		Proxy:                  cfg.Proxy,
+		UserAgentName:          userAgentName,
+		UserAgentVersion:       userAgentVersion,
+		ChainParams:            sp.server.chainParams,
+		Services:               sp.server.services,
+		DisableRelayTx:         sp.relayBlocksOnly(),
+		ProtocolVersion:        maxProtocolVersion,
+		TrickleInterval:        cfg.TrickleInterval,
+		InboundTrickleInterval: cfg.InboundTrickle,
 	}
 }
 
@@ -1696,6 +2152,13 @@ func newPeerConfig(sp *serverPeer) *peer.Config {
 // instance, associates it with the connection, and starts a goroutine to wait
 // for disconnection.
 func (s *server) inboundPeerConnected(conn net.Conn) {
+	if isBlacklisted(conn.RemoteAddr()) {
+		srvrLog.Debugf("Refusing connection from blacklisted address %s",
+			conn.RemoteAddr())
+		conn.Close()
+		return
+	}
+
 	sp := newServerPeer(s, false)
 	sp.isWhitelisted = isWhitelisted(conn.RemoteAddr())
 	sp.Peer = peer.NewInboundPeer(newPeerConfig(sp))
@@ -1710,6 +2173,7 @@ func (s *server) inboundPeerConnected(conn net.Conn) {
 // manager of the attempt.
 func (s *server) outboundPeerConnected(c *connmgr.ConnReq, conn net.Conn) {
 	sp := newServerPeer(s, c.Permanent)
+	sp.blockOnly = c.BlockOnly
 	p, err := peer.NewOutboundPeer(newPeerConfig(sp), c.Addr.String())
 	if err != nil {
 		srvrLog.Debugf("Cannot create outbound peer %s: %v", c.Addr, err)
@@ -1757,18 +2221,37 @@ func (s *server) peerHandler() {
 		outboundPeers:   make(map[int32]*serverPeer),
 		banned:          make(map[string]time.Time),
 		outboundGroups:  make(map[string]int),
+		maxPeers:        cfg.MaxPeers,
 	}
 
 	if !cfg.DisableDNSSeed {
 		// Add peers discovered through DNS to the address manager.
-		connmgr.SeedFromDNS(activeNetParams.Params, hcdLookup, func(addrs []*wire.NetAddress) {
-			// Bitcoind uses a lookup of the dns seeder here. This
-			// is rather strange since the values looked up by the
-			// DNS seed lookups will vary quite a lot.
-			// to replicate this behaviour we put all addresses as
-			// having come from the first one.
-			s.addrManager.AddAddresses(addrs, addrs[0])
-		})
+		connmgr.SeedFromDNS(activeNetParams.Params, defaultRequiredServices,
+			hcdLookup, func(addrs []*wire.NetAddress) {
+				// Bitcoind uses a lookup of the dns seeder
+				// here. This is rather strange since the
+				// values looked up by the DNS seed lookups
+				// will vary quite a lot.
+				// to replicate this behaviour we put all
+				// addresses as having come from the first one.
+				s.addrManager.AddAddresses(addrs, addrs[0])
+			})
+	}
+
+	// Reconnect to the anchor peers saved on the last shutdown before
+	// making any other outbound connections so an attacker which filled
+	// the address manager can't eclipse the node after a restart.
+	anchors := s.addrManager.TakeAnchors()
+	if !cfg.SimNet && len(cfg.ConnectPeers) == 0 {
+		for _, na := range anchors {
+			addr, err := addrStringToNetAddr(addrmgr.NetAddressKey(na))
+			if err != nil {
+				srvrLog.Debugf("Skipping anchor peer %v: %v", na.IP, err)
+				continue
+			}
+			srvrLog.Debugf("Connecting to anchor peer %s", addr)
+			go s.connManager.Connect(&connmgr.ConnReq{Addr: addr})
+		}
 	}
 	go s.connManager.Start()
 
@@ -1804,7 +2287,14 @@ out:
 			s.handleQuery(state, qmsg)
 
 		case <-s.quit:
-			// Disconnect all peers on server shutdown.
+			// Stop making and accepting connections, save the
+			// outbound peers to reconnect to first at the next start
+			// and disconnect all peers on server shutdown.
+			s.setShutdownStep("stopping the connection manager")
+			s.connManager.Stop()
+			s.addrManager.SetAnchors(state.anchorAddresses())
+			s.setShutdownStep(fmt.Sprintf("disconnecting %d peers",
+				state.Count()))
 			state.forAllPeers(func(sp *serverPeer) {
 				srvrLog.Tracef("Shutdown peer %s", sp)
 				sp.Disconnect()
@@ -1813,8 +2303,14 @@ out:
 		}
 	}
 
-	s.connManager.Stop()
+	// Wait for the peers to finish before stopping the subsystems they
+	// rely on, so none of them is still processing a block or transaction
+	// when the chain and the database are shut down.
+	s.drainPeers(state)
+
+	s.setShutdownStep("stopping the block manager")
 	s.blockManager.Stop()
+	s.setShutdownStep("saving the peer addresses")
 	s.addrManager.Stop()
 
 	// Drain channels before exiting so nothing is left waiting around
@@ -1836,6 +2332,39 @@ cleanup:
 	srvrLog.Tracef("Peer handler done")
 }
 
+// drainPeers waits until all of the disconnected peers in the passed state are
+// done.  Peers which finish connecting in the meantime are disconnected right
+// away, queries are still answered and any other requests are dropped, since
+// peers could otherwise block on them and never finish.  It is invoked from the
+// peerHandler goroutine.
+func (s *server) drainPeers(state *peerState) {
+	lastCount := -1
+	for state.Count() > 0 {
+		if count := state.Count(); count != lastCount {
+			s.setShutdownStep(fmt.Sprintf("waiting for %d peers "+
+				"to disconnect", count))
+			lastCount = count
+		}
+
+		select {
+		case p := <-s.newPeers:
+			srvrLog.Tracef("Shutdown peer %s", p)
+			p.Disconnect()
+
+		case p := <-s.donePeers:
+			s.handleDonePeerMsg(state, p)
+
+		case qmsg := <-s.query:
+			s.handleQuery(state, qmsg)
+
+		case <-s.peerHeightsUpdate:
+		case <-s.banPeers:
+		case <-s.relayInv:
+		case <-s.broadcast:
+		}
+	}
+}
+
 // AddPeer adds a new peer that has already been connected to the server.
 func (s *server) AddPeer(sp *serverPeer) {
 	s.newPeers <- sp
@@ -1949,6 +2478,15 @@ func (s *server) RemoveNodeByID(id int32) error {
 	return <-replyChan
 }
 
+// SetMaxPeers changes the maximum number of inbound and outbound peers, which
+// starts out as the maxpeers option, along with the number of outbound peers
+// to maintain.  Peers above a lowered maximum stay connected.
+func (s *server) SetMaxPeers(maxPeers int) {
+	replyChan := make(chan struct{})
+	s.query <- setMaxPeersMsg{maxPeers: maxPeers, reply: replyChan}
+	<-replyChan
+}
+
 // ConnectNode adds `addr' as a new outbound peer. If permanent is true then the
 // peer will be persistent and reconnect if the connection is lost.
 // It is an error to call this with an already existing peer.
@@ -1991,64 +2529,38 @@ func (s *server) UpdatePeerHeights(latestBlkHash *chainhash.Hash, latestHeight i
 	}
 }
 
-// rebroadcastHandler keeps track of user submitted inventories that we have
-// sent out but have not yet made it into a block. We periodically rebroadcast
-// them in case our peers restarted or otherwise lost track of them.
-func (s *server) rebroadcastHandler() {
-	// Wait 5 min before first tx rebroadcast.
-	timer := time.NewTimer(5 * time.Minute)
-	pendingInvs := make(map[wire.InvVect]interface{})
+// mempoolExpiryHandler periodically evicts transactions which have been in the
+// memory pool for longer than the configured expiry, notifying websocket
+// clients of each transaction removed.  It must be run as a goroutine.
+func (s *server) mempoolExpiryHandler() {
+	ticker := time.NewTicker(mempoolExpiryScanInterval)
+	defer ticker.Stop()
 
 out:
 	for {
 		select {
-		case riv := <-s.modifyRebroadcastInv:
-			switch msg := riv.(type) {
-			// Incoming InvVects are added to our map of RPC txs.
-			case broadcastInventoryAdd:
-				srvrLog.Debugf("Add inventory : %v", msg.invVect)
-				pendingInvs[*msg.invVect] = msg.data
-
-			// When an InvVect has been added to a block, we can
-			// now remove it, if it was present.
-			case broadcastInventoryDel:
-				if _, ok := pendingInvs[*msg]; ok {
-					srvrLog.Debugf("Remove inventory : %v", msg)
-					delete(pendingInvs, *msg)
+		case <-ticker.C:
+			expired := s.txMemPool.ExpireOldTx(time.Now())
+			for _, tx := range expired {
+				srvrLog.Debugf("Expired transaction %v from the "+
+					"mempool", tx.Hash())
+
+				// The expired transaction no longer needs to be
+				// rebroadcast.
+				iv := wire.NewInvVect(wire.InvTypeTx, tx.Hash())
+				s.RemoveRebroadcastInventory(iv)
+
+				// Notify websocket clients of its removal.
+				if r := s.rpcServer; r != nil {
+					r.ntfnMgr.NotifyTxExpired(tx)
 				}
 			}
 
-		case <-timer.C:
-			// Any inventory we have has not made it into a block
-			// yet. We periodically resubmit them until they have.
-			srvrLog.Debugf("Start relay inventory")
-			for iv, data := range pendingInvs {
-				srvrLog.Debugf("Relay inventory : %v", iv)
-				ivCopy := iv
-				s.RelayInventory(&ivCopy, data)
-			}
-			srvrLog.Debugf("Start relay complete")
-			// Process at a random time up to 30mins (in seconds)
-			// in the future.
-			//timer.Reset(time.Second * time.Duration(randomUint16Number(1800)))
-			timer.Reset(time.Second * 300)
 		case <-s.quit:
 			break out
 		}
 	}
 
-	timer.Stop()
-
-	// Drain channels before exiting so nothing is left waiting around
-	// to send.
-cleanup:
-	for {
-		select {
-		case <-s.modifyRebroadcastInv:
-		default:
-			break cleanup
-		}
-	}
 	s.wg.Done()
 }
 
@@ -2066,11 +2578,36 @@ func (s *server) Start() {
 	s.wg.Add(1)
 	go s.peerHandler()
 
+	// Import the blocks of the bootstrap files if requested.
+	if len(cfg.ImportBootstrap) > 0 {
+		go s.importBootstrapHandler(cfg.ImportBootstrap)
+	}
+
 	if s.nat != nil {
 		s.wg.Add(1)
 		go s.upnpUpdateThread()
 	}
 
+	// Start the mempool expiry handler when time based expiration of
+	// transactions is enabled.
+	if cfg.MempoolExpiry > 0 {
+		s.wg.Add(1)
+		go s.mempoolExpiryHandler()
+	}
+
+	// Start verifying the stored blocks periodically when enabled.
+	if s.dbVerifier != nil {
+		s.wg.Add(1)
+		go s.dbVerifier.run(s.quit)
+	}
+
+	// Start the handler which catches up the optional indexes which are
+	// behind the chain once it is synced.
+	if s.indexManager != nil {
+		s.wg.Add(1)
+		go s.indexCatchUpHandler()
+	}
+
 	if !cfg.DisableRPC {
 		s.wg.Add(1)
 
@@ -2081,6 +2618,14 @@ func (s *server) Start() {
 		s.rpcServer.Start()
 	}
 
+	if s.publisher != nil {
+		s.publisher.Start()
+	}
+
+	if s.tracer != nil {
+		s.tracer.Start()
+	}
+
 	// Start the CPU miner if generation is enabled.
 	if cfg.Generate {
 		s.cpuMiner.Start()
@@ -2098,33 +2643,148 @@ func (s *server) Stop() error {
 
 	srvrLog.Warnf("Server shutting down")
 
-	// Stop the CPU miner if needed.
-	if cfg.Generate && s.cpuMiner != nil {
+	// Stop accepting RPC requests first so clients can't start any new
+	// work while the remaining subsystems shut down.
+	if !cfg.DisableRPC && s.rpcServer != nil {
+		s.setShutdownStep("stopping the RPC server")
+		s.rpcServer.Stop()
+	}
+
+	// Stop the CPU miner if needed.  It may also have been started with the
+	// setgenerate RPC.
+	if s.cpuMiner != nil && s.cpuMiner.IsMining() {
+		s.setShutdownStep("stopping the CPU miner")
 		s.cpuMiner.Stop()
 	}
 
-	// Shutdown the RPC server if it's not disabled.
-	if !cfg.DisableRPC && s.rpcServer != nil {
-		s.rpcServer.Stop()
+	// Stop publishing blocks and transactions.
+	if s.publisher != nil {
+		s.setShutdownStep("stopping the publisher")
+		s.publisher.Stop()
 	}
 
+	// Export the spans of the requests served before the RPC server was
+	// stopped.
+	if s.tracer != nil {
+		s.setShutdownStep("stopping the tracer")
+		s.tracer.Stop()
+	}
+
+	// Log the signature cache statistics.
+	stats := s.sigCache.Stats()
+	srvrLog.Debugf("Signature cache: %d hits, %d misses, %d evictions, "+
+		"%d entries (%d of %d bytes)", stats.Hits, stats.Misses,
+		stats.Evictions, stats.Entries, stats.Size, stats.MaxSize)
+	scriptStats := s.scriptCache.Stats()
+	srvrLog.Debugf("Script cache: %d hits, %d misses, %d evictions, "+
+		"%d entries (%d of %d bytes)", scriptStats.Hits,
+		scriptStats.Misses, scriptStats.Evictions, scriptStats.Entries,
+		scriptStats.Size, scriptStats.MaxSize)
+
 	// Signal the remaining goroutines to quit.
 	close(s.quit)
 	return nil
 }
 
+// indexCatchUpHandler waits until the chain is synced and then catches up the
+// optional indexes which were behind it when they were loaded, so catching
+// them up does not slow down the initial block download.  It must be run as a
+// goroutine.
+func (s *server) indexCatchUpHandler() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(indexCatchUpCheckInterval)
+	defer ticker.Stop()
+
+	chain := s.blockManager.chain
+	lastHeight := chain.BestSnapshot().Height
+	lastChange := time.Now()
+	for {
+		select {
+		case <-ticker.C:
+		case <-s.quit:
+			return
+		}
+
+		height := chain.BestSnapshot().Height
+		if height != lastHeight {
+			lastHeight = height
+			lastChange = time.Now()
+		}
+		if chain.IsCurrent() ||
+			time.Since(lastChange) >= indexCatchUpStallTimeout {
+			break
+		}
+	}
+
+	done := s.indexManager.CatchUp(s.quit)
+	if done == nil {
+		return
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			indxLog.Errorf("Unable to catch up the indexes: %v", err)
+		}
+	case <-s.quit:
+		<-done
+	}
+}
+
+// RebuildIndex drops the passed optional index and rebuilds it in the
+// background while the server keeps processing blocks.  The rebuild stops when
+// the server shuts down and is then finished on the next start.
+func (s *server) RebuildIndex(indexer indexers.Indexer) error {
+	if s.indexManager == nil {
+		return errors.New("no optional indexes are enabled")
+	}
+	done, err := s.indexManager.StartRebuild(indexer, s.quit)
+	if err != nil {
+		return err
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		select {
+		case err := <-done:
+			if err != nil {
+				indxLog.Errorf("Unable to rebuild the %s: %v",
+					indexer.Name(), err)
+			}
+		case <-s.quit:
+			<-done
+		}
+	}()
+	return nil
+}
+
 // WaitForShutdown blocks until the main listener and peer handlers are stopped.
 func (s *server) WaitForShutdown() {
 	s.wg.Wait()
 }
 
+// setShutdownStep logs the step of the shutdown sequence in progress and
+// records it so it can be reported when the shutdown does not finish in time.
+func (s *server) setShutdownStep(step string) {
+	srvrLog.Infof("Shutdown: %s", step)
+	s.shutdownStep.Store(step)
+}
+
+// ShutdownStep returns the step of the shutdown sequence in progress.
+func (s *server) ShutdownStep() string {
+	step, _ := s.shutdownStep.Load().(string)
+	return step
+}
+
 
 
 // parseListeners splits the list of listen addresses passed in addrs into
 // IPv4 and IPv6 slices and returns them.  This allows easy creation of the
 // listeners on the correct interface "tcp4" and "tcp6".  It also properly
 // detects addresses which apply to "all interfaces" and adds the address to
-// both slices.
+// both slices.  The unspecified IPv4 and IPv6 addresses apply to all interfaces
+// of their address family only.
 func parseListeners(addrs []string) ([]string, []string, bool, error) {
 	ipv4ListenAddrs := make([]string, 0, len(addrs)*2)
 	ipv6ListenAddrs := make([]string, 0, len(addrs)*2)
@@ -2158,6 +2818,9 @@ func parseListeners(addrs []string) ([]string, []string, bool, error) {
 			return nil, nil, false, fmt.Errorf("'%s' is not a "+
 				"valid IP address", host)
 		}
+		if ip.IsUnspecified() {
+			haveWildcard = true
+		}
 
 		// To4 returns nil when the IP is not an IPv4 address, so use
 		// this determine the address type.
@@ -2238,6 +2901,15 @@ func standardScriptVerifyFlags(chain *blockchain.BlockChain) (txscript.ScriptFla
 	return scriptFlags, nil
 }
 
+// targetOutboundPeers returns the number of automatic outbound peers to
+// maintain for the passed maximum number of peers.
+func targetOutboundPeers(maxPeers int) int {
+	if maxPeers < defaultTargetOutbound {
+		return maxPeers
+	}
+	return defaultTargetOutbound
+}
+
 // newServer returns a new hcd server configured to listen on addr for the
 // hcd network type specified by chainParams.  Use start to begin accepting
 // connections from peers.
@@ -2247,6 +2919,12 @@ func newServer(listenAddrs []string, db database.DB, chainParams *chaincfg.Param
 		services &^= wire.SFNodeBloom
 	}
 
+	// Record the latency of all database transactions made by the server
+	// and its subsystems for the gethealth RPC and log the slow ones.
+	dbLatency := newLatencyTracker(dbLatencySamples)
+	slowQueries := newSlowQueryLog(cfg.SlowQueryThreshold)
+	db = &timedDB{DB: db, latency: dbLatency, slow: slowQueries}
+
 	amgr := addrmgr.New(cfg.DataDir, hcdLookup)
 
 	var listeners []net.Listener
@@ -2319,6 +2997,14 @@ func newServer(listenAddrs []string, db database.DB, chainParams *chaincfg.Param
 				if err != nil {
 					continue
 				}
+
+				// Skip the addresses of an address family
+				// which is not listened on, such as the IPv4
+				// addresses when only bound to IPv6.
+				if (ip.To4() != nil && len(ipv4Addrs) == 0) ||
+					(ip.To4() == nil && len(ipv6Addrs) == 0) {
+					continue
+				}
 				na := wire.NewNetAddressIPPort(ip,
 					uint16(port), services)
 				if discover {
@@ -2387,9 +3073,18 @@ func newServer(listenAddrs []string, db database.DB, chainParams *chaincfg.Param
 		peerHeightsUpdate:    make(chan updatePeerHeightsMsg),
 		nat:                  nat,
 		db:                   db,
+		dbLatency:            dbLatency,
+		slowQueries:          slowQueries,
 		timeSource:           blockchain.NewMedianTime(),
 		services:             services,
-		sigCache:             txscript.NewSigCache(cfg.SigCacheMaxSize),
+		sigCache:             txscript.NewSigCache(cfg.SigCacheMaxSize * 1000 * 1000),
+		scriptCache:          txscript.NewScriptCache(cfg.ScriptCacheMaxSize * 1000 * 1000),
+		blockArrivals:        newArrivalIndex(maxBlockArrivals),
+		txArrivals:           newArrivalIndex(maxTxArrivals),
+	}
+
+	if _, err := rand.Read(s.netGroupKey[:]); err != nil {
+		return nil, err
 	}
 
 	// Create the transaction and address indexes if needed.
@@ -2427,13 +3122,19 @@ func newServer(listenAddrs []string, db database.DB, chainParams *chaincfg.Param
 	// Create an index manager if any of the optional indexes are enabled.
 	var indexManager blockchain.IndexManager
 	if len(indexes) > 0 {
-		indexManager = indexers.NewManager(db, indexes, chainParams)
+		s.indexManager = indexers.NewManager(db, indexes, chainParams)
+		indexManager = s.indexManager
 	}
 	bm, err := newBlockManager(&s, indexManager)
 	if err != nil {
 		return nil, err
 	}
 	s.blockManager = bm
+	s.chainStats = newChainStats(maxChainStatsBlocks,
+		bm.chain.BestSnapshot().Height)
+	if cfg.DBVerifyInterval > 0 {
+		s.dbVerifier = newDBVerifier(&s, cfg.DBVerifyInterval)
+	}
 
 	txC := mempool.Config{
 		Policy: mempool.Policy{
@@ -2445,6 +3146,9 @@ func newServer(listenAddrs []string, db database.DB, chainParams *chaincfg.Param
 			MaxOrphanTxSize:      defaultMaxOrphanTxSize,
 			MaxSigOpsPerTx:       blockchain.MaxSigOpsPerBlock / 5,
 			MinRelayTxFee:        cfg.minRelayTxFee,
+			DustRelayFee:         cfg.dustRelayFee,
+			MempoolExpiry:        cfg.MempoolExpiry,
+			MaxMempoolSize:       int64(cfg.MaxMempool) * 1000 * 1000,
 			AllowOldVotes:        cfg.AllowOldVotes,
 			StandardVerifyFlags: func() (txscript.ScriptFlags, error) {
 				return standardScriptVerifyFlags(bm.chain)
@@ -2464,6 +3168,7 @@ func newServer(listenAddrs []string, db database.DB, chainParams *chaincfg.Param
 		CalcSequenceLock: bm.chain.CalcSequenceLock,
 		SubsidyCache:     bm.chain.FetchSubsidyCache(),
 		SigCache:         s.sigCache,
+		ScriptCache:      s.scriptCache,
 		PastMedianTime:   func() time.Time { return bm.chain.BestSnapshot().MedianTime },
 		AddrIndex:        s.addrIndex,
 		ExistsAddrIndex:  s.existsAddrIndex,
@@ -2477,7 +3182,6 @@ func newServer(listenAddrs []string, db database.DB, chainParams *chaincfg.Param
 		BlockMinSize:      cfg.BlockMinSize,
 		BlockMaxSize:      cfg.BlockMaxSize,
 		BlockPrioritySize: cfg.BlockPrioritySize,
-		TxMinFreeFee:      cfg.minRelayTxFee,
 	}
 	s.cpuMiner = newCPUMiner(&policy, &s)
 
@@ -2496,17 +3200,41 @@ func newServer(listenAddrs []string, db database.DB, chainParams *chaincfg.Param
 					break
 				}
 
+				// Peers are identified by their legacy address, so
+				// addresses of networks which can't be represented
+				// by it, such as version 3 Tor onion services, are
+				// only stored and relayed.
+				na := addr.NetAddress()
+				if na == nil {
+					continue
+				}
+
 				// Address will not be invalid, local or unroutable
 				// because addrmanager rejects those on addition.
 				// Just check that we don't already have an address
 				// in the same group so that we are not connecting
 				// to the same network segment at the expense of
 				// others.
-				key := addrmgr.GroupKey(addr.NetAddress())
+				key := addrmgr.GroupKey(na)
 				if s.OutboundGroupCount(key) != 0 {
 					continue
 				}
 
+				// Only connect to addresses of the networks
+				// selected with --onlynet, if any.
+				if cfg.onlyNets != nil {
+					network := addrmgr.Network(na)
+					if _, ok := cfg.onlyNets[network]; !ok {
+						continue
+					}
+				}
+
+				// Never connect to blacklisted addresses.
+				tcpAddr := &net.TCPAddr{IP: na.IP, Port: int(na.Port)}
+				if isBlacklisted(tcpAddr) {
+					continue
+				}
+
 				// only allow recent nodes (10mins) after we failed 30
 				// times
 				if tries < 30 && time.Now().Sub(addr.LastAttempt()) < 10*time.Minute {
@@ -2514,12 +3242,12 @@ func newServer(listenAddrs []string, db database.DB, chainParams *chaincfg.Param
 				}
 
 				// allow nondefault ports after 50 failed tries.
-				if fmt.Sprintf("%d", addr.NetAddress().Port) !=
+				if fmt.Sprintf("%d", na.Port) !=
 					activeNetParams.DefaultPort && tries < 50 {
 					continue
 				}
 
-				addrString := addrmgr.NetAddressKey(addr.NetAddress())
+				addrString := addrmgr.NetAddressKey(na)
 				return addrStringToNetAddr(addrString)
 			}
 
@@ -2528,18 +3256,23 @@ func newServer(listenAddrs []string, db database.DB, chainParams *chaincfg.Param
 	}
 
 	// Create a connection manager.
-	targetOutbound := defaultTargetOutbound
-	if cfg.MaxPeers < targetOutbound {
-		targetOutbound = cfg.MaxPeers
-	}
+	targetOutbound := targetOutboundPeers(cfg.MaxPeers)
+
+	// Use a quarter of the automatic outbound connections to only relay
+	// blocks.  Those connections don't reveal any transaction relay
+	// information, which makes it harder for an attacker to infer the
+	// network topology and partition the node.
+	blockOnlyOutbound := targetOutbound / 4
 	cmgr, err := connmgr.New(&connmgr.Config{
-		Listeners:      listeners,
-		OnAccept:       s.inboundPeerConnected,
-		RetryDuration:  connectionRetryInterval,
-		TargetOutbound: uint32(targetOutbound),
-		Dial:           hcdDial,
-		OnConnection:   s.outboundPeerConnected,
-		GetNewAddress:  newAddressFunc,
+		Listeners:              listeners,
+		OnAccept:               s.inboundPeerConnected,
+		RetryDuration:          connectionRetryInterval,
+		TargetOutbound:         uint32(targetOutbound),
+		BlockOnlyOutbound:      uint32(blockOnlyOutbound),
+		MaxOutboundPerNetGroup: maxOutboundPerNetGroup,
+		Dial:                   hcdDial,
+		OnConnection:           s.outboundPeerConnected,
+		GetNewAddress:          newAddressFunc,
 	})
 	if err != nil {
 		return nil, err
@@ -2563,8 +3296,37 @@ func newServer(listenAddrs []string, db database.DB, chainParams *chaincfg.Param
 		})
 	}
 
+	// Create the publisher when any of its topics are published.
+	pubTopics := make(map[string]string)
+	for topic, addr := range map[string]string{
+		pubsub.TopicHashBlock: cfg.ZMQPubHashBlock,
+		pubsub.TopicHashTx:    cfg.ZMQPubHashTx,
+		pubsub.TopicRawBlock:  cfg.ZMQPubRawBlock,
+		pubsub.TopicRawTx:     cfg.ZMQPubRawTx,
+	} {
+		if addr != "" {
+			pubTopics[topic] = addr
+		}
+	}
+	if len(pubTopics) > 0 {
+		s.publisher, err = pubsub.New(&pubsub.Config{Topics: pubTopics})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.TracingEndpoint != "" {
+		s.tracer, err = tracing.New(&tracing.Config{
+			Endpoint: cfg.TracingEndpoint,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if !cfg.DisableRPC {
-		s.rpcServer, err = newRPCServer(cfg.RPCListeners, &policy, &s)
+		s.rpcServer, err = newRPCServer(cfg.RPCListeners,
+			cfg.RPCPublicListeners, &policy, &s)
 		if err != nil {
 			return nil, err
 		}
@@ -2611,10 +3373,10 @@ func addrStringToNetAddr(addr string) (net.Addr, error) {
 }
 
 
-// isWhitelisted returns whether the IP address is included in the whitelisted
-// networks and IPs.
-func isWhitelisted(addr net.Addr) bool {
-	if len(cfg.whitelists) == 0 {
+// ipNetsContain returns whether the IP address of the passed address is
+// included in any of the passed networks.
+func ipNetsContain(ipnets []*net.IPNet, addr net.Addr) bool {
+	if len(ipnets) == 0 {
 		return false
 	}
 
@@ -2629,10 +3391,28 @@ func isWhitelisted(addr net.Addr) bool {
 		return false
 	}
 
-	for _, ipnet := range cfg.whitelists {
+	for _, ipnet := range ipnets {
 		if ipnet.Contains(ip) {
 			return true
 		}
 	}
 	return false
 }
+
+// isWhitelisted returns whether the IP address is included in the whitelisted
+// networks and IPs.
+func isWhitelisted(addr net.Addr) bool {
+	cfgMtx.RLock()
+	defer cfgMtx.RUnlock()
+	return ipNetsContain(cfg.whitelists, addr)
+}
+
+// isBlacklisted returns whether the IP address is included in the blacklisted
+// networks and IPs and not whitelisted.  Connections to and from blacklisted
+// addresses are refused.
+func isBlacklisted(addr net.Addr) bool {
+	cfgMtx.RLock()
+	blacklisted := ipNetsContain(cfg.blacklists, addr)
+	cfgMtx.RUnlock()
+	return blacklisted && !isWhitelisted(addr)
+}
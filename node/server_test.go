@@ -0,0 +1,158 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package node
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/HcashOrg/hcd/blockchain/stake"
+	"github.com/HcashOrg/hcd/hcutil"
+	"github.com/HcashOrg/hcd/mempool"
+	"github.com/HcashOrg/hcd/mining"
+	"github.com/HcashOrg/hcd/peer"
+	"github.com/HcashOrg/hcd/wire"
+)
+
+// TestParseListeners ensures listen addresses are split by address family,
+// the unspecified addresses are only wildcards of their own family and
+// addresses which are not IP addresses are rejected.
+func TestParseListeners(t *testing.T) {
+	tests := []struct {
+		name     string
+		addrs    []string
+		ipv4     []string
+		ipv6     []string
+		wildcard bool
+		err      bool
+	}{{
+		name:     "all interfaces",
+		addrs:    []string{":14008"},
+		ipv4:     []string{":14008"},
+		ipv6:     []string{":14008"},
+		wildcard: true,
+	}, {
+		name:     "ipv6 only",
+		addrs:    []string{"[::]:14008"},
+		ipv4:     []string{},
+		ipv6:     []string{"[::]:14008"},
+		wildcard: true,
+	}, {
+		name:     "ipv4 only",
+		addrs:    []string{"0.0.0.0:14008"},
+		ipv4:     []string{"0.0.0.0:14008"},
+		ipv6:     []string{},
+		wildcard: true,
+	}, {
+		name:  "specific addresses",
+		addrs: []string{"127.0.0.1:14008", "[2001:470::1]:14008"},
+		ipv4:  []string{"127.0.0.1:14008"},
+		ipv6:  []string{"[2001:470::1]:14008"},
+	}, {
+		name:  "host name",
+		addrs: []string{"localhost:14008"},
+		err:   true,
+	}}
+
+	for _, test := range tests {
+		ipv4, ipv6, wildcard, err := parseListeners(test.addrs)
+		if (err != nil) != test.err {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+			continue
+		}
+		if test.err {
+			continue
+		}
+		if !reflect.DeepEqual(ipv4, test.ipv4) ||
+			!reflect.DeepEqual(ipv6, test.ipv6) {
+			t.Errorf("%s: got ipv4 %v and ipv6 %v, want %v and %v",
+				test.name, ipv4, ipv6, test.ipv4, test.ipv6)
+		}
+		if wildcard != test.wildcard {
+			t.Errorf("%s: got wildcard %v, want %v", test.name,
+				wildcard, test.wildcard)
+		}
+	}
+}
+
+// TestBelowFeeFilter ensures only regular transactions paying less than the
+// fee rate announced by a peer with a feefilter message are withheld from it,
+// while zero fee stake transactions such as votes are still announced.
+func TestBelowFeeFilter(t *testing.T) {
+	newTxDesc := func(txType stake.TxType, fee int64) *mempool.TxDesc {
+		return &mempool.TxDesc{TxDesc: mining.TxDesc{
+			Tx:   hcutil.NewTx(wire.NewMsgTx()),
+			Type: txType,
+			Fee:  fee,
+		}}
+	}
+
+	tests := []struct {
+		name      string
+		feeFilter int64
+		txDesc    *mempool.TxDesc
+		want      bool
+	}{
+		{"no feefilter", 0, newTxDesc(stake.TxTypeRegular, 0), false},
+		{"zero fee regular", 1000, newTxDesc(stake.TxTypeRegular, 0), true},
+		{"high fee regular", 1000, newTxDesc(stake.TxTypeRegular, 1e8), false},
+		{"zero fee vote", 1000, newTxDesc(stake.TxTypeSSGen, 0), false},
+		{"zero fee revocation", 1000, newTxDesc(stake.TxTypeSSRtx, 0), false},
+		{"zero fee ticket", 1000, newTxDesc(stake.TxTypeSStx, 0), false},
+	}
+	for _, test := range tests {
+		sp := &serverPeer{feeFilter: test.feeFilter}
+		if got := sp.belowFeeFilter(test.txDesc); got != test.want {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+// TestOnReadBanScore ensures the ban score of a peer is increased by messages
+// which violate the protocol, with malformed payloads scoring more than those
+// merely rejected while decoding, and that neither bans the peer at once.
+func TestOnReadBanScore(t *testing.T) {
+	oldCfg := cfg
+	defer func() {
+		cfg = oldCfg
+	}()
+	cfg = &Config{BanThreshold: defaultBanThreshold}
+
+	tests := []struct {
+		name  string
+		err   error
+		score uint32
+	}{{
+		name:  "no error",
+		err:   nil,
+		score: 0,
+	}, {
+		name:  "wrong network",
+		err:   &wire.MessageError{ErrorCode: wire.ErrWrongNetwork},
+		score: 0,
+	}, {
+		name:  "payload too large",
+		err:   &wire.MessageError{ErrorCode: wire.ErrPayloadTooLarge},
+		score: 50,
+	}, {
+		name:  "malformed message",
+		err:   &wire.MessageError{ErrorCode: wire.ErrMalformedMessage},
+		score: 50,
+	}, {
+		name:  "rejected message",
+		err:   &wire.MessageError{ErrorCode: wire.ErrUnclassified},
+		score: 20,
+	}}
+
+	for _, test := range tests {
+		sp := newServerPeer(&server{}, false)
+		sp.Peer = peer.NewInboundPeer(&peer.Config{})
+		sp.OnRead(sp.Peer, 0, nil, test.err)
+		if score := sp.banScore.Int(); score != test.score {
+			t.Errorf("%s: got ban score %d, want %d", test.name,
+				score, test.score)
+		}
+	}
+}
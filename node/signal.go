@@ -4,7 +4,7 @@
 // Use of this source code is governed by an ISC
 // license that can be found in the LICENSE file.
 
-package main
+package node
 
 import (
 	"context"
@@ -16,6 +16,11 @@ import (
 // subsystems using the same code paths as when an interrupt signal is received.
 var shutdownRequestChannel = make(chan struct{})
 
+// chainInterrupt is closed once a shutdown signal is received while hcd is
+// running, so a rebuild of the chain state started on start up can be
+// interrupted.  It is nil for embedded nodes.
+var chainInterrupt <-chan struct{}
+
 // interruptSignals defines the default signals to catch in order to do a proper
 // shutdown.  This may be modified during init depending on the platform.
 var interruptSignals = []os.Signal{os.Interrupt}
@@ -4,7 +4,7 @@
 
 // +build darwin dragonfly freebsd linux netbsd openbsd solaris
 
-package main
+package node
 
 import (
 	"os"
@@ -13,4 +13,5 @@ import (
 
 func init() {
 	interruptSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	reloadSignals = []os.Signal{syscall.SIGHUP}
 }
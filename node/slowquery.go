@@ -0,0 +1,156 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package node
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"path"
+	"runtime"
+	"sync"
+	"time"
+)
+
+const (
+	// maxSlowQueries is the number of the most recent slow queries kept
+	// for the getslowqueries RPC.
+	maxSlowQueries = 256
+
+	// The following constants are the types of slow queries.
+	slowQueryRPC      = "rpc"
+	slowQueryDBView   = "dbview"
+	slowQueryDBUpdate = "dbupdate"
+)
+
+// slowQuery is an RPC call or database transaction which took longer than
+// the slow query threshold.
+type slowQuery struct {
+	time     time.Time
+	kind     string
+	name     string
+	params   string
+	duration time.Duration
+	lockWait time.Duration
+}
+
+// slowQueryLog logs and keeps the most recent RPC calls and database
+// transactions which took longer than a threshold.  It is safe for concurrent
+// access.
+type slowQueryLog struct {
+	threshold time.Duration
+
+	mtx     sync.Mutex
+	queries []slowQuery
+	next    int
+}
+
+// newSlowQueryLog returns a slow query log for the operations which take
+// longer than the passed threshold.  Nothing is logged when the threshold is
+// zero.
+func newSlowQueryLog(threshold time.Duration) *slowQueryLog {
+	return &slowQueryLog{threshold: threshold}
+}
+
+// isSlow returns whether an operation which took the passed duration must be
+// logged.
+func (l *slowQueryLog) isSlow(d time.Duration) bool {
+	return l.threshold > 0 && d >= l.threshold
+}
+
+// add keeps the passed slow query, replacing the oldest one once the log is
+// full.
+func (l *slowQueryLog) add(q slowQuery) {
+	l.mtx.Lock()
+	if len(l.queries) < maxSlowQueries {
+		l.queries = append(l.queries, q)
+	} else {
+		l.queries[l.next] = q
+		l.next = (l.next + 1) % maxSlowQueries
+	}
+	l.mtx.Unlock()
+}
+
+// recordRPC logs and keeps the RPC call of the passed method with the passed
+// parsed parameters when it took longer than the threshold since it started
+// at the passed time.  Only a digest of the parameters is kept since they may
+// be large or contain secrets.
+func (l *slowQueryLog) recordRPC(method string, cmd interface{}, start time.Time) {
+	d := time.Since(start)
+	if !l.isSlow(d) {
+		return
+	}
+
+	params := paramsDigest(cmd)
+	rpcsLog.Warnf("Slow RPC call <%s> with params digest %s took %v",
+		method, params, d)
+	l.add(slowQuery{
+		time:     start,
+		kind:     slowQueryRPC,
+		name:     method,
+		params:   params,
+		duration: d,
+	})
+}
+
+// recordDB logs and keeps the database transaction of the passed type made
+// by the passed caller when it took longer than the threshold since it started
+// at the passed time.  The lock wait is the time it took to acquire the
+// transaction, which includes waiting for any other write transaction to
+// finish for updates.
+func (l *slowQueryLog) recordDB(kind, caller string, start time.Time, lockWait time.Duration) {
+	d := time.Since(start)
+	if !l.isSlow(d) {
+		return
+	}
+
+	bcdbLog.Warnf("Slow database transaction (%s) by %s took %v, of "+
+		"which %v waiting for locks", kind, caller, d, lockWait)
+	l.add(slowQuery{
+		time:     start,
+		kind:     kind,
+		name:     caller,
+		duration: d,
+		lockWait: lockWait,
+	})
+}
+
+// recent returns the kept slow queries, most recent first.
+func (l *slowQueryLog) recent() []slowQuery {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	queries := make([]slowQuery, 0, len(l.queries))
+	for i := len(l.queries) - 1; i >= 0; i-- {
+		queries = append(queries, l.queries[(l.next+i)%len(l.queries)])
+	}
+	return queries
+}
+
+// paramsDigest returns the first 8 bytes of the SHA-256 hash of the JSON
+// encoding of the passed parsed RPC parameters as a hexadecimal string.  Calls
+// with the same parameters have the same digest.
+func paramsDigest(cmd interface{}) string {
+	params, err := json.Marshal(cmd)
+	if err != nil {
+		return ""
+	}
+	digest := sha256.Sum256(params)
+	return hex.EncodeToString(digest[:8])
+}
+
+// callerName returns the name of the function which called the function the
+// call is made from, such as blockchain.(*BlockChain).BlockByHash.
+func callerName() string {
+	pc, _, _, ok := runtime.Caller(2)
+	if !ok {
+		return "unknown"
+	}
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return "unknown"
+	}
+	return path.Base(fn.Name())
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/HcashOrg/hcd/hcjson"
+	"github.com/btcsuite/btclog"
+)
+
+// TestSlowQueryLog ensures only the operations which take longer than the
+// threshold are kept, most recent first, and that the oldest ones are
+// replaced once the log is full.
+func TestSlowQueryLog(t *testing.T) {
+	oldRPCLog, oldDBLog := rpcsLog, bcdbLog
+	defer func() { rpcsLog, bcdbLog = oldRPCLog, oldDBLog }()
+	rpcsLog, bcdbLog = btclog.Disabled, btclog.Disabled
+
+	// Nothing is logged with a zero threshold.
+	l := newSlowQueryLog(0)
+	l.recordRPC("getbestblock", &hcjson.GetBestBlockCmd{},
+		time.Now().Add(-time.Hour))
+	if queries := l.recent(); len(queries) != 0 {
+		t.Fatalf("unexpected slow queries with zero threshold: %v",
+			queries)
+	}
+
+	l = newSlowQueryLog(time.Second)
+	now := time.Now()
+	l.recordRPC("getbestblock", &hcjson.GetBestBlockCmd{}, now)
+	l.recordRPC("getblock", hcjson.NewGetBlockCmd("123", nil, nil),
+		now.Add(-2*time.Second))
+	l.recordDB(slowQueryDBUpdate, "blockchain.(*BlockChain).connectBlock",
+		now.Add(-3*time.Second), time.Second)
+	queries := l.recent()
+	if len(queries) != 2 {
+		t.Fatalf("unexpected number of slow queries: got %d, want 2",
+			len(queries))
+	}
+	if q := queries[0]; q.kind != slowQueryDBUpdate ||
+		q.name != "blockchain.(*BlockChain).connectBlock" ||
+		q.lockWait != time.Second || q.duration < 3*time.Second {
+
+		t.Fatalf("unexpected slow database transaction: %+v", q)
+	}
+	q := queries[1]
+	if q.kind != slowQueryRPC || q.name != "getblock" ||
+		q.params != paramsDigest(hcjson.NewGetBlockCmd("123", nil, nil)) {
+
+		t.Fatalf("unexpected slow RPC call: %+v", q)
+	}
+	if q.params == paramsDigest(hcjson.NewGetBlockCmd("456", nil, nil)) {
+		t.Fatalf("params digest does not depend on the params")
+	}
+
+	// The oldest queries are replaced once the log is full.
+	for i := 0; i < maxSlowQueries; i++ {
+		l.recordRPC("getbestblock", &hcjson.GetBestBlockCmd{},
+			now.Add(-time.Duration(i+2)*time.Second))
+	}
+	queries = l.recent()
+	if len(queries) != maxSlowQueries {
+		t.Fatalf("unexpected number of slow queries: got %d, want %d",
+			len(queries), maxSlowQueries)
+	}
+	for i, q := range queries {
+		want := now.Add(-time.Duration(maxSlowQueries+1-i) * time.Second)
+		if q.name != "getbestblock" || !q.time.Equal(want) {
+			t.Fatalf("unexpected slow query %d: %+v", i, q)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package node
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// syncState describes the stage of the initial block download the block
+// manager is in.
+type syncState int
+
+const (
+	// syncStateIdle indicates the chain is not current and there is no
+	// sync peer to download the chain from.
+	syncStateIdle syncState = iota
+
+	// syncStateHeaders indicates block headers up to the next checkpoint
+	// are being downloaded from the sync peer.
+	syncStateHeaders
+
+	// syncStateBlocks indicates blocks are being downloaded from the sync
+	// peer.
+	syncStateBlocks
+
+	// syncStateCurrent indicates the chain is synced with the network.
+	syncStateCurrent
+)
+
+// syncStateStrings is a map of sync states back to their constant names for
+// pretty printing.
+var syncStateStrings = map[syncState]string{
+	syncStateIdle:    "idle",
+	syncStateHeaders: "headers",
+	syncStateBlocks:  "blocks",
+	syncStateCurrent: "current",
+}
+
+// String returns the syncState as a human-readable name.
+func (s syncState) String() string {
+	if str, ok := syncStateStrings[s]; ok {
+		return str
+	}
+	return fmt.Sprintf("Unknown syncState (%d)", int(s))
+}
+
+// syncProgressWindow is the period of most recent block arrivals the
+// download speed is measured over.
+const syncProgressWindow = time.Minute
+
+// syncProgress tracks the progress of the initial block download.  It is fed
+// by the block manager with the sync state, the timestamps of received
+// headers and the arrival of blocks, and estimates the height of the best
+// chain of the network and the speed blocks are downloaded at.
+//
+// This is done because the block manager is typically quite busy during the
+// initial block download, so querying it for the progress would be slow.
+// The tracker is therefore protected by its own mutex and is safe for
+// concurrent access.
+type syncProgress struct {
+	mtx           sync.Mutex
+	state         syncState
+	headersHeight int64
+	headersTime   time.Time
+	blockHeight   int64
+	blockTime     time.Time
+	arrivals      []time.Time
+}
+
+// newSyncProgress returns a sync progress tracker for a chain with the passed
+// best block height and timestamp.
+func newSyncProgress(bestHeight int64, bestTime time.Time) *syncProgress {
+	return &syncProgress{
+		headersHeight: bestHeight,
+		headersTime:   bestTime,
+		blockHeight:   bestHeight,
+		blockTime:     bestTime,
+	}
+}
+
+// setState sets the sync state of the initial block download.
+func (p *syncProgress) setState(state syncState) {
+	p.mtx.Lock()
+	p.state = state
+	p.mtx.Unlock()
+}
+
+// resetHeaders discards the known headers beyond the most recently received
+// block.  It is used when the sync peer is lost and the headers downloaded from
+// it are thrown away.
+func (p *syncProgress) resetHeaders() {
+	p.mtx.Lock()
+	p.headersHeight = p.blockHeight
+	p.headersTime = p.blockTime
+	p.mtx.Unlock()
+}
+
+// headersReceived records a block header with the passed height and timestamp
+// received from the sync peer.
+func (p *syncProgress) headersReceived(height int64, timestamp time.Time) {
+	p.mtx.Lock()
+	if height > p.headersHeight {
+		p.headersHeight = height
+		p.headersTime = timestamp
+	}
+	p.mtx.Unlock()
+}
+
+// blockReceived records the arrival of a block with the passed height and
+// timestamp which was connected to the chain.
+func (p *syncProgress) blockReceived(height int64, timestamp,
+	now time.Time) {
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	p.blockHeight = height
+	p.blockTime = timestamp
+	if height > p.headersHeight {
+		p.headersHeight = height
+		p.headersTime = timestamp
+	}
+
+	// Drop arrivals which are no longer within the window the download
+	// speed is measured over.
+	var i int
+	for i < len(p.arrivals) && now.Sub(p.arrivals[i]) > syncProgressWindow {
+		i++
+	}
+	p.arrivals = append(p.arrivals[i:], now)
+}
+
+// syncProgressSnapshot is a snapshot of the progress of the initial block
+// download as returned by syncProgress.snapshot.
+type syncProgressSnapshot struct {
+	State           syncState
+	HeadersHeight   int64
+	EstimatedHeight int64
+	Progress        float64
+	BlocksPerSec    float64
+}
+
+// snapshot returns the progress of the initial block download for a chain with
+// the passed best block height.  peerHeight is the latest block height
+// announced by the sync peer, if any.
+//
+// The height of the best chain of the network is estimated as the greater of
+// the height announced by the sync peer and the height extrapolated from the
+// timestamp of the best known header using the target time per block.
+func (p *syncProgress) snapshot(bestHeight, peerHeight int64, current bool,
+	targetTimePerBlock time.Duration, now time.Time) *syncProgressSnapshot {
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	snap := &syncProgressSnapshot{
+		State:         p.state,
+		HeadersHeight: p.headersHeight,
+	}
+	if current {
+		snap.State = syncStateCurrent
+	}
+	if snap.HeadersHeight < bestHeight {
+		snap.HeadersHeight = bestHeight
+	}
+
+	estimated := snap.HeadersHeight
+	if !current && targetTimePerBlock > 0 && !p.headersTime.IsZero() &&
+		now.After(p.headersTime) {
+
+		elapsed := now.Sub(p.headersTime)
+		estimated = p.headersHeight + int64(elapsed/targetTimePerBlock)
+	}
+	if estimated < peerHeight {
+		estimated = peerHeight
+	}
+	if estimated < snap.HeadersHeight {
+		estimated = snap.HeadersHeight
+	}
+	snap.EstimatedHeight = estimated
+
+	if estimated > 0 {
+		snap.Progress = math.Min(float64(bestHeight)/float64(estimated),
+			1.0)
+	}
+
+	// Measure the download speed over the arrivals within the window.  The
+	// speed is zero when no blocks arrived during the window.
+	var blocks int
+	var oldest time.Time
+	for _, arrival := range p.arrivals {
+		if now.Sub(arrival) > syncProgressWindow {
+			continue
+		}
+		if blocks == 0 {
+			oldest = arrival
+		}
+		blocks++
+	}
+	if blocks > 0 {
+		elapsed := now.Sub(oldest).Seconds()
+		if elapsed < 1 {
+			elapsed = 1
+		}
+		snap.BlocksPerSec = float64(blocks) / elapsed
+	}
+
+	return snap
+}
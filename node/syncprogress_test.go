@@ -0,0 +1,87 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package node
+
+import (
+	"testing"
+	"time"
+)
+
+// TestSyncProgress ensures the initial block download progress tracker
+// estimates the height of the network chain from header timestamps and peer
+// heights and measures the block download speed.
+func TestSyncProgress(t *testing.T) {
+	const target = 5 * time.Minute
+	now := time.Unix(1500000000, 0)
+	p := newSyncProgress(100, now.Add(-100*target))
+
+	// The height is extrapolated from the timestamp of the best block.
+	snap := p.snapshot(100, 0, false, target, now)
+	if snap.State != syncStateIdle {
+		t.Fatalf("unexpected state: got %v, want %v", snap.State,
+			syncStateIdle)
+	}
+	if snap.HeadersHeight != 100 || snap.EstimatedHeight != 200 {
+		t.Fatalf("unexpected heights: got %d/%d, want 100/200",
+			snap.HeadersHeight, snap.EstimatedHeight)
+	}
+	if snap.Progress != 0.5 {
+		t.Fatalf("unexpected progress: got %v, want 0.5", snap.Progress)
+	}
+
+	// A sync peer announcing a greater height raises the estimate.
+	snap = p.snapshot(100, 400, false, target, now)
+	if snap.EstimatedHeight != 400 || snap.Progress != 0.25 {
+		t.Fatalf("unexpected estimate: got %d (%v), want 400 (0.25)",
+			snap.EstimatedHeight, snap.Progress)
+	}
+
+	// Received headers move the extrapolation forward.
+	p.setState(syncStateHeaders)
+	p.headersReceived(180, now.Add(-10*target))
+	snap = p.snapshot(100, 0, false, target, now)
+	if snap.State != syncStateHeaders || snap.HeadersHeight != 180 ||
+		snap.EstimatedHeight != 190 {
+		t.Fatalf("unexpected snapshot after headers: %+v", snap)
+	}
+
+	// Losing the sync peer discards the headers.
+	p.resetHeaders()
+	snap = p.snapshot(100, 0, false, target, now)
+	if snap.HeadersHeight != 100 || snap.EstimatedHeight != 200 {
+		t.Fatalf("unexpected heights after reset: got %d/%d, want "+
+			"100/200", snap.HeadersHeight, snap.EstimatedHeight)
+	}
+
+	// Blocks received over the last 10 seconds give the download speed.
+	p.setState(syncStateBlocks)
+	for i := int64(1); i <= 20; i++ {
+		p.blockReceived(100+i, now.Add(time.Duration(i-100)*target),
+			now.Add(time.Duration(i)*500*time.Millisecond))
+	}
+	now = now.Add(10 * time.Second)
+	snap = p.snapshot(120, 0, false, target, now)
+	if snap.State != syncStateBlocks || snap.HeadersHeight != 120 {
+		t.Fatalf("unexpected snapshot after blocks: %+v", snap)
+	}
+	if snap.BlocksPerSec != 20.0/9.5 {
+		t.Fatalf("unexpected download speed: got %v, want %v",
+			snap.BlocksPerSec, 20.0/9.5)
+	}
+
+	// Arrivals older than the window no longer count.
+	now = now.Add(2 * syncProgressWindow)
+	snap = p.snapshot(120, 0, true, target, now)
+	if snap.BlocksPerSec != 0 {
+		t.Fatalf("unexpected download speed: got %v, want 0",
+			snap.BlocksPerSec)
+	}
+
+	// A current chain is not extrapolated.
+	if snap.State != syncStateCurrent || snap.EstimatedHeight != 120 ||
+		snap.Progress != 1 {
+		t.Fatalf("unexpected snapshot when current: %+v", snap)
+	}
+}
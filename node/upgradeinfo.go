@@ -0,0 +1,58 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package node
+
+import (
+	"sort"
+
+	"github.com/HcashOrg/hcd/hcjson"
+)
+
+// versionTally counts how many peers, blocks or votes signal each version for
+// the getnetworkupgradeinfo RPC.
+type versionTally struct {
+	counts map[uint32]uint32
+	total  uint32
+}
+
+// newVersionTally returns an empty version tally.
+func newVersionTally() *versionTally {
+	return &versionTally{counts: make(map[uint32]uint32)}
+}
+
+// add counts one more signal of the passed version.
+func (t *versionTally) add(version uint32) {
+	t.counts[version]++
+	t.total++
+}
+
+// atLeast returns how many of the counted signals are for the passed version
+// or a later one.
+func (t *versionTally) atLeast(version uint32) uint32 {
+	var n uint32
+	for v, count := range t.counts {
+		if v >= version {
+			n += count
+		}
+	}
+	return n
+}
+
+// result returns the counted versions, latest first, along with the
+// percentage of all signals each of them represents.
+func (t *versionTally) result() []hcjson.VersionShare {
+	versions := make([]hcjson.VersionShare, 0, len(t.counts))
+	for v, count := range t.counts {
+		versions = append(versions, hcjson.VersionShare{
+			Version: v,
+			Count:   count,
+			Percent: float64(count) * 100 / float64(t.total),
+		})
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Version > versions[j].Version
+	})
+	return versions
+}
@@ -0,0 +1,49 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package node
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/HcashOrg/hcd/hcjson"
+)
+
+// TestVersionTally ensures the version tally reports the counted versions
+// latest first with their share of all signals.
+func TestVersionTally(t *testing.T) {
+	tally := newVersionTally()
+	if got := tally.result(); len(got) != 0 {
+		t.Fatalf("unexpected versions without signals: %v", got)
+	}
+
+	for _, v := range []uint32{5, 6, 6, 4, 6, 5, 6, 6} {
+		tally.add(v)
+	}
+	want := []hcjson.VersionShare{
+		{Version: 6, Count: 5, Percent: 62.5},
+		{Version: 5, Count: 2, Percent: 25},
+		{Version: 4, Count: 1, Percent: 12.5},
+	}
+	if got := tally.result(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected versions: got %v, want %v", got, want)
+	}
+
+	tests := []struct {
+		version uint32
+		want    uint32
+	}{
+		{3, 8},
+		{5, 7},
+		{6, 5},
+		{7, 0},
+	}
+	for _, test := range tests {
+		if got := tally.atLeast(test.version); got != test.want {
+			t.Errorf("atLeast(%d): got %d, want %d", test.version,
+				got, test.want)
+		}
+	}
+}
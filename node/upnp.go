@@ -1,4 +1,4 @@
-package main
+package node
 
 // Upnp code taken from Taipei Torrent license is below:
 // Copyright (c) 2010 Jack Palevich. All rights reserved.
@@ -229,7 +229,7 @@ func getServiceURL(rootURL string) (url string, err error) {
 	}
 	defer r.Body.Close()
 	if r.StatusCode >= 400 {
-		err = errors.New(string(r.StatusCode))
+		err = errors.New(r.Status)
 		return
 	}
 	var root root
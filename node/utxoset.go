@@ -0,0 +1,168 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package node
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/HcashOrg/hcd/blockchain"
+	"github.com/HcashOrg/hcd/chaincfg/chainhash"
+)
+
+const (
+	// maxUtxoSetCursors is the maximum number of getutxoset cursors which
+	// may be open at the same time.  Each of them holds a snapshot of the
+	// database.
+	maxUtxoSetCursors = 4
+
+	// maxUtxoSetCount is the maximum number of outputs a single getutxoset
+	// call returns.
+	maxUtxoSetCount = 10000
+
+	// utxoSetCursorTimeout is how long a getutxoset cursor may go unused
+	// before it is closed.
+	utxoSetCursorTimeout = 5 * time.Minute
+)
+
+var (
+	// errUtxoSetCursorLimit is returned when a new getutxoset cursor is
+	// requested while the maximum number of them is open.
+	errUtxoSetCursorLimit = errors.New("too many open utxo set cursors")
+
+	// errUtxoSetCursorUnknown is returned when a getutxoset cursor does not
+	// exist, either because it was never opened, or because it was done or
+	// unused for too long and was closed.
+	errUtxoSetCursorUnknown = errors.New("unknown or expired utxo set cursor")
+)
+
+// utxoSetCursor is an open iteration over the utxo set for the getutxoset
+// RPC.
+type utxoSetCursor struct {
+	mtx   sync.Mutex
+	iter  *blockchain.UtxoSetIterator
+	timer *time.Timer
+}
+
+// utxoSetCursors tracks the open getutxoset cursors by their ID.  Cursors are
+// closed once the iteration is done, after they went unused for the timeout
+// and when the RPC server shuts down.
+//
+// The cursors are safe for concurrent access.
+type utxoSetCursors struct {
+	mtx     sync.Mutex
+	cursors map[string]*utxoSetCursor
+	closed  bool
+}
+
+// newUtxoSetCursors returns an empty set of getutxoset cursors.
+func newUtxoSetCursors() *utxoSetCursors {
+	return &utxoSetCursors{cursors: make(map[string]*utxoSetCursor)}
+}
+
+// open registers a cursor for the passed iterator and returns its ID.  The
+// iterator is closed when the cursor can not be registered.
+func (c *utxoSetCursors) open(iter *blockchain.UtxoSetIterator) (string, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if c.closed || len(c.cursors) >= maxUtxoSetCursors {
+		iter.Close()
+		return "", errUtxoSetCursorLimit
+	}
+
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		iter.Close()
+		return "", err
+	}
+	id := hex.EncodeToString(b[:])
+	c.cursors[id] = &utxoSetCursor{
+		iter:  iter,
+		timer: time.AfterFunc(utxoSetCursorTimeout, func() { c.close(id) }),
+	}
+	return id, nil
+}
+
+// utxoSetPage is a batch of outputs returned by a getutxoset cursor along with
+// the best block the cursor iterates the utxo set of.
+type utxoSetPage struct {
+	outputs []blockchain.UtxoSetOutput
+	hash    chainhash.Hash
+	height  int64
+	done    bool
+}
+
+// next returns up to count of the next outputs of the cursor with the passed
+// ID.  The cursor is closed once there are no more outputs.
+func (c *utxoSetCursors) next(id string, count int) (*utxoSetPage, error) {
+	c.mtx.Lock()
+	cursor, ok := c.cursors[id]
+	c.mtx.Unlock()
+	if !ok {
+		return nil, errUtxoSetCursorUnknown
+	}
+
+	cursor.mtx.Lock()
+	if !cursor.timer.Stop() {
+		// The cursor expired in the meantime.
+		cursor.mtx.Unlock()
+		return nil, errUtxoSetCursorUnknown
+	}
+	outputs, err := cursor.iter.Next(count)
+	page := &utxoSetPage{
+		outputs: outputs,
+		hash:    cursor.iter.Hash(),
+		height:  cursor.iter.Height(),
+		done:    err != nil || len(outputs) < count,
+	}
+	if !page.done {
+		cursor.timer.Reset(utxoSetCursorTimeout)
+	}
+	cursor.mtx.Unlock()
+	if page.done {
+		c.close(id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// close closes the cursor with the passed ID when it is open.
+func (c *utxoSetCursors) close(id string) {
+	c.mtx.Lock()
+	cursor, ok := c.cursors[id]
+	delete(c.cursors, id)
+	c.mtx.Unlock()
+	if !ok {
+		return
+	}
+
+	cursor.mtx.Lock()
+	cursor.timer.Stop()
+	if err := cursor.iter.Close(); err != nil {
+		rpcsLog.Errorf("Failed to close utxo set cursor: %v", err)
+	}
+	cursor.mtx.Unlock()
+}
+
+// closeAll closes every open cursor and prevents new ones from being opened.
+func (c *utxoSetCursors) closeAll() {
+	c.mtx.Lock()
+	c.closed = true
+	ids := make([]string, 0, len(c.cursors))
+	for id := range c.cursors {
+		ids = append(ids, id)
+	}
+	c.mtx.Unlock()
+
+	for _, id := range ids {
+		c.close(id)
+	}
+}
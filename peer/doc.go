@@ -74,6 +74,25 @@ Disconnect to disconnect from the peer and clean up all resources.
 WaitForDisconnect can be used to block until peer disconnection and resource
 cleanup has completed.
 
+Peers which do not complete the handshake in a timely manner are disconnected.
+The remote peer must send its version message before any other message and its
+verack message within a short timeout.  Until the verack message is received,
+only a limited number of small messages are accepted, and the payload size of
+each message is checked before its payload is read.
+
+Feature Negotiation
+
+Optional protocol features, such as new message types, are negotiated during
+the handshake instead of requiring a new protocol version for each of them.
+The Features field of the Config struct lists the features the local peer
+supports along with their versions.  When the negotiated protocol version is at
+least wire.FeatureNegotiationVersion, a sendfeature message is sent for each of
+them before the verack message.  Once the remote peer's verack message has been
+received, the Feature function reports whether a feature is enabled, which is
+the case when both peers advertised it, and the lower of the two versions to
+use.  Remote peers which advertise features after their verack message are
+disconnected.
+
 Callbacks
 
 In order to do anything useful with a peer, it is necessary to react to HC
@@ -107,6 +126,15 @@ QueueInventory function.  It employs batching and trickling along with
 intelligent known remote peer inventory detection and avoidance through the use
 of a most-recently used algorithm.
 
+Transaction inventory is trickled in batches sent at random, exponentially
+distributed intervals, so the announcements to each peer follow a Poisson
+process whose average interval is set by the TrickleInterval and
+InboundTrickleInterval configuration fields.  This hides the order in which a
+transaction reached the peers of a node, which would otherwise help locate the
+node it originated from, and saves the overhead of announcing transactions one
+message at a time.  Block inventory is sent right away since delaying it would
+only slow down block propagation.
+
 Message Sending Helper Functions
 
 In addition to the bare QueueMessage function previously described, the
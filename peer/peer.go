@@ -8,12 +8,14 @@ package peer
 
 import (
 	"bytes"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
 	"math/rand"
 	"container/list"
 	"net"
+	"sort"
 	"strconv"
 	"sync"
 	"sync/atomic"
@@ -29,7 +31,7 @@ import (
 
 const (
 	// MaxProtocolVersion is the max protocol version the peer supports.
-	MaxProtocolVersion = wire.FeeFilterVersion
+	MaxProtocolVersion = wire.FeatureNegotiationVersion
 
 	// outputBufferSize is the number of elements the output channels use.
 	outputBufferSize = 5000
@@ -50,6 +52,18 @@ const (
 	// peer that hasn't completed the initial version negotiation.
 	negotiateTimeout = 30 * time.Second
 
+	// maxPreVerAckPayload is the maximum payload size of the messages
+	// accepted from a peer before it sent its verack message.  It is
+	// checked before the payload is read, so peers which did not complete
+	// the handshake can't make us allocate the buffers for large messages
+	// such as blocks.
+	maxPreVerAckPayload = 64 * 1024
+
+	// maxPreVerAckMsgs is the maximum number of messages, including the
+	// version message, accepted from a peer before it sent its verack
+	// message.
+	maxPreVerAckMsgs = 50
+
 	// idleTimeout is the duration of inactivity before we time out a peer.
 	idleTimeout = 5 * time.Minute
 
@@ -63,9 +77,19 @@ const (
 	// only checked on each stall tick interval.
 	stallResponseTimeout = 30 * time.Second
 
-	// trickleTimeout is the duration of the ticker which trickles down the
-	// inventory to a peer.
-	trickleTimeout = 500 * time.Millisecond
+	// DefaultTrickleInterval is the default average interval between the
+	// batches of transaction inventory trickled to outbound peers.
+	DefaultTrickleInterval = 500 * time.Millisecond
+
+	// DefaultInboundTrickleInterval is the default average interval between
+	// the batches of transaction inventory trickled to inbound peers.  It is
+	// longer than the outbound interval since a node connecting to many
+	// peers to learn where transactions originate connects inbound.
+	DefaultInboundTrickleInterval = 2 * DefaultTrickleInterval
+
+	// maxRemoteFeatures is the maximum number of features a remote peer may
+	// advertise with sendfeature messages.
+	maxRemoteFeatures = 64
 )
 
 var (
@@ -103,6 +127,9 @@ type MessageListeners struct {
 	// OnAddr is invoked when a peer receives an addr wire message.
 	OnAddr func(p *Peer, msg *wire.MsgAddr)
 
+	// OnAddrV2 is invoked when a peer receives an addrv2 wire message.
+	OnAddrV2 func(p *Peer, msg *wire.MsgAddrV2)
+
 	// OnPing is invoked when a peer receives a ping wire message.
 	OnPing func(p *Peer, msg *wire.MsgPing)
 
@@ -175,6 +202,10 @@ type MessageListeners struct {
 	// message.
 	OnSendHeaders func(p *Peer, msg *wire.MsgSendHeaders)
 
+	// OnSendFeature is invoked when a peer receives a sendfeature wire
+	// message.
+	OnSendFeature func(p *Peer, msg *wire.MsgSendFeature)
+
 	// OnRead is invoked when a peer receives a wire message.  It consists
 	// of the number of bytes read, the message, and whether or not an error
 	// in the read occurred.  Typically, callers will opt to use the
@@ -237,6 +268,25 @@ type Config struct {
 	// not send inv messages for transactions.
 	DisableRelayTx bool
 
+	// Features specifies the optional protocol features to advertise to
+	// remote peers which support feature negotiation, keyed by feature name
+	// with the highest supported version of each feature as the value.  See
+	// Peer.Feature for the features enabled for a connection.
+	Features map[string]uint32
+
+	// TrickleInterval specifies the average interval between the batches of
+	// transaction inventory sent to an outbound peer.  The intervals are
+	// randomized so announcements follow a Poisson process, which makes it
+	// harder to tell from their timing which peer first relayed a
+	// transaction.  Block inventory is sent without delay.  This field can
+	// be omitted in which case DefaultTrickleInterval will be used.
+	TrickleInterval time.Duration
+
+	// InboundTrickleInterval is the same as TrickleInterval for inbound
+	// peers.  This field can be omitted in which case
+	// DefaultInboundTrickleInterval will be used.
+	InboundTrickleInterval time.Duration
+
 	// Listeners houses callback functions to be invoked on receiving peer
 	// messages.
 	Listeners MessageListeners
@@ -323,6 +373,13 @@ type stallControlMsg struct {
 	message wire.Message
 }
 
+// MsgStats houses the number of messages of a single wire command sent to or
+// received from a peer along with their total size in bytes.
+type MsgStats struct {
+	Count uint64
+	Bytes uint64
+}
+
 // StatsSnap is a snapshot of peer stats at a point in time.
 type StatsSnap struct {
 	ID             int32
@@ -342,6 +399,9 @@ type StatsSnap struct {
 	LastPingNonce  uint64
 	LastPingTime   time.Time
 	LastPingMicros int64
+	SentPerMsg     map[string]MsgStats
+	RecvPerMsg     map[string]MsgStats
+	Features       map[string]uint32
 }
 
 // HashFunc is a function which returns a block hash, height and error
@@ -395,9 +455,10 @@ type Peer struct {
 
 	// These fields are set at creation time and never modified, so they are
 	// safe to read from concurrently without a mutex.
-	addr    string
-	cfg     Config
-	inbound bool
+	addr            string
+	cfg             Config
+	inbound         bool
+	trickleInterval time.Duration
 
 	flagsMtx             sync.Mutex // protects the peer flags below
 	na                   *wire.NetAddress
@@ -410,6 +471,7 @@ type Peer struct {
 	sendHeadersPreferred bool   // peer sent a sendheaders message
 	versionSent          bool
 	verAckReceived       bool
+	remoteFeatures       map[string]uint32 // features advertised by remote
 
 	knownInventory     *mruInventoryMap
 	prevGetBlocksMtx   sync.Mutex
@@ -431,6 +493,13 @@ type Peer struct {
 	lastPingTime       time.Time // Time we sent last ping.
 	lastPingMicros     int64     // Time for last ping to return.
 
+	// These fields keep track of the messages sent to and received from
+	// the peer per wire command and are protected by the msgStatsMtx
+	// mutex.
+	msgStatsMtx sync.Mutex
+	sentPerMsg  map[string]MsgStats
+	recvPerMsg  map[string]MsgStats
+
 	stallControl  chan stallControlMsg
 	outputQueue   chan outMsg
 	sendQueue     chan outMsg
@@ -440,6 +509,10 @@ type Peer struct {
 	queueQuit     chan struct{}
 	outQuit       chan struct{}
 	quit          chan struct{}
+
+	// preVerAckMsgs is the number of messages received before the verack
+	// message.  It is only accessed by the goroutine reading messages.
+	preVerAckMsgs int
 }
 
 // String returns the peer's address and directionality as a human-readable
@@ -497,6 +570,7 @@ func (p *Peer) StatsSnapshot() *StatsSnap {
 	userAgent := p.userAgent
 	services := p.services
 	protocolVersion := p.advertisedProtoVer
+	features := p.negotiatedFeatures()
 	p.flagsMtx.Unlock()
 
 	// Get a copy of all relevant flags and stats.
@@ -518,12 +592,38 @@ func (p *Peer) StatsSnapshot() *StatsSnap {
 		LastPingNonce:  p.lastPingNonce,
 		LastPingMicros: p.lastPingMicros,
 		LastPingTime:   p.lastPingTime,
+		Features:       features,
 	}
 
 	p.statsMtx.RUnlock()
+
+	p.msgStatsMtx.Lock()
+	statsSnap.SentPerMsg = make(map[string]MsgStats, len(p.sentPerMsg))
+	for command, stats := range p.sentPerMsg {
+		statsSnap.SentPerMsg[command] = stats
+	}
+	statsSnap.RecvPerMsg = make(map[string]MsgStats, len(p.recvPerMsg))
+	for command, stats := range p.recvPerMsg {
+		statsSnap.RecvPerMsg[command] = stats
+	}
+	p.msgStatsMtx.Unlock()
+
 	return statsSnap
 }
 
+// addMsgStats accounts for a message of the passed wire command and size in
+// the passed per message statistics.
+//
+// This function is safe for concurrent access.
+func (p *Peer) addMsgStats(perMsg map[string]MsgStats, command string, n int) {
+	p.msgStatsMtx.Lock()
+	stats := perMsg[command]
+	stats.Count++
+	stats.Bytes += uint64(n)
+	perMsg[command] = stats
+	p.msgStatsMtx.Unlock()
+}
+
 // ID returns the peer id.
 //
 // This function is safe for concurrent access.
@@ -750,6 +850,44 @@ func (p *Peer) WantsHeaders() bool {
 	return sendHeadersPreferred
 }
 
+// Feature returns the version of the named optional protocol feature enabled
+// for the connection and whether it is enabled at all.  A feature is enabled
+// when both the local peer, through Config.Features, and the remote peer
+// advertised it, and its version is the lower of the two advertised versions.
+//
+// The remote peer's features are only known once its verack message has been
+// received, so callers should not rely on this before the OnVerAck callback.
+//
+// This function is safe for concurrent access.
+func (p *Peer) Feature(name string) (uint32, bool) {
+	localVersion, ok := p.cfg.Features[name]
+	if !ok {
+		return 0, false
+	}
+
+	p.flagsMtx.Lock()
+	remoteVersion, ok := p.remoteFeatures[name]
+	p.flagsMtx.Unlock()
+	if !ok {
+		return 0, false
+	}
+	return minUint32(localVersion, remoteVersion), true
+}
+
+// negotiatedFeatures returns the features enabled for the connection keyed by
+// name along with their versions.  See Feature for details.
+//
+// This function MUST be called with the flags mutex held.
+func (p *Peer) negotiatedFeatures() map[string]uint32 {
+	features := make(map[string]uint32)
+	for name, remoteVersion := range p.remoteFeatures {
+		if localVersion, ok := p.cfg.Features[name]; ok {
+			features[name] = minUint32(localVersion, remoteVersion)
+		}
+	}
+	return features
+}
+
 // localVersionMsg creates a version message that can be used to send to the
 // remote peer.
 func (p *Peer) localVersionMsg() (*wire.MsgVersion, error) {
@@ -867,6 +1005,42 @@ func (p *Peer) PushAddrMsg(addresses []*wire.NetAddress) ([]*wire.NetAddress, er
 	return msg.AddrList, nil
 }
 
+// PushAddrV2Msg sends an addrv2 message to the connected peer using the
+// provided addresses.  It behaves like PushAddrMsg, but may only be used with
+// peers which negotiated a protocol version of wire.AddrV2Version or later.
+//
+// This function is safe for concurrent access.
+func (p *Peer) PushAddrV2Msg(addresses []*wire.NetAddressV2) ([]*wire.NetAddressV2, error) {
+	if p.ProtocolVersion() < wire.AddrV2Version {
+		return nil, fmt.Errorf("peer %s does not support addrv2 "+
+			"messages", p)
+	}
+
+	// Nothing to send.
+	if len(addresses) == 0 {
+		return nil, nil
+	}
+
+	msg := wire.NewMsgAddrV2()
+	msg.AddrList = make([]*wire.NetAddressV2, len(addresses))
+	copy(msg.AddrList, addresses)
+
+	// Randomize the addresses sent if there are more than the maximum allowed.
+	if len(msg.AddrList) > wire.MaxAddrPerMsg {
+		// Shuffle the address list.
+		for i := range msg.AddrList {
+			j := rand.Intn(i + 1)
+			msg.AddrList[i], msg.AddrList[j] = msg.AddrList[j], msg.AddrList[i]
+		}
+
+		// Truncate it to the maximum size.
+		msg.AddrList = msg.AddrList[:wire.MaxAddrPerMsg]
+	}
+
+	p.QueueMessage(msg, nil)
+	return msg.AddrList, nil
+}
+
 // PushGetBlocksMsg sends a getblocks message for the provided block locator
 // and stop hash.  It will ignore back-to-back duplicate requests.
 //
@@ -1049,6 +1223,34 @@ func (p *Peer) handleRemoteVersionMsg(msg *wire.MsgVersion) error {
 	return nil
 }
 
+// handleSendFeatureMsg is invoked when a peer receives a sendfeature wire
+// message.  It records the feature advertised by the remote peer and returns
+// an error when the message violates the feature negotiation rules.
+func (p *Peer) handleSendFeatureMsg(msg *wire.MsgSendFeature) error {
+	p.flagsMtx.Lock()
+	defer p.flagsMtx.Unlock()
+
+	// Features are only negotiated before the verack message so they are
+	// known to both peers before any other message is exchanged.
+	if p.verAckReceived {
+		return fmt.Errorf("received sendfeature %q after verack",
+			msg.Name)
+	}
+	if _, ok := p.remoteFeatures[msg.Name]; ok {
+		return fmt.Errorf("received duplicate sendfeature %q", msg.Name)
+	}
+	if len(p.remoteFeatures) >= maxRemoteFeatures {
+		return fmt.Errorf("received more than %d sendfeature messages",
+			maxRemoteFeatures)
+	}
+
+	if p.remoteFeatures == nil {
+		p.remoteFeatures = make(map[string]uint32)
+	}
+	p.remoteFeatures[msg.Name] = msg.Version
+	return nil
+}
+
 // handlePingMsg is invoked when a peer receives a ping wire message.  For
 // recent clients (protocol version > BIP0031Version), it replies with a pong
 // message.  For older clients, it does nothing and anything other than failure
@@ -1079,9 +1281,54 @@ func (p *Peer) handlePongMsg(msg *wire.MsgPong) {
 	p.statsMtx.Unlock()
 }
 
+// readPreVerAckHeader reads the header of the next message from a peer which
+// did not send its verack message yet and ensures the number of messages
+// received before the verack message and the payload size do not exceed the
+// limits before the payload is read.  It returns a reader which yields the
+// full message along with the number of bytes read when the header is
+// rejected.
+func (p *Peer) readPreVerAckHeader() (io.Reader, int, error) {
+	p.preVerAckMsgs++
+	if p.preVerAckMsgs > maxPreVerAckMsgs {
+		return nil, 0, fmt.Errorf("received more than %d messages "+
+			"before verack", maxPreVerAckMsgs)
+	}
+
+	var hdr [wire.MessageHeaderSize]byte
+	n, err := io.ReadFull(p.conn, hdr[:])
+	if err != nil {
+		return nil, n, err
+	}
+
+	// The payload length follows the network magic and the command.
+	command := hdr[4 : 4+wire.CommandSize]
+	length := binary.LittleEndian.Uint32(hdr[4+wire.CommandSize:])
+	if length > maxPreVerAckPayload {
+		return nil, n, fmt.Errorf("received %q message with payload of "+
+			"%d bytes before verack, which exceeds the limit of %d "+
+			"bytes", bytes.TrimRight(command, "\x00"), length,
+			maxPreVerAckPayload)
+	}
+	return io.MultiReader(bytes.NewReader(hdr[:]), p.conn), 0, nil
+}
+
 // readMessage reads the next wire message from the peer with logging.
 func (p *Peer) readMessage() (wire.Message, []byte, error) {
-	n, msg, buf, err := wire.ReadMessageN(p.conn, p.ProtocolVersion(),
+	// Limit the messages a peer which did not complete the handshake may
+	// send.  No read lock is necessary because verAckReceived is only
+	// written to by the goroutine reading messages.
+	var r io.Reader = p.conn
+	if !p.verAckReceived {
+		var n int
+		var err error
+		r, n, err = p.readPreVerAckHeader()
+		if err != nil {
+			atomic.AddUint64(&p.bytesReceived, uint64(n))
+			return nil, nil, err
+		}
+	}
+
+	n, msg, buf, err := wire.ReadMessageN(r, p.ProtocolVersion(),
 		p.cfg.ChainParams.Net)
 	atomic.AddUint64(&p.bytesReceived, uint64(n))
 	if p.cfg.Listeners.OnRead != nil {
@@ -1090,6 +1337,7 @@ func (p *Peer) readMessage() (wire.Message, []byte, error) {
 	if err != nil {
 		return nil, nil, err
 	}
+	p.addMsgStats(p.recvPerMsg, msg.Command(), n)
 
 	// Use closures to log expensive operations so they are only run when
 	// the logging level requires it.
@@ -1150,6 +1398,9 @@ func (p *Peer) writeMessage(msg wire.Message) error {
 	if p.cfg.Listeners.OnWrite != nil {
 		p.cfg.Listeners.OnWrite(p, n, msg, err)
 	}
+	if err == nil {
+		p.addMsgStats(p.sentPerMsg, msg.Command(), n)
+	}
 	return err
 }
 
@@ -1371,9 +1622,16 @@ cleanup:
 // inHandler handles all incoming messages for the peer.  It must be run as a
 // goroutine.
 func (p *Peer) inHandler() {
-	// Peers must complete the initial version negotiation within a shorter
-	// timeframe than a general idle timeout.  The timer is then reset below
-	// to idleTimeout for all future messages.
+	// Peers must complete the handshake by sending their verack message
+	// within a shorter timeframe than a general idle timeout.
+	verAckTimer := time.AfterFunc(negotiateTimeout, func() {
+		log.Warnf("Peer %s did not send verack within %s -- "+
+			"disconnecting", p, negotiateTimeout)
+		p.Disconnect()
+	})
+	defer verAckTimer.Stop()
+
+	// The idle timer is reset below to idleTimeout for all future messages.
 	idleTimer := time.AfterFunc(idleTimeout, func() {
 		log.Warnf("Peer %s no answer for %s -- disconnecting", p, idleTimeout)
 		p.Disconnect()
@@ -1426,6 +1684,7 @@ out:
 					"disconnecting", p)
 				break out
 			}
+			verAckTimer.Stop()
 			p.flagsMtx.Lock()
 			p.verAckReceived = true
 			p.flagsMtx.Unlock()
@@ -1443,6 +1702,11 @@ out:
 				p.cfg.Listeners.OnAddr(p, msg)
 			}
 
+		case *wire.MsgAddrV2:
+			if p.cfg.Listeners.OnAddrV2 != nil {
+				p.cfg.Listeners.OnAddrV2(p, msg)
+			}
+
 		case *wire.MsgPing:
 			p.handlePingMsg(msg)
 			if p.cfg.Listeners.OnPing != nil {
@@ -1549,6 +1813,16 @@ out:
 				p.cfg.Listeners.OnSendHeaders(p, msg)
 			}
 
+		case *wire.MsgSendFeature:
+			if err := p.handleSendFeatureMsg(msg); err != nil {
+				log.Infof("%v -- disconnecting peer %v", err, p)
+				break out
+			}
+
+			if p.cfg.Listeners.OnSendFeature != nil {
+				p.cfg.Listeners.OnSendFeature(p, msg)
+			}
+
 		default:
 			log.Debugf("Received unhandled message of type %v "+
 				"from %v", rmsg.Command(), p)
@@ -1584,8 +1858,8 @@ func (p *Peer) queueHandler() {
 	//invSendQueue := list.New()
 	var pendingMsgs []outMsg
 	var invSendQueue []*wire.InvVect
-	trickleTicker := time.NewTicker(trickleTimeout)
-	defer trickleTicker.Stop()
+	trickleTimer := time.NewTimer(p.trickleDelay())
+	defer trickleTimer.Stop()
 
 	// We keep the waiting flag so that we know if we have a message queued
 	// to the outHandler or not.  We could use the presence of a head of
@@ -1637,12 +1911,31 @@ out:
 
 		case iv := <-p.outputInvChan:
 			// No handshake?  They'll find out soon enough.
-			if p.VersionKnown() {
-				//invSendQueue.PushBack(iv)
-				invSendQueue = append(invSendQueue, iv)
+			if !p.VersionKnown() {
+				continue
+			}
+
+			// Blocks are announced right away since delaying them
+			// only slows down their propagation.
+			if iv.Type == wire.InvTypeBlock {
+				if atomic.LoadInt32(&p.disconnect) != 0 ||
+					p.knownInventory.Exists(iv) {
+					continue
+				}
+				invMsg := wire.NewMsgInvSizeHint(1)
+				invMsg.AddInvVect(iv)
+				waiting = queuePacket(outMsg{msg: invMsg},
+					&pendingMsgs, waiting)
+				p.AddKnownInventory(iv)
+				continue
 			}
 
-		case <-trickleTicker.C:
+			//invSendQueue.PushBack(iv)
+			invSendQueue = append(invSendQueue, iv)
+
+		case <-trickleTimer.C:
+			trickleTimer.Reset(p.trickleDelay())
+
 			// Don't send anything if we're disconnecting or there
 			// is no queued inventory.
 			// version is known if send queue has any entries.
@@ -1835,8 +2128,16 @@ func (p *Peer) QueueMessage(msg wire.Message, doneChan chan<- struct{}) {
 	p.outputQueue <- outMsg{msg: msg, doneChan: doneChan}
 }
 
+// trickleDelay returns a random delay until the next batch of inventory is
+// trickled to the peer.  The delays are exponentially distributed around the
+// trickle interval of the peer, so the batches are sent as a Poisson process.
+func (p *Peer) trickleDelay() time.Duration {
+	return time.Duration(rand.ExpFloat64() * float64(p.trickleInterval))
+}
+
 // QueueInventory adds the passed inventory to the inventory send queue which
-// might not be sent right away, rather it is trickled to the peer in batches.
+// might not be sent right away, rather it is trickled to the peer in batches
+// at random intervals.  Block inventory is sent as soon as possible.
 // Inventory that the peer is already known to have is ignored.
 //
 // This function is safe for concurrent access.
@@ -1918,7 +2219,9 @@ func (p *Peer) Disconnect() {
 func (p *Peer) start() error {
 	log.Tracef("Starting peer %s", p)
 
-	negotiateErr := make(chan error)
+	// The channel is buffered so the negotiation goroutine does not block
+	// forever when the negotiation times out.
+	negotiateErr := make(chan error, 1)
 	go func() {
 		if p.inbound {
 			negotiateErr <- p.negotiateInboundProtocol()
@@ -1945,6 +2248,21 @@ func (p *Peer) start() error {
 	go p.queueHandler()
 	go p.outHandler()
 
+	// Advertise the optional features the local peer supports when the
+	// remote peer is able to negotiate them.  They must be sent before the
+	// verack message.
+	if p.ProtocolVersion() >= wire.FeatureNegotiationVersion {
+		names := make([]string, 0, len(p.cfg.Features))
+		for name := range p.cfg.Features {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			msg := wire.NewMsgSendFeature(name, p.cfg.Features[name])
+			p.QueueMessage(msg, nil)
+		}
+	}
+
 	// Send our verack message now that the IO processing machinery has started.
 	p.QueueMessage(wire.NewMsgVerAck(), nil)
 	return nil
@@ -1974,9 +2292,15 @@ func (p *Peer) readRemoteVersionMsg() error {
 		errStr := "A version message must precede all others"
 		log.Errorf(errStr)
 
+		// Send a reject message and disconnect the peer regardless of
+		// whether it could be sent.
 		rejectMsg := wire.NewMsgReject(msg.Command(), wire.RejectMalformed,
 			errStr)
-		return p.writeMessage(rejectMsg)
+		if err := p.writeMessage(rejectMsg); err != nil {
+			return err
+		}
+		return fmt.Errorf("received %s message before version",
+			msg.Command())
 	}
 
 	if err := p.handleRemoteVersionMsg(remoteVerMsg); err != nil {
@@ -2044,8 +2368,28 @@ func newPeerBase(cfg *Config, inbound bool) *Peer {
 		cfg.ChainParams = &chaincfg.TestNet2Params
 	}
 
+	// Use the trickle interval configured for the direction of the
+	// connection, or the default one.
+	trickleInterval := cfg.TrickleInterval
+	if trickleInterval <= 0 {
+		trickleInterval = DefaultTrickleInterval
+	}
+	if inbound {
+		trickleInterval = cfg.InboundTrickleInterval
+		if trickleInterval <= 0 {
+			trickleInterval = DefaultInboundTrickleInterval
+		}
+	}
+
+	// Copy the advertised features so the caller can't mutate them.
+	features := make(map[string]uint32, len(cfg.Features))
+	for name, version := range cfg.Features {
+		features[name] = version
+	}
+
 	p := Peer{
 		inbound:         inbound,
+		trickleInterval: trickleInterval,
 		knownInventory:  newMruInventoryMap(maxKnownInventory),
 		stallControl:    make(chan stallControlMsg, 1), // nonblocking sync
 		outputQueue:     make(chan outMsg, outputBufferSize),
@@ -2059,7 +2403,10 @@ func newPeerBase(cfg *Config, inbound bool) *Peer {
 		cfg:             *cfg, // Copy so caller can't mutate.
 		services:        cfg.Services,
 		protocolVersion: protocolVersion,
+		sentPerMsg:      make(map[string]MsgStats),
+		recvPerMsg:      make(map[string]MsgStats),
 	}
+	p.cfg.Features = features
 	return &p
 }
 
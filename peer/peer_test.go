@@ -10,7 +10,9 @@ import (
 	"encoding/binary"
 	"errors"
 	"io"
+	"io/ioutil"
 	"net"
+	"reflect"
 	"strconv"
 	"sync"
 	"testing"
@@ -241,8 +243,8 @@ func TestPeerConnection(t *testing.T) {
 		wantLastPingNonce:   uint64(0),
 		wantLastPingMicros:  int64(0),
 		wantTimeOffset:      int64(0),
-		wantBytesSent:       158, // 134 version + 24 verack
-		wantBytesReceived:   158,
+		wantBytesSent:       157, // 133 version + 24 verack
+		wantBytesReceived:   157,
 	}
 	tests := []struct {
 		name  string
@@ -506,14 +508,6 @@ func TestPeerListeners(t *testing.T) {
 			"OnFilterLoad",
 			wire.NewMsgFilterLoad([]byte{0x01}, 10, 0, wire.BloomUpdateNone),
 		},
-		{
-			"OnMerkleBlock",
-			wire.NewMsgMerkleBlock(wire.NewBlockHeader(0,
-				&chainhash.Hash{}, &chainhash.Hash{},
-				&chainhash.Hash{}, 1, [6]byte{},
-				1, 1, 1, 1, 1, 1, 1, 1, 1, [32]byte{},
-				binary.LittleEndian.Uint32([]byte{0xb0, 0x1d, 0xfa, 0xce}))),
-		},
 		// only one version message is allowed
 		// only one verack message is allowed
 		{
@@ -540,6 +534,308 @@ func TestPeerListeners(t *testing.T) {
 	outPeer.Disconnect()
 }
 
+// TestPeerFeatures tests the negotiation of optional features between
+// inbound and outbound peers.
+func TestPeerFeatures(t *testing.T) {
+	// connectPeers connects an inbound and an outbound peer advertising the
+	// passed features with the passed protocol versions and waits for both
+	// to receive a verack.
+	connectPeers := func(inFeatures, outFeatures map[string]uint32,
+		inPver, outPver uint32) (*peer.Peer, *peer.Peer, error) {
+
+		verack := make(chan struct{}, 2)
+		peerCfg := &peer.Config{
+			Listeners: peer.MessageListeners{
+				OnVerAck: func(p *peer.Peer, msg *wire.MsgVerAck) {
+					verack <- struct{}{}
+				},
+			},
+			UserAgentName:    "peer",
+			UserAgentVersion: "1.0",
+			ChainParams:      &chaincfg.MainNetParams,
+		}
+		inConn, outConn := pipe(
+			&conn{raddr: "10.0.0.1:8333"},
+			&conn{raddr: "10.0.0.2:8333"},
+		)
+		inCfg, outCfg := *peerCfg, *peerCfg
+		inCfg.Features, inCfg.ProtocolVersion = inFeatures, inPver
+		outCfg.Features, outCfg.ProtocolVersion = outFeatures, outPver
+
+		inPeer := peer.NewInboundPeer(&inCfg)
+		inPeer.AssociateConnection(inConn)
+		outPeer, err := peer.NewOutboundPeer(&outCfg, "10.0.0.2:8333")
+		if err != nil {
+			return nil, nil, err
+		}
+		outPeer.AssociateConnection(outConn)
+
+		for i := 0; i < 2; i++ {
+			select {
+			case <-verack:
+			case <-time.After(time.Second):
+				return nil, nil, errors.New("verack timeout")
+			}
+		}
+		return inPeer, outPeer, nil
+	}
+
+	inFeatures := map[string]uint32{"cfilters": 2, "votebatch": 1}
+	outFeatures := map[string]uint32{"cfilters": 1, "other": 1}
+	tests := []struct {
+		name   string
+		pver   uint32
+		want   map[string]uint32
+		absent []string
+	}{
+		{
+			name:   "feature negotiation",
+			pver:   peer.MaxProtocolVersion,
+			want:   map[string]uint32{"cfilters": 1},
+			absent: []string{"votebatch", "other", "unknown"},
+		},
+		{
+			name:   "before feature negotiation",
+			pver:   wire.FeatureNegotiationVersion - 1,
+			want:   map[string]uint32{},
+			absent: []string{"cfilters", "votebatch", "other"},
+		},
+	}
+
+	for _, test := range tests {
+		inPeer, outPeer, err := connectPeers(inFeatures, outFeatures,
+			peer.MaxProtocolVersion, test.pver)
+		if err != nil {
+			t.Errorf("%s: unexpected err %v", test.name, err)
+			continue
+		}
+
+		for _, p := range []*peer.Peer{inPeer, outPeer} {
+			for name, wantVersion := range test.want {
+				version, ok := p.Feature(name)
+				if !ok || version != wantVersion {
+					t.Errorf("%s: %s: feature %q - got %d %v, "+
+						"want %d", test.name, p, name, version,
+						ok, wantVersion)
+				}
+			}
+			for _, name := range test.absent {
+				if _, ok := p.Feature(name); ok {
+					t.Errorf("%s: %s: feature %q unexpectedly "+
+						"enabled", test.name, p, name)
+				}
+			}
+			features := p.StatsSnapshot().Features
+			if !reflect.DeepEqual(features, test.want) {
+				t.Errorf("%s: %s: wrong snapshot features - got "+
+					"%v, want %v", test.name, p, features,
+					test.want)
+			}
+		}
+
+		inPeer.Disconnect()
+		outPeer.Disconnect()
+		inPeer.WaitForDisconnect()
+		outPeer.WaitForDisconnect()
+	}
+
+	// Features may only be advertised before the verack message.
+	inPeer, outPeer, err := connectPeers(inFeatures, outFeatures,
+		peer.MaxProtocolVersion, peer.MaxProtocolVersion)
+	if err != nil {
+		t.Fatalf("late feature: unexpected err %v", err)
+	}
+	outPeer.QueueMessage(wire.NewMsgSendFeature("late", 1), nil)
+	disconnected := make(chan struct{})
+	go func() {
+		inPeer.WaitForDisconnect()
+		close(disconnected)
+	}()
+	select {
+	case <-disconnected:
+	case <-time.After(time.Second):
+		t.Errorf("late feature: peer was not disconnected")
+	}
+	outPeer.Disconnect()
+	outPeer.WaitForDisconnect()
+}
+
+// TestPeerInventoryTrickle tests that block inventory is announced right away
+// while transaction inventory is trickled at the configured interval.
+func TestPeerInventoryTrickle(t *testing.T) {
+	// connectPeers connects an inbound peer, which reports the inventory
+	// it receives on invs, to an outbound peer trickling transaction
+	// inventory at the passed interval.
+	connectPeers := func(interval time.Duration, invs chan *wire.InvVect) (*peer.Peer, *peer.Peer, error) {
+		verack := make(chan struct{}, 2)
+		inCfg := &peer.Config{
+			Listeners: peer.MessageListeners{
+				OnVerAck: func(p *peer.Peer, msg *wire.MsgVerAck) {
+					verack <- struct{}{}
+				},
+				OnInv: func(p *peer.Peer, msg *wire.MsgInv) {
+					for _, iv := range msg.InvList {
+						invs <- iv
+					}
+				},
+			},
+			UserAgentName:    "peer",
+			UserAgentVersion: "1.0",
+			ChainParams:      &chaincfg.MainNetParams,
+		}
+		outCfg := *inCfg
+		outCfg.Listeners.OnInv = nil
+		outCfg.TrickleInterval = interval
+
+		inConn, outConn := pipe(
+			&conn{raddr: "10.0.0.1:8333"},
+			&conn{raddr: "10.0.0.2:8333"},
+		)
+		inPeer := peer.NewInboundPeer(inCfg)
+		inPeer.AssociateConnection(inConn)
+		outPeer, err := peer.NewOutboundPeer(&outCfg, "10.0.0.2:8333")
+		if err != nil {
+			return nil, nil, err
+		}
+		outPeer.AssociateConnection(outConn)
+
+		for i := 0; i < 2; i++ {
+			select {
+			case <-verack:
+			case <-time.After(time.Second):
+				return nil, nil, errors.New("verack timeout")
+			}
+		}
+		return inPeer, outPeer, nil
+	}
+
+	blockInv := wire.NewInvVect(wire.InvTypeBlock, &chainhash.Hash{0x01})
+	txInv := wire.NewInvVect(wire.InvTypeTx, &chainhash.Hash{0x02})
+
+	// With a trickle interval far longer than the test, only the block
+	// inventory may be announced.
+	invs := make(chan *wire.InvVect, 10)
+	inPeer, outPeer, err := connectPeers(time.Hour, invs)
+	if err != nil {
+		t.Fatalf("long interval: unexpected err %v", err)
+	}
+	outPeer.QueueInventory(txInv)
+	outPeer.QueueInventory(blockInv)
+	select {
+	case iv := <-invs:
+		if *iv != *blockInv {
+			t.Errorf("long interval: got inventory %v, want %v",
+				iv, blockInv)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("long interval: block inventory was not sent")
+	}
+	select {
+	case iv := <-invs:
+		t.Errorf("long interval: unexpected inventory %v", iv)
+	case <-time.After(100 * time.Millisecond):
+	}
+	inPeer.Disconnect()
+	outPeer.Disconnect()
+	inPeer.WaitForDisconnect()
+	outPeer.WaitForDisconnect()
+
+	// With a short trickle interval the transaction inventory follows.
+	invs = make(chan *wire.InvVect, 10)
+	inPeer, outPeer, err = connectPeers(time.Millisecond, invs)
+	if err != nil {
+		t.Fatalf("short interval: unexpected err %v", err)
+	}
+	outPeer.QueueInventory(txInv)
+	select {
+	case iv := <-invs:
+		if *iv != *txInv {
+			t.Errorf("short interval: got inventory %v, want %v",
+				iv, txInv)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("short interval: transaction inventory was not sent")
+	}
+	inPeer.Disconnect()
+	outPeer.Disconnect()
+	inPeer.WaitForDisconnect()
+	outPeer.WaitForDisconnect()
+}
+
+// TestPeerHandshakeLimits ensures an inbound peer is disconnected when the
+// remote peer sends another message before its version message or a large
+// message before its verack message.
+func TestPeerHandshakeLimits(t *testing.T) {
+	peerCfg := &peer.Config{
+		UserAgentName:    "peer",
+		UserAgentVersion: "1.0",
+		ChainParams:      &chaincfg.MainNetParams,
+	}
+	hcnet := chaincfg.MainNetParams.Net
+	na := wire.NewNetAddressIPPort(net.ParseIP("10.0.0.2"), 8333, 0)
+	version := wire.NewMsgVersion(na, na, 1, 0)
+
+	// Only the header of the large message is sent since it must be
+	// rejected before the payload is read.
+	var blockHdr [wire.MessageHeaderSize]byte
+	binary.LittleEndian.PutUint32(blockHdr[0:4], uint32(hcnet))
+	copy(blockHdr[4:4+wire.CommandSize], wire.CmdBlock)
+	binary.LittleEndian.PutUint32(blockHdr[4+wire.CommandSize:],
+		wire.MaxBlockPayloadV3)
+
+	tests := []struct {
+		name string
+		send func(w io.Writer) error
+	}{
+		{
+			name: "message before version",
+			send: func(w io.Writer) error {
+				return wire.WriteMessage(w, wire.NewMsgPing(1),
+					wire.ProtocolVersion, hcnet)
+			},
+		},
+		{
+			name: "large message before verack",
+			send: func(w io.Writer) error {
+				err := wire.WriteMessage(w, version,
+					wire.ProtocolVersion, hcnet)
+				if err != nil {
+					return err
+				}
+				_, err = w.Write(blockHdr[:])
+				return err
+			},
+		},
+	}
+
+	for _, test := range tests {
+		inConn, remoteConn := pipe(
+			&conn{raddr: "10.0.0.1:8333"},
+			&conn{raddr: "10.0.0.2:8333"},
+		)
+		go io.Copy(ioutil.Discard, remoteConn)
+
+		inPeer := peer.NewInboundPeer(peerCfg)
+		inPeer.AssociateConnection(inConn)
+		if err := test.send(remoteConn); err != nil {
+			t.Errorf("%s: unexpected err %v", test.name, err)
+			continue
+		}
+
+		disconnected := make(chan struct{})
+		go func() {
+			inPeer.WaitForDisconnect()
+			close(disconnected)
+		}()
+		select {
+		case <-disconnected:
+		case <-time.After(time.Second):
+			t.Errorf("%s: peer was not disconnected", test.name)
+			inPeer.Disconnect()
+		}
+	}
+}
+
 // TestOutboundPeer tests that the outbound peer works as expected.
 func TestOutboundPeer(t *testing.T) {
 	peerCfg := &peer.Config{
@@ -0,0 +1,41 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package pubsub implements a ZeroMQ style publisher of raw blocks and
+transactions over plain TCP.
+
+Publisher Overview
+
+The publisher allows indexers and other services to consume chain and memory
+pool events without holding a websocket RPC session.  Each topic is published
+on a TCP address and several topics may share the same address.  Subscribers
+connect to the address and receive every message published on the topics of
+that address.  There is no handshake and nothing is ever read from the
+subscribers.
+
+Each message consists of three frames which are each encoded as a 4-byte
+little-endian length followed by the frame data:
+
+  - The topic, for example rawblock
+  - The body of the message
+  - The 4-byte little-endian sequence number of the message on the topic
+
+The sequence numbers start at zero for each topic and allow subscribers to
+detect missed messages.  Messages are dropped for subscribers which do not
+keep up rather than delaying the publisher.
+
+Topics
+
+The following topics are supported:
+
+  - hashblock: the hash of a block connected to the main chain
+  - hashtx: the hash of a transaction accepted to the memory pool
+  - rawblock: the serialized block connected to the main chain
+  - rawtx: the serialized transaction accepted to the memory pool
+
+Hashes are published in the byte order they are displayed in by the RPC
+server, which is the reverse of the byte order of the wire protocol.
+*/
+package pubsub
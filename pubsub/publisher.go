@@ -0,0 +1,299 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package pubsub
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// These constants define the topics which can be published.
+const (
+	// TopicHashBlock is the topic of the hashes of blocks connected to the
+	// main chain.
+	TopicHashBlock = "hashblock"
+
+	// TopicHashTx is the topic of the hashes of transactions accepted to
+	// the memory pool.
+	TopicHashTx = "hashtx"
+
+	// TopicRawBlock is the topic of serialized blocks connected to the main
+	// chain.
+	TopicRawBlock = "rawblock"
+
+	// TopicRawTx is the topic of serialized transactions accepted to the
+	// memory pool.
+	TopicRawTx = "rawtx"
+)
+
+const (
+	// sendQueueSize is the maximum number of messages queued for a
+	// subscriber.  Further messages are dropped until the subscriber
+	// catches up.
+	sendQueueSize = 1000
+
+	// writeTimeout is the maximum time a write of a message to a
+	// subscriber may take before the subscriber is disconnected.
+	writeTimeout = time.Minute
+)
+
+// isValidTopic returns whether or not the passed topic can be published.
+func isValidTopic(topic string) bool {
+	switch topic {
+	case TopicHashBlock, TopicHashTx, TopicRawBlock, TopicRawTx:
+		return true
+	}
+	return false
+}
+
+// Config is a descriptor containing the publisher configuration.
+type Config struct {
+	// Topics maps each topic to publish to the TCP address it is published
+	// on.  Several topics may share the same address.
+	Topics map[string]string
+
+	// Listen defines a function used to listen on the addresses of the
+	// topics.  It defaults to net.Listen when nil.
+	Listen func(network, addr string) (net.Listener, error)
+}
+
+// subscriber is a connection to a subscriber along with the queue of messages
+// waiting to be written to it.
+type subscriber struct {
+	conn      net.Conn
+	sendQueue chan []byte
+	dropped   bool
+}
+
+// endpoint is an address the publisher listens on along with the subscribers
+// connected to it.
+type endpoint struct {
+	listener    net.Listener
+	subscribers map[*subscriber]struct{}
+}
+
+// Publisher publishes messages on topics to the subscribers connected to the
+// addresses of the topics.
+type Publisher struct {
+	started  int32
+	shutdown int32
+
+	mtx       sync.Mutex
+	endpoints []*endpoint
+	topics    map[string]*endpoint
+	sequences map[string]uint32
+
+	wg   sync.WaitGroup
+	quit chan struct{}
+}
+
+// New returns a new publisher listening on the addresses of the topics of the
+// passed configuration.  An error is returned when a topic is not known or
+// listening on an address fails.
+func New(cfg *Config) (*Publisher, error) {
+	listen := cfg.Listen
+	if listen == nil {
+		listen = net.Listen
+	}
+
+	p := &Publisher{
+		topics:    make(map[string]*endpoint),
+		sequences: make(map[string]uint32),
+		quit:      make(chan struct{}),
+	}
+	byAddr := make(map[string]*endpoint)
+	for topic, addr := range cfg.Topics {
+		if !isValidTopic(topic) {
+			p.closeListeners()
+			return nil, fmt.Errorf("unknown topic %q", topic)
+		}
+
+		e, ok := byAddr[addr]
+		if !ok {
+			listener, err := listen("tcp", addr)
+			if err != nil {
+				p.closeListeners()
+				return nil, fmt.Errorf("unable to listen on %s for "+
+					"topic %s: %v", addr, topic, err)
+			}
+			e = &endpoint{
+				listener:    listener,
+				subscribers: make(map[*subscriber]struct{}),
+			}
+			byAddr[addr] = e
+			p.endpoints = append(p.endpoints, e)
+		}
+		p.topics[topic] = e
+	}
+
+	return p, nil
+}
+
+// closeListeners closes the listeners of all endpoints.
+func (p *Publisher) closeListeners() {
+	for _, e := range p.endpoints {
+		e.listener.Close()
+	}
+}
+
+// HasTopic returns whether or not the publisher publishes the passed topic.
+// It allows callers to avoid serializing messages nobody is interested in.
+func (p *Publisher) HasTopic(topic string) bool {
+	_, ok := p.topics[topic]
+	return ok
+}
+
+// Publish publishes the passed message body on the passed topic.  Messages on
+// topics which are not published are ignored.
+//
+// This function is safe for concurrent access.
+func (p *Publisher) Publish(topic string, body []byte) {
+	e, ok := p.topics[topic]
+	if !ok {
+		return
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	seq := p.sequences[topic]
+	p.sequences[topic] = seq + 1
+	if len(e.subscribers) == 0 {
+		return
+	}
+
+	msg := encodeMessage(topic, body, seq)
+	for sub := range e.subscribers {
+		select {
+		case sub.sendQueue <- msg:
+			sub.dropped = false
+		default:
+			if !sub.dropped {
+				log.Warnf("Dropping messages for slow subscriber %s",
+					sub.conn.RemoteAddr())
+				sub.dropped = true
+			}
+		}
+	}
+}
+
+// encodeMessage returns the frames of a message on the passed topic with the
+// passed body and sequence number.
+func encodeMessage(topic string, body []byte, seq uint32) []byte {
+	var seqBytes [4]byte
+	binary.LittleEndian.PutUint32(seqBytes[:], seq)
+
+	frames := [][]byte{[]byte(topic), body, seqBytes[:]}
+	size := 0
+	for _, frame := range frames {
+		size += 4 + len(frame)
+	}
+	msg := make([]byte, 0, size)
+	for _, frame := range frames {
+		var length [4]byte
+		binary.LittleEndian.PutUint32(length[:], uint32(len(frame)))
+		msg = append(msg, length[:]...)
+		msg = append(msg, frame...)
+	}
+	return msg
+}
+
+// acceptHandler accepts subscribers connecting to the passed endpoint.  It
+// must be run as a goroutine.
+func (p *Publisher) acceptHandler(e *endpoint) {
+	defer p.wg.Done()
+
+	for {
+		conn, err := e.listener.Accept()
+		if err != nil {
+			// Only log the error if not forcibly shutting down.
+			if atomic.LoadInt32(&p.shutdown) == 0 {
+				log.Errorf("Can't accept subscriber: %v", err)
+			}
+			return
+		}
+
+		sub := &subscriber{
+			conn:      conn,
+			sendQueue: make(chan []byte, sendQueueSize),
+		}
+		p.mtx.Lock()
+		e.subscribers[sub] = struct{}{}
+		p.mtx.Unlock()
+		log.Debugf("New subscriber %s on %s", conn.RemoteAddr(),
+			e.listener.Addr())
+
+		p.wg.Add(1)
+		go p.writeHandler(e, sub)
+	}
+}
+
+// writeHandler writes the messages queued for the passed subscriber until the
+// subscriber disconnects or the publisher is stopped.  It must be run as a
+// goroutine.
+func (p *Publisher) writeHandler(e *endpoint, sub *subscriber) {
+	defer p.wg.Done()
+
+out:
+	for {
+		select {
+		case msg := <-sub.sendQueue:
+			sub.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if _, err := sub.conn.Write(msg); err != nil {
+				log.Debugf("Subscriber %s disconnected: %v",
+					sub.conn.RemoteAddr(), err)
+				break out
+			}
+
+		case <-p.quit:
+			break out
+		}
+	}
+
+	p.mtx.Lock()
+	delete(e.subscribers, sub)
+	p.mtx.Unlock()
+	sub.conn.Close()
+}
+
+// Start begins accepting subscribers.
+func (p *Publisher) Start() {
+	// Already started?
+	if atomic.AddInt32(&p.started, 1) != 1 {
+		return
+	}
+
+	for _, e := range p.endpoints {
+		log.Infof("Publishing on %s", e.listener.Addr())
+		p.wg.Add(1)
+		go p.acceptHandler(e)
+	}
+}
+
+// Stop stops accepting subscribers, disconnects all subscribers and waits for
+// the handlers to finish.
+func (p *Publisher) Stop() {
+	// Already shutting down?
+	if atomic.AddInt32(&p.shutdown, 1) != 1 {
+		return
+	}
+
+	close(p.quit)
+	p.closeListeners()
+
+	// Close the connections to interrupt writes in progress.
+	p.mtx.Lock()
+	for _, e := range p.endpoints {
+		for sub := range e.subscribers {
+			sub.conn.Close()
+		}
+	}
+	p.mtx.Unlock()
+	p.wg.Wait()
+}
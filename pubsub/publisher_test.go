@@ -0,0 +1,122 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package pubsub
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+// readFrame reads a single length-prefixed frame from the passed reader.
+func readFrame(r io.Reader) ([]byte, error) {
+	var length [4]byte
+	if _, err := io.ReadFull(r, length[:]); err != nil {
+		return nil, err
+	}
+	frame := make([]byte, binary.LittleEndian.Uint32(length[:]))
+	_, err := io.ReadFull(r, frame)
+	return frame, err
+}
+
+// TestPublisher ensures messages published on a topic are delivered to the
+// subscribers of its address along with increasing sequence numbers.
+func TestPublisher(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to listen: %v", err)
+	}
+	p, err := New(&Config{
+		Topics: map[string]string{
+			TopicHashBlock: "block",
+			TopicRawBlock:  "block",
+		},
+		Listen: func(network, addr string) (net.Listener, error) {
+			return listener, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("New: unexpected error: %v", err)
+	}
+	p.Start()
+	defer p.Stop()
+
+	if !p.HasTopic(TopicRawBlock) || p.HasTopic(TopicRawTx) {
+		t.Fatal("HasTopic: unexpected topics")
+	}
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("unable to connect: %v", err)
+	}
+	defer conn.Close()
+
+	// Wait for the subscriber to be registered before publishing.
+	for i := 0; ; i++ {
+		p.mtx.Lock()
+		n := len(p.topics[TopicRawBlock].subscribers)
+		p.mtx.Unlock()
+		if n == 1 {
+			break
+		}
+		if i == 100 {
+			t.Fatal("subscriber was not registered")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Messages on topics which are not published are ignored.
+	p.Publish(TopicRawTx, []byte{0x05})
+	p.Publish(TopicRawBlock, []byte{0x01, 0x02})
+	p.Publish(TopicHashBlock, []byte{0x03})
+	p.Publish(TopicRawBlock, []byte{0x04})
+
+	tests := []struct {
+		topic string
+		body  []byte
+		seq   uint32
+	}{
+		{TopicRawBlock, []byte{0x01, 0x02}, 0},
+		{TopicHashBlock, []byte{0x03}, 0},
+		{TopicRawBlock, []byte{0x04}, 1},
+	}
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for i, test := range tests {
+		var frames [3][]byte
+		for j := range frames {
+			frames[j], err = readFrame(conn)
+			if err != nil {
+				t.Fatalf("#%d: unable to read frame: %v", i, err)
+			}
+		}
+		if string(frames[0]) != test.topic {
+			t.Errorf("#%d: unexpected topic: got %s, want %s", i,
+				frames[0], test.topic)
+		}
+		if !bytes.Equal(frames[1], test.body) {
+			t.Errorf("#%d: unexpected body: got %x, want %x", i,
+				frames[1], test.body)
+		}
+		if len(frames[2]) != 4 ||
+			binary.LittleEndian.Uint32(frames[2]) != test.seq {
+			t.Errorf("#%d: unexpected sequence: got %x, want %d", i,
+				frames[2], test.seq)
+		}
+	}
+}
+
+// TestPublisherUnknownTopic ensures a publisher can't be created for an
+// unknown topic.
+func TestPublisherUnknownTopic(t *testing.T) {
+	_, err := New(&Config{
+		Topics: map[string]string{"aitxlock": "127.0.0.1:0"},
+	})
+	if err == nil {
+		t.Fatal("New: expected an error for an unknown topic")
+	}
+}
@@ -6,7 +6,8 @@ set -ex
 # 1. gofmt         (https://golang.org/cmd/gofmt/)
 # 2. go vet        (http://golang.org/cmd/vet)
 # 3. unconvert     (https://github.com/mdempsky/unconvert)
-# 4. race detector (http://blog.golang.org/race-detector)
+# 4. test build    (every package's tests must compile)
+# 5. race detector (http://blog.golang.org/race-detector)
 
 # gometalinter (github.com/alecthomas/gometalinter) is used to run each each
 # static checker.
@@ -26,6 +27,7 @@ TESTCMD="test -z \"\$(gometalinter --disable-all \
   --enable=unconvert \
   --vendor \
   --deadline=10m . 2>&1 | tee /dev/stderr)\"&& \
+  go test -run=NONE -tags rpctest \$(glide novendor) && \
   env GORACE='halt_on_error=1' go test -short -race \
   -tags rpctest \
   \$(glide novendor)"
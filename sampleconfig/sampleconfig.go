@@ -21,6 +21,30 @@ const FileContents = `[Application Options]
 ; $VARIABLE here.  Also, ~ is expanded to $LOCALAPPDATA on Windows.
 ; datadir=~/.hcd/data
 
+; The directory to store the block files of the block database in instead of
+; the data directory, such as on a larger and slower volume, while the metadata
+; and the chain state remain in the data directory.  The block files are stored
+; in a network specific subdirectory as well.  Existing block files must be
+; moved to the new directory manually.
+; blocksdir=/mnt/archive/hcd
+
+; The maximum time to wait on shutdown for the RPC server, the peers and the
+; other subsystems to stop.  When it elapses, hcd logs the step in progress and
+; exits without closing the database.  0 waits indefinitely.
+; shutdowntimeout=2m
+
+; Verify the checksums, hashes and merkle roots of the stored blocks at this
+; interval.  Blocks which fail to verify are quarantined, so they are no longer
+; served to peers, reported by the gethealth RPC and repaired once identical
+; copies were received from two peers.  0 disables the verification (default).
+; dbverifyinterval=168h
+
+; Import and fully validate the blocks of flat files of blocks, such as written
+; by the dumpblockchain RPC, while the server is running.  Blocks which are
+; already known are skipped.  May be specified multiple times to import files in
+; order.
+; importbootstrap=~/bootstrap.dat
+
 
 ; ------------------------------------------------------------------------------
 ; Network settings
@@ -32,6 +56,9 @@ const FileContents = `[Application Options]
 ; Use simnet.
 ; simnet=1
 
+; Use the custom network defined in a JSON file, see docs/custom_networks.md.
+; customnet=~/consortium.json
+
 ; Connect via a SOCKS5 proxy.  NOTE: Specifying a proxy will disable listening
 ; for incoming connections unless listen addresses are provided via the 'listen'
 ; option.
@@ -57,6 +84,13 @@ const FileContents = `[Application Options]
 ; to correlate connections.
 ; torisolation=1
 
+; Only make automatic outbound connections to addresses of the specified
+; networks: ipv4, ipv6 or onion.  One network per line.  Peers added with the
+; 'addpeer' and 'connect' options are not restricted.  Unless listen addresses
+; are provided via the 'listen' option, only the IPv6 addresses are listened on
+; when the ipv6 network is specified without the ipv4 network, and vice versa.
+; onlynet=ipv6
+
 ; Use Universal Plug and Play (UPnP) to automatically open the listen port
 ; and obtain the external IP address from supported devices.  NOTE: This option
 ; will have no effect if exernal IP addresses are specified.
@@ -125,6 +159,12 @@ const FileContents = `[Application Options]
 ; banduration=24h
 ; banduration=11h30m15s
 
+; Switch to another sync peer when the current one does not deliver any of the
+; requested blocks or headers within this time while syncing.  The stalled peer
+; stays connected, but is no longer synced from, and the blocks requested from
+; it are requested from the new sync peer.  0 disables the stall detection.
+; syncstalltimeout=2m
+
 ; Add whitelisted IP networks and IPs. Connected peers whose IP matches a
 ; whitelist will not have their ban score increased.
 ; whitelist=127.0.0.1
@@ -132,6 +172,14 @@ const FileContents = `[Application Options]
 ; whitelist=192.168.0.0/24
 ; whitelist=fd00::/16
 
+; Average interval between the batches of transaction inventory announced to
+; outbound and inbound peers.  The batches are sent at random intervals around
+; the average to make it harder to tell where a transaction originated.  Block
+; inventory is always announced right away.  Valid time units are
+; {ms, s, m, h}.  Minimum 1ms.
+; trickleinterval=500ms
+; inboundtrickleinterval=1s
+
 ; Disable DNS seeding for peers.  By default, when hcd starts, it will use
 ; DNS to query for available peers to connect with.
 ; nodnsseed=1
@@ -177,7 +225,7 @@ const FileContents = `[Application Options]
 ; which is used to control and query information from a running hcd process.
 ;
 ; NOTE: The RPC server is disabled by default if no rpcuser or rpcpass is
-; specified.
+; specified and cookie authentication is disabled.
 ; ------------------------------------------------------------------------------
 
 ; Secure the RPC API by specifying the username and password.  You must specify
@@ -185,6 +233,15 @@ const FileContents = `[Application Options]
 ; rpcuser=whatever_username_you_want
 ; rpcpass=
 
+; An authentication cookie with admin credentials and a random password is
+; written on startup and removed on shutdown.  Local tools such as hcctl read it
+; when no username and password are configured.  By default it is written to
+; .cookie in the data directory.
+; rpccookie=~/.hcd/data/mainnet/.cookie
+
+; Disable authentication with the cookie file.
+; norpccookie=1
+
 ; Specify the interfaces for the RPC server listen on.  One listen address per
 ; line.  NOTE: The default port is modified by some options such as 'testnet',
 ; so it is recommended to not specify a port and allow a proper default to be
@@ -215,6 +272,31 @@ const FileContents = `[Application Options]
 ; All ipv6 interfaces on non-standard port 8337:
 ;   rpclisten=[::]:8337
 
+; Specify additional interfaces for the RPC server to listen on which only serve
+; the limited RPC methods, such as the chain queries, regardless of the
+; credentials of the clients.  This allows exposing the chain queries publicly
+; while the admin methods are only served on the rpclisten interfaces, such as
+; localhost.  One listen address per line, the syntax is the same as rpclisten.
+;   rpcpubliclisten=0.0.0.0:14010
+; Use a separate certificate for the public interfaces.  Both the certificate
+; and the key must be specified, they are generated when neither exists.
+; rpcpubliccert=~/.hcd/rpcpublic.cert
+; rpcpublickey=~/.hcd/rpcpublic.key
+
+; Validity period of the auto-generated RPC certificates.
+; rpccertvalidity=87600h
+
+; Regenerate the auto-generated RPC certificates once they expire within the
+; given duration.  Connected websocket clients receive the new certificate with
+; a certrotated notification.  Set to 0 to disable rotation.
+; rpccertrotate=720h
+
+; Add IP addresses or hostnames to the auto-generated RPC certificates, such as
+; the public address of the host.  The certificates are regenerated when they
+; don't cover them.  One host per line.
+; rpcextrahost=203.0.113.1
+; rpcextrahost=node.example.com
+
 ; Specify the maximum number of concurrent RPC clients for standard connections.
 ; rpcmaxclients=10
 
@@ -235,6 +317,16 @@ const FileContents = `[Application Options]
 ; Set the minimum transaction fee to be considered a non-zero fee,
 ; minrelaytxfee=0.01
 
+; Set the fee rate used to determine whether or not an output is dust.  Outputs
+; which would cost more than a third of their value to spend at this rate are
+; rejected as non-standard unless relaynonstd is set.
+; dustrelayfee=0.001
+
+; Evict transactions, along with any transactions which spend them, once they
+; have been in the memory pool for longer than this duration.  Valid time units
+; are {s, m, h}.  Set to 0 to disable.
+; mempoolexpiry=72h
+
 ; Rate-limit free transactions to the value 15 * 1000 bytes per
 ; minute.
 ; limitfreerelay=15
@@ -245,9 +337,19 @@ const FileContents = `[Application Options]
 ; Limit orphan transaction pool to 1000 transactions.
 ; maxorphantx=1000
 
+; Limit the memory used by the transactions in the memory pool to 300 MB.  Once
+; exceeded, the transactions with the lowest fee rate are evicted and the
+; minimum fee required to enter the pool is raised.  Set to 0 to disable.
+; maxmempool=300
+
 ; Do not accept transactions from remote peers.
 ; blocksonly=1
 
+; Do not request the memory pool contents of outbound peers when connecting to
+; them.  By default the transactions in the memory pool of outbound peers that
+; pay at least the minimum relay fee are requested once the chain is current.
+; nomempoolsync=1
+
 ; Relay non-standard transactions regardless of default network settings.
 ; relaynonstd=1
 
@@ -275,13 +377,33 @@ const FileContents = `[Application Options]
 ; searchrawtransactions RPC available.
 ; addrindex=1
 
+; Drop an enabled optional index on start up and rebuild it in the background
+; once the chain is synced.  Valid indexes are txindex, addrindex and
+; existsaddrindex.  Rebuilding the transaction index also rebuilds the address
+; index.  May be specified multiple times.
+; reindexindex=addrindex
+
+; Rebuild the chain state, such as the utxo set and the ticket database, from
+; the blocks already stored in the database on start up instead of downloading
+; them again.  The blocks are fully validated again and the enabled optional
+; indexes are rebuilt along with it.  An interrupted rebuild resumes on the next
+; start.  This option should only be used once, not left in the config file.
+; reindexchainstate=1
+
 
 ; ------------------------------------------------------------------------------
 ; Signature Verification Cache
 ; ------------------------------------------------------------------------------
 
-; Limit the signature cache to a max of 50000 entries.
-; sigcachemaxsize=50000
+; Limit the signature cache to a max of 32 MB.  The least recently used
+; signatures are evicted once the limit is reached.  Set it to 0 to disable the
+; cache.
+; sigcachemaxsize=32
+
+; Limit the cache of input scripts verified when accepting transactions to the
+; mempool to a max of 16 MB.  Inputs found in it are not verified again when
+; their block is connected.
+; scriptcachemaxsize=16
 
 
 ; ------------------------------------------------------------------------------
@@ -297,12 +419,24 @@ const FileContents = `[Application Options]
 ; worth your while.
 ; generate=false
 
+; Number of goroutines the nonce space of each block is split between when
+; mining with the CPU.  Negative values use the number of processor cores.
+; genproclimit=-1
+
 ; Add addresses to pay mined blocks to for CPU mining and the block templates
 ; generated for the getwork RPC service as desired.  One address per line.
 ; miningaddr=youraddress
 ; miningaddr=youraddress2
 ; miningaddr=youraddress3
 
+; Split the miner payout of generated blocks, the subsidy and fees, between
+; several addresses in proportion to their weights instead of paying it to a
+; single mining address.  Each address is given with its weight, separated by a
+; colon.  The weights may add up to at most 1000000.  For example, to pay three
+; quarters of the payout to one address and the rest to another:
+; miningaddrsplit=youraddress:3
+; miningaddrsplit=youraddress2:1
+
 ; Specify the minimum block size in bytes to create.  By default, only
 ; transactions which have enough fees or a high enough priority will be included
 ; in generated block templates.  Specifying a minimum block size will instead
@@ -350,4 +484,37 @@ const FileContents = `[Application Options]
 ;   profile=192.168.1.123:6061
 ; Listen on ipv6 loopback interface:
 ;   profile=[::1]:6061
+
+; ------------------------------------------------------------------------------
+; Slow query log
+; ------------------------------------------------------------------------------
+
+; Log RPC calls and database transactions which take longer than this duration
+; as warnings and keep the most recent ones for the getslowqueries RPC.  The
+; time database transactions waited for locks is logged as well.  Slow queries
+; are not logged if this option is not specified.
+; slowquerythreshold=500ms
+
+; ------------------------------------------------------------------------------
+; Lock contention profiling
+; ------------------------------------------------------------------------------
+
+; Profile how long the mempool and chain locks are waited for and held at each
+; call site for the getlockstats RPC, and log a warning whenever one of them is
+; held for longer than this duration.  A lock which is held for longer than this
+; duration without being released, such as due to a deadlock, is reported while
+; it is still held.  Lock contention is not profiled if this option is not
+; specified.
+; lockholdthreshold=1s
+
+; ------------------------------------------------------------------------------
+; Tracing - export spans of RPC requests
+; ------------------------------------------------------------------------------
+
+; Every RPC request is traced with a trace ID which is shared by the spans of
+; the memory pool and block chain operations it triggers, such as processing
+; the transactions and blocks of sendrawtransaction and submitblock.  The spans
+; are exported to an OpenTelemetry collector using OTLP/HTTP.  Tracing is
+; disabled if this option is not specified.
+; tracingendpoint=http://localhost:4318/v1/traces
 `
@@ -0,0 +1,36 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package tracing implements OpenTelemetry style request tracing with spans
+exported to an OTLP/HTTP collector.
+
+Tracer Overview
+
+A trace is the tree of spans recorded while serving a single request, such as
+an RPC call.  Each trace has a random 16-byte ID which is shared by all of its
+spans, so the operations performed on behalf of a request can be correlated
+even when they run on other goroutines or in other subsystems.  A span records
+the name, start and end time of an operation along with attributes describing
+it and whether it failed.
+
+The root span of a trace is started with Tracer.StartSpan and the spans of the
+operations it triggers are started with Span.StartChild.  Passing the span
+along with the work is all that is needed to propagate the trace.  All methods
+of Tracer and Span do nothing when called on nil, so code can be instrumented
+unconditionally and tracing disabled by using a nil tracer.
+
+Export
+
+Finished spans are queued and exported in batches using the JSON encoding of
+the OTLP/HTTP protocol, which is accepted by the OpenTelemetry collector and
+most tracing backends, for example:
+
+  http://localhost:4318/v1/traces
+
+A batch is exported once it is full or the flush interval elapsed.  Spans are
+dropped rather than delaying the traced code when the collector does not keep
+up, and failed exports are logged and not retried.
+*/
+package tracing
@@ -0,0 +1,195 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package tracing
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+)
+
+// These constants define the span kinds and status codes of the OTLP trace
+// data model.
+const (
+	otlpSpanKindInternal = 1
+	otlpSpanKindServer   = 2
+
+	otlpStatusCodeOk    = 1
+	otlpStatusCodeError = 2
+)
+
+// otlpAnyValue is the JSON encoding of an OTLP attribute value.  Exactly one
+// of the fields is set.  64-bit integers are encoded as strings.
+type otlpAnyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+// otlpKeyValue is the JSON encoding of an OTLP attribute.
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+// otlpStatus is the JSON encoding of the status of an OTLP span.
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+// otlpSpan is the JSON encoding of an OTLP span.  The IDs are encoded as
+// hexadecimal strings and the timestamps as strings of nanoseconds since the
+// Unix epoch.
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+// otlpScope is the JSON encoding of an OTLP instrumentation scope.
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+// otlpScopeSpans is the JSON encoding of the spans of an OTLP instrumentation
+// scope.
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+// otlpResource is the JSON encoding of an OTLP resource.
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+// otlpResourceSpans is the JSON encoding of the spans of an OTLP resource.
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+// otlpExportRequest is the JSON encoding of an OTLP trace export request.
+type otlpExportRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+// otlpValue returns the OTLP encoding of the passed attribute value.
+func otlpValue(value interface{}) otlpAnyValue {
+	var v otlpAnyValue
+	switch value := value.(type) {
+	case string:
+		v.StringValue = &value
+	case bool:
+		v.BoolValue = &value
+	case int:
+		s := strconv.FormatInt(int64(value), 10)
+		v.IntValue = &s
+	case int32:
+		s := strconv.FormatInt(int64(value), 10)
+		v.IntValue = &s
+	case int64:
+		s := strconv.FormatInt(value, 10)
+		v.IntValue = &s
+	case uint32:
+		s := strconv.FormatUint(uint64(value), 10)
+		v.IntValue = &s
+	case float64:
+		v.DoubleValue = &value
+	default:
+		s := fmt.Sprint(value)
+		v.StringValue = &s
+	}
+	return v
+}
+
+// encodeSpans returns the OTLP export request for the passed finished spans of
+// the passed service.
+func encodeSpans(serviceName string, spans []*Span) *otlpExportRequest {
+	encoded := make([]otlpSpan, 0, len(spans))
+	for _, s := range spans {
+		s.mtx.Lock()
+		span := otlpSpan{
+			TraceID:           s.traceID.String(),
+			SpanID:            s.spanID.String(),
+			Name:              s.name,
+			Kind:              otlpSpanKindInternal,
+			StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+			Status:            otlpStatus{Code: otlpStatusCodeOk},
+		}
+		if s.parentID != (SpanID{}) {
+			span.ParentSpanID = s.parentID.String()
+		}
+		if s.server {
+			span.Kind = otlpSpanKindServer
+		}
+		for _, attr := range s.attributes {
+			span.Attributes = append(span.Attributes, otlpKeyValue{
+				Key:   attr.key,
+				Value: otlpValue(attr.value),
+			})
+		}
+		if s.err != "" {
+			span.Status = otlpStatus{
+				Code:    otlpStatusCodeError,
+				Message: s.err,
+			}
+		}
+		s.mtx.Unlock()
+		encoded = append(encoded, span)
+	}
+
+	return &otlpExportRequest{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource: otlpResource{
+				Attributes: []otlpKeyValue{{
+					Key:   "service.name",
+					Value: otlpValue(serviceName),
+				}},
+			},
+			ScopeSpans: []otlpScopeSpans{{
+				Scope: otlpScope{Name: "github.com/HcashOrg/hcd"},
+				Spans: encoded,
+			}},
+		}},
+	}
+}
+
+// export sends the passed finished spans to the OTLP/HTTP endpoint of the
+// tracer using the JSON encoding.
+func (t *Tracer) export(spans []*Span) error {
+	body, err := json.Marshal(encodeSpans(t.serviceName, spans))
+	if err != nil {
+		return err
+	}
+
+	resp, err := t.client.Post(t.endpoint, "application/json",
+		bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("collector responded with status %s",
+			resp.Status)
+	}
+	log.Debugf("Exported %d spans", len(spans))
+	return nil
+}
@@ -0,0 +1,326 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package tracing
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultServiceName is the service name spans are exported with when
+	// none is configured.
+	DefaultServiceName = "hcd"
+
+	// DefaultFlushInterval is the maximum time a finished span is held
+	// before it is exported when none is configured.
+	DefaultFlushInterval = 5 * time.Second
+
+	// maxBatchSize is the maximum number of spans exported in a single
+	// request.  A batch is exported early once it reaches this size.
+	maxBatchSize = 512
+
+	// queueSize is the maximum number of finished spans waiting to be
+	// exported.  Further spans are dropped until the exporter catches up.
+	queueSize = 4096
+
+	// exportTimeout is the maximum time an export request may take.
+	exportTimeout = 10 * time.Second
+)
+
+// TraceID uniquely identifies a trace, which is the tree of spans recorded
+// for a single request.
+type TraceID [16]byte
+
+// String returns the trace ID as a hexadecimal string.
+func (id TraceID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// SpanID uniquely identifies a span within a trace.
+type SpanID [8]byte
+
+// String returns the span ID as a hexadecimal string.
+func (id SpanID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// Config is a descriptor containing the tracer configuration.
+type Config struct {
+	// Endpoint is the URL of the OTLP/HTTP traces endpoint of a collector,
+	// for example http://localhost:4318/v1/traces.
+	Endpoint string
+
+	// ServiceName is the name of the service the spans are exported for.
+	// It defaults to DefaultServiceName when empty.
+	ServiceName string
+
+	// FlushInterval is the maximum time a finished span is held before it
+	// is exported.  It defaults to DefaultFlushInterval when zero.
+	FlushInterval time.Duration
+
+	// Client is the HTTP client used to export spans.  A client with a
+	// timeout of exportTimeout is used when nil.
+	Client *http.Client
+}
+
+// attribute is a key-value pair describing a span.
+type attribute struct {
+	key   string
+	value interface{}
+}
+
+// Span records the duration of an operation along with attributes describing
+// it.  A span belongs to a trace and may have a parent span within it.
+//
+// All methods are safe to call on a nil span, in which case they do nothing.
+// This allows code to be instrumented unconditionally while tracing is
+// disabled.
+type Span struct {
+	tracer   *Tracer
+	traceID  TraceID
+	spanID   SpanID
+	parentID SpanID
+	name     string
+	server   bool
+	start    time.Time
+
+	mtx        sync.Mutex
+	end        time.Time
+	attributes []attribute
+	err        string
+	ended      bool
+}
+
+// TraceID returns the ID of the trace the span belongs to.
+func (s *Span) TraceID() TraceID {
+	if s == nil {
+		return TraceID{}
+	}
+	return s.traceID
+}
+
+// StartChild starts a new span for an operation performed on behalf of the
+// span.  The returned span must be ended by calling End.
+//
+// This function is safe for concurrent access.
+func (s *Span) StartChild(name string) *Span {
+	if s == nil {
+		return nil
+	}
+	child := s.tracer.newSpan(name, s.traceID)
+	child.parentID = s.spanID
+	return child
+}
+
+// SetAttribute sets an attribute describing the operation of the span.  The
+// value should be a string, bool, integer or floating point number.  Other
+// values are recorded using their default string representation.
+//
+// This function is safe for concurrent access.
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	s.mtx.Lock()
+	s.attributes = append(s.attributes, attribute{key, value})
+	s.mtx.Unlock()
+}
+
+// SetError marks the operation of the span as failed with the passed error.
+// Nil errors are ignored.
+//
+// This function is safe for concurrent access.
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mtx.Lock()
+	s.err = err.Error()
+	s.mtx.Unlock()
+}
+
+// End records the end of the operation of the span and queues it for export.
+// Calls after the first one are ignored.
+//
+// This function is safe for concurrent access.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mtx.Lock()
+	if s.ended {
+		s.mtx.Unlock()
+		return
+	}
+	s.ended = true
+	s.end = time.Now()
+	s.mtx.Unlock()
+
+	s.tracer.queue(s)
+}
+
+// Tracer creates spans and exports them in batches to an OTLP/HTTP endpoint.
+//
+// All methods are safe to call on a nil tracer, in which case no spans are
+// created.
+type Tracer struct {
+	started  int32
+	shutdown int32
+
+	endpoint      string
+	serviceName   string
+	flushInterval time.Duration
+	client        *http.Client
+
+	spans chan *Span
+
+	wg   sync.WaitGroup
+	quit chan struct{}
+}
+
+// New returns a new tracer exporting spans to the endpoint of the passed
+// configuration.  An error is returned when no endpoint is configured.
+func New(cfg *Config) (*Tracer, error) {
+	if cfg.Endpoint == "" {
+		return nil, fmt.Errorf("no endpoint to export spans to")
+	}
+
+	t := &Tracer{
+		endpoint:      cfg.Endpoint,
+		serviceName:   cfg.ServiceName,
+		flushInterval: cfg.FlushInterval,
+		client:        cfg.Client,
+		spans:         make(chan *Span, queueSize),
+		quit:          make(chan struct{}),
+	}
+	if t.serviceName == "" {
+		t.serviceName = DefaultServiceName
+	}
+	if t.flushInterval == 0 {
+		t.flushInterval = DefaultFlushInterval
+	}
+	if t.client == nil {
+		t.client = &http.Client{Timeout: exportTimeout}
+	}
+	return t, nil
+}
+
+// newSpan returns a new started span with a random span ID in the passed
+// trace.
+func (t *Tracer) newSpan(name string, traceID TraceID) *Span {
+	s := &Span{
+		tracer:  t,
+		traceID: traceID,
+		name:    name,
+		start:   time.Now(),
+	}
+	rand.Read(s.spanID[:])
+	return s
+}
+
+// StartSpan starts the root span of a new trace for a request served by the
+// process.  The returned span must be ended by calling End.
+//
+// This function is safe for concurrent access.
+func (t *Tracer) StartSpan(name string) *Span {
+	if t == nil {
+		return nil
+	}
+	var traceID TraceID
+	rand.Read(traceID[:])
+	s := t.newSpan(name, traceID)
+	s.server = true
+	return s
+}
+
+// queue queues the passed finished span for export.  The span is dropped when
+// the queue is full.
+func (t *Tracer) queue(s *Span) {
+	select {
+	case t.spans <- s:
+	default:
+		log.Tracef("Dropping span %s of trace %s", s.name, s.traceID)
+	}
+}
+
+// exportHandler exports the queued spans in batches until the tracer is
+// stopped.  It must be run as a goroutine.
+func (t *Tracer) exportHandler() {
+	defer t.wg.Done()
+
+	ticker := time.NewTicker(t.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*Span, 0, maxBatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := t.export(batch); err != nil {
+			log.Warnf("Failed to export %d spans: %v", len(batch), err)
+		}
+		batch = batch[:0]
+	}
+
+out:
+	for {
+		select {
+		case s := <-t.spans:
+			batch = append(batch, s)
+			if len(batch) == maxBatchSize {
+				flush()
+			}
+
+		case <-ticker.C:
+			flush()
+
+		case <-t.quit:
+			break out
+		}
+	}
+
+	// Export the spans finished before the tracer was stopped.
+	for {
+		select {
+		case s := <-t.spans:
+			batch = append(batch, s)
+			if len(batch) == maxBatchSize {
+				flush()
+			}
+		default:
+			flush()
+			return
+		}
+	}
+}
+
+// Start begins exporting spans.
+func (t *Tracer) Start() {
+	// Already started?
+	if atomic.AddInt32(&t.started, 1) != 1 {
+		return
+	}
+
+	log.Infof("Exporting trace spans to %s", t.endpoint)
+	t.wg.Add(1)
+	go t.exportHandler()
+}
+
+// Stop exports the remaining finished spans and stops exporting spans.
+func (t *Tracer) Stop() {
+	// Already shutting down?
+	if atomic.AddInt32(&t.shutdown, 1) != 1 {
+		return
+	}
+
+	close(t.quit)
+	t.wg.Wait()
+}
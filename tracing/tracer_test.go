@@ -0,0 +1,117 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package tracing
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestTracer ensures spans are exported to the OTLP/HTTP endpoint with the IDs
+// of their trace and parent along with their attributes and status.
+func TestTracer(t *testing.T) {
+	var mtx sync.Mutex
+	var spans []otlpSpan
+	var serviceName string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req otlpExportRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("unable to decode export request: %v", err)
+			return
+		}
+		mtx.Lock()
+		defer mtx.Unlock()
+		for _, rs := range req.ResourceSpans {
+			serviceName = *rs.Resource.Attributes[0].Value.StringValue
+			for _, ss := range rs.ScopeSpans {
+				spans = append(spans, ss.Spans...)
+			}
+		}
+	}))
+	defer srv.Close()
+
+	tracer, err := New(&Config{
+		Endpoint:      srv.URL,
+		FlushInterval: time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("New: unexpected error: %v", err)
+	}
+	tracer.Start()
+
+	root := tracer.StartSpan("rpc submitblock")
+	root.SetAttribute("method", "submitblock")
+	child := root.StartChild("blockchain.ProcessBlock")
+	child.SetAttribute("height", int64(100))
+	child.SetError(errors.New("orphan"))
+	child.End()
+	child.End()
+	root.End()
+
+	// Stopping the tracer must export the finished spans.
+	tracer.Stop()
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	if serviceName != DefaultServiceName {
+		t.Errorf("unexpected service name: got %q, want %q", serviceName,
+			DefaultServiceName)
+	}
+	if len(spans) != 2 {
+		t.Fatalf("unexpected number of exported spans: got %d, want 2",
+			len(spans))
+	}
+	gotChild, gotRoot := spans[0], spans[1]
+	if gotRoot.TraceID != root.TraceID().String() ||
+		gotChild.TraceID != gotRoot.TraceID {
+
+		t.Errorf("spans not exported with the trace ID %s", root.TraceID())
+	}
+	if gotRoot.ParentSpanID != "" || gotRoot.Kind != otlpSpanKindServer {
+		t.Errorf("unexpected root span: %+v", gotRoot)
+	}
+	if gotChild.ParentSpanID != gotRoot.SpanID ||
+		gotChild.Kind != otlpSpanKindInternal {
+
+		t.Errorf("unexpected child span: %+v", gotChild)
+	}
+	if len(gotChild.Attributes) != 1 ||
+		gotChild.Attributes[0].Key != "height" ||
+		*gotChild.Attributes[0].Value.IntValue != "100" {
+
+		t.Errorf("unexpected child span attributes: %+v",
+			gotChild.Attributes)
+	}
+	if gotChild.Status.Code != otlpStatusCodeError ||
+		gotChild.Status.Message != "orphan" {
+
+		t.Errorf("unexpected child span status: %+v", gotChild.Status)
+	}
+	if gotRoot.Status.Code != otlpStatusCodeOk {
+		t.Errorf("unexpected root span status: %+v", gotRoot.Status)
+	}
+}
+
+// TestNilTracer ensures spans of a nil tracer can be used without effect.
+func TestNilTracer(t *testing.T) {
+	var tracer *Tracer
+	span := tracer.StartSpan("rpc getblockcount")
+	if span != nil {
+		t.Fatalf("nil tracer started span")
+	}
+	child := span.StartChild("child")
+	child.SetAttribute("key", "value")
+	child.SetError(errors.New("error"))
+	child.End()
+	span.End()
+	if span.TraceID() != (TraceID{}) {
+		t.Fatalf("nil span has trace ID")
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txscript
+
+import (
+	"errors"
+	"fmt"
+)
+
+// StandardPolicy houses the policy limits used when determining whether or
+// not a script is considered standard.
+type StandardPolicy struct {
+	// MaxMultiSigKeys is the maximum number of public keys a standard
+	// multi-signature public key script may contain.
+	MaxMultiSigKeys int
+
+	// MaxP2SHSigOps is the maximum number of signature operations the
+	// redeem script of a standard pay-to-script-hash input may contain.
+	MaxP2SHSigOps int
+
+	// ScriptFlags are the flags standard scripts are executed with.  Public
+	// key scripts which use alternative signature schemes are only standard
+	// when the schemes are active under these flags.
+	ScriptFlags ScriptFlags
+}
+
+// ScriptAnalysis houses the result of statically analyzing a public key
+// script.  It is created with Analyze.
+type ScriptAnalysis struct {
+	// Version is the script version the script was analyzed under.
+	Version uint16
+
+	// Class is the recognized form of the script.  It is NonStandardTy for
+	// scripts with a non-default version or which fail to parse.
+	Class ScriptClass
+
+	// ReqSigs is the number of signatures required to redeem the script.
+	// It is 0 for unspendable and non-standard scripts.
+	ReqSigs int
+
+	// NumPubKeys is the number of public keys in a multi-signature script
+	// and 0 for all other classes.
+	NumPubKeys int
+
+	// PushSizes are the sizes of the data pushed by each of the data push
+	// opcodes (OP_DATA_1 through OP_PUSHDATA4) of the script in order.
+	PushSizes []int
+
+	// PushOnly is whether or not the script only pushes data.
+	PushOnly bool
+
+	// SigOps is the number of signature operations in the script as
+	// counted prior to pay-to-script-hash, where every multi-signature
+	// operation counts as MaxPubKeysPerMultiSig.
+	SigOps int
+
+	// PreciseSigOps is the number of signature operations in the script
+	// where multi-signature operations count as their number of public
+	// keys when it is known.
+	PreciseSigOps int
+
+	// AltSigSchemes are the signature schemes selected by each
+	// OP_CHECKSIGALT and OP_CHECKSIGALTVERIFY of the script in order.  The
+	// entry is nil when the signature type is not pushed immediately prior
+	// to the opcode or is not registered.
+	AltSigSchemes []*SignatureScheme
+
+	// ParseErr is the error encountered while parsing the script, if any.
+	// The other fields describe the script up to the point of failure.
+	ParseErr error
+
+	script []byte
+}
+
+// Analyze parses the passed public key script without executing it and
+// returns its class, signature requirements, push sizes and signature
+// operation counts.  Scripts which fail to parse are analyzed up to the point
+// of failure and have ParseErr set.
+func Analyze(version uint16, script []byte) *ScriptAnalysis {
+	pops, err := parseScript(script)
+	a := &ScriptAnalysis{
+		Version:       version,
+		Class:         NonStandardTy,
+		PushOnly:      isPushOnly(pops),
+		SigOps:        getSigOpCount(pops, false),
+		PreciseSigOps: getSigOpCount(pops, true),
+		ParseErr:      err,
+		script:        script,
+	}
+	for i, pop := range pops {
+		if pop.data != nil {
+			a.PushSizes = append(a.PushSizes, len(pop.data))
+		}
+
+		switch pop.opcode.value {
+		case OP_CHECKSIGALT, OP_CHECKSIGALTVERIFY:
+			var scheme *SignatureScheme
+			if i > 0 {
+				scheme, _ = altSigScheme(&pops[i-1])
+			}
+			a.AltSigSchemes = append(a.AltSigSchemes, scheme)
+		}
+	}
+	if version != DefaultScriptVersion || err != nil {
+		return a
+	}
+
+	a.Class = typeOfScript(pops)
+	switch a.Class {
+	case PubKeyTy, PubkeyAltTy, PubKeyHashTy, PubkeyHashAltTy,
+		ScriptHashTy, StakeSubmissionTy, StakeGenTy,
+		StakeRevocationTy, StakeSubChangeTy:
+		a.ReqSigs = 1
+
+	case MultiSigTy:
+		// A multi-signature script is of the pattern:
+		//  NUM_SIGS PUBKEY PUBKEY PUBKEY... NUM_PUBKEYS OP_CHECKMULTISIG
+		a.ReqSigs = asSmallInt(pops[0].opcode)
+		a.NumPubKeys = asSmallInt(pops[len(pops)-2].opcode)
+	}
+
+	return a
+}
+
+// P2SHSigOps returns the number of signature operations counted once
+// pay-to-script-hash is active when the analyzed script is redeemed by the
+// passed signature script.  It is the same as PreciseSigOps for scripts which
+// are not pay-to-script-hash.
+func (a *ScriptAnalysis) P2SHSigOps(sigScript []byte) int {
+	return GetPreciseSigOpCount(sigScript, a.script, true)
+}
+
+// CheckStandard returns an error when the analyzed script is not a standard
+// public key script under the passed policy.  A standard public key script is
+// one that has the default version, is a recognized form, and for
+// multi-signature scripts, requires from 1 to the number of public keys it
+// contains signatures and contains from 1 to policy.MaxMultiSigKeys public
+// keys.  Any alternative signature schemes it uses must also be registered and
+// active under policy.ScriptFlags.
+func (a *ScriptAnalysis) CheckStandard(policy *StandardPolicy) error {
+	if a.Version != DefaultScriptVersion {
+		return errors.New("versions other than default pkscript " +
+			"version are currently non-standard except for " +
+			"provably unspendable outputs")
+	}
+
+	switch a.Class {
+	case MultiSigTy:
+		if a.NumPubKeys < 1 {
+			return errors.New("multi-signature script with no pubkeys")
+		}
+		if a.NumPubKeys > policy.MaxMultiSigKeys {
+			return fmt.Errorf("multi-signature script with %d "+
+				"public keys which is more than the allowed "+
+				"max of %d", a.NumPubKeys, policy.MaxMultiSigKeys)
+		}
+		if a.ReqSigs < 1 {
+			return errors.New("multi-signature script with no " +
+				"signatures")
+		}
+		if a.ReqSigs > a.NumPubKeys {
+			return fmt.Errorf("multi-signature script with %d "+
+				"signatures which is more than the available "+
+				"%d public keys", a.ReqSigs, a.NumPubKeys)
+		}
+
+	case NonStandardTy:
+		return errors.New("non-standard script form")
+	}
+
+	for _, scheme := range a.AltSigSchemes {
+		if scheme == nil {
+			return errors.New("unknown signature scheme")
+		}
+		if !scheme.isActive(policy.ScriptFlags) {
+			return fmt.Errorf("signature scheme %s is not active",
+				scheme.Name)
+		}
+	}
+
+	return nil
+}
+
+// CheckStandardInput returns an error when spending the analyzed script with
+// the passed signature script is not standard under the passed policy.  The
+// analyzed script must be of a recognized form and, for pay-to-script-hash,
+// the redeem script must not have more than policy.MaxP2SHSigOps signature
+// operations.
+//
+// Clean stack and push only signature script requirements are not checked
+// since the script engine already enforces them when executing with the
+// ScriptVerifyCleanStack and ScriptVerifySigPushOnly flags.
+func (a *ScriptAnalysis) CheckStandardInput(sigScript []byte, policy *StandardPolicy) error {
+	switch a.Class {
+	case ScriptHashTy:
+		numSigOps := a.P2SHSigOps(sigScript)
+		if numSigOps > policy.MaxP2SHSigOps {
+			return fmt.Errorf("redeem script has %d signature "+
+				"operations which is more than the allowed max "+
+				"amount of %d",
+				numSigOps, policy.MaxP2SHSigOps)
+		}
+
+	case NonStandardTy:
+		return errors.New("non-standard script form")
+	}
+
+	return nil
+}
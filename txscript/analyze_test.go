@@ -0,0 +1,205 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txscript_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/HcashOrg/hcd/txscript"
+)
+
+// TestAnalyze ensures the static analysis of public key scripts reports the
+// expected class, signature requirements, push sizes and signature operation
+// counts.
+func TestAnalyze(t *testing.T) {
+	t.Parallel()
+
+	pubKey := bytes.Repeat([]byte{0x02}, 33)
+	hash := bytes.Repeat([]byte{0x01}, 20)
+	mustScript := func(b *txscript.ScriptBuilder) []byte {
+		script, err := b.Script()
+		if err != nil {
+			t.Fatalf("unable to build script: %v", err)
+		}
+		return script
+	}
+
+	tests := []struct {
+		name          string
+		version       uint16
+		script        []byte
+		class         txscript.ScriptClass
+		reqSigs       int
+		numPubKeys    int
+		pushSizes     []int
+		pushOnly      bool
+		sigOps        int
+		preciseSigOps int
+		parseErr      bool
+	}{
+		{
+			name:    "pay to pubkey hash",
+			version: 0,
+			script: mustScript(txscript.NewScriptBuilder().
+				AddOp(txscript.OP_DUP).AddOp(txscript.OP_HASH160).
+				AddData(hash).AddOp(txscript.OP_EQUALVERIFY).
+				AddOp(txscript.OP_CHECKSIG)),
+			class:         txscript.PubKeyHashTy,
+			reqSigs:       1,
+			pushSizes:     []int{20},
+			sigOps:        1,
+			preciseSigOps: 1,
+		},
+		{
+			name:    "pay to script hash",
+			version: 0,
+			script: mustScript(txscript.NewScriptBuilder().
+				AddOp(txscript.OP_HASH160).AddData(hash).
+				AddOp(txscript.OP_EQUAL)),
+			class:     txscript.ScriptHashTy,
+			reqSigs:   1,
+			pushSizes: []int{20},
+		},
+		{
+			name:    "1 of 2 multisig",
+			version: 0,
+			script: mustScript(txscript.NewScriptBuilder().
+				AddOp(txscript.OP_1).AddData(pubKey).
+				AddData(pubKey).AddOp(txscript.OP_2).
+				AddOp(txscript.OP_CHECKMULTISIG)),
+			class:         txscript.MultiSigTy,
+			reqSigs:       1,
+			numPubKeys:    2,
+			pushSizes:     []int{33, 33},
+			sigOps:        txscript.MaxPubKeysPerMultiSig,
+			preciseSigOps: 2,
+		},
+		{
+			name:    "non-default version",
+			version: 1,
+			script: mustScript(txscript.NewScriptBuilder().
+				AddOp(txscript.OP_HASH160).AddData(hash).
+				AddOp(txscript.OP_EQUAL)),
+			class:     txscript.NonStandardTy,
+			pushSizes: []int{20},
+		},
+		{
+			name:      "push only",
+			version:   0,
+			script:    mustScript(txscript.NewScriptBuilder().AddData(hash)),
+			class:     txscript.NonStandardTy,
+			pushSizes: []int{20},
+			pushOnly:  true,
+		},
+		{
+			name:          "parse failure",
+			version:       0,
+			script:        []byte{txscript.OP_CHECKSIG, txscript.OP_DATA_5, 0x01},
+			class:         txscript.NonStandardTy,
+			sigOps:        1,
+			preciseSigOps: 1,
+			parseErr:      true,
+		},
+	}
+
+	for _, test := range tests {
+		a := txscript.Analyze(test.version, test.script)
+		if a.Class != test.class {
+			t.Errorf("%s: unexpected class - got %v, want %v",
+				test.name, a.Class, test.class)
+		}
+		if a.ReqSigs != test.reqSigs {
+			t.Errorf("%s: unexpected required signatures - got %d, "+
+				"want %d", test.name, a.ReqSigs, test.reqSigs)
+		}
+		if a.NumPubKeys != test.numPubKeys {
+			t.Errorf("%s: unexpected number of pubkeys - got %d, "+
+				"want %d", test.name, a.NumPubKeys, test.numPubKeys)
+		}
+		if !reflect.DeepEqual(a.PushSizes, test.pushSizes) {
+			t.Errorf("%s: unexpected push sizes - got %v, want %v",
+				test.name, a.PushSizes, test.pushSizes)
+		}
+		if a.PushOnly != test.pushOnly {
+			t.Errorf("%s: unexpected push only - got %v, want %v",
+				test.name, a.PushOnly, test.pushOnly)
+		}
+		if a.SigOps != test.sigOps {
+			t.Errorf("%s: unexpected sigops - got %d, want %d",
+				test.name, a.SigOps, test.sigOps)
+		}
+		if a.PreciseSigOps != test.preciseSigOps {
+			t.Errorf("%s: unexpected precise sigops - got %d, want %d",
+				test.name, a.PreciseSigOps, test.preciseSigOps)
+		}
+		if (a.ParseErr != nil) != test.parseErr {
+			t.Errorf("%s: unexpected parse error - got %v, want "+
+				"error %v", test.name, a.ParseErr, test.parseErr)
+		}
+	}
+}
+
+// TestAnalyzeStandard ensures the standardness checks of a script analysis
+// enforce the limits of the passed policy.
+func TestAnalyzeStandard(t *testing.T) {
+	t.Parallel()
+
+	policy := &txscript.StandardPolicy{
+		MaxMultiSigKeys: 3,
+		MaxP2SHSigOps:   15,
+	}
+	pubKey := bytes.Repeat([]byte{0x02}, 33)
+
+	// A 1 of 4 multisig script has more keys than allowed.
+	builder := txscript.NewScriptBuilder().AddOp(txscript.OP_1)
+	for i := 0; i < 4; i++ {
+		builder.AddData(pubKey)
+	}
+	builder.AddOp(txscript.OP_4).AddOp(txscript.OP_CHECKMULTISIG)
+	multiSig, err := builder.Script()
+	if err != nil {
+		t.Fatalf("unable to build script: %v", err)
+	}
+	if err := txscript.Analyze(0, multiSig).CheckStandard(policy); err == nil {
+		t.Fatal("CheckStandard: 1 of 4 multisig unexpectedly standard")
+	}
+	policy.MaxMultiSigKeys = 4
+	if err := txscript.Analyze(0, multiSig).CheckStandard(policy); err != nil {
+		t.Fatalf("CheckStandard: unexpected error: %v", err)
+	}
+
+	// Redeem scripts are only standard up to the max number of sigops.
+	redeemScript := bytes.Repeat([]byte{txscript.OP_CHECKSIG}, 16)
+	p2shScript, err := txscript.PayToScriptHashScript(
+		make([]byte, 20))
+	if err != nil {
+		t.Fatalf("unable to build script: %v", err)
+	}
+	sigScript, err := txscript.NewScriptBuilder().AddData(redeemScript).
+		Script()
+	if err != nil {
+		t.Fatalf("unable to build script: %v", err)
+	}
+	a := txscript.Analyze(0, p2shScript)
+	if got := a.P2SHSigOps(sigScript); got != 16 {
+		t.Fatalf("P2SHSigOps: unexpected count - got %d, want 16", got)
+	}
+	if err := a.CheckStandardInput(sigScript, policy); err == nil {
+		t.Fatal("CheckStandardInput: 16 sigops unexpectedly standard")
+	}
+	policy.MaxP2SHSigOps = 16
+	if err := a.CheckStandardInput(sigScript, policy); err != nil {
+		t.Fatalf("CheckStandardInput: unexpected error: %v", err)
+	}
+
+	// Non-standard scripts are never standard to spend.
+	a = txscript.Analyze(0, []byte{txscript.OP_TRUE})
+	if err := a.CheckStandardInput(nil, policy); err == nil {
+		t.Fatal("CheckStandardInput: non-standard script unexpectedly " +
+			"standard")
+	}
+}
@@ -109,8 +109,10 @@ type Engine struct {
 	numOps          int
 	flags           ScriptFlags
 	sigCache        *SigCache
-	bip16           bool     // treat execution as pay-to-script-hash
-	savedFirstStack [][]byte // stack from first script for bip16 scripts
+	bip16           bool        // treat execution as pay-to-script-hash
+	savedFirstStack [][]byte    // stack from first script for bip16 scripts
+	tracing         bool        // record executed steps
+	trace           []DebugStep // steps recorded when tracing
 }
 
 // hasFlag returns whether the script engine instance has the passed flag set.
@@ -284,6 +286,9 @@ func (vm *Engine) Step() (done bool, err error) {
 	// disabled opcodes, illegal opcodes, maximum allowed operations per
 	// script, maximum script element sizes, and conditionals.
 	err = vm.executeOpcode(opcode)
+	if vm.tracing {
+		vm.recordStep(vm.scriptIdx, vm.scriptOff, err)
+	}
 	if err != nil {
 		return true, err
 	}
@@ -7,6 +7,7 @@
 package txscript_test
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/HcashOrg/hcd/chaincfg/chainhash"
@@ -475,4 +476,92 @@ func TestCheckErrorCondition(t *testing.T) {
 	}
 }
 
+// TestEngineTracing ensures that an engine with tracing enabled records every
+// executed opcode along with the resulting stacks and the error of a failing
+// opcode.
+func TestEngineTracing(t *testing.T) {
+	t.Parallel()
+
+	tx := &wire.MsgTx{
+		SerType: wire.TxSerializeFull,
+		Version: 1,
+		TxIn: []*wire.TxIn{{
+			PreviousOutPoint: wire.OutPoint{Hash: chainhash.Hash{0x01}},
+			SignatureScript:  []byte{txscript.OP_2, txscript.OP_3},
+			Sequence:         wire.MaxTxInSequenceNum,
+		}},
+		TxOut: []*wire.TxOut{{Value: 1}},
+	}
+	pkScript := []byte{txscript.OP_TOALTSTACK, txscript.OP_1,
+		txscript.OP_EQUALVERIFY}
+
+	vm, err := txscript.NewEngine(pkScript, tx, 0, 0, 0, nil)
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+
+	// Ensure nothing is recorded without tracing enabled.
+	if vm.DebugSteps().Next() {
+		t.Fatal("DebugSteps: recorded step without tracing enabled")
+	}
 
+	vm.EnableTracing()
+	if err := vm.Execute(); err != txscript.ErrStackVerifyFailed {
+		t.Fatalf("Execute: unexpected error %v", err)
+	}
+
+	tests := []struct {
+		scriptIdx int
+		opcode    string
+		stack     [][]byte
+		altStack  [][]byte
+		err       error
+	}{
+		{0, "OP_2", [][]byte{{2}}, [][]byte{}, nil},
+		{0, "OP_3", [][]byte{{2}, {3}}, [][]byte{}, nil},
+		{1, "OP_TOALTSTACK", [][]byte{{2}}, [][]byte{{3}}, nil},
+		{1, "OP_1", [][]byte{{2}, {1}}, [][]byte{{3}}, nil},
+		{1, "OP_EQUALVERIFY", [][]byte{}, [][]byte{{3}},
+			txscript.ErrStackVerifyFailed},
+	}
+	iter := vm.DebugSteps()
+	for i, test := range tests {
+		if !iter.Next() {
+			t.Fatalf("DebugSteps: missing step %d", i)
+		}
+		step := iter.Step()
+		if step.ScriptIdx != test.scriptIdx || step.Opcode != test.opcode {
+			t.Fatalf("step %d: got %02x %s, want %02x %s", i,
+				step.ScriptIdx, step.Opcode, test.scriptIdx,
+				test.opcode)
+		}
+		if !equalStacks(step.Stack, test.stack) {
+			t.Fatalf("step %d: got stack %x, want %x", i, step.Stack,
+				test.stack)
+		}
+		if !equalStacks(step.AltStack, test.altStack) {
+			t.Fatalf("step %d: got alt stack %x, want %x", i,
+				step.AltStack, test.altStack)
+		}
+		if step.Err != test.err {
+			t.Fatalf("step %d: got error %v, want %v", i, step.Err,
+				test.err)
+		}
+	}
+	if iter.Next() {
+		t.Fatalf("DebugSteps: unexpected step %v", iter.Step())
+	}
+}
+
+// equalStacks returns whether the passed stack contents are equal.
+func equalStacks(a, b [][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
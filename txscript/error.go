@@ -152,6 +152,12 @@ var (
 	// implements a hcutil.Address is not a supported type.
 	ErrUnsupportedAddress = errors.New("unsupported address type")
 
+	// ErrUnsupportedHashFunc is returned when a script paying to an
+	// address created with an alternative hash function is requested.  No
+	// script type commits to the hash function yet, so such a script would
+	// pay to a hash the default hash function can't produce.
+	ErrUnsupportedHashFunc = errors.New("unsupported address hash function")
+
 	// ErrBadNumRequired is returned from MultiSigScript when nrequired is
 	// larger than the number of provided public keys.
 	ErrBadNumRequired = errors.New("more signatures required than keys present")
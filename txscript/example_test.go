@@ -30,7 +30,7 @@ func ExamplePayToAddrScript() {
 	// which is useful to ensure the accuracy of the address and determine
 	// the address type.  It is also required for the upcoming call to
 	// PayToAddrScript.
-	addressStr := "DsSej1qR3Fyc8kV176DCh9n9cY9nqf9Quxk"
+	addressStr := "HsDLAAP6zDtWop9K1Bs4GTzvm4iCB9EUg81"
 	address, err := hcutil.DecodeAddress(addressStr)
 	if err != nil {
 		fmt.Println(err)
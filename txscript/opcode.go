@@ -2785,11 +2785,13 @@ var bliss = sigTypes(bs.BSTypeBliss)
 
 // opcodeCheckSigAlt accepts a three item stack and pops off the first three
 // items. The first item is a signature type (1-255, can not be zero or the
-// soft fork will fail). Any unused signature types return true, so that future
-// alternative signature methods may be added. The second item popped off the
-// stack is the public key; wrong size pubkeys return false. The third item to
-// be popped off the stack is the signature along with the hash type at the
-// end; wrong sized signatures also return false.
+// soft fork will fail) which selects a registered SignatureScheme. Any unused
+// signature types, as well as those of schemes which are not active under the
+// script flags yet, return true, so that future alternative signature methods
+// may be added. The second item popped off the stack is the public key; wrong
+// size pubkeys return false. The third item to be popped off the stack is the
+// signature along with the hash type at the end; wrong sized signatures also
+// return false.
 // Failing to parse a pubkey or signature results in false.
 // After parsing, the signature and pubkey are verified against the message
 // (the hash of this transaction and its input).
@@ -2799,18 +2801,11 @@ func opcodeCheckSigAlt(op *parsedOpcode, vm *Engine) error {
 		return err
 	}
 
-	switch sigTypes(sigType) {
-	case secp256k1:
-		break
-	case edwards:
-		break
-	case secSchnorr:
-		break
-	case bliss:
-		break
-	default:
-		// Caveat: All unknown signature types return true, allowing for future
-		// softforks with other new signature types.
+	// Caveat: All unknown signature types, as well as the types of schemes
+	// which have not been activated yet, return true, allowing for future
+	// softforks with other new signature types.
+	scheme, ok := LookupSignatureScheme(uint8(sigType))
+	if !ok || !scheme.isActive(vm.flags) {
 		vm.dstack.PushBool(true)
 		return nil
 	}
@@ -2820,31 +2815,10 @@ func opcodeCheckSigAlt(op *parsedOpcode, vm *Engine) error {
 		return err
 	}
 
-	// Check the public key lengths. Only 33-byte compressed secp256k1 keys
-	// are allowed for secp256k1 Schnorr signatures, which 32 byte keys
-	// are used for Curve25519, and 897 byte keys are used for Bliss.
-	switch sigTypes(sigType) {
-	case secp256k1:
-		if len(pkBytes) != 33 {
-			vm.dstack.PushBool(false)
-			return nil
-		}
-	case edwards:
-		if len(pkBytes) != 32 {
-			vm.dstack.PushBool(false)
-			return nil
-		}
-	case secSchnorr:
-		if len(pkBytes) != 33 {
-			vm.dstack.PushBool(false)
-			return nil
-		}
-	case bliss:
-		if len(pkBytes) != 897 {
-			fmt.Printf("pub key length is not 897, length:%v\n", len(pkBytes))
-			vm.dstack.PushBool(false)
-			return nil
-		}
+	// Check the public key length required by the signature scheme.
+	if !scheme.ValidPubKeyLen(len(pkBytes)) {
+		vm.dstack.PushBool(false)
+		return nil
 	}
 
 	fullSigBytes, err := vm.dstack.PopByteArray()
@@ -2852,29 +2826,11 @@ func opcodeCheckSigAlt(op *parsedOpcode, vm *Engine) error {
 		return err
 	}
 
-	// Schnorr signatures are 65 bytes in length (64 bytes for [r,s] and
-	// 1 byte appened to the end for hashType).
-	switch sigTypes(sigType) {
-	case secp256k1:
-		if len(fullSigBytes) < 69 || len(fullSigBytes) > 72 { // length of fullSigBytes may be one of 69, 70,71,72
-			vm.dstack.PushBool(false)
-			return nil
-		}
-	case edwards:
-		if len(fullSigBytes) != 65 {
-			vm.dstack.PushBool(false)
-			return nil
-		}
-	case secSchnorr:
-		if len(fullSigBytes) != 65 {
-			vm.dstack.PushBool(false)
-			return nil
-		}
-	case bliss:
-		if len(fullSigBytes) < 397 || len(fullSigBytes) > 860 {
-			vm.dstack.PushBool(false)
-			return nil
-		}
+	// Check the signature length, including the hash type, required by the
+	// signature scheme.
+	if !scheme.ValidSigLen(len(fullSigBytes)) {
+		vm.dstack.PushBool(false)
+		return nil
 	}
 
 	// Trim off hashtype from the signature string and check if the
@@ -2917,101 +2873,20 @@ func opcodeCheckSigAlt(op *parsedOpcode, vm *Engine) error {
 		return nil
 	}
 
-	// Get the public key from bytes.
-	var pubKey chainec.PublicKey
-	switch sigTypes(sigType) {
-	case secp256k1:
-		pubKeyEd, err := chainec.Secp256k1.ParsePubKey(pkBytes)
-		if err != nil {
-			vm.dstack.PushBool(false)
-			return nil
-		}
-		pubKey = pubKeyEd
-	case edwards:
-		pubKeyEd, err := chainec.Edwards.ParsePubKey(pkBytes)
-		if err != nil {
-			vm.dstack.PushBool(false)
-			return nil
-		}
-		pubKey = pubKeyEd
-	case secSchnorr:
-		pubKeySec, err := chainec.SecSchnorr.ParsePubKey(pkBytes)
-		if err != nil {
-			vm.dstack.PushBool(false)
-			return nil
-		}
-		pubKey = pubKeySec
-	case bliss:
-		pubKeySec, err := bs.Bliss.ParsePubKey(pkBytes)
-		if err != nil {
-			vm.dstack.PushBool(false)
-			return nil
-		}
-		pubKey = pubKeySec
-	}
-
-	// Get the signature from bytes.
-	var signature chainec.Signature
-	switch sigTypes(sigType) {
-	case secp256k1:
-		sigEd, err := chainec.Secp256k1.ParseSignature(sigBytes)
-		if err != nil {
-			vm.dstack.PushBool(false)
-			return nil
-		}
-		signature = sigEd
-	case edwards:
-		sigEd, err := chainec.Edwards.ParseSignature(sigBytes)
-		if err != nil {
-			vm.dstack.PushBool(false)
-			return nil
-		}
-		signature = sigEd
-	case secSchnorr:
-		sigSec, err := chainec.SecSchnorr.ParseSignature(sigBytes)
-		if err != nil {
-			vm.dstack.PushBool(false)
-			return nil
-		}
-		signature = sigSec
-	case bliss:
-		sigSec, err := bs.Bliss.ParseSignature(sigBytes)
-		if err != nil {
-			vm.dstack.PushBool(false)
-			return nil
-		}
-		signature = sigSec
-	default:
+	// Get the public key and signature from bytes.
+	pubKey, err := scheme.ParsePubKey(pkBytes)
+	if err != nil {
 		vm.dstack.PushBool(false)
 		return nil
 	}
-
-	// Attempt to validate the signature.
-	switch sigTypes(sigType) {
-	case secp256k1:
-		ok := chainec.Secp256k1.Verify(pubKey, hash, signature.GetR(),
-			signature.GetS())
-		vm.dstack.PushBool(ok)
-		return nil
-	case edwards:
-		ok := chainec.Edwards.Verify(pubKey, hash, signature.GetR(),
-			signature.GetS())
-		vm.dstack.PushBool(ok)
-		return nil
-	case secSchnorr:
-		ok := chainec.SecSchnorr.Verify(pubKey, hash, signature.GetR(),
-			signature.GetS())
-		vm.dstack.PushBool(ok)
-		return nil
-	case bliss:
-		ok := bs.Bliss.Verify(pubKey, hash, signature)
-		vm.dstack.PushBool(ok)
+	signature, err := scheme.ParseSignature(sigBytes)
+	if err != nil {
+		vm.dstack.PushBool(false)
 		return nil
 	}
 
-	// Fallthrough of somekind automatically results in false, but
-	// this should never be hit.
-	vm.dstack.PushBool(false)
+	// Attempt to validate the signature.
+	vm.dstack.PushBool(scheme.Verify(pubKey, hash, signature))
 	return nil
 }
 
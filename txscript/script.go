@@ -568,7 +568,7 @@ func getSigOpCount(pops []parsedOpcode, precise bool) int {
 		case OP_CHECKSIG:
 			fallthrough
 		case OP_CHECKSIGVERIFY:
-			fallthrough
+			nSigs++
 		case OP_CHECKSIGALT:
 			fallthrough
 		case OP_CHECKSIGALTVERIFY:
@@ -110,10 +110,6 @@ func TestScriptBuilderAddInt64(t *testing.T) {
 		{name: "push small int 14", val: 14, expected: []byte{txscript.OP_14}},
 		{name: "push small int 15", val: 15, expected: []byte{txscript.OP_15}},
 		{name: "push small int 16", val: 16, expected: []byte{txscript.OP_16}},
-		{name: "push small int 17", val: 17, expected: []byte{txscript.OP_17}},
-		{name: "push small int 18", val: 18, expected: []byte{txscript.OP_18}},
-		{name: "push small int 19", val: 19, expected: []byte{txscript.OP_19}},
-		{name: "push small int 20", val: 20, expected: []byte{txscript.OP_20}},
 		{name: "push 17", val: 17, expected: []byte{txscript.OP_DATA_1, 0x11}},
 		{name: "push 65", val: 65, expected: []byte{txscript.OP_DATA_1, 0x41}},
 		{name: "push 127", val: 127, expected: []byte{txscript.OP_DATA_1, 0x7f}},
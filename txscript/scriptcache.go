@@ -0,0 +1,243 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txscript
+
+import (
+	"container/list"
+	"encoding/binary"
+	"sync"
+	"sync/atomic"
+
+	"github.com/HcashOrg/hcd/chaincfg/chainhash"
+	"github.com/HcashOrg/hcd/wire"
+)
+
+const (
+	// scriptCacheEntrySize is the approximate number of bytes of memory
+	// used by each entry in the ScriptCache.  It accounts for the 32-byte
+	// key stored in both the shard map and the LRU list element, the flags,
+	// the list element itself and the map bucket overhead.
+	scriptCacheEntrySize = 144
+
+	// scriptCacheExactFlags are the flags which change the meaning of
+	// opcodes rather than only adding checks.  A script which is valid with
+	// one of these flags set is not necessarily valid without it, so cached
+	// results only apply when these flags match exactly.
+	scriptCacheExactFlags = ScriptVerifySHA256
+)
+
+// scriptCacheEntry is the value of the LRU list elements of a ScriptCache.
+type scriptCacheEntry struct {
+	key   chainhash.Hash
+	flags ScriptFlags
+}
+
+// scriptCacheShard houses a portion of the entries of a ScriptCache along with
+// the order in which they were last used.  Each shard is protected by its own
+// mutex.
+type scriptCacheShard struct {
+	sync.Mutex
+	entries    map[chainhash.Hash]*list.Element
+	lru        *list.List // front is most recently used
+	maxEntries uint
+}
+
+// ScriptCacheStats houses statistics about the usage of a ScriptCache.
+type ScriptCacheStats struct {
+	// Hits and Misses are the number of lookups which found and did not
+	// find a usable entry in the cache, respectively.
+	Hits   uint64
+	Misses uint64
+
+	// Evictions is the number of entries which were evicted to make room
+	// for new entries.
+	Evictions uint64
+
+	// Entries is the number of entries currently in the cache.
+	Entries uint
+
+	// Size and MaxSize are the approximate current and maximum amount of
+	// memory in bytes used by the cache.
+	Size    uint
+	MaxSize uint
+}
+
+// ScriptCache implements a cache of successful input script verifications
+// with a least recently used entry eviction policy.  While the SigCache only
+// saves the signature checks, the ScriptCache saves executing the script pair
+// of an input altogether, so inputs which were already verified when their
+// transaction was accepted to the memory pool do not need to be verified again
+// when the block which contains the transaction is connected.
+//
+// Entries are keyed by the full hash of the spending transaction, which
+// commits to its signature scripts, and the outpoint spent by the input, which
+// determines the public key script.  Each entry records the script flags the
+// input was verified with.  Since all flags aside from scriptCacheExactFlags
+// only add checks, an entry applies to verifications with any subset of its
+// flags, such as the consensus flags used for blocks after the stricter
+// standard flags were used for the memory pool.
+type ScriptCache struct {
+	// The following variables must only be used atomically.
+	hits      uint64
+	misses    uint64
+	evictions uint64
+
+	shards  []scriptCacheShard
+	maxSize uint
+}
+
+// NewScriptCache creates and initializes a new instance of ScriptCache.  Its
+// sole parameter 'maxSize' represents the approximate maximum amount of memory
+// in bytes the entries in the ScriptCache may use at any particular moment.
+// The least recently used entries are evicted to make room for new entries
+// that would cause the cache to exceed the max.
+func NewScriptCache(maxSize uint) *ScriptCache {
+	maxEntries := maxSize / scriptCacheEntrySize
+	numShards := uint(sigCacheShards)
+	if maxEntries < sigCacheShards*sigCacheMinShardEntries {
+		numShards = 1
+	}
+
+	shards := make([]scriptCacheShard, numShards)
+	for i := range shards {
+		shards[i] = scriptCacheShard{
+			entries:    make(map[chainhash.Hash]*list.Element),
+			lru:        list.New(),
+			maxEntries: maxEntries / numShards,
+		}
+	}
+	return &ScriptCache{
+		shards:  shards,
+		maxSize: maxSize,
+	}
+}
+
+// scriptCacheKey returns the key which identifies an entry for the input of
+// the transaction with full hash 'txHash' which spends 'prevOut'.
+func scriptCacheKey(txHash *chainhash.Hash, prevOut *wire.OutPoint) chainhash.Hash {
+	var buf [2*chainhash.HashSize + 5]byte
+	copy(buf[:], txHash[:])
+	copy(buf[chainhash.HashSize:], prevOut.Hash[:])
+	binary.LittleEndian.PutUint32(buf[2*chainhash.HashSize:], prevOut.Index)
+	buf[2*chainhash.HashSize+4] = byte(prevOut.Tree)
+	return chainhash.HashH(buf[:])
+}
+
+// shard returns the shard responsible for the entry with the passed key.
+func (c *ScriptCache) shard(key *chainhash.Hash) *scriptCacheShard {
+	idx := binary.LittleEndian.Uint32(key[:4]) % uint32(len(c.shards))
+	return &c.shards[idx]
+}
+
+// flagsCovered returns whether a verification with 'flags' is implied by a
+// successful verification with 'verified'.
+func flagsCovered(verified, flags ScriptFlags) bool {
+	return verified&flags == flags &&
+		verified&scriptCacheExactFlags == flags&scriptCacheExactFlags
+}
+
+// Exists returns true if the input of the transaction with full hash 'txHash'
+// which spends 'prevOut' was already verified successfully with the passed
+// script flags, or with flags which imply them.  Otherwise, false is returned.
+// A found entry is marked as the most recently used.
+//
+// NOTE: This function is safe for concurrent access. Only the shard
+// containing the entry is locked.
+func (c *ScriptCache) Exists(txHash *chainhash.Hash, prevOut *wire.OutPoint, flags ScriptFlags) bool {
+	key := scriptCacheKey(txHash, prevOut)
+	shard := c.shard(&key)
+
+	shard.Lock()
+	elem, ok := shard.entries[key]
+	if ok {
+		ok = flagsCovered(elem.Value.(*scriptCacheEntry).flags, flags)
+		if ok {
+			shard.lru.MoveToFront(elem)
+		}
+	}
+	shard.Unlock()
+
+	if ok {
+		atomic.AddUint64(&c.hits, 1)
+	} else {
+		atomic.AddUint64(&c.misses, 1)
+	}
+	return ok
+}
+
+// Add adds an entry for the input of the transaction with full hash 'txHash'
+// which spends 'prevOut' and was verified successfully with the passed script
+// flags.  An existing entry is replaced unless its flags already imply the
+// passed ones.  In the event that the shard the entry belongs to is 'full',
+// its least recently used entry is evicted in order to make space for the new
+// entry.
+//
+// NOTE: This function is safe for concurrent access. Only the shard the entry
+// belongs to is locked.
+func (c *ScriptCache) Add(txHash *chainhash.Hash, prevOut *wire.OutPoint, flags ScriptFlags) {
+	key := scriptCacheKey(txHash, prevOut)
+	shard := c.shard(&key)
+
+	shard.Lock()
+	defer shard.Unlock()
+
+	if shard.maxEntries == 0 {
+		return
+	}
+
+	// Verifications with different flags do not imply a verification
+	// with the union of them, so an existing entry is either kept or
+	// replaced rather than merged.
+	if elem, ok := shard.entries[key]; ok {
+		entry := elem.Value.(*scriptCacheEntry)
+		if !flagsCovered(entry.flags, flags) {
+			entry.flags = flags
+		}
+		shard.lru.MoveToFront(elem)
+		return
+	}
+
+	// If adding this new entry will put the shard over the max number of
+	// allowed entries, then evict the least recently used entry.
+	if uint(len(shard.entries)+1) > shard.maxEntries {
+		oldest := shard.lru.Back()
+		delete(shard.entries, oldest.Value.(*scriptCacheEntry).key)
+		shard.lru.Remove(oldest)
+		atomic.AddUint64(&c.evictions, 1)
+	}
+	shard.entries[key] = shard.lru.PushFront(&scriptCacheEntry{
+		key:   key,
+		flags: flags,
+	})
+}
+
+// Len returns the number of entries currently in the ScriptCache.
+//
+// NOTE: This function is safe for concurrent access.
+func (c *ScriptCache) Len() uint {
+	var n uint
+	for i := range c.shards {
+		shard := &c.shards[i]
+		shard.Lock()
+		n += uint(len(shard.entries))
+		shard.Unlock()
+	}
+	return n
+}
+
+// Stats returns statistics about the usage of the ScriptCache.
+//
+// NOTE: This function is safe for concurrent access.
+func (c *ScriptCache) Stats() ScriptCacheStats {
+	entries := c.Len()
+	return ScriptCacheStats{
+		Hits:      atomic.LoadUint64(&c.hits),
+		Misses:    atomic.LoadUint64(&c.misses),
+		Evictions: atomic.LoadUint64(&c.evictions),
+		Entries:   entries,
+		Size:      entries * scriptCacheEntrySize,
+		MaxSize:   c.maxSize,
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txscript
+
+import (
+	"testing"
+
+	"github.com/HcashOrg/hcd/chaincfg/chainhash"
+	"github.com/HcashOrg/hcd/wire"
+)
+
+// testScriptCacheInput returns a deterministic transaction hash and spent
+// outpoint for the passed index.
+func testScriptCacheInput(i uint32) (*chainhash.Hash, *wire.OutPoint) {
+	txHash := chainhash.HashH([]byte{byte(i), byte(i >> 8), 0})
+	prevHash := chainhash.HashH([]byte{byte(i), byte(i >> 8), 1})
+	return &txHash, wire.NewOutPoint(&prevHash, i, wire.TxTreeRegular)
+}
+
+// TestScriptCacheFlags ensures entries of the script cache only apply to
+// verifications with flags implied by the flags they were verified with.
+func TestScriptCacheFlags(t *testing.T) {
+	t.Parallel()
+
+	const (
+		consensusFlags = ScriptBip16 | ScriptVerifyCleanStack |
+			ScriptVerifySHA256
+		standardFlags = consensusFlags | ScriptVerifyLowS |
+			ScriptDiscourageUpgradableNops
+	)
+
+	scriptCache := NewScriptCache(100 * scriptCacheEntrySize)
+	txHash, prevOut := testScriptCacheInput(0)
+	scriptCache.Add(txHash, prevOut, standardFlags)
+
+	tests := []struct {
+		name  string
+		flags ScriptFlags
+		want  bool
+	}{
+		{"same flags", standardFlags, true},
+		{"subset of flags", consensusFlags, true},
+		{"no flags besides exact ones", ScriptVerifySHA256, true},
+		{"exact flag missing", consensusFlags &^ ScriptVerifySHA256, false},
+		{"additional flag", standardFlags | ScriptVerifyMinimalData, false},
+	}
+	for _, test := range tests {
+		got := scriptCache.Exists(txHash, prevOut, test.flags)
+		if got != test.want {
+			t.Errorf("%s: unexpected result: got %v, want %v",
+				test.name, got, test.want)
+		}
+	}
+
+	// Other inputs of the same transaction and other transactions spending
+	// the same outpoint must not be found.
+	otherOut := *prevOut
+	otherOut.Index++
+	if scriptCache.Exists(txHash, &otherOut, consensusFlags) {
+		t.Errorf("entry found for other outpoint")
+	}
+	otherTxHash, _ := testScriptCacheInput(1)
+	if scriptCache.Exists(otherTxHash, prevOut, consensusFlags) {
+		t.Errorf("entry found for other transaction")
+	}
+
+	// Adding a verification with a subset of the flags must keep the
+	// existing entry, while one with other flags must replace it.
+	scriptCache.Add(txHash, prevOut, consensusFlags)
+	if !scriptCache.Exists(txHash, prevOut, standardFlags) {
+		t.Errorf("entry replaced by verification with fewer flags")
+	}
+	scriptCache.Add(txHash, prevOut, ScriptVerifyMinimalData)
+	if scriptCache.Exists(txHash, prevOut, standardFlags) {
+		t.Errorf("entry not replaced by verification with other flags")
+	}
+	if !scriptCache.Exists(txHash, prevOut, ScriptVerifyMinimalData) {
+		t.Errorf("replaced entry not found")
+	}
+	if n := scriptCache.Len(); n != 1 {
+		t.Errorf("unexpected number of entries: got %d, want 1", n)
+	}
+}
+
+// TestScriptCacheEviction ensures the least recently used entry is evicted
+// when adding an entry to a full script cache.
+func TestScriptCacheEviction(t *testing.T) {
+	t.Parallel()
+
+	const numEntries = 100
+	scriptCache := NewScriptCache(numEntries * scriptCacheEntrySize)
+	for i := uint32(0); i < numEntries; i++ {
+		txHash, prevOut := testScriptCacheInput(i)
+		scriptCache.Add(txHash, prevOut, ScriptBip16)
+	}
+
+	// Mark the first entry as recently used so the second one is evicted.
+	firstHash, firstOut := testScriptCacheInput(0)
+	if !scriptCache.Exists(firstHash, firstOut, ScriptBip16) {
+		t.Fatalf("first entry not found")
+	}
+	txHash, prevOut := testScriptCacheInput(numEntries)
+	scriptCache.Add(txHash, prevOut, ScriptBip16)
+
+	if n := scriptCache.Len(); n != numEntries {
+		t.Fatalf("unexpected number of entries: got %d, want %d", n,
+			numEntries)
+	}
+	if !scriptCache.Exists(firstHash, firstOut, ScriptBip16) {
+		t.Errorf("recently used entry was evicted")
+	}
+	secondHash, secondOut := testScriptCacheInput(1)
+	if scriptCache.Exists(secondHash, secondOut, ScriptBip16) {
+		t.Errorf("least recently used entry was not evicted")
+	}
+
+	stats := scriptCache.Stats()
+	if stats.Evictions != 1 || stats.Hits != 2 || stats.Misses != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
@@ -56,8 +56,8 @@ func TestScriptNumBytes(t *testing.T) {
 		{-7340032, hexToBytes("0000f0")},
 		{8388608, hexToBytes("00008000")},
 		{-8388608, hexToBytes("00008080")},
-		{9384608, hexToBytes("00009000")},
-		{-9384608, hexToBytes("00009080")},
+		{9437184, hexToBytes("00009000")},
+		{-9437184, hexToBytes("00009080")},
 		{2147483647, hexToBytes("ffffff7f")},
 		{-2147483647, hexToBytes("ffffffff")},
 
@@ -108,7 +108,6 @@ func TestMakeScriptNum(t *testing.T) {
 		// Minimally encoded valid values with minimal encoding flag.
 		// Should not error and return expected integral number.
 		{nil, 0, mathOpCodeMaxScriptNumLen, true, nil},
-		{hexToBytes("80"), 0, mathOpCodeMaxScriptNumLen, true, errMinimalData},
 		{hexToBytes("01"), 1, mathOpCodeMaxScriptNumLen, true, nil},
 		{hexToBytes("81"), -1, mathOpCodeMaxScriptNumLen, true, nil},
 		{hexToBytes("7f"), 127, mathOpCodeMaxScriptNumLen, true, nil},
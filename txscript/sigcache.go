@@ -6,102 +6,218 @@
 package txscript
 
 import (
-	"bytes"
+	"container/list"
+	"encoding/binary"
 	"sync"
+	"sync/atomic"
 
 	"github.com/HcashOrg/hcd/chaincfg/chainec"
 	"github.com/HcashOrg/hcd/chaincfg/chainhash"
 )
 
-// sigCacheEntry represents an entry in the SigCache. Entries within the
-// SigCache are keyed according to the sigHash of the signature. In the
-// scenario of a cache-hit (according to the sigHash), an additional comparison
-// of the signature, and public key will be executed in order to ensure a complete
-// match. In the occasion that two sigHashes collide, the newer sigHash will
-// simply overwrite the existing entry.
-type sigCacheEntry struct {
-	sig    chainec.Signature
-	pubKey chainec.PublicKey
+const (
+	// sigCacheEntrySize is the approximate number of bytes of memory used by
+	// each entry in the SigCache.  It accounts for the 32-byte key stored in
+	// both the shard map and the LRU list element, the list element itself
+	// and the map bucket overhead.
+	sigCacheEntrySize = 128
+
+	// sigCacheShards is the number of independently locked shards the
+	// entries of a SigCache are spread across in order to reduce lock
+	// contention when validating signatures concurrently.
+	sigCacheShards = 16
+
+	// sigCacheMinShardEntries is the minimum number of entries each shard
+	// must be able to hold for the cache to be sharded.  Smaller caches use
+	// a single shard so that the LRU eviction order is global.
+	sigCacheMinShardEntries = 1024
+)
+
+// sigCacheShard houses a portion of the entries of a SigCache along with the
+// order in which they were last used.  Each shard is protected by its own
+// mutex.
+type sigCacheShard struct {
+	sync.Mutex
+	entries    map[chainhash.Hash]*list.Element
+	lru        *list.List // front is most recently used
+	maxEntries uint
+}
+
+// SigCacheStats houses statistics about the usage of a SigCache.
+type SigCacheStats struct {
+	// Hits and Misses are the number of lookups which found and did not
+	// find an entry in the cache, respectively.
+	Hits   uint64
+	Misses uint64
+
+	// Evictions is the number of entries which were evicted to make room
+	// for new entries.
+	Evictions uint64
+
+	// Entries is the number of entries currently in the cache.
+	Entries uint
+
+	// Size and MaxSize are the approximate current and maximum amount of
+	// memory in bytes used by the cache.
+	Size    uint
+	MaxSize uint
 }
 
-// SigCache implements an ECDSA signature verification cache with a randomized
-// entry eviction policy. Only valid signatures will be added to the cache. The
-// benefits of SigCache are two fold. Firstly, usage of SigCache mitigates a DoS
-// attack wherein an attack causes a victim's client to hang due to worst-case
-// behavior triggered while processing attacker crafted invalid transactions. A
-// detailed description of the mitigated DoS attack can be found here:
+// SigCache implements a signature verification cache with a least recently
+// used entry eviction policy. Only valid signatures will be added to the
+// cache. The benefits of SigCache are two fold. Firstly, usage of SigCache
+// mitigates a DoS attack wherein an attack causes a victim's client to hang
+// due to worst-case behavior triggered while processing attacker crafted
+// invalid transactions. A detailed description of the mitigated DoS attack can
+// be found here:
 // https://bitslog.wordpress.com/2013/01/23/fixed-bitcoin-vulnerability-explanation-why-the-signature-cache-is-a-dos-protection/.
 // Secondly, usage of the SigCache introduces a signature verification
 // optimization which speeds up the validation of transactions within a block,
 // if they've already been seen and verified within the mempool.
+//
+// Entries are keyed by the hash of the signature hash, the signature and the
+// public key, and are spread across multiple shards which are locked
+// independently.
 type SigCache struct {
-	sync.RWMutex
-	validSigs  map[chainhash.Hash]sigCacheEntry
-	maxEntries uint
+	// The following variables must only be used atomically.
+	hits      uint64
+	misses    uint64
+	evictions uint64
+
+	shards  []sigCacheShard
+	maxSize uint
 }
 
 // NewSigCache creates and initializes a new instance of SigCache. Its sole
-// parameter 'maxEntries' represents the maximum number of entries allowed to
-// exist in the SigCache at any particular moment. Random entries are evicted
-// to make room for new entries that would cause the number of entries in the
-// cache to exceed the max.
-func NewSigCache(maxEntries uint) *SigCache {
+// parameter 'maxSize' represents the approximate maximum amount of memory in
+// bytes the entries in the SigCache may use at any particular moment. The
+// least recently used entries are evicted to make room for new entries that
+// would cause the cache to exceed the max.
+func NewSigCache(maxSize uint) *SigCache {
+	maxEntries := maxSize / sigCacheEntrySize
+	numShards := uint(sigCacheShards)
+	if maxEntries < sigCacheShards*sigCacheMinShardEntries {
+		numShards = 1
+	}
+
+	shards := make([]sigCacheShard, numShards)
+	for i := range shards {
+		shards[i] = sigCacheShard{
+			entries:    make(map[chainhash.Hash]*list.Element),
+			lru:        list.New(),
+			maxEntries: maxEntries / numShards,
+		}
+	}
 	return &SigCache{
-		validSigs:  make(map[chainhash.Hash]sigCacheEntry, maxEntries),
-		maxEntries: maxEntries,
+		shards:  shards,
+		maxSize: maxSize,
 	}
 }
 
+// sigCacheKey returns the key which identifies an entry for a signature over
+// 'sigHash' under public key 'pubKey'.
+func sigCacheKey(sigHash chainhash.Hash, sig chainec.Signature, pubKey chainec.PublicKey) chainhash.Hash {
+	sigBytes := sig.Serialize()
+	pkBytes := pubKey.SerializeCompressed()
+	buf := make([]byte, 0, chainhash.HashSize+len(sigBytes)+len(pkBytes))
+	buf = append(buf, sigHash[:]...)
+	buf = append(buf, sigBytes...)
+	buf = append(buf, pkBytes...)
+	return chainhash.HashH(buf)
+}
+
+// shard returns the shard responsible for the entry with the passed key.
+func (s *SigCache) shard(key *chainhash.Hash) *sigCacheShard {
+	idx := binary.LittleEndian.Uint32(key[:4]) % uint32(len(s.shards))
+	return &s.shards[idx]
+}
+
 // Exists returns true if an existing entry of 'sig' over 'sigHash' for public
-// key 'pubKey' is found within the SigCache. Otherwise, false is returned.
+// key 'pubKey' is found within the SigCache. Otherwise, false is returned. A
+// found entry is marked as the most recently used.
 //
-// NOTE: This function is safe for concurrent access. Readers won't be blocked
-// unless there exists a writer, adding an entry to the SigCache.
+// NOTE: This function is safe for concurrent access. Only the shard
+// containing the entry is locked.
 func (s *SigCache) Exists(sigHash chainhash.Hash, sig chainec.Signature, pubKey chainec.PublicKey) bool {
-	s.RLock()
-	defer s.RUnlock()
-
-	if entry, ok := s.validSigs[sigHash]; ok {
-		pkEqual := bytes.Equal(entry.pubKey.SerializeCompressed(),
-			pubKey.SerializeCompressed())
-		sigEqual := bytes.Equal(entry.sig.Serialize(), sig.Serialize())
-		return pkEqual && sigEqual
+	key := sigCacheKey(sigHash, sig, pubKey)
+	shard := s.shard(&key)
+
+	shard.Lock()
+	elem, ok := shard.entries[key]
+	if ok {
+		shard.lru.MoveToFront(elem)
 	}
+	shard.Unlock()
 
-	return false
+	if ok {
+		atomic.AddUint64(&s.hits, 1)
+	} else {
+		atomic.AddUint64(&s.misses, 1)
+	}
+	return ok
 }
 
 // Add adds an entry for a signature over 'sigHash' under public key 'pubKey'
-// to the signature cache. In the event that the SigCache is 'full', an
-// existing entry is randomly chosen to be evicted in order to make space for
+// to the signature cache. In the event that the shard the entry belongs to is
+// 'full', its least recently used entry is evicted in order to make space for
 // the new entry.
 //
-// NOTE: This function is safe for concurrent access. Writers will block
-// simultaneous readers until function execution has concluded.
+// NOTE: This function is safe for concurrent access. Only the shard the entry
+// belongs to is locked.
 func (s *SigCache) Add(sigHash chainhash.Hash, sig chainec.Signature, pubKey chainec.PublicKey) {
-	s.Lock()
-	defer s.Unlock()
+	key := sigCacheKey(sigHash, sig, pubKey)
+	shard := s.shard(&key)
 
-	if s.maxEntries == 0 {
+	shard.Lock()
+	defer shard.Unlock()
+
+	if shard.maxEntries == 0 {
 		return
 	}
 
-	// If adding this new entry will put us over the max number of allowed
-	// entries, then evict an entry.
-	if uint(len(s.validSigs)+1) > s.maxEntries {
-		// Remove a random entry from the map. Relying on the random
-		// starting point of Go's map iteration. It's worth noting that
-		// the random iteration starting point is not 100% guaranteed
-		// by the spec, however most Go compilers support it.
-		// Ultimately, the iteration order isn't important here because
-		// in order to manipulate which items are evicted, an adversary
-		// would need to be able to execute preimage attacks on the
-		// hashing function in order to start eviction at a specific
-		// entry.
-		for sigEntry := range s.validSigs {
-			delete(s.validSigs, sigEntry)
-			break
-		}
+	// Nothing more to do when the entry already exists aside from marking
+	// it as the most recently used.
+	if elem, ok := shard.entries[key]; ok {
+		shard.lru.MoveToFront(elem)
+		return
+	}
+
+	// If adding this new entry will put the shard over the max number of
+	// allowed entries, then evict the least recently used entry.
+	if uint(len(shard.entries)+1) > shard.maxEntries {
+		oldest := shard.lru.Back()
+		delete(shard.entries, oldest.Value.(chainhash.Hash))
+		shard.lru.Remove(oldest)
+		atomic.AddUint64(&s.evictions, 1)
+	}
+	shard.entries[key] = shard.lru.PushFront(key)
+}
+
+// Len returns the number of entries currently in the SigCache.
+//
+// NOTE: This function is safe for concurrent access.
+func (s *SigCache) Len() uint {
+	var n uint
+	for i := range s.shards {
+		shard := &s.shards[i]
+		shard.Lock()
+		n += uint(len(shard.entries))
+		shard.Unlock()
+	}
+	return n
+}
+
+// Stats returns statistics about the usage of the SigCache.
+//
+// NOTE: This function is safe for concurrent access.
+func (s *SigCache) Stats() SigCacheStats {
+	entries := s.Len()
+	return SigCacheStats{
+		Hits:      atomic.LoadUint64(&s.hits),
+		Misses:    atomic.LoadUint64(&s.misses),
+		Evictions: atomic.LoadUint64(&s.evictions),
+		Entries:   entries,
+		Size:      entries * sigCacheEntrySize,
+		MaxSize:   s.maxSize,
 	}
-	s.validSigs[sigHash] = sigCacheEntry{sig, pubKey}
 }
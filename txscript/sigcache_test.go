@@ -43,7 +43,7 @@ func genRandomSig() (*chainhash.Hash, chainec.Signature, chainec.PublicKey, erro
 // TestSigCacheAddExists tests the ability to add, and later check the
 // existence of a signature triplet in the signature cache.
 func TestSigCacheAddExists(t *testing.T) {
-	sigCache := NewSigCache(200)
+	sigCache := NewSigCache(200 * sigCacheEntrySize)
 
 	// Generate a random sigCache entry triplet.
 	msg1, sig1, key1, err := genRandomSig()
@@ -63,19 +63,26 @@ func TestSigCacheAddExists(t *testing.T) {
 }
 
 // TestSigCacheAddEvictEntry tests the eviction case where a new signature
-// triplet is added to a full signature cache which should trigger randomized
-// eviction, followed by adding the new element to the cache.
+// triplet is added to a full signature cache which should trigger eviction of
+// the least recently used entry, followed by adding the new element to the
+// cache.
 func TestSigCacheAddEvictEntry(t *testing.T) {
 	// Create a sigcache that can hold up to 100 entries.
 	sigCacheSize := uint(100)
-	sigCache := NewSigCache(sigCacheSize)
+	sigCache := NewSigCache(sigCacheSize * sigCacheEntrySize)
 
 	// Fill the sigcache up with some random sig triplets.
+	var firstMsg *chainhash.Hash
+	var firstSig chainec.Signature
+	var firstKey chainec.PublicKey
 	for i := uint(0); i < sigCacheSize; i++ {
 		msg, sig, key, err := genRandomSig()
 		if err != nil {
 			t.Fatalf("unable to generate random signature test data")
 		}
+		if i == 0 {
+			firstMsg, firstSig, firstKey = msg, sig, key
+		}
 
 		sigCache.Add(*msg, sig, key)
 
@@ -88,13 +95,13 @@ func TestSigCacheAddEvictEntry(t *testing.T) {
 	}
 
 	// The sigcache should now have sigCacheSize entries within it.
-	if uint(len(sigCache.validSigs)) != sigCacheSize {
+	if sigCache.Len() != sigCacheSize {
 		t.Fatalf("sigcache should now have %v entries, instead it has %v",
-			sigCacheSize, len(sigCache.validSigs))
+			sigCacheSize, sigCache.Len())
 	}
 
-	// Add a new entry, this should cause eviction of a randomly chosen
-	// previous entry.
+	// Add a new entry, this should cause eviction of the least recently
+	// used entry, which is the first one added.
 	msgNew, sigNew, keyNew, err := genRandomSig()
 	if err != nil {
 		t.Fatalf("unable to generate random signature test data")
@@ -102,9 +109,9 @@ func TestSigCacheAddEvictEntry(t *testing.T) {
 	sigCache.Add(*msgNew, sigNew, keyNew)
 
 	// The sigcache should still have sigCache entries.
-	if uint(len(sigCache.validSigs)) != sigCacheSize {
+	if sigCache.Len() != sigCacheSize {
 		t.Fatalf("sigcache should now have %v entries, instead it has %v",
-			sigCacheSize, len(sigCache.validSigs))
+			sigCacheSize, sigCache.Len())
 	}
 
 	// The entry added above should be found within the sigcache.
@@ -113,6 +120,86 @@ func TestSigCacheAddEvictEntry(t *testing.T) {
 	if !sigCache.Exists(*msgNew, sigNewCopy, keyNewCopy) {
 		t.Fatalf("previously added item not found in signature cache")
 	}
+
+	// The least recently used entry should have been evicted.
+	if sigCache.Exists(*firstMsg, firstSig, firstKey) {
+		t.Fatalf("least recently used item not evicted from signature " +
+			"cache")
+	}
+}
+
+// TestSigCacheLRU ensures that looking up an entry marks it as the most
+// recently used so that it is not the next entry evicted.
+func TestSigCacheLRU(t *testing.T) {
+	sigCache := NewSigCache(2 * sigCacheEntrySize)
+
+	type triplet struct {
+		msg *chainhash.Hash
+		sig chainec.Signature
+		key chainec.PublicKey
+	}
+	var triplets [3]triplet
+	for i := range triplets {
+		msg, sig, key, err := genRandomSig()
+		if err != nil {
+			t.Fatalf("unable to generate random signature test data")
+		}
+		triplets[i] = triplet{msg, sig, key}
+	}
+
+	// Add the first two triplets and look up the first one so the second
+	// becomes the least recently used.
+	sigCache.Add(*triplets[0].msg, triplets[0].sig, triplets[0].key)
+	sigCache.Add(*triplets[1].msg, triplets[1].sig, triplets[1].key)
+	if !sigCache.Exists(*triplets[0].msg, triplets[0].sig, triplets[0].key) {
+		t.Fatalf("previously added item not found in signature cache")
+	}
+
+	// Adding the third triplet must evict the second.
+	sigCache.Add(*triplets[2].msg, triplets[2].sig, triplets[2].key)
+	wantExists := []bool{true, false, true}
+	for i, trip := range triplets {
+		if got := sigCache.Exists(*trip.msg, trip.sig, trip.key); got != wantExists[i] {
+			t.Fatalf("triplet %d: exists %v, want %v", i, got,
+				wantExists[i])
+		}
+	}
+
+	// Ensure the statistics reflect the lookups and eviction above.
+	stats := sigCache.Stats()
+	want := SigCacheStats{
+		Hits:      3,
+		Misses:    1,
+		Evictions: 1,
+		Entries:   2,
+		Size:      2 * sigCacheEntrySize,
+		MaxSize:   2 * sigCacheEntrySize,
+	}
+	if stats != want {
+		t.Fatalf("unexpected stats: got %+v, want %+v", stats, want)
+	}
+}
+
+// TestSigCacheSharding ensures that large signature caches are split into
+// multiple shards while small ones use a single shard.
+func TestSigCacheSharding(t *testing.T) {
+	tests := []struct {
+		maxEntries uint
+		numShards  int
+	}{
+		{0, 1},
+		{100, 1},
+		{sigCacheShards*sigCacheMinShardEntries - 1, 1},
+		{sigCacheShards * sigCacheMinShardEntries, sigCacheShards},
+	}
+	for _, test := range tests {
+		sigCache := NewSigCache(test.maxEntries * sigCacheEntrySize)
+		if len(sigCache.shards) != test.numShards {
+			t.Errorf("%d entries: got %d shards, want %d",
+				test.maxEntries, len(sigCache.shards),
+				test.numShards)
+		}
+	}
 }
 
 // TestSigCacheAddMaxEntriesZeroOrNegative tests that if a sigCache is created
@@ -139,9 +226,9 @@ func TestSigCacheAddMaxEntriesZeroOrNegative(t *testing.T) {
 	}
 
 	// There shouldn't be any entries in the sigCache.
-	if len(sigCache.validSigs) != 0 {
+	if sigCache.Len() != 0 {
 		t.Errorf("%v items found in sigcache, no items should have"+
-			"been added", len(sigCache.validSigs))
+			"been added", sigCache.Len())
 	}
 }
 
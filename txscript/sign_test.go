@@ -1754,7 +1754,7 @@ func TestSignTxOutput(t *testing.T) {
 			}
 
 			pkScript, err := txscript.MultiSigScript(
-				[]*hcutil.AddressSecpPubKey{address1, address2},
+				[]hcutil.Address{address1, address2},
 				2)
 			if err != nil {
 				t.Errorf("failed to make pkscript "+
@@ -1829,7 +1829,7 @@ func TestSignTxOutput(t *testing.T) {
 			}
 
 			pkScript, err := txscript.MultiSigScript(
-				[]*hcutil.AddressSecpPubKey{address1, address2},
+				[]hcutil.Address{address1, address2},
 				2)
 			if err != nil {
 				t.Errorf("failed to make pkscript "+
@@ -1923,7 +1923,7 @@ func TestSignTxOutput(t *testing.T) {
 			}
 
 			pkScript, err := txscript.MultiSigScript(
-				[]*hcutil.AddressSecpPubKey{address1, address2},
+				[]hcutil.Address{address1, address2},
 				2)
 			if err != nil {
 				t.Errorf("failed to make pkscript "+
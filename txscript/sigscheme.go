@@ -0,0 +1,159 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txscript
+
+import (
+	"errors"
+
+	"github.com/HcashOrg/hcd/chaincfg/chainec"
+	bs "github.com/HcashOrg/hcd/crypto/bliss"
+)
+
+// ErrDuplicateSigScheme describes an error where the signature type of a
+// signature scheme being registered is already taken by another scheme.
+var ErrDuplicateSigScheme = errors.New("duplicate signature scheme")
+
+// SignatureScheme describes an alternative signature scheme which may be used
+// with OP_CHECKSIGALT and OP_CHECKSIGALTVERIFY.  Scripts select the scheme by
+// pushing its type immediately before the opcode.  Every OP_CHECKSIGALT counts
+// as a single signature operation regardless of the scheme it selects.
+type SignatureScheme struct {
+	// Name is a human-readable name for the scheme.
+	Name string
+
+	// Type is the signature type which identifies the scheme in scripts.
+	Type uint8
+
+	// ValidPubKeyLen and ValidSigLen return whether or not the passed
+	// serialized public key and signature lengths are valid for the
+	// scheme.  The signature length includes the trailing hash type byte.
+	ValidPubKeyLen func(n int) bool
+	ValidSigLen    func(n int) bool
+
+	// ParsePubKey and ParseSignature parse a serialized public key and a
+	// signature without its hash type byte, respectively.
+	ParsePubKey    func(pubKey []byte) (chainec.PublicKey, error)
+	ParseSignature func(sig []byte) (chainec.Signature, error)
+
+	// Verify returns whether or not the signature is valid for the hash
+	// under the public key.
+	Verify func(pubKey chainec.PublicKey, hash []byte, sig chainec.Signature) bool
+
+	// ActivationFlag is the script flag which must be set for signatures of
+	// the scheme to be verified.  Until then, OP_CHECKSIGALT treats the
+	// scheme like any other unknown signature type and succeeds, so new
+	// schemes are introduced as a soft fork.  Schemes with no activation
+	// flag are always active.
+	ActivationFlag ScriptFlags
+}
+
+// isActive returns whether or not signatures of the scheme are verified when
+// executing scripts with the passed flags.
+func (s *SignatureScheme) isActive(flags ScriptFlags) bool {
+	return flags&s.ActivationFlag == s.ActivationFlag
+}
+
+// registeredSigSchemes houses the registered signature schemes keyed by their
+// signature type.
+var registeredSigSchemes = make(map[sigTypes]*SignatureScheme)
+
+// RegisterSignatureScheme registers an alternative signature scheme for use
+// with OP_CHECKSIGALT.  This may error with ErrDuplicateSigScheme if a scheme
+// with the same signature type is already registered.
+//
+// Signature schemes must be registered by a main package as early as possible
+// and before any scripts are executed since the registry is not safe for
+// concurrent access.
+func RegisterSignatureScheme(scheme *SignatureScheme) error {
+	if _, ok := registeredSigSchemes[sigTypes(scheme.Type)]; ok {
+		return ErrDuplicateSigScheme
+	}
+	registeredSigSchemes[sigTypes(scheme.Type)] = scheme
+	return nil
+}
+
+// mustRegisterSignatureScheme performs the same function as
+// RegisterSignatureScheme except it panics if there is an error.  This should
+// only be called from package init functions.
+func mustRegisterSignatureScheme(scheme *SignatureScheme) {
+	if err := RegisterSignatureScheme(scheme); err != nil {
+		panic("failed to register signature scheme: " + err.Error())
+	}
+}
+
+// LookupSignatureScheme returns the registered signature scheme with the passed
+// signature type, if any.
+func LookupSignatureScheme(sigType uint8) (*SignatureScheme, bool) {
+	scheme, ok := registeredSigSchemes[sigTypes(sigType)]
+	return scheme, ok
+}
+
+// altSigScheme returns the registered signature scheme selected by the passed
+// parsed opcode which precedes an OP_CHECKSIGALT, if any.
+func altSigScheme(pop *parsedOpcode) (*SignatureScheme, bool) {
+	if !isOneByteMaxDataPush(*pop) {
+		return nil, false
+	}
+	return LookupSignatureScheme(uint8(extractOneBytePush(*pop)))
+}
+
+// exactLen returns a function which returns whether or not a length is the
+// passed length.
+func exactLen(length int) func(int) bool {
+	return func(n int) bool { return n == length }
+}
+
+// lenRange returns a function which returns whether or not a length is within
+// the passed inclusive range.
+func lenRange(min, max int) func(int) bool {
+	return func(n int) bool { return n >= min && n <= max }
+}
+
+// ecScheme returns a signature scheme for an elliptic curve signature
+// algorithm which verifies signatures by their R and S values.
+func ecScheme(name string, sigType sigTypes, dsa chainec.DSA, pubKeyLen int,
+	validSigLen func(int) bool) *SignatureScheme {
+
+	return &SignatureScheme{
+		Name:           name,
+		Type:           uint8(sigType),
+		ValidPubKeyLen: exactLen(pubKeyLen),
+		ValidSigLen:    validSigLen,
+		ParsePubKey:    dsa.ParsePubKey,
+		ParseSignature: dsa.ParseSignature,
+		Verify: func(pubKey chainec.PublicKey, hash []byte, sig chainec.Signature) bool {
+			return dsa.Verify(pubKey, hash, sig.GetR(), sig.GetS())
+		},
+	}
+}
+
+func init() {
+	// Only 33-byte compressed keys are allowed for secp256k1 ECDSA and
+	// Schnorr signatures, 32-byte keys are used for Curve25519 and 897-byte
+	// keys are used for Bliss.  ECDSA signatures are DER encoded, while
+	// Schnorr signatures are 65 bytes in length (64 bytes for [r,s] and
+	// 1 byte appended to the end for the hash type).
+	mustRegisterSignatureScheme(ecScheme("secp256k1", secp256k1,
+		chainec.Secp256k1, 33, lenRange(69, 72)))
+	mustRegisterSignatureScheme(ecScheme("edwards", edwards,
+		chainec.Edwards, 32, exactLen(65)))
+	mustRegisterSignatureScheme(ecScheme("secschnorr", secSchnorr,
+		chainec.SecSchnorr, 33, exactLen(65)))
+	mustRegisterSignatureScheme(&SignatureScheme{
+		Name:           "bliss",
+		Type:           uint8(bliss),
+		ValidPubKeyLen: exactLen(897),
+		ValidSigLen:    lenRange(397, 860),
+		ParsePubKey: func(pubKey []byte) (chainec.PublicKey, error) {
+			return bs.Bliss.ParsePubKey(pubKey)
+		},
+		ParseSignature: func(sig []byte) (chainec.Signature, error) {
+			return bs.Bliss.ParseSignature(sig)
+		},
+		Verify: func(pubKey chainec.PublicKey, hash []byte, sig chainec.Signature) bool {
+			return bs.Bliss.Verify(pubKey, hash, sig)
+		},
+	})
+}
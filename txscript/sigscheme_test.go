@@ -0,0 +1,120 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txscript
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/HcashOrg/hcd/chaincfg/chainec"
+	"github.com/HcashOrg/hcd/wire"
+)
+
+// TestSignatureSchemeRegistry ensures alternative signature schemes can be
+// registered, are only enforced by OP_CHECKSIGALT once their activation flag
+// is set, count as a single sigop regardless of activation, and are accounted
+// for by the standardness checks.
+func TestSignatureSchemeRegistry(t *testing.T) {
+	const testSigType = 100
+	const testActivationFlag = ScriptFlags(1 << 30)
+
+	// Register a scheme whose verification result is controlled by the
+	// test.
+	var verifyResult bool
+	scheme := &SignatureScheme{
+		Name:           "test",
+		Type:           testSigType,
+		ValidPubKeyLen: exactLen(5),
+		ValidSigLen:    exactLen(11),
+		ParsePubKey: func(pubKey []byte) (chainec.PublicKey, error) {
+			return nil, nil
+		},
+		ParseSignature: func(sig []byte) (chainec.Signature, error) {
+			return nil, nil
+		},
+		Verify: func(chainec.PublicKey, []byte, chainec.Signature) bool {
+			return verifyResult
+		},
+		ActivationFlag: testActivationFlag,
+	}
+
+	if err := RegisterSignatureScheme(scheme); err != nil {
+		t.Fatalf("RegisterSignatureScheme: unexpected error: %v", err)
+	}
+	defer delete(registeredSigSchemes, testSigType)
+	if err := RegisterSignatureScheme(scheme); err != ErrDuplicateSigScheme {
+		t.Fatalf("RegisterSignatureScheme: unexpected error - got %v, "+
+			"want %v", err, ErrDuplicateSigScheme)
+	}
+	if got, ok := LookupSignatureScheme(testSigType); !ok || got != scheme {
+		t.Fatal("LookupSignatureScheme: registered scheme not found")
+	}
+
+	// The default schemes must not be able to be replaced.
+	bogus := *scheme
+	bogus.Type = uint8(edwards)
+	if err := RegisterSignatureScheme(&bogus); err != ErrDuplicateSigScheme {
+		t.Fatalf("RegisterSignatureScheme: unexpected error - got %v, "+
+			"want %v", err, ErrDuplicateSigScheme)
+	}
+
+	sig := append(bytes.Repeat([]byte{0x01}, 10), byte(SigHashAll))
+	pubKey := bytes.Repeat([]byte{0x02}, 5)
+	sigScript, err := NewScriptBuilder().AddData(sig).AddData(pubKey).Script()
+	if err != nil {
+		t.Fatalf("unable to build script: %v", err)
+	}
+	pkScript := []byte{OP_DATA_1, testSigType, OP_CHECKSIGALT}
+	tx := &wire.MsgTx{
+		TxIn:  []*wire.TxIn{{SignatureScript: sigScript}},
+		TxOut: []*wire.TxOut{{}},
+	}
+	execute := func(flags ScriptFlags) error {
+		vm, err := NewEngine(pkScript, tx, 0, flags, 0, nil)
+		if err != nil {
+			t.Fatalf("NewEngine: unexpected error: %v", err)
+		}
+		return vm.Execute()
+	}
+
+	// Signatures of the scheme are not verified prior to activation.
+	verifyResult = false
+	if err := execute(0); err != nil {
+		t.Fatalf("Execute: unexpected error prior to activation: %v", err)
+	}
+	if err := execute(testActivationFlag); err == nil {
+		t.Fatal("Execute: invalid signature accepted after activation")
+	}
+	verifyResult = true
+	if err := execute(testActivationFlag); err != nil {
+		t.Fatalf("Execute: unexpected error after activation: %v", err)
+	}
+
+	// Each use of the scheme counts as a single sigop.
+	if got := GetSigOpCount(pkScript); got != 1 {
+		t.Fatalf("GetSigOpCount: unexpected count - got %d, want 1", got)
+	}
+
+	// Scripts using the scheme are only standard once it is active.
+	altPkScript, err := NewScriptBuilder().AddData(pubKey).
+		AddInt64(testSigType).AddOp(OP_CHECKSIGALT).Script()
+	if err != nil {
+		t.Fatalf("unable to build script: %v", err)
+	}
+	a := Analyze(0, altPkScript)
+	if a.Class != PubkeyAltTy || len(a.AltSigSchemes) != 1 ||
+		a.AltSigSchemes[0] != scheme {
+
+		t.Fatalf("Analyze: unexpected analysis %+v", a)
+	}
+	policy := &StandardPolicy{MaxMultiSigKeys: 3, MaxP2SHSigOps: 15}
+	if err := a.CheckStandard(policy); err == nil {
+		t.Fatal("CheckStandard: inactive scheme unexpectedly standard")
+	}
+	policy.ScriptFlags = testActivationFlag
+	if err := a.CheckStandard(policy); err != nil {
+		t.Fatalf("CheckStandard: unexpected error: %v", err)
+	}
+}
@@ -828,6 +828,31 @@ func payToBlissPubKeyScript(serializedPubKey []byte) ([]byte, error) {
 		AddOp(OP_CHECKSIGALT).Script()
 }
 
+// checkAddrHashFunc returns ErrUnsupportedHashFunc when the passed address is a
+// pubkey hash or script hash created with a hash function other than the
+// default one.  Scripts only commit to the hash, which OP_HASH160 always
+// computes with the default hash function, so outputs paying to such an address
+// could never be spent and would decode to a different address.
+func checkAddrHashFunc(addr hcutil.Address) error {
+	var hashFunc hcutil.HashFunc
+	switch addr := addr.(type) {
+	case *hcutil.AddressPubKeyHash:
+		if addr == nil {
+			return nil
+		}
+		hashFunc = addr.HashFunc()
+	case *hcutil.AddressScriptHash:
+		if addr == nil {
+			return nil
+		}
+		hashFunc = addr.HashFunc()
+	}
+	if hashFunc != hcutil.HashFuncBlake256Ripemd160 {
+		return ErrUnsupportedHashFunc
+	}
+	return nil
+}
+
 // PayToSStx creates a new script to pay a transaction output to a script hash or
 // public key hash, but tags the output with OP_SSTX. For use in constructing
 // valid SStxs.
@@ -837,6 +862,9 @@ func PayToSStx(addr hcutil.Address) ([]byte, error) {
 	if addr == nil {
 		return nil, ErrUnsupportedAddress
 	}
+	if err := checkAddrHashFunc(addr); err != nil {
+		return nil, err
+	}
 
 	// Only pay to pubkey hash and pay to script hash are
 	// supported.
@@ -874,6 +902,9 @@ func PayToSStxChange(addr hcutil.Address) ([]byte, error) {
 	if addr == nil {
 		return nil, ErrUnsupportedAddress
 	}
+	if err := checkAddrHashFunc(addr); err != nil {
+		return nil, err
+	}
 
 	// Only pay to pubkey hash and pay to script hash are
 	// supported.
@@ -913,6 +944,9 @@ func PayToSSGen(addr hcutil.Address) ([]byte, error) {
 	if addr == nil {
 		return nil, ErrUnsupportedAddress
 	}
+	if err := checkAddrHashFunc(addr); err != nil {
+		return nil, err
+	}
 
 	// Only pay to pubkey hash and pay to script hash are
 	// supported.
@@ -983,6 +1017,9 @@ func PayToSSRtx(addr hcutil.Address) ([]byte, error) {
 	if addr == nil {
 		return nil, ErrUnsupportedAddress
 	}
+	if err := checkAddrHashFunc(addr); err != nil {
+		return nil, err
+	}
 
 	// Only pay to pubkey hash and pay to script hash are
 	// supported.
@@ -1058,6 +1095,9 @@ func GenerateSStxAddrPush(addr hcutil.Address, amount hcutil.Amount,
 	if addr == nil {
 		return nil, ErrUnsupportedAddress
 	}
+	if err := checkAddrHashFunc(addr); err != nil {
+		return nil, err
+	}
 
 	// Only pay to pubkey hash and pay to script hash are
 	// supported.
@@ -1151,8 +1191,13 @@ func GenerateProvablyPruneableOut(data []byte) ([]byte, error) {
 }
 
 // PayToAddrScript creates a new script to pay a transaction output to a the
-// specified address.
+// specified address.  Addresses created with an alternative hash function are
+// rejected with ErrUnsupportedHashFunc until a script type commits to it.
 func PayToAddrScript(addr hcutil.Address) ([]byte, error) {
+	if err := checkAddrHashFunc(addr); err != nil {
+		return nil, err
+	}
+
 	switch addr := addr.(type) {
 	case *hcutil.AddressPubKeyHash:
 		if addr == nil {
@@ -1260,7 +1305,9 @@ func GetMultisigMandN(script []byte) (uint8, uint8, error) {
 // ExtractPkScriptAddrs returns the type of script, addresses and required
 // signatures associated with the passed PkScript.  Note that it only works for
 // 'standard' transaction script types.  Any data such as public keys which are
-// invalid are omitted from the results.
+// invalid are omitted from the results.  Pubkey hash and script hash addresses
+// always use the default hash function since no script version commits to
+// another one, which is also why PayToAddrScript rejects them.
 func ExtractPkScriptAddrs(version uint16, pkScript []byte,
 	chainParams *chaincfg.Params) (ScriptClass, []hcutil.Address, int, error) {
 	if version != DefaultScriptVersion {
@@ -694,13 +694,13 @@ func TestMultiSigScript(t *testing.T) {
 		"2a3"))
 
 	tests := []struct {
-		keys      []*hcutil.AddressSecpPubKey
+		keys      []hcutil.Address
 		nrequired int
 		expected  string
 		err       error
 	}{
 		{
-			[]*hcutil.AddressSecpPubKey{
+			[]hcutil.Address{
 				p2pkCompressedMain,
 				p2pkCompressed2Main,
 			},
@@ -712,7 +712,7 @@ func TestMultiSigScript(t *testing.T) {
 			nil,
 		},
 		{
-			[]*hcutil.AddressSecpPubKey{
+			[]hcutil.Address{
 				p2pkCompressedMain,
 				p2pkCompressed2Main,
 			},
@@ -724,7 +724,7 @@ func TestMultiSigScript(t *testing.T) {
 			nil,
 		},
 		{
-			[]*hcutil.AddressSecpPubKey{
+			[]hcutil.Address{
 				p2pkCompressedMain,
 				p2pkCompressed2Main,
 			},
@@ -734,8 +734,8 @@ func TestMultiSigScript(t *testing.T) {
 		},
 		{
 			// By default compressed pubkeys are used in Hcd.
-			[]*hcutil.AddressSecpPubKey{
-				p2pkUncompressedMain.(*hcutil.AddressSecpPubKey),
+			[]hcutil.Address{
+				p2pkUncompressedMain,
 			},
 			1,
 			"1 DATA_33 0x0311db93e1dcdb8a016b49840f8c53bc1eb68a3" +
@@ -743,8 +743,8 @@ func TestMultiSigScript(t *testing.T) {
 			nil,
 		},
 		{
-			[]*hcutil.AddressSecpPubKey{
-				p2pkUncompressedMain.(*hcutil.AddressSecpPubKey),
+			[]hcutil.Address{
+				p2pkUncompressedMain,
 			},
 			2,
 			"",
@@ -1027,3 +1027,76 @@ func TestStringifyClass(t *testing.T) {
 		}
 	}
 }
+
+// TestPayToAddrScriptHashFunc ensures scripts paying to addresses created with
+// an alternative hash function are rejected since no script commits to the
+// hash function, while the same hashes created with the default hash function
+// are still accepted.
+func TestPayToAddrScriptHashFunc(t *testing.T) {
+	const testHashFunc = hcutil.HashFunc(201)
+	err := hcutil.RegisterHashFunc(testHashFunc, "test-hash", func(b []byte) []byte {
+		return hcutil.Hash160(append([]byte{0x01}, b...))
+	})
+	if err != nil {
+		t.Fatalf("RegisterHashFunc: unexpected error: %v", err)
+	}
+	net := chaincfg.MainNetParams
+	net.HashFuncAddrs = []chaincfg.HashFuncAddrParams{{
+		HashFunc:         uint8(testHashFunc),
+		PubKeyHashAddrID: [2]byte{0x09, 0x70},
+		ScriptHashAddrID: [2]byte{0x09, 0x72},
+	}}
+
+	pkHash := hcutil.Hash160([]byte{0x02})
+	altPkh, err := hcutil.NewAddressPubKeyHashWithHashFunc(pkHash, &net,
+		testHashFunc)
+	if err != nil {
+		t.Fatalf("NewAddressPubKeyHashWithHashFunc: unexpected error: %v",
+			err)
+	}
+	altSh, err := hcutil.NewAddressScriptHashWithHashFunc([]byte{0x51},
+		&net, testHashFunc)
+	if err != nil {
+		t.Fatalf("NewAddressScriptHashWithHashFunc: unexpected error: %v",
+			err)
+	}
+	pkh, err := hcutil.NewAddressPubKeyHash(pkHash, &net,
+		chainec.ECTypeSecp256k1)
+	if err != nil {
+		t.Fatalf("NewAddressPubKeyHash: unexpected error: %v", err)
+	}
+	sh, err := hcutil.NewAddressScriptHash([]byte{0x51}, &net)
+	if err != nil {
+		t.Fatalf("NewAddressScriptHash: unexpected error: %v", err)
+	}
+
+	payFuncs := []struct {
+		name string
+		pay  func(hcutil.Address) ([]byte, error)
+	}{
+		{"PayToAddrScript", txscript.PayToAddrScript},
+		{"PayToSStx", txscript.PayToSStx},
+		{"PayToSStxChange", txscript.PayToSStxChange},
+		{"PayToSSGen", txscript.PayToSSGen},
+		{"PayToSSRtx", txscript.PayToSSRtx},
+		{"GenerateSStxAddrPush", func(addr hcutil.Address) ([]byte, error) {
+			return txscript.GenerateSStxAddrPush(addr, 1, 0)
+		}},
+	}
+	for _, payFunc := range payFuncs {
+		for _, addr := range []hcutil.Address{altPkh, altSh} {
+			_, err := payFunc.pay(addr)
+			if err != txscript.ErrUnsupportedHashFunc {
+				t.Errorf("%s(%v): unexpected error - got %v, "+
+					"want %v", payFunc.name, addr, err,
+					txscript.ErrUnsupportedHashFunc)
+			}
+		}
+		for _, addr := range []hcutil.Address{pkh, sh} {
+			if _, err := payFunc.pay(addr); err != nil {
+				t.Errorf("%s(%v): unexpected error: %v",
+					payFunc.name, addr, err)
+			}
+		}
+	}
+}
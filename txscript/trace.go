@@ -0,0 +1,92 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txscript
+
+// DebugStep describes a single opcode executed by a script engine with
+// tracing enabled along with the state of the engine after executing it.
+type DebugStep struct {
+	// ScriptIdx and ScriptOff identify the executed opcode by the index
+	// of the script it is part of and its offset within that script.
+	ScriptIdx int
+	ScriptOff int
+
+	// Opcode is the disassembly of the executed opcode.
+	Opcode string
+
+	// Stack and AltStack are the contents of the data and alternate data
+	// stacks, bottom up, after executing the opcode.
+	Stack    [][]byte
+	AltStack [][]byte
+
+	// Flags are the script flags the engine is executing with.
+	Flags ScriptFlags
+
+	// Err is the error the opcode failed with, if any.
+	Err error
+}
+
+// DebugStepIterator iterates over the steps recorded by a script engine with
+// tracing enabled.  The iterator is positioned before the first step, so Next
+// must be called before the first call to Step.
+type DebugStepIterator struct {
+	steps []DebugStep
+	idx   int
+}
+
+// Next advances the iterator to the next step and returns whether or not
+// there was one.
+func (iter *DebugStepIterator) Next() bool {
+	if iter.idx >= len(iter.steps) {
+		return false
+	}
+	iter.idx++
+	return true
+}
+
+// Step returns the step the iterator is currently positioned at.  It must
+// only be called after a call to Next returned true.
+func (iter *DebugStepIterator) Step() *DebugStep {
+	return &iter.steps[iter.idx-1]
+}
+
+// EnableTracing instructs the engine to record every opcode it executes along
+// with the resulting stacks so they can be inspected via DebugSteps once
+// execution has finished.  This is intended to help debug failing scripts and
+// should not be enabled when validating scripts in general since it copies the
+// stacks after every opcode.
+func (vm *Engine) EnableTracing() {
+	vm.tracing = true
+}
+
+// DebugSteps returns an iterator over the steps recorded since tracing was
+// enabled.  The iterator is empty when tracing is not enabled.
+func (vm *Engine) DebugSteps() *DebugStepIterator {
+	return &DebugStepIterator{steps: vm.trace}
+}
+
+// copyStack returns a deep copy of the passed stack contents.
+func copyStack(data [][]byte) [][]byte {
+	stackCopy := make([][]byte, len(data))
+	for i, item := range data {
+		stackCopy[i] = make([]byte, len(item))
+		copy(stackCopy[i], item)
+	}
+	return stackCopy
+}
+
+// recordStep records the opcode at the passed script index and offset, which
+// has just been executed with the passed result, along with the current state
+// of the engine.
+func (vm *Engine) recordStep(scriptIdx, scriptOff int, err error) {
+	vm.trace = append(vm.trace, DebugStep{
+		ScriptIdx: scriptIdx,
+		ScriptOff: scriptOff,
+		Opcode:    vm.scripts[scriptIdx][scriptOff].print(false),
+		Stack:     copyStack(vm.GetStack()),
+		AltStack:  copyStack(vm.GetAltStack()),
+		Flags:     vm.flags,
+		Err:       err,
+	})
+}
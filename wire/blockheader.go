@@ -90,14 +90,14 @@ const blockHeaderLen = 180
 
 // BlockHash computes the block identifier hash for the given block header.
 func (h *BlockHeader) BlockHash() chainhash.Hash {
-	// Encode the header and hash256 everything prior to the number of
-	// transactions.  Ignore the error returns since there is no way the
-	// encode could fail except being out of memory which would cause a
-	// run-time panic.
-	buf := bytes.NewBuffer(make([]byte, 0, MaxBlockHeaderPayload))
-	_ = writeBlockHeader(buf, 0, h)
-
-	return chainhash.HashH(buf.Bytes())
+	// Encode the header directly into a pooled hasher and hash everything
+	// prior to the number of transactions.  Ignore the error returns since
+	// there is no way the encode could fail except being out of memory
+	// which would cause a run-time panic.
+	hasher := borrowHasher()
+	_ = writeBlockHeader(hasher, 0, h)
+
+	return sumHasher(hasher)
 }
 
 // BtcDecode decodes r using the bitcoin protocol encoding into the receiver.
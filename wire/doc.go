@@ -146,7 +146,12 @@ Errors returned by this package are either the raw errors provided by underlying
 calls to read/write from streams such as io.EOF, io.ErrUnexpectedEOF, and
 io.ErrShortWrite, or of type wire.MessageError.  This allows the caller to
 differentiate between general IO errors and malformed messages through type
-assertions.
+assertions.  The ErrorCode field of a wire.MessageError returned by ReadMessage
+further identifies the issue, such as a message for another network, an unknown
+command, a payload exceeding the maximum size for its message type or a payload
+which does not match its checksum or could not be parsed.  Messages rejected
+while decoding for other reasons, such as a field not allowed by the protocol
+version or a list with too many entries, use ErrUnclassified.
 
 Bitcoin Improvement Proposals
 
@@ -10,16 +10,74 @@ import (
 	"fmt"
 )
 
+// MessageErrorCode identifies a kind of issue with a message.
+type MessageErrorCode int
+
+// These constants are used to identify a specific MessageError.
+const (
+	// ErrUnclassified indicates a message error which is not described by
+	// a more specific code, such as a field rejected by the decode function
+	// of a message for the negotiated protocol version or a list exceeding
+	// its maximum number of entries.  It is the zero value so errors created
+	// without a code are never mistaken for one of the codes below.
+	ErrUnclassified MessageErrorCode = iota
+
+	// ErrMalformedMessage indicates a message whose payload ended early or
+	// could otherwise not be parsed as a message of its type.
+	ErrMalformedMessage
+
+	// ErrWrongNetwork indicates a message for another network.
+	ErrWrongNetwork
+
+	// ErrInvalidCommand indicates a message with a command which is not
+	// valid UTF-8.
+	ErrInvalidCommand
+
+	// ErrUnknownCommand indicates a message with a valid command which is
+	// not supported.
+	ErrUnknownCommand
+
+	// ErrPayloadTooLarge indicates a message with a payload which exceeds
+	// the maximum payload size of any message or of messages of its type.
+	ErrPayloadTooLarge
+
+	// ErrBadChecksum indicates a message with a payload which does not
+	// match the checksum in its header.
+	ErrBadChecksum
+)
+
+// Map of MessageErrorCode values back to their constant names for pretty
+// printing.
+var messageErrorCodeStrings = map[MessageErrorCode]string{
+	ErrUnclassified:     "ErrUnclassified",
+	ErrMalformedMessage: "ErrMalformedMessage",
+	ErrWrongNetwork:     "ErrWrongNetwork",
+	ErrInvalidCommand:   "ErrInvalidCommand",
+	ErrUnknownCommand:   "ErrUnknownCommand",
+	ErrPayloadTooLarge:  "ErrPayloadTooLarge",
+	ErrBadChecksum:      "ErrBadChecksum",
+}
+
+// String returns the MessageErrorCode as a human-readable name.
+func (e MessageErrorCode) String() string {
+	if s := messageErrorCodeStrings[e]; s != "" {
+		return s
+	}
+	return fmt.Sprintf("Unknown MessageErrorCode (%d)", int(e))
+}
+
 // MessageError describes an issue with a message.
 // An example of some potential issues are messages from the wrong hcd
 // network, invalid commands, mismatched checksums, and exceeding max payloads.
 //
 // This provides a mechanism for the caller to type assert the error to
 // differentiate between general io errors such as io.EOF and issues that
-// resulted from malformed messages.
+// resulted from malformed messages, which are protocol violations by the
+// sender.  The ErrorCode field identifies the specific issue.
 type MessageError struct {
-	Func        string // Function name
-	Description string // Human readable description of the issue
+	Func        string           // Function name
+	ErrorCode   MessageErrorCode // Describes the kind of issue
+	Description string           // Human readable description of the issue
 }
 
 // Error satisfies the error interface and prints human-readable errors.
@@ -34,3 +92,9 @@ func (e *MessageError) Error() string {
 func messageError(f string, desc string) *MessageError {
 	return &MessageError{Func: f, Description: desc}
 }
+
+// readMessageError creates an error for ReadMessage with the given code and
+// description.
+func readMessageError(c MessageErrorCode, desc string) *MessageError {
+	return &MessageError{Func: "ReadMessage", ErrorCode: c, Description: desc}
+}
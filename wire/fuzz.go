@@ -0,0 +1,174 @@
+// Copyright (c) 2018-2020 The Hcd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// +build gofuzz
+
+package wire
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// This file provides entry points for go-fuzz, which are built with
+// go-fuzz-build (add -libfuzzer to build a libFuzzer target), for example:
+//
+//   go-fuzz-build -func FuzzMsgTx github.com/HcashOrg/hcd/wire
+//   go-fuzz -bin wire-fuzz.zip -workdir fuzz/tx
+//
+// Each entry point decodes the input with both the lenient and the strict
+// decoder and panics when they disagree, when re-encoding is not stable, or
+// when the canonical encoding decodes to a different message.  The entry
+// points are deterministic so every crash can be reproduced from its input.
+
+// fuzzMessage is implemented by the messages the fuzz entry points
+// round-trip.
+type fuzzMessage interface {
+	FromBytes([]byte) error
+	FromBytesStrict([]byte) error
+	Serialize(io.Writer) error
+}
+
+// Fuzz is the default go-fuzz entry point.  It uses the first byte of data
+// to choose the message type data is decoded as.
+func Fuzz(data []byte) int {
+	if len(data) == 0 {
+		return 0
+	}
+	switch data[0] % 3 {
+	case 0:
+		return FuzzMsgTx(data[1:])
+	case 1:
+		return FuzzMsgBlock(data[1:])
+	default:
+		return FuzzBlockHeader(data[1:])
+	}
+}
+
+// FuzzMsgTx round-trips data as a transaction.
+func FuzzMsgTx(data []byte) int {
+	msg, ok := fuzzRoundTrip(data, func() fuzzMessage { return new(MsgTx) })
+	if msg == nil {
+		return 0
+	}
+
+	// The hashes of a decoded transaction must not depend on whether it
+	// was decoded from the canonical encoding.
+	tx := msg.(*MsgTx)
+	var canonical MsgTx
+	if err := canonical.FromBytes(fuzzSerialize(tx)); err != nil {
+		panic(fmt.Sprintf("canonical transaction does not decode: %v", err))
+	}
+	if tx.TxHash() != canonical.TxHash() {
+		panic("transaction hash differs for the canonical encoding")
+	}
+	if tx.SerType == TxSerializeFull &&
+		tx.TxHashFull() != canonical.TxHashFull() {
+		panic("full transaction hash differs for the canonical encoding")
+	}
+	return fuzzPriority(ok)
+}
+
+// FuzzMsgBlock round-trips data as a block and checks that DeserializeTxLoc
+// agrees with Deserialize.
+func FuzzMsgBlock(data []byte) int {
+	msg, ok := fuzzRoundTrip(data, func() fuzzMessage { return new(MsgBlock) })
+	if msg == nil {
+		return 0
+	}
+	block := msg.(*MsgBlock)
+
+	var located MsgBlock
+	txLocs, sTxLocs, err := located.DeserializeTxLoc(bytes.NewBuffer(data))
+	if err != nil {
+		panic(fmt.Sprintf("DeserializeTxLoc rejected a decodable "+
+			"block: %v", err))
+	}
+	if !reflect.DeepEqual(block, &located) {
+		panic("DeserializeTxLoc and Deserialize decoded different blocks")
+	}
+
+	// Every located transaction must decode strictly on its own to the
+	// transaction found in the block.
+	checkLocs := func(locs []TxLoc, txns []*MsgTx) {
+		for i, loc := range locs {
+			var tx MsgTx
+			raw := data[loc.TxStart : loc.TxStart+loc.TxLen]
+			if err := tx.FromBytesStrict(raw); err != nil {
+				panic(fmt.Sprintf("located transaction %d does not "+
+					"decode strictly: %v", i, err))
+			}
+			if !reflect.DeepEqual(&tx, txns[i]) {
+				panic(fmt.Sprintf("located transaction %d differs", i))
+			}
+		}
+	}
+	checkLocs(txLocs, block.Transactions)
+	checkLocs(sTxLocs, block.STransactions)
+	return fuzzPriority(ok)
+}
+
+// FuzzBlockHeader round-trips data as a block header.
+func FuzzBlockHeader(data []byte) int {
+	msg, ok := fuzzRoundTrip(data, func() fuzzMessage { return new(BlockHeader) })
+	if msg == nil {
+		return 0
+	}
+	return fuzzPriority(ok)
+}
+
+// fuzzRoundTrip decodes data into a new message, returning nil when it does
+// not decode, and whether data is the canonical encoding of the message.  It
+// panics when the strict decoder disagrees with the lenient one, or when the
+// canonical encoding of the message does not decode strictly to an identical
+// message.
+func fuzzRoundTrip(data []byte, newMsg func() fuzzMessage) (fuzzMessage, bool) {
+	msg := newMsg()
+	if err := msg.FromBytes(data); err != nil {
+		if newMsg().FromBytesStrict(data) == nil {
+			panic("strict decoder accepted data the lenient decoder " +
+				"rejected")
+		}
+		return nil, false
+	}
+
+	canonical := fuzzSerialize(msg)
+	isCanonical := bytes.Equal(canonical, data)
+	strictErr := newMsg().FromBytesStrict(data)
+	if (strictErr == nil) != isCanonical {
+		panic(fmt.Sprintf("strict decoder result %v does not match "+
+			"canonical encoding check %v", strictErr, isCanonical))
+	}
+
+	reencoded := newMsg()
+	if err := reencoded.FromBytesStrict(canonical); err != nil {
+		panic(fmt.Sprintf("canonical encoding is rejected: %v", err))
+	}
+	if !reflect.DeepEqual(msg, reencoded) {
+		panic("canonical encoding decodes to a different message")
+	}
+	return msg, isCanonical
+}
+
+// fuzzSerialize returns the serialized message and panics when it cannot be
+// serialized, since every decoded message must be.
+func fuzzSerialize(msg fuzzMessage) []byte {
+	var buf bytes.Buffer
+	if err := msg.Serialize(&buf); err != nil {
+		panic(fmt.Sprintf("decoded message does not serialize: %v", err))
+	}
+	return buf.Bytes()
+}
+
+// fuzzPriority returns the go-fuzz priority of an input which decoded, which
+// is raised for canonical encodings so the fuzzer favours them as the base
+// of further mutations.
+func fuzzPriority(canonical bool) int {
+	if canonical {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"hash"
+	"sync"
+
+	"github.com/HcashOrg/hcd/chaincfg/chainhash"
+)
+
+// pooledHasher is a hasher along with a buffer for its sum.  Since the sum is
+// written through an interface, a buffer on the stack would escape to the heap,
+// so the buffer is pooled along with the hasher.
+type pooledHasher struct {
+	hash.Hash
+	sum chainhash.Hash
+}
+
+// hasherPool houses hashers which messages are serialized into in order to
+// hash them.  Serializing directly into a pooled hasher avoids allocating a
+// buffer for the serialization along with a new hasher each time a
+// transaction or block header is hashed, which happens on every hot path from
+// mempool acceptance to the initial block download.
+var hasherPool = sync.Pool{
+	New: func() interface{} {
+		return &pooledHasher{Hash: chainhash.NewHasher()}
+	},
+}
+
+// borrowHasher returns a reset hasher from the pool.  It must be returned with
+// sumHasher once the data to hash was written to it.
+func borrowHasher() *pooledHasher {
+	h := hasherPool.Get().(*pooledHasher)
+	h.Reset()
+	return h
+}
+
+// writeHash writes the passed hash to the hasher.  It is copied to the sum
+// buffer first so that it does not escape to the heap.
+func (h *pooledHasher) writeHash(hash *chainhash.Hash) {
+	h.sum = *hash
+	h.Write(h.sum[:])
+}
+
+// sumHasher returns the hash of the data written to the passed hasher and
+// returns the hasher to the pool.  The hasher must not be used afterwards.
+func sumHasher(h *pooledHasher) chainhash.Hash {
+	h.Sum(h.sum[:0])
+	sum := h.sum
+	hasherPool.Put(h)
+	return sum
+}
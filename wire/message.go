@@ -10,6 +10,8 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"sync"
 	"unicode/utf8"
 
 	"github.com/HcashOrg/hcd/chaincfg/chainhash"
@@ -24,6 +26,21 @@ const MessageHeaderSize = 24
 // header.  Shorter commands must be zero padded.
 const CommandSize = 12
 
+// largePayloadSize is the payload size above which payloads are read into
+// pooled buffers which grow as the payload arrives instead of being allocated
+// for the length the header indicates up front.  Peers therefore can't make
+// us allocate large buffers without actually sending the data.
+const largePayloadSize = 64 * 1024
+
+// maxPooledPayloadSize is the maximum capacity of the payload buffers returned
+// to the pool, so buffers which grew for unusually large messages are freed.
+const maxPooledPayloadSize = MaxBlockPayload
+
+// payloadPool provides the buffers large payloads are read into.
+var payloadPool = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
 // MaxMessagePayload is the maximum bytes a message can be regardless of other
 // individual limits imposed by messages themselves.
 const MaxMessagePayload = (1024 * 1024 * 32) // 32MB
@@ -55,6 +72,8 @@ const (
 	CmdReject         = "reject"
 	CmdSendHeaders    = "sendheaders"
 	CmdFeeFilter      = "feefilter"
+	CmdAddrV2         = "addrv2"
+	CmdSendFeature    = "sendfeature"
 )
 
 // Message is an interface that describes a HC message.  A type that
@@ -145,6 +164,12 @@ func makeEmptyMessage(command string) (Message, error) {
 	case CmdFeeFilter:
 		msg = &MsgFeeFilter{}
 
+	case CmdAddrV2:
+		msg = &MsgAddrV2{}
+
+	case CmdSendFeature:
+		msg = &MsgSendFeature{}
+
 	default:
 		return nil, fmt.Errorf("unhandled command [%s]", command)
 	}
@@ -178,7 +203,7 @@ func readMessageHeader(r io.Reader) (int, *messageHeader, error) {
 	readElements(hr, &hdr.magic, &command, &hdr.length, &hdr.checksum)
 
 	// Strip trailing zeros from command string.
-	hdr.command = string(bytes.TrimRight(command[:], string(0)))
+	hdr.command = string(bytes.TrimRight(command[:], "\x00"))
 
 	return n, &hdr, nil
 }
@@ -188,19 +213,52 @@ func readMessageHeader(r io.Reader) (int, *messageHeader, error) {
 // prevent rogue nodes from causing massive memory allocation through forging
 // header length.
 func discardInput(r io.Reader, n uint32) {
-	maxSize := uint32(10 * 1024) // 10k at a time
-	numReads := n / maxSize
-	bytesRemaining := n % maxSize
-	if n > 0 {
-		buf := make([]byte, maxSize)
-		for i := uint32(0); i < numReads; i++ {
-			io.ReadFull(r, buf)
+	io.CopyN(ioutil.Discard, r, int64(n))
+}
+
+// readPayload reads a payload of the passed length from r and verifies it
+// matches the passed checksum.  Large payloads are read into a pooled buffer
+// which grows as the data arrives and are only copied into a buffer of their
+// exact size once complete.  It returns the number of bytes read in addition
+// to the payload.
+func readPayload(r io.Reader, length uint32, checksum [4]byte) (int, []byte, error) {
+	var payload []byte
+	if length <= largePayloadSize {
+		payload = make([]byte, length)
+		n, err := io.ReadFull(r, payload)
+		if err != nil {
+			return n, nil, err
 		}
+	} else {
+		buf := payloadPool.Get().(*bytes.Buffer)
+		buf.Reset()
+		defer func() {
+			if buf.Cap() <= maxPooledPayloadSize {
+				payloadPool.Put(buf)
+			}
+		}()
+
+		n, err := io.CopyN(buf, r, int64(length))
+		if err != nil {
+			// Match io.ReadFull, which only returns io.EOF when no
+			// bytes were read.
+			if err == io.EOF && n > 0 {
+				err = io.ErrUnexpectedEOF
+			}
+			return int(n), nil, err
+		}
+		payload = append([]byte(nil), buf.Bytes()...)
 	}
-	if bytesRemaining > 0 {
-		buf := make([]byte, bytesRemaining)
-		io.ReadFull(r, buf)
+
+	// Test checksum.
+	sum := chainhash.HashB(payload)[0:4]
+	if !bytes.Equal(sum, checksum[:]) {
+		str := fmt.Sprintf("payload checksum failed - header "+
+			"indicates %v, but actual checksum is %v.",
+			checksum, sum)
+		return len(payload), nil, readMessageError(ErrBadChecksum, str)
 	}
+	return len(payload), payload, nil
 }
 
 // WriteMessageN writes a HC Message to w including the necessary header
@@ -286,6 +344,12 @@ func WriteMessage(w io.Writer, msg Message, pver uint32, hcnet CurrencyNet) erro
 // bytes read in addition to the parsed Message and raw bytes which comprise the
 // message.  This function is the same as ReadMessage except it also returns the
 // number of bytes read.
+//
+// The payload size is checked against the maximum for the message type before
+// any buffer is allocated for it.  Errors which result from the message
+// violating the protocol are of type *MessageError, while errors reading from r
+// are returned unchanged, so callers can tell misbehaving peers from failing
+// connections.
 func ReadMessageN(r io.Reader, pver uint32, hcnet CurrencyNet) (int, Message, []byte, error) {
 	totalBytes := 0
 	n, hdr, err := readMessageHeader(r)
@@ -299,7 +363,8 @@ func ReadMessageN(r io.Reader, pver uint32, hcnet CurrencyNet) (int, Message, []
 		str := fmt.Sprintf("message payload is too large - header "+
 			"indicates %d bytes, but max message payload is %d "+
 			"bytes.", hdr.length, MaxMessagePayload)
-		return totalBytes, nil, nil, messageError("ReadMessage", str)
+		return totalBytes, nil, nil, readMessageError(ErrPayloadTooLarge,
+			str)
 
 	}
 
@@ -307,7 +372,7 @@ func ReadMessageN(r io.Reader, pver uint32, hcnet CurrencyNet) (int, Message, []
 	if hdr.magic != hcnet {
 		discardInput(r, hdr.length)
 		str := fmt.Sprintf("message from other network [%v]", hdr.magic)
-		return totalBytes, nil, nil, messageError("ReadMessage", str)
+		return totalBytes, nil, nil, readMessageError(ErrWrongNetwork, str)
 	}
 
 	// Check for malformed commands.
@@ -315,14 +380,15 @@ func ReadMessageN(r io.Reader, pver uint32, hcnet CurrencyNet) (int, Message, []
 	if !utf8.ValidString(command) {
 		discardInput(r, hdr.length)
 		str := fmt.Sprintf("invalid command %v", []byte(command))
-		return totalBytes, nil, nil, messageError("ReadMessage", str)
+		return totalBytes, nil, nil, readMessageError(ErrInvalidCommand,
+			str)
 	}
 
 	// Create struct of appropriate message type based on the command.
 	msg, err := makeEmptyMessage(command)
 	if err != nil {
 		discardInput(r, hdr.length)
-		return totalBytes, nil, nil, messageError("ReadMessage",
+		return totalBytes, nil, nil, readMessageError(ErrUnknownCommand,
 			err.Error())
 	}
 
@@ -335,32 +401,32 @@ func ReadMessageN(r io.Reader, pver uint32, hcnet CurrencyNet) (int, Message, []
 		str := fmt.Sprintf("payload exceeds max length - header "+
 			"indicates %v bytes, but max payload size for "+
 			"messages of type [%v] is %v.", hdr.length, command, mpl)
-		return totalBytes, nil, nil, messageError("ReadMessage", str)
+		return totalBytes, nil, nil, readMessageError(ErrPayloadTooLarge,
+			str)
 	}
 
 	// Read payload.
-	payload := make([]byte, hdr.length)
-	n, err = io.ReadFull(r, payload)
+	n, payload, err := readPayload(r, hdr.length, hdr.checksum)
 	totalBytes += n
 	if err != nil {
 		return totalBytes, nil, nil, err
 	}
 
-	// Test checksum.
-	checksum := chainhash.HashB(payload)[0:4]
-	if !bytes.Equal(checksum[:], hdr.checksum[:]) {
-		str := fmt.Sprintf("payload checksum failed - header "+
-			"indicates %v, but actual checksum is %v.",
-			hdr.checksum, checksum)
-		return totalBytes, nil, nil, messageError("ReadMessage", str)
-	}
-
 	// Unmarshal message.  NOTE: This must be a *bytes.Buffer since the
-	// MsgVersion BtcDecode function requires it.
+	// MsgVersion BtcDecode function requires it.  The payload was read
+	// completely, so any error decoding it other than a message error,
+	// such as running out of data, is a malformed message.  Message errors
+	// returned by the decode function are passed through with their code.
 	pr := bytes.NewBuffer(payload)
 	err = msg.BtcDecode(pr, pver)
 	if err != nil {
-		return totalBytes, nil, nil, err
+		if _, ok := err.(*MessageError); ok {
+			return totalBytes, nil, nil, err
+		}
+		str := fmt.Sprintf("failed to decode %v message: %v", command,
+			err)
+		return totalBytes, nil, nil, readMessageError(ErrMalformedMessage,
+			str)
 	}
 
 	return totalBytes, msg, payload, nil
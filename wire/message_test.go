@@ -74,26 +74,6 @@ func TestMessage(t *testing.T) {
 	msgFilterAdd := NewMsgFilterAdd([]byte{0x01})
 	msgFilterClear := NewMsgFilterClear()
 	msgFilterLoad := NewMsgFilterLoad([]byte{0x01}, 10, 0, BloomUpdateNone)
-	bh := NewBlockHeader(
-		int32(0),                                    // Version
-		&chainhash.Hash{},                           // PrevHash
-		&chainhash.Hash{},                           // MerkleRoot
-		&chainhash.Hash{},                           // StakeRoot
-		uint16(0x0000),                              // VoteBits
-		[6]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, // FinalState
-		uint16(0x0000),                              // Voters
-		uint8(0x00),                                 // FreshStake
-		uint8(0x00),                                 // Revocations
-		uint32(0),                                   // Poolsize
-		uint32(0x00000000),                          // Bits
-		int64(0x0000000000000000),                   // Sbits
-		uint32(0),                                   // Height
-		uint32(0),                                   // Size
-		uint32(0x00000000),                          // Nonce
-		[32]byte{},                                  // ExtraData
-		uint32(0xcab005e0),                          // StakeVersion
-	)
-	msgMerkleBlock := NewMsgMerkleBlock(bh)
 	msgReject := NewMsgReject("block", RejectDuplicate, "duplicate block")
 
 	tests := []struct {
@@ -103,27 +83,26 @@ func TestMessage(t *testing.T) {
 		hcnet CurrencyNet // Network to use for wire encoding
 		bytes int         // Expected num bytes read/written
 	}{
-		{msgVersion, msgVersion, pver, MainNet, 125},         // [0]
-		{msgVerack, msgVerack, pver, MainNet, 24},            // [1]
-		{msgGetAddr, msgGetAddr, pver, MainNet, 24},          // [2]
-		{msgAddr, msgAddr, pver, MainNet, 25},                // [3]
-		{msgGetBlocks, msgGetBlocks, pver, MainNet, 61},      // [4]
-		{msgBlock, msgBlock, pver, MainNet, 522},             // [5]
-		{msgInv, msgInv, pver, MainNet, 25},                  // [6]
-		{msgGetData, msgGetData, pver, MainNet, 25},          // [7]
-		{msgNotFound, msgNotFound, pver, MainNet, 25},        // [8]
-		{msgTx, msgTx, pver, MainNet, 39},                    // [9]
-		{msgPing, msgPing, pver, MainNet, 32},                // [10]
-		{msgPong, msgPong, pver, MainNet, 32},                // [11]
-		{msgGetHeaders, msgGetHeaders, pver, MainNet, 61},    // [12]
-		{msgHeaders, msgHeaders, pver, MainNet, 25},          // [13]
-		{msgAlert, msgAlert, pver, MainNet, 42},              // [14]
-		{msgMemPool, msgMemPool, pver, MainNet, 24},          // [15]
-		{msgFilterAdd, msgFilterAdd, pver, MainNet, 26},      // [16]
-		{msgFilterClear, msgFilterClear, pver, MainNet, 24},  // [17]
-		{msgFilterLoad, msgFilterLoad, pver, MainNet, 35},    // [18]
-		{msgMerkleBlock, msgMerkleBlock, pver, MainNet, 215}, // [19]
-		{msgReject, msgReject, pver, MainNet, 79},            // [20]
+		{msgVersion, msgVersion, pver, MainNet, 124},        // [0]
+		{msgVerack, msgVerack, pver, MainNet, 24},           // [1]
+		{msgGetAddr, msgGetAddr, pver, MainNet, 24},         // [2]
+		{msgAddr, msgAddr, pver, MainNet, 25},               // [3]
+		{msgGetBlocks, msgGetBlocks, pver, MainNet, 61},     // [4]
+		{msgBlock, msgBlock, pver, MainNet, 522},            // [5]
+		{msgInv, msgInv, pver, MainNet, 25},                 // [6]
+		{msgGetData, msgGetData, pver, MainNet, 25},         // [7]
+		{msgNotFound, msgNotFound, pver, MainNet, 25},       // [8]
+		{msgTx, msgTx, pver, MainNet, 39},                   // [9]
+		{msgPing, msgPing, pver, MainNet, 32},               // [10]
+		{msgPong, msgPong, pver, MainNet, 32},               // [11]
+		{msgGetHeaders, msgGetHeaders, pver, MainNet, 61},   // [12]
+		{msgHeaders, msgHeaders, pver, MainNet, 25},         // [13]
+		{msgAlert, msgAlert, pver, MainNet, 42},             // [14]
+		{msgMemPool, msgMemPool, pver, MainNet, 24},         // [15]
+		{msgFilterAdd, msgFilterAdd, pver, MainNet, 26},     // [16]
+		{msgFilterClear, msgFilterClear, pver, MainNet, 24}, // [17]
+		{msgFilterLoad, msgFilterLoad, pver, MainNet, 35},   // [18]
+		{msgReject, msgReject, pver, MainNet, 79},           // [19]
 	}
 
 	t.Logf("Running %d tests", len(tests))
@@ -470,3 +449,169 @@ func TestWriteMessageWireErrors(t *testing.T) {
 		}
 	}
 }
+
+// TestReadMessageErrorCodes ensures protocol violations are reported as
+// MessageError with the expected error code, while short reads are reported
+// as io errors.
+func TestReadMessageErrorCodes(t *testing.T) {
+	pver := ProtocolVersion
+	hcnet := MainNet
+
+	// checksum returns the checksum of the passed payload as encoded in a
+	// message header.
+	checksum := func(payload []byte) uint32 {
+		return binary.LittleEndian.Uint32(chainhash.HashB(payload)[0:4])
+	}
+
+	// An addr message which claims to contain two addresses, but does not
+	// provide them.
+	badAddr := []byte{0x02}
+	badAddrBytes := append(makeHeader(hcnet, "addr", 1, checksum(badAddr)),
+		badAddr...)
+
+	// An addr message claiming more addresses than allowed, which is
+	// rejected by its decode function rather than being malformed.
+	manyAddr := []byte{0xfd, 0xe9, 0x03}
+	manyAddrBytes := append(makeHeader(hcnet, "addr", 3, checksum(manyAddr)),
+		manyAddr...)
+
+	// A large inv message which does not deliver the full payload.
+	shortInvBytes := append(makeHeader(hcnet, "inv", largePayloadSize+1, 0),
+		make([]byte, largePayloadSize)...)
+
+	badCommandBytes := makeHeader(hcnet, "bogus", 0, 0)
+	badCommandBytes[4] = 0x81
+
+	tests := []struct {
+		name    string
+		buf     []byte
+		code    MessageErrorCode
+		readErr error
+	}{
+		{
+			name: "wrong network",
+			buf:  makeHeader(TestNet2, "", 0, 0),
+			code: ErrWrongNetwork,
+		},
+		{
+			name: "invalid command",
+			buf:  badCommandBytes,
+			code: ErrInvalidCommand,
+		},
+		{
+			name: "unknown command",
+			buf:  makeHeader(hcnet, "bogus", 0, 0),
+			code: ErrUnknownCommand,
+		},
+		{
+			name: "exceeds max message payload",
+			buf:  makeHeader(hcnet, "getaddr", MaxMessagePayload+1, 0),
+			code: ErrPayloadTooLarge,
+		},
+		{
+			name: "exceeds max payload for type",
+			buf:  makeHeader(hcnet, "getaddr", 1, 0),
+			code: ErrPayloadTooLarge,
+		},
+		{
+			name: "bad checksum",
+			buf:  append(makeHeader(hcnet, "ping", 8, 0), make([]byte, 8)...),
+			code: ErrBadChecksum,
+		},
+		{
+			name: "malformed payload",
+			buf:  badAddrBytes,
+			code: ErrMalformedMessage,
+		},
+		{
+			name: "rejected payload",
+			buf:  manyAddrBytes,
+			code: ErrUnclassified,
+		},
+		{
+			name:    "short large payload",
+			buf:     shortInvBytes,
+			readErr: io.ErrUnexpectedEOF,
+		},
+	}
+
+	for _, test := range tests {
+		_, _, _, err := ReadMessageN(bytes.NewReader(test.buf), pver, hcnet)
+		if test.readErr != nil {
+			if err != test.readErr {
+				t.Errorf("%s: wrong error - got %v, want %v",
+					test.name, err, test.readErr)
+			}
+			continue
+		}
+		msgErr, ok := err.(*MessageError)
+		if !ok {
+			t.Errorf("%s: wrong error type - got %T, want "+
+				"*MessageError", test.name, err)
+			continue
+		}
+		if msgErr.ErrorCode != test.code {
+			t.Errorf("%s: wrong error code - got %v, want %v",
+				test.name, msgErr.ErrorCode, test.code)
+		}
+	}
+
+	// Ensure the error codes are printed by name.
+	if got := ErrBadChecksum.String(); got != "ErrBadChecksum" {
+		t.Errorf("String: got %q, want %q", got, "ErrBadChecksum")
+	}
+	if got := MessageErrorCode(0xffff).String(); got !=
+		"Unknown MessageErrorCode (65535)" {
+		t.Errorf("String: unexpected unknown code string %q", got)
+	}
+}
+
+// TestReadMessageLargePayload ensures messages with payloads which are read
+// into pooled buffers are decoded correctly and their returned payloads are
+// not modified by reading further messages.
+func TestReadMessageLargePayload(t *testing.T) {
+	pver := ProtocolVersion
+	hcnet := MainNet
+
+	// newInv returns an inv message with a payload larger than
+	// largePayloadSize whose hashes start with the passed byte.
+	newInv := func(b byte) *MsgInv {
+		msg := NewMsgInv()
+		for i := 0; i < 2000; i++ {
+			hash := chainhash.Hash{b, byte(i), byte(i >> 8)}
+			msg.AddInvVect(NewInvVect(InvTypeTx, &hash))
+		}
+		return msg
+	}
+
+	var buf bytes.Buffer
+	msgs := []*MsgInv{newInv(0x01), newInv(0x02)}
+	for _, msg := range msgs {
+		if err := WriteMessage(&buf, msg, pver, hcnet); err != nil {
+			t.Fatalf("WriteMessage: unexpected error %v", err)
+		}
+	}
+
+	var payloads [][]byte
+	for i, want := range msgs {
+		wantLen := buf.Len() / (len(msgs) - i)
+		n, msg, payload, err := ReadMessageN(&buf, pver, hcnet)
+		if err != nil {
+			t.Fatalf("ReadMessage #%d: unexpected error %v", i, err)
+		}
+		if n != wantLen || len(payload) <= largePayloadSize {
+			t.Fatalf("ReadMessage #%d: read %d bytes with payload "+
+				"of %d bytes, want %d bytes with a large payload",
+				i, n, len(payload), wantLen)
+		}
+		if !reflect.DeepEqual(msg, want) {
+			t.Fatalf("ReadMessage #%d: wrong message - got %v, "+
+				"want %v", i, spew.Sdump(msg), spew.Sdump(want))
+		}
+		payloads = append(payloads, payload)
+	}
+
+	if bytes.Equal(payloads[0], payloads[1]) {
+		t.Fatal("payload of the first message was overwritten")
+	}
+}
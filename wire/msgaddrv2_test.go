@@ -0,0 +1,115 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/davecgh/go-spew/spew"
+)
+
+// TestAddrV2Wire tests the MsgAddrV2 wire encode and decode, including that
+// addresses of unknown networks are skipped and that the message is rejected
+// for protocol versions before AddrV2Version.
+func TestAddrV2Wire(t *testing.T) {
+	pver := AddrV2Version
+	ts := time.Unix(0x495fab29, 0) // 2009-01-03 12:15:05 -0600 CST
+	ipv4 := &NetAddressV2{
+		Timestamp: ts,
+		Services:  SFNodeNetwork,
+		Type:      IPv4Address,
+		Addr:      []byte{0x7f, 0x00, 0x00, 0x01},
+		Port:      8333,
+	}
+	torV3 := &NetAddressV2{
+		Timestamp: ts,
+		Services:  SFNodeNetwork | SFNodeBloom,
+		Type:      TorV3Address,
+		Addr:      bytes.Repeat([]byte{0xaa}, 32),
+		Port:      8334,
+	}
+
+	msg := NewMsgAddrV2()
+	if cmd := msg.Command(); cmd != "addrv2" {
+		t.Errorf("Command: wrong command - got %v want addrv2", cmd)
+	}
+	msg.AddAddresses(ipv4, torV3)
+	encoded := []byte{
+		0x02,                   // Varint for number of addresses
+		0x29, 0xab, 0x5f, 0x49, // Timestamp
+		0x01,                         // Varint for SFNodeNetwork
+		0x01,                         // IPv4
+		0x04, 0x7f, 0x00, 0x00, 0x01, // IP 127.0.0.1
+		0x20, 0x8d, // Port 8333 in big-endian
+		0x29, 0xab, 0x5f, 0x49, // Timestamp
+		0x03, // Varint for SFNodeNetwork|SFNodeBloom
+		0x04, // TorV3
+		0x20, // Varint for address length
+	}
+	encoded = append(encoded, torV3.Addr...)
+	encoded = append(encoded, 0x20, 0x8e) // Port 8334 in big-endian
+
+	var buf bytes.Buffer
+	if err := msg.BtcEncode(&buf, pver); err != nil {
+		t.Fatalf("BtcEncode: unexpected error: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), encoded) {
+		t.Fatalf("BtcEncode: wrong encoding\ngot: %s want: %s",
+			spew.Sdump(buf.Bytes()), spew.Sdump(encoded))
+	}
+	var decoded MsgAddrV2
+	if err := decoded.BtcDecode(bytes.NewReader(encoded), pver); err != nil {
+		t.Fatalf("BtcDecode: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(&decoded, msg) {
+		t.Fatalf("BtcDecode: wrong message\ngot: %s want: %s",
+			spew.Sdump(&decoded), spew.Sdump(msg))
+	}
+
+	// Addresses of unknown networks are skipped while known networks
+	// with the wrong address size are rejected.
+	unknown := []byte{
+		0x01,                   // Varint for number of addresses
+		0x29, 0xab, 0x5f, 0x49, // Timestamp
+		0x01,             // Varint for SFNodeNetwork
+		0xf0,             // Unknown network
+		0x02, 0x01, 0x02, // Address
+		0x20, 0x8d, // Port 8333 in big-endian
+	}
+	if err := decoded.BtcDecode(bytes.NewReader(unknown), pver); err != nil {
+		t.Fatalf("BtcDecode: unexpected error: %v", err)
+	}
+	if len(decoded.AddrList) != 0 {
+		t.Fatalf("BtcDecode: unknown network was not skipped: %s",
+			spew.Sdump(decoded.AddrList))
+	}
+	badSize := append([]byte(nil), unknown...)
+	badSize[6] = byte(IPv4Address)
+	if err := decoded.BtcDecode(bytes.NewReader(badSize), pver); err == nil {
+		t.Fatal("BtcDecode: unexpected success with bad address size")
+	}
+
+	// The message is invalid before the protocol version which added it.
+	if err := msg.BtcEncode(&buf, pver-1); err == nil {
+		t.Fatal("BtcEncode: unexpected success with old protocol version")
+	}
+	if err := decoded.BtcDecode(bytes.NewReader(encoded), pver-1); err == nil {
+		t.Fatal("BtcDecode: unexpected success with old protocol version")
+	}
+
+	// Ensure adding more than the max allowed addresses per message
+	// returns error.
+	msg.ClearAddresses()
+	for i := 0; i < MaxAddrPerMsg; i++ {
+		msg.AddAddress(ipv4)
+	}
+	if err := msg.AddAddress(ipv4); err == nil {
+		t.Fatal("AddAddress: expected error on too many addresses not " +
+			"received")
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"fmt"
+	"io"
+)
+
+// MaxFeatureNameLen is the maximum number of bytes a feature name in a
+// sendfeature message may have.
+const MaxFeatureNameLen = 32
+
+// MsgSendFeature implements the Message interface and represents a hcd
+// sendfeature message.  It is used to advertise support for an optional
+// protocol feature, such as a new message type, to the remote peer.  Peers
+// send one sendfeature message for each feature they support after the
+// version message and before the verack message.  A feature is enabled for
+// the connection when both peers advertised it, at the lower of the two
+// advertised versions.
+//
+// Feature names are chosen by the feature's specification.  Peers ignore the
+// features they don't know, so features may be rolled out without a new
+// protocol version.
+//
+// This message was not added until protocol versions starting with
+// FeatureNegotiationVersion.
+type MsgSendFeature struct {
+	Name    string
+	Version uint32
+}
+
+// BtcDecode decodes r using the protocol encoding into the receiver.
+// This is part of the Message interface implementation.
+func (msg *MsgSendFeature) BtcDecode(r io.Reader, pver uint32) error {
+	if pver < FeatureNegotiationVersion {
+		str := fmt.Sprintf("sendfeature message invalid for protocol "+
+			"version %d", pver)
+		return messageError("MsgSendFeature.BtcDecode", str)
+	}
+
+	name, err := ReadVarString(r, pver)
+	if err != nil {
+		return err
+	}
+	if len(name) == 0 || len(name) > MaxFeatureNameLen {
+		str := fmt.Sprintf("feature name length %d is not in the "+
+			"range [1, %d]", len(name), MaxFeatureNameLen)
+		return messageError("MsgSendFeature.BtcDecode", str)
+	}
+	msg.Name = name
+
+	return readElement(r, &msg.Version)
+}
+
+// BtcEncode encodes the receiver to w using the protocol encoding.
+// This is part of the Message interface implementation.
+func (msg *MsgSendFeature) BtcEncode(w io.Writer, pver uint32) error {
+	if pver < FeatureNegotiationVersion {
+		str := fmt.Sprintf("sendfeature message invalid for protocol "+
+			"version %d", pver)
+		return messageError("MsgSendFeature.BtcEncode", str)
+	}
+
+	if len(msg.Name) == 0 || len(msg.Name) > MaxFeatureNameLen {
+		str := fmt.Sprintf("feature name length %d is not in the "+
+			"range [1, %d]", len(msg.Name), MaxFeatureNameLen)
+		return messageError("MsgSendFeature.BtcEncode", str)
+	}
+	err := WriteVarString(w, pver, msg.Name)
+	if err != nil {
+		return err
+	}
+
+	return writeElement(w, msg.Version)
+}
+
+// Command returns the protocol command string for the message.  This is part
+// of the Message interface implementation.
+func (msg *MsgSendFeature) Command() string {
+	return CmdSendFeature
+}
+
+// MaxPayloadLength returns the maximum length the payload can be for the
+// receiver.  This is part of the Message interface implementation.
+func (msg *MsgSendFeature) MaxPayloadLength(pver uint32) uint32 {
+	// Name length varint + max name length + 4 bytes version.
+	return uint32(VarIntSerializeSize(MaxFeatureNameLen)) +
+		MaxFeatureNameLen + 4
+}
+
+// NewMsgSendFeature returns a new sendfeature message that conforms to the
+// Message interface.  See MsgSendFeature for details.
+func NewMsgSendFeature(name string, version uint32) *MsgSendFeature {
+	return &MsgSendFeature{
+		Name:    name,
+		Version: version,
+	}
+}
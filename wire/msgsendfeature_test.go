@@ -0,0 +1,165 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+)
+
+// TestSendFeature tests the MsgSendFeature API.
+func TestSendFeature(t *testing.T) {
+	pver := ProtocolVersion
+
+	msg := NewMsgSendFeature("cfilters", 2)
+	if msg.Name != "cfilters" || msg.Version != 2 {
+		t.Errorf("NewMsgSendFeature: wrong feature - got %v %v, want "+
+			"cfilters 2", msg.Name, msg.Version)
+	}
+
+	// Ensure the command is expected value.
+	wantCmd := "sendfeature"
+	if cmd := msg.Command(); cmd != wantCmd {
+		t.Errorf("NewMsgSendFeature: wrong command - got %v want %v",
+			cmd, wantCmd)
+	}
+
+	// Ensure max payload is expected value.
+	// Name length varint 1 byte + 32 bytes name + 4 bytes version.
+	wantPayload := uint32(37)
+	maxPayload := msg.MaxPayloadLength(pver)
+	if maxPayload != wantPayload {
+		t.Errorf("MaxPayloadLength: wrong max payload length for "+
+			"protocol version %d - got %v, want %v", pver,
+			maxPayload, wantPayload)
+	}
+
+	// Ensure a feature with the longest allowed name fits the max payload.
+	long := NewMsgSendFeature(strings.Repeat("a", MaxFeatureNameLen), 1)
+	var buf bytes.Buffer
+	if err := long.BtcEncode(&buf, pver); err != nil {
+		t.Fatalf("BtcEncode: %v", err)
+	}
+	if uint32(buf.Len()) != maxPayload {
+		t.Errorf("BtcEncode: longest name is %d bytes, want %d",
+			buf.Len(), maxPayload)
+	}
+}
+
+// TestSendFeatureWire tests the MsgSendFeature wire encode and decode for
+// various protocol versions.
+func TestSendFeatureWire(t *testing.T) {
+	msg := NewMsgSendFeature("cfilters", 2)
+	msgEncoded := []byte{
+		0x08,                                           // Varint for name length
+		0x63, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x73, // "cfilters"
+		0x02, 0x00, 0x00, 0x00, // Version
+	}
+
+	tests := []struct {
+		in   *MsgSendFeature // Message to encode
+		out  *MsgSendFeature // Expected decoded message
+		buf  []byte          // Wire encoding
+		pver uint32          // Protocol version for wire encoding
+	}{
+		// Latest protocol version.
+		{msg, msg, msgEncoded, ProtocolVersion},
+
+		// Protocol version FeatureNegotiationVersion.
+		{msg, msg, msgEncoded, FeatureNegotiationVersion},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		// Encode the message to wire format.
+		var buf bytes.Buffer
+		err := test.in.BtcEncode(&buf, test.pver)
+		if err != nil {
+			t.Errorf("BtcEncode #%d error %v", i, err)
+			continue
+		}
+		if !bytes.Equal(buf.Bytes(), test.buf) {
+			t.Errorf("BtcEncode #%d\n got: %s want: %s", i,
+				spew.Sdump(buf.Bytes()), spew.Sdump(test.buf))
+			continue
+		}
+
+		// Decode the message from wire format.
+		var readmsg MsgSendFeature
+		rbuf := bytes.NewReader(test.buf)
+		err = readmsg.BtcDecode(rbuf, test.pver)
+		if err != nil {
+			t.Errorf("BtcDecode #%d error %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(&readmsg, test.out) {
+			t.Errorf("BtcDecode #%d\n got: %s want: %s", i,
+				spew.Sdump(&readmsg), spew.Sdump(test.out))
+			continue
+		}
+	}
+}
+
+// TestSendFeatureWireErrors performs negative tests against wire encode and
+// decode of MsgSendFeature to confirm error paths work correctly.
+func TestSendFeatureWireErrors(t *testing.T) {
+	pver := ProtocolVersion
+	oldPver := FeatureNegotiationVersion - 1
+
+	valid := NewMsgSendFeature("cfilters", 2)
+	validEncoded := []byte{
+		0x08, 0x63, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x73,
+		0x02, 0x00, 0x00, 0x00,
+	}
+
+	tests := []struct {
+		in   *MsgSendFeature // Value to encode
+		buf  []byte          // Wire encoding
+		pver uint32          // Protocol version for wire encoding
+	}{
+		// Unsupported protocol version.
+		{valid, validEncoded, oldPver},
+		// Empty feature name.
+		{NewMsgSendFeature("", 1), []byte{0x00, 0x01, 0x00, 0x00, 0x00}, pver},
+		// Feature name which is too long.
+		{
+			NewMsgSendFeature(strings.Repeat("a", MaxFeatureNameLen+1), 1),
+			append(append([]byte{MaxFeatureNameLen + 1},
+				bytes.Repeat([]byte("a"), MaxFeatureNameLen+1)...),
+				0x01, 0x00, 0x00, 0x00),
+			pver,
+		},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		var w bytes.Buffer
+		err := test.in.BtcEncode(&w, test.pver)
+		if _, ok := err.(*MessageError); !ok {
+			t.Errorf("BtcEncode #%d wrong error got: %v, want "+
+				"MessageError", i, err)
+		}
+
+		var msg MsgSendFeature
+		err = msg.BtcDecode(bytes.NewReader(test.buf), test.pver)
+		if _, ok := err.(*MessageError); !ok {
+			t.Errorf("BtcDecode #%d wrong error got: %v, want "+
+				"MessageError", i, err)
+		}
+	}
+
+	// Truncated encodings must fail to decode.
+	for i := 0; i < len(validEncoded); i++ {
+		var msg MsgSendFeature
+		err := msg.BtcDecode(bytes.NewReader(validEncoded[:i]), pver)
+		if err == nil {
+			t.Errorf("BtcDecode of %d bytes did not fail", i)
+		}
+	}
+}
@@ -422,12 +422,30 @@ func (msg *MsgTx) mustSerialize(serType TxSerializeType) []byte {
 	return serialized
 }
 
+// hashSerialized returns the hash of the serialization of the transaction for
+// the provided serialization type without modifying the original transaction.
+// The transaction is serialized directly into a pooled hasher, so no
+// intermediate buffer is allocated.  It will panic if any errors occur.
+func (msg *MsgTx) hashSerialized(serType TxSerializeType) chainhash.Hash {
+	// Shallow copy so the serialization type can be changed without
+	// modifying the original transaction.
+	mtxCopy := *msg
+	mtxCopy.SerType = serType
+	h := borrowHasher()
+	err := mtxCopy.Serialize(h)
+	if err != nil {
+		panic(fmt.Sprintf("MsgTx failed serializing for type %v",
+			serType))
+	}
+	return sumHasher(h)
+}
+
 // TxHash generates the hash for the transaction prefix.  Since it does not
 // contain any witness data, it is not malleable and therefore is stable for
 // use in unconfirmed transaction chains.
 func (msg *MsgTx) TxHash() chainhash.Hash {
 	// TxHash should always calculate a non-witnessed hash.
-	return chainhash.HashH(msg.mustSerialize(TxSerializeNoWitness))
+	return msg.hashSerialized(TxSerializeNoWitness)
 }
 
 // CachedTxHash is equivalent to calling TxHash, however it caches the result so
@@ -455,21 +473,21 @@ func (msg *MsgTx) RecacheTxHash() *chainhash.Hash {
 // TxHashWitness generates the hash for the transaction witness.
 func (msg *MsgTx) TxHashWitness() chainhash.Hash {
 	// TxHashWitness should always calculate a witnessed hash.
-	return chainhash.HashH(msg.mustSerialize(TxSerializeOnlyWitness))
+	return msg.hashSerialized(TxSerializeOnlyWitness)
 }
 
 // TxHashWitnessSigning generates the hash for the transaction witness with the
 // malleable portions (AmountIn, BlockHeight, BlockIndex) removed.  These are
 // verified and set by the miner instead.
 func (msg *MsgTx) TxHashWitnessSigning() chainhash.Hash {
-	return chainhash.HashH(msg.mustSerialize(TxSerializeWitnessSigning))
+	return msg.hashSerialized(TxSerializeWitnessSigning)
 }
 
 // TxHashWitnessValueSigning generates the hash for the transaction witness with
 // BlockHeight and BlockIndex removed, allowing the signer to specify the
 // ValueIn.
 func (msg *MsgTx) TxHashWitnessValueSigning() chainhash.Hash {
-	return chainhash.HashH(msg.mustSerialize(TxSerializeWitnessValueSigning))
+	return msg.hashSerialized(TxSerializeWitnessValueSigning)
 }
 
 // TxHashFull generates the hash for the transaction prefix || witness. It first
@@ -482,13 +500,15 @@ func (msg *MsgTx) TxHashFull() chainhash.Hash {
 	// lower 16 bits and the transaction serialization type in the upper 16
 	// bits.  The real transaction version (lower 16 bits) will be the same
 	// in both serializations.
-	concat := make([]byte, chainhash.HashSize*2)
+	//
+	// Writing the hashes one after the other to the hasher is the same as
+	// hashing their concatenation.
 	prefixHash := msg.TxHash()
 	witnessHash := msg.TxHashWitness()
-	copy(concat[0:], prefixHash[:])
-	copy(concat[chainhash.HashSize:], witnessHash[:])
-
-	return chainhash.HashH(concat)
+	h := borrowHasher()
+	h.writeHash(&prefixHash)
+	h.writeHash(&witnessHash)
+	return sumHasher(h)
 }
 
 // Copy creates a deep copy of a transaction so that the original does not get
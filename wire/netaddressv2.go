@@ -0,0 +1,371 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"bytes"
+	"encoding/base32"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// NetAddressType identifies the network an address in the versioned address
+// format (MsgAddrV2) belongs to.  The values match the network IDs of BIP155.
+type NetAddressType uint8
+
+const (
+	// IPv4Address identifies an IPv4 address.
+	IPv4Address NetAddressType = 1
+
+	// IPv6Address identifies an IPv6 address.
+	IPv6Address NetAddressType = 2
+
+	// TorV2Address identifies a version 2 Tor onion service address.
+	TorV2Address NetAddressType = 3
+
+	// TorV3Address identifies a version 3 Tor onion service address.
+	TorV3Address NetAddressType = 4
+
+	// I2PAddress identifies an I2P address.
+	I2PAddress NetAddressType = 5
+
+	// CJDNSAddress identifies a CJDNS address.
+	CJDNSAddress NetAddressType = 6
+)
+
+// MaxNetAddressV2Size is the maximum size of the address of a NetAddressV2
+// allowed on the wire regardless of its network.
+const MaxNetAddressV2Size = 512
+
+// netAddressTypeSizes houses the address sizes of the known networks.
+var netAddressTypeSizes = map[NetAddressType]int{
+	IPv4Address:  4,
+	IPv6Address:  16,
+	TorV2Address: 10,
+	TorV3Address: 32,
+	I2PAddress:   32,
+	CJDNSAddress: 16,
+}
+
+// Map of address types back to their names for pretty printing.
+var netAddressTypeStrings = map[NetAddressType]string{
+	IPv4Address:  "ipv4",
+	IPv6Address:  "ipv6",
+	TorV2Address: "torv2",
+	TorV3Address: "torv3",
+	I2PAddress:   "i2p",
+	CJDNSAddress: "cjdns",
+}
+
+// String returns the NetAddressType in human-readable form.
+func (t NetAddressType) String() string {
+	if s, ok := netAddressTypeStrings[t]; ok {
+		return s
+	}
+	return fmt.Sprintf("Unknown NetAddressType (%d)", uint8(t))
+}
+
+// onionCatPrefix is the IPv6 prefix used to represent version 2 Tor onion
+// service addresses in the legacy address format.
+var onionCatPrefix = []byte{0xfd, 0x87, 0xd8, 0x7e, 0xeb, 0x43}
+
+// torV3Version is the version byte of version 3 Tor onion service addresses.
+const torV3Version = 3
+
+// i2pSuffix is the suffix of the host names of I2P addresses.
+const i2pSuffix = ".b32.i2p"
+
+// NetAddressV2 defines information about a peer on the network in the
+// versioned address format, which, unlike NetAddress, is able to describe
+// addresses of networks which are not based on IP, such as version 3 Tor
+// onion services and I2P.
+type NetAddressV2 struct {
+	// Last time the address was seen.  This is encoded as a uint32 on the
+	// wire and therefore is limited to 2106.
+	Timestamp time.Time
+
+	// Bitfield which identifies the services supported by the address.
+	Services ServiceFlag
+
+	// Type identifies the network of the address.
+	Type NetAddressType
+
+	// Addr is the address of the peer in the encoding of its network.
+	Addr []byte
+
+	// Port the peer is using.  This is encoded in big endian on the wire.
+	Port uint16
+}
+
+// HasService returns whether the specified service is supported by the address.
+func (na *NetAddressV2) HasService(service ServiceFlag) bool {
+	return na.Services&service == service
+}
+
+// AddService adds service as a supported service by the peer generating the
+// message.
+func (na *NetAddressV2) AddService(service ServiceFlag) {
+	na.Services |= service
+}
+
+// NewNetAddressV2 returns a new NetAddressV2 using the provided timestamp,
+// services, network, address and port.  The timestamp is rounded to single
+// second precision.  An error is returned when the address does not have the
+// size required by the network.
+func NewNetAddressV2(timestamp time.Time, services ServiceFlag,
+	addrType NetAddressType, addr []byte, port uint16) (*NetAddressV2, error) {
+
+	size, ok := netAddressTypeSizes[addrType]
+	if !ok {
+		return nil, fmt.Errorf("unknown address type %v", addrType)
+	}
+	if len(addr) != size {
+		return nil, fmt.Errorf("invalid %v address size %d", addrType,
+			len(addr))
+	}
+	na := NetAddressV2{
+		Timestamp: time.Unix(timestamp.Unix(), 0),
+		Services:  services,
+		Type:      addrType,
+		Addr:      append([]byte(nil), addr...),
+		Port:      port,
+	}
+	return &na, nil
+}
+
+// NewNetAddressV2FromLegacy returns the passed legacy address in the versioned
+// address format.  IPv6 addresses in the OnionCat range are converted to
+// version 2 Tor onion service addresses.
+func NewNetAddressV2FromLegacy(na *NetAddress) *NetAddressV2 {
+	na2 := NetAddressV2{
+		Timestamp: na.Timestamp,
+		Services:  na.Services,
+		Port:      na.Port,
+	}
+	if ip4 := na.IP.To4(); ip4 != nil {
+		na2.Type = IPv4Address
+		na2.Addr = append([]byte(nil), ip4...)
+		return &na2
+	}
+	ip := na.IP.To16()
+	if ip == nil {
+		ip = make(net.IP, net.IPv6len)
+	}
+	if bytes.HasPrefix(ip, onionCatPrefix) {
+		na2.Type = TorV2Address
+		na2.Addr = append([]byte(nil), ip[len(onionCatPrefix):]...)
+		return &na2
+	}
+	na2.Type = IPv6Address
+	na2.Addr = append([]byte(nil), ip...)
+	return &na2
+}
+
+// ToLegacy returns the address in the legacy address format, or nil when its
+// network can't be represented by it.  Version 2 Tor onion service addresses
+// are converted to the OnionCat range.  CJDNS addresses are not converted
+// since legacy peers would treat them as private IPv6 addresses.
+func (na *NetAddressV2) ToLegacy() *NetAddress {
+	var ip net.IP
+	switch na.Type {
+	case IPv4Address, IPv6Address:
+		ip = append(net.IP(nil), na.Addr...)
+	case TorV2Address:
+		ip = append(append(net.IP(nil), onionCatPrefix...), na.Addr...)
+	default:
+		return nil
+	}
+	return &NetAddress{
+		Timestamp: na.Timestamp,
+		Services:  na.Services,
+		IP:        ip,
+		Port:      na.Port,
+	}
+}
+
+// torV3Checksum returns the checksum of a version 3 Tor onion service public
+// key as defined by the Tor rendezvous specification.
+func torV3Checksum(pubKey []byte) []byte {
+	h := sha3.New256()
+	h.Write([]byte(".onion checksum"))
+	h.Write(pubKey)
+	h.Write([]byte{torV3Version})
+	return h.Sum(nil)[:2]
+}
+
+// Host returns the host name of the address in the form used by its network.
+// IP based addresses are returned in their textual form, Tor addresses as
+// .onion host names and I2P addresses as .b32.i2p host names.
+func (na *NetAddressV2) Host() string {
+	switch na.Type {
+	case TorV2Address:
+		s := base32.StdEncoding.EncodeToString(na.Addr)
+		return strings.ToLower(s) + ".onion"
+
+	case TorV3Address:
+		data := make([]byte, 0, len(na.Addr)+3)
+		data = append(data, na.Addr...)
+		data = append(data, torV3Checksum(na.Addr)...)
+		data = append(data, torV3Version)
+		s := base32.StdEncoding.EncodeToString(data)
+		return strings.ToLower(s) + ".onion"
+
+	case I2PAddress:
+		s := base32.StdEncoding.WithPadding(base32.NoPadding).
+			EncodeToString(na.Addr)
+		return strings.ToLower(s) + i2pSuffix
+	}
+	return net.IP(na.Addr).String()
+}
+
+// String returns the address in the form host:port.
+func (na *NetAddressV2) String() string {
+	return net.JoinHostPort(na.Host(), fmt.Sprint(na.Port))
+}
+
+// NewNetAddressV2FromHost returns a new NetAddressV2 for the passed host name
+// as returned by Host along with the provided port and services.  Host names
+// which are not IP addresses, Tor onion service addresses, or I2P addresses
+// result in an error since they must be resolved by the caller.  The timestamp
+// is set to the current time.
+func NewNetAddressV2FromHost(host string, port uint16, services ServiceFlag) (*NetAddressV2, error) {
+	now := time.Now()
+	lower := strings.ToLower(host)
+	switch {
+	case strings.HasSuffix(lower, ".onion"):
+		data, err := base32.StdEncoding.DecodeString(
+			strings.ToUpper(strings.TrimSuffix(lower, ".onion")))
+		if err != nil {
+			return nil, fmt.Errorf("invalid onion address %q: %v",
+				host, err)
+		}
+		switch len(data) {
+		case netAddressTypeSizes[TorV2Address]:
+			return NewNetAddressV2(now, services, TorV2Address, data,
+				port)
+
+		case netAddressTypeSizes[TorV3Address] + 3:
+			pubKey := data[:32]
+			if data[34] != torV3Version ||
+				!bytes.Equal(data[32:34], torV3Checksum(pubKey)) {
+
+				return nil, fmt.Errorf("invalid onion address "+
+					"%q: bad version or checksum", host)
+			}
+			return NewNetAddressV2(now, services, TorV3Address,
+				pubKey, port)
+		}
+		return nil, fmt.Errorf("invalid onion address %q", host)
+
+	case strings.HasSuffix(lower, i2pSuffix):
+		data, err := base32.StdEncoding.WithPadding(base32.NoPadding).
+			DecodeString(strings.ToUpper(
+				strings.TrimSuffix(lower, i2pSuffix)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid I2P address %q: %v",
+				host, err)
+		}
+		return NewNetAddressV2(now, services, I2PAddress, data, port)
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return nil, fmt.Errorf("%q is not an IP, onion or I2P address",
+			host)
+	}
+	na := NewNetAddressV2FromLegacy(NewNetAddressTimestamp(now, services,
+		ip, port))
+
+	// Addresses in fc00::/8 are private IPv6 addresses which are not
+	// routable on the internet, so they are interpreted as CJDNS addresses
+	// in order to round trip with Host.
+	if na.Type == IPv6Address && na.Addr[0] == 0xfc {
+		na.Type = CJDNSAddress
+	}
+	return na, nil
+}
+
+// maxNetAddressV2Payload returns the max payload size for a NetAddressV2.
+func maxNetAddressV2Payload() uint32 {
+	// Timestamp 4 bytes + services (varint) + network 1 byte + address
+	// (varint length and address) + port 2 bytes.
+	return 4 + MaxVarIntPayload + 1 + MaxVarIntPayload +
+		MaxNetAddressV2Size + 2
+}
+
+// readNetAddressV2 reads an encoded NetAddressV2 from r.  It returns false
+// when the address is of an unknown network, in which case the address is
+// read but must be ignored by the caller.
+func readNetAddressV2(r io.Reader, pver uint32, na *NetAddressV2) (bool, error) {
+	err := readElement(r, (*uint32Time)(&na.Timestamp))
+	if err != nil {
+		return false, err
+	}
+	services, err := ReadVarInt(r, pver)
+	if err != nil {
+		return false, err
+	}
+	typ, err := binarySerializer.Uint8(r)
+	if err != nil {
+		return false, err
+	}
+	addrType := NetAddressType(typ)
+	addr, err := ReadVarBytes(r, pver, MaxNetAddressV2Size, "addr")
+	if err != nil {
+		return false, err
+	}
+	// Sigh.  Hcd protocol mixes little and big endian.
+	port, err := binarySerializer.Uint16(r, bigEndian)
+	if err != nil {
+		return false, err
+	}
+
+	size, known := netAddressTypeSizes[addrType]
+	if known && len(addr) != size {
+		str := fmt.Sprintf("invalid %v address size %d", addrType,
+			len(addr))
+		return false, messageError("readNetAddressV2", str)
+	}
+
+	*na = NetAddressV2{
+		Timestamp: na.Timestamp,
+		Services:  ServiceFlag(services),
+		Type:      addrType,
+		Addr:      addr,
+		Port:      port,
+	}
+	return known, nil
+}
+
+// writeNetAddressV2 serializes a NetAddressV2 to w.
+func writeNetAddressV2(w io.Writer, pver uint32, na *NetAddressV2) error {
+	if size, ok := netAddressTypeSizes[na.Type]; ok && len(na.Addr) != size {
+		str := fmt.Sprintf("invalid %v address size %d", na.Type,
+			len(na.Addr))
+		return messageError("writeNetAddressV2", str)
+	}
+
+	err := writeElement(w, uint32(na.Timestamp.Unix()))
+	if err != nil {
+		return err
+	}
+	if err := WriteVarInt(w, pver, uint64(na.Services)); err != nil {
+		return err
+	}
+	if err := binarySerializer.PutUint8(w, uint8(na.Type)); err != nil {
+		return err
+	}
+	if err := WriteVarBytes(w, pver, na.Addr); err != nil {
+		return err
+	}
+
+	// Sigh.  Hcd protocol mixes little and big endian.
+	return binarySerializer.PutUint16(w, bigEndian, na.Port)
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2018-2020 The Hc developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"net"
+	"testing"
+)
+
+// TestNetAddressV2Host ensures the host names of the supported networks are
+// parsed and formatted consistently and converted to and from the legacy
+// address format where possible.
+func TestNetAddressV2Host(t *testing.T) {
+	tests := []struct {
+		host     string
+		addrType NetAddressType
+		legacy   string // IP of the legacy address, empty if none
+	}{
+		{"127.0.0.1", IPv4Address, "127.0.0.1"},
+		{"2001:db8::1", IPv6Address, "2001:db8::1"},
+		{"aaaaaaaaaaaaaaaa.onion", TorV2Address, "fd87:d87e:eb43::"},
+		{"pg6mmjiyjmcrsslvykfwnntlaru7p5svn6y2ymmju6nubxndf4pscryd.onion",
+			TorV3Address, ""},
+		{"ukeu3k5oycgaauneqgtnvselmt4yemvoilkln7jpvamvfx7dnkdq.b32.i2p",
+			I2PAddress, ""},
+		{"fc00:1:2:3:4:5:6:7", CJDNSAddress, ""},
+	}
+
+	for _, test := range tests {
+		na, err := NewNetAddressV2FromHost(test.host, 8333, SFNodeNetwork)
+		if err != nil {
+			t.Errorf("NewNetAddressV2FromHost(%q): unexpected error: %v",
+				test.host, err)
+			continue
+		}
+		if na.Type != test.addrType {
+			t.Errorf("NewNetAddressV2FromHost(%q): wrong type - got "+
+				"%v, want %v", test.host, na.Type, test.addrType)
+		}
+		if host := na.Host(); host != test.host {
+			t.Errorf("Host: wrong host - got %q, want %q", host,
+				test.host)
+		}
+
+		legacy := na.ToLegacy()
+		if (legacy == nil) != (test.legacy == "") {
+			t.Errorf("ToLegacy(%q): unexpected legacy address %v",
+				test.host, legacy)
+			continue
+		}
+		if legacy == nil {
+			continue
+		}
+		if !legacy.IP.Equal(net.ParseIP(test.legacy)) {
+			t.Errorf("ToLegacy(%q): wrong IP %v", test.host,
+				legacy.IP)
+		}
+		back := NewNetAddressV2FromLegacy(legacy)
+		if back.Type != na.Type || back.Host() != test.host {
+			t.Errorf("NewNetAddressV2FromLegacy(%q): wrong address "+
+				"%v", test.host, back)
+		}
+	}
+
+	// Onion addresses with a bad checksum and unresolved host names are
+	// rejected.
+	invalid := []string{
+		"pg6mmjiyjmcrsslvykfwnntlaru7p5svn6y2ymmju6nubxndf4pscryb.onion",
+		"example.com",
+	}
+	for _, host := range invalid {
+		if _, err := NewNetAddressV2FromHost(host, 8333, 0); err == nil {
+			t.Errorf("NewNetAddressV2FromHost(%q): unexpected success",
+				host)
+		}
+	}
+}
@@ -18,7 +18,7 @@ const (
 	InitialProcotolVersion uint32 = 1
 
 	// ProtocolVersion is the latest protocol version this package supports.
-	ProtocolVersion uint32 = 5
+	ProtocolVersion uint32 = 7
 
 	// BIP0111Version is the protocol version which added the SFNodeBloom
 	// service flag.
@@ -35,6 +35,15 @@ const (
 	// FeeFilterVersion is the protocol version which added a new
 	// feefilter message.
 	FeeFilterVersion uint32 = 5
+
+	// AddrV2Version is the protocol version which added a new addrv2
+	// message supporting addresses of networks which are not based on IP.
+	AddrV2Version uint32 = 6
+
+	// FeatureNegotiationVersion is the protocol version which added a new
+	// sendfeature message used to negotiate optional features during the
+	// handshake.
+	FeatureNegotiationVersion uint32 = 7
 )
 
 // ServiceFlag identifies services supported by a hcd peer.
@@ -0,0 +1,67 @@
+// Copyright (c) 2018-2020 The Hcd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// FromBytesStrict deserializes a transaction byte slice like FromBytes, but
+// only accepts the canonical encoding of the transaction.  The whole slice
+// must be consumed and serializing the decoded transaction must reproduce it
+// exactly.  This ensures no two distinct byte slices decode to the same
+// transaction, which matters wherever the received bytes are hashed or stored
+// as-is.
+func (msg *MsgTx) FromBytesStrict(b []byte) error {
+	r := bytes.NewReader(b)
+	if err := msg.Deserialize(r); err != nil {
+		return err
+	}
+	return checkCanonical("MsgTx.FromBytesStrict", b, r, msg.Serialize)
+}
+
+// FromBytesStrict deserializes a block byte slice like FromBytes, but only
+// accepts the canonical encoding of the block.  See MsgTx.FromBytesStrict.
+func (msg *MsgBlock) FromBytesStrict(b []byte) error {
+	r := bytes.NewReader(b)
+	if err := msg.Deserialize(r); err != nil {
+		return err
+	}
+	return checkCanonical("MsgBlock.FromBytesStrict", b, r, msg.Serialize)
+}
+
+// FromBytesStrict deserializes a block header byte slice like FromBytes, but
+// only accepts the canonical encoding of the header.  See
+// MsgTx.FromBytesStrict.
+func (h *BlockHeader) FromBytesStrict(b []byte) error {
+	r := bytes.NewReader(b)
+	if err := h.Deserialize(r); err != nil {
+		return err
+	}
+	return checkCanonical("BlockHeader.FromBytesStrict", b, r, h.Serialize)
+}
+
+// checkCanonical returns an error when r, which was used to decode a message
+// from b, has unread bytes left, or when serializing the decoded message does
+// not reproduce b.
+func checkCanonical(f string, b []byte, r *bytes.Reader, serialize func(io.Writer) error) error {
+	if r.Len() != 0 {
+		str := fmt.Sprintf("%d trailing bytes after encoded message",
+			r.Len())
+		return messageError(f, str)
+	}
+
+	var buf bytes.Buffer
+	buf.Grow(len(b))
+	if err := serialize(&buf); err != nil {
+		return err
+	}
+	if !bytes.Equal(buf.Bytes(), b) {
+		return messageError(f, "non-canonical encoding")
+	}
+	return nil
+}
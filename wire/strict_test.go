@@ -0,0 +1,77 @@
+// Copyright (c) 2018-2020 The Hcd developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+)
+
+// TestFromBytesStrict ensures the strict decoders accept canonical encodings
+// and reject trailing data the lenient decoders ignore.
+func TestFromBytesStrict(t *testing.T) {
+	withTrailing := func(b []byte) []byte {
+		return append(append([]byte{}, b...), 0x00)
+	}
+	header, err := testBlock.Header.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		msg  interface {
+			FromBytes([]byte) error
+			FromBytesStrict([]byte) error
+		}
+		in    []byte
+		valid bool
+	}{
+		{"tx", &MsgTx{}, multiTxEncoded, true},
+		{"tx prefix", &MsgTx{}, multiTxPrefixEncoded, true},
+		{"tx witness", &MsgTx{}, multiTxWitnessEncoded, true},
+		{"tx trailing", &MsgTx{}, withTrailing(multiTxEncoded), false},
+		{"tx truncated", &MsgTx{}, multiTxEncoded[:len(multiTxEncoded)-1], false},
+		{"block", &MsgBlock{}, testBlockBytes, true},
+		{"block trailing", &MsgBlock{}, withTrailing(testBlockBytes), false},
+		{"header", &BlockHeader{}, header, true},
+		{"header trailing", &BlockHeader{}, withTrailing(header), false},
+	}
+
+	for _, test := range tests {
+		err := test.msg.FromBytesStrict(test.in)
+		if (err == nil) != test.valid {
+			t.Errorf("%s: unexpected result - got %v, want valid %v",
+				test.name, err, test.valid)
+			continue
+		}
+		if !test.valid {
+			continue
+		}
+
+		// Canonical encodings must decode to the same message as with
+		// the lenient decoder.
+		lenient := reflect.New(reflect.TypeOf(test.msg).Elem()).Interface().(interface {
+			FromBytes([]byte) error
+		})
+		if err := lenient.FromBytes(test.in); err != nil {
+			t.Errorf("%s: FromBytes: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(lenient, test.msg) {
+			t.Errorf("%s: mismatched messages - got %v, want %v",
+				test.name, spew.Sdump(test.msg), spew.Sdump(lenient))
+		}
+	}
+
+	// Trailing data must be reported as a message error.
+	var tx MsgTx
+	err = tx.FromBytesStrict(withTrailing(multiTxEncoded))
+	if _, ok := err.(*MessageError); !ok {
+		t.Errorf("unexpected error type %T for trailing data", err)
+	}
+}